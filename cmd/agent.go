@@ -210,6 +210,7 @@ var agentPromptCmd = &cobra.Command{
 		// If --finding is supplied, load it and prepend its YAML to the context.
 		if findingID != "" {
 			findingStore := finding.NewStore(p)
+			defer func() { _ = findingStore.Close() }()
 			f, err := findingStore.Read(findingID)
 			if err != nil {
 				exitError("failed to load finding %s: %v", findingID, err)
@@ -36,6 +36,7 @@ The dashboard provides:
 		idleTimeout, _ := cmd.Flags().GetDuration("idle-timeout")
 
 		server := dashboard.NewServer(p, port)
+		defer func() { _ = server.Close() }()
 		server.SetTimeouts(readHeaderTimeout, readTimeout, writeTimeout, idleTimeout)
 
 		url := fmt.Sprintf("http://localhost:%d", port)
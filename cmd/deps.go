@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/diffsec/quokka/internal/deps"
+	"github.com/diffsec/quokka/internal/finding"
+	"github.com/diffsec/quokka/internal/project"
+	"github.com/spf13/cobra"
+)
+
+var depsCmd = &cobra.Command{
+	Use:   "deps",
+	Short: "Dependency analysis",
+	Long:  `Commands for analysing the project's third-party dependencies.`,
+}
+
+var depsScanCmd = &cobra.Command{
+	Use:   "scan",
+	Short: "Match pinned dependencies against an offline OSV database",
+	Long: `Parses the project's dependency manifests and lockfiles and matches every
+pinned version against a local OSV (https://osv.dev) vulnerability dump,
+persisting one finding per vulnerable package and advisory.
+
+Supported manifests: go.mod, package-lock.json, yarn.lock, package.json
+(only when no lockfile sits next to it; versions are the range lower
+bound), requirements.txt (== pins), poetry.lock, Cargo.lock, Gemfile.lock
+and pom.xml. node_modules, vendor and build directories are skipped.
+
+The database is read from --db, else $QUOKKA_OSV_DB, else .quokka/osv/. It
+may be a directory of OSV JSON records and/or per-ecosystem all.zip
+exports (https://osv-vulnerabilities.storage.googleapis.com/<ecosystem>/all.zip),
+a single all.zip, or a JSON file. Nothing is fetched over the network.
+
+Findings have created_by="osv", CWE from the advisory (CWE-1395 when it has
+none), CVSS when the record carries a v3 vector, and the OSV id as an
+"osv-id:<id>" tag. They go through the normal fingerprint pipeline, so
+re-scanning doesn't duplicate them.
+
+Examples:
+  quokka deps scan --db ~/osv
+  quokka deps scan --diff origin/main
+  quokka deps scan --path services/api --dry-run --json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		p, err := project.EnsureActive()
+		if err != nil {
+			exitError("%v", err)
+		}
+
+		dbPath, _ := cmd.Flags().GetString("db")
+		diffBase, _ := cmd.Flags().GetString("diff")
+		targetFlag, _ := cmd.Flags().GetStringSlice("path")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		if dbPath == "" {
+			dbPath = os.Getenv("QUOKKA_OSV_DB")
+		}
+		if dbPath == "" {
+			dbPath = filepath.Join(p.GetQuokkaPath(), "osv")
+		}
+
+		targets := targetFlag
+		if len(targets) == 0 {
+			targets = []string{p.RootPath}
+		}
+		if diffBase != "" {
+			changed, err := getChangedFiles(diffBase)
+			if err != nil {
+				exitError("%v", err)
+			}
+			targets = nil
+			for f := range changed {
+				if deps.IsManifest(filepath.Base(f)) {
+					if _, err := os.Stat(f); err == nil {
+						targets = append(targets, f)
+					}
+				}
+			}
+			sort.Strings(targets)
+			if len(targets) == 0 {
+				if jsonOutput {
+					if err := outputJSON(map[string]any{"total": 0, "created": 0, "diff_base": diffBase}); err != nil {
+						exitError("failed to encode JSON: %v", err)
+					}
+				} else {
+					fmt.Printf("No dependency manifests changed since %s — skipping scan.\n", diffBase)
+				}
+				return
+			}
+		}
+
+		db, err := deps.LoadDatabase(dbPath)
+		if err != nil {
+			exitError("%v\nDownload an OSV export (e.g. https://osv-vulnerabilities.storage.googleapis.com/npm/all.zip) and pass it with --db.", err)
+		}
+
+		scanner := &deps.Scanner{DB: db, ProjectRoot: p.RootPath}
+		res, err := scanner.Scan(targets)
+		if err != nil {
+			exitError("%v", err)
+		}
+
+		created := 0
+		skipped := 0
+		if !dryRun {
+			store := finding.NewStore(p)
+			defer func() { _ = store.Close() }()
+			for i := range res.Findings {
+				if _, err := store.Create(&res.Findings[i]); err != nil {
+					skipped++
+					continue
+				}
+				created++
+			}
+		}
+
+		bySeverity := map[string]int{}
+		for _, f := range res.Findings {
+			bySeverity[string(f.Severity)]++
+		}
+
+		if jsonOutput {
+			out := map[string]any{
+				"total":               len(res.Findings),
+				"created":             created,
+				"skipped":             skipped,
+				"by_severity":         bySeverity,
+				"manifests":           res.Manifests,
+				"packages":            res.Packages,
+				"advisories":          db.Len(),
+				"diff_base":           diffBase,
+				"dry_run":             dryRun,
+				"parse_errors":        res.Errors,
+				"vulnerable_packages": vulnerablePackages(res.Matches),
+			}
+			if dryRun {
+				out["findings"] = res.Findings
+			}
+			if err := outputJSON(out); err != nil {
+				exitError("failed to encode JSON: %v", err)
+			}
+			return
+		}
+
+		fmt.Printf("dependency scan complete (%d advisories loaded from %s)\n", db.Len(), dbPath)
+		fmt.Printf("  manifests: %d\n", len(res.Manifests))
+		fmt.Printf("  packages:  %d\n", res.Packages)
+		fmt.Printf("  results:   %d\n", len(res.Findings))
+		if dryRun {
+			fmt.Printf("  (dry run — nothing persisted)\n")
+		} else {
+			fmt.Printf("  created:   %d\n", created)
+			if skipped > 0 {
+				fmt.Printf("  skipped:   %d (store errors)\n", skipped)
+			}
+		}
+		for _, sev := range []finding.Severity{finding.SeverityCritical, finding.SeverityHigh, finding.SeverityMedium, finding.SeverityLow, finding.SeverityInfo} {
+			if n := bySeverity[string(sev)]; n > 0 {
+				fmt.Printf("  %s: %d\n", sev, n)
+			}
+		}
+		for _, e := range res.Errors {
+			fmt.Printf("  warning: %s\n", e)
+		}
+		if len(res.Matches) > 0 {
+			fmt.Println("\nVulnerable packages:")
+			for _, m := range res.Matches {
+				fix := "no fix"
+				if m.FixedVersion != "" {
+					fix = "fixed in " + m.FixedVersion
+				}
+				fmt.Printf("  %s:%d  %s %s  %s (%s)\n", m.Package.File, m.Package.Line, m.Package.Name, m.Package.Version, m.Vulnerability.ID, fix)
+			}
+		}
+	},
+}
+
+// vulnerablePackages summarises matches as "<ecosystem>/<name>@<version>"
+// with the advisory ids affecting each.
+func vulnerablePackages(matches []deps.Match) map[string][]string {
+	out := map[string][]string{}
+	for _, m := range matches {
+		key := fmt.Sprintf("%s/%s@%s", m.Package.Ecosystem, m.Package.Name, m.Package.Version)
+		out[key] = append(out[key], m.Vulnerability.ID)
+	}
+	return out
+}
+
+func init() {
+	rootCmd.AddCommand(depsCmd)
+	depsCmd.AddCommand(depsScanCmd)
+	depsScanCmd.Flags().String("db", "", "OSV database: directory, all.zip or JSON file (default: $QUOKKA_OSV_DB, then .quokka/osv)")
+	depsScanCmd.Flags().String("diff", "", "Only scan manifests changed since this git ref (e.g. origin/main)")
+	depsScanCmd.Flags().StringSlice("path", nil, "Explicit files or directories to scan (repeatable). Default: project root.")
+	depsScanCmd.Flags().Bool("dry-run", false, "Report matches without persisting findings")
+}
@@ -16,6 +16,8 @@ var exceptionCmd = &cobra.Command{
 	Short: "Manage finding suppressions (exceptions)",
 	Long: `Exceptions suppress findings either by fingerprint (one specific
 finding) or by path glob + CWE (a class of findings within a path).
+Suppressions can also be declared next to the code with an inline
+"quokka:ignore" comment; see "quokka exception scan".
 
 Every exception requires a reason, an expires date, and an approver.
 Suppressions are time-bounded by design — they expire and reappear for
@@ -45,7 +47,7 @@ var exceptionAddCmd = &cobra.Command{
 		if expiresStr == "" {
 			exitError("--expires is required (YYYY-MM-DD); suppressions must be time-bounded")
 		}
-		expires, err := parseExpires(expiresStr)
+		expires, err := exception.ParseExpires(expiresStr)
 		if err != nil {
 			exitError("invalid --expires: %v", err)
 		}
@@ -110,6 +112,8 @@ var exceptionListCmd = &cobra.Command{
 			target := ""
 			if e.IsFingerprint() {
 				target = "fp=" + shortFP(e.Fingerprint)
+			} else if e.IsLocation() {
+				target = fmt.Sprintf("%s:%d (%s)", e.File, e.Line, e.CWE)
 			} else {
 				target = e.PathGlob + " (" + e.CWE + ")"
 			}
@@ -176,17 +180,79 @@ var exceptionExpireCmd = &cobra.Command{
 	},
 }
 
-// parseExpires accepts YYYY-MM-DD or full RFC3339. Anchors the date at
-// 23:59:59 UTC so "expires: 2026-09-01" means "good through 2026-09-01."
-func parseExpires(s string) (time.Time, error) {
-	s = strings.TrimSpace(s)
-	if t, err := time.Parse("2006-01-02", s); err == nil {
-		return t.Add(24*time.Hour - time.Second), nil
-	}
-	if t, err := time.Parse(time.RFC3339, s); err == nil {
-		return t, nil
-	}
-	return time.Time{}, fmt.Errorf("expected YYYY-MM-DD or RFC3339, got %q", s)
+var exceptionScanCmd = &cobra.Command{
+	Use:   "scan",
+	Short: "Report inline quokka:ignore annotations that suppress nothing",
+	Long: `Scan the project's source for inline suppression comments and report
+those that no longer suppress anything: expired, malformed (unknown keys,
+bad dates, missing reason/expires/approved-by, or no CWE on a
+location-scoped annotation), or approved by an agent (approved-by=agent:...)
+while allow_agent_writes.exceptions is off in the project config.
+
+An annotation opens a comment in any language tree-sitter supports:
+
+  // quokka:ignore CWE-89 reason="parameterised upstream" expires=2027-01-01 approved-by=alice
+
+On its own line it applies to the next line of code; after code on the
+same line it applies to that line. Add fingerprint=<fp> to scope it to one
+finding instead of the location. Valid annotations suppress findings like
+entries in .quokka/exceptions.yaml.
+
+Exits non-zero when any problem is found, so it can gate CI.
+
+Examples:
+  quokka exception scan
+  quokka exception scan --all
+  quokka exception scan --json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		p, err := project.EnsureActive()
+		if err != nil {
+			exitError("%v", err)
+		}
+		all, _ := cmd.Flags().GetBool("all")
+
+		annotations, err := exception.ScanInline(p.RootPath)
+		if err != nil {
+			exitError("%v", err)
+		}
+		now := time.Now()
+		type scanEntry struct {
+			exception.Annotation
+			Problem string `json:"problem,omitempty"`
+		}
+		var entries []scanEntry
+		problems := 0
+		for _, a := range annotations {
+			problem := a.Problem(now, p.Config.AllowAgentWrites.Exceptions)
+			if problem != "" {
+				problems++
+			} else if !all {
+				continue
+			}
+			entries = append(entries, scanEntry{Annotation: a, Problem: problem})
+		}
+
+		if jsonOutput {
+			if err := outputJSON(map[string]any{"annotations": entries, "total": len(annotations), "problems": problems}); err != nil {
+				exitError("failed to encode JSON: %v", err)
+			}
+		} else {
+			for _, e := range entries {
+				status := "ok"
+				if e.Problem != "" {
+					status = strings.ToUpper(e.Problem[:1]) + e.Problem[1:]
+				}
+				fmt.Printf("%s:%d  %s\n  %s\n", e.File, e.Line, status, e.Text)
+			}
+			if len(entries) > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("%d inline annotation(s), %d with problems\n", len(annotations), problems)
+		}
+		if problems > 0 {
+			os.Exit(1)
+		}
+	},
 }
 
 // defaultApprovedBy infers a sensible attribution when --approved-by is
@@ -212,6 +278,7 @@ func init() {
 	exceptionCmd.AddCommand(exceptionListCmd)
 	exceptionCmd.AddCommand(exceptionRemoveCmd)
 	exceptionCmd.AddCommand(exceptionExpireCmd)
+	exceptionCmd.AddCommand(exceptionScanCmd)
 
 	exceptionAddCmd.Flags().String("fingerprint", "", "Finding fingerprint to suppress (mutually exclusive with --path-glob)")
 	exceptionAddCmd.Flags().String("path-glob", "", "filepath.Match glob to suppress (e.g. tests/*.py); requires --cwe")
@@ -222,4 +289,6 @@ func init() {
 	exceptionAddCmd.Flags().String("approved-for", "", "Optional context (e.g. PR #482)")
 
 	exceptionListCmd.Flags().Bool("include-expired", false, "Include expired exceptions in the output")
+
+	exceptionScanCmd.Flags().Bool("all", false, "List every inline annotation, not just expired or malformed ones")
 }
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -14,7 +15,9 @@ import (
 	"github.com/diffsec/quokka/internal/exception"
 	"github.com/diffsec/quokka/internal/finding"
 	"github.com/diffsec/quokka/internal/finding/export"
+	_ "github.com/diffsec/quokka/internal/finding/sqlitestore" // registers the sqlite backend
 	"github.com/diffsec/quokka/internal/project"
+	"github.com/diffsec/quokka/internal/sast"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 )
@@ -194,7 +197,9 @@ Valid statuses: open, confirmed, false_positive, fixed, duplicate.`,
 		}
 
 		store := finding.NewStore(p)
-		if err := store.Create(&f); err != nil {
+		defer func() { _ = store.Close() }()
+
+		if _, err := store.Create(&f); err != nil {
 			exitError("%v", err)
 		}
 
@@ -504,7 +509,11 @@ You can update status, severity, or provide a new YAML file.
 
 Valid statuses: open, confirmed, false_positive, fixed, duplicate.
 When marking a finding as duplicate, optionally pass --duplicate-of FIND-XXX
-to record the canonical finding ID.`,
+to record the canonical finding ID.
+
+Every changed field is recorded in the finding's history (see
+` + "`quokka finding history`" + `) under --author, which defaults to
+$QUOKKA_AGENT_NAME, else human:$USER.`,
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		p, err := project.EnsureActive()
@@ -513,45 +522,56 @@ to record the canonical finding ID.`,
 		}
 
 		id := args[0]
-		store := finding.NewStore(p)
-
-		f, err := store.Read(id)
-		if err != nil {
-			exitError("%v", err)
+		store := trackRuleTriage(p, finding.NewStore(p))
+		defer func() { _ = store.Close() }()
+
+		status, _ := cmd.Flags().GetString("status")
+		severity, _ := cmd.Flags().GetString("severity")
+		confidence, _ := cmd.Flags().GetString("confidence")
+		exploitability, _ := cmd.Flags().GetString("exploitability")
+		fixPriority, _ := cmd.Flags().GetString("fix-priority")
+		dupOf, _ := cmd.Flags().GetString("duplicate-of")
+		note, _ := cmd.Flags().GetString("note")
+		noteAuthor, _ := cmd.Flags().GetString("note-author")
+		if noteAuthor == "" {
+			noteAuthor = "user"
+		}
+		author, _ := cmd.Flags().GetString("author")
+		if author == "" {
+			author = defaultActor()
 		}
 
-		// Apply updates
-		if status, _ := cmd.Flags().GetString("status"); status != "" {
-			f.Status = finding.Status(status)
-		}
-		if severity, _ := cmd.Flags().GetString("severity"); severity != "" {
-			f.Severity = finding.Severity(severity)
-		}
-		if confidence, _ := cmd.Flags().GetString("confidence"); confidence != "" {
-			f.Confidence = finding.Confidence(confidence)
-		}
-		if exploitability, _ := cmd.Flags().GetString("exploitability"); exploitability != "" {
-			f.Exploitability = finding.Exploitability(exploitability)
-		}
-		if fixPriority, _ := cmd.Flags().GetString("fix-priority"); fixPriority != "" {
-			f.FixPriority = finding.FixPriority(fixPriority)
-		}
-		if dupOf, _ := cmd.Flags().GetString("duplicate-of"); dupOf != "" {
-			f.DuplicateOf = dupOf
-		}
-		if note, _ := cmd.Flags().GetString("note"); note != "" {
-			author, _ := cmd.Flags().GetString("note-author")
-			if author == "" {
-				author = "user"
+		// Apply updates to the stored finding in one step, so a concurrent
+		// triage of the same finding is not overwritten.
+		f, err := store.Modify(id, finding.Actor{Name: author, Source: "finding update"}, func(f *finding.Finding) error {
+			if status != "" {
+				f.Status = finding.Status(status)
 			}
-			f.Notes = append(f.Notes, finding.FindingNote{
-				Timestamp: time.Now(),
-				Author:    author,
-				Text:      note,
-			})
-		}
-
-		if err := store.Update(f); err != nil {
+			if severity != "" {
+				f.Severity = finding.Severity(severity)
+			}
+			if confidence != "" {
+				f.Confidence = finding.Confidence(confidence)
+			}
+			if exploitability != "" {
+				f.Exploitability = finding.Exploitability(exploitability)
+			}
+			if fixPriority != "" {
+				f.FixPriority = finding.FixPriority(fixPriority)
+			}
+			if dupOf != "" {
+				f.DuplicateOf = dupOf
+			}
+			if note != "" {
+				f.Notes = append(f.Notes, finding.FindingNote{
+					Timestamp: time.Now(),
+					Author:    noteAuthor,
+					Text:      note,
+				})
+			}
+			return nil
+		})
+		if err != nil {
 			exitError("%v", err)
 		}
 
@@ -629,6 +649,10 @@ Plan file shape (` + "`quokka finding triage --plan path/to/plan.json`" + `):
 Default plan path: .quokka/review/triage-plan.json (where the dispatcher
 expects validation-agent and sast-triage-agent to write).
 
+Changes are recorded in each finding's history with the plan author as
+actor and --source (default "finding triage"; the dispatcher passes
+"dispatcher") as source.
+
 Each decision is applied independently:
   - Missing finding_id: counted as skipped, reported, no fatal error
   - Invalid status value: counted as errored, reported, run continues
@@ -648,6 +672,7 @@ problem worth surfacing).`,
 			planPath = filepath.Join(p.GetQuokkaPath(), "review", "triage-plan.json")
 		}
 		authorOverride, _ := cmd.Flags().GetString("author")
+		source, _ := cmd.Flags().GetString("source")
 
 		data, err := os.ReadFile(planPath)
 		if err != nil {
@@ -669,7 +694,8 @@ problem worth surfacing).`,
 			author = "triage"
 		}
 
-		store := finding.NewStore(p)
+		store := trackRuleTriage(p, finding.NewStore(p))
+		defer func() { _ = store.Close() }()
 		var applied, skipped, errored int
 		statusBreakdown := map[string]int{}
 
@@ -679,12 +705,6 @@ problem worth surfacing).`,
 				fmt.Fprintf(os.Stderr, "  skip: decision has no finding_id\n")
 				continue
 			}
-			f, err := store.Read(d.FindingID)
-			if err != nil {
-				skipped++
-				fmt.Fprintf(os.Stderr, "  skip %s: %v\n", d.FindingID, err)
-				continue
-			}
 
 			// Validate status (let blank pass — means caller only wanted
 			// to tweak severity / notes / etc.).
@@ -693,37 +713,47 @@ problem worth surfacing).`,
 				fmt.Fprintf(os.Stderr, "  error %s: invalid status %q (use open/confirmed/false_positive/fixed/duplicate)\n", d.FindingID, d.Status)
 				continue
 			}
-			if d.Status != "" {
-				f.Status = finding.Status(d.Status)
-				statusBreakdown[d.Status]++
-			}
-			if d.DuplicateOf != "" {
-				f.DuplicateOf = d.DuplicateOf
-			}
-			if d.SeverityOverride != "" {
-				f.Severity = finding.Severity(d.SeverityOverride)
-			}
-			if d.FixPriority != "" {
-				f.FixPriority = finding.FixPriority(d.FixPriority)
-			}
-			if d.Exploitability != "" {
-				f.Exploitability = finding.Exploitability(d.Exploitability)
-			}
-			if d.ConfidenceOverride != "" {
-				f.Confidence = finding.Confidence(d.ConfidenceOverride)
-			}
-			if d.Reason != "" {
-				f.Notes = append(f.Notes, finding.FindingNote{
-					Timestamp: time.Now(),
-					Author:    author,
-					Text:      d.Reason,
-				})
+			_, err := store.Modify(d.FindingID, finding.Actor{Name: author, Source: source}, func(f *finding.Finding) error {
+				if d.Status != "" {
+					f.Status = finding.Status(d.Status)
+				}
+				if d.DuplicateOf != "" {
+					f.DuplicateOf = d.DuplicateOf
+				}
+				if d.SeverityOverride != "" {
+					f.Severity = finding.Severity(d.SeverityOverride)
+				}
+				if d.FixPriority != "" {
+					f.FixPriority = finding.FixPriority(d.FixPriority)
+				}
+				if d.Exploitability != "" {
+					f.Exploitability = finding.Exploitability(d.Exploitability)
+				}
+				if d.ConfidenceOverride != "" {
+					f.Confidence = finding.Confidence(d.ConfidenceOverride)
+				}
+				if d.Reason != "" {
+					f.Notes = append(f.Notes, finding.FindingNote{
+						Timestamp: time.Now(),
+						Author:    author,
+						Text:      d.Reason,
+					})
+				}
+				return nil
+			})
+			if errors.Is(err, finding.ErrNotFound) {
+				skipped++
+				fmt.Fprintf(os.Stderr, "  skip %s: %v\n", d.FindingID, err)
+				continue
 			}
-			if err := store.Update(f); err != nil {
+			if err != nil {
 				errored++
 				fmt.Fprintf(os.Stderr, "  error %s: update failed: %v\n", d.FindingID, err)
 				continue
 			}
+			if d.Status != "" {
+				statusBreakdown[d.Status]++
+			}
 			applied++
 		}
 
@@ -768,7 +798,12 @@ func isValidStatus(s string) bool {
 var findingListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List findings",
-	Long:  `List all findings with optional filters.`,
+	Long: `List all findings with optional filters.
+
+Findings matched by a non-expired exception in .quokka/exceptions.yaml are
+hidden by default. --include-suppressed shows them alongside the rest,
+tagged with the exception ID, reason, approver and expiry; --suppressed
+shows only the suppressed ones.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		p, err := project.EnsureActive()
 		if err != nil {
@@ -776,6 +811,8 @@ var findingListCmd = &cobra.Command{
 		}
 
 		store := finding.NewStore(p)
+		defer func() { _ = store.Close() }()
+
 		opts := &finding.FilterOptions{}
 
 		if sev, _ := cmd.Flags().GetString("severity"); sev != "" {
@@ -817,20 +854,27 @@ var findingListCmd = &cobra.Command{
 
 		// Apply suppression filter from .quokka/exceptions.yaml. By default
 		// suppressed findings are hidden; --include-suppressed surfaces them
-		// with a SUPPRESSED tag so reviewers can see what was filtered.
+		// with a SUPPRESSED tag so reviewers can see what was filtered, and
+		// --suppressed lists only the hidden ones.
 		includeSuppressed, _ := cmd.Flags().GetBool("include-suppressed")
-		excStore := exception.NewStore(p)
+		onlySuppressed, _ := cmd.Flags().GetBool("suppressed")
+		if onlySuppressed {
+			includeSuppressed = true
+		}
+		suppressedCount, err := exception.NewStore(p).Annotate(result.Findings)
+		if err != nil {
+			exitError("%v", err)
+		}
 		suppressedFor := map[string]string{} // ID → reason
 		filtered := result.Findings[:0]
-		var suppressedCount int
 		for _, f := range result.Findings {
-			match, _ := excStore.Match(f)
-			if match != nil {
-				suppressedCount++
-				suppressedFor[f.ID] = match.Reason
+			if f.Suppression != nil {
+				suppressedFor[f.ID] = f.Suppression.Reason
 				if !includeSuppressed {
 					continue
 				}
+			} else if onlySuppressed {
+				continue
 			}
 			filtered = append(filtered, f)
 		}
@@ -851,6 +895,10 @@ var findingListCmd = &cobra.Command{
 			}
 		} else {
 			if result.Total == 0 {
+				if onlySuppressed {
+					fmt.Println("No suppressed findings")
+					return
+				}
 				if suppressedCount > 0 {
 					fmt.Printf("No findings (%d suppressed by exception; pass --include-suppressed to see)\n", suppressedCount)
 				} else {
@@ -862,11 +910,15 @@ var findingListCmd = &cobra.Command{
 			for _, f := range result.Findings {
 				badge := getSeverityBadge(f.Severity)
 				suppressTag := ""
-				if reason, ok := suppressedFor[f.ID]; ok {
-					suppressTag = " [SUPPRESSED: " + reason + "]"
+				if f.Suppression != nil {
+					suppressTag = " [SUPPRESSED: " + f.Suppression.Reason + "]"
 				}
 				fmt.Printf("%s [%s] %s - %s%s\n", badge, f.ID, f.Title, f.Status, suppressTag)
 				fmt.Printf("   Location: %s:%d\n", f.Location.File, f.Location.LineStart)
+				if sup := f.Suppression; sup != nil {
+					fmt.Printf("   Suppressed by %s (approved by %s, expires %s)\n",
+						sup.ExceptionID, sup.ApprovedBy, sup.Expires.Format("2006-01-02"))
+				}
 			}
 			fmt.Printf("\nTotal: %d findings", result.Total)
 			if suppressedCount > 0 {
@@ -890,6 +942,8 @@ var findingShowCmd = &cobra.Command{
 		}
 
 		store := finding.NewStore(p)
+		defer func() { _ = store.Close() }()
+
 		f, err := store.Read(args[0])
 		if err != nil {
 			exitError("%v", err)
@@ -995,7 +1049,11 @@ var findingExportCmd = &cobra.Command{
 	Short: "Export findings",
 	Long: `Export findings to various formats.
 
-Supported formats: sarif, json, md (markdown), html, csv`,
+Supported formats: sarif, json, md (markdown), html, csv
+
+Findings suppressed by .quokka/exceptions.yaml are included and marked with
+the exception ID, reason, approver and expiry (SARIF: suppressions[]). Pass
+--include-suppressed=false to drop them from the export.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		p, err := project.EnsureActive()
 		if err != nil {
@@ -1006,6 +1064,8 @@ Supported formats: sarif, json, md (markdown), html, csv`,
 		output, _ := cmd.Flags().GetString("output")
 
 		store := finding.NewStore(p)
+		defer func() { _ = store.Close() }()
+
 		result, err := store.List(nil)
 		if err != nil {
 			exitError("%v", err)
@@ -1021,6 +1081,23 @@ Supported formats: sarif, json, md (markdown), html, csv`,
 			result.Total = len(result.Findings)
 		}
 
+		// Mark findings matched by .quokka/exceptions.yaml so every format
+		// can show who suppressed them and until when. Pass
+		// --include-suppressed=false to leave them out of the export.
+		if _, err := exception.NewStore(p).Annotate(result.Findings); err != nil {
+			exitError("%v", err)
+		}
+		if includeSuppressed, _ := cmd.Flags().GetBool("include-suppressed"); !includeSuppressed {
+			kept := result.Findings[:0]
+			for _, f := range result.Findings {
+				if f.Suppression == nil {
+					kept = append(kept, f)
+				}
+			}
+			result.Findings = kept
+			result.Total = len(kept)
+		}
+
 		data, err := export.ExportFindings(result.Findings, format, p.Config.Name)
 		if err != nil {
 			exitError("%v", err)
@@ -1060,19 +1137,272 @@ Supported formats: sarif, json, md (markdown), html, csv`,
 	},
 }
 
+// findingDiffCmd represents the finding diff command
+var findingDiffCmd = &cobra.Command{
+	Use:   "diff --baseline <export.json|git-ref>",
+	Short: "Compare findings against a previous run",
+	Long: `Classify findings as new, unchanged or resolved relative to a baseline.
+
+Findings are matched by fingerprint (CWE, file, symbol and normalized
+title), so the same issue survives line shifts and rewording between runs.
+
+The baseline is either a JSON export from an earlier run
+(quokka finding export -f json -o baseline.json) or a git ref whose tree
+contains the project's .quokka/findings store (e.g. main, HEAD~1).
+
+By default the current findings are the ones in the store; findings with
+status fixed count as absent. Pass --current to compare two exports
+instead (e.g. tonight's run against last night's).
+
+Flags:
+  --baseline <path|ref>  Baseline export file or git ref (required)
+  --current <path>       Current JSON export (default: the finding store)
+  --mark-fixed           Set store findings matching resolved fingerprints
+                         to status fixed (only open/confirmed are touched)
+  -f, --format <fmt>     Emit an export with the classification instead of
+                         the text summary: json, sarif (baselineState), md
+  -o, --output <file>    Write the export to a file (default: stdout)
+
+Examples:
+  quokka finding diff --baseline exports/nightly-2024-05-01.json
+  quokka finding diff --baseline main --format sarif -o diff.sarif
+  quokka finding diff --baseline last.json --current tonight.json --mark-fixed`,
+	Run: func(cmd *cobra.Command, args []string) {
+		p, err := project.EnsureActive()
+		if err != nil {
+			exitError("%v", err)
+		}
+
+		baselineRef, _ := cmd.Flags().GetString("baseline")
+		if baselineRef == "" {
+			exitError("--baseline is required")
+		}
+		currentPath, _ := cmd.Flags().GetString("current")
+		markFixed, _ := cmd.Flags().GetBool("mark-fixed")
+		format, _ := cmd.Flags().GetString("format")
+		output, _ := cmd.Flags().GetString("output")
+
+		baseline, err := loadBaseline(p, baselineRef)
+		if err != nil {
+			exitError("%v", err)
+		}
+
+		store := finding.NewStore(p)
+		defer func() { _ = store.Close() }()
+
+		var current []finding.Finding
+		if currentPath != "" {
+			data, err := os.ReadFile(currentPath)
+			if err != nil {
+				exitError("failed to read current findings: %v", err)
+			}
+			if current, err = finding.ParseBaseline(data); err != nil {
+				exitError("%s: %v", currentPath, err)
+			}
+		} else {
+			result, err := store.List(nil)
+			if err != nil {
+				exitError("%v", err)
+			}
+			current = result.Findings
+		}
+
+		diff := finding.DiffBaseline(current, baseline)
+
+		var marked []string
+		if markFixed {
+			marked, err = store.MarkResolvedFixed(diff.Resolved, "quokka")
+			if err != nil {
+				exitError("%v", err)
+			}
+		}
+
+		if format != "" {
+			data, err := export.ExportFindings(diff.All(), format, p.Config.Name)
+			if err != nil {
+				exitError("%v", err)
+			}
+			if output == "" {
+				fmt.Print(string(data))
+				return
+			}
+			if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+				exitError("failed to create directory: %v", err)
+			}
+			if err := os.WriteFile(output, data, 0644); err != nil {
+				exitError("failed to write file: %v", err)
+			}
+			if !jsonOutput {
+				fmt.Printf("Wrote %s diff to %s\n", format, output)
+			}
+			return
+		}
+
+		counts := diff.Counts()
+		if jsonOutput {
+			if err := outputJSON(map[string]any{
+				"baseline":     baselineRef,
+				"new":          diff.New,
+				"unchanged":    diff.Unchanged,
+				"resolved":     diff.Resolved,
+				"counts":       counts,
+				"marked_fixed": marked,
+			}); err != nil {
+				exitError("failed to encode JSON: %v", err)
+			}
+			return
+		}
+
+		fmt.Printf("Baseline: %s\n", baselineRef)
+		fmt.Printf("New: %d  Unchanged: %d  Resolved: %d\n",
+			counts[finding.BaselineNew], counts[finding.BaselineUnchanged], counts[finding.BaselineResolved])
+		for _, group := range []struct {
+			label    string
+			findings []finding.Finding
+		}{
+			{"NEW", diff.New},
+			{"RESOLVED", diff.Resolved},
+		} {
+			if len(group.findings) == 0 {
+				continue
+			}
+			fmt.Printf("\n%s:\n", group.label)
+			for _, f := range group.findings {
+				fmt.Printf("  %s [%s] %s\n", getSeverityBadge(f.Severity), f.ID, f.Title)
+				fmt.Printf("     %s:%d\n", f.Location.File, f.Location.LineStart)
+			}
+		}
+		if len(marked) > 0 {
+			fmt.Printf("\nMarked fixed: %s\n", strings.Join(marked, ", "))
+		}
+	},
+}
+
+// loadBaseline reads baseline findings from a JSON export when ref names an
+// existing file, otherwise from the finding store as committed at git ref.
+func loadBaseline(p *project.Project, ref string) ([]finding.Finding, error) {
+	if info, err := os.Stat(ref); err == nil && !info.IsDir() {
+		data, err := os.ReadFile(ref)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read baseline: %w", err)
+		}
+		findings, err := finding.ParseBaseline(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", ref, err)
+		}
+		return findings, nil
+	}
+	return loadBaselineFromGit(p.RootPath, ref)
+}
+
+// loadBaselineFromGit reads the finding store as committed at the given ref.
+// The findings directory is copied out of git into a temporary directory and
+// opened with the backend project.yaml selected at that ref, so a SQLite
+// store is read the same way as a YAML one. A ref that predates the store
+// yields an empty baseline, which classifies everything as new.
+func loadBaselineFromGit(root, ref string) ([]finding.Finding, error) {
+	if err := exec.Command("git", "-C", root, "rev-parse", "--verify", "--quiet", ref+"^{commit}").Run(); err != nil {
+		return nil, fmt.Errorf("baseline %q is neither a file nor a git ref", ref)
+	}
+
+	backend := finding.DefaultBackend
+	configPath := filepath.ToSlash(filepath.Join(project.QuokkaDir, project.ProjectFile))
+	if data, err := exec.Command("git", "-C", root, "show", ref+":./"+configPath).Output(); err == nil {
+		var cfg project.ProjectConfig
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			return nil, fmt.Errorf("%s at %s: %w", configPath, ref, err)
+		}
+		if cfg.Findings.Backend != "" {
+			backend = cfg.Findings.Backend
+		}
+	}
+
+	findingsDir := filepath.ToSlash(filepath.Join(project.QuokkaDir, project.FindingsDir))
+	out, err := exec.Command("git", "-C", root, "ls-tree", "-r", "--name-only", ref, findingsDir+"/").Output()
+	if err != nil {
+		return nil, fmt.Errorf("git ls-tree %s failed: %w", ref, err)
+	}
+
+	tmp, err := os.MkdirTemp("", "quokka-baseline-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmp) }()
+
+	for _, path := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		rel := strings.TrimPrefix(path, findingsDir+"/")
+		if path == "" || strings.HasPrefix(rel, project.ExportsDir+"/") {
+			continue
+		}
+		data, err := exec.Command("git", "-C", root, "show", ref+":./"+path).Output()
+		if err != nil {
+			return nil, fmt.Errorf("git show %s:%s failed: %w", ref, path, err)
+		}
+		dst := filepath.Join(tmp, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create directory: %w", err)
+		}
+		if err := os.WriteFile(dst, data, 0o644); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", dst, err)
+		}
+	}
+
+	b, err := finding.OpenBackendAt(backend, tmp)
+	if err != nil {
+		return nil, fmt.Errorf("baseline at %s: %w", ref, err)
+	}
+	defer func() { _ = b.Close() }()
+	findings, err := b.Query(nil)
+	if err != nil {
+		return nil, fmt.Errorf("baseline at %s: %w", ref, err)
+	}
+	return findings, nil
+}
+
 // findingImportCmd represents the finding import command
 var findingImportCmd = &cobra.Command{
 	Use:   "import <file>",
 	Short: "Import findings",
-	Long:  `Import findings from a YAML file.`,
-	Args:  cobra.ExactArgs(1),
+	Long: `Import findings from a quokka YAML file, or in batch from a report
+produced by another SAST tool.
+
+Formats (--format):
+  yaml           One quokka-format finding (default)
+  sarif          SARIF 2.1.0 from any tool (CodeQL, opengrep, Snyk, ...)
+  semgrep-json   semgrep --json
+  bandit-json    bandit -f json
+  gosec-json     gosec -fmt=json
+
+Report findings are attributed in created_by to the tool — the SARIF driver
+name (e.g. codeql) or semgrep / bandit / gosec — unless --created-by names
+another. Each finding is fingerprinted and deduplicated against findings
+already in the store from the same creator, so re-importing the next CI
+run's report only adds what's new. Imported findings are open and flow
+through the same triage and validation as ` + "`quokka sast`" + ` results.
+
+Examples:
+  quokka finding import finding.yaml
+  quokka finding import --format sarif codeql-results.sarif
+  quokka finding import --format semgrep-json --created-by semgrep-ci semgrep.json
+  quokka finding import --format gosec-json --dry-run --json gosec.json`,
+	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		p, err := project.EnsureActive()
 		if err != nil {
 			exitError("%v", err)
 		}
 
+		format, _ := cmd.Flags().GetString("format")
+		if format = strings.ToLower(strings.TrimSpace(format)); format != "yaml" {
+			createdBy, _ := cmd.Flags().GetString("created-by")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			importReport(p, args[0], format, createdBy, dryRun)
+			return
+		}
+
 		store := finding.NewStore(p)
+		defer func() { _ = store.Close() }()
+
 		f, err := store.Import(args[0])
 		if err != nil {
 			exitError("%v", err)
@@ -1091,6 +1421,77 @@ var findingImportCmd = &cobra.Command{
 	},
 }
 
+// importReport batch-imports a third-party SAST report.
+func importReport(p *project.Project, path, format, createdBy string, dryRun bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		exitError("failed to read file: %v", err)
+	}
+	results, err := sast.ParseReport(format, data, sast.ReportOptions{Tool: createdBy, ProjectRoot: p.RootPath})
+	if err != nil {
+		exitError("%v", err)
+	}
+
+	store := finding.NewStore(p)
+	defer func() { _ = store.Close() }()
+
+	var created, duplicates, failed int
+	var ids []string
+	for i := range results {
+		f := &results[i]
+		if dryRun {
+			f.Fingerprint = finding.Fingerprint(*f)
+			continue
+		}
+		existed, err := store.Create(f)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "  skipped %q (%s:%d): %v\n", f.Title, f.Location.File, f.Location.LineStart, err)
+			failed++
+			continue
+		}
+		if existed {
+			duplicates++
+		} else {
+			created++
+			ids = append(ids, f.ID)
+		}
+	}
+
+	if jsonOutput {
+		out := map[string]any{
+			"format":     format,
+			"total":      len(results),
+			"created":    created,
+			"duplicates": duplicates,
+			"failed":     failed,
+			"ids":        ids,
+			"dry_run":    dryRun,
+		}
+		if dryRun {
+			out["findings"] = results
+		}
+		if err := outputJSON(out); err != nil {
+			exitError("failed to encode JSON: %v", err)
+		}
+		return
+	}
+
+	if dryRun {
+		fmt.Printf("%d finding(s) parsed from %s (dry run — nothing persisted)\n", len(results), path)
+		for _, f := range results {
+			fmt.Printf("  [%s] %s — %s:%d (%s)\n", f.Severity, f.Title, f.Location.File, f.Location.LineStart, f.CreatedBy)
+		}
+		return
+	}
+	fmt.Printf("Imported %s report %s\n", format, path)
+	fmt.Printf("  results:    %d\n", len(results))
+	fmt.Printf("  created:    %d\n", created)
+	fmt.Printf("  duplicates: %d (already in the store)\n", duplicates)
+	if failed > 0 {
+		fmt.Printf("  failed:     %d\n", failed)
+	}
+}
+
 // findingStatsCmd represents the finding stats command
 var findingStatsCmd = &cobra.Command{
 	Use:   "stats",
@@ -1103,6 +1504,8 @@ var findingStatsCmd = &cobra.Command{
 		}
 
 		store := finding.NewStore(p)
+		defer func() { _ = store.Close() }()
+
 		stats, err := store.Stats()
 		if err != nil {
 			exitError("%v", err)
@@ -1190,6 +1593,8 @@ var findingDeleteCmd = &cobra.Command{
 		}
 
 		store := finding.NewStore(p)
+		defer func() { _ = store.Close() }()
+
 		if err := store.Delete(args[0]); err != nil {
 			exitError("%v", err)
 		}
@@ -1208,6 +1613,179 @@ var findingDeleteCmd = &cobra.Command{
 	},
 }
 
+// findingHistoryCmd represents the finding history command
+var findingHistoryCmd = &cobra.Command{
+	Use:   "history <id>",
+	Short: "Show the change history of a finding",
+	Long: `Show every recorded change to a finding, oldest first: when it happened,
+who made it, the command or agent it came through, and the old and new
+value of each field. Status, severity, confidence, exploitability, fix
+priority, duplicate-of, CWE, title and location changes are tracked, as are
+appended notes.
+
+Examples:
+  quokka finding history FIND-012
+  quokka finding history FIND-012 --field status
+  quokka finding history FIND-012 --json`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		p, err := project.EnsureActive()
+		if err != nil {
+			exitError("%v", err)
+		}
+
+		store := finding.NewStore(p)
+		defer func() { _ = store.Close() }()
+		f, err := store.Read(args[0])
+		if err != nil {
+			exitError("%v", err)
+		}
+
+		field, _ := cmd.Flags().GetString("field")
+		events := make([]finding.HistoryEvent, 0, len(f.History))
+		for _, e := range f.History {
+			if field == "" || e.Field == field {
+				events = append(events, e)
+			}
+		}
+
+		if jsonOutput {
+			if err := outputJSON(map[string]interface{}{
+				"id":      f.ID,
+				"history": events,
+			}); err != nil {
+				exitError("failed to encode JSON: %v", err)
+			}
+			return
+		}
+
+		if len(events) == 0 {
+			fmt.Printf("No recorded history for %s\n", f.ID)
+			return
+		}
+		fmt.Printf("History of %s: %s\n\n", f.ID, f.Title)
+		for _, e := range events {
+			fmt.Printf("%s  %-22s %-16s %s\n",
+				e.Timestamp.Format("2006-01-02 15:04:05"), orDash(e.Actor), orDash(e.Source), describeHistoryEvent(e))
+		}
+	},
+}
+
+// describeHistoryEvent renders the change part of a history line.
+func describeHistoryEvent(e finding.HistoryEvent) string {
+	switch e.Field {
+	case finding.HistoryCreated:
+		return "created (status " + e.New + ")"
+	case finding.HistoryNote:
+		return "note: " + e.New
+	}
+	return fmt.Sprintf("%s: %s -> %s", e.Field, orDash(e.Old), orDash(e.New))
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// defaultActor is the history actor when --author is not given: the agent
+// running the command, else the local user.
+func defaultActor() string {
+	if env := os.Getenv("QUOKKA_AGENT_NAME"); env != "" {
+		return env
+	}
+	return defaultCreatedBy()
+}
+
+// findingMigrateCmd represents the finding migrate command
+var findingMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Move findings to another store backend",
+	Long: `Copy every finding from the current store backend into another one and
+switch project.yaml (findings.backend) to it. IDs, timestamps, notes and
+fingerprints are preserved.
+
+Backends:
+  yaml    One YAML file per finding under .quokka/findings/raw (default)
+  sqlite  .quokka/findings/findings.db, with indexed filters and atomic ID
+          allocation for parallel agents
+
+The target must be empty. The source is left in place unless --prune is
+given, so the YAML files can be kept as a readable export; migrating back
+with --to yaml rewrites them from the database.
+
+Examples:
+  quokka finding migrate
+  quokka finding migrate --prune
+  quokka finding migrate --to yaml`,
+	Run: func(cmd *cobra.Command, args []string) {
+		p, err := project.EnsureActive()
+		if err != nil {
+			exitError("%v", err)
+		}
+
+		to, _ := cmd.Flags().GetString("to")
+		prune, _ := cmd.Flags().GetBool("prune")
+		from := finding.ConfiguredBackend(p)
+		if to == from {
+			exitError("findings already use the %s backend", from)
+		}
+
+		src, err := finding.OpenBackend(p, from)
+		if err != nil {
+			exitError("%v", err)
+		}
+		defer func() { _ = src.Close() }()
+		dst, err := finding.OpenBackend(p, to)
+		if err != nil {
+			exitError("%v", err)
+		}
+		defer func() { _ = dst.Close() }()
+
+		ids, err := finding.Migrate(src, dst)
+		if err != nil {
+			// Undo a partial copy so the migration can simply be rerun.
+			for _, id := range ids {
+				_ = dst.Delete(id)
+			}
+			exitError("migration failed: %v", err)
+		}
+
+		p.Config.Findings.Backend = to
+		if err := p.Save(); err != nil {
+			exitError("%v", err)
+		}
+
+		pruned := 0
+		if prune {
+			for _, id := range ids {
+				if err := src.Delete(id); err != nil {
+					exitError("migrated, but failed to prune %s from the %s backend: %v", id, from, err)
+				}
+				pruned++
+			}
+		}
+
+		if jsonOutput {
+			if err := outputJSON(map[string]interface{}{
+				"success":  true,
+				"from":     from,
+				"to":       to,
+				"migrated": len(ids),
+				"pruned":   pruned,
+			}); err != nil {
+				exitError("failed to encode JSON: %v", err)
+			}
+		} else {
+			fmt.Printf("Migrated %d findings from %s to %s\n", len(ids), from, to)
+			if pruned > 0 {
+				fmt.Printf("Removed %d findings from the %s backend\n", pruned, from)
+			}
+		}
+	},
+}
+
 // getChangedFiles returns the list of files changed between base-ref and HEAD
 func getChangedFiles(baseRef string) (map[string]bool, error) {
 	cmd := exec.Command("git", "diff", "--name-only", baseRef+"...HEAD")
@@ -1259,13 +1837,17 @@ func init() {
 	findingCmd.AddCommand(findingUpdateCmd)
 	findingCmd.AddCommand(findingTriageCmd)
 	findingTriageCmd.Flags().String("plan", "", "Path to triage plan JSON (default: .quokka/review/triage-plan.json)")
-	findingTriageCmd.Flags().String("author", "", "Override the plan's `author` field for note attribution")
+	findingTriageCmd.Flags().String("author", "", "Override the plan's `author` field for note and history attribution")
+	findingTriageCmd.Flags().String("source", "finding triage", "Source recorded in the finding history")
 	findingCmd.AddCommand(findingListCmd)
 	findingCmd.AddCommand(findingShowCmd)
 	findingCmd.AddCommand(findingExportCmd)
+	findingCmd.AddCommand(findingDiffCmd)
 	findingCmd.AddCommand(findingImportCmd)
 	findingCmd.AddCommand(findingStatsCmd)
 	findingCmd.AddCommand(findingDeleteCmd)
+	findingCmd.AddCommand(findingMigrateCmd)
+	findingCmd.AddCommand(findingHistoryCmd)
 
 	findingCreateCmd.Flags().StringP("file", "f", "", "YAML file path (file mode); in --title flag mode this is the source file the finding refers to. Use '-' to read YAML from stdin.")
 	findingCreateCmd.Flags().String("title", "", "Finding title (triggers flag mode)")
@@ -1288,6 +1870,7 @@ func init() {
 	findingUpdateCmd.Flags().String("duplicate-of", "", "Canonical finding ID this is a duplicate of (e.g. FIND-001); typically paired with --status duplicate")
 	findingUpdateCmd.Flags().String("note", "", "Append a timestamped note to the finding (repeatable across updates)")
 	findingUpdateCmd.Flags().String("note-author", "", "Author for --note (default: \"user\")")
+	findingUpdateCmd.Flags().String("author", "", "Who is making the change, recorded in the finding history (default: $QUOKKA_AGENT_NAME, else human:$USER)")
 
 	findingListCmd.Flags().String("severity", "", "Filter by severity")
 	findingListCmd.Flags().String("status", "", "Filter by status")
@@ -1298,8 +1881,25 @@ func init() {
 	findingListCmd.Flags().String("diff", "", "Filter to findings in files changed since base ref (e.g., main)")
 	findingListCmd.Flags().String("created-by", "", "Filter by creator (e.g., opengrep, security-agent). Matches finding.created_by exactly.")
 	findingListCmd.Flags().Bool("include-suppressed", false, "Show findings that would be filtered by .quokka/exceptions.yaml")
+	findingListCmd.Flags().Bool("suppressed", false, "Show only findings suppressed by .quokka/exceptions.yaml")
 
 	findingExportCmd.Flags().StringP("format", "f", "json", "Export format (sarif, json, md, html, csv)")
 	findingExportCmd.Flags().StringP("output", "o", "", "Output file (default: stdout)")
 	findingExportCmd.Flags().String("diff", "", "Filter to findings in files changed since base ref (e.g., main)")
+	findingExportCmd.Flags().Bool("include-suppressed", true, "Include findings suppressed by .quokka/exceptions.yaml (marked as suppressed)")
+
+	findingDiffCmd.Flags().String("baseline", "", "Baseline JSON export or git ref (required)")
+	findingDiffCmd.Flags().String("current", "", "Current JSON export (default: the finding store)")
+	findingDiffCmd.Flags().Bool("mark-fixed", false, "Mark store findings whose fingerprint was resolved as fixed")
+	findingDiffCmd.Flags().StringP("format", "f", "", "Emit an export instead of the summary (json, sarif, md)")
+	findingDiffCmd.Flags().StringP("output", "o", "", "Output file for --format (default: stdout)")
+
+	findingImportCmd.Flags().StringP("format", "f", "yaml", "Input format (yaml, sarif, semgrep-json, bandit-json, gosec-json)")
+	findingImportCmd.Flags().String("created-by", "", "Attribute report findings to this tool (default: SARIF driver name, or semgrep/bandit/gosec)")
+	findingImportCmd.Flags().Bool("dry-run", false, "Parse the report and show what would be imported without persisting")
+
+	findingHistoryCmd.Flags().String("field", "", "Only show changes to this field (e.g. status)")
+
+	findingMigrateCmd.Flags().String("to", "sqlite", "Target backend (sqlite, yaml)")
+	findingMigrateCmd.Flags().Bool("prune", false, "Remove the migrated findings from the source backend")
 }
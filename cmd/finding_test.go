@@ -3,6 +3,8 @@ package cmd
 import (
 	"bytes"
 	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -137,7 +139,7 @@ func TestFindingNotesRoundTrip(t *testing.T) {
 		},
 		Description: "test",
 	}
-	if err := store.Create(f); err != nil {
+	if _, err := store.Create(f); err != nil {
 		t.Fatalf("create: %v", err)
 	}
 
@@ -208,7 +210,7 @@ func TestFindingDuplicateOfAndNotesPersist(t *testing.T) {
 			{Timestamp: time.Now(), Author: "alice", Text: "looks like a dup"},
 		},
 	}
-	if err := store.Create(f); err != nil {
+	if _, err := store.Create(f); err != nil {
 		t.Fatalf("create: %v", err)
 	}
 	loaded, err := store.Read(f.ID)
@@ -238,7 +240,7 @@ func makeFinding(t *testing.T, store *finding.Store, file string, line int, cwe,
 			LineStart: line,
 		},
 	}
-	if err := store.Create(f); err != nil {
+	if _, err := store.Create(f); err != nil {
 		t.Fatalf("create: %v", err)
 	}
 	return f
@@ -493,7 +495,7 @@ func TestTriagePlanApplyHappyPath(t *testing.T) {
 			CreatedBy: "injection-agent",
 			Location:  finding.Location{File: files[i], LineStart: i + 1},
 		}
-		if err := store.Create(f); err != nil {
+		if _, err := store.Create(f); err != nil {
 			t.Fatalf("seed Create: %v", err)
 		}
 	}
@@ -550,7 +552,7 @@ func TestTriagePlanApplyMixedErrors(t *testing.T) {
 	defer cleanup()
 	store := finding.NewStore(p)
 
-	if err := store.Create(&finding.Finding{
+	if _, err := store.Create(&finding.Finding{
 		Title:     "Test",
 		Severity:  finding.SeverityHigh,
 		CWE:       "CWE-89",
@@ -775,3 +777,143 @@ func TestRejectInvalidCreatedBy(t *testing.T) {
 		})
 	}
 }
+
+// TestLoadBaselineFromGit: the baseline is read from the finding store as
+// committed at the given ref, ignoring later working-tree changes.
+func TestLoadBaselineFromGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	p, cleanup := setupFindingTestProject(t)
+	defer cleanup()
+
+	store := finding.NewStore(p)
+	committed := makeFinding(t, store, "db.go", 10, "CWE-89", "high", "")
+	commitAll(t, p.RootPath)
+	makeFinding(t, store, "web.go", 20, "CWE-79", "medium", "")
+
+	got, err := loadBaseline(p, "HEAD")
+	if err != nil {
+		t.Fatalf("loadBaseline(HEAD): %v", err)
+	}
+	if len(got) != 1 || got[0].ID != committed.ID {
+		t.Fatalf("baseline = %+v, want only %s", got, committed.ID)
+	}
+
+	if _, err := loadBaseline(p, "no-such-ref"); err == nil {
+		t.Error("expected error for unknown ref")
+	}
+
+	diff := finding.DiffBaseline(mustList(t, store), got)
+	if len(diff.New) != 1 || len(diff.Unchanged) != 1 || len(diff.Resolved) != 0 {
+		t.Errorf("diff = new %d unchanged %d resolved %d, want 1/1/0",
+			len(diff.New), len(diff.Unchanged), len(diff.Resolved))
+	}
+}
+
+// TestLoadBaselineFromGit_SQLite: a committed SQLite store is opened through
+// its backend rather than parsed as YAML.
+func TestLoadBaselineFromGit_SQLite(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	p, cleanup := setupFindingTestProject(t)
+	defer cleanup()
+	p.Config.Findings.Backend = "sqlite"
+	if err := p.Save(); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	store := finding.NewStore(p)
+	committed := makeFinding(t, store, "db.go", 10, "CWE-89", "high", "")
+	if err := store.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	commitAll(t, p.RootPath)
+
+	got, err := loadBaseline(p, "HEAD")
+	if err != nil {
+		t.Fatalf("loadBaseline(HEAD): %v", err)
+	}
+	if len(got) != 1 || got[0].ID != committed.ID {
+		t.Fatalf("baseline = %+v, want only %s", got, committed.ID)
+	}
+}
+
+// commitAll commits the whole tree at root, initializing the repository
+// first if needed.
+func commitAll(t *testing.T, root string) {
+	t.Helper()
+	for _, args := range [][]string{{"init", "-q"}, {"add", "."}, {"commit", "-q", "-m", "baseline"}} {
+		cmd := exec.Command("git", append([]string{"-C", root}, args...)...)
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@t", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@t")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+}
+
+func mustList(t *testing.T, store *finding.Store) []finding.Finding {
+	t.Helper()
+	result, err := store.List(nil)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	return result.Findings
+}
+
+func TestDescribeHistoryEvent(t *testing.T) {
+	cases := []struct {
+		event finding.HistoryEvent
+		want  string
+	}{
+		{finding.HistoryEvent{Field: finding.HistoryCreated, New: "open"}, "created (status open)"},
+		{finding.HistoryEvent{Field: "status", Old: "open", New: "false_positive"}, "status: open -> false_positive"},
+		{finding.HistoryEvent{Field: "duplicate_of", New: "FIND-002"}, "duplicate_of: - -> FIND-002"},
+		{finding.HistoryEvent{Field: finding.HistoryNote, New: "sanitised upstream"}, "note: sanitised upstream"},
+	}
+	for _, c := range cases {
+		if got := describeHistoryEvent(c.event); got != c.want {
+			t.Errorf("describeHistoryEvent(%+v) = %q, want %q", c.event, got, c.want)
+		}
+	}
+}
+
+func TestImportReportDedupsAcrossRuns(t *testing.T) {
+	p, cleanup := setupFindingTestProject(t)
+	defer cleanup()
+
+	report := filepath.Join(p.RootPath, "gosec.json")
+	data := `{"Issues": [
+	  {"severity": "HIGH", "confidence": "HIGH", "cwe": {"id": "89"}, "rule_id": "G201",
+	   "details": "SQL string formatting", "file": "` + filepath.Join(p.RootPath, "db.go") + `", "line": "12"},
+	  {"severity": "MEDIUM", "confidence": "HIGH", "cwe": {"id": "22"}, "rule_id": "G304",
+	   "details": "Potential file inclusion via variable", "file": "` + filepath.Join(p.RootPath, "files.go") + `", "line": "30"}
+	]}`
+	if err := os.WriteFile(report, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	importReport(p, report, "gosec-json", "", false)
+	importReport(p, report, "gosec-json", "", false)
+
+	store := finding.NewStore(p)
+	findings := mustList(t, store)
+	if len(findings) != 2 {
+		t.Fatalf("expected 2 findings after re-import, got %d", len(findings))
+	}
+	for _, f := range findings {
+		if f.CreatedBy != "gosec" || f.Fingerprint == "" {
+			t.Errorf("finding %s: created_by=%q fingerprint=%q", f.ID, f.CreatedBy, f.Fingerprint)
+		}
+		if filepath.IsAbs(f.Location.File) {
+			t.Errorf("finding %s: path not relativized: %s", f.ID, f.Location.File)
+		}
+	}
+
+	// A different attribution is a different creator, so it isn't deduped.
+	importReport(p, report, "gosec-json", "gosec-nightly", false)
+	if got := len(mustList(t, store)); got != 4 {
+		t.Errorf("expected 4 findings with a second creator, got %d", got)
+	}
+}
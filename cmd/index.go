@@ -5,11 +5,14 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/diffsec/quokka/internal/embedding"
 	"github.com/diffsec/quokka/internal/project"
 	"github.com/diffsec/quokka/internal/semantic"
+	"github.com/diffsec/quokka/internal/vectordb"
 	"github.com/spf13/cobra"
 )
 
@@ -29,6 +32,7 @@ Index management commands:
   update   - Incrementally update changed files
   status   - Show index statistics
   watch    - Start file watcher for real-time updates
+  compact  - Reclaim space left by deleted vectors
   clear    - Clear the index`,
 }
 
@@ -41,7 +45,34 @@ var indexEnableCmd = &cobra.Command{
 Available providers:
   - ollama      (local, free, requires Ollama installed)
   - openai      (cloud, paid, requires OPENAI_API_KEY)
-  - huggingface (cloud, free tier, requires HF_API_KEY)`,
+  - huggingface (cloud, free tier, requires HF_API_KEY)
+  - local       (offline, in-process, no model download or service;
+                 lower recall than a learned model, best with --mode hybrid)
+
+The openai provider also talks to any OpenAI-compatible /v1/embeddings
+server (llama.cpp, vLLM, LiteLLM, Azure OpenAI) via --endpoint. Requests
+that hit 429/5xx are retried with exponential backoff, and the batch size
+shrinks automatically when the server rejects a batch as too large.
+
+The endpoint is saved in .quokka/project.yaml, which anyone who can open a
+pull request can edit, so the API key is only sent to a custom endpoint
+that is also exported as $QUOKKA_EMBEDDING_ENDPOINT. Header values may
+reference the API key variable and the variables listed, comma-separated,
+in $QUOKKA_EMBEDDING_HEADER_ENV.
+
+For very large repositories, --quantization int8 keeps 1-byte codes in
+memory instead of float32 vectors (about 4x less vector memory) and
+re-ranks the best candidates at full precision from disk. 'quokka index
+status' reports the savings and a recall estimate against exact search.
+
+Examples:
+  quokka index enable --provider local
+  quokka index enable --provider ollama --quantization int8
+  quokka index enable --provider openai --endpoint http://vllm:8000/v1 \
+      --model BAAI/bge-m3 --dimension 1024 --auth-scheme none
+  export QUOKKA_EMBEDDING_ENDPOINT=https://litellm.internal
+  quokka index enable --provider openai --endpoint https://litellm.internal \
+      --api-key-env LITELLM_KEY --header "X-Team: appsec" --rpm 300`,
 	Run: func(cmd *cobra.Command, args []string) {
 		p, err := project.EnsureActive()
 		if err != nil {
@@ -51,11 +82,25 @@ Available providers:
 		provider, _ := cmd.Flags().GetString("provider")
 		model, _ := cmd.Flags().GetString("model")
 		endpoint, _ := cmd.Flags().GetString("endpoint")
+		dimension, _ := cmd.Flags().GetInt("dimension")
+		apiKeyEnv, _ := cmd.Flags().GetString("api-key-env")
+		authScheme, _ := cmd.Flags().GetString("auth-scheme")
+		authHeader, _ := cmd.Flags().GetString("auth-header")
+		headerFlags, _ := cmd.Flags().GetStringArray("header")
+		rpm, _ := cmd.Flags().GetInt("rpm")
+		batchSize, _ := cmd.Flags().GetInt("batch-size")
+		quantizationFlag, _ := cmd.Flags().GetString("quantization")
+		rerankFactor, _ := cmd.Flags().GetInt("rerank-factor")
+
+		quantization, err := vectordb.ParseQuantization(quantizationFlag)
+		if err != nil {
+			exitError("%v", err)
+		}
 
 		// Get default config for provider
 		defaultConfig, ok := embedding.DefaultConfigs[provider]
 		if !ok {
-			exitError("unknown provider: %s (valid: ollama, openai, huggingface)", provider)
+			exitError("unknown provider: %s (valid: %s)", provider, strings.Join(embedding.AvailableProviders(), ", "))
 		}
 
 		// Build embedding config
@@ -75,6 +120,26 @@ Available providers:
 		if defaultConfig.APIKeyEnv != "" {
 			embConfig.APIKeyEnv = defaultConfig.APIKeyEnv
 		}
+		if apiKeyEnv != "" {
+			embConfig.APIKeyEnv = apiKeyEnv
+		}
+		if dimension > 0 {
+			embConfig.Dimension = dimension
+		}
+		embConfig.AuthScheme = authScheme
+		embConfig.AuthHeader = authHeader
+		embConfig.RequestsPerMinute = rpm
+		embConfig.BatchSize = batchSize
+		for _, h := range headerFlags {
+			name, value, ok := strings.Cut(h, ":")
+			if !ok || strings.TrimSpace(name) == "" {
+				exitError("invalid --header %q: expected \"Name: value\"", h)
+			}
+			if embConfig.Headers == nil {
+				embConfig.Headers = make(map[string]string)
+			}
+			embConfig.Headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+		}
 
 		// Update project config
 		p.Config.Index = project.IndexConfig{
@@ -88,6 +153,10 @@ Available providers:
 				"vendor/",
 				"node_modules/",
 			},
+			RerankFactor: rerankFactor,
+		}
+		if quantization != vectordb.QuantizationNone {
+			p.Config.Index.Quantization = string(quantization)
 		}
 
 		if err := p.Save(); err != nil {
@@ -107,6 +176,9 @@ Available providers:
 			fmt.Printf("Semantic indexing enabled\n")
 			fmt.Printf("  Provider: %s\n", provider)
 			fmt.Printf("  Model: %s\n", embConfig.Model)
+			if provider == "openai" && endpoint != "" && !strings.EqualFold(authScheme, embedding.AuthNone) && os.Getenv(embedding.EndpointEnv) != endpoint {
+				fmt.Printf("\nThe API key is only sent to %s once you run:\n  export %s=%s\n", endpoint, embedding.EndpointEnv, endpoint)
+			}
 			fmt.Println("\nRun 'quokka index build' to build the index")
 		}
 	},
@@ -316,8 +388,13 @@ var indexStatusCmd = &cobra.Command{
 			exitError("failed to get stats: %v", err)
 		}
 
+		quant, err := indexer.QuantizationStats()
+		if err != nil {
+			exitError("failed to get quantization stats: %v", err)
+		}
+
 		if jsonOutput {
-			if err := outputJSON(map[string]interface{}{
+			out := map[string]interface{}{
 				"enabled":         true,
 				"provider":        p.Config.Index.Embedding.Provider,
 				"model":           p.Config.Index.Embedding.Model,
@@ -325,7 +402,12 @@ var indexStatusCmd = &cobra.Command{
 				"total_files":     stats.TotalFiles,
 				"type_counts":     stats.TypeCounts,
 				"language_counts": stats.LanguageCounts,
-			}); err != nil {
+				"tombstones":      stats.Tombstones,
+			}
+			if quant != nil {
+				out["quantization"] = quant
+			}
+			if err := outputJSON(out); err != nil {
 				exitError("failed to encode JSON: %v", err)
 			}
 		} else {
@@ -337,6 +419,16 @@ var indexStatusCmd = &cobra.Command{
 			fmt.Printf("\nIndex Statistics:\n")
 			fmt.Printf("  Total Chunks: %d\n", stats.TotalChunks)
 			fmt.Printf("  Total Files: %d\n", stats.TotalFiles)
+			fmt.Printf("  Deleted Vectors: %d\n", stats.Tombstones)
+
+			if quant != nil {
+				fmt.Printf("\nQuantization: %s (re-rank x%d)\n", quant.Mode, quant.RerankFactor)
+				fmt.Printf("  Vector Memory: %s (full precision %s, %.0f%% saved)\n",
+					formatBytes(quant.QuantizedBytes), formatBytes(quant.FullBytes), quant.Savings*100)
+				if quant.Queries > 0 {
+					fmt.Printf("  Recall@%d: %.3f (%d sampled queries vs exact search)\n", quant.RecallK, quant.Recall, quant.Queries)
+				}
+			}
 
 			if len(stats.TypeCounts) > 0 {
 				fmt.Printf("\nBy Type:\n")
@@ -438,14 +530,92 @@ var indexClearCmd = &cobra.Command{
 	},
 }
 
+// indexCompactCmd represents the index compact command
+var indexCompactCmd = &cobra.Command{
+	Use:   "compact",
+	Short: "Reclaim space left by deleted vectors",
+	Long: `Rebuild the vector index without deleted vectors.
+
+Deleting or re-indexing a file leaves its old vectors behind as tombstones
+that still take memory and disk space. Compaction drops them, renumbers the
+remaining vectors densely and rebuilds the neighbor graph; chunk metadata is
+remapped in a single transaction. Update and watch compact automatically
+once tombstones reach 20% of the index; this command forces it.
+
+Examples:
+  quokka index compact
+  quokka index compact --json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		p, err := project.EnsureActive()
+		if err != nil {
+			exitError("%v", err)
+		}
+
+		if !p.Config.Index.Enabled {
+			exitError("semantic indexing is not enabled")
+		}
+
+		indexer, err := createIndexer(p)
+		if err != nil {
+			exitError("failed to create indexer: %v", err)
+		}
+		defer func() { _ = indexer.Close() }()
+
+		stats, err := indexer.Compact()
+		if err != nil {
+			exitError("failed to compact index: %v", err)
+		}
+
+		if jsonOutput {
+			if err := outputJSON(stats); err != nil {
+				exitError("failed to encode JSON: %v", err)
+			}
+			return
+		}
+
+		fmt.Printf("Index compacted in %s: %d vector slots -> %d vectors\n",
+			stats.Duration.Round(time.Millisecond), stats.Slots, stats.Vectors)
+		fmt.Printf("  Tombstones reclaimed: %d\n", stats.Tombstones)
+		if stats.Orphans > 0 {
+			fmt.Printf("  Unreferenced vectors dropped: %d\n", stats.Orphans)
+		}
+		if stats.Missing > 0 {
+			fmt.Printf("  Chunks without a vector: %d (run 'quokka index build --force' to re-embed)\n", stats.Missing)
+		}
+		if stats.Repaired > 0 {
+			fmt.Printf("  Graph nodes reconnected: %d\n", stats.Repaired)
+		}
+	},
+}
+
+// formatBytes renders a byte count with a binary unit
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
 // createIndexer creates an indexer from project config
 func createIndexer(p *project.Project) (*semantic.Indexer, error) {
 	embConfig := &embedding.Config{
-		Provider:  p.Config.Index.Embedding.Provider,
-		Model:     p.Config.Index.Embedding.Model,
-		Endpoint:  p.Config.Index.Embedding.Endpoint,
-		APIKeyEnv: p.Config.Index.Embedding.APIKeyEnv,
-		Dimension: p.Config.Index.Embedding.Dimension,
+		Provider:          p.Config.Index.Embedding.Provider,
+		Model:             p.Config.Index.Embedding.Model,
+		Endpoint:          p.Config.Index.Embedding.Endpoint,
+		APIKeyEnv:         p.Config.Index.Embedding.APIKeyEnv,
+		Dimension:         p.Config.Index.Embedding.Dimension,
+		BatchSize:         p.Config.Index.Embedding.BatchSize,
+		Headers:           p.Config.Index.Embedding.Headers,
+		AuthScheme:        p.Config.Index.Embedding.AuthScheme,
+		AuthHeader:        p.Config.Index.Embedding.AuthHeader,
+		MaxRetries:        p.Config.Index.Embedding.MaxRetries,
+		RequestsPerMinute: p.Config.Index.Embedding.RequestsPerMinute,
 	}
 
 	// Validate embedding config
@@ -459,6 +629,8 @@ func createIndexer(p *project.Project) (*semantic.Indexer, error) {
 		ChunkStrategy:   p.Config.Index.ChunkStrategy,
 		MaxChunkLines:   p.Config.Index.MaxChunkLines,
 		ExcludePatterns: p.Config.Index.ExcludePatterns,
+		Quantization:    p.Config.Index.Quantization,
+		RerankFactor:    p.Config.Index.RerankFactor,
 	}
 
 	return semantic.NewIndexer(p, config)
@@ -472,12 +644,22 @@ func init() {
 	indexCmd.AddCommand(indexUpdateCmd)
 	indexCmd.AddCommand(indexStatusCmd)
 	indexCmd.AddCommand(indexWatchCmd)
+	indexCmd.AddCommand(indexCompactCmd)
 	indexCmd.AddCommand(indexClearCmd)
 
 	// Enable flags
-	indexEnableCmd.Flags().StringP("provider", "p", "ollama", "Embedding provider (ollama, openai, huggingface)")
+	indexEnableCmd.Flags().StringP("provider", "p", "ollama", "Embedding provider (ollama, openai, huggingface, local)")
 	indexEnableCmd.Flags().StringP("model", "m", "", "Model name (provider-specific, uses default if not set)")
-	indexEnableCmd.Flags().StringP("endpoint", "e", "", "API endpoint (for ollama)")
+	indexEnableCmd.Flags().StringP("endpoint", "e", "", "API endpoint (ollama, or an OpenAI-compatible base URL for openai)")
+	indexEnableCmd.Flags().Int("dimension", 0, "Embedding dimension (required for non-OpenAI models behind --endpoint)")
+	indexEnableCmd.Flags().String("api-key-env", "", "Environment variable holding the API key")
+	indexEnableCmd.Flags().String("auth-scheme", "", "How the API key is sent: bearer (default), header or none")
+	indexEnableCmd.Flags().String("auth-header", "", "Header carrying the key for --auth-scheme header (default api-key)")
+	indexEnableCmd.Flags().StringArray("header", nil, "Extra request header \"Name: value\"; values may reference the API key variable and ${ENV_VARS} listed in $QUOKKA_EMBEDDING_HEADER_ENV (repeatable)")
+	indexEnableCmd.Flags().Int("rpm", 0, "Client-side rate limit in requests per minute (0 = unlimited)")
+	indexEnableCmd.Flags().Int("batch-size", 0, "Texts per embedding request (default depends on provider)")
+	indexEnableCmd.Flags().String("quantization", "none", "In-memory vector compression (none, int8)")
+	indexEnableCmd.Flags().Int("rerank-factor", 0, "Quantized candidates re-ranked at full precision per result (default 4)")
 
 	// Build flags
 	indexBuildCmd.Flags().Bool("force", false, "Force full rebuild")
@@ -0,0 +1,955 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/diffsec/quokka/internal/chunk"
+	"github.com/diffsec/quokka/internal/exception"
+	"github.com/diffsec/quokka/internal/finding"
+	"github.com/diffsec/quokka/internal/mcp"
+	"github.com/diffsec/quokka/internal/memory"
+	"github.com/diffsec/quokka/internal/navigate"
+	"github.com/diffsec/quokka/internal/project"
+	"github.com/diffsec/quokka/internal/rule"
+	"github.com/diffsec/quokka/internal/sast"
+	"github.com/diffsec/quokka/internal/semantic"
+	"github.com/diffsec/quokka/internal/think"
+	"github.com/diffsec/quokka/internal/vectordb"
+	"github.com/spf13/cobra"
+)
+
+// mcpCmd groups the Model Context Protocol commands
+var mcpCmd = &cobra.Command{
+	Use:   "mcp",
+	Short: "Model Context Protocol server",
+	Long: `Expose quokka to MCP clients (Claude Desktop, opencode, IDE agents) as
+typed tools instead of shell commands.`,
+}
+
+// mcpServeCmd represents the mcp serve command
+var mcpServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve quokka tools over MCP on stdio",
+	Long: `Run a Model Context Protocol server on stdin/stdout for the active
+project.
+
+Tools cover code navigation (read_file, search_code, symbols, definition,
+references, callers, callees), memories, finding CRUD, semantic search,
+the think analyses, and rule and exception management. Input schemas are
+generated from the same Go types the CLI uses, and arguments are checked
+against them: unknown argument names and invalid enum values are reported
+back to the model as tool errors.
+
+Language servers, the finding store and the semantic index are opened once
+and reused across calls, so navigation is much faster than running the
+equivalent CLI commands one by one.
+
+Writes follow the same opt-in as dispatched agents: rule_add, rule_annotate
+and rule_remove are only offered when allow_agent_writes.rules is set in
+.quokka/config.yaml, and exception_add / exception_remove only when
+allow_agent_writes.exceptions is. --read-only drops every tool that
+modifies project state.
+
+The protocol uses stdout exclusively; diagnostics go to stderr.
+
+Examples:
+  quokka mcp serve
+  quokka mcp serve --read-only
+  quokka mcp serve --list-tools
+
+  # Claude Desktop / Claude Code (.mcp.json)
+  {"mcpServers": {"quokka": {"command": "quokka", "args": ["mcp", "serve"]}}}
+
+  # opencode (opencode.json)
+  {"mcp": {"quokka": {"type": "local", "command": ["quokka", "mcp", "serve"]}}}`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		p, err := project.EnsureActive()
+		if err != nil {
+			exitError("%v", err)
+		}
+		readOnly, _ := cmd.Flags().GetBool("read-only")
+		listTools, _ := cmd.Flags().GetBool("list-tools")
+
+		session := newMCPSession(p)
+		defer session.Close()
+		server := session.server(readOnly)
+
+		if listTools {
+			tools := server.Tools()
+			if jsonOutput {
+				if err := outputJSON(tools); err != nil {
+					exitError("failed to encode JSON: %v", err)
+				}
+				return
+			}
+			for _, t := range tools {
+				fmt.Printf("%-22s %s\n", t.Name, firstLine(t.Description))
+			}
+			fmt.Printf("\n%d tools\n", len(tools))
+			return
+		}
+
+		// Everything the packages print with fmt.Printf would corrupt the
+		// JSON-RPC stream, so stdout is reserved for the protocol and the
+		// process-wide os.Stdout is pointed at stderr.
+		protocolOut := os.Stdout
+		os.Stdout = os.Stderr
+
+		ctx, cancel := context.WithCancel(cmd.Context())
+		defer cancel()
+		sigCh := make(chan os.Signal, 1)
+		signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+		go func() {
+			<-sigCh
+			cancel()
+			// Serve is blocked reading stdin; closing it unblocks the scan.
+			_ = os.Stdin.Close()
+		}()
+
+		fmt.Fprintf(os.Stderr, "quokka MCP server on stdio (%d tools, project %s)\n", len(server.Tools()), p.Config.Name)
+		if err := server.Serve(ctx, os.Stdin, protocolOut); err != nil && ctx.Err() == nil {
+			exitError("%v", err)
+		}
+	},
+}
+
+// mcpInstructions is sent to the client in the initialize handshake.
+const mcpInstructions = `quokka is a security review toolkit for the active project. Navigate code with read_file, search_code, symbols, definition, references, callers and callees; record and triage findings with the finding_* tools; keep project knowledge in memories; use the think_* tools to check coverage and validate findings. Paths are relative to the project root.`
+
+// mcpSession holds the resources the tools share for the lifetime of the
+// server. Navigators, extractors and the semantic indexer start language
+// servers or open indexes, so they are created on first use.
+type mcpSession struct {
+	p          *project.Project
+	findings   *finding.Store
+	memories   *memory.Store
+	nav        *navigate.Navigator
+	graph      *navigate.CallGraph
+	extractors map[navigate.ExtractionMethod]*navigate.UnifiedExtractor
+	indexer    *semantic.Indexer
+}
+
+func newMCPSession(p *project.Project) *mcpSession {
+	return &mcpSession{
+		p:          p,
+		findings:   trackRuleTriage(p, finding.NewStore(p)),
+		memories:   memory.NewStore(p),
+		extractors: map[navigate.ExtractionMethod]*navigate.UnifiedExtractor{},
+	}
+}
+
+// Close releases everything the session opened.
+func (s *mcpSession) Close() {
+	_ = s.findings.Close()
+	_ = s.memories.Close()
+	if s.nav != nil {
+		_ = s.nav.Close()
+	}
+	if s.graph != nil {
+		_ = s.graph.Close()
+	}
+	for _, e := range s.extractors {
+		_ = e.Close()
+	}
+	if s.indexer != nil {
+		_ = s.indexer.Close()
+	}
+}
+
+func (s *mcpSession) navigator() *navigate.Navigator {
+	if s.nav == nil {
+		s.nav = navigate.NewNavigator(s.p)
+	}
+	return s.nav
+}
+
+func (s *mcpSession) callGraph() *navigate.CallGraph {
+	if s.graph == nil {
+		s.graph = navigate.NewCallGraph(s.p)
+	}
+	return s.graph
+}
+
+func (s *mcpSession) extractor(method navigate.ExtractionMethod) *navigate.UnifiedExtractor {
+	if method == "" {
+		method = navigate.MethodAuto
+	}
+	e, ok := s.extractors[method]
+	if !ok {
+		e = navigate.NewUnifiedExtractor(s.p, method)
+		s.extractors[method] = e
+	}
+	return e
+}
+
+func (s *mcpSession) searcher() (*semantic.Searcher, error) {
+	if s.indexer == nil {
+		indexer, err := createIndexer(s.p)
+		if err != nil {
+			return nil, fmt.Errorf("semantic index unavailable (run 'quokka index build' and check index.embedding in the project config): %w", err)
+		}
+		s.indexer = indexer
+	}
+	return s.indexer.Searcher(), nil
+}
+
+// ---- tool inputs ----
+//
+// Where the CLI already has an options struct it is embedded as is, so a
+// new option shows up in the tool schema without touching this file.
+
+type mcpReadArgs struct {
+	Path      string `json:"path" desc:"Project-relative file path" mcp:"required"`
+	StartLine int    `json:"start_line,omitempty" desc:"First line to return (1-indexed); omit to read the whole file"`
+	EndLine   int    `json:"end_line,omitempty" desc:"Last line to return; defaults to the end of the file"`
+}
+
+type mcpListArgs struct {
+	Dir string `json:"dir,omitempty" desc:"Directory to list; defaults to the project root"`
+	navigate.ListOptions
+}
+
+type mcpFindArgs struct {
+	Pattern string `json:"pattern" desc:"Glob pattern, e.g. *.go or **/*.py" mcp:"required"`
+	navigate.FindOptions
+}
+
+type mcpSearchArgs struct {
+	Pattern string `json:"pattern" desc:"Text to search for (a regular expression when regex is true)" mcp:"required"`
+	navigate.SearchOptions
+}
+
+type mcpSymbolsArgs struct {
+	File   string                    `json:"file,omitempty" desc:"Extract the symbols defined in this file"`
+	Name   string                    `json:"name,omitempty" desc:"Find definitions of this symbol name across the project"`
+	Method navigate.ExtractionMethod `json:"method,omitempty"`
+}
+
+type mcpPositionArgs struct {
+	File   string                    `json:"file" desc:"Project-relative file path" mcp:"required"`
+	Line   int                       `json:"line" desc:"1-indexed line" mcp:"required"`
+	Column int                       `json:"column" desc:"1-indexed column of the symbol" mcp:"required"`
+	Method navigate.ExtractionMethod `json:"method,omitempty"`
+}
+
+func (a mcpPositionArgs) position() (navigate.CodeLocation, error) {
+	return navigate.ParsePosition(fmt.Sprintf("%s:%d:%d", a.File, a.Line, a.Column))
+}
+
+type mcpRefsArgs struct {
+	mcpPositionArgs
+	IncludeDeclaration *bool `json:"include_declaration,omitempty" desc:"Include the declaration itself (default true)"`
+}
+
+type mcpCallArgs struct {
+	Symbol string `json:"symbol" desc:"Function name, or Parent.name for a method" mcp:"required"`
+	navigate.CallOptions
+}
+
+type mcpNameArgs struct {
+	Name string `json:"name" mcp:"required"`
+}
+
+type mcpMemoryListArgs struct {
+	Type memory.MemoryType `json:"type,omitempty" desc:"Only list memories of this type"`
+}
+
+type mcpMemorySearchArgs struct {
+	Query string            `json:"query" mcp:"required"`
+	Type  memory.MemoryType `json:"type,omitempty" desc:"Only search memories of this type"`
+}
+
+type mcpFindingCreateArgs struct {
+	finding.Finding
+	Strict bool `json:"strict,omitempty" desc:"Reject the finding when its CWE is outside the creating agent's owns_cwes"`
+}
+
+type mcpIDArgs struct {
+	ID string `json:"id" desc:"Finding ID (FIND-XXX)" mcp:"required"`
+}
+
+type mcpFindingListArgs struct {
+	finding.FilterOptions
+	IncludeSuppressed bool `json:"include_suppressed,omitempty" desc:"Include findings suppressed by an exception"`
+}
+
+type mcpFindingUpdateArgs struct {
+	ID             string                 `json:"id" desc:"Finding ID (FIND-XXX)" mcp:"required"`
+	Status         finding.Status         `json:"status,omitempty"`
+	Severity       finding.Severity       `json:"severity,omitempty"`
+	Confidence     finding.Confidence     `json:"confidence,omitempty"`
+	Exploitability finding.Exploitability `json:"exploitability,omitempty"`
+	FixPriority    finding.FixPriority    `json:"fix_priority,omitempty"`
+	DuplicateOf    string                 `json:"duplicate_of,omitempty" desc:"ID of the finding this one duplicates"`
+	Note           string                 `json:"note,omitempty" desc:"Note to append to the finding"`
+	Author         string                 `json:"author,omitempty" desc:"Actor recorded in the finding history; defaults to $QUOKKA_AGENT_NAME, else human:$USER"`
+}
+
+type mcpSemanticArgs struct {
+	Query     string        `json:"query" desc:"Natural-language description of the code to find" mcp:"required"`
+	Mode      string        `json:"mode,omitempty" desc:"hybrid (default), vector or lexical"`
+	Limit     int           `json:"limit,omitempty" desc:"Maximum results (default 10)"`
+	MultiHop  bool          `json:"multi_hop,omitempty" desc:"Follow references from the first results"`
+	MaxHops   int           `json:"max_hops,omitempty" desc:"Maximum hops for multi_hop (default 3)"`
+	Threshold float32       `json:"threshold,omitempty" desc:"Minimum similarity score"`
+	File      string        `json:"file,omitempty" desc:"Only search chunks from this file"`
+	Type      string        `json:"type,omitempty" desc:"Only search chunks of this type, e.g. function or class"`
+	Language  string        `json:"language,omitempty" desc:"Only search chunks in this language"`
+	Timeout   time.Duration `json:"timeout,omitempty"`
+}
+
+type mcpRuleAddArgs struct {
+	Slug       string            `json:"slug" desc:"Rule name; stored as .quokka/rules/<slug>.yaml" mcp:"required"`
+	Content    string            `json:"content" desc:"opengrep rule YAML with a top-level rules: list" mcp:"required"`
+	CreatedBy  string            `json:"created_by,omitempty" desc:"Author, e.g. agent:injection-agent"`
+	CreatedFor string            `json:"created_for,omitempty" desc:"What the rule was written for, e.g. PR #482"`
+	Reasoning  string            `json:"reasoning,omitempty" desc:"Why the rule is needed"`
+	Fixtures   map[string]string `json:"fixtures" desc:"Test files by relative path, e.g. {\"app.py\": \"...\"}, marked with # ruleid: <id> above lines the rule must match and # ok: <id> above lines it must not" mcp:"required"`
+}
+
+type mcpRuleAnnotateArgs struct {
+	Slug    string       `json:"slug" mcp:"required"`
+	Verdict rule.Verdict `json:"verdict" mcp:"required"`
+	Note    string       `json:"note,omitempty"`
+}
+
+type mcpSlugArgs struct {
+	Slug string `json:"slug" mcp:"required"`
+}
+
+type mcpExceptionListArgs struct {
+	IncludeExpired bool `json:"include_expired,omitempty"`
+}
+
+type mcpExceptionAddArgs struct {
+	Fingerprint string `json:"fingerprint,omitempty" desc:"Suppress the finding with this fingerprint"`
+	PathGlob    string `json:"path_glob,omitempty" desc:"Suppress findings under this glob (requires cwe)"`
+	CWE         string `json:"cwe,omitempty"`
+	Reason      string `json:"reason" mcp:"required"`
+	Expires     string `json:"expires" desc:"YYYY-MM-DD or RFC3339; suppressions must be time-bounded" mcp:"required"`
+	ApprovedBy  string `json:"approved_by,omitempty" desc:"Approver, e.g. agent:triage-agent; defaults to human:$USER"`
+	ApprovedFor string `json:"approved_for,omitempty"`
+}
+
+type mcpExceptionRemoveArgs struct {
+	ID string `json:"id" desc:"Exception ID" mcp:"required"`
+}
+
+// ---- tools ----
+
+// server builds the MCP server with every tool the project allows.
+func (s *mcpSession) server(readOnly bool) *mcp.Server {
+	srv := mcp.NewServer("quokka", version)
+	srv.Instructions = mcpInstructions
+
+	r := srv.Reflector
+	mcp.RegisterEnum(r, finding.ValidSeverities...)
+	mcp.RegisterEnum(r, finding.ValidConfidences...)
+	mcp.RegisterEnum(r, finding.ValidStatuses...)
+	mcp.RegisterEnum(r, finding.ValidExploitabilities...)
+	mcp.RegisterEnum(r, finding.ValidFixPriorities...)
+	mcp.RegisterEnum(r, memory.ValidMemoryTypes...)
+	mcp.RegisterEnum(r, rule.ValidVerdicts...)
+	mcp.RegisterEnum(r, navigate.MethodAuto, navigate.MethodTreeSitter, navigate.MethodLSP, navigate.MethodRegex)
+	mcp.Describe[navigate.ExtractionMethod](r, "auto (default) uses a language server when one is installed and tree-sitter otherwise")
+
+	writes := s.p.Config.AllowAgentWrites
+	s.addNavigateTools(srv)
+	s.addMemoryTools(srv, !readOnly)
+	s.addFindingTools(srv, !readOnly)
+	s.addAnalysisTools(srv)
+	s.addRuleTools(srv, !readOnly && writes.Rules)
+	s.addExceptionTools(srv, !readOnly && writes.Exceptions)
+	return srv
+}
+
+func (s *mcpSession) addNavigateTools(srv *mcp.Server) {
+	mcp.AddTool(srv, mcp.ToolSpec{
+		Name:        "read_file",
+		Description: "Read a file, or a line range of it.",
+		ReadOnly:    true,
+	}, func(ctx context.Context, in mcpReadArgs) (any, error) {
+		reader := navigate.NewReader(s.p)
+		if in.StartLine > 0 || in.EndLine > 0 {
+			end := in.EndLine
+			if end == 0 {
+				end = math.MaxInt
+			}
+			return reader.ReadLines(in.Path, max(in.StartLine, 1), end)
+		}
+		return reader.Read(in.Path)
+	})
+
+	mcp.AddTool(srv, mcp.ToolSpec{
+		Name:        "list_files",
+		Description: "List a directory. Paths are relative to the project root.",
+		ReadOnly:    true,
+	}, func(ctx context.Context, in mcpListArgs) (any, error) {
+		dir := in.Dir
+		if dir == "" {
+			dir = "."
+		}
+		result, err := navigate.NewLister(s.p).List(dir, &in.ListOptions)
+		if err != nil {
+			return nil, err
+		}
+		normalizeListPaths(result, s.p.RootPath, dir)
+		return result, nil
+	})
+
+	mcp.AddTool(srv, mcp.ToolSpec{
+		Name:        "find_files",
+		Description: "Find files and directories whose path matches a glob.",
+		ReadOnly:    true,
+	}, func(ctx context.Context, in mcpFindArgs) (any, error) {
+		return navigate.NewFinder(s.p).Find(in.Pattern, &in.FindOptions)
+	})
+
+	mcp.AddTool(srv, mcp.ToolSpec{
+		Name:        "search_code",
+		Description: "Search file contents for text or a regular expression, optionally with context lines and a file glob.",
+		ReadOnly:    true,
+	}, func(ctx context.Context, in mcpSearchArgs) (any, error) {
+		return navigate.NewFinder(s.p).Search(in.Pattern, &in.SearchOptions)
+	})
+
+	mcp.AddTool(srv, mcp.ToolSpec{
+		Name:        "symbols",
+		Description: "List the functions, classes and methods defined in a file, or find the definitions of a name across the project. Pass exactly one of file or name.",
+		ReadOnly:    true,
+	}, func(ctx context.Context, in mcpSymbolsArgs) (any, error) {
+		switch {
+		case in.File != "" && in.Name != "":
+			return nil, fmt.Errorf("pass either file or name, not both")
+		case in.File != "":
+			return s.extractor(in.Method).Extract(in.File)
+		case in.Name != "":
+			return s.extractor(in.Method).Find(in.Name)
+		}
+		return nil, fmt.Errorf("one of file or name is required")
+	})
+
+	mcp.AddTool(srv, mcp.ToolSpec{
+		Name:        "definition",
+		Description: "Go to the definition of the symbol at a position. With a language server the result includes the type definition and hover text.",
+		ReadOnly:    true,
+	}, func(ctx context.Context, in mcpPositionArgs) (any, error) {
+		pos, err := in.position()
+		if err != nil {
+			return nil, err
+		}
+		return s.navigator().Definition(ctx, pos, in.Method)
+	})
+
+	mcp.AddTool(srv, mcp.ToolSpec{
+		Name:        "references",
+		Description: "Find every reference to the symbol at a position.",
+		ReadOnly:    true,
+	}, func(ctx context.Context, in mcpRefsArgs) (any, error) {
+		pos, err := in.position()
+		if err != nil {
+			return nil, err
+		}
+		includeDecl := in.IncludeDeclaration == nil || *in.IncludeDeclaration
+		return s.navigator().References(ctx, pos, in.Method, includeDecl)
+	})
+
+	mcp.AddTool(srv, mcp.ToolSpec{
+		Name:        "callers",
+		Description: "Depth-limited tree of the functions that call a symbol.",
+		ReadOnly:    true,
+	}, func(ctx context.Context, in mcpCallArgs) (any, error) {
+		return s.callGraph().Callers(ctx, in.Symbol, &in.CallOptions)
+	})
+
+	mcp.AddTool(srv, mcp.ToolSpec{
+		Name:        "callees",
+		Description: "Depth-limited tree of the functions a symbol calls. Set external to include library calls.",
+		ReadOnly:    true,
+	}, func(ctx context.Context, in mcpCallArgs) (any, error) {
+		return s.callGraph().Callees(ctx, in.Symbol, &in.CallOptions)
+	})
+}
+
+func (s *mcpSession) addMemoryTools(srv *mcp.Server, write bool) {
+	mcp.AddTool(srv, mcp.ToolSpec{
+		Name:        "memory_list",
+		Description: "List project memories (context, pattern and stack notes recorded during review).",
+		ReadOnly:    true,
+	}, func(ctx context.Context, in mcpMemoryListArgs) (any, error) {
+		return s.memories.List(in.Type)
+	})
+
+	mcp.AddTool(srv, mcp.ToolSpec{
+		Name:        "memory_read",
+		Description: "Read a memory by name.",
+		ReadOnly:    true,
+	}, func(ctx context.Context, in mcpNameArgs) (any, error) {
+		return s.memories.ReadByName(in.Name)
+	})
+
+	mcp.AddTool(srv, mcp.ToolSpec{
+		Name:        "memory_search",
+		Description: "Search memories by content, name or tags.",
+		ReadOnly:    true,
+	}, func(ctx context.Context, in mcpMemorySearchArgs) (any, error) {
+		if in.Type != "" {
+			return s.memories.SearchByType(in.Query, in.Type)
+		}
+		return s.memories.Search(in.Query)
+	})
+
+	if !write {
+		return
+	}
+
+	mcp.AddTool(srv, mcp.ToolSpec{
+		Name:        "memory_write",
+		Description: "Create a memory, or replace the content of an existing one with the same name (its type is kept).",
+		Required:    []string{"name", "content"},
+		Omit:        []string{"created_at", "updated_at"},
+	}, func(ctx context.Context, in memory.Memory) (any, error) {
+		if in.Type == "" {
+			in.Type = memory.MemoryTypeContext
+		}
+		existing, _ := s.memories.ReadByName(in.Name)
+		action := "created"
+		var err error
+		if existing != nil {
+			in.Type = existing.Type
+			action = "updated"
+			err = s.memories.Update(&in)
+		} else {
+			err = s.memories.Create(&in)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"name": in.Name, "type": in.Type, "action": action}, nil
+	})
+
+	mcp.AddTool(srv, mcp.ToolSpec{
+		Name:        "memory_delete",
+		Description: "Delete a memory by name.",
+		Destructive: true,
+	}, func(ctx context.Context, in mcpNameArgs) (any, error) {
+		if err := s.memories.DeleteByName(in.Name); err != nil {
+			return nil, err
+		}
+		return map[string]any{"name": in.Name, "action": "deleted"}, nil
+	})
+}
+
+func (s *mcpSession) addFindingTools(srv *mcp.Server, write bool) {
+	mcp.AddTool(srv, mcp.ToolSpec{
+		Name:        "finding_get",
+		Description: "Read a finding, including its notes and history.",
+		ReadOnly:    true,
+	}, func(ctx context.Context, in mcpIDArgs) (any, error) {
+		return s.findings.Read(in.ID)
+	})
+
+	mcp.AddTool(srv, mcp.ToolSpec{
+		Name:        "finding_list",
+		Description: "List findings, filtered by severity, status, CWE, file, tag or creator. Findings suppressed by an exception are hidden unless include_suppressed is set.",
+		ReadOnly:    true,
+	}, func(ctx context.Context, in mcpFindingListArgs) (any, error) {
+		result, err := s.findings.List(&in.FilterOptions)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := exception.NewStore(s.p).Annotate(result.Findings); err != nil {
+			return nil, err
+		}
+		if !in.IncludeSuppressed {
+			kept := result.Findings[:0]
+			for _, f := range result.Findings {
+				if f.Suppression == nil {
+					kept = append(kept, f)
+				}
+			}
+			result.Findings = kept
+			result.Total = len(kept)
+		}
+		return result, nil
+	})
+
+	mcp.AddTool(srv, mcp.ToolSpec{
+		Name:        "finding_stats",
+		Description: "Counts of findings by severity, status, confidence and CWE.",
+		ReadOnly:    true,
+	}, func(ctx context.Context, in struct{}) (any, error) {
+		return s.findings.Stats()
+	})
+
+	if !write {
+		return
+	}
+
+	mcp.AddTool(srv, mcp.ToolSpec{
+		Name: "finding_create",
+		Description: "Record a security finding. created_by must be your agent name (or human:<id>); it defaults to $QUOKKA_AGENT_NAME. " +
+			"A finding with the same fingerprint from the same creator is not duplicated.",
+		Required: []string{"title", "severity", "confidence", "location"},
+		Omit: []string{"id", "fingerprint", "created_at", "updated_at", "history", "notes",
+			"reviewed_by", "duplicate_of", "baseline_state", "suppressed_by"},
+	}, func(ctx context.Context, in mcpFindingCreateArgs) (any, error) {
+		f := in.Finding
+		if f.CreatedBy == "" {
+			f.CreatedBy = os.Getenv("QUOKKA_AGENT_NAME")
+		}
+		eff, rejectReason, fellBack, envHint := enforceCreatedBy(s.p, f.CreatedBy)
+		if fellBack {
+			fmt.Fprintf(os.Stderr, "warning: created_by %q rejected (%s); using QUOKKA_AGENT_NAME=%q instead.\n",
+				f.CreatedBy, rejectReason, envHint)
+			f.CreatedBy = eff
+		} else if rejectReason != "" {
+			return nil, fmt.Errorf("created_by %q is invalid: %s. Use your agent name (e.g. injection-agent) or human:<id>", f.CreatedBy, rejectReason)
+		}
+		if err := validateOwnsCWEs(s.p, &f, in.Strict); err != nil {
+			return nil, err
+		}
+		if _, err := s.findings.Create(&f); err != nil {
+			return nil, err
+		}
+		return map[string]any{"id": f.ID, "finding": f}, nil
+	})
+
+	mcp.AddTool(srv, mcp.ToolSpec{
+		Name:        "finding_update",
+		Description: "Change a finding's status, severity, confidence, exploitability or fix priority, mark it a duplicate, or append a note. Every change is recorded in its history.",
+	}, func(ctx context.Context, in mcpFindingUpdateArgs) (any, error) {
+		author := in.Author
+		if author == "" {
+			author = defaultActor()
+		}
+		f, err := s.findings.Modify(in.ID, finding.Actor{Name: author, Source: "mcp finding_update"}, func(f *finding.Finding) error {
+			if in.Status != "" {
+				f.Status = in.Status
+			}
+			if in.Severity != "" {
+				f.Severity = in.Severity
+			}
+			if in.Confidence != "" {
+				f.Confidence = in.Confidence
+			}
+			if in.Exploitability != "" {
+				f.Exploitability = in.Exploitability
+			}
+			if in.FixPriority != "" {
+				f.FixPriority = in.FixPriority
+			}
+			if in.DuplicateOf != "" {
+				f.DuplicateOf = in.DuplicateOf
+			}
+			if in.Note != "" {
+				f.Notes = append(f.Notes, finding.FindingNote{Timestamp: time.Now(), Author: author, Text: in.Note})
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"finding": f}, nil
+	})
+
+	mcp.AddTool(srv, mcp.ToolSpec{
+		Name:        "finding_delete",
+		Description: "Delete a finding. Prefer finding_update with status false_positive, which keeps the audit trail.",
+		Destructive: true,
+	}, func(ctx context.Context, in mcpIDArgs) (any, error) {
+		if err := s.findings.Delete(in.ID); err != nil {
+			return nil, err
+		}
+		return map[string]any{"id": in.ID, "action": "deleted"}, nil
+	})
+}
+
+func (s *mcpSession) addAnalysisTools(srv *mcp.Server) {
+	mcp.AddTool(srv, mcp.ToolSpec{
+		Name:        "semantic_search",
+		Description: "Search the semantic code index with a natural-language query or exact identifiers. Hybrid mode (default) fuses vector and keyword rankings. Requires 'quokka index build'.",
+		ReadOnly:    true,
+	}, func(ctx context.Context, in mcpSemanticArgs) (any, error) {
+		searcher, err := s.searcher()
+		if err != nil {
+			return nil, err
+		}
+		opts := semantic.DefaultSearchOptions()
+		mode, err := semantic.ParseSearchMode(in.Mode)
+		if err != nil {
+			return nil, err
+		}
+		opts.Mode = mode
+		if in.Limit > 0 {
+			opts.Limit = in.Limit
+		}
+		if in.MaxHops > 0 {
+			opts.MaxHops = in.MaxHops
+		}
+		if in.Timeout > 0 {
+			opts.TimeLimit = in.Timeout
+		}
+		opts.MultiHop = in.MultiHop
+		opts.Threshold = in.Threshold
+		if in.Type != "" || in.File != "" || in.Language != "" || in.Threshold > 0 {
+			opts.Filter = &vectordb.Filter{MinScore: in.Threshold}
+			if in.Type != "" {
+				opts.Filter.Types = []chunk.ChunkType{chunk.ChunkType(in.Type)}
+			}
+			if in.File != "" {
+				opts.Filter.Files = []string{in.File}
+			}
+			if in.Language != "" {
+				opts.Filter.Languages = []string{in.Language}
+			}
+		}
+		return searcher.Search(ctx, in.Query, opts)
+	})
+
+	mcp.AddTool(srv, mcp.ToolSpec{
+		Name:        "think_collected",
+		Description: "Audit the memory store against what the configured agents expect: missing, orphaned and cross-referenced memories.",
+		ReadOnly:    true,
+	}, func(ctx context.Context, in think.CollectedOptions) (any, error) {
+		return think.AnalyzeCollected(s.p, in)
+	})
+
+	mcp.AddTool(srv, mcp.ToolSpec{
+		Name:        "think_adherence",
+		Description: "Check that an agent's findings fall within its declared CWE scope. Without agent, every agent with findings is checked.",
+		ReadOnly:    true,
+	}, func(ctx context.Context, in think.AdherenceOptions) (any, error) {
+		return think.AnalyzeAdherence(s.p, in)
+	})
+
+	mcp.AddTool(srv, mcp.ToolSpec{
+		Name:        "think_done",
+		Description: "Score an agent's completeness against its declared CWEs and required memories.",
+		Required:    []string{"agent"},
+		ReadOnly:    true,
+	}, func(ctx context.Context, in think.DoneOptions) (any, error) {
+		return think.AnalyzeDone(s.p, in)
+	})
+
+	mcp.AddTool(srv, mcp.ToolSpec{
+		Name:        "think_next",
+		Description: "Rank the next review actions from the current findings and memories.",
+		ReadOnly:    true,
+	}, func(ctx context.Context, in think.NextOptions) (any, error) {
+		return think.AnalyzeNext(s.p, in)
+	})
+
+	mcp.AddTool(srv, mcp.ToolSpec{
+		Name:        "think_hypothesis",
+		Description: "Generate ranked CWE hypotheses from the tech stack and memories.",
+		ReadOnly:    true,
+	}, func(ctx context.Context, in think.HypothesisOptions) (any, error) {
+		return think.AnalyzeHypothesis(s.p, in)
+	})
+
+	mcp.AddTool(srv, mcp.ToolSpec{
+		Name:        "think_validate",
+		Description: "Validate a finding against its code context: source, sink and guards around the cited line, with a verdict.",
+		Required:    []string{"finding_id"},
+		ReadOnly:    true,
+	}, func(ctx context.Context, in think.ValidateOptions) (any, error) {
+		return think.AnalyzeValidate(s.p, in)
+	})
+
+	mcp.AddTool(srv, mcp.ToolSpec{
+		Name: "think_dataflow",
+		Description: "Trace source-to-sink chains. engine is regex (default, intra-file) or taint (cross-file, via tree-sitter). " +
+			"sink overrides sink_classes; from_finding loads source, sink and file from a finding.",
+		ReadOnly: true,
+	}, func(ctx context.Context, in think.DataflowOptions) (any, error) {
+		return think.AnalyzeDataflow(s.p, in)
+	})
+}
+
+// writeMCPFixtures writes rule_add fixtures to a new temporary directory,
+// rejecting paths that would land outside it
+func writeMCPFixtures(files map[string]string) (string, error) {
+	if len(files) == 0 {
+		return "", fmt.Errorf("fixtures are required: the rule must pass annotated test files before it is added")
+	}
+	dir, err := os.MkdirTemp("", "quokka-rule-tests-*")
+	if err != nil {
+		return "", err
+	}
+	for name, content := range files {
+		if !filepath.IsLocal(name) {
+			_ = os.RemoveAll(dir)
+			return "", fmt.Errorf("fixture path %q must be relative and stay inside the fixture directory", name)
+		}
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			_ = os.RemoveAll(dir)
+			return "", err
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			_ = os.RemoveAll(dir)
+			return "", err
+		}
+	}
+	return dir, nil
+}
+
+// ruleTestFailures lists a failed test run's missed and unexpected lines
+func ruleTestFailures(res *rule.TestResult) string {
+	var parts []string
+	for _, e := range res.Missed {
+		parts = append(parts, fmt.Sprintf("missed %s:%d (%s should match)", e.File, e.Line, e.RuleID))
+	}
+	for _, m := range res.Unexpected {
+		parts = append(parts, fmt.Sprintf("unexpected %s:%d (%s matched)", m.File, m.Line, m.RuleID))
+	}
+	return strings.Join(parts, "; ")
+}
+
+func (s *mcpSession) addRuleTools(srv *mcp.Server, write bool) {
+	mcp.AddTool(srv, mcp.ToolSpec{
+		Name:        "rule_list",
+		Description: "List project-local opengrep rules with their provenance and judge verdicts.",
+		ReadOnly:    true,
+	}, func(ctx context.Context, in struct{}) (any, error) {
+		return rule.NewStore(s.p).List()
+	})
+
+	if !write {
+		return
+	}
+
+	mcp.AddTool(srv, mcp.ToolSpec{
+		Name:        "rule_add",
+		Description: "Add a project-local opengrep rule. It is only added if it passes its fixtures; quokka sast picks it up on the next run.",
+	}, func(ctx context.Context, in mcpRuleAddArgs) (any, error) {
+		createdBy := in.CreatedBy
+		if createdBy == "" {
+			createdBy = defaultCreatedBy()
+		}
+		meta := rule.Meta{
+			CreatedBy:  createdBy,
+			CreatedAt:  time.Now().UTC(),
+			CreatedFor: in.CreatedFor,
+			Reasoning:  in.Reasoning,
+		}
+		fixtureDir, err := writeMCPFixtures(in.Fixtures)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = os.RemoveAll(fixtureDir) }()
+		res, err := sast.AddRule(sast.Scanner{}, rule.NewStore(s.p), in.Slug, []byte(in.Content), meta, fixtureDir)
+		if err != nil {
+			if res != nil && !res.Passed() {
+				return nil, fmt.Errorf("%w: %s", err, ruleTestFailures(res))
+			}
+			return nil, err
+		}
+		return map[string]any{"slug": in.Slug, "created_by": createdBy, "tests": res}, nil
+	})
+
+	mcp.AddTool(srv, mcp.ToolSpec{
+		Name:        "rule_annotate",
+		Description: "Record a judge verdict on a rule. retire disables it for future scans; keep promotes a draft once it passes its fixtures.",
+	}, func(ctx context.Context, in mcpRuleAnnotateArgs) (any, error) {
+		res, err := sast.AnnotateRule(sast.Scanner{}, rule.NewStore(s.p), in.Slug, in.Verdict, in.Note)
+		if err != nil {
+			if res != nil && !res.Passed() {
+				return nil, fmt.Errorf("%w: %s", err, ruleTestFailures(res))
+			}
+			return nil, err
+		}
+		out := map[string]any{"slug": in.Slug, "verdict": in.Verdict, "note": in.Note}
+		if res != nil {
+			out["tests"] = res
+		}
+		return out, nil
+	})
+
+	mcp.AddTool(srv, mcp.ToolSpec{
+		Name:        "rule_remove",
+		Description: "Remove a rule and its metadata sidecar.",
+		Destructive: true,
+	}, func(ctx context.Context, in mcpSlugArgs) (any, error) {
+		if err := rule.NewStore(s.p).Remove(in.Slug); err != nil {
+			return nil, err
+		}
+		return map[string]any{"removed": in.Slug}, nil
+	})
+}
+
+func (s *mcpSession) addExceptionTools(srv *mcp.Server, write bool) {
+	mcp.AddTool(srv, mcp.ToolSpec{
+		Name:        "exception_list",
+		Description: "List finding suppressions from .quokka/exceptions.yaml.",
+		ReadOnly:    true,
+	}, func(ctx context.Context, in mcpExceptionListArgs) (any, error) {
+		list, err := exception.NewStore(s.p).List(in.IncludeExpired)
+		if err != nil {
+			return nil, err
+		}
+		exception.SortByExpires(list)
+		return list, nil
+	})
+
+	if !write {
+		return
+	}
+
+	mcp.AddTool(srv, mcp.ToolSpec{
+		Name:        "exception_add",
+		Description: "Suppress a finding by fingerprint, or a CWE under a path glob, until an expiry date.",
+	}, func(ctx context.Context, in mcpExceptionAddArgs) (any, error) {
+		expires, err := exception.ParseExpires(in.Expires)
+		if err != nil {
+			return nil, fmt.Errorf("invalid expires: %w", err)
+		}
+		approvedBy := in.ApprovedBy
+		if approvedBy == "" {
+			approvedBy = defaultApprovedBy()
+		}
+		return exception.NewStore(s.p).Add(exception.Exception{
+			Fingerprint: strings.TrimSpace(in.Fingerprint),
+			PathGlob:    strings.TrimSpace(in.PathGlob),
+			CWE:         strings.ToUpper(strings.TrimSpace(in.CWE)),
+			Reason:      in.Reason,
+			Expires:     expires,
+			ApprovedBy:  approvedBy,
+			ApprovedFor: in.ApprovedFor,
+		})
+	})
+
+	mcp.AddTool(srv, mcp.ToolSpec{
+		Name:        "exception_remove",
+		Description: "Remove a suppression by ID.",
+		Destructive: true,
+	}, func(ctx context.Context, in mcpExceptionRemoveArgs) (any, error) {
+		if err := exception.NewStore(s.p).Remove(in.ID); err != nil {
+			return nil, err
+		}
+		return map[string]any{"removed": in.ID}, nil
+	})
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
+
+func init() {
+	rootCmd.AddCommand(mcpCmd)
+	mcpCmd.AddCommand(mcpServeCmd)
+
+	mcpServeCmd.Flags().Bool("read-only", false, "Only offer tools that don't modify project state")
+	mcpServeCmd.Flags().Bool("list-tools", false, "Print the tools that would be offered and exit")
+}
@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/diffsec/quokka/internal/memory"
 	"github.com/diffsec/quokka/internal/navigate"
 	"github.com/diffsec/quokka/internal/project"
 	"github.com/spf13/cobra"
@@ -420,12 +421,359 @@ Use --method to specify extraction method:
 	},
 }
 
+// navigateCmd groups the cross-file code navigation commands
+var navigateCmd = &cobra.Command{
+	Use:   "navigate",
+	Short: "Navigate code across files",
+	Long: `Navigate code across files: call graphs, definitions, references and
+HTTP routes.
+
+Answers come from a language server (gopls, pyright, ...) when one is
+installed for the file, and from tree-sitter otherwise (see --method).`,
+}
+
+// definitionCmd represents the navigate definition command
+var definitionCmd = &cobra.Command{
+	Use:   "definition <file>:<line>:<col>",
+	Short: "Go to the definition of the symbol at a position",
+	Long: `Show where the symbol at a position is defined.
+
+Lines and columns are 1-indexed. With a language server the result also
+includes the symbol's type definition and hover text (signature and docs).
+The tree-sitter fallback matches definitions by name, preferring the
+qualifier's class or module and then the same file.
+
+Examples:
+  quokka navigate definition api/handlers.go:42:17
+  quokka navigate definition app/views.py:10:12 --method treesitter --json`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		p, err := project.EnsureActive()
+		if err != nil {
+			exitError("%v", err)
+		}
+
+		pos, err := navigate.ParsePosition(args[0])
+		if err != nil {
+			exitError("%v", err)
+		}
+		methodStr, _ := cmd.Flags().GetString("method")
+
+		nav := navigate.NewNavigator(p)
+		defer func() { _ = nav.Close() }()
+
+		result, err := nav.Definition(cmd.Context(), pos, navigate.ExtractionMethod(methodStr))
+		if err != nil {
+			exitError("%v", err)
+		}
+
+		if jsonOutput {
+			if err := outputJSON(result); err != nil {
+				exitError("failed to encode JSON: %v", err)
+			}
+			return
+		}
+
+		fmt.Printf("%s at %s:%d:%d (via %s)\n", orDash(result.Symbol), result.Position.File, result.Position.Line, result.Position.Column, result.Method)
+		if len(result.Definitions) == 0 {
+			fmt.Println("\nNo definition found")
+		} else {
+			fmt.Println("\nDefinition:")
+			printCodeLocations(result.Definitions)
+		}
+		if len(result.TypeDefinitions) > 0 {
+			fmt.Println("\nType definition:")
+			printCodeLocations(result.TypeDefinitions)
+		}
+		if result.Hover != "" {
+			fmt.Printf("\nHover:\n%s\n", result.Hover)
+		}
+		for _, note := range result.Notes {
+			fmt.Printf("\nNote: %s\n", note)
+		}
+	},
+}
+
+// refsCmd represents the navigate refs command
+var refsCmd = &cobra.Command{
+	Use:   "refs <file>:<line>:<col>",
+	Short: "Find references to the symbol at a position",
+	Long: `Find every reference to the symbol at a position.
+
+Unlike 'symbols refs', which is a text search for a name, this resolves the
+symbol first. Without a language server the tree-sitter fallback reports
+the call sites of the matching functions.
+
+Examples:
+  quokka navigate refs internal/auth/token.go:18:6
+  quokka navigate refs app/models.py:33:9 --include-declaration=false --json`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		p, err := project.EnsureActive()
+		if err != nil {
+			exitError("%v", err)
+		}
+
+		pos, err := navigate.ParsePosition(args[0])
+		if err != nil {
+			exitError("%v", err)
+		}
+		methodStr, _ := cmd.Flags().GetString("method")
+		includeDecl, _ := cmd.Flags().GetBool("include-declaration")
+
+		nav := navigate.NewNavigator(p)
+		defer func() { _ = nav.Close() }()
+
+		result, err := nav.References(cmd.Context(), pos, navigate.ExtractionMethod(methodStr), includeDecl)
+		if err != nil {
+			exitError("%v", err)
+		}
+
+		if jsonOutput {
+			if err := outputJSON(result); err != nil {
+				exitError("failed to encode JSON: %v", err)
+			}
+			return
+		}
+
+		printCodeLocations(result.References)
+		fmt.Printf("\nFound: %d references to %s (via %s)\n", result.Total, orDash(result.Symbol), result.Method)
+		for _, note := range result.Notes {
+			fmt.Printf("Note: %s\n", note)
+		}
+	},
+}
+
+func printCodeLocations(locs []navigate.CodeLocation) {
+	for _, loc := range locs {
+		fmt.Printf("%s:%d:%d: %s\n", loc.File, loc.Line, loc.Column, loc.Text)
+	}
+}
+
+// callersCmd represents the navigate callers command
+var callersCmd = &cobra.Command{
+	Use:   "callers <symbol>",
+	Short: "Show the functions that call a symbol",
+	Long: `Show a depth-limited tree of the functions that call a symbol.
+
+The symbol is a function name or Parent.name for a method. Every
+definition with that name gets its own tree; use --file to pick one.
+
+Examples:
+  quokka navigate callers run_query
+  quokka navigate callers UserRepo.find --depth 5
+  quokka navigate callers handler --file api/routes.py --json`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runCallGraph(cmd, args[0], navigate.DirectionCallers)
+	},
+}
+
+// calleesCmd represents the navigate callees command
+var calleesCmd = &cobra.Command{
+	Use:   "callees <symbol>",
+	Short: "Show the functions a symbol calls",
+	Long: `Show a depth-limited tree of the functions a symbol calls.
+
+Only functions defined in the project are shown unless --external is set,
+which also lists library calls as leaves.
+
+Examples:
+  quokka navigate callees handle_login
+  quokka navigate callees Server.ServeHTTP --depth 2 --external
+  quokka navigate callees main --method treesitter --json`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runCallGraph(cmd, args[0], navigate.DirectionCallees)
+	},
+}
+
+func runCallGraph(cmd *cobra.Command, symbol string, dir navigate.CallDirection) {
+	p, err := project.EnsureActive()
+	if err != nil {
+		exitError("%v", err)
+	}
+
+	methodStr, _ := cmd.Flags().GetString("method")
+	depth, _ := cmd.Flags().GetInt("depth")
+	file, _ := cmd.Flags().GetString("file")
+	external, _ := cmd.Flags().GetBool("external")
+
+	graph := navigate.NewCallGraph(p)
+	defer func() { _ = graph.Close() }()
+
+	opts := &navigate.CallOptions{
+		File:     file,
+		Depth:    depth,
+		Method:   navigate.ExtractionMethod(methodStr),
+		External: external,
+	}
+
+	var result *navigate.CallGraphResult
+	if dir == navigate.DirectionCallers {
+		result, err = graph.Callers(cmd.Context(), symbol, opts)
+	} else {
+		result, err = graph.Callees(cmd.Context(), symbol, opts)
+	}
+	if err != nil {
+		exitError("%v", err)
+	}
+
+	if jsonOutput {
+		if err := outputJSON(result); err != nil {
+			exitError("failed to encode JSON: %v", err)
+		}
+		return
+	}
+
+	for i, root := range result.Roots {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s (%s, via %s)\n", describeCallNode(root), result.Direction, root.Method)
+		printCallTree(root.Children, "  ")
+	}
+	for _, note := range result.Notes {
+		fmt.Printf("\nNote: %s\n", note)
+	}
+}
+
+// describeCallNode formats a call tree node as name (file:line) plus any
+// call-site lines and markers.
+func describeCallNode(n *navigate.CallNode) string {
+	name := n.Name
+	if n.Parent != "" {
+		name = n.Parent + "." + name
+	}
+	var b strings.Builder
+	b.WriteString(name)
+	switch {
+	case n.File != "" && n.Line > 0:
+		fmt.Fprintf(&b, " (%s:%d)", n.File, n.Line)
+	case n.File != "":
+		fmt.Fprintf(&b, " (%s)", n.File)
+	}
+	if len(n.CallLines) > 0 {
+		lines := make([]string, len(n.CallLines))
+		for i, l := range n.CallLines {
+			lines[i] = strconv.Itoa(l)
+		}
+		fmt.Fprintf(&b, " calls at line %s", strings.Join(lines, ", "))
+	}
+	if n.External {
+		b.WriteString(" [external]")
+	}
+	if n.Recursive {
+		b.WriteString(" [recursive]")
+	}
+	if n.Truncated {
+		b.WriteString(" [...]")
+	}
+	return b.String()
+}
+
+func printCallTree(nodes []*navigate.CallNode, indent string) {
+	for _, n := range nodes {
+		fmt.Printf("%s%s\n", indent, describeCallNode(n))
+		printCallTree(n.Children, indent+"  ")
+	}
+}
+
+// routesCmd represents the navigate routes command
+var routesCmd = &cobra.Command{
+	Use:   "routes",
+	Short: "List HTTP routes with their handlers and middleware",
+	Long: `List the HTTP routes declared in the project: method, path, handler,
+file:line and the middleware, decorators or annotations applied to each.
+Middleware that looks like authentication or authorization is also listed
+under auth.
+
+Routes are extracted with tree-sitter for Flask, FastAPI, Django, Express,
+Gin, chi, net/http, Spring MVC and Rails. Routers mounted from another file
+keep the path they were declared with.
+
+--write-memory stores the routes as a markdown context memory (replacing
+one with the same name), e.g. the api_endpoints memory recon expects.
+
+Examples:
+  quokka navigate routes
+  quokka navigate routes --framework flask,django --json
+  quokka navigate routes --file app/api.py
+  quokka navigate routes --write-memory api_endpoints`,
+	Run: func(cmd *cobra.Command, args []string) {
+		p, err := project.EnsureActive()
+		if err != nil {
+			exitError("%v", err)
+		}
+
+		frameworks, _ := cmd.Flags().GetStringSlice("framework")
+		file, _ := cmd.Flags().GetString("file")
+		memName, _ := cmd.Flags().GetString("write-memory")
+
+		result, err := navigate.ExtractRoutes(p, &navigate.RouteOptions{
+			Frameworks: frameworks,
+			File:       file,
+		})
+		if err != nil {
+			exitError("%v", err)
+		}
+
+		if memName != "" {
+			store := memory.NewStore(p)
+			defer func() { _ = store.Close() }()
+			mem := &memory.Memory{
+				Name:        memName,
+				Type:        memory.MemoryTypeContext,
+				Content:     navigate.RenderRoutesMarkdown(result),
+				Description: "HTTP routes extracted by quokka navigate routes",
+				Tags:        append([]string{"routes"}, result.Frameworks...),
+			}
+			if existing, _ := store.ReadByName(memName); existing != nil {
+				mem.Type = existing.Type
+				err = store.Update(mem)
+			} else {
+				err = store.Create(mem)
+			}
+			if err != nil {
+				exitError("write memory %s: %v", memName, err)
+			}
+		}
+
+		if jsonOutput {
+			if err := outputJSON(result); err != nil {
+				exitError("failed to encode JSON: %v", err)
+			}
+			return
+		}
+
+		for _, r := range result.Routes {
+			fmt.Printf("%-7s %s -> %s (%s:%d) [%s]\n", r.Method, r.Path, r.Handler, r.File, r.Line, r.Framework)
+			if len(r.Middleware) > 0 {
+				fmt.Printf("        middleware: %s\n", strings.Join(r.Middleware, ", "))
+			}
+		}
+		fmt.Printf("\n%d route(s) in %d file(s) scanned\n", result.Total, result.FilesScanned)
+		if memName != "" {
+			fmt.Printf("Wrote memory: %s\n", memName)
+		}
+		for _, note := range result.Notes {
+			fmt.Printf("Note: %s\n", note)
+		}
+	},
+}
+
 func init() {
 	rootCmd.AddCommand(readCmd)
 	rootCmd.AddCommand(listCmd)
 	rootCmd.AddCommand(findCmd)
 	rootCmd.AddCommand(searchCmd)
 	rootCmd.AddCommand(symbolsCmd)
+	rootCmd.AddCommand(navigateCmd)
+	navigateCmd.AddCommand(callersCmd)
+	navigateCmd.AddCommand(calleesCmd)
+	navigateCmd.AddCommand(definitionCmd)
+	navigateCmd.AddCommand(refsCmd)
+	navigateCmd.AddCommand(routesCmd)
 
 	readCmd.Flags().StringP("lines", "l", "", "Line range to read (N:M)")
 
@@ -443,4 +791,19 @@ func init() {
 	searchCmd.Flags().StringP("file", "f", "", "File pattern to search")
 
 	symbolsCmd.Flags().StringP("method", "m", "auto", "Extraction method: auto, treesitter, lsp, regex")
+
+	for _, c := range []*cobra.Command{callersCmd, calleesCmd} {
+		c.Flags().IntP("depth", "d", navigate.DefaultCallDepth, "Levels to expand below the symbol")
+		c.Flags().StringP("method", "m", "auto", "Call graph method: auto, treesitter, lsp")
+		c.Flags().StringP("file", "f", "", "Only use the definition in this file")
+	}
+	calleesCmd.Flags().Bool("external", false, "Include calls to functions outside the project")
+
+	definitionCmd.Flags().StringP("method", "m", "auto", "Navigation method: auto, treesitter, lsp")
+	refsCmd.Flags().StringP("method", "m", "auto", "Navigation method: auto, treesitter, lsp")
+	refsCmd.Flags().Bool("include-declaration", true, "Include the declaration itself")
+
+	routesCmd.Flags().StringSlice("framework", nil, "Only list routes of these frameworks ("+strings.Join(navigate.RouteFrameworks(), ", ")+")")
+	routesCmd.Flags().StringP("file", "f", "", "Only list routes declared in this file")
+	routesCmd.Flags().String("write-memory", "", "Also write the routes to this memory (e.g. api_endpoints)")
 }
@@ -122,3 +122,36 @@ func TestNormalizeListPathsOutsideProject(t *testing.T) {
 		}
 	}
 }
+
+func TestDescribeCallNode(t *testing.T) {
+	tests := []struct {
+		node *navigate.CallNode
+		want string
+	}{
+		{
+			&navigate.CallNode{Name: "find", Parent: "UserRepo", File: "repo.py", Line: 12, CallLines: []int{4, 9}},
+			"UserRepo.find (repo.py:12) calls at line 4, 9",
+		},
+		{
+			&navigate.CallNode{Name: navigate.ModuleLevel, File: "main.py", CallLines: []int{30}},
+			"<module> (main.py) calls at line 30",
+		},
+		{
+			&navigate.CallNode{Name: "cursor.execute", External: true, CallLines: []int{2}},
+			"cursor.execute calls at line 2 [external]",
+		},
+		{
+			&navigate.CallNode{Name: "walk", File: "tree.go", Line: 5, Recursive: true},
+			"walk (tree.go:5) [recursive]",
+		},
+		{
+			&navigate.CallNode{Name: "fetch", File: "db.py", Line: 8, Truncated: true},
+			"fetch (db.py:8) [...]",
+		},
+	}
+	for _, tt := range tests {
+		if got := describeCallNode(tt.node); got != tt.want {
+			t.Errorf("describeCallNode() = %q, want %q", got, tt.want)
+		}
+	}
+}
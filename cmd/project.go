@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/diffsec/quokka/internal/agent"
+	"github.com/diffsec/quokka/internal/finding"
 	"github.com/diffsec/quokka/internal/memory"
 	"github.com/diffsec/quokka/internal/project"
 	"github.com/diffsec/quokka/internal/skill"
@@ -119,13 +120,10 @@ var statusCmd = &cobra.Command{
 
 		// Count findings
 		findingCount := 0
-		findingsDir := p.GetFindingsPath() + "/raw"
-		if entries, err := os.ReadDir(findingsDir); err == nil {
-			for _, e := range entries {
-				if !e.IsDir() && strings.HasSuffix(e.Name(), ".yaml") {
-					findingCount++
-				}
-			}
+		findingStore := finding.NewStore(p)
+		defer func() { _ = findingStore.Close() }()
+		if findings, err := findingStore.List(nil); err == nil {
+			findingCount = findings.Total
 		}
 
 		status := map[string]interface{}{
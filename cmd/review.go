@@ -1,20 +1,27 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/diffsec/quokka/internal/agent"
 	"github.com/diffsec/quokka/internal/exception"
 	"github.com/diffsec/quokka/internal/finding"
 	"github.com/diffsec/quokka/internal/finding/export"
+	"github.com/diffsec/quokka/internal/gitdiff"
 	"github.com/diffsec/quokka/internal/memory"
+	"github.com/diffsec/quokka/internal/navigate"
 	"github.com/diffsec/quokka/internal/project"
+	"github.com/diffsec/quokka/internal/publish"
 	"github.com/diffsec/quokka/internal/runner"
 	"github.com/spf13/cobra"
 )
@@ -39,6 +46,7 @@ type reviewSetup struct {
 	Base             string                       `json:"base"`
 	HeadSHA          string                       `json:"head_sha"`
 	ChangedFiles     []string                     `json:"changed_files"`
+	Hunks            map[string][]gitdiff.Hunk    `json:"hunks,omitempty"`
 	Classification   project.ProjectClassification `json:"classification"`
 	SuggestedAgents  []string                     `json:"suggested_agents"`
 	PromptsDir       string                       `json:"prompts_dir,omitempty"`
@@ -145,6 +153,15 @@ type reviewReport struct {
 	CommentMarkdown string               `json:"comment_markdown"`
 	SarifPath       string               `json:"sarif_path,omitempty"`
 	CommentPath     string               `json:"comment_path,omitempty"`
+	Published       *publish.Result      `json:"published,omitempty"`
+
+	// Scope is the --scope limit applied; Scopes classifies every finding
+	// in a changed file (by ID), including those the limit dropped, and
+	// ByScope counts them.
+	Scope      gitdiff.Scope                     `json:"scope"`
+	ByScope    map[string]int                    `json:"by_scope"`
+	OutOfScope int                               `json:"out_of_scope"`
+	Scopes     map[string]gitdiff.Classification `json:"scopes,omitempty"`
 }
 
 var reviewPrSetupCmd = &cobra.Command{
@@ -152,7 +169,10 @@ var reviewPrSetupCmd = &cobra.Command{
 	Short: "Prepare scope for a PR review and emit agent prompts",
 	Long: `Resolves the diff against --base, classifies the project, and emits the
 set of agents that apply along with their fully-rendered prompts. The output
-is consumed by the CI driver (Claude Code, OpenCode) to spawn agents.`,
+is consumed by the CI driver (Claude Code, OpenCode) to spawn agents.
+
+setup.json records the diff hunks per file ("hunks"); the orchestrator and
+dispatched agents see each changed file with its changed line ranges.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		base, _ := cmd.Flags().GetString("base")
 		if base == "" {
@@ -198,6 +218,14 @@ is consumed by the CI driver (Claude Code, OpenCode) to spawn agents.`,
 		if err != nil {
 			exitError("%v", err)
 		}
+		// Hunks drive line-level scoping: agents are told which lines
+		// changed, and `pr report` drops findings outside them.
+		diff, err := gitdiff.Git("", base)
+		if err != nil {
+			exitError("%v", err)
+		}
+		hunks := diff.HunkMap()
+		scopeList := describeChangedFiles(changed, hunks)
 		head, err := gitHeadSHA()
 		if err != nil {
 			exitError("%v", err)
@@ -359,7 +387,7 @@ is consumed by the CI driver (Claude Code, OpenCode) to spawn agents.`,
 				// recon-agent` from the CLI still gives the broad version.
 				prText := text
 				if name == "recon-agent" {
-					prText += prModeReconScopingOverride(changed)
+					prText += prModeReconScopingOverride(scopeList)
 				}
 				path := filepath.Join(runnerAgentsDir, name+".md")
 				var body string
@@ -391,15 +419,15 @@ is consumed by the CI driver (Claude Code, OpenCode) to spawn agents.`,
 			switch runner {
 			case "opencode":
 				if profile == "fast" {
-					orchestrator = renderOpenCodeOrchestratorFast(base, changed, suggested, effective)
+					orchestrator = renderOpenCodeOrchestratorFast(base, scopeList, suggested, effective)
 				} else {
-					orchestrator = renderOpenCodeOrchestrator(base, changed, suggested, effective)
+					orchestrator = renderOpenCodeOrchestrator(base, scopeList, suggested, effective)
 				}
 			case "claude":
 				if profile == "fast" {
-					orchestrator = renderClaudeOrchestratorFast(base, changed, suggested, effective)
+					orchestrator = renderClaudeOrchestratorFast(base, scopeList, suggested, effective)
 				} else {
-					orchestrator = renderClaudeOrchestrator(base, changed, suggested, effective)
+					orchestrator = renderClaudeOrchestrator(base, scopeList, suggested, effective)
 				}
 			}
 			if err := os.WriteFile(orchestratorPath, []byte(orchestrator), 0644); err != nil {
@@ -411,6 +439,7 @@ is consumed by the CI driver (Claude Code, OpenCode) to spawn agents.`,
 			Base:            base,
 			HeadSHA:         head,
 			ChangedFiles:    changed,
+			Hunks:           hunks,
 			Classification:  classification,
 			SuggestedAgents: suggested,
 			DispatchPlan:    buildDispatchPlan(profile, suggested),
@@ -450,7 +479,7 @@ is consumed by the CI driver (Claude Code, OpenCode) to spawn agents.`,
 
 		fmt.Printf("Base: %s\nHead: %s\n", out.Base, out.HeadSHA)
 		fmt.Printf("Changed files: %d\n", len(out.ChangedFiles))
-		for _, f := range out.ChangedFiles {
+		for _, f := range describeChangedFiles(out.ChangedFiles, out.Hunks) {
 			fmt.Printf("  - %s\n", f)
 		}
 		fmt.Printf("\nSuggested agents (%d):\n", len(out.SuggestedAgents))
@@ -596,7 +625,9 @@ func buildOrchestratorBodyFast(base string, changedFiles, suggestedAgents []stri
 	}
 	b.WriteString("\nAnalysis is scoped to these files. The report step filters anything ")
 	b.WriteString("else out anyway. Do NOT explore the project broadly — that's the deep ")
-	b.WriteString("profile's job.\n\n")
+	b.WriteString("profile's job.\n")
+	b.WriteString(changedLinesGuidance)
+	b.WriteString("\n")
 
 	b.WriteString("## Available subagents\n")
 	for _, name := range suggestedAgents {
@@ -638,6 +669,13 @@ func buildOrchestratorBodyFast(base string, changedFiles, suggestedAgents []stri
 	return b.String()
 }
 
+// changedLinesGuidance follows the changed-file list in both orchestrator
+// profiles. `pr report` scopes to changed functions by default, so issues
+// found only in untouched code never reach the PR comment.
+const changedLinesGuidance = "Line ranges are the diff hunks. Focus on those lines and the " +
+	"functions containing them; read the rest of the file only for context. Findings " +
+	"entirely in unchanged functions are pre-existing and are dropped from the PR report.\n"
+
 // prModeReconScopingOverride is appended to the recon-agent prompt ONLY when
 // the agent is materialized for an OpenCode PR-review run. It narrows
 // recon to the changed files + their 1-hop neighborhood, which keeps the
@@ -767,7 +805,9 @@ func buildOrchestratorBodyDeep(base string, changedFiles, suggestedAgents []stri
 	}
 	b.WriteString("\nYou MUST keep analysis scoped to the changed files above. ")
 	b.WriteString("Out-of-scope findings will be filtered out by the report step ")
-	b.WriteString("regardless, so spending tokens on them is waste.\n\n")
+	b.WriteString("regardless, so spending tokens on them is waste.\n")
+	b.WriteString(changedLinesGuidance)
+	b.WriteString("\n")
 
 	b.WriteString("## Available subagents\n")
 	b.WriteString("Specialized subagents are configured for this run. Invoke each ")
@@ -821,9 +861,17 @@ func buildOrchestratorBodyDeep(base string, changedFiles, suggestedAgents []stri
 			b.WriteString("           severity: ERROR\n")
 			b.WriteString("           languages: [<lang>]\n")
 			b.WriteString("       EOF\n")
-			b.WriteString("       quokka rule add <slug> --file /tmp/rule.yaml \\\n")
+			b.WriteString("       mkdir -p /tmp/<slug> && cat > /tmp/<slug>/example.<ext> <<EOF\n")
+			b.WriteString("       # ruleid: <slug>\n")
+			b.WriteString("       <a line the rule must match>\n")
+			b.WriteString("       # ok: <slug>\n")
+			b.WriteString("       <a similar line it must not match>\n")
+			b.WriteString("       EOF\n")
+			b.WriteString("       quokka rule add <slug> --file /tmp/rule.yaml --tests /tmp/<slug> \\\n")
 			b.WriteString("         --created-by agent:<your-agent-name> \\\n")
 			b.WriteString("         --reasoning \"<why this pattern is worth catching>\"\n\n")
+			b.WriteString("   The rule is only added if it matches every `ruleid:` line in the fixtures and nothing else; ")
+			b.WriteString("use the comment syntax of the fixture's language.\n")
 			b.WriteString("   Only add rules when you've seen the *same pattern multiple times* in this codebase. ")
 			b.WriteString("One-offs aren't worth the rule-set bloat. Rules accumulate; the rule-judge-agent will ")
 			b.WriteString("retire noisy ones later, so err toward not adding.\n\n")
@@ -966,7 +1014,19 @@ with --setup-json. Run ` + "`quokka review pr setup`" + ` first to produce it.
 
 Per-agent logs land in ` + "`.quokka/review/agents/<name>.log`" + `; the report step
 (` + "`quokka review pr report`" + `) reads findings from the store and emits the
-final PR comment regardless of which dispatch path produced them.`,
+final PR comment regardless of which dispatch path produced them.
+
+Progress is checkpointed to ` + "`.quokka/review/checkpoint.json`" + ` after every agent
+and phase. If a run is cut short — per-agent timeout, a hard provider
+failure such as a rate limit, or Ctrl-C — re-run with --resume: completed
+phases and agents are skipped and only failed or missing agents run again.
+Resuming is refused when setup.json changed since the checkpoint was
+written; re-run without --resume to start over.
+
+Examples:
+  quokka review pr run --runner opencode --model openrouter/qwen/qwen3-coder-plus
+  quokka review pr run --runner claude --per-agent-timeout 15m
+  quokka review pr run --runner claude --resume`,
 	Run: func(cmd *cobra.Command, args []string) {
 		setupPath, _ := cmd.Flags().GetString("setup-json")
 		runnerName, _ := cmd.Flags().GetString("runner")
@@ -974,6 +1034,7 @@ final PR comment regardless of which dispatch path produced them.`,
 		maxParallel, _ := cmd.Flags().GetInt("max-parallel")
 		perAgentTimeout, _ := cmd.Flags().GetDuration("per-agent-timeout")
 		userTurn, _ := cmd.Flags().GetString("user-turn")
+		resume, _ := cmd.Flags().GetBool("resume")
 
 		runnerName = strings.ToLower(strings.TrimSpace(runnerName))
 		if runnerName == "" {
@@ -1028,13 +1089,33 @@ final PR comment regardless of which dispatch path produced them.`,
 			PerAgentTimeout: perAgentTimeout,
 			UserTurn:        userTurn,
 			ChangedFiles:    setup.ChangedFiles,
+			ChangedLines:    changedLineRanges(setup.Hunks),
+			CheckpointPath:  filepath.Join(p.GetQuokkaPath(), "review", runner.CheckpointFile),
+			SetupHash:       runner.HashSetup(setupBytes),
+			Resume:          resume,
 		}
 
+		ctx, cancel := context.WithCancel(cmd.Context())
+		defer cancel()
+
+		// Handle interrupt: cancelling kills in-flight agents; whatever
+		// finished is already in the checkpoint.
+		sigCh := make(chan os.Signal, 1)
+		signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+		go func() {
+			<-sigCh
+			fmt.Println("\nInterrupted, stopping agents...")
+			cancel()
+		}()
+
 		fmt.Printf("Dispatching %d phases (runner=%s, model=%s, max-parallel=%d)\n",
 			len(setup.DispatchPlan.Phases), runnerName, model, maxParallel)
 
-		result, err := runner.Dispatch(cmd.Context(), setup.DispatchPlan, cfg)
+		result, err := runner.Dispatch(ctx, setup.DispatchPlan, cfg)
 		if err != nil {
+			if ctx.Err() != nil {
+				exitError("dispatch interrupted: %v\nProgress is saved in %s; re-run with --resume to continue.", err, cfg.CheckpointPath)
+			}
 			exitError("dispatch failed: %v", err)
 		}
 
@@ -1069,6 +1150,9 @@ final PR comment regardless of which dispatch path produced them.`,
 				if ar.Retries > 0 {
 					suffix = fmt.Sprintf(" [retried %d×: %s]", ar.Retries, ar.RetryReason)
 				}
+				if ar.Resumed {
+					suffix += " [from checkpoint]"
+				}
 				fmt.Printf("    - %s: %s (%s)%s\n", ar.Agent, status, ar.Duration.Round(time.Second), suffix)
 			}
 		}
@@ -1076,7 +1160,7 @@ final PR comment regardless of which dispatch path produced them.`,
 			fmt.Printf("\n%d agent(s) needed a corrective retry — typically a sign the model struggled with tool-call schema. See per-agent logs (.attempt-1 files preserve the first attempt) in %s\n", retried, logDir)
 		}
 		if errored > 0 {
-			exitError("%d agent invocation(s) failed — see per-agent logs in %s", errored, logDir)
+			exitError("%d agent invocation(s) failed — see per-agent logs in %s\nFix the cause and re-run with --resume to retry only the failed agents.", errored, logDir)
 		}
 	},
 }
@@ -1087,7 +1171,31 @@ var reviewPrReportCmd = &cobra.Command{
 	Long: `Filters the finding store to issues located in the diff against --base,
 renders a standardized PR comment, and writes a SARIF file with stable
 partialFingerprints. Designed to be consumed by a GitHub Action posting via
-gh api and uploading to code-scanning.`,
+gh api and uploading to code-scanning.
+
+Findings are scoped by the diff hunks, not just the changed file names.
+Each finding in a changed file is classified as:
+  changed-lines     its lines overlap a hunk
+  changed-function  it sits in a function or method a hunk touches
+                    (ranges from the symbol extractor)
+  file              elsewhere in the changed file
+--scope sets the widest class reported (default changed-function); use
+--scope file for the old whole-file behavior. Each comment block is labeled
+with its class, and --json includes the classification of every finding.
+
+With --publish github|gitlab the comment is also posted to the pull/merge
+request, together with one inline comment per finding (at or above
+--severity-threshold) anchored to its file and line. Comments carry hidden
+markers, so re-running updates the previous summary and inline comments
+instead of adding new ones. Lines the host rejects as outside the diff are
+reported and skipped. On GitHub, inline comments are anchored to the pull
+request's head commit unless --commit names another.
+
+Publishing reads its target from flags, falling back to CI variables:
+  github: --repo (GITHUB_REPOSITORY), --pr (from GITHUB_REF refs/pull/N/...),
+          --api-url (GITHUB_API_URL), token from GITHUB_TOKEN
+  gitlab: --repo (CI_PROJECT_PATH), --pr (CI_MERGE_REQUEST_IID),
+          --api-url (CI_API_V4_URL), token from GITLAB_TOKEN`,
 	Run: func(cmd *cobra.Command, args []string) {
 		base, _ := cmd.Flags().GetString("base")
 		if base == "" {
@@ -1097,6 +1205,25 @@ gh api and uploading to code-scanning.`,
 		threshold, _ := cmd.Flags().GetString("severity-threshold")
 		outDir, _ := cmd.Flags().GetString("output-dir")
 		sarifLink, _ := cmd.Flags().GetString("sarif-link")
+		publishTo, _ := cmd.Flags().GetString("publish")
+		scopeFlag, _ := cmd.Flags().GetString("scope")
+		limit, err := gitdiff.ParseScope(scopeFlag)
+		if err != nil {
+			exitError("--scope: %v", err)
+		}
+
+		// Resolve the publish target before doing any work so a missing
+		// token fails fast instead of after the report is rendered.
+		var publisher publish.Publisher
+		if publishTo != "" {
+			cfg, err := publishConfig(cmd, publishTo)
+			if err != nil {
+				exitError("%v", err)
+			}
+			if publisher, err = publish.NewPublisher(cfg); err != nil {
+				exitError("%v", err)
+			}
+		}
 
 		p, err := project.EnsureActive()
 		if err != nil {
@@ -1113,24 +1240,37 @@ gh api and uploading to code-scanning.`,
 		}
 
 		store := finding.NewStore(p)
+		defer func() { _ = store.Close() }()
+
 		all, err := store.List(nil)
 		if err != nil {
 			exitError("%v", err)
 		}
 		inDiff := filterFindingsByDiff(all.Findings, changedSet)
 
+		// Line-level scoping: a one-line change to a large file shouldn't
+		// pull every pre-existing finding in that file into the comment.
+		classifier, closeSymbols, err := diffClassifier(p, base)
+		if err != nil {
+			exitError("%v", err)
+		}
+		inScope, scopes := classifier.Filter(inDiff, limit)
+		closeSymbols()
+		byScope := map[string]int{}
+		for _, cl := range scopes {
+			byScope[string(cl.Scope)]++
+		}
+
 		// Partition by suppression: comment-bound findings get the visible
 		// ones; SARIF gets both, with suppressed entries marked dismissed.
-		excStore := exception.NewStore(p)
-		suppressedFor := map[string]string{} // ID → reason
+		if _, err := exception.NewStore(p).Annotate(inScope); err != nil {
+			exitError("%v", err)
+		}
 		var visible []finding.Finding
-		for _, f := range inDiff {
-			match, _ := excStore.Match(f)
-			if match != nil {
-				suppressedFor[f.ID] = match.Reason
-				continue
+		for _, f := range inScope {
+			if f.Suppression == nil {
+				visible = append(visible, f)
 			}
-			visible = append(visible, f)
 		}
 
 		bySeverity := map[string]int{}
@@ -1138,7 +1278,7 @@ gh api and uploading to code-scanning.`,
 			bySeverity[string(f.Severity)]++
 		}
 
-		md := renderPRComment(visible, topN, finding.Severity(strings.ToLower(threshold)), sarifLink)
+		md := renderPRComment(visible, topN, finding.Severity(strings.ToLower(threshold)), sarifLink, scopes)
 
 		// Write artifacts to disk so the action can pick them up.
 		if outDir == "" {
@@ -1151,12 +1291,11 @@ gh api and uploading to code-scanning.`,
 		if err := os.WriteFile(commentPath, []byte(md), 0644); err != nil {
 			exitError("failed to write comment.md: %v", err)
 		}
-		// SARIF includes ALL findings in the diff. Suppressed ones are marked
-		// as dismissed via SARIF suppressions so code-scanning shows them
-		// "Closed (won't fix)" with the justification rather than dropping
-		// the audit trail.
-		sarifExporter := export.NewSARIFExporter().WithSuppressions(suppressedFor)
-		sarifBytes, err := sarifExporter.Export(inDiff)
+		// SARIF includes ALL in-scope findings. Suppressed ones carry their
+		// exception and are marked dismissed, so code-scanning shows them
+		// "Closed (won't fix)" with the justification, approver and expiry
+		// rather than dropping the audit trail.
+		sarifBytes, err := export.NewSARIFExporter().Export(inScope)
 		if err != nil {
 			exitError("failed to render SARIF: %v", err)
 		}
@@ -1173,6 +1312,28 @@ gh api and uploading to code-scanning.`,
 			CommentMarkdown: md,
 			SarifPath:       sarifPath,
 			CommentPath:     commentPath,
+			Scope:           limit,
+			ByScope:         byScope,
+			OutOfScope:      len(inDiff) - len(inScope),
+			Scopes:          scopes,
+		}
+
+		if publisher != nil {
+			// Not the local HEAD: on a pull_request checkout that is a
+			// synthetic merge commit. Empty lets the publisher use the
+			// pull request's head commit.
+			commit, _ := cmd.Flags().GetString("commit")
+			review := publish.Review{
+				Summary:   md,
+				Inline:    inlineComments(visible, finding.Severity(strings.ToLower(threshold))),
+				CommitSHA: commit,
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			out.Published, err = publisher.Publish(ctx, review)
+			cancel()
+			if err != nil {
+				exitError("publish to %s failed: %v", publisher.Name(), err)
+			}
 		}
 
 		if jsonOutput {
@@ -1182,19 +1343,153 @@ gh api and uploading to code-scanning.`,
 			return
 		}
 
-		fmt.Printf("Findings in diff: %d\n", out.Total)
+		fmt.Printf("Findings in diff: %d (scope: %s)\n", out.Total, limit)
 		for _, sev := range []finding.Severity{finding.SeverityCritical, finding.SeverityHigh, finding.SeverityMedium, finding.SeverityLow, finding.SeverityInfo} {
 			if n := bySeverity[string(sev)]; n > 0 {
 				fmt.Printf("  %s: %d\n", sev, n)
 			}
 		}
+		if out.OutOfScope > 0 {
+			fmt.Printf("  (%d more in changed files, outside --scope %s)\n", out.OutOfScope, limit)
+		}
 		fmt.Printf("\nComment: %s\nSARIF:   %s\n", commentPath, sarifPath)
+		if res := out.Published; res != nil {
+			action := "unchanged"
+			if res.SummaryCreated {
+				action = "posted"
+			} else if res.SummaryUpdated {
+				action = "updated"
+			}
+			fmt.Printf("\nPublished to %s: summary %s", res.Provider, action)
+			if res.SummaryURL != "" {
+				fmt.Printf(" (%s)", res.SummaryURL)
+			}
+			fmt.Printf("\n  inline: %d new, %d updated, %d unchanged\n", res.InlineCreated, res.InlineUpdated, res.InlineKept)
+			for _, loc := range res.InlineSkipped {
+				fmt.Printf("  skipped %s (not part of the diff)\n", loc)
+			}
+		}
 	},
 }
 
+// publishConfig builds the publisher target from flags, falling back to the
+// variables GitHub Actions and GitLab CI set for pull/merge request jobs.
+func publishConfig(cmd *cobra.Command, provider string) (publish.Config, error) {
+	cfg := publish.Config{Provider: provider}
+	cfg.Repo, _ = cmd.Flags().GetString("repo")
+	cfg.Number, _ = cmd.Flags().GetInt("pr")
+	cfg.BaseURL, _ = cmd.Flags().GetString("api-url")
+
+	switch provider {
+	case "github":
+		if cfg.Repo == "" {
+			cfg.Repo = os.Getenv("GITHUB_REPOSITORY")
+		}
+		if cfg.Number == 0 {
+			cfg.Number = prNumberFromRef(os.Getenv("GITHUB_REF"))
+		}
+		if cfg.BaseURL == "" {
+			cfg.BaseURL = os.Getenv("GITHUB_API_URL")
+		}
+		cfg.Token = os.Getenv("GITHUB_TOKEN")
+		if cfg.Token == "" {
+			return cfg, fmt.Errorf("GITHUB_TOKEN is not set")
+		}
+	case "gitlab":
+		if cfg.Repo == "" {
+			cfg.Repo = os.Getenv("CI_PROJECT_PATH")
+		}
+		if cfg.Number == 0 {
+			cfg.Number, _ = strconv.Atoi(os.Getenv("CI_MERGE_REQUEST_IID"))
+		}
+		if cfg.BaseURL == "" {
+			cfg.BaseURL = os.Getenv("CI_API_V4_URL")
+		}
+		cfg.Token = os.Getenv("GITLAB_TOKEN")
+		if cfg.Token == "" {
+			return cfg, fmt.Errorf("GITLAB_TOKEN is not set")
+		}
+	default:
+		return cfg, fmt.Errorf("unknown --publish target %q (valid: github, gitlab)", provider)
+	}
+
+	if cfg.Repo == "" {
+		return cfg, fmt.Errorf("--repo is required (or set it via the CI environment)")
+	}
+	if cfg.Number <= 0 {
+		return cfg, fmt.Errorf("--pr is required (or run from a pull/merge request pipeline)")
+	}
+	return cfg, nil
+}
+
+// prNumberFromRef extracts N from a GitHub Actions ref like refs/pull/N/merge.
+func prNumberFromRef(ref string) int {
+	rest, ok := strings.CutPrefix(ref, "refs/pull/")
+	if !ok {
+		return 0
+	}
+	num, _, _ := strings.Cut(rest, "/")
+	n, _ := strconv.Atoi(num)
+	return n
+}
+
+// inlineComments turns findings at or above threshold into line-anchored
+// review comments keyed by fingerprint.
+func inlineComments(findings []finding.Finding, threshold finding.Severity) []publish.InlineComment {
+	thresholdWeight := finding.SeverityWeight(threshold)
+	var out []publish.InlineComment
+	for _, f := range findings {
+		if thresholdWeight > 0 && finding.SeverityWeight(f.Severity) < thresholdWeight {
+			continue
+		}
+		if f.Location.File == "" || f.Location.LineStart < 1 {
+			continue
+		}
+		fp := f.Fingerprint
+		if fp == "" {
+			fp = finding.Fingerprint(f)
+		}
+		out = append(out, publish.InlineComment{
+			Fingerprint: fp,
+			Path:        f.Location.File,
+			Line:        f.Location.LineStart,
+			Body:        renderInlineComment(f),
+		})
+	}
+	return out
+}
+
+// renderInlineComment is the body of a single line-anchored comment. Shorter
+// than renderFindingBlock: the location is implied by the anchor.
+func renderInlineComment(f finding.Finding) string {
+	var b strings.Builder
+	cweSuffix := ""
+	if f.CWE != "" {
+		cweSuffix = fmt.Sprintf(" (%s)", f.CWE)
+	}
+	fmt.Fprintf(&b, "**[%s] %s**%s\n\n", strings.ToUpper(string(f.Severity)), f.Title, cweSuffix)
+	if f.Description != "" {
+		b.WriteString(strings.TrimSpace(f.Description) + "\n\n")
+	}
+	if f.Remediation != "" {
+		b.WriteString("**Suggested fix:** " + strings.TrimSpace(f.Remediation) + "\n\n")
+	}
+	meta := []string{"quokka " + f.ID}
+	if f.Confidence != "" {
+		meta = append(meta, "confidence: "+string(f.Confidence))
+	}
+	if f.CreatedBy != "" {
+		meta = append(meta, "agent: "+f.CreatedBy)
+	}
+	b.WriteString("_" + strings.Join(meta, " · ") + "_\n")
+	return b.String()
+}
+
 // renderPRComment is the standardized PR comment template. Kept pure so it can
-// be tested without git or filesystem state.
-func renderPRComment(findings []finding.Finding, topN int, threshold finding.Severity, sarifLink string) string {
+// be tested without git or filesystem state. scopes (by finding ID) labels
+// each block with where the finding sits relative to the diff; nil omits
+// the label.
+func renderPRComment(findings []finding.Finding, topN int, threshold finding.Severity, sarifLink string, scopes map[string]gitdiff.Classification) string {
 	if topN <= 0 {
 		topN = 10
 	}
@@ -1235,7 +1530,11 @@ func renderPRComment(findings []finding.Finding, topN int, threshold finding.Sev
 			break
 		}
 		shown++
-		b.WriteString(renderFindingBlock(shown, f))
+		label := ""
+		if cl, ok := scopes[f.ID]; ok {
+			label = cl.Label()
+		}
+		b.WriteString(renderFindingBlock(shown, f, label))
 	}
 
 	if shown == 0 {
@@ -1250,7 +1549,7 @@ func renderPRComment(findings []finding.Finding, topN int, threshold finding.Sev
 	return b.String()
 }
 
-func renderFindingBlock(n int, f finding.Finding) string {
+func renderFindingBlock(n int, f finding.Finding, scopeLabel string) string {
 	var b strings.Builder
 	badge := strings.ToUpper(string(f.Severity))
 	cweSuffix := ""
@@ -1267,6 +1566,9 @@ func renderFindingBlock(n int, f finding.Finding) string {
 	if f.Location.Function != "" {
 		loc += fmt.Sprintf(" in `%s`", f.Location.Function)
 	}
+	if scopeLabel != "" {
+		loc += " · _" + scopeLabel + "_"
+	}
 	b.WriteString("**Location:** " + loc + "\n\n")
 
 	if f.Description != "" {
@@ -1311,6 +1613,59 @@ func gitChangedFiles(baseRef string) ([]string, error) {
 	return files, nil
 }
 
+// describeChangedFiles renders the changed-file list for prompts, each file
+// annotated with its changed line ranges when the diff has hunks for it.
+func describeChangedFiles(changed []string, hunks map[string][]gitdiff.Hunk) []string {
+	out := make([]string, 0, len(changed))
+	for _, f := range changed {
+		if hs := hunks[f]; len(hs) > 0 {
+			f += " (changed lines: " + gitdiff.FormatRanges(hs) + ")"
+		}
+		out = append(out, f)
+	}
+	return out
+}
+
+// changedLineRanges is setup.json's hunks in the form the dispatcher
+// injects into each agent's user-turn.
+func changedLineRanges(hunks map[string][]gitdiff.Hunk) map[string]string {
+	if len(hunks) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(hunks))
+	for f, hs := range hunks {
+		out[f] = gitdiff.FormatRanges(hs)
+	}
+	return out
+}
+
+// diffClassifier loads the diff hunks against baseRef and returns a
+// classifier whose function ranges come from the symbol extractor. The
+// returned func releases any language servers the extractor started.
+func diffClassifier(p *project.Project, baseRef string) (*gitdiff.Classifier, func(), error) {
+	d, err := gitdiff.Git("", baseRef)
+	if err != nil {
+		return nil, nil, err
+	}
+	extractor := navigate.NewUnifiedExtractor(p, navigate.MethodAuto)
+	spans := func(path string) []gitdiff.Span {
+		res, err := extractor.Extract(path)
+		if err != nil {
+			return nil
+		}
+		var out []gitdiff.Span
+		for _, sym := range res.Functions() {
+			name := sym.Name
+			if sym.Parent != "" {
+				name = sym.Parent + "." + sym.Name
+			}
+			out = append(out, gitdiff.Span{Name: name, Start: sym.Line, End: sym.EndLine})
+		}
+		return out
+	}
+	return gitdiff.NewClassifier(d, spans), func() { _ = extractor.Close() }, nil
+}
+
 func gitHeadSHA() (string, error) {
 	out, err := exec.Command("git", "rev-parse", "HEAD").Output()
 	if err != nil {
@@ -1332,6 +1687,7 @@ func init() {
 	reviewPrRunCmd.Flags().Int("max-parallel", 0, "Max concurrent subprocesses in parallel phases (0 = no cap)")
 	reviewPrRunCmd.Flags().Duration("per-agent-timeout", 0, "Per-agent subprocess timeout (e.g. 15m). 0 = no timeout.")
 	reviewPrRunCmd.Flags().String("user-turn", "", "Override the default user-turn prompt the dispatcher passes to each agent. Empty = sensible default.")
+	reviewPrRunCmd.Flags().Bool("resume", false, "Resume from .quokka/review/checkpoint.json: skip completed phases and agents, re-run only failed or missing ones")
 
 	reviewPrSetupCmd.Flags().String("base", "", "Base git ref to diff against (e.g. origin/main)")
 	reviewPrSetupCmd.Flags().Bool("inline-prompts", false, "Embed agent prompts in JSON output instead of writing to disk")
@@ -1344,7 +1700,13 @@ func init() {
 
 	reviewPrReportCmd.Flags().String("base", "", "Base git ref to diff against (e.g. origin/main)")
 	reviewPrReportCmd.Flags().Int("top-n", 10, "Maximum findings to inline in the PR comment")
+	reviewPrReportCmd.Flags().String("scope", "changed-function", "Which findings in changed files to report: changed-lines, changed-function (also those in a function the diff touches) or file (anything in a changed file)")
 	reviewPrReportCmd.Flags().String("severity-threshold", "", "Only inline findings at or above this severity (critical, high, medium, low, info)")
 	reviewPrReportCmd.Flags().String("output-dir", "", "Directory to write comment.md and report.sarif (default: .quokka/findings/exports/pr)")
 	reviewPrReportCmd.Flags().String("sarif-link", "", "URL to link to in the PR comment (e.g. code-scanning view)")
+	reviewPrReportCmd.Flags().String("publish", "", "Post the comment and inline findings to the PR: github or gitlab")
+	reviewPrReportCmd.Flags().String("repo", "", "Repository for --publish: owner/name (GitHub) or project path/ID (GitLab)")
+	reviewPrReportCmd.Flags().Int("pr", 0, "Pull request number (GitHub) or merge request IID (GitLab) for --publish")
+	reviewPrReportCmd.Flags().String("api-url", "", "REST API root for --publish (default: public GitHub/GitLab, or CI environment)")
+	reviewPrReportCmd.Flags().String("commit", "", "Commit inline comments refer to on GitHub (default: the pull request's head commit)")
 }
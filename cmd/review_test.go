@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/diffsec/quokka/internal/finding"
+	"github.com/diffsec/quokka/internal/gitdiff"
 )
 
 func sampleFinding(id string, sev finding.Severity, title string) finding.Finding {
@@ -29,7 +30,7 @@ func sampleFinding(id string, sev finding.Severity, title string) finding.Findin
 }
 
 func TestRenderPRComment_NoFindings(t *testing.T) {
-	out := renderPRComment(nil, 10, "", "")
+	out := renderPRComment(nil, 10, "", "", nil)
 	if !strings.Contains(out, "No security findings") {
 		t.Errorf("expected all-clear message, got:\n%s", out)
 	}
@@ -42,7 +43,7 @@ func TestRenderPRComment_SummaryCounts(t *testing.T) {
 		sampleFinding("F3", finding.SeverityHigh, "Path Traversal"),
 		sampleFinding("F4", finding.SeverityMedium, "Open Redirect"),
 	}
-	out := renderPRComment(findings, 10, "", "")
+	out := renderPRComment(findings, 10, "", "", nil)
 	if !strings.Contains(out, "Found **4** finding(s)") {
 		t.Errorf("expected total count of 4, got:\n%s", out)
 	}
@@ -56,7 +57,7 @@ func TestRenderPRComment_TopNTruncates(t *testing.T) {
 	for i := 0; i < 5; i++ {
 		findings = append(findings, sampleFinding("F", finding.SeverityHigh, "Issue"))
 	}
-	out := renderPRComment(findings, 2, "", "")
+	out := renderPRComment(findings, 2, "", "", nil)
 	if !strings.Contains(out, "and 3 more finding(s)") {
 		t.Errorf("expected truncation footer, got:\n%s", out)
 	}
@@ -75,7 +76,7 @@ func TestRenderPRComment_SeverityThresholdFilters(t *testing.T) {
 		sampleFinding("F2", finding.SeverityLow, "Style"),
 		sampleFinding("F3", finding.SeverityInfo, "Note"),
 	}
-	out := renderPRComment(findings, 10, finding.SeverityHigh, "")
+	out := renderPRComment(findings, 10, finding.SeverityHigh, "", nil)
 	if !strings.Contains(out, "### 1. [CRITICAL] Bad") {
 		t.Errorf("expected critical finding to render, got:\n%s", out)
 	}
@@ -89,7 +90,7 @@ func TestRenderPRComment_ThresholdElidesAll(t *testing.T) {
 		sampleFinding("F1", finding.SeverityLow, "Style"),
 		sampleFinding("F2", finding.SeverityInfo, "Note"),
 	}
-	out := renderPRComment(findings, 10, finding.SeverityHigh, "")
+	out := renderPRComment(findings, 10, finding.SeverityHigh, "", nil)
 	if !strings.Contains(out, "below the `high` severity threshold") {
 		t.Errorf("expected threshold-elision message, got:\n%s", out)
 	}
@@ -97,7 +98,7 @@ func TestRenderPRComment_ThresholdElidesAll(t *testing.T) {
 
 func TestRenderPRComment_SarifLinkRendered(t *testing.T) {
 	findings := []finding.Finding{sampleFinding("F1", finding.SeverityHigh, "Bad")}
-	out := renderPRComment(findings, 10, "", "https://example.com/scan")
+	out := renderPRComment(findings, 10, "", "https://example.com/scan", nil)
 	if !strings.Contains(out, "(https://example.com/scan)") {
 		t.Errorf("expected SARIF link, got:\n%s", out)
 	}
@@ -105,7 +106,7 @@ func TestRenderPRComment_SarifLinkRendered(t *testing.T) {
 
 func TestRenderFindingBlock_IncludesAllSections(t *testing.T) {
 	f := sampleFinding("F1", finding.SeverityHigh, "SQL Injection")
-	out := renderFindingBlock(1, f)
+	out := renderFindingBlock(1, f, "")
 	mustContain := []string{
 		"### 1. [HIGH] SQL Injection (CWE-89)",
 		"`internal/db/q.go:42-45` in `GetUser`",
@@ -129,7 +130,7 @@ func TestRenderFindingBlock_OmitsMissingFields(t *testing.T) {
 		Severity: finding.SeverityMedium,
 		Location: finding.Location{File: "a.go", LineStart: 1},
 	}
-	out := renderFindingBlock(1, f)
+	out := renderFindingBlock(1, f, "")
 	if strings.Contains(out, "**Why it matters:**") {
 		t.Errorf("should not render Why it matters when Impact is empty, got:\n%s", out)
 	}
@@ -140,3 +141,77 @@ func TestRenderFindingBlock_OmitsMissingFields(t *testing.T) {
 		t.Errorf("expected heading, got:\n%s", out)
 	}
 }
+
+func TestRenderPRComment_ScopeLabels(t *testing.T) {
+	findings := []finding.Finding{
+		sampleFinding("F1", finding.SeverityHigh, "SQL Injection"),
+		sampleFinding("F2", finding.SeverityMedium, "Weak hash"),
+		sampleFinding("F3", finding.SeverityLow, "Verbose error"),
+	}
+	scopes := map[string]gitdiff.Classification{
+		"F1": {Scope: gitdiff.ScopeChangedLines},
+		"F2": {Scope: gitdiff.ScopeChangedFunction, Function: "GetUser"},
+	}
+	out := renderPRComment(findings, 10, "", "", scopes)
+	if !strings.Contains(out, "`internal/db/q.go:42-45` in `GetUser` · _in changed lines_") {
+		t.Errorf("expected changed-lines label, got:\n%s", out)
+	}
+	if !strings.Contains(out, "_in changed function `GetUser`_") {
+		t.Errorf("expected changed-function label, got:\n%s", out)
+	}
+	if strings.Count(out, " · _") != 2 {
+		t.Errorf("expected no label for the unclassified finding, got:\n%s", out)
+	}
+}
+
+func TestDescribeChangedFiles(t *testing.T) {
+	hunks := map[string][]gitdiff.Hunk{
+		"app/db.go": {{NewStart: 13, NewLines: 2}, {NewStart: 42, NewLines: 1}},
+	}
+	got := describeChangedFiles([]string{"app/db.go", "logo.png"}, hunks)
+	want := []string{"app/db.go (changed lines: 13-14, 42)", "logo.png"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("describeChangedFiles = %q, want %q", got, want)
+	}
+	if r := changedLineRanges(hunks); r["app/db.go"] != "13-14, 42" || len(r) != 1 {
+		t.Errorf("changedLineRanges = %v", r)
+	}
+	if changedLineRanges(nil) != nil {
+		t.Error("changedLineRanges(nil) should be nil")
+	}
+}
+
+func TestPRNumberFromRef(t *testing.T) {
+	cases := map[string]int{
+		"refs/pull/42/merge": 42,
+		"refs/pull/7/head":   7,
+		"refs/heads/main":    0,
+		"":                   0,
+	}
+	for ref, want := range cases {
+		if got := prNumberFromRef(ref); got != want {
+			t.Errorf("prNumberFromRef(%q) = %d, want %d", ref, got, want)
+		}
+	}
+}
+
+func TestInlineComments_ThresholdAndFingerprint(t *testing.T) {
+	findings := []finding.Finding{
+		sampleFinding("F1", finding.SeverityCritical, "SQL Injection"),
+		sampleFinding("F2", finding.SeverityLow, "Verbose error"),
+	}
+	got := inlineComments(findings, finding.SeverityHigh)
+	if len(got) != 1 {
+		t.Fatalf("expected 1 inline comment above threshold, got %d", len(got))
+	}
+	c := got[0]
+	if c.Path != "internal/db/q.go" || c.Line != 42 {
+		t.Errorf("anchor = %s:%d, want internal/db/q.go:42", c.Path, c.Line)
+	}
+	if c.Fingerprint != finding.Fingerprint(findings[0]) {
+		t.Errorf("fingerprint = %q, want computed fingerprint", c.Fingerprint)
+	}
+	if !strings.Contains(c.Body, "[CRITICAL] SQL Injection") || !strings.Contains(c.Body, "Use parameterized queries.") {
+		t.Errorf("unexpected body:\n%s", c.Body)
+	}
+}
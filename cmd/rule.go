@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -11,6 +12,7 @@ import (
 	"github.com/diffsec/quokka/internal/project"
 	"github.com/diffsec/quokka/internal/rule"
 	"github.com/diffsec/quokka/internal/sast"
+	"github.com/diffsec/quokka/internal/treesitter"
 	"github.com/spf13/cobra"
 )
 
@@ -32,7 +34,18 @@ var ruleAddCmd = &cobra.Command{
 The rule YAML is validated for minimal opengrep structure (top-level
 "rules:" list, each with id/message/pattern) before being written —
 malformed rules are rejected at authoring time rather than failing later
-during scan.`,
+during scan. Rules may instead carry a tree-sitter "query:" and a
+"languages:" list; those run under quokka sast --tool treesitter.
+
+The rule must also pass its tests (see quokka rule test): fixtures from
+--tests, or already under .quokka/rules/tests/<slug>/, are scanned with
+the new rule and every "ruleid:" line must match while nothing else does.
+--tests copies the fixtures into place once the rule is added.
+--skip-tests adds the rule untested.
+
+Examples:
+  quokka rule add hand-built-sql --file rule.yaml --tests ./fixtures
+  cat rule.yaml | quokka rule add hand-built-sql --created-by agent:injection-agent`,
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		p, err := project.EnsureActive()
@@ -45,6 +58,8 @@ during scan.`,
 		createdBy, _ := cmd.Flags().GetString("created-by")
 		createdFor, _ := cmd.Flags().GetString("created-for")
 		reasoning, _ := cmd.Flags().GetString("reasoning")
+		testsDir, _ := cmd.Flags().GetString("tests")
+		skipTests, _ := cmd.Flags().GetBool("skip-tests")
 
 		var content []byte
 		if file == "-" || file == "" {
@@ -74,23 +89,289 @@ during scan.`,
 		}
 
 		store := rule.NewStore(p)
-		if err := store.Add(slug, content, meta); err != nil {
-			exitError("%v", err)
+		var tested *rule.TestResult
+		if skipTests {
+			if err := store.Add(slug, content, meta); err != nil {
+				exitError("%v", err)
+			}
+		} else {
+			binary, _ := cmd.Flags().GetString("binary")
+			tested, err = sast.AddRule(sast.Scanner{Binary: binary}, store, slug, content, meta, testsDir)
+			if err != nil {
+				switch {
+				case tested == nil:
+					exitError("%v (pass --skip-tests to add the rule untested)", err)
+				case !tested.Passed():
+					printRuleTestResult(tested)
+				}
+				exitError("%v", err)
+			}
 		}
 
 		if jsonOutput {
 			if err := outputJSON(map[string]any{
 				"slug":       slug,
 				"created_by": createdBy,
+				"tests":      tested,
 			}); err != nil {
 				exitError("failed to encode JSON: %v", err)
 			}
 			return
 		}
 		fmt.Printf("Added rule %s (by %s)\n", slug, createdBy)
+		if tested != nil {
+			fmt.Printf("  tests: %d/%d expected match(es) in %d fixture file(s)\n", tested.Matched, tested.Expected, tested.Files)
+		} else {
+			fmt.Println("  tests: skipped")
+		}
+	},
+}
+
+// runRuleTests runs rule YAML against fixtures with the opengrep binary
+// from --binary.
+func runRuleTests(cmd *cobra.Command, slug string, content []byte, fixtureDir string) (*rule.TestResult, error) {
+	binary, _ := cmd.Flags().GetString("binary")
+	return sast.RunRuleTests(sast.Scanner{Binary: binary}, slug, content, fixtureDir)
+}
+
+func printRuleTestResult(res *rule.TestResult) {
+	status := "PASS"
+	if !res.Passed() {
+		status = "FAIL"
+	}
+	fmt.Printf("%s %s: %d/%d expected match(es) in %d file(s)\n", status, res.Slug, res.Matched, res.Expected, res.Files)
+	for _, e := range res.Missed {
+		fmt.Printf("  missed:     %s:%d  %s should match\n", e.File, e.Line, e.RuleID)
+	}
+	for _, m := range res.Unexpected {
+		fmt.Printf("  unexpected: %s:%d  %s matched\n", m.File, m.Line, m.RuleID)
+	}
+}
+
+var ruleTestCmd = &cobra.Command{
+	Use:   "test <slug>...",
+	Short: "Run rules against their annotated fixtures",
+	Long: `Scans the fixture files under .quokka/rules/tests/<slug>/ with the rule
+and checks the results against inline annotations, following the opengrep
+convention: a comment "ruleid: <rule-id>" marks the next line as one the
+rule must match, "ok: <rule-id>" as one it must not. Any match on a line
+not annotated "ruleid:" is reported as unexpected.
+
+Exits non-zero if any rule misses an expected match, matches anything
+else, or has no fixtures.
+
+Examples:
+  quokka rule test hand-built-sql
+  quokka rule test --all --json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		p, err := project.EnsureActive()
+		if err != nil {
+			exitError("%v", err)
+		}
+		store := rule.NewStore(p)
+		slugs := args
+		if all, _ := cmd.Flags().GetBool("all"); all {
+			metas, err := store.List()
+			if err != nil {
+				exitError("%v", err)
+			}
+			slugs = nil
+			for _, m := range metas {
+				if !m.Disabled {
+					slugs = append(slugs, m.Slug)
+				}
+			}
+		}
+		if len(slugs) == 0 {
+			exitError("specify at least one rule slug or --all")
+		}
+
+		results := make([]*rule.TestResult, 0, len(slugs))
+		failed := 0
+		for _, slug := range slugs {
+			content, err := store.ReadRule(slug)
+			if err != nil {
+				exitError("rule %q not found", slug)
+			}
+			res, err := runRuleTests(cmd, slug, content, store.TestDir(slug))
+			if err != nil {
+				exitError("%v", err)
+			}
+			if !res.Passed() {
+				failed++
+			}
+			results = append(results, res)
+		}
+
+		if jsonOutput {
+			if err := outputJSON(results); err != nil {
+				exitError("failed to encode JSON: %v", err)
+			}
+		} else {
+			for _, res := range results {
+				printRuleTestResult(res)
+			}
+		}
+		if failed > 0 {
+			exitError("%d of %d rule(s) failed their tests", failed, len(results))
+		}
+	},
+}
+
+var ruleProposeCmd = &cobra.Command{
+	Use:   "propose",
+	Short: "Draft a rule from a confirmed finding",
+	Long: `Generalises a finding into a draft opengrep rule. The sink call at the
+finding's location (or its flow trace's sink) becomes the pattern; locals
+of the enclosing function, found from its tree-sitter AST, become
+metavariables while module and global names stay literal, and string
+literals become "...". When the flow trace has a source such as
+request.args.get(...), the rule is taint mode from that source to the
+sink; otherwise the assignment that built the sink's argument is kept as
+a pattern-inside.
+
+The draft is scanned across the repository and stored as
+.quokka/rules/<slug>.yaml with draft: true in its sidecar, along with
+the reasoning (including the match count) and a fixture built from the
+enclosing function. A draft that does not match the finding it came from
+is not stored. quokka sast skips drafts until a keep verdict promotes
+them, which requires the fixture to pass.
+
+Examples:
+  quokka rule propose --from-finding FIND-042
+  quokka rule propose --from-finding FIND-042 --slug flask-sql-concat --json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		p, err := project.EnsureActive()
+		if err != nil {
+			exitError("%v", err)
+		}
+		id, _ := cmd.Flags().GetString("from-finding")
+		slug, _ := cmd.Flags().GetString("slug")
+		createdBy, _ := cmd.Flags().GetString("created-by")
+		skipScan, _ := cmd.Flags().GetBool("skip-scan")
+		binary, _ := cmd.Flags().GetString("binary")
+		if id == "" {
+			exitError("--from-finding is required")
+		}
+
+		findingStore := finding.NewStore(p)
+		f, err := findingStore.Read(id)
+		_ = findingStore.Close()
+		if err != nil {
+			exitError("finding %s not found: %v", id, err)
+		}
+		if f.Status == finding.StatusFalsePositive || f.Status == finding.StatusDuplicate {
+			exitError("finding %s is %s; propose rules from real findings only", id, f.Status)
+		}
+
+		store := rule.NewStore(p)
+		if slug == "" {
+			slug = rule.ProposalSlug(f)
+			for base, n := slug, 2; ruleExists(store, slug); n++ {
+				slug = fmt.Sprintf("%s-%d", base, n)
+			}
+		} else if ruleExists(store, slug) {
+			exitError("rule %q already exists", slug)
+		}
+
+		in := rule.ProposeInput{
+			Finding:  f,
+			Slug:     slug,
+			Language: treesitter.DetectLanguageName(f.Location.File),
+		}
+		path := filepath.Join(p.RootPath, filepath.FromSlash(f.Location.File))
+		if in.Source, err = os.ReadFile(path); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v; generalising from the finding's snippet\n", err)
+		}
+
+		prop, err := rule.Propose(in)
+		if err != nil {
+			exitError("%v", err)
+		}
+
+		matches, matchedOrigin := -1, false
+		if !skipScan {
+			results, err := sast.ScanRule(sast.Scanner{Binary: binary}, prop.Content, p.RootPath, []string{p.RootPath})
+			if err != nil {
+				exitError("scan draft rule: %v (pass --skip-scan to store it unscanned)", err)
+			}
+			matches = len(results)
+			for _, r := range results {
+				if filepath.ToSlash(r.Location.File) == f.Location.File && r.Location.LineStart == prop.SinkLine {
+					matchedOrigin = true
+				}
+			}
+			if !matchedOrigin {
+				if !jsonOutput {
+					fmt.Print(string(prop.Content))
+				}
+				exitError("draft rule does not match %s at %s:%d (it matched %d other location(s)); not stored", f.ID, f.Location.File, prop.SinkLine, matches)
+			}
+			prop.Reasoning += fmt.Sprintf(" Matched %d location(s) across the repository, including the original finding.", matches)
+		} else {
+			prop.Reasoning += " Stored unscanned (--skip-scan): whether it matches the original finding is unverified."
+		}
+
+		meta := rule.Meta{
+			CreatedBy:  createdBy,
+			CreatedAt:  time.Now().UTC(),
+			CreatedFor: f.ID,
+			Reasoning:  prop.Reasoning,
+			Draft:      true,
+		}
+		if err := store.Add(slug, prop.Content, meta); err != nil {
+			exitError("%v", err)
+		}
+		if err := writeProposalFixture(store, prop); err != nil {
+			exitError("rule added but saving its fixture failed: %v", err)
+		}
+
+		if jsonOutput {
+			out := map[string]any{
+				"slug":       slug,
+				"rule_id":    prop.RuleID,
+				"mode":       prop.Mode,
+				"draft":      true,
+				"created_by": createdBy,
+				"reasoning":  prop.Reasoning,
+				"rule":       string(prop.Content),
+			}
+			if matches >= 0 {
+				out["matches"] = matches
+				out["matched_origin"] = matchedOrigin
+			}
+			if err := outputJSON(out); err != nil {
+				exitError("failed to encode JSON: %v", err)
+			}
+			return
+		}
+		fmt.Printf("Proposed draft rule %s (%s mode, by %s)\n\n", slug, prop.Mode, createdBy)
+		fmt.Print(string(prop.Content))
+		fmt.Printf("\n%s\n", prop.Reasoning)
+		fmt.Printf("\nReview .quokka/rules/%s.yaml and its fixture, run quokka rule test %s, then promote it with:\n", slug, slug)
+		fmt.Printf("  quokka rule annotate %s --verdict keep\n", slug)
 	},
 }
 
+// writeProposalFixture stores a proposal's generated fixture as the rule's
+// test.
+func writeProposalFixture(store *rule.Store, prop *rule.Proposal) error {
+	dir, err := os.MkdirTemp("", "quokka-propose-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	if err := os.WriteFile(filepath.Join(dir, prop.FixtureName), []byte(prop.Fixture), 0644); err != nil {
+		return err
+	}
+	return store.SetTests(prop.Slug, dir)
+}
+
+func ruleExists(store *rule.Store, slug string) bool {
+	_, err := store.ReadRule(slug)
+	return err == nil
+}
+
 var ruleListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List project-local rules",
@@ -118,6 +399,8 @@ var ruleListCmd = &cobra.Command{
 			status := "enabled"
 			if m.Disabled {
 				status = "disabled"
+			} else if m.Draft {
+				status = "draft"
 			}
 			verdict := string(m.Verdict)
 			if verdict == "" {
@@ -158,7 +441,14 @@ var ruleAnnotateCmd = &cobra.Command{
 	Short: "Set the rule-judge-agent verdict on a rule",
 	Long: `Records a verdict (keep / refine / retire / escalate) on the rule's
 metadata. Retire marks the rule disabled, so subsequent quokka sast runs skip
-it; the rule file itself is preserved for archaeology.`,
+it; the rule file itself is preserved for archaeology. Keep promotes a draft
+from quokka rule propose so it is scanned, but only if it passes its
+fixtures under .quokka/rules/tests/<slug>/; a failing draft is left
+untouched.
+
+Examples:
+  quokka rule annotate cwe-89-execute --verdict keep --note "matches are real"
+  quokka rule annotate weak-hash --verdict retire`,
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		p, err := project.EnsureActive()
@@ -171,17 +461,29 @@ it; the rule file itself is preserved for archaeology.`,
 		if !rule.IsValidVerdict(verdict) {
 			exitError("invalid --verdict %q (valid: keep, refine, retire, escalate)", verdictStr)
 		}
-		store := rule.NewStore(p)
-		if err := store.Annotate(args[0], verdict, note); err != nil {
+		binary, _ := cmd.Flags().GetString("binary")
+		res, err := sast.AnnotateRule(sast.Scanner{Binary: binary}, rule.NewStore(p), args[0], verdict, note)
+		if err != nil {
+			if res != nil && !jsonOutput {
+				printRuleTestResult(res)
+			}
 			exitError("%v", err)
 		}
 		if jsonOutput {
-			if err := outputJSON(map[string]any{"slug": args[0], "verdict": verdict, "note": note}); err != nil {
+			out := map[string]any{"slug": args[0], "verdict": verdict, "note": note}
+			if res != nil {
+				out["tests"] = res
+			}
+			if err := outputJSON(out); err != nil {
 				exitError("failed to encode JSON: %v", err)
 			}
 			return
 		}
 		fmt.Printf("Annotated %s: verdict=%s\n", args[0], verdict)
+		if res != nil {
+			printRuleTestResult(res)
+			fmt.Println("(Draft promoted — quokka sast will scan it.)")
+		}
 		if verdict == rule.VerdictRetire {
 			fmt.Println("(Rule will be skipped by quokka sast — file preserved.)")
 		}
@@ -203,11 +505,20 @@ type ruleAuditEntry struct {
 	VerdictNote string       `json:"verdict_note,omitempty"`
 	LastAuditAt time.Time    `json:"last_audit_at,omitempty"`
 
-	// Activity signals (populated when quokka tracks them; zero otherwise).
-	TriggerCount int `json:"trigger_count"`
-	FPCount      int `json:"fp_count"`
+	// Activity signals from the rule activity series, recorded by
+	// quokka sast and by triage of the findings each rule produced.
+	TriggerCount   int        `json:"trigger_count"`
+	FPCount        int        `json:"fp_count"`
+	ConfirmedCount int        `json:"confirmed_count"`
+	FPRatio        float64    `json:"fp_ratio"`
+	LastTriggerAt  *time.Time `json:"last_trigger_at,omitempty"`
+	// DaysSinceTrigger is -1 for a rule that has never fired.
+	DaysSinceTrigger int `json:"days_since_trigger"`
+	// RetireSignals lists the retirement thresholds the rule has crossed.
+	RetireSignals []string `json:"retire_signals,omitempty"`
 
 	Disabled bool   `json:"disabled"`
+	Draft    bool   `json:"draft,omitempty"`
 	RuleBody string `json:"rule_body"` // raw opengrep YAML content
 }
 
@@ -219,6 +530,13 @@ verdict history, and rule body — designed to be consumed by the
 rule-judge-agent. With --json (recommended), the agent can parse the result
 in one call and emit annotate verdicts for each rule.
 
+Trigger and false-positive counts come from the rule activity series
+(.quokka/rules/activity.jsonl): quokka sast records every firing of a
+project-local rule, and triaging a finding it produced as false_positive or
+confirmed records the verdict. Rules crossing a retirement threshold — no
+fires in --stale-days, or an FP ratio above --max-fp-ratio over at least
+--min-triaged triaged findings — carry retire_signals.
+
 This command makes no changes. The judge applies verdicts via
 quokka rule annotate.`,
 	Run: func(cmd *cobra.Command, args []string) {
@@ -232,11 +550,21 @@ quokka rule annotate.`,
 			exitError("%v", err)
 		}
 
-		// Compute live trigger and false-positive counts per rule slug by
-		// walking the finding store. Findings carry "opengrep-rule:<id>"
-		// tags from internal/sast; we map those back to slug via the
-		// rule store's RuleIDToSlug.
-		triggers, fps := computeRuleCounts(p, store)
+		events, err := store.Events("")
+		if err != nil {
+			exitError("%v", err)
+		}
+		policy := rule.DefaultRetirePolicy()
+		if cmd.Flags().Changed("stale-days") {
+			policy.StaleDays, _ = cmd.Flags().GetInt("stale-days")
+		}
+		if cmd.Flags().Changed("max-fp-ratio") {
+			policy.MaxFPRatio, _ = cmd.Flags().GetFloat64("max-fp-ratio")
+		}
+		if cmd.Flags().Changed("min-triaged") {
+			policy.MinTriaged, _ = cmd.Flags().GetInt("min-triaged")
+		}
+		now := time.Now().UTC()
 
 		entries := make([]ruleAuditEntry, 0, len(metas))
 		for _, m := range metas {
@@ -244,19 +572,26 @@ quokka rule annotate.`,
 			if err != nil {
 				continue
 			}
+			a := rule.Summarize(m.Slug, events)
 			entries = append(entries, ruleAuditEntry{
-				Slug:         m.Slug,
-				CreatedBy:    m.CreatedBy,
-				CreatedAt:    m.CreatedAt,
-				CreatedFor:   m.CreatedFor,
-				Reasoning:    m.Reasoning,
-				Verdict:      m.Verdict,
-				VerdictNote:  m.VerdictNote,
-				LastAuditAt:  m.LastAuditAt,
-				TriggerCount: triggers[m.Slug],
-				FPCount:      fps[m.Slug],
-				Disabled:     m.Disabled,
-				RuleBody:     string(body),
+				Slug:             m.Slug,
+				CreatedBy:        m.CreatedBy,
+				CreatedAt:        m.CreatedAt,
+				CreatedFor:       m.CreatedFor,
+				Reasoning:        m.Reasoning,
+				Verdict:          m.Verdict,
+				VerdictNote:      m.VerdictNote,
+				LastAuditAt:      m.LastAuditAt,
+				TriggerCount:     a.Triggers,
+				FPCount:          a.FalsePositives,
+				ConfirmedCount:   a.Confirmed,
+				FPRatio:          a.FPRatio,
+				LastTriggerAt:    a.LastTriggerAt,
+				DaysSinceTrigger: a.DaysSinceTrigger(now),
+				RetireSignals:    policy.Signals(m, a, now),
+				Disabled:         m.Disabled,
+				Draft:            m.Draft,
+				RuleBody:         string(body),
 			})
 		}
 
@@ -287,7 +622,13 @@ quokka rule annotate.`,
 			if !e.LastAuditAt.IsZero() {
 				fmt.Printf("  last_audit:   %s\n", e.LastAuditAt.Format(time.RFC3339))
 			}
-			fmt.Printf("  triggers:     %d (fp: %d)\n", e.TriggerCount, e.FPCount)
+			fmt.Printf("  triggers:     %d (fp: %d, confirmed: %d)\n", e.TriggerCount, e.FPCount, e.ConfirmedCount)
+			if e.LastTriggerAt != nil {
+				fmt.Printf("  last_trigger: %s (%d days ago)\n", e.LastTriggerAt.Format(time.RFC3339), e.DaysSinceTrigger)
+			}
+			for _, sig := range e.RetireSignals {
+				fmt.Printf("  retire?:      %s\n", sig)
+			}
 			fmt.Printf("  disabled:     %v\n", e.Disabled)
 			fmt.Println()
 		}
@@ -295,57 +636,68 @@ quokka rule annotate.`,
 	},
 }
 
-// computeRuleCounts walks the finding store filtering created_by=opengrep
-// and returns per-rule-slug trigger and false-positive counts. Findings
-// from opengrep rules that aren't in the project's local set (e.g. bundled
-// rule packs) are ignored — only project-local rules get counted.
-func computeRuleCounts(p *project.Project, rs *rule.Store) (triggers, fps map[string]int) {
-	triggers = map[string]int{}
-	fps = map[string]int{}
-
-	idToSlug, err := rs.RuleIDToSlug()
-	if err != nil || len(idToSlug) == 0 {
-		return triggers, fps
-	}
+var ruleHistoryCmd = &cobra.Command{
+	Use:   "history <slug>",
+	Short: "Show a rule's trigger and triage time series",
+	Long: `Prints the activity series for one project-local rule: every firing
+recorded by quokka sast and every false_positive, confirmed or reopened
+triage of a finding it produced, oldest first.
 
-	fs := finding.NewStore(p)
-	all, err := fs.List(&finding.FilterOptions{})
-	if err != nil {
-		return triggers, fps
-	}
-	for _, f := range all.Findings {
-		if f.CreatedBy != "opengrep" {
-			continue
+Examples:
+  quokka rule history hand-built-sql
+  quokka rule history hand-built-sql --limit 20 --json`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		p, err := project.EnsureActive()
+		if err != nil {
+			exitError("%v", err)
 		}
-		slug := ""
-		for _, tag := range f.Tags {
-			if !strings.HasPrefix(tag, sast.OpengrepRuleTagPrefix) {
-				continue
-			}
-			id := strings.TrimPrefix(tag, sast.OpengrepRuleTagPrefix)
-			// Opengrep prefixes rule IDs with their file path
-			// (e.g. "tmp.rdbg..quokka.quokka-admin-default-creds"), so we
-			// also accept a match on the dotted suffix.
-			if s, ok := idToSlug[id]; ok {
-				slug = s
-				break
-			}
-			if idx := strings.LastIndex(id, "."); idx >= 0 {
-				if s, ok := idToSlug[id[idx+1:]]; ok {
-					slug = s
-					break
-				}
+		store := rule.NewStore(p)
+		slug := args[0]
+		if _, err := store.ReadRule(slug); err != nil {
+			exitError("rule %q not found", slug)
+		}
+		events, err := store.Events(slug)
+		if err != nil {
+			exitError("%v", err)
+		}
+		activity := rule.Summarize(slug, events)
+		if limit, _ := cmd.Flags().GetInt("limit"); limit > 0 && len(events) > limit {
+			events = events[len(events)-limit:]
+		}
+
+		if jsonOutput {
+			if err := outputJSON(map[string]any{
+				"activity": activity,
+				"events":   events,
+			}); err != nil {
+				exitError("failed to encode JSON: %v", err)
 			}
+			return
 		}
-		if slug == "" {
-			continue
+		for _, e := range events {
+			line := fmt.Sprintf("%s  %-14s", e.Time.Format(time.RFC3339), e.Kind)
+			if e.FindingID != "" {
+				line += "  " + e.FindingID
+			}
+			fmt.Println(line)
 		}
-		triggers[slug]++
-		if f.Status == finding.StatusFalsePositive {
-			fps[slug]++
+		fmt.Printf("\n%d trigger(s), %d false positive(s), %d confirmed", activity.Triggers, activity.FalsePositives, activity.Confirmed)
+		if activity.Triaged() > 0 {
+			fmt.Printf(" (fp ratio %.0f%%)", activity.FPRatio*100)
 		}
-	}
-	return triggers, fps
+		fmt.Println()
+	},
+}
+
+// trackRuleTriage makes status changes on store feed the rule activity
+// series, so triaging an opengrep finding updates the FP accounting of the
+// project-local rule that produced it.
+func trackRuleTriage(p *project.Project, store *finding.Store) *finding.Store {
+	store.OnStatusChange(sast.TriageRecorder(rule.NewStore(p), func(err error) {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record rule activity: %v\n", err)
+	}))
+	return store
 }
 
 func defaultCreatedBy() string {
@@ -362,12 +714,35 @@ func init() {
 	ruleCmd.AddCommand(ruleRemoveCmd)
 	ruleCmd.AddCommand(ruleAnnotateCmd)
 	ruleCmd.AddCommand(ruleAuditCmd)
+	ruleCmd.AddCommand(ruleHistoryCmd)
+	ruleCmd.AddCommand(ruleTestCmd)
+	ruleCmd.AddCommand(ruleProposeCmd)
 
 	ruleAddCmd.Flags().String("file", "-", "Path to rule YAML (default: stdin)")
 	ruleAddCmd.Flags().String("created-by", "", "Attribution (e.g. agent:injection-agent or human:alice). Defaults to human:$USER.")
 	ruleAddCmd.Flags().String("created-for", "", "Optional context (e.g. PR #482)")
 	ruleAddCmd.Flags().String("reasoning", "", "Why this rule was added (encouraged, not required)")
+	ruleAddCmd.Flags().String("tests", "", "Directory of annotated fixture files (default: .quokka/rules/tests/<slug>/)")
+	ruleAddCmd.Flags().Bool("skip-tests", false, "Add the rule without running its tests")
+	ruleAddCmd.Flags().String("binary", "", "Override opengrep binary path (default: opengrep on PATH)")
+
+	ruleTestCmd.Flags().Bool("all", false, "Test every enabled rule")
+	ruleTestCmd.Flags().String("binary", "", "Override opengrep binary path (default: opengrep on PATH)")
+
+	ruleProposeCmd.Flags().String("from-finding", "", "Finding ID to generalise (required)")
+	ruleProposeCmd.Flags().String("slug", "", "Rule slug (default: derived from the finding's CWE and sink)")
+	ruleProposeCmd.Flags().String("created-by", "agent:rule-propose", "Attribution recorded in the draft's metadata")
+	ruleProposeCmd.Flags().Bool("skip-scan", false, "Store the draft without scanning the repository")
+	ruleProposeCmd.Flags().String("binary", "", "Override opengrep binary path (default: opengrep on PATH)")
 
 	ruleAnnotateCmd.Flags().String("verdict", "", "Verdict: keep, refine, retire, escalate (required)")
 	ruleAnnotateCmd.Flags().String("note", "", "Free-form note explaining the verdict")
+	ruleAnnotateCmd.Flags().String("binary", "", "Override opengrep binary path (default: opengrep on PATH)")
+
+	policy := rule.DefaultRetirePolicy()
+	ruleAuditCmd.Flags().Int("stale-days", policy.StaleDays, "Flag rules with no fires in this many days (0 disables)")
+	ruleAuditCmd.Flags().Float64("max-fp-ratio", policy.MaxFPRatio, "Flag rules whose false-positive ratio exceeds this")
+	ruleAuditCmd.Flags().Int("min-triaged", policy.MinTriaged, "Triaged findings needed before the FP ratio is considered")
+
+	ruleHistoryCmd.Flags().Int("limit", 0, "Show only the most recent N events (0 = all)")
 }
@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"github.com/diffsec/quokka/internal/finding"
+	"github.com/diffsec/quokka/internal/gitdiff"
 	"github.com/diffsec/quokka/internal/project"
 	"github.com/diffsec/quokka/internal/rule"
 	"github.com/diffsec/quokka/internal/sast"
@@ -20,7 +21,27 @@ persists findings into the quokka store.
 Findings created here have created_by="opengrep" and status="open", so the
 sast-triage-agent can later mark false positives or confirm them. They flow
 through the same fingerprint pipeline as LLM-agent findings, so duplicates
-across SAST + LLM dedup automatically in SARIF code-scanning uploads.`,
+across SAST + LLM dedup automatically in SARIF code-scanning uploads.
+
+With --diff, only changed files are scanned and results are then scoped by
+the diff hunks: --scope changed-lines keeps results overlapping a changed
+line, changed-function (the default) also keeps results inside a function
+the diff touches, and file keeps everything in a changed file. Results
+outside the scope are pre-existing and not persisted.
+
+--tool treesitter needs no opengrep binary: it runs rules written as
+tree-sitter queries (a "query:" with predicates such as #eq? and #match?
+in place of "pattern:") with quokka's bundled grammars. Enabled,
+non-draft query rules in .quokka/rules are picked up automatically, and
+--config adds a rule file or directory; each rule file is loaded once.
+Findings have created_by="treesitter" and otherwise the same shape as
+opengrep's.
+
+Examples:
+  quokka sast --config p/security-audit
+  quokka sast --config .quokka/rules --diff origin/main
+  quokka sast --config p/owasp-top-ten --diff origin/main --scope changed-lines
+  quokka sast --tool treesitter --diff origin/main`,
 	Run: func(cmd *cobra.Command, args []string) {
 		p, err := project.EnsureActive()
 		if err != nil {
@@ -28,16 +49,24 @@ across SAST + LLM dedup automatically in SARIF code-scanning uploads.`,
 		}
 
 		config, _ := cmd.Flags().GetString("config")
-		if config == "" {
-			exitError("--config is required (path to rules dir, single YAML, or registry id like p/security-audit)")
-		}
 		tool, _ := cmd.Flags().GetString("tool")
-		if tool != "opengrep" {
-			exitError("unsupported --tool %q (supported: opengrep)", tool)
+		switch tool {
+		case "opengrep":
+			if config == "" {
+				exitError("--config is required (path to rules dir, single YAML, or registry id like p/security-audit)")
+			}
+		case "treesitter":
+		default:
+			exitError("unsupported --tool %q (supported: opengrep, treesitter)", tool)
 		}
 		binary, _ := cmd.Flags().GetString("binary")
 		diffBase, _ := cmd.Flags().GetString("diff")
 		targetFlag, _ := cmd.Flags().GetStringSlice("path")
+		scopeFlag, _ := cmd.Flags().GetString("scope")
+		limit, err := gitdiff.ParseScope(scopeFlag)
+		if err != nil {
+			exitError("--scope: %v", err)
+		}
 
 		// Determine the target paths the scanner walks. Default to the project
 		// root; --path scopes to specific files/dirs; --diff scopes to the
@@ -71,26 +100,60 @@ across SAST + LLM dedup automatically in SARIF code-scanning uploads.`,
 		// becomes an extra --config to opengrep, so org-specific rules
 		// apply automatically alongside the user's chosen ruleset. Retired
 		// rules (verdict=retire on their metadata) are skipped here.
+		// Tree-sitter query rules go to the built-in engine instead.
 		ruleStore := rule.NewStore(p)
-		localRulePaths, err := ruleStore.EnabledRulePaths()
-		if err != nil {
-			exitError("read project rules: %v", err)
-		}
-
-		scanner := &sast.Scanner{
-			Binary:       binary,
-			Config:       config,
-			ExtraConfigs: localRulePaths,
-			ProjectRoot:  p.RootPath,
+		var results []finding.Finding
+		var localRulePaths []string
+		if tool == "treesitter" {
+			localRulePaths, err = ruleStore.EnabledQueryRulePaths()
+			if err != nil {
+				exitError("read project rules: %v", err)
+			}
+			configs := localRulePaths
+			if config != "" {
+				configs = append([]string{config}, configs...)
+			}
+			if len(configs) == 0 {
+				exitError("no tree-sitter query rules: add rules with a \"query:\" to .quokka/rules or pass --config")
+			}
+			scanner := &sast.QueryScanner{Configs: configs, Store: ruleStore, ProjectRoot: p.RootPath}
+			results, err = scanner.Scan(targets)
+		} else {
+			localRulePaths, err = ruleStore.EnabledRulePaths()
+			if err != nil {
+				exitError("read project rules: %v", err)
+			}
+			scanner := &sast.Scanner{
+				Binary:       binary,
+				Config:       config,
+				ExtraConfigs: localRulePaths,
+				ProjectRoot:  p.RootPath,
+			}
+			results, err = scanner.Scan(targets)
 		}
-		results, err := scanner.Scan(targets)
 		if err != nil {
 			exitError("%v", err)
 		}
 
+		// With --diff, scope results by the hunks so untouched code in a
+		// changed file doesn't produce findings on every PR.
+		var classifier *gitdiff.Classifier
+		if diffBase != "" {
+			c, closeSymbols, err := diffClassifier(p, diffBase)
+			if err != nil {
+				exitError("%v", err)
+			}
+			defer closeSymbols()
+			classifier = c
+		}
+
 		store := finding.NewStore(p)
+		defer func() { _ = store.Close() }()
+
 		created := 0
 		skipped := 0
+		outOfScope := 0
+		var stored []finding.Finding
 		for _, f := range results {
 			f := f
 			// Skip results outside the project (opengrep can pick these up
@@ -99,11 +162,22 @@ across SAST + LLM dedup automatically in SARIF code-scanning uploads.`,
 				skipped++
 				continue
 			}
-			if err := store.Create(&f); err != nil {
+			if classifier != nil && !classifier.Classify(f.Location).Scope.Within(limit) {
+				outOfScope++
+				continue
+			}
+			if _, err := store.Create(&f); err != nil {
 				skipped++
 				continue
 			}
 			created++
+			stored = append(stored, f)
+		}
+
+		// Every firing of a project-local rule goes into the rule activity
+		// series, including re-fires on findings deduped into existing ones.
+		if err := sast.RecordFirings(ruleStore, stored); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to record rule activity: %v\n", err)
 		}
 
 		bySeverity := map[string]int{}
@@ -118,6 +192,8 @@ across SAST + LLM dedup automatically in SARIF code-scanning uploads.`,
 				"skipped":      skipped,
 				"by_severity":  bySeverity,
 				"diff_base":    diffBase,
+				"scope":        limit,
+				"out_of_scope": outOfScope,
 				"target_count": len(targets),
 			}); err != nil {
 				exitError("failed to encode JSON: %v", err)
@@ -125,7 +201,7 @@ across SAST + LLM dedup automatically in SARIF code-scanning uploads.`,
 			return
 		}
 
-		fmt.Printf("opengrep scan complete\n")
+		fmt.Printf("%s scan complete\n", tool)
 		if len(localRulePaths) > 0 {
 			fmt.Printf("  + %d project-local rule(s) from .quokka/rules\n", len(localRulePaths))
 		}
@@ -134,6 +210,9 @@ across SAST + LLM dedup automatically in SARIF code-scanning uploads.`,
 		if skipped > 0 {
 			fmt.Printf("  skipped:  %d (out-of-project or store errors)\n", skipped)
 		}
+		if outOfScope > 0 {
+			fmt.Printf("  out of scope: %d (outside --scope %s of the diff)\n", outOfScope, limit)
+		}
 		for _, sev := range []finding.Severity{finding.SeverityCritical, finding.SeverityHigh, finding.SeverityMedium, finding.SeverityLow, finding.SeverityInfo} {
 			if n := bySeverity[string(sev)]; n > 0 {
 				fmt.Printf("  %s: %d\n", sev, n)
@@ -144,9 +223,10 @@ across SAST + LLM dedup automatically in SARIF code-scanning uploads.`,
 
 func init() {
 	rootCmd.AddCommand(sastCmd)
-	sastCmd.Flags().String("tool", "opengrep", "SAST tool to invoke (currently only: opengrep)")
-	sastCmd.Flags().String("config", "", "opengrep --config arg: rules path or registry id (required)")
+	sastCmd.Flags().String("tool", "opengrep", "SAST tool to invoke: opengrep, or treesitter (built-in engine for tree-sitter query rules)")
+	sastCmd.Flags().String("config", "", "opengrep --config arg: rules path or registry id (required for opengrep); with treesitter, a query rule file or directory")
 	sastCmd.Flags().String("binary", "", "Override opengrep binary path (default: opengrep on PATH)")
 	sastCmd.Flags().String("diff", "", "Scope scan to files changed since this git ref (e.g. origin/main)")
+	sastCmd.Flags().String("scope", "changed-function", "With --diff: changed-lines, changed-function or file (keep every result in a changed file)")
 	sastCmd.Flags().StringSlice("path", nil, "Explicit paths to scan (repeatable). Default: project root.")
 }
@@ -22,8 +22,16 @@ var semanticCmd = &cobra.Command{
 Semantic search uses embeddings to find code that matches the meaning
 of your query, not just exact text matches.
 
+By default results are hybrid: the embedding ranking is fused with a BM25
+keyword ranking (reciprocal-rank fusion), so exact identifiers such as
+verifyHMAC or X-Forwarded-For are found even when embeddings miss them.
+Use --mode vector or --mode lexical to use a single retriever. With
+--json each hybrid result carries its per-retriever scores and ranks.
+
 Examples:
   quokka semantic "authentication middleware"
+  quokka semantic "verifyHMAC" --mode lexical
+  quokka semantic "X-Forwarded-For trust" --json
   quokka semantic "SQL injection vulnerabilities" --multi-hop
   quokka semantic "error handling" --type function
   quokka semantic "database connection" --file "*.go"`,
@@ -39,6 +47,7 @@ Examples:
 		}
 
 		query := args[0]
+		modeFlag, _ := cmd.Flags().GetString("mode")
 		limit, _ := cmd.Flags().GetInt("limit")
 		multiHop, _ := cmd.Flags().GetBool("multi-hop")
 		maxHops, _ := cmd.Flags().GetInt("max-hops")
@@ -48,6 +57,11 @@ Examples:
 		langFilter, _ := cmd.Flags().GetString("language")
 		timeout, _ := cmd.Flags().GetInt("timeout")
 
+		mode, err := semantic.ParseSearchMode(modeFlag)
+		if err != nil {
+			exitError("%v", err)
+		}
+
 		// Create indexer and searcher
 		indexer, err := createIndexer(p)
 		if err != nil {
@@ -77,6 +91,7 @@ Examples:
 
 		// Build search options
 		opts := &semantic.SearchOptions{
+			Mode:      mode,
 			Limit:     limit,
 			MultiHop:  multiHop,
 			MaxHops:   maxHops,
@@ -89,6 +104,7 @@ Examples:
 
 		if !jsonOutput && verbose {
 			fmt.Printf("Searching for: %s\n", query)
+			fmt.Printf("  Mode: %s\n", mode)
 			if multiHop {
 				fmt.Printf("  Multi-hop: enabled (max %d hops)\n", maxHops)
 			}
@@ -112,6 +128,12 @@ Examples:
 
 // printSearchResults prints search results in human-readable format
 func printSearchResults(results *semantic.SearchResults, verbose bool) {
+	for _, n := range results.Notes {
+		fmt.Printf("Note: %s\n", n)
+	}
+	if len(results.Notes) > 0 {
+		fmt.Println()
+	}
 	if len(results.Results) == 0 {
 		fmt.Println("No results found")
 		return
@@ -123,7 +145,11 @@ func printSearchResults(results *semantic.SearchResults, verbose bool) {
 		if r.Chunk.StartLine > 0 {
 			fmt.Printf(":%d-%d", r.Chunk.StartLine, r.Chunk.EndLine)
 		}
-		fmt.Printf(" (score: %.3f)\n", r.Score)
+		if s := r.Scores; s != nil {
+			fmt.Printf(" (score: %.4f, vector: %s, lexical: %s)\n", r.Score, rankLabel(s.VectorRank), rankLabel(s.LexicalRank))
+		} else {
+			fmt.Printf(" (score: %.3f)\n", r.Score)
+		}
 
 		// Symbol info
 		fmt.Printf("   %s %s", r.Chunk.Type, r.Chunk.Name)
@@ -164,9 +190,20 @@ func printSearchResults(results *semantic.SearchResults, verbose bool) {
 	if results.TotalHops > 1 {
 		fmt.Printf(" in %d hops", results.TotalHops)
 	}
+	if results.Mode != "" {
+		fmt.Printf(" [%s]", results.Mode)
+	}
 	fmt.Printf(" (%s)\n", results.Duration.Round(time.Millisecond))
 }
 
+// rankLabel renders a 1-based retriever rank, "-" when it did not match
+func rankLabel(rank int) string {
+	if rank == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("#%d", rank)
+}
+
 // semanticRelatedCmd represents the semantic related command
 var semanticRelatedCmd = &cobra.Command{
 	Use:   "related <file>",
@@ -223,10 +260,11 @@ func init() {
 	semanticCmd.AddCommand(semanticRelatedCmd)
 
 	// Search flags
+	semanticCmd.Flags().String("mode", string(semantic.ModeHybrid), "Retrieval mode: hybrid, vector or lexical")
 	semanticCmd.Flags().IntP("limit", "l", 10, "Maximum number of results")
 	semanticCmd.Flags().Bool("multi-hop", false, "Enable multi-hop exploration")
 	semanticCmd.Flags().Int("max-hops", 3, "Maximum hops for multi-hop search")
-	semanticCmd.Flags().Float32P("threshold", "t", 0.0, "Minimum similarity score (0-1)")
+	semanticCmd.Flags().Float32P("threshold", "t", 0.0, "Minimum vector similarity score (0-1)")
 	semanticCmd.Flags().String("type", "", "Filter by chunk type (function, method, class, struct, interface)")
 	semanticCmd.Flags().StringP("file", "f", "", "Filter by file pattern (glob)")
 	semanticCmd.Flags().String("language", "", "Filter by language")
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/diffsec/quokka/internal/project"
@@ -26,7 +27,8 @@ Available verbs:
   next       - Rank next actions from current state
   hypothesis - Generate ranked CWE hypotheses from tech stack + memories
   validate   - Validate a specific finding against its code context
-  dataflow   - Trace source-to-sink chains in project code`,
+  dataflow   - Trace source-to-sink chains in project code (regex or taint engine)
+  model      - Inspect and lint the project's custom taint model`,
 }
 
 // ---- collected ----
@@ -242,10 +244,20 @@ Flags:
                            The sink class is inferred from the finding's
                            CWE when --sink is not given.
   --max-chains N           Cap reported chains per file (default 8).
+  --engine regex|taint     Tracer to use (default regex).
+  --max-depth N            Call depth followed by the taint engine
+                           (default 6).
 
-The algorithm is intentionally simple: regex-based, intra-file, linear
-between source and sink lines, with guard-shaped calls reported between
-them. Use it to flag candidates, not to prove safety.`,
+The regex engine is intentionally simple: intra-file, linear between source
+and sink lines, with guard-shaped calls reported between them.
+
+The taint engine parses the project with tree-sitter, builds a def-use graph
+per function and follows calls across files through function summaries, so
+a request parameter passed through a helper module into cursor.execute is
+reported with each CALL/RETURN hop. Calls to project functions that do not
+propagate their argument act as sanitizers.
+
+Use either to flag candidates, not to prove safety.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if listClasses, _ := cmd.Flags().GetBool("list-sink-classes"); listClasses {
 			classes := think.ListSinkClasses()
@@ -278,6 +290,8 @@ them. Use it to flag candidates, not to prove safety.`,
 		file, _ := cmd.Flags().GetString("file")
 		fromFinding, _ := cmd.Flags().GetString("from-finding")
 		max, _ := cmd.Flags().GetInt("max-chains")
+		engine, _ := cmd.Flags().GetString("engine")
+		maxDepth, _ := cmd.Flags().GetInt("max-depth")
 
 		report, err := think.AnalyzeDataflow(p, think.DataflowOptions{
 			Source:      source,
@@ -286,6 +300,8 @@ them. Use it to flag candidates, not to prove safety.`,
 			File:        file,
 			FromFinding: fromFinding,
 			MaxChains:   max,
+			Engine:      engine,
+			MaxDepth:    maxDepth,
 		})
 		if err != nil {
 			exitError("%v", err)
@@ -298,6 +314,131 @@ them. Use it to flag candidates, not to prove safety.`,
 	},
 }
 
+// ---- model ----
+
+var thinkModelCmd = &cobra.Command{
+	Use:   "model",
+	Short: "Inspect the project's custom taint model",
+	Long: `Inspect .quokka/taint.yaml, the project's custom taint model.
+
+The model declares sources, sinks per sink class and sanitizers per CWE, per
+language. dataflow, validate and hypothesis merge it with the built-in
+patterns; an explicit --source or --sink is used as given.
+
+  languages:
+    go:
+      sources: ['r\.URL\.Query\(\)\.Get']
+      sinks:
+        sqli: ['db\.QueryRaw\b']
+        cmdi: ['shellutil\.Run\b']
+      sanitizers:
+        CWE-89: ['policy\.Check\s*\(']
+    "*":
+      sanitizers:
+        "*": ['sanitize\.\w+\(']
+
+A sink class that is not built in becomes a new class for --sink-class and
+joins the default mix. The "*" language applies to every file; the "*"
+sanitizer key applies to every CWE.`,
+}
+
+var thinkModelListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List built-in and custom sources, sinks and sanitizers",
+	Long: `List the merged taint model: built-in patterns plus the entries of
+.quokka/taint.yaml.
+
+Examples:
+  quokka think model list
+  quokka think model list --language go --custom
+  quokka think model list --json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		p, err := project.EnsureActive()
+		if err != nil {
+			exitError("%v", err)
+		}
+		model, err := think.ReadTaintModel(p)
+		if err != nil {
+			exitError("%v", err)
+		}
+		lang, _ := cmd.Flags().GetString("language")
+		custom, _ := cmd.Flags().GetBool("custom")
+		entries := think.ListTaintModel(model, lang, !custom)
+
+		if jsonOutput {
+			if err := outputJSON(entries); err != nil {
+				exitError("failed to encode JSON: %v", err)
+			}
+			return
+		}
+		if model == nil {
+			fmt.Printf("No custom taint model (%s); showing built-ins.\n\n", think.TaintModelPath(p))
+		}
+		if len(entries) == 0 {
+			fmt.Println("No entries.")
+			return
+		}
+		for _, e := range entries {
+			key := e.Key
+			if key == "" {
+				key = "-"
+			}
+			fmt.Printf("%-9s %-16s %-10s %-7s %s\n", e.Kind, key, e.Language, e.Origin, e.Pattern)
+		}
+	},
+}
+
+var thinkModelLintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Check the custom taint model for errors",
+	Long: `Check .quokka/taint.yaml: unknown keys, patterns that do not compile or
+match the empty string, sanitizer keys that are not CWE IDs, duplicate
+patterns and unknown languages. Exits non-zero when any error is found;
+the analyzers refuse to run with a model that has errors.
+
+Examples:
+  quokka think model lint
+  quokka think model lint --json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		p, err := project.EnsureActive()
+		if err != nil {
+			exitError("%v", err)
+		}
+		model, err := think.ReadTaintModel(p)
+		if err != nil {
+			exitError("%v", err)
+		}
+		if model == nil {
+			exitError("no taint model at %s", think.TaintModelPath(p))
+		}
+		issues := model.Lint()
+		errCount := 0
+		for _, i := range issues {
+			if i.Severity == "error" {
+				errCount++
+			}
+		}
+
+		if jsonOutput {
+			if err := outputJSON(map[string]interface{}{
+				"path":   think.TaintModelPath(p),
+				"issues": issues,
+				"errors": errCount,
+			}); err != nil {
+				exitError("failed to encode JSON: %v", err)
+			}
+		} else {
+			for _, i := range issues {
+				fmt.Printf("%s: %s: %s\n", i.Severity, i.Path, i.Message)
+			}
+			fmt.Printf("%s: %d error(s), %d warning(s)\n", think.TaintModelPath(p), errCount, len(issues)-errCount)
+		}
+		if errCount > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
 // emit prints the result in text or JSON form.
 func emit(result *think.ThinkingResult) {
 	if jsonOutput {
@@ -325,6 +466,9 @@ func init() {
 	thinkCmd.AddCommand(thinkHypothesisCmd)
 	thinkCmd.AddCommand(thinkValidateCmd)
 	thinkCmd.AddCommand(thinkDataflowCmd)
+	thinkCmd.AddCommand(thinkModelCmd)
+	thinkModelCmd.AddCommand(thinkModelListCmd)
+	thinkModelCmd.AddCommand(thinkModelLintCmd)
 
 	thinkCollectedCmd.Flags().StringSlice("memory", nil, "Restrict to one or more memory names (repeatable)")
 
@@ -348,4 +492,9 @@ func init() {
 	thinkDataflowCmd.Flags().String("file", "", "Limit analysis to one file (project-relative)")
 	thinkDataflowCmd.Flags().String("from-finding", "", "Load source/sink/file from a finding (FIND-XXX)")
 	thinkDataflowCmd.Flags().Int("max-chains", 8, "Cap reported chains per file")
+	thinkDataflowCmd.Flags().String("engine", think.EngineRegex, "Tracer: regex (intra-file) or taint (AST, inter-procedural)")
+	thinkDataflowCmd.Flags().Int("max-depth", 6, "Call depth followed by the taint engine")
+
+	thinkModelListCmd.Flags().String("language", "", "Only entries that apply to this language (e.g. go, python)")
+	thinkModelListCmd.Flags().Bool("custom", false, "Only entries from .quokka/taint.yaml")
 }
@@ -65,6 +65,13 @@ All commands output JSON when --json flag is provided.
 - quokka symbols <file>        Extract code symbols
 - quokka symbols find <name>   Find symbol globally
 - quokka symbols refs <name>   Find references to symbol
+- quokka navigate callers <fn> Tree of functions calling fn
+- quokka navigate callees <fn> Tree of functions fn calls
+  --depth N, -d              Levels to expand (default 3)
+- quokka navigate definition <file>:<line>:<col>  Go to definition
+- quokka navigate refs <file>:<line>:<col>        Find references
+- quokka navigate routes       HTTP routes with handlers and middleware
+  --write-memory <name>      Store as a memory (e.g. api_endpoints)
 
 ### Memory Management
 - quokka memory list           List all memories
@@ -89,9 +96,17 @@ All commands output JSON when --json flag is provided.
 - quokka finding export        Export findings
   --format sarif|json|md|html|csv
   --output <file>            Output file
+- quokka finding import <file> Import findings
+  --format sarif|semgrep-json|bandit-json|gosec-json
+  --created-by codeql        Override attribution
 - quokka finding stats         Show statistics
 - quokka finding delete <id>   Delete a finding
 
+### Dependencies
+- quokka deps scan             Match pinned deps against an offline OSV DB
+  --db <path>                OSV dir, all.zip or JSON (default .quokka/osv)
+  --diff <ref>               Only manifests changed since ref
+
 ### Thinking Tools
 - quokka think collected       Evaluate collected info
 - quokka think adherence       Check task adherence
@@ -99,6 +114,8 @@ All commands output JSON when --json flag is provided.
 - quokka think next            Suggest next steps
 - quokka think hypothesis      Generate hypotheses
 - quokka think validate <id>   Validate a finding
+- quokka think model list      List built-in + custom taint patterns
+- quokka think model lint      Check .quokka/taint.yaml
 
 ### Agent Management
 - quokka agent list            List available agents
@@ -110,6 +127,11 @@ All commands output JSON when --json flag is provided.
 - quokka dashboard             Start web dashboard
   --port 8080                Dashboard port
 
+### MCP Server
+- quokka mcp serve             Serve quokka tools to MCP clients over stdio
+  --read-only                Only offer tools that don't modify state
+  --list-tools               Print the tool list and exit
+
 ## Finding YAML Format
 
 title: "SQL Injection in user search"
@@ -206,6 +228,7 @@ var exportContextCmd = &cobra.Command{
 
 		// Findings
 		findingStore := finding.NewStore(p)
+		defer func() { _ = findingStore.Close() }()
 		if findingList, err := findingStore.List(nil); err == nil {
 			context["findings"] = findingList.Findings
 		}
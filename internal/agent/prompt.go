@@ -264,6 +264,10 @@ func (g *PromptGenerator) buildToolDescriptions(tools []string) string {
   Usage: quokka symbols <file> [--method auto|treesitter|lsp|regex]
   Usage: quokka symbols find <name> [--method auto|treesitter|lsp|regex]
   Usage: quokka symbols refs <symbol>
+  Usage: quokka navigate callers <symbol> [--depth N] [--file <path>]
+  Usage: quokka navigate callees <symbol> [--depth N] [--external]
+  Usage: quokka navigate definition <file>:<line>:<col>
+  Usage: quokka navigate refs <file>:<line>:<col>
   Backed by tree-sitter (no index required, in-process, fast). Supported
   languages: Go, JavaScript/TypeScript, Python, Java, Ruby, Rust, C/C++.
   Other languages fall back to regex.
@@ -273,7 +277,13 @@ func (g *PromptGenerator) buildToolDescriptions(tools []string) string {
 
   Note: 'symbols find <name>' in --method auto runs tree-sitter first for
   AST-grade matches on supported languages and falls back to regex
-  otherwise. Pass --method treesitter to force AST-only behavior.`,
+  otherwise. Pass --method treesitter to force AST-only behavior.
+
+  'symbols refs' is a text search for the name. To answer "who calls this
+  handler" or "what does this function reach", use 'navigate callers' /
+  'navigate callees', which resolve real call sites across files. For the
+  exact definition or every reference of the identifier at a position, use
+  'navigate definition' / 'navigate refs' (language server when installed).`,
 
 		"memory": `**memory** - Manage analysis memories
   Usage: quokka memory list [--type context|pattern|stack]
@@ -100,11 +100,17 @@ quokka finding update FIND-001 \
 }
 
 // RuleAddExample returns an invocation of `quokka rule add` reading an
-// opengrep YAML rule from stdin.
+// opengrep YAML rule from stdin, with the annotated fixture its tests need.
 func RuleAddExample(agentName string) string {
 	name := agentNameOrPlaceholder(agentName)
 	return strings.TrimSpace(`
-cat <<'EOF' | quokka rule add no-shell-true \
+mkdir -p /tmp/no-shell-true && cat > /tmp/no-shell-true/app.py <<'EOF'
+# ruleid: no-shell-true
+subprocess.run(cmd, shell=True)
+# ok: no-shell-true
+subprocess.run(["ls", path])
+EOF
+cat <<'EOF' | quokka rule add no-shell-true --tests /tmp/no-shell-true \
   --created-by agent:` + name + ` \
   --reasoning "subprocess.run with shell=True keeps causing CWE-78 findings; codify a pattern."
 rules:
@@ -49,7 +49,7 @@ func TestToolExamplesContainRequiredFlags(t *testing.T) {
 		{
 			"RuleAddExample",
 			RuleAddExample("test-agent"),
-			[]string{"quokka rule add", "--created-by agent:test-agent", "--reasoning", "rules:", "pattern:"},
+			[]string{"quokka rule add", "--tests", "# ruleid:", "--created-by agent:test-agent", "--reasoning", "rules:", "pattern:"},
 		},
 		{
 			"ExceptionAddFingerprintExample",
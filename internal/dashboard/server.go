@@ -4,6 +4,7 @@ import (
 	"context"
 	"embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
 	"io/fs"
@@ -15,10 +16,13 @@ import (
 
 	"github.com/diffsec/quokka/internal/agent"
 	"github.com/diffsec/quokka/internal/embedding"
+	"github.com/diffsec/quokka/internal/exception"
 	"github.com/diffsec/quokka/internal/finding"
 	"github.com/diffsec/quokka/internal/finding/export"
 	"github.com/diffsec/quokka/internal/memory"
 	"github.com/diffsec/quokka/internal/project"
+	"github.com/diffsec/quokka/internal/rule"
+	"github.com/diffsec/quokka/internal/sast"
 	"github.com/diffsec/quokka/internal/semantic"
 )
 
@@ -79,6 +83,9 @@ func NewServer(p *project.Project, port int) *Server {
 		writeTimeout:      DefaultWriteTimeout,
 		idleTimeout:       DefaultIdleTimeout,
 	}
+	s.findingStore.OnStatusChange(sast.TriageRecorder(rule.NewStore(p), func(err error) {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record rule activity: %v\n", err)
+	}))
 
 	// Parse templates with custom functions
 	funcs := template.FuncMap{
@@ -162,6 +169,16 @@ func (s *Server) Start() error {
 	return srv.ListenAndServe()
 }
 
+// Close releases the finding and memory stores
+func (s *Server) Close() error {
+	ferr := s.findingStore.Close()
+	merr := s.memoryStore.Close()
+	if ferr != nil {
+		return ferr
+	}
+	return merr
+}
+
 // SetTimeouts overrides the default HTTP server timeouts. Zero values keep
 // the existing setting. Must be called before Start().
 func (s *Server) SetTimeouts(readHeader, read, write, idle time.Duration) {
@@ -450,7 +467,7 @@ func (s *Server) handleFindings(w http.ResponseWriter, r *http.Request) {
 			s.writeError(w, err, http.StatusBadRequest)
 			return
 		}
-		if err := s.findingStore.Create(&f); err != nil {
+		if _, err := s.findingStore.Create(&f); err != nil {
 			s.writeError(w, err, http.StatusInternalServerError)
 			return
 		}
@@ -465,6 +482,10 @@ func (s *Server) handleFindings(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// dashboardActor attributes changes made through the dashboard API in the
+// finding history. The dashboard has no login, so the actor is generic.
+var dashboardActor = finding.Actor{Name: "dashboard", Source: "dashboard"}
+
 func (s *Server) handleFinding(w http.ResponseWriter, r *http.Request) {
 	id := strings.TrimPrefix(r.URL.Path, "/api/findings/")
 	if id == "" {
@@ -488,8 +509,12 @@ func (s *Server) handleFinding(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		f.ID = id
-		if err := s.findingStore.Update(&f); err != nil {
-			s.writeError(w, err, http.StatusInternalServerError)
+		if err := s.findingStore.UpdateBy(&f, dashboardActor); err != nil {
+			code := http.StatusInternalServerError
+			if errors.Is(err, finding.ErrConflict) {
+				code = http.StatusConflict
+			}
+			s.writeError(w, err, code)
 			return
 		}
 		s.writeJSON(w, f)
@@ -505,17 +530,17 @@ func (s *Server) handleFinding(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		f, err := s.findingStore.Read(id)
-		if err != nil {
+		f, err := s.findingStore.Modify(id, dashboardActor, func(f *finding.Finding) error {
+			if status, ok := update["status"].(string); ok {
+				f.Status = finding.Status(status)
+			}
+			return nil
+		})
+		if errors.Is(err, finding.ErrNotFound) {
 			s.writeError(w, err, http.StatusNotFound)
 			return
 		}
-
-		if status, ok := update["status"].(string); ok {
-			f.Status = finding.Status(status)
-		}
-
-		if err := s.findingStore.Update(f); err != nil {
+		if err != nil {
 			s.writeError(w, err, http.StatusInternalServerError)
 			return
 		}
@@ -707,6 +732,11 @@ func (s *Server) handleExport(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if _, err := exception.NewStore(s.project).Annotate(result.Findings); err != nil {
+		s.writeError(w, err, http.StatusInternalServerError)
+		return
+	}
+
 	exporter, err := export.GetExporter(req.Format)
 	if err != nil {
 		s.writeError(w, err, http.StatusBadRequest)
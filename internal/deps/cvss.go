@@ -0,0 +1,86 @@
+package deps
+
+import (
+	"math"
+	"strings"
+)
+
+// cvss3BaseScore computes the CVSS v3.x base score from a vector such as
+// "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H". Many OSV sources (PYSEC,
+// RUSTSEC, Go) carry only the vector, not a score or severity label. ok is
+// false when a base metric is missing or unknown.
+func cvss3BaseScore(vector string) (score float64, ok bool) {
+	if !strings.HasPrefix(vector, "CVSS:3.") {
+		return 0, false
+	}
+	metrics := map[string]string{}
+	for _, part := range strings.Split(vector, "/")[1:] {
+		if k, v, found := strings.Cut(part, ":"); found {
+			metrics[k] = v
+		}
+	}
+
+	weights := map[string]map[string]float64{
+		"AV": {"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2},
+		"AC": {"L": 0.77, "H": 0.44},
+		"UI": {"N": 0.85, "R": 0.62},
+		"C":  {"H": 0.56, "L": 0.22, "N": 0},
+		"I":  {"H": 0.56, "L": 0.22, "N": 0},
+		"A":  {"H": 0.56, "L": 0.22, "N": 0},
+	}
+	w := map[string]float64{}
+	for metric, values := range weights {
+		v, found := values[metrics[metric]]
+		if !found {
+			return 0, false
+		}
+		w[metric] = v
+	}
+
+	scope := metrics["S"]
+	if scope != "U" && scope != "C" {
+		return 0, false
+	}
+	changed := scope == "C"
+	var pr float64
+	switch metrics["PR"] {
+	case "N":
+		pr = 0.85
+	case "L":
+		pr = 0.62
+		if changed {
+			pr = 0.68
+		}
+	case "H":
+		pr = 0.27
+		if changed {
+			pr = 0.5
+		}
+	default:
+		return 0, false
+	}
+
+	iss := 1 - (1-w["C"])*(1-w["I"])*(1-w["A"])
+	impact := 6.42 * iss
+	if changed {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	}
+	exploitability := 8.22 * w["AV"] * w["AC"] * pr * w["UI"]
+	if impact <= 0 {
+		return 0, true
+	}
+	if changed {
+		return roundUp(math.Min(1.08*(impact+exploitability), 10)), true
+	}
+	return roundUp(math.Min(impact+exploitability, 10)), true
+}
+
+// roundUp is the specification's Roundup: the smallest one-decimal number
+// not less than x, computed on integers to dodge floating-point error.
+func roundUp(x float64) float64 {
+	i := int(math.Round(x * 100000))
+	if i%10000 == 0 {
+		return float64(i) / 100000
+	}
+	return float64(i/10000+1) / 10
+}
@@ -0,0 +1,524 @@
+package deps
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Ecosystem names as used by OSV (https://ossf.github.io/osv-schema/).
+const (
+	EcosystemGo       = "Go"
+	EcosystemNPM      = "npm"
+	EcosystemPyPI     = "PyPI"
+	EcosystemCrates   = "crates.io"
+	EcosystemRubyGems = "RubyGems"
+	EcosystemMaven    = "Maven"
+)
+
+// Package is one dependency pinned by a manifest or lockfile.
+type Package struct {
+	Ecosystem string `json:"ecosystem"`
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	// File is the manifest path relative to the project root.
+	File string `json:"file"`
+	Line int    `json:"line,omitempty"`
+	// FromRange is set when the version is the lower bound of a range
+	// (package.json without a lockfile) rather than a resolved pin.
+	FromRange bool `json:"from_range,omitempty"`
+}
+
+// manifestParsers maps the manifest file names quokka understands to their
+// parsers. These are the files project.Detector keys its language
+// detection on, plus the lockfiles next to them that pin exact versions.
+var manifestParsers = map[string]func(data []byte) ([]Package, error){
+	"go.mod":            parseGoMod,
+	"package-lock.json": parsePackageLock,
+	"yarn.lock":         parseYarnLock,
+	"package.json":      parsePackageJSON,
+	"requirements.txt":  parseRequirements,
+	"poetry.lock":       parsePoetryLock,
+	"Cargo.lock":        parseCargoLock,
+	"Gemfile.lock":      parseGemfileLock,
+	"pom.xml":           parsePomXML,
+}
+
+// supersededBy lists manifests that are skipped when one of the given
+// lockfiles sits in the same directory: the lockfile has the resolved
+// versions, the manifest only ranges.
+var supersededBy = map[string][]string{
+	"package.json": {"package-lock.json", "yarn.lock"},
+}
+
+// IsManifest reports whether name is a manifest file name FindManifests
+// picks up.
+func IsManifest(name string) bool {
+	_, ok := manifestParsers[name]
+	return ok
+}
+
+// FindManifests walks the given targets (files or directories) and returns
+// the absolute paths of the dependency manifests under them, skipping
+// vendored and build directories. Manifests superseded by a lockfile in
+// the same directory are left out.
+func FindManifests(targets []string) ([]string, error) {
+	seen := map[string]bool{}
+	var out []string
+	add := func(path string) {
+		if !seen[path] {
+			seen[path] = true
+			out = append(out, path)
+		}
+	}
+
+	for _, target := range targets {
+		abs, err := filepath.Abs(target)
+		if err != nil {
+			return nil, err
+		}
+		info, err := os.Stat(abs)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			if IsManifest(info.Name()) {
+				add(abs)
+			}
+			continue
+		}
+		err = filepath.WalkDir(abs, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if d.IsDir() {
+				if path != abs && shouldIgnore(d.Name()) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if IsManifest(d.Name()) {
+				add(path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	kept := out[:0]
+	for _, path := range out {
+		if !superseded(path) {
+			kept = append(kept, path)
+		}
+	}
+	sort.Strings(kept)
+	return kept, nil
+}
+
+func superseded(path string) bool {
+	dir := filepath.Dir(path)
+	for _, lock := range supersededBy[filepath.Base(path)] {
+		if _, err := os.Stat(filepath.Join(dir, lock)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func shouldIgnore(name string) bool {
+	ignorePatterns := []string{
+		"node_modules", "vendor", ".git", ".quokka",
+		"__pycache__", "target", "dist", "build",
+	}
+	return slices.Contains(ignorePatterns, name)
+}
+
+// ParseManifest reads one manifest and returns its packages. rel is the
+// path recorded on each package, normally relative to the project root.
+// Packages whose line the parser didn't track are located by searching the
+// file for their name.
+func ParseManifest(path, rel string) ([]Package, error) {
+	parse, ok := manifestParsers[filepath.Base(path)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported manifest %s", filepath.Base(path))
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pkgs, err := parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", rel, err)
+	}
+
+	lines := strings.Split(string(data), "\n")
+	seen := map[string]bool{}
+	out := pkgs[:0]
+	for _, p := range pkgs {
+		key := p.Ecosystem + "|" + p.Name + "|" + p.Version
+		if p.Name == "" || p.Version == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		p.File = rel
+		if p.Line == 0 {
+			p.Line = lineOf(lines, p.Name)
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+// lineOf returns the 1-based line of the first quoted or bare occurrence
+// of name, or 1 when it isn't found.
+func lineOf(lines []string, name string) int {
+	short := name
+	if i := strings.LastIndex(name, ":"); i >= 0 {
+		short = name[i+1:] // Maven group:artifact — the artifact tag is on its own line
+	}
+	for _, needle := range []string{`"` + name + `"`, `/` + name + `"`, short} {
+		for i, l := range lines {
+			if strings.Contains(l, needle) {
+				return i + 1
+			}
+		}
+	}
+	return 1
+}
+
+// --- Go ---
+
+func parseGoMod(data []byte) ([]Package, error) {
+	var pkgs []Package
+	inBlock := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(line, "require ("):
+			inBlock = true
+			continue
+		case inBlock && line == ")":
+			inBlock = false
+			continue
+		case strings.HasPrefix(line, "require "):
+			line = strings.TrimSpace(strings.TrimPrefix(line, "require"))
+		case !inBlock:
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		pkgs = append(pkgs, Package{Ecosystem: EcosystemGo, Name: fields[0], Version: fields[1], Line: n})
+	}
+	return pkgs, scanner.Err()
+}
+
+// --- npm ---
+
+func parsePackageLock(data []byte) ([]Package, error) {
+	var lock struct {
+		Packages     map[string]struct{ Name, Version string } `json:"packages"`
+		Dependencies map[string]npmLockDep                     `json:"dependencies"`
+	}
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, err
+	}
+
+	var pkgs []Package
+	// lockfileVersion 2 and 3: flat map keyed by install path.
+	for path, p := range lock.Packages {
+		if path == "" || p.Version == "" {
+			continue // the root project itself
+		}
+		name := p.Name
+		if name == "" {
+			i := strings.LastIndex(path, "node_modules/")
+			if i < 0 {
+				continue // workspace link
+			}
+			name = path[i+len("node_modules/"):]
+		}
+		pkgs = append(pkgs, Package{Ecosystem: EcosystemNPM, Name: name, Version: p.Version})
+	}
+	// lockfileVersion 1: nested dependency tree.
+	if len(lock.Packages) == 0 {
+		var walk func(deps map[string]npmLockDep)
+		walk = func(deps map[string]npmLockDep) {
+			for name, d := range deps {
+				if d.Version != "" {
+					pkgs = append(pkgs, Package{Ecosystem: EcosystemNPM, Name: name, Version: d.Version})
+				}
+				walk(d.Dependencies)
+			}
+		}
+		walk(lock.Dependencies)
+	}
+	sortPackages(pkgs)
+	return pkgs, nil
+}
+
+type npmLockDep struct {
+	Version      string                `json:"version"`
+	Dependencies map[string]npmLockDep `json:"dependencies"`
+}
+
+func parseYarnLock(data []byte) ([]Package, error) {
+	var pkgs []Package
+	var names []string
+	header := 0
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		raw := scanner.Text()
+		line := strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !strings.HasPrefix(raw, " ") && strings.HasSuffix(line, ":") {
+			// `"lodash@^4.17.15", lodash@^4.17.20:` (v1) or
+			// `"lodash@npm:^4.17.21":` (berry)
+			names = names[:0]
+			for _, spec := range strings.Split(strings.TrimSuffix(line, ":"), ",") {
+				spec = strings.Trim(strings.TrimSpace(spec), `"`)
+				if at := strings.LastIndex(spec, "@"); at > 0 {
+					names = append(names, spec[:at])
+				}
+			}
+			header = n
+			continue
+		}
+		if len(names) == 0 || !strings.HasPrefix(line, "version") {
+			continue
+		}
+		version := strings.TrimSpace(strings.TrimPrefix(line, "version"))
+		version = strings.Trim(strings.TrimPrefix(version, ":"), ` "`)
+		name := strings.TrimSuffix(names[0], "@npm")
+		if name == "__metadata" {
+			continue
+		}
+		pkgs = append(pkgs, Package{Ecosystem: EcosystemNPM, Name: name, Version: version, Line: header})
+		names = names[:0]
+	}
+	return pkgs, scanner.Err()
+}
+
+// npmRangeFloor matches the simple ranges whose lower bound is a usable
+// version: exact, ^x.y.z, ~x.y.z, >=x.y.z.
+var npmRangeFloor = regexp.MustCompile(`^(?:=|\^|~|>=)?\s*v?(\d+\.\d+\.\d+[0-9A-Za-z.+-]*)$`)
+
+func parsePackageJSON(data []byte) ([]Package, error) {
+	var manifest struct {
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, err
+	}
+	var pkgs []Package
+	for _, deps := range []map[string]string{manifest.Dependencies, manifest.DevDependencies} {
+		for name, spec := range deps {
+			spec = strings.TrimSpace(spec)
+			m := npmRangeFloor.FindStringSubmatch(spec)
+			if m == nil {
+				continue // tags, URLs, unions — nothing to match on
+			}
+			exact := !strings.ContainsAny(spec[:1], "^~>")
+			pkgs = append(pkgs, Package{Ecosystem: EcosystemNPM, Name: name, Version: m[1], FromRange: !exact})
+		}
+	}
+	sortPackages(pkgs)
+	return pkgs, nil
+}
+
+// --- Python ---
+
+var requirementPin = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)(?:\[[^\]]*\])?\s*===?\s*([^\s;#,]+)`)
+
+func parseRequirements(data []byte) ([]Package, error) {
+	var pkgs []Package
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		// Only exact pins identify a version; ranges and includes (-r) are skipped.
+		if m := requirementPin.FindStringSubmatch(line); m != nil {
+			pkgs = append(pkgs, Package{Ecosystem: EcosystemPyPI, Name: m[1], Version: m[2], Line: n})
+		}
+	}
+	return pkgs, scanner.Err()
+}
+
+func parsePoetryLock(data []byte) ([]Package, error) {
+	return parseTOMLPackages(data, EcosystemPyPI)
+}
+
+// --- Rust ---
+
+func parseCargoLock(data []byte) ([]Package, error) {
+	return parseTOMLPackages(data, EcosystemCrates)
+}
+
+// parseTOMLPackages reads the [[package]] tables shared by poetry.lock and
+// Cargo.lock. Only top-level name/version keys matter, so a line scanner
+// is enough and avoids a TOML dependency.
+func parseTOMLPackages(data []byte, ecosystem string) ([]Package, error) {
+	var pkgs []Package
+	var cur *Package
+	flush := func() {
+		if cur != nil && cur.Name != "" && cur.Version != "" {
+			pkgs = append(pkgs, *cur)
+		}
+		cur = nil
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			flush()
+			if line == "[[package]]" {
+				cur = &Package{Ecosystem: ecosystem, Line: n}
+			}
+			continue
+		}
+		if cur == nil {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"`)
+		switch strings.TrimSpace(key) {
+		case "name":
+			cur.Name = value
+		case "version":
+			cur.Version = value
+		}
+	}
+	flush()
+	return pkgs, scanner.Err()
+}
+
+// --- Ruby ---
+
+var gemSpec = regexp.MustCompile(`^    ([A-Za-z0-9._-]+) \(([^)\s]+)\)$`)
+
+func parseGemfileLock(data []byte) ([]Package, error) {
+	var pkgs []Package
+	inSpecs := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "specs:" {
+			inSpecs = true
+			continue
+		}
+		if line == "" || !strings.HasPrefix(line, " ") {
+			inSpecs = false
+			continue
+		}
+		// Resolved gems sit at four spaces; their own requirements at six.
+		if m := gemSpec.FindStringSubmatch(line); inSpecs && m != nil {
+			version := m[2]
+			// Platform-specific gems: "nokogiri (1.15.4-x86_64-linux)".
+			if i := strings.Index(version, "-"); i > 0 {
+				version = version[:i]
+			}
+			pkgs = append(pkgs, Package{Ecosystem: EcosystemRubyGems, Name: m[1], Version: version, Line: n})
+		}
+	}
+	return pkgs, scanner.Err()
+}
+
+// --- Java ---
+
+type pomProject struct {
+	Version    string          `xml:"version"`
+	Parent     pomDependency   `xml:"parent"`
+	Properties pomProperties   `xml:"properties"`
+	Deps       []pomDependency `xml:"dependencies>dependency"`
+	Managed    []pomDependency `xml:"dependencyManagement>dependencies>dependency"`
+}
+
+type pomDependency struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+}
+
+type pomProperties struct {
+	Entries []struct {
+		XMLName xml.Name
+		Value   string `xml:",chardata"`
+	} `xml:",any"`
+}
+
+var pomProperty = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+func parsePomXML(data []byte) ([]Package, error) {
+	var pom pomProject
+	if err := xml.Unmarshal(data, &pom); err != nil {
+		return nil, err
+	}
+	props := map[string]string{
+		"project.version": pom.Version,
+		"version":         pom.Version,
+	}
+	if pom.Version == "" {
+		props["project.version"] = pom.Parent.Version
+	}
+	for _, e := range pom.Properties.Entries {
+		props[e.XMLName.Local] = strings.TrimSpace(e.Value)
+	}
+
+	// Managed versions fill in dependencies that omit <version>.
+	managed := map[string]string{}
+	for _, d := range pom.Managed {
+		managed[d.GroupID+":"+d.ArtifactID] = d.Version
+	}
+
+	var pkgs []Package
+	for _, d := range append(pom.Deps, pom.Managed...) {
+		name := strings.TrimSpace(d.GroupID) + ":" + strings.TrimSpace(d.ArtifactID)
+		version := strings.TrimSpace(d.Version)
+		if version == "" {
+			version = managed[name]
+		}
+		version = pomProperty.ReplaceAllStringFunc(version, func(ref string) string {
+			if v, ok := props[ref[2:len(ref)-1]]; ok {
+				return v
+			}
+			return ref
+		})
+		if version == "" || strings.Contains(version, "${") || strings.ContainsAny(version, "[(,") {
+			continue // unresolved property or version range
+		}
+		pkgs = append(pkgs, Package{Ecosystem: EcosystemMaven, Name: name, Version: version})
+	}
+	return pkgs, nil
+}
+
+func sortPackages(pkgs []Package) {
+	sort.Slice(pkgs, func(i, j int) bool {
+		if pkgs[i].Name != pkgs[j].Name {
+			return pkgs[i].Name < pkgs[j].Name
+		}
+		return pkgs[i].Version < pkgs[j].Version
+	})
+}
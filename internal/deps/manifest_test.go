@@ -0,0 +1,239 @@
+package deps
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// pkgVersions indexes parsed packages as name -> version.
+func pkgVersions(pkgs []Package) map[string]string {
+	out := map[string]string{}
+	for _, p := range pkgs {
+		out[p.Name] = p.Version
+	}
+	return out
+}
+
+func TestParseManifests(t *testing.T) {
+	cases := []struct {
+		name    string
+		file    string
+		content string
+		want    map[string]string
+		line    map[string]int
+	}{
+		{
+			name: "go.mod",
+			file: "go.mod",
+			content: `module example.com/app
+
+go 1.22
+
+require github.com/gin-gonic/gin v1.9.0
+
+require (
+	golang.org/x/net v0.17.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+replace (
+	example.com/old => example.com/new v1.0.0
+)
+`,
+			want: map[string]string{"github.com/gin-gonic/gin": "v1.9.0", "golang.org/x/net": "v0.17.0", "gopkg.in/yaml.v3": "v3.0.1"},
+			line: map[string]int{"golang.org/x/net": 8},
+		},
+		{
+			name: "package-lock v3",
+			file: "package-lock.json",
+			content: `{
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"name": "app", "version": "1.0.0"},
+    "node_modules/lodash": {"version": "4.17.20"},
+    "node_modules/@babel/core": {"version": "7.22.0"},
+    "node_modules/a/node_modules/minimist": {"version": "1.2.5"}
+  }
+}`,
+			want: map[string]string{"lodash": "4.17.20", "@babel/core": "7.22.0", "minimist": "1.2.5"},
+			line: map[string]int{"lodash": 5},
+		},
+		{
+			name: "package-lock v1",
+			file: "package-lock.json",
+			content: `{"lockfileVersion": 1, "dependencies": {
+  "express": {"version": "4.17.1", "dependencies": {"qs": {"version": "6.7.0"}}}
+}}`,
+			want: map[string]string{"express": "4.17.1", "qs": "6.7.0"},
+		},
+		{
+			name: "yarn.lock",
+			file: "yarn.lock",
+			content: `# yarn lockfile v1
+
+"@types/node@^18.0.0":
+  version "18.11.9"
+
+lodash@^4.17.15, "lodash@^4.17.20":
+  version "4.17.20"
+  resolved "https://registry.yarnpkg.com/lodash/-/lodash-4.17.20.tgz"
+`,
+			want: map[string]string{"@types/node": "18.11.9", "lodash": "4.17.20"},
+			line: map[string]int{"lodash": 6},
+		},
+		{
+			name:    "package.json ranges",
+			file:    "package.json",
+			content: `{"dependencies": {"lodash": "^4.17.20", "left-pad": "1.3.0", "react": "latest", "x": ">=1 <2"}}`,
+			want:    map[string]string{"lodash": "4.17.20", "left-pad": "1.3.0"},
+		},
+		{
+			name: "requirements.txt",
+			file: "requirements.txt",
+			content: `# pinned
+Django==3.2.0
+requests[security] == 2.25.1 ; python_version >= "3"
+flask>=2.0
+-r other.txt
+`,
+			want: map[string]string{"Django": "3.2.0", "requests": "2.25.1"},
+			line: map[string]int{"requests": 3},
+		},
+		{
+			name: "poetry.lock",
+			file: "poetry.lock",
+			content: `[[package]]
+name = "jinja2"
+version = "2.11.2"
+
+[package.dependencies]
+markupsafe = ">=0.23"
+
+[[package]]
+name = "markupsafe"
+version = "1.1.1"
+`,
+			want: map[string]string{"jinja2": "2.11.2", "markupsafe": "1.1.1"},
+			line: map[string]int{"markupsafe": 8},
+		},
+		{
+			name: "Cargo.lock",
+			file: "Cargo.lock",
+			content: `version = 3
+
+[[package]]
+name = "smallvec"
+version = "1.6.0"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+`,
+			want: map[string]string{"smallvec": "1.6.0"},
+		},
+		{
+			name: "Gemfile.lock",
+			file: "Gemfile.lock",
+			content: `GEM
+  remote: https://rubygems.org/
+  specs:
+    nokogiri (1.13.3-x86_64-linux)
+      racc (~> 1.4)
+    rack (2.2.3)
+
+PLATFORMS
+  x86_64-linux
+`,
+			want: map[string]string{"nokogiri": "1.13.3", "rack": "2.2.3"},
+			line: map[string]int{"rack": 6},
+		},
+		{
+			name: "pom.xml",
+			file: "pom.xml",
+			content: `<project>
+  <version>1.0.0</version>
+  <properties><log4j.version>2.14.1</log4j.version></properties>
+  <dependencyManagement><dependencies>
+    <dependency><groupId>com.fasterxml.jackson.core</groupId><artifactId>jackson-databind</artifactId><version>2.9.8</version></dependency>
+  </dependencies></dependencyManagement>
+  <dependencies>
+    <dependency>
+      <groupId>org.apache.logging.log4j</groupId>
+      <artifactId>log4j-core</artifactId>
+      <version>${log4j.version}</version>
+    </dependency>
+    <dependency><groupId>com.fasterxml.jackson.core</groupId><artifactId>jackson-databind</artifactId></dependency>
+    <dependency><groupId>junit</groupId><artifactId>junit</artifactId><version>[4.0,5.0)</version></dependency>
+  </dependencies>
+</project>`,
+			want: map[string]string{"org.apache.logging.log4j:log4j-core": "2.14.1", "com.fasterxml.jackson.core:jackson-databind": "2.9.8"},
+			line: map[string]int{"org.apache.logging.log4j:log4j-core": 10},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tc.file)
+			writeFile(t, path, tc.content)
+			pkgs, err := ParseManifest(path, tc.file)
+			if err != nil {
+				t.Fatalf("ParseManifest: %v", err)
+			}
+			got := pkgVersions(pkgs)
+			if len(got) != len(tc.want) {
+				t.Errorf("packages = %v, want %v", got, tc.want)
+			}
+			for name, version := range tc.want {
+				if got[name] != version {
+					t.Errorf("%s = %q, want %q", name, got[name], version)
+				}
+			}
+			for _, p := range pkgs {
+				if p.File != tc.file {
+					t.Errorf("%s file = %q", p.Name, p.File)
+				}
+				if want, ok := tc.line[p.Name]; ok && p.Line != want {
+					t.Errorf("%s line = %d, want %d", p.Name, p.Line, want)
+				}
+			}
+		})
+	}
+}
+
+func TestFindManifests(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "go.mod"), "module x\n")
+	writeFile(t, filepath.Join(root, "web", "package.json"), `{}`)
+	writeFile(t, filepath.Join(root, "web", "package-lock.json"), `{}`)
+	writeFile(t, filepath.Join(root, "tools", "package.json"), `{}`)
+	writeFile(t, filepath.Join(root, "web", "node_modules", "x", "package.json"), `{}`)
+	writeFile(t, filepath.Join(root, "vendor", "y", "go.mod"), "module y\n")
+	writeFile(t, filepath.Join(root, "README.md"), "")
+
+	got, err := FindManifests([]string{root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rel []string
+	for _, p := range got {
+		r, _ := filepath.Rel(root, p)
+		rel = append(rel, r)
+	}
+	want := []string{"go.mod", "tools/package.json", "web/package-lock.json"}
+	if len(rel) != len(want) {
+		t.Fatalf("manifests = %v, want %v", rel, want)
+	}
+	for i := range want {
+		if rel[i] != want[i] {
+			t.Errorf("manifests[%d] = %q, want %q", i, rel[i], want[i])
+		}
+	}
+}
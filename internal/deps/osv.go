@@ -0,0 +1,343 @@
+package deps
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Vulnerability is the subset of an OSV record (https://ossf.github.io/osv-schema/)
+// the scanner reads.
+type Vulnerability struct {
+	ID               string          `json:"id"`
+	Summary          string          `json:"summary"`
+	Details          string          `json:"details"`
+	Aliases          []string        `json:"aliases"`
+	Withdrawn        *time.Time      `json:"withdrawn"`
+	Affected         []Affected      `json:"affected"`
+	Severity         []OSVSeverity   `json:"severity"`
+	References       []OSVReference  `json:"references"`
+	DatabaseSpecific json.RawMessage `json:"database_specific"`
+}
+
+// Affected lists the affected versions of one package.
+type Affected struct {
+	Package struct {
+		Ecosystem string `json:"ecosystem"`
+		Name      string `json:"name"`
+	} `json:"package"`
+	Ranges   []AffectedRange `json:"ranges"`
+	Versions []string        `json:"versions"`
+}
+
+// AffectedRange is a version range expressed as introduced/fixed events.
+type AffectedRange struct {
+	Type   string       `json:"type"`
+	Events []RangeEvent `json:"events"`
+}
+
+// RangeEvent is one event of an AffectedRange; exactly one field is set.
+type RangeEvent struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
+	Limit        string `json:"limit,omitempty"`
+}
+
+// OSVSeverity is a scored severity, e.g. {"type": "CVSS_V3", "score": "CVSS:3.1/..."}.
+type OSVSeverity struct {
+	Type  string `json:"type"`
+	Score string `json:"score"`
+}
+
+// OSVReference is a link attached to a record.
+type OSVReference struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+// databaseSpecific holds the fields GitHub advisories put in
+// database_specific, the most common source of CWE and severity labels.
+type databaseSpecific struct {
+	Severity string   `json:"severity"`
+	CWEIDs   []string `json:"cwe_ids"`
+}
+
+// Database is an in-memory index of an offline OSV dump, keyed by
+// ecosystem and package name.
+type Database struct {
+	byPackage map[string][]*Vulnerability
+	count     int
+}
+
+// Len returns the number of vulnerabilities loaded.
+func (db *Database) Len() int { return db.count }
+
+// LoadDatabase loads an offline OSV dump. path may be:
+//
+//   - a directory, walked for *.json records and *.zip archives — the
+//     layout of the per-ecosystem exports at
+//     https://osv-vulnerabilities.storage.googleapis.com/<ecosystem>/all.zip
+//     once downloaded side by side;
+//   - a single all.zip archive;
+//   - a single JSON file holding one record or an array of records.
+//
+// Withdrawn records are dropped.
+func LoadDatabase(path string) (*Database, error) {
+	db := &Database{byPackage: map[string][]*Vulnerability{}}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("osv database: %w", err)
+	}
+	if !info.IsDir() {
+		if err := db.loadFile(path); err != nil {
+			return nil, err
+		}
+		return db, nil
+	}
+	err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		switch strings.ToLower(filepath.Ext(p)) {
+		case ".json", ".zip":
+			return db.loadFile(p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+func (db *Database) loadFile(path string) error {
+	if strings.EqualFold(filepath.Ext(path), ".zip") {
+		return db.loadZip(path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("osv database: %w", err)
+	}
+	if err := db.addJSON(data); err != nil {
+		return fmt.Errorf("osv database: %s: %w", path, err)
+	}
+	return nil
+}
+
+func (db *Database) loadZip(path string) error {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return fmt.Errorf("osv database: %w", err)
+	}
+	defer func() { _ = zr.Close() }()
+	for _, f := range zr.File {
+		if !strings.EqualFold(filepath.Ext(f.Name), ".json") {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("osv database: %s: %w", f.Name, err)
+		}
+		data, err := io.ReadAll(rc)
+		_ = rc.Close()
+		if err != nil {
+			return fmt.Errorf("osv database: %s: %w", f.Name, err)
+		}
+		if err := db.addJSON(data); err != nil {
+			return fmt.Errorf("osv database: %s!%s: %w", path, f.Name, err)
+		}
+	}
+	return nil
+}
+
+func (db *Database) addJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		var vulns []Vulnerability
+		if err := json.Unmarshal(data, &vulns); err != nil {
+			return err
+		}
+		for i := range vulns {
+			db.Add(&vulns[i])
+		}
+		return nil
+	}
+	var v Vulnerability
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	db.Add(&v)
+	return nil
+}
+
+// Add indexes one vulnerability record.
+func (db *Database) Add(v *Vulnerability) {
+	if v.ID == "" || v.Withdrawn != nil {
+		return
+	}
+	seen := map[string]bool{}
+	for _, a := range v.Affected {
+		key := packageKey(a.Package.Ecosystem, a.Package.Name)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		db.byPackage[key] = append(db.byPackage[key], v)
+	}
+	db.count++
+}
+
+// Match is a vulnerability affecting a package version.
+type Match struct {
+	Package       Package
+	Vulnerability *Vulnerability
+	// FixedVersion is the earliest fixed version above Package.Version,
+	// empty when no fix is published.
+	FixedVersion string
+}
+
+// Lookup returns the vulnerabilities affecting pkg, ordered by ID.
+func (db *Database) Lookup(pkg Package) []Match {
+	var out []Match
+	for _, v := range db.byPackage[packageKey(pkg.Ecosystem, pkg.Name)] {
+		for _, a := range v.Affected {
+			if packageKey(a.Package.Ecosystem, a.Package.Name) != packageKey(pkg.Ecosystem, pkg.Name) {
+				continue
+			}
+			if fixed, hit := affects(a, pkg.Ecosystem, pkg.Version); hit {
+				out = append(out, Match{Package: pkg, Vulnerability: v, FixedVersion: fixed})
+				break
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Vulnerability.ID < out[j].Vulnerability.ID })
+	return out
+}
+
+// affects reports whether version falls in a's explicit version list or
+// one of its SEMVER/ECOSYSTEM ranges. GIT ranges name commits, which a
+// manifest version can't be compared against, and are ignored.
+func affects(a Affected, ecosystem, version string) (fixed string, hit bool) {
+	for _, v := range a.Versions {
+		if normalizeVersion(v) == normalizeVersion(version) {
+			hit = true
+		}
+	}
+	for _, r := range a.Ranges {
+		if r.Type != "SEMVER" && r.Type != "ECOSYSTEM" {
+			continue
+		}
+		if inRange(r.Events, ecosystem, version) {
+			hit = true
+			fixed = fixedAbove(r.Events, ecosystem, version)
+			break
+		}
+	}
+	if hit && fixed == "" {
+		for _, r := range a.Ranges {
+			if f := fixedAbove(r.Events, ecosystem, version); f != "" {
+				fixed = f
+				break
+			}
+		}
+	}
+	return fixed, hit
+}
+
+// inRange evaluates OSV range events in version order: the last event at
+// or below version decides whether it is affected.
+func inRange(events []RangeEvent, ecosystem, version string) bool {
+	sorted := append([]RangeEvent(nil), events...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return compareEvent(ecosystem, sorted[i], sorted[j]) < 0
+	})
+	affected := false
+	for _, e := range sorted {
+		switch {
+		case e.Introduced != "":
+			if e.Introduced == "0" || compareVersions(ecosystem, version, e.Introduced) >= 0 {
+				affected = true
+			}
+		case e.Fixed != "":
+			if compareVersions(ecosystem, version, e.Fixed) >= 0 {
+				affected = false
+			}
+		case e.LastAffected != "":
+			if compareVersions(ecosystem, version, e.LastAffected) > 0 {
+				affected = false
+			}
+		}
+	}
+	return affected
+}
+
+func compareEvent(ecosystem string, a, b RangeEvent) int {
+	va, vb := eventVersion(a), eventVersion(b)
+	switch {
+	case va == vb:
+		return 0
+	case va == "0":
+		return -1
+	case vb == "0":
+		return 1
+	}
+	return compareVersions(ecosystem, va, vb)
+}
+
+func eventVersion(e RangeEvent) string {
+	switch {
+	case e.Introduced != "":
+		return e.Introduced
+	case e.Fixed != "":
+		return e.Fixed
+	case e.LastAffected != "":
+		return e.LastAffected
+	}
+	return e.Limit
+}
+
+func fixedAbove(events []RangeEvent, ecosystem, version string) string {
+	best := ""
+	for _, e := range events {
+		if e.Fixed == "" || compareVersions(ecosystem, e.Fixed, version) <= 0 {
+			continue
+		}
+		if best == "" || compareVersions(ecosystem, e.Fixed, best) < 0 {
+			best = e.Fixed
+		}
+	}
+	return best
+}
+
+var pypiSeparators = regexp.MustCompile(`[-_.]+`)
+
+// packageKey normalises ecosystem and name for lookup. OSV ecosystems may
+// carry a release suffix ("Debian:12"); PyPI names are case- and
+// separator-insensitive (PEP 503).
+func packageKey(ecosystem, name string) string {
+	eco, _, _ := strings.Cut(ecosystem, ":")
+	if eco == EcosystemPyPI {
+		name = pypiSeparators.ReplaceAllString(strings.ToLower(name), "-")
+	}
+	return eco + "|" + name
+}
+
+// advisory decodes the GitHub-advisory style database_specific
+// block; other shapes decode to the zero value.
+func (v *Vulnerability) advisory() databaseSpecific {
+	var ds databaseSpecific
+	if len(v.DatabaseSpecific) > 0 {
+		_ = json.Unmarshal(v.DatabaseSpecific, &ds)
+	}
+	return ds
+}
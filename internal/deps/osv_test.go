@@ -0,0 +1,174 @@
+package deps
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const lodashAdvisory = `{
+  "id": "GHSA-35jh-r3h4-6jhm",
+  "summary": "Command Injection in lodash",
+  "aliases": ["CVE-2021-23337"],
+  "affected": [{
+    "package": {"ecosystem": "npm", "name": "lodash"},
+    "ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "4.17.21"}]}]
+  }],
+  "severity": [{"type": "CVSS_V3", "score": "CVSS:3.1/AV:N/AC:L/PR:H/UI:N/S:U/C:H/I:H/A:H"}],
+  "references": [{"type": "ADVISORY", "url": "https://nvd.nist.gov/vuln/detail/CVE-2021-23337"}],
+  "database_specific": {"severity": "HIGH", "cwe_ids": ["CWE-77", "CWE-94"]}
+}`
+
+const jinjaRecords = `[
+  {
+    "id": "PYSEC-2021-66",
+    "details": "ReDoS in the urlize filter.",
+    "affected": [{
+      "package": {"ecosystem": "PyPI", "name": "Jinja2"},
+      "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}, {"fixed": "2.11.3"}]}],
+      "versions": ["2.11.2"]
+    }],
+    "severity": [{"type": "CVSS_V3", "score": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:L"}]
+  },
+  {
+    "id": "PYSEC-WITHDRAWN",
+    "withdrawn": "2022-01-01T00:00:00Z",
+    "affected": [{"package": {"ecosystem": "PyPI", "name": "jinja2"}, "versions": ["2.11.2"]}]
+  }
+]`
+
+func TestLoadDatabaseLayouts(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "npm", "GHSA-35jh-r3h4-6jhm.json"), lodashAdvisory)
+	writeFile(t, filepath.Join(dir, "pypi.json"), jinjaRecords)
+
+	// A per-ecosystem all.zip alongside the loose files.
+	if err := os.MkdirAll(filepath.Join(dir, "crates"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	zf, err := os.Create(filepath.Join(dir, "crates", "all.zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(zf)
+	w, _ := zw.Create("RUSTSEC-2021-0003.json")
+	_, _ = w.Write([]byte(`{"id": "RUSTSEC-2021-0003", "affected": [{"package": {"ecosystem": "crates.io", "name": "smallvec"},
+		"ranges": [{"type": "SEMVER", "events": [{"introduced": "1.6.0"}, {"fixed": "1.6.1"}]}]}]}`))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	_ = zf.Close()
+
+	db, err := LoadDatabase(dir)
+	if err != nil {
+		t.Fatalf("LoadDatabase: %v", err)
+	}
+	if db.Len() != 3 {
+		t.Errorf("Len = %d, want 3 (withdrawn record dropped)", db.Len())
+	}
+
+	// PyPI names match case- and separator-insensitively.
+	if m := db.Lookup(Package{Ecosystem: EcosystemPyPI, Name: "jinja2", Version: "2.11.2"}); len(m) != 1 || m[0].FixedVersion != "2.11.3" {
+		t.Errorf("jinja2 lookup = %+v", m)
+	}
+	if m := db.Lookup(Package{Ecosystem: EcosystemCrates, Name: "smallvec", Version: "1.6.0"}); len(m) != 1 {
+		t.Errorf("smallvec lookup = %+v", m)
+	}
+
+	single, err := LoadDatabase(filepath.Join(dir, "npm", "GHSA-35jh-r3h4-6jhm.json"))
+	if err != nil || single.Len() != 1 {
+		t.Fatalf("single-file LoadDatabase = %v, %v", single, err)
+	}
+}
+
+func TestLookupRanges(t *testing.T) {
+	db := &Database{byPackage: map[string][]*Vulnerability{}}
+	db.Add(&Vulnerability{ID: "GO-1", Affected: []Affected{goAffected("golang.org/x/net",
+		AffectedRange{Type: "SEMVER", Events: []RangeEvent{{Introduced: "0"}, {Fixed: "0.7.0"}, {Introduced: "0.8.0"}, {LastAffected: "0.9.0"}}},
+	)}})
+
+	cases := []struct {
+		version string
+		hit     bool
+		fixed   string
+	}{
+		{"v0.6.0", true, "0.7.0"},
+		{"v0.7.0", false, ""},
+		{"v0.7.5", false, ""},
+		{"v0.8.0", true, ""},
+		{"v0.9.0", true, ""},
+		{"v0.9.1", false, ""},
+		{"v0.0.0-20210226172049-e18ecbb05110", true, "0.7.0"},
+	}
+	for _, tc := range cases {
+		m := db.Lookup(Package{Ecosystem: EcosystemGo, Name: "golang.org/x/net", Version: tc.version})
+		if (len(m) == 1) != tc.hit {
+			t.Errorf("%s: hit = %v, want %v", tc.version, len(m) == 1, tc.hit)
+			continue
+		}
+		if tc.hit && m[0].FixedVersion != tc.fixed {
+			t.Errorf("%s: fixed = %q, want %q", tc.version, m[0].FixedVersion, tc.fixed)
+		}
+	}
+}
+
+func goAffected(name string, ranges ...AffectedRange) Affected {
+	var a Affected
+	a.Package.Ecosystem = EcosystemGo
+	a.Package.Name = name
+	a.Ranges = ranges
+	return a
+}
+
+func TestCompareVersions(t *testing.T) {
+	cases := []struct {
+		ecosystem, a, b string
+		want            int
+	}{
+		{EcosystemGo, "v1.2.3", "1.2.3", 0},
+		{EcosystemGo, "v1.10.0", "v1.9.9", 1},
+		{EcosystemNPM, "1.0.0-rc.1", "1.0.0", -1},
+		{EcosystemNPM, "1.0.0-alpha", "1.0.0-beta", -1},
+		{EcosystemCrates, "0.2.0+build5", "0.2.0", 0},
+		{EcosystemPyPI, "1.0", "1.0.0", 0},
+		{EcosystemPyPI, "1.0rc1", "1.0", -1},
+		{EcosystemPyPI, "1.0.dev1", "1.0a1", -1},
+		{EcosystemPyPI, "1.0.post1", "1.0", 1},
+		{EcosystemPyPI, "1.0.post1", "1.0.1", -1},
+		{EcosystemMaven, "2.14.1", "2.15.0", -1},
+		{EcosystemMaven, "5.3.0.RELEASE", "5.3.0", 0},
+		{EcosystemMaven, "1.0-SNAPSHOT", "1.0", -1},
+		{EcosystemRubyGems, "1.13.10", "1.13.3", 1},
+	}
+	for _, tc := range cases {
+		if got := compareVersions(tc.ecosystem, tc.a, tc.b); got != tc.want {
+			t.Errorf("compareVersions(%s, %q, %q) = %d, want %d", tc.ecosystem, tc.a, tc.b, got, tc.want)
+		}
+		if got := compareVersions(tc.ecosystem, tc.b, tc.a); got != -tc.want {
+			t.Errorf("compareVersions(%s, %q, %q) = %d, want %d", tc.ecosystem, tc.b, tc.a, got, -tc.want)
+		}
+	}
+}
+
+func TestCVSS3BaseScore(t *testing.T) {
+	cases := []struct {
+		vector string
+		want   float64
+		ok     bool
+	}{
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", 9.8, true},
+		{"CVSS:3.1/AV:N/AC:L/PR:H/UI:N/S:U/C:H/I:H/A:H", 7.2, true},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:R/S:C/C:L/I:L/A:N", 6.1, true},
+		{"CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H", 10.0, true},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:N", 0, true},
+		{"CVSS:3.1/AV:N/AC:L", 0, false},
+		{"AV:N/AC:L/Au:N/C:P/I:P/A:P", 0, false},
+	}
+	for _, tc := range cases {
+		got, ok := cvss3BaseScore(tc.vector)
+		if ok != tc.ok || got != tc.want {
+			t.Errorf("cvss3BaseScore(%q) = %v, %v; want %v, %v", tc.vector, got, ok, tc.want, tc.ok)
+		}
+	}
+}
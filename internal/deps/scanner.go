@@ -0,0 +1,210 @@
+// Package deps matches the dependencies pinned in a project's manifests
+// and lockfiles against an offline OSV vulnerability database and turns
+// the hits into quokka findings.
+package deps
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/diffsec/quokka/internal/finding"
+)
+
+// CreatedBy is the finding creator for dependency scan results. Together
+// with the fingerprint it lets a re-scan dedup against earlier runs.
+const CreatedBy = "osv"
+
+// OSVTagPrefix is the prefix on the finding tag naming the OSV record
+// (e.g. "osv-id:GHSA-jf85-cpcp-j695").
+const OSVTagPrefix = "osv-id:"
+
+// DefaultCWE is used when a record carries no CWE: CWE-1395, Dependency
+// on Vulnerable Third-Party Component.
+const DefaultCWE = "CWE-1395"
+
+// Scanner matches project dependencies against an OSV database. Like
+// sast.Scanner it doesn't write to the store; callers decide what to do
+// with the results.
+type Scanner struct {
+	// DB is the loaded OSV database. Required.
+	DB *Database
+
+	// ProjectRoot is used to relativize manifest paths on findings.
+	ProjectRoot string
+}
+
+// Result is the outcome of a scan.
+type Result struct {
+	Manifests []string          `json:"manifests"`
+	Packages  int               `json:"packages"`
+	Matches   []Match           `json:"-"`
+	Findings  []finding.Finding `json:"findings"`
+	// Errors holds manifests that couldn't be parsed; the scan carries on
+	// without them.
+	Errors []string `json:"errors,omitempty"`
+}
+
+// Scan finds the manifests under targets (files or directories), matches
+// every pinned package against the database and returns one finding per
+// vulnerable package and OSV record. Findings are not persisted.
+func (s *Scanner) Scan(targets []string) (*Result, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("deps: Scanner.DB is required")
+	}
+	manifests, err := FindManifests(targets)
+	if err != nil {
+		return nil, fmt.Errorf("deps: %w", err)
+	}
+
+	res := &Result{}
+	for _, path := range manifests {
+		rel := s.relativize(path)
+		res.Manifests = append(res.Manifests, rel)
+		pkgs, err := ParseManifest(path, rel)
+		if err != nil {
+			res.Errors = append(res.Errors, err.Error())
+			continue
+		}
+		res.Packages += len(pkgs)
+		for _, pkg := range pkgs {
+			for _, m := range s.DB.Lookup(pkg) {
+				res.Matches = append(res.Matches, m)
+				res.Findings = append(res.Findings, m.Finding())
+			}
+		}
+	}
+	return res, nil
+}
+
+func (s *Scanner) relativize(path string) string {
+	if s.ProjectRoot == "" {
+		return path
+	}
+	root, err := filepath.Abs(s.ProjectRoot)
+	if err != nil {
+		return path
+	}
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return rel
+}
+
+// Finding converts a match into a quokka finding located at the manifest
+// line that pins the package. The package name doubles as the location's
+// symbol, so the fingerprint stays stable when the version changes but the
+// package remains vulnerable.
+func (m Match) Finding() finding.Finding {
+	v := m.Vulnerability
+	pkg := m.Package
+	adv := v.advisory()
+
+	summary := strings.TrimSpace(v.Summary)
+	if summary == "" {
+		summary = "known vulnerability"
+	}
+
+	cwe := DefaultCWE
+	for _, id := range adv.CWEIDs {
+		if strings.HasPrefix(strings.ToUpper(id), "CWE-") {
+			cwe = strings.ToUpper(id)
+			break
+		}
+	}
+
+	var cvss *finding.CVSS
+	for _, sev := range v.Severity {
+		if sev.Type != "CVSS_V3" {
+			continue
+		}
+		if score, ok := cvss3BaseScore(sev.Score); ok {
+			cvss = &finding.CVSS{Score: score, Vector: sev.Score}
+			break
+		}
+	}
+	severity := mapSeverity(adv.Severity, cvss)
+
+	confidence := finding.ConfidenceHigh
+	if pkg.FromRange {
+		confidence = finding.ConfidenceMedium
+	}
+
+	var desc strings.Builder
+	fmt.Fprintf(&desc, "%s %s (%s) is affected by %s", pkg.Name, pkg.Version, pkg.Ecosystem, v.ID)
+	if len(v.Aliases) > 0 {
+		fmt.Fprintf(&desc, " (%s)", strings.Join(v.Aliases, ", "))
+	}
+	desc.WriteString(".")
+	if pkg.FromRange {
+		fmt.Fprintf(&desc, " The version is the lower bound of the range declared in %s; commit a lockfile for exact matching.", pkg.File)
+	}
+	if details := strings.TrimSpace(v.Details); details != "" {
+		desc.WriteString("\n\n" + details)
+	}
+
+	remediation := fmt.Sprintf("Upgrade %s to %s or later.", pkg.Name, m.FixedVersion)
+	if m.FixedVersion == "" {
+		remediation = fmt.Sprintf("No fixed version of %s is published; remove or replace the dependency, or confirm the vulnerable code path is unreachable and record an exception.", pkg.Name)
+	}
+
+	refs := []string{"https://osv.dev/vulnerability/" + v.ID}
+	for _, r := range v.References {
+		if r.URL != "" && r.URL != refs[0] {
+			refs = append(refs, r.URL)
+		}
+	}
+
+	f := finding.Finding{
+		Title:       fmt.Sprintf("%s: %s (%s)", pkg.Name, summary, v.ID),
+		Severity:    severity,
+		Confidence:  confidence,
+		Status:      finding.StatusOpen,
+		CWE:         cwe,
+		CVSS:        cvss,
+		Location:    finding.Location{File: pkg.File, LineStart: max(pkg.Line, 1), Function: pkg.Name},
+		Description: desc.String(),
+		Remediation: remediation,
+		References:  refs,
+		Tags:        []string{"sca", "osv", "dependencies:vulnerable", OSVTagPrefix + v.ID},
+		CreatedBy:   CreatedBy,
+	}
+	f.Fingerprint = finding.Fingerprint(f)
+	return f
+}
+
+// mapSeverity prefers the advisory's own label (GitHub's LOW, MODERATE,
+// HIGH, CRITICAL), then the CVSS score bands, then medium.
+func mapSeverity(label string, cvss *finding.CVSS) finding.Severity {
+	switch strings.ToUpper(strings.TrimSpace(label)) {
+	case "CRITICAL":
+		return finding.SeverityCritical
+	case "HIGH":
+		return finding.SeverityHigh
+	case "MODERATE", "MEDIUM":
+		return finding.SeverityMedium
+	case "LOW":
+		return finding.SeverityLow
+	}
+	if cvss != nil {
+		switch {
+		case cvss.Score >= 9:
+			return finding.SeverityCritical
+		case cvss.Score >= 7:
+			return finding.SeverityHigh
+		case cvss.Score >= 4:
+			return finding.SeverityMedium
+		case cvss.Score > 0:
+			return finding.SeverityLow
+		}
+		return finding.SeverityInfo
+	}
+	return finding.SeverityMedium
+}
@@ -0,0 +1,97 @@
+package deps
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/diffsec/quokka/internal/finding"
+)
+
+func TestScannerFindings(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "web", "package-lock.json"), `{
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"name": "web"},
+    "node_modules/lodash": {"version": "4.17.20"},
+    "node_modules/express": {"version": "4.18.2"}
+  }
+}`)
+	writeFile(t, filepath.Join(root, "requirements.txt"), "jinja2==2.11.2\n")
+
+	dbPath := filepath.Join(root, "osv")
+	writeFile(t, filepath.Join(dbPath, "lodash.json"), lodashAdvisory)
+	writeFile(t, filepath.Join(dbPath, "pypi.json"), jinjaRecords)
+	db, err := LoadDatabase(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &Scanner{DB: db, ProjectRoot: root}
+	res, err := s.Scan([]string{root})
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if res.Packages != 3 || len(res.Manifests) != 2 {
+		t.Errorf("packages = %d, manifests = %v", res.Packages, res.Manifests)
+	}
+	if len(res.Findings) != 2 {
+		t.Fatalf("findings = %d, want 2: %+v", len(res.Findings), res.Findings)
+	}
+
+	var lodash, jinja finding.Finding
+	for _, f := range res.Findings {
+		switch f.Location.Function {
+		case "lodash":
+			lodash = f
+		case "jinja2":
+			jinja = f
+		}
+	}
+
+	if lodash.Location.File != filepath.Join("web", "package-lock.json") || lodash.Location.LineStart != 5 {
+		t.Errorf("lodash location = %+v", lodash.Location)
+	}
+	if lodash.Severity != finding.SeverityHigh || lodash.CWE != "CWE-77" {
+		t.Errorf("lodash severity/cwe = %s/%s", lodash.Severity, lodash.CWE)
+	}
+	if lodash.CVSS == nil || lodash.CVSS.Score != 7.2 {
+		t.Errorf("lodash cvss = %+v", lodash.CVSS)
+	}
+	if !strings.Contains(lodash.Remediation, "4.17.21") {
+		t.Errorf("lodash remediation = %q", lodash.Remediation)
+	}
+	if len(lodash.References) != 2 || lodash.References[0] != "https://osv.dev/vulnerability/GHSA-35jh-r3h4-6jhm" {
+		t.Errorf("lodash references = %v", lodash.References)
+	}
+	if lodash.Fingerprint != finding.Fingerprint(lodash) || lodash.CreatedBy != CreatedBy {
+		t.Errorf("lodash fingerprint/creator = %q/%q", lodash.Fingerprint, lodash.CreatedBy)
+	}
+	wantTags := []string{"sca", "osv", "dependencies:vulnerable", "osv-id:GHSA-35jh-r3h4-6jhm"}
+	if strings.Join(lodash.Tags, ",") != strings.Join(wantTags, ",") {
+		t.Errorf("lodash tags = %v", lodash.Tags)
+	}
+
+	// No advisory label or CWE: severity from the CVSS vector, default CWE.
+	if jinja.Severity != finding.SeverityMedium || jinja.CWE != DefaultCWE {
+		t.Errorf("jinja2 severity/cwe = %s/%s", jinja.Severity, jinja.CWE)
+	}
+	if jinja.Location.File != "requirements.txt" || jinja.Location.LineStart != 1 {
+		t.Errorf("jinja2 location = %+v", jinja.Location)
+	}
+}
+
+func TestMatchFingerprintIgnoresVersion(t *testing.T) {
+	v := &Vulnerability{ID: "GHSA-x", Summary: "Prototype pollution"}
+	a := Match{Package: Package{Ecosystem: EcosystemNPM, Name: "lodash", Version: "4.17.15", File: "package-lock.json", Line: 9}, Vulnerability: v}
+	b := a
+	b.Package.Version = "4.17.19"
+	b.Package.Line = 12
+	if a.Finding().Fingerprint != b.Finding().Fingerprint {
+		t.Error("fingerprint should not change with the pinned version or line")
+	}
+	if !strings.Contains(a.Finding().Remediation, "No fixed version") {
+		t.Errorf("remediation without fix = %q", a.Finding().Remediation)
+	}
+}
@@ -0,0 +1,175 @@
+package deps
+
+import (
+	"strconv"
+	"strings"
+)
+
+// compareVersions orders two versions of a package in the given ecosystem,
+// returning -1, 0 or 1. It is deliberately forgiving rather than a full
+// implementation of each ecosystem's rules: semver-style ecosystems get
+// semver pre-release ordering, everything else (PEP 440, Maven, RubyGems)
+// a tokenised comparison where numeric segments compare numerically and
+// qualifiers such as dev, alpha, rc and post sort around the release.
+func compareVersions(ecosystem, a, b string) int {
+	switch ecosystem {
+	case EcosystemGo, EcosystemNPM, EcosystemCrates:
+		return compareSemver(a, b)
+	}
+	return compareTokens(tokenize(normalizeVersion(a)), tokenize(normalizeVersion(b)))
+}
+
+func normalizeVersion(v string) string {
+	v = strings.TrimSpace(v)
+	v = strings.TrimPrefix(strings.TrimPrefix(v, "v"), "V")
+	// Build metadata (semver +build, PEP 440 +local) never affects ordering.
+	if i := strings.Index(v, "+"); i >= 0 {
+		v = v[:i]
+	}
+	return v
+}
+
+// compareSemver compares MAJOR.MINOR.PATCH first; a version with a
+// pre-release suffix sorts before the same core without one. Go
+// pseudo-versions (v0.0.0-20200101000000-abcdef) are pre-releases too.
+func compareSemver(a, b string) int {
+	a, b = normalizeVersion(a), normalizeVersion(b)
+	coreA, preA, _ := strings.Cut(a, "-")
+	coreB, preB, _ := strings.Cut(b, "-")
+	if c := compareTokens(tokenize(coreA), tokenize(coreB)); c != 0 {
+		return c
+	}
+	switch {
+	case preA == preB:
+		return 0
+	case preA == "":
+		return 1
+	case preB == "":
+		return -1
+	}
+	return compareTokens(tokenize(preA), tokenize(preB))
+}
+
+// versionToken is a run of digits or a run of letters.
+type versionToken struct {
+	num     int
+	text    string
+	numeric bool
+}
+
+func tokenize(v string) []versionToken {
+	var out []versionToken
+	for i := 0; i < len(v); {
+		c := v[i]
+		switch {
+		case isDigit(c):
+			j := i
+			for j < len(v) && isDigit(v[j]) {
+				j++
+			}
+			n, err := strconv.Atoi(v[i:j])
+			if err != nil {
+				// Longer than an int — Go pseudo-version timestamps fit, but
+				// be safe and compare textually.
+				out = append(out, versionToken{text: v[i:j]})
+			} else {
+				out = append(out, versionToken{num: n, numeric: true})
+			}
+			i = j
+		case isLetter(c):
+			j := i
+			for j < len(v) && isLetter(v[j]) {
+				j++
+			}
+			out = append(out, versionToken{text: strings.ToLower(v[i:j])})
+			i = j
+		default:
+			i++ // separators
+		}
+	}
+	return out
+}
+
+// qualifierRank places textual segments relative to a release (0).
+// Unknown words rank just below a release, like a pre-release.
+func qualifierRank(q string) int {
+	switch q {
+	case "dev":
+		return -50
+	case "a", "alpha":
+		return -40
+	case "b", "beta":
+		return -30
+	case "m", "milestone", "pre", "preview":
+		return -20
+	case "c", "rc", "cr":
+		return -10
+	case "snapshot":
+		return -5
+	case "final", "ga", "release":
+		return 0
+	case "post", "sp", "patch", "p":
+		return 10
+	}
+	return -1
+}
+
+func compareTokens(a, b []versionToken) int {
+	n := max(len(a), len(b))
+	for i := 0; i < n; i++ {
+		// A missing segment behaves as a plain release: 0 against a
+		// number (1.0 == 1.0.0), rank 0 against a qualifier (1.0 > 1.0rc1).
+		ta, okA := tokenAt(a, i)
+		tb, okB := tokenAt(b, i)
+		if !okA && !okB {
+			return 0
+		}
+		if c := compareToken(ta, okA, tb, okB); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func tokenAt(tokens []versionToken, i int) (versionToken, bool) {
+	if i < len(tokens) {
+		return tokens[i], true
+	}
+	return versionToken{}, false
+}
+
+func compareToken(a versionToken, okA bool, b versionToken, okB bool) int {
+	switch {
+	case !okA:
+		return -compareToken(b, okB, a, okA)
+	case !okB:
+		if a.numeric {
+			return sign(a.num)
+		}
+		return sign(qualifierRank(a.text))
+	case a.numeric && b.numeric:
+		return sign(a.num - b.num)
+	case a.numeric:
+		return 1 // 1.0.1 > 1.0rc1, 1.0.1 > 1.0.post1
+	case b.numeric:
+		return -1
+	}
+	ra, rb := qualifierRank(a.text), qualifierRank(b.text)
+	if ra != rb {
+		return sign(ra - rb)
+	}
+	return strings.Compare(a.text, b.text)
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
+
+func isDigit(c byte) bool  { return c >= '0' && c <= '9' }
+func isLetter(c byte) bool { return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' }
@@ -0,0 +1,295 @@
+package embedding
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// LocalModel is the only local embedding model. The name is versioned so a
+// change to the feature set can ship as a new model instead of silently
+// invalidating existing indexes.
+const LocalModel = "code-hash-v1"
+
+// localDefaultDimension balances collisions against index size
+const localDefaultDimension = 384
+
+// Feature weights. Whole identifiers and their parts carry the most signal;
+// trigrams give typo and inflection tolerance; concepts bridge vocabulary
+// (login vs authenticate) the way a learned model would.
+const (
+	localWeightToken   = 1.0
+	localWeightConcept = 0.8
+	localWeightStem    = 0.5
+	localWeightBigram  = 0.4
+	localWeightTrigram = 0.3
+)
+
+// localStopwords are keywords and filler words present in nearly every chunk
+var localStopwords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "be": true, "by": true,
+	"const": true, "def": true, "do": true, "else": true, "end": true, "err": true,
+	"false": true, "fn": true, "for": true, "from": true, "func": true, "function": true,
+	"if": true, "import": true, "in": true, "int": true, "is": true, "it": true, "let": true,
+	"new": true, "nil": true, "none": true, "not": true, "null": true, "of": true, "on": true,
+	"or": true, "package": true, "private": true, "public": true, "return": true,
+	"self": true, "static": true, "string": true, "the": true, "this": true, "to": true,
+	"true": true, "var": true, "void": true, "with": true,
+}
+
+// localConcepts groups terms a reviewer would treat as the same idea. Each
+// term also emits its concept as a feature.
+var localConcepts = map[string][]string{
+	"auth":     {"auth", "authn", "authenticate", "authentication", "login", "logon", "signin", "credential", "credentials", "password", "passwd", "jwt", "oauth", "saml", "sso", "bearer"},
+	"authz":    {"authz", "authorize", "authorization", "permission", "permissions", "role", "roles", "acl", "rbac", "policy", "admin", "privilege", "scope"},
+	"session":  {"session", "sessions", "cookie", "cookies", "token", "tokens", "csrf", "xsrf", "nonce"},
+	"crypto":   {"crypto", "cipher", "encrypt", "decrypt", "aes", "rsa", "hmac", "sha", "sha1", "sha256", "md5", "hash", "digest", "signature", "sign", "verify", "key", "secret", "salt", "bcrypt", "argon2", "pbkdf2"},
+	"random":   {"random", "rand", "entropy", "uuid", "nonce", "seed"},
+	"sql":      {"sql", "query", "queries", "select", "insert", "update", "delete", "where", "database", "db", "orm", "execute", "exec", "cursor", "sqlite", "postgres", "mysql"},
+	"command":  {"command", "cmd", "exec", "shell", "spawn", "subprocess", "popen", "system", "process", "bash", "sh"},
+	"file":     {"file", "files", "path", "filepath", "filename", "dir", "directory", "open", "read", "write", "upload", "download", "traversal"},
+	"http":     {"http", "https", "request", "response", "req", "res", "resp", "header", "headers", "url", "uri", "endpoint", "route", "handler", "router", "middleware", "client", "fetch", "curl"},
+	"input":    {"input", "param", "params", "parameter", "query", "body", "form", "args", "argv", "payload", "user", "untrusted"},
+	"validate": {"validate", "validation", "validator", "sanitize", "sanitise", "escape", "clean", "filter", "check", "whitelist", "allowlist", "regex", "regexp"},
+	"error":    {"error", "errors", "exception", "panic", "recover", "catch", "throw", "raise", "fail", "failure"},
+	"log":      {"log", "logger", "logging", "audit", "trace", "debug", "print", "printf"},
+	"template": {"template", "render", "html", "xss", "innerhtml", "markup", "view"},
+	"serial":   {"serialize", "deserialize", "marshal", "unmarshal", "pickle", "yaml", "json", "xml", "decode", "encode", "parse"},
+	"redirect": {"redirect", "forward", "location", "next", "callback"},
+	"network":  {"socket", "dial", "connect", "listen", "tcp", "udp", "ip", "host", "port", "proxy", "dns", "ssrf"},
+	"config":   {"config", "configuration", "settings", "env", "environment", "option", "options", "flag"},
+}
+
+// localConceptOf maps each term to its concepts
+var localConceptOf = func() map[string][]string {
+	out := make(map[string][]string)
+	for concept, terms := range localConcepts {
+		for _, t := range terms {
+			out[t] = append(out[t], concept)
+		}
+	}
+	return out
+}()
+
+// LocalProvider implements Provider in-process with no model files or
+// network: code-aware tokens, subword trigrams and security concepts are
+// hashed with random signs into a fixed-size vector (the hashing trick, a
+// sparse random projection of the feature space). Output is deterministic
+// for a given model and dimension.
+type LocalProvider struct {
+	config *Config
+	seed   string
+}
+
+// NewLocalProvider creates a new local embedding provider
+func NewLocalProvider(config *Config) (*LocalProvider, error) {
+	model := config.Model
+	if model == "" {
+		model = LocalModel
+	}
+	if model != LocalModel {
+		return nil, fmt.Errorf("unknown local model %q (available: %s)", model, LocalModel)
+	}
+
+	dimension := config.Dimension
+	if dimension <= 0 {
+		dimension = localDefaultDimension
+	}
+
+	batchSize := config.BatchSize
+	if batchSize == 0 {
+		batchSize = 256
+	}
+
+	return &LocalProvider{
+		config: &Config{
+			Provider:  "local",
+			Model:     model,
+			Dimension: dimension,
+			BatchSize: batchSize,
+		},
+		seed: model + "\x00",
+	}, nil
+}
+
+// Name returns the provider name
+func (p *LocalProvider) Name() string {
+	return "local"
+}
+
+// Dimension returns the embedding dimension
+func (p *LocalProvider) Dimension() int {
+	return p.config.Dimension
+}
+
+// Embed generates an embedding for a single text
+func (p *LocalProvider) Embed(ctx context.Context, text string) ([]float32, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return p.embed(text), nil
+}
+
+// EmbedBatch generates embeddings for multiple texts
+func (p *LocalProvider) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	out := make([][]float32, len(texts))
+	for i, text := range texts {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		out[i] = p.embed(text)
+	}
+	return out, nil
+}
+
+// Close releases any resources
+func (p *LocalProvider) Close() error {
+	return nil
+}
+
+// embed hashes the weighted features of text into an L2-normalized vector
+func (p *LocalProvider) embed(text string) []float32 {
+	features := localFeatures(text)
+	if len(features) == 0 {
+		// A zero vector has no cosine similarity; give empty text a
+		// fixed direction instead
+		features = map[string]float64{"<empty>": 1}
+	}
+
+	// Accumulate in a fixed order: float addition is not associative, and
+	// map order would make the output differ between runs
+	names := make([]string, 0, len(features))
+	for f := range features {
+		names = append(names, f)
+	}
+	sort.Strings(names)
+
+	vec := make([]float64, p.config.Dimension)
+	h := fnv.New64a()
+	for _, f := range names {
+		w := features[f]
+		h.Reset()
+		_, _ = h.Write([]byte(p.seed))
+		_, _ = h.Write([]byte(f))
+		sum := h.Sum64()
+		// Sublinear term frequency, so a name repeated fifty times does not
+		// drown out everything else in the chunk
+		if w > 1 {
+			w = 1 + math.Log(w)
+		}
+		if sum>>63 == 1 {
+			w = -w
+		}
+		vec[sum%uint64(len(vec))] += w
+	}
+
+	var norm float64
+	for _, v := range vec {
+		norm += v * v
+	}
+	norm = math.Sqrt(norm)
+	out := make([]float32, len(vec))
+	if norm == 0 {
+		out[0] = 1
+		return out
+	}
+	for i, v := range vec {
+		out[i] = float32(v / norm)
+	}
+	return out
+}
+
+// localFeatures extracts weighted features from text. Feature names are
+// prefixed by kind so a token and an identical trigram do not collide.
+func localFeatures(text string) map[string]float64 {
+	features := make(map[string]float64)
+	var prev string
+	for _, ident := range localIdentifiers(text) {
+		parts := splitIdentifier(ident)
+		if whole := strings.ToLower(ident); len(parts) > 1 && !localStopwords[whole] {
+			features["w:"+whole] += localWeightToken
+		}
+		for _, part := range parts {
+			if len(part) < 2 || localStopwords[part] {
+				continue
+			}
+			features["t:"+part] += localWeightToken
+			if stem := localStem(part); stem != part {
+				features["s:"+stem] += localWeightStem
+			}
+			for _, c := range localConceptOf[part] {
+				features["c:"+c] += localWeightConcept
+			}
+			if prev != "" {
+				features["b:"+prev+" "+part] += localWeightBigram
+			}
+			prev = part
+
+			grams := localTrigrams(part)
+			scale := localWeightTrigram / math.Sqrt(float64(len(grams)))
+			for _, g := range grams {
+				features["g:"+g] += scale
+			}
+		}
+	}
+	return features
+}
+
+// localIdentifiers splits text into runs of letters, digits and underscores
+func localIdentifiers(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	})
+}
+
+// splitIdentifier breaks an identifier into lowercase parts at underscores,
+// camelCase humps and acronym ends (HTTPServer); digits stay attached so
+// sha256 and utf8 remain one part
+func splitIdentifier(ident string) []string {
+	var parts []string
+	for _, seg := range strings.Split(ident, "_") {
+		runes := []rune(seg)
+		start := 0
+		for i := 1; i < len(runes); i++ {
+			prev, cur := runes[i-1], runes[i]
+			boundary := (unicode.IsLower(prev) || unicode.IsDigit(prev)) && unicode.IsUpper(cur) ||
+				unicode.IsUpper(prev) && unicode.IsUpper(cur) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if boundary {
+				parts = append(parts, strings.ToLower(string(runes[start:i])))
+				start = i
+			}
+		}
+		if start < len(runes) {
+			parts = append(parts, strings.ToLower(string(runes[start:])))
+		}
+	}
+	return parts
+}
+
+// localStem strips common English suffixes so validate, validates and
+// validation share a feature
+func localStem(word string) string {
+	for _, suffix := range []string{"ations", "ation", "ings", "ing", "ers", "er", "ed", "es", "s"} {
+		if strings.HasSuffix(word, suffix) && len(word)-len(suffix) >= 3 {
+			return strings.TrimSuffix(word, suffix)
+		}
+	}
+	return word
+}
+
+// localTrigrams returns the character trigrams of word with boundary marks
+func localTrigrams(word string) []string {
+	runes := []rune("<" + word + ">")
+	if len(runes) <= 3 {
+		return []string{string(runes)}
+	}
+	grams := make([]string, 0, len(runes)-2)
+	for i := 0; i+3 <= len(runes); i++ {
+		grams = append(grams, string(runes[i:i+3]))
+	}
+	return grams
+}
@@ -0,0 +1,103 @@
+package embedding
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func cosine(a, b []float32) float64 {
+	var dot float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+	}
+	return dot
+}
+
+func TestLocalProviderDeterministic(t *testing.T) {
+	p, err := NewProvider(&Config{Provider: "local"})
+	if err != nil {
+		t.Fatalf("NewProvider: %v", err)
+	}
+	defer func() { _ = p.Close() }()
+	if p.Dimension() != localDefaultDimension {
+		t.Errorf("Dimension() = %d, want %d", p.Dimension(), localDefaultDimension)
+	}
+
+	text := "func verifyHMAC(sig, body []byte) bool { return hmac.Equal(sig, mac(body)) }"
+	a, err := p.Embed(context.Background(), text)
+	if err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	batch, err := p.EmbedBatch(context.Background(), []string{text, ""})
+	if err != nil {
+		t.Fatalf("EmbedBatch: %v", err)
+	}
+	if !reflect.DeepEqual(a, batch[0]) {
+		t.Error("Embed and EmbedBatch disagree for the same text")
+	}
+	if n := cosine(a, a); math.Abs(n-1) > 1e-5 {
+		t.Errorf("embedding not normalized: |v|^2 = %v", n)
+	}
+	if n := cosine(batch[1], batch[1]); math.Abs(n-1) > 1e-5 {
+		t.Errorf("empty text embedding not normalized: |v|^2 = %v", n)
+	}
+
+	// A second provider instance must produce identical output
+	q, _ := NewLocalProvider(&Config{})
+	b, _ := q.Embed(context.Background(), text)
+	if !reflect.DeepEqual(a, b) {
+		t.Error("embeddings differ between provider instances")
+	}
+}
+
+func TestLocalProviderSimilarity(t *testing.T) {
+	p, _ := NewLocalProvider(&Config{Dimension: 256})
+	embed := func(s string) []float32 {
+		v, err := p.Embed(context.Background(), s)
+		if err != nil {
+			t.Fatalf("Embed(%q): %v", s, err)
+		}
+		if len(v) != 256 {
+			t.Fatalf("len = %d, want 256", len(v))
+		}
+		return v
+	}
+
+	query := embed("authentication middleware")
+	login := embed("def require_login(view):\n    if not request.user.is_authenticated:\n        return redirect('/login')")
+	render := embed("def render_chart(data):\n    plot.bar(data.labels, data.values)")
+	if cosine(query, login) <= cosine(query, render) {
+		t.Errorf("auth query closer to chart code (%.3f) than login check (%.3f)", cosine(query, render), cosine(query, login))
+	}
+
+	ident := embed("verify hmac signature")
+	hmac := embed("func verifyHMAC(sig []byte) bool")
+	other := embed("func listUsers(db *sql.DB) []User")
+	if cosine(ident, hmac) <= cosine(ident, other) {
+		t.Errorf("identifier parts not matched: hmac %.3f, other %.3f", cosine(ident, hmac), cosine(ident, other))
+	}
+}
+
+func TestLocalProviderUnknownModel(t *testing.T) {
+	if _, err := NewLocalProvider(&Config{Model: "bge-small"}); err == nil {
+		t.Error("expected error for unknown local model")
+	}
+	if err := ValidateConfig(&Config{Provider: "local"}); err != nil {
+		t.Errorf("ValidateConfig: %v", err)
+	}
+}
+
+func TestSplitIdentifier(t *testing.T) {
+	for in, want := range map[string][]string{
+		"verifyHMAC":      {"verify", "hmac"},
+		"HTTPServer":      {"http", "server"},
+		"x_forwarded_for": {"x", "forwarded", "for"},
+		"sha256Sum":       {"sha256", "sum"},
+	} {
+		if got := splitIdentifier(in); !reflect.DeepEqual(got, want) {
+			t.Errorf("splitIdentifier(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
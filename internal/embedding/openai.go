@@ -4,19 +4,64 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync/atomic"
 	"time"
 )
 
 const openAIAPIURL = "https://api.openai.com/v1/embeddings"
 
-// OpenAIProvider implements Provider using OpenAI's API
+// Auth schemes for OpenAI-compatible endpoints
+const (
+	// AuthBearer sends "Authorization: Bearer <key>" (OpenAI, vLLM, LiteLLM)
+	AuthBearer = "bearer"
+	// AuthHeader sends the raw key in Config.AuthHeader (Azure: api-key)
+	AuthHeader = "header"
+	// AuthNone sends no credentials (local llama.cpp, vLLM without --api-key)
+	AuthNone = "none"
+)
+
+// defaultAuthHeader is the key header for AuthHeader, as used by Azure OpenAI
+const defaultAuthHeader = "api-key"
+
+// The project config is repository content, so a pull request can change
+// it. These variables are set by the user instead and decide where secrets
+// may go.
+const (
+	// EndpointEnv names a custom endpoint the user trusts with the API key;
+	// it overrides the endpoint in the project config
+	EndpointEnv = "QUOKKA_EMBEDDING_ENDPOINT"
+	// HeaderEnvEnv lists, comma-separated, the environment variables that
+	// header values may reference besides the API key variable
+	HeaderEnvEnv = "QUOKKA_EMBEDDING_HEADER_ENV"
+)
+
+// tooLargeRE recognises 400 responses rejecting a batch for its size rather
+// than its content; wording differs between OpenAI, vLLM, llama.cpp and
+// LiteLLM. A 413 needs no message check.
+var tooLargeRE = regexp.MustCompile(`(?i)maximum context length|context[ _]length[ _]exceeded|too many (inputs|tokens)|input (batch )?(is )?too (large|long)|batch size \d+ exceeds|exceeds (the )?max(imum)? (batch|input|number of inputs)|n_ubatch|physical batch|(payload|request entity|request) too large`)
+
+// errBatchTooLarge marks a request rejected because the batch was too big
+var errBatchTooLarge = errors.New("batch too large")
+
+// OpenAIProvider implements Provider using OpenAI's API or any server that
+// speaks the same /v1/embeddings protocol
 type OpenAIProvider struct {
-	config *Config
-	client *http.Client
-	apiKey string
+	config    *Config
+	client    *http.Client
+	apiKey    string
+	endpoint  string
+	headers   map[string]string
+	limiter   *rateLimiter
+	backoff   time.Duration
+	batchSize atomic.Int64
 }
 
 // openAIEmbedRequest is the request format for OpenAI embeddings
@@ -45,17 +90,45 @@ type openAIEmbedResponse struct {
 	} `json:"error,omitempty"`
 }
 
-// NewOpenAIProvider creates a new OpenAI embedding provider
+// NewOpenAIProvider creates a new OpenAI embedding provider. A custom
+// Endpoint points it at an OpenAI-compatible server instead.
 func NewOpenAIProvider(config *Config) (*OpenAIProvider, error) {
 	apiKeyEnv := config.APIKeyEnv
 	if apiKeyEnv == "" {
 		apiKeyEnv = "OPENAI_API_KEY"
 	}
 
-	apiKey, err := GetAPIKey(apiKeyEnv)
+	authScheme := strings.ToLower(config.AuthScheme)
+	if authScheme == "" {
+		authScheme = AuthBearer
+	}
+	if authScheme != AuthBearer && authScheme != AuthHeader && authScheme != AuthNone {
+		return nil, fmt.Errorf("unknown auth scheme %q (expected bearer, header or none)", config.AuthScheme)
+	}
+
+	var apiKey string
+	if authScheme != AuthNone {
+		var err error
+		apiKey, err = GetAPIKey(apiKeyEnv)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// A custom endpoint from the project config alone never receives the key
+	// or expanded header values; the user has to name it in EndpointEnv
+	configured := config.Endpoint
+	if env := os.Getenv(EndpointEnv); env != "" {
+		configured = env
+	}
+	endpoint, err := openAIEndpointURL(configured)
 	if err != nil {
 		return nil, err
 	}
+	trusted := endpoint == openAIAPIURL || os.Getenv(EndpointEnv) != ""
+	if !trusted && authScheme != AuthNone {
+		return nil, fmt.Errorf("refusing to send $%s to embedding endpoint %s set only in the project config; export %s=%s to allow it, or use auth_scheme none", apiKeyEnv, config.Endpoint, EndpointEnv, config.Endpoint)
+	}
 
 	model := config.Model
 	if model == "" {
@@ -82,19 +155,111 @@ func NewOpenAIProvider(config *Config) (*OpenAIProvider, error) {
 		batchSize = 100
 	}
 
-	return &OpenAIProvider{
+	maxRetries := config.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = defaultMaxRetries
+	} else if maxRetries < 0 {
+		maxRetries = 0
+	}
+
+	authHeader := config.AuthHeader
+	if authHeader == "" {
+		authHeader = defaultAuthHeader
+	}
+
+	headers, err := expandHeaders(config.Headers, apiKeyEnv, trusted)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &OpenAIProvider{
 		config: &Config{
-			Provider:  "openai",
-			Model:     model,
-			APIKeyEnv: apiKeyEnv,
-			Dimension: dimension,
-			BatchSize: batchSize,
+			Provider:          "openai",
+			Model:             model,
+			Endpoint:          configured,
+			APIKeyEnv:         apiKeyEnv,
+			Dimension:         dimension,
+			BatchSize:         batchSize,
+			AuthScheme:        authScheme,
+			AuthHeader:        authHeader,
+			MaxRetries:        maxRetries,
+			RequestsPerMinute: config.RequestsPerMinute,
 		},
 		client: &http.Client{
 			Timeout: 60 * time.Second,
 		},
-		apiKey: apiKey,
-	}, nil
+		apiKey:   apiKey,
+		endpoint: endpoint,
+		headers:  headers,
+		limiter:  newRateLimiter(config.RequestsPerMinute),
+		backoff:  defaultRetryBackoff,
+	}
+	p.batchSize.Store(int64(batchSize))
+	return p, nil
+}
+
+// expandHeaders expands ${VAR} references in header values so gateway
+// secrets stay out of the project config. Only the API key variable and
+// those listed in HeaderEnvEnv may be referenced, and none at all when the
+// endpoint is not trusted; anything else is an error rather than a silent
+// empty value.
+func expandHeaders(in map[string]string, apiKeyEnv string, trusted bool) (map[string]string, error) {
+	allowed := map[string]bool{}
+	if trusted {
+		allowed[apiKeyEnv] = true
+		for _, name := range strings.Split(os.Getenv(HeaderEnvEnv), ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				allowed[name] = true
+			}
+		}
+	}
+	out := make(map[string]string, len(in))
+	for k, v := range in {
+		var denied string
+		out[k] = os.Expand(v, func(name string) string {
+			if !allowed[name] {
+				if denied == "" {
+					denied = name
+				}
+				return ""
+			}
+			return os.Getenv(name)
+		})
+		if denied != "" {
+			if !trusted {
+				return nil, fmt.Errorf("header %s references $%s, but the embedding endpoint is set only in the project config; export %s to allow it", k, denied, EndpointEnv)
+			}
+			return nil, fmt.Errorf("header %s references $%s; add it to %s to allow it", k, denied, HeaderEnvEnv)
+		}
+	}
+	return out, nil
+}
+
+// openAIEndpointURL resolves a configured endpoint to the embeddings URL.
+// A base URL (http://host:8000 or http://host:8000/v1) gets
+// /v1/embeddings or /embeddings appended; a URL already ending in
+// /embeddings is used as is, query string included (Azure api-version).
+func openAIEndpointURL(endpoint string) (string, error) {
+	if endpoint == "" {
+		return openAIAPIURL, nil
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("invalid embedding endpoint %q: expected an http(s) URL", endpoint)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("invalid embedding endpoint %q: unsupported scheme %s", endpoint, u.Scheme)
+	}
+	path := strings.TrimRight(u.Path, "/")
+	switch {
+	case strings.HasSuffix(path, "/embeddings"):
+	case strings.HasSuffix(path, "/v1"):
+		path += "/embeddings"
+	default:
+		path += "/v1/embeddings"
+	}
+	u.Path = path
+	return u.String(), nil
 }
 
 // Name returns the provider name
@@ -116,37 +281,91 @@ func (p *OpenAIProvider) Embed(ctx context.Context, text string) ([]float32, err
 	return embeddings[0], nil
 }
 
-// EmbedBatch generates embeddings for multiple texts
+// EmbedBatch generates embeddings for multiple texts. When the server
+// rejects a batch as too large the batch size is halved, for this and all
+// later calls, until requests fit.
 func (p *OpenAIProvider) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
 	if len(texts) == 0 {
 		return [][]float32{}, nil
 	}
 
-	// Process in batches
-	batchSize := p.config.BatchSize
 	allEmbeddings := make([][]float32, len(texts))
 
-	for i := 0; i < len(texts); i += batchSize {
-		end := i + batchSize
-		if end > len(texts) {
-			end = len(texts)
-		}
+	for i := 0; i < len(texts); {
+		end := min(i+p.currentBatchSize(), len(texts))
 
-		batch := texts[i:end]
-		embeddings, err := p.embedBatchInternal(ctx, batch)
+		embeddings, err := p.embedWithRetry(ctx, texts[i:end])
+		if errors.Is(err, errBatchTooLarge) && end-i > 1 {
+			p.shrinkBatch(end - i)
+			continue
+		}
 		if err != nil {
 			return nil, err
 		}
 
-		for j, emb := range embeddings {
-			allEmbeddings[i+j] = emb
-		}
+		copy(allEmbeddings[i:end], embeddings)
+		i = end
 	}
 
 	return allEmbeddings, nil
 }
 
-func (p *OpenAIProvider) embedBatchInternal(ctx context.Context, texts []string) ([][]float32, error) {
+// BatchSize returns the current, possibly auto-tuned, batch size
+func (p *OpenAIProvider) BatchSize() int {
+	return p.currentBatchSize()
+}
+
+func (p *OpenAIProvider) currentBatchSize() int {
+	if n := p.batchSize.Load(); n > 0 {
+		return int(n)
+	}
+	return max(p.config.BatchSize, 1)
+}
+
+// shrinkBatch halves the batch size after a batch of n was rejected. Only
+// shrinks: a concurrent caller may already have gone lower.
+func (p *OpenAIProvider) shrinkBatch(n int) {
+	next := int64(max(n/2, 1))
+	for {
+		cur := p.batchSize.Load()
+		if cur > 0 && cur <= next {
+			return
+		}
+		if p.batchSize.CompareAndSwap(cur, next) {
+			return
+		}
+	}
+}
+
+// embedWithRetry sends one batch, retrying rate limits, server errors and
+// network failures with exponential backoff
+func (p *OpenAIProvider) embedWithRetry(ctx context.Context, texts []string) ([][]float32, error) {
+	for attempt := 0; ; attempt++ {
+		if err := p.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+		embeddings, header, retry, err := p.embedBatchInternal(ctx, texts)
+		if err == nil || !retry || attempt >= p.config.MaxRetries || ctx.Err() != nil {
+			return embeddings, err
+		}
+		if err := sleepContext(ctx, retryDelay(attempt, p.backoff, header)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// redact removes the API key and any custom header values from s
+func (p *OpenAIProvider) redact(s string) string {
+	s = redactSecret(s, p.apiKey)
+	for _, v := range p.headers {
+		s = redactSecret(s, v)
+	}
+	return s
+}
+
+// embedBatchInternal sends a single request. It reports whether a failure
+// is worth retrying, with the response headers for Retry-After.
+func (p *OpenAIProvider) embedBatchInternal(ctx context.Context, texts []string) ([][]float32, http.Header, bool, error) {
 	reqBody := openAIEmbedRequest{
 		Model: p.config.Model,
 		Input: texts,
@@ -154,57 +373,95 @@ func (p *OpenAIProvider) embedBatchInternal(ctx context.Context, texts []string)
 
 	jsonBody, err := json.Marshal(reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
+		return nil, nil, false, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", openAIAPIURL, bytes.NewReader(jsonBody))
+	endpoint := p.endpoint
+	if endpoint == "" {
+		endpoint = openAIAPIURL
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(jsonBody))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, nil, false, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	switch p.config.AuthScheme {
+	case AuthNone:
+	case AuthHeader:
+		req.Header.Set(p.config.AuthHeader, p.apiKey)
+	default:
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+	for k, v := range p.headers {
+		req.Header.Set(k, v)
+	}
 
 	resp, err := p.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request to OpenAI: %w", err)
+		return nil, nil, true, fmt.Errorf("failed to send request to OpenAI: %s", p.redact(err.Error()))
 	}
 	defer func() { _ = resp.Body.Close() }()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return nil, resp.Header, true, fmt.Errorf("failed to read response: %w", err)
 	}
 
+	retry := retryableStatus(resp.StatusCode)
+	tooLarge := resp.StatusCode == http.StatusRequestEntityTooLarge
+
 	var result openAIEmbedResponse
 	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+		if resp.StatusCode != http.StatusOK {
+			// Non-JSON error page; redact the key from the raw body
+			safeBody := p.redact(string(body))
+			if tooLarge || resp.StatusCode == http.StatusBadRequest && tooLargeRE.MatchString(safeBody) {
+				return nil, resp.Header, false, fmt.Errorf("%w: OpenAI API error (status %d): %s", errBatchTooLarge, resp.StatusCode, safeBody)
+			}
+			return nil, resp.Header, retry, fmt.Errorf("OpenAI API error (status %d): %s", resp.StatusCode, safeBody)
+		}
+		return nil, resp.Header, false, fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	if result.Error != nil {
 		// Redact API key in case the upstream echoed the Authorization header
 		// into the error message (some hostile/buggy servers do).
-		msg := redactSecret(result.Error.Message, p.apiKey)
-		return nil, fmt.Errorf("OpenAI API error: %s (%s)", msg, result.Error.Type)
+		msg := p.redact(result.Error.Message)
+		if tooLarge || resp.StatusCode == http.StatusBadRequest && tooLargeRE.MatchString(msg) {
+			return nil, resp.Header, false, fmt.Errorf("%w: OpenAI API error: %s (%s)", errBatchTooLarge, msg, result.Error.Type)
+		}
+		return nil, resp.Header, retry, fmt.Errorf("OpenAI API error: %s (%s)", msg, result.Error.Type)
 	}
 
 	if resp.StatusCode != http.StatusOK {
 		// Redact API key from raw response body before including in error.
-		safeBody := redactSecret(string(body), p.apiKey)
-		return nil, fmt.Errorf("OpenAI API error (status %d): %s", resp.StatusCode, safeBody)
+		safeBody := p.redact(string(body))
+		if tooLarge {
+			return nil, resp.Header, false, fmt.Errorf("%w: OpenAI API error (status %d): %s", errBatchTooLarge, resp.StatusCode, safeBody)
+		}
+		return nil, resp.Header, retry, fmt.Errorf("OpenAI API error (status %d): %s", resp.StatusCode, safeBody)
 	}
 
 	// Convert to float32 and ensure order matches input
 	embeddings := make([][]float32, len(texts))
 	for _, data := range result.Data {
+		if data.Index < 0 || data.Index >= len(texts) {
+			return nil, resp.Header, false, fmt.Errorf("OpenAI API returned embedding index %d for %d inputs", data.Index, len(texts))
+		}
 		embedding := make([]float32, len(data.Embedding))
 		for i, v := range data.Embedding {
 			embedding[i] = float32(v)
 		}
 		embeddings[data.Index] = embedding
 	}
+	for i, e := range embeddings {
+		if e == nil {
+			return nil, resp.Header, false, fmt.Errorf("OpenAI API returned no embedding for input %d of %d", i, len(texts))
+		}
+	}
 
-	return embeddings, nil
+	return embeddings, resp.Header, false, nil
 }
 
 // Close releases resources
@@ -0,0 +1,306 @@
+package embedding
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeEmbeddingsHandler answers /v1/embeddings requests with a 2-dimension
+// vector per input whose first element is the input's length, returning
+// the data in reverse order to exercise index handling
+func fakeEmbeddingsHandler(t *testing.T, check func(r *http.Request, inputs []string) (int, string)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req openAIEmbedRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if check != nil {
+			if code, body := check(r, req.Input); code != 0 {
+				w.WriteHeader(code)
+				_, _ = w.Write([]byte(body))
+				return
+			}
+		}
+		var data []string
+		for i := len(req.Input) - 1; i >= 0; i-- {
+			data = append(data, fmt.Sprintf(`{"object":"embedding","index":%d,"embedding":[%d,1]}`, i, len(req.Input[i])))
+		}
+		_, _ = fmt.Fprintf(w, `{"object":"list","data":[%s],"model":%q}`, strings.Join(data, ","), req.Model)
+	}
+}
+
+func newTestOpenAI(t *testing.T, endpoint string, cfg Config) *OpenAIProvider {
+	t.Helper()
+	t.Setenv("QUOKKA_TEST_EMBED_KEY", fakeAPIKey)
+	t.Setenv(EndpointEnv, endpoint)
+	cfg.Provider = "openai"
+	cfg.Endpoint = endpoint
+	cfg.APIKeyEnv = "QUOKKA_TEST_EMBED_KEY"
+	cfg.Dimension = 2
+	p, err := NewOpenAIProvider(&cfg)
+	if err != nil {
+		t.Fatalf("NewOpenAIProvider: %v", err)
+	}
+	p.backoff = time.Millisecond
+	return p
+}
+
+func TestOpenAIEndpointURL(t *testing.T) {
+	for in, want := range map[string]string{
+		"":                           openAIAPIURL,
+		"http://vllm:8000":           "http://vllm:8000/v1/embeddings",
+		"http://vllm:8000/v1/":       "http://vllm:8000/v1/embeddings",
+		"https://gw.internal/openai": "https://gw.internal/openai/v1/embeddings",
+		"https://x.openai.azure.com/openai/deployments/emb/embeddings?api-version=2024-02-01": "https://x.openai.azure.com/openai/deployments/emb/embeddings?api-version=2024-02-01",
+	} {
+		got, err := openAIEndpointURL(in)
+		if err != nil || got != want {
+			t.Errorf("openAIEndpointURL(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, bad := range []string{"vllm:8000", "ftp://host/v1", "://"} {
+		if _, err := openAIEndpointURL(bad); err == nil {
+			t.Errorf("openAIEndpointURL(%q) should fail", bad)
+		}
+	}
+}
+
+func TestOpenAICustomEndpointHeadersAndAuth(t *testing.T) {
+	t.Setenv("QUOKKA_TEST_TEAM", "appsec")
+	t.Setenv(HeaderEnvEnv, "QUOKKA_TEST_TEAM")
+	var seen http.Header
+	srv := httptest.NewServer(fakeEmbeddingsHandler(t, func(r *http.Request, _ []string) (int, string) {
+		if r.URL.Path != "/v1/embeddings" {
+			return http.StatusNotFound, "wrong path " + r.URL.Path
+		}
+		seen = r.Header.Clone()
+		return 0, ""
+	}))
+	defer srv.Close()
+
+	p := newTestOpenAI(t, srv.URL, Config{
+		Model:      "bge-m3",
+		AuthScheme: AuthHeader,
+		Headers:    map[string]string{"X-Team": "${QUOKKA_TEST_TEAM}"},
+	})
+	got, err := p.EmbedBatch(context.Background(), []string{"a", "bbb"})
+	if err != nil {
+		t.Fatalf("EmbedBatch: %v", err)
+	}
+	if got[0][0] != 1 || got[1][0] != 3 {
+		t.Errorf("embeddings out of order: %v", got)
+	}
+	if seen.Get("api-key") != fakeAPIKey || seen.Get("Authorization") != "" {
+		t.Errorf("header auth not applied: api-key=%q authorization=%q", seen.Get("api-key"), seen.Get("Authorization"))
+	}
+	if seen.Get("X-Team") != "appsec" {
+		t.Errorf("X-Team = %q, want env-expanded appsec", seen.Get("X-Team"))
+	}
+
+	// Auth scheme none needs no key at all
+	none, err := NewOpenAIProvider(&Config{Provider: "openai", Endpoint: srv.URL, APIKeyEnv: "QUOKKA_TEST_UNSET_KEY", AuthScheme: AuthNone})
+	if err != nil {
+		t.Fatalf("NewOpenAIProvider with auth none: %v", err)
+	}
+	if _, err := none.Embed(context.Background(), "x"); err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	if seen.Get("Authorization") != "" || seen.Get("api-key") != "" {
+		t.Errorf("auth none sent credentials: %v", seen)
+	}
+}
+
+func TestOpenAIConfigEndpointGetsNoSecrets(t *testing.T) {
+	t.Setenv("QUOKKA_TEST_EMBED_KEY", fakeAPIKey)
+	t.Setenv("QUOKKA_TEST_SECRET", "hunter2-hunter2")
+	t.Setenv(EndpointEnv, "")
+	t.Setenv(HeaderEnvEnv, "")
+	base := Config{Provider: "openai", Endpoint: "https://attacker.example", APIKeyEnv: "QUOKKA_TEST_EMBED_KEY"}
+
+	if _, err := NewOpenAIProvider(&base); err == nil || !strings.Contains(err.Error(), EndpointEnv) {
+		t.Errorf("key sent to an endpoint set only in project config: err=%v", err)
+	}
+	cfg := base
+	cfg.AuthScheme = AuthNone
+	if _, err := NewOpenAIProvider(&cfg); err != nil {
+		t.Errorf("auth none to a config endpoint: %v", err)
+	}
+	cfg.Headers = map[string]string{"X-Leak": "${QUOKKA_TEST_EMBED_KEY}"}
+	if _, err := NewOpenAIProvider(&cfg); err == nil {
+		t.Error("header expanded an env var for an endpoint set only in project config")
+	}
+
+	// Once the user names the endpoint the key goes there, but headers may
+	// still only reference the key variable and the allowlist
+	t.Setenv(EndpointEnv, "https://attacker.example")
+	cfg = base
+	cfg.Headers = map[string]string{"X-Key": "${QUOKKA_TEST_EMBED_KEY}"}
+	p, err := NewOpenAIProvider(&cfg)
+	if err != nil {
+		t.Fatalf("trusted endpoint: %v", err)
+	}
+	if p.headers["X-Key"] != fakeAPIKey {
+		t.Errorf("X-Key = %q, want the key", p.headers["X-Key"])
+	}
+	cfg.Headers = map[string]string{"X-Leak": "$QUOKKA_TEST_SECRET"}
+	if _, err := NewOpenAIProvider(&cfg); err == nil || !strings.Contains(err.Error(), HeaderEnvEnv) {
+		t.Errorf("header expanded a variable outside the allowlist: err=%v", err)
+	}
+	t.Setenv(HeaderEnvEnv, "OTHER, QUOKKA_TEST_SECRET")
+	if p, err := NewOpenAIProvider(&cfg); err != nil || p.headers["X-Leak"] != "hunter2-hunter2" {
+		t.Errorf("allowlisted header variable not expanded: err=%v", err)
+	}
+
+	// The user's endpoint replaces the one in the project config
+	t.Setenv(EndpointEnv, "http://vllm:8000")
+	if p, err := NewOpenAIProvider(&base); err != nil || p.endpoint != "http://vllm:8000/v1/embeddings" {
+		t.Errorf("endpoint override: err=%v", err)
+	}
+}
+
+func TestTooLargeRE(t *testing.T) {
+	for _, msg := range []string{
+		"This model's maximum context length is 8192 tokens",
+		"input batch size 8 exceeds maximum of 3",
+		"input is too large to process. increase the physical batch size",
+		"Too many inputs. The max number of inputs is 2048",
+		"Payload Too Large",
+	} {
+		if !tooLargeRE.MatchString(msg) {
+			t.Errorf("%q not recognised as too large", msg)
+		}
+	}
+	for _, msg := range []string{
+		"Invalid payload: 'model' is required",
+		"value exceeds allowed enum for encoding_format",
+		"'input' must be a string or array",
+	} {
+		if tooLargeRE.MatchString(msg) {
+			t.Errorf("%q recognised as too large", msg)
+		}
+	}
+}
+
+func TestOpenAIRetriesRateLimitsAndServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(fakeEmbeddingsHandler(t, func(r *http.Request, _ []string) (int, string) {
+		switch calls.Add(1) {
+		case 1:
+			return http.StatusTooManyRequests, `{"error":{"message":"Rate limit reached","type":"requests"}}`
+		case 2:
+			return http.StatusBadGateway, "upstream unavailable"
+		}
+		return 0, ""
+	}))
+	defer srv.Close()
+
+	p := newTestOpenAI(t, srv.URL, Config{})
+	if _, err := p.Embed(context.Background(), "hello"); err != nil {
+		t.Fatalf("Embed after retries: %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("calls = %d, want 3", calls.Load())
+	}
+
+	// Retries disabled: the first failure is returned
+	calls.Store(0)
+	p = newTestOpenAI(t, srv.URL, Config{MaxRetries: -1})
+	if _, err := p.Embed(context.Background(), "hello"); err == nil || calls.Load() != 1 {
+		t.Errorf("MaxRetries -1: err = %v, calls = %d", err, calls.Load())
+	}
+
+	// Client errors are not retried
+	var bad atomic.Int32
+	badSrv := httptest.NewServer(fakeEmbeddingsHandler(t, func(*http.Request, []string) (int, string) {
+		bad.Add(1)
+		return http.StatusUnauthorized, `{"error":{"message":"invalid key","type":"auth"}}`
+	}))
+	defer badSrv.Close()
+	p = newTestOpenAI(t, badSrv.URL, Config{})
+	if _, err := p.Embed(context.Background(), "hello"); err == nil || bad.Load() != 1 {
+		t.Errorf("401: err = %v, calls = %d", err, bad.Load())
+	}
+}
+
+func TestOpenAIBatchAutoTune(t *testing.T) {
+	var largest atomic.Int32
+	srv := httptest.NewServer(fakeEmbeddingsHandler(t, func(_ *http.Request, inputs []string) (int, string) {
+		if len(inputs) > 3 {
+			return http.StatusBadRequest, `{"error":{"message":"input batch size 8 exceeds maximum of 3","type":"invalid_request_error"}}`
+		}
+		if n := int32(len(inputs)); n > largest.Load() {
+			largest.Store(n)
+		}
+		return 0, ""
+	}))
+	defer srv.Close()
+
+	p := newTestOpenAI(t, srv.URL, Config{BatchSize: 8})
+	texts := []string{"a", "bb", "ccc", "dddd", "eeeee", "ffffff", "g"}
+	got, err := p.EmbedBatch(context.Background(), texts)
+	if err != nil {
+		t.Fatalf("EmbedBatch: %v", err)
+	}
+	for i, text := range texts {
+		if got[i][0] != float32(len(text)) {
+			t.Errorf("embedding %d = %v, want first element %d", i, got[i], len(text))
+		}
+	}
+	if p.BatchSize() != 3 || largest.Load() != 3 {
+		t.Errorf("batch size = %d (largest sent %d), want 3 (7 texts halved)", p.BatchSize(), largest.Load())
+	}
+
+	// A single input that is still too large is an error, not a loop
+	one := newTestOpenAI(t, srv.URL, Config{BatchSize: 1})
+	srvTooLarge := httptest.NewServer(fakeEmbeddingsHandler(t, func(*http.Request, []string) (int, string) {
+		return http.StatusRequestEntityTooLarge, "payload too large"
+	}))
+	defer srvTooLarge.Close()
+	one.endpoint = srvTooLarge.URL + "/v1/embeddings"
+	if _, err := one.Embed(context.Background(), "x"); !errors.Is(err, errBatchTooLarge) {
+		t.Errorf("err = %v, want errBatchTooLarge", err)
+	}
+}
+
+func TestRateLimiterSpacesRequests(t *testing.T) {
+	l := newRateLimiter(6000) // one request per 10ms
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("4 requests at 6000 rpm took %v, want >= 30ms", elapsed)
+	}
+	if newRateLimiter(0) != nil {
+		t.Error("rpm 0 should disable the limiter")
+	}
+	var none *rateLimiter
+	if err := none.Wait(context.Background()); err != nil {
+		t.Errorf("nil limiter Wait: %v", err)
+	}
+}
+
+func TestRetryDelayHonoursRetryAfter(t *testing.T) {
+	h := http.Header{"Retry-After": []string{"2"}}
+	if d := retryDelay(0, time.Millisecond, h); d != 2*time.Second {
+		t.Errorf("Retry-After 2: delay = %v", d)
+	}
+	if d := retryDelay(3, 10*time.Millisecond, nil); d < 80*time.Millisecond || d > 100*time.Millisecond {
+		t.Errorf("attempt 3 backoff = %v, want 80-100ms", d)
+	}
+	if d := retryDelay(40, time.Second, nil); d > maxRetryBackoff*5/4 {
+		t.Errorf("backoff not capped: %v", d)
+	}
+}
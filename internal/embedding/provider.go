@@ -24,7 +24,7 @@ type Provider interface {
 
 // Config contains configuration for embedding providers
 type Config struct {
-	// Provider is the provider name: "ollama", "openai", "huggingface"
+	// Provider is the provider name: "ollama", "openai", "huggingface", "local"
 	Provider string
 	// Model is the model name
 	Model string
@@ -36,6 +36,18 @@ type Config struct {
 	Dimension int
 	// BatchSize is the maximum batch size for batch operations
 	BatchSize int
+	// Headers are extra HTTP headers; values may reference the API key
+	// variable and ${ENV_VARS} listed in $QUOKKA_EMBEDDING_HEADER_ENV
+	Headers map[string]string
+	// AuthScheme is how the API key is sent: "bearer" (default), "header"
+	// (raw key in AuthHeader) or "none"
+	AuthScheme string
+	// AuthHeader is the key header for the "header" scheme (default api-key)
+	AuthHeader string
+	// MaxRetries bounds retries of 429/5xx responses (0 = default, -1 = off)
+	MaxRetries int
+	// RequestsPerMinute limits the request rate client-side (0 = unlimited)
+	RequestsPerMinute int
 }
 
 // DefaultConfigs contains default configurations for each provider
@@ -62,6 +74,12 @@ var DefaultConfigs = map[string]*Config{
 		Dimension: 384,
 		BatchSize: 64, // HuggingFace Inference API handles large batches well
 	},
+	"local": {
+		Provider:  "local",
+		Model:     LocalModel,
+		Dimension: localDefaultDimension,
+		BatchSize: 256, // In-process, no request overhead
+	},
 }
 
 // NewProvider creates a new embedding provider based on the config
@@ -73,6 +91,8 @@ func NewProvider(config *Config) (Provider, error) {
 		return NewOpenAIProvider(config)
 	case "huggingface":
 		return NewHuggingFaceProvider(config)
+	case "local":
+		return NewLocalProvider(config)
 	default:
 		return nil, fmt.Errorf("unknown provider: %s", config.Provider)
 	}
@@ -116,7 +136,7 @@ func redactSecret(s, secret string) string {
 
 // AvailableProviders returns a list of available providers
 func AvailableProviders() []string {
-	return []string{"ollama", "openai", "huggingface"}
+	return []string{"ollama", "openai", "huggingface", "local"}
 }
 
 // ValidateConfig validates a provider configuration
@@ -137,8 +157,13 @@ func ValidateConfig(config *Config) error {
 		if config.APIKeyEnv == "" {
 			config.APIKeyEnv = DefaultConfigs["openai"].APIKeyEnv
 		}
-		if _, err := GetAPIKey(config.APIKeyEnv); err != nil {
-			return fmt.Errorf("OpenAI API key not configured: %w", err)
+		if !strings.EqualFold(config.AuthScheme, AuthNone) {
+			if _, err := GetAPIKey(config.APIKeyEnv); err != nil {
+				return fmt.Errorf("OpenAI API key not configured: %w", err)
+			}
+		}
+		if _, err := openAIEndpointURL(config.Endpoint); err != nil {
+			return err
 		}
 		if config.Model == "" {
 			config.Model = DefaultConfigs["openai"].Model
@@ -153,6 +178,13 @@ func ValidateConfig(config *Config) error {
 		if config.Model == "" {
 			config.Model = DefaultConfigs["huggingface"].Model
 		}
+	case "local":
+		if config.Model == "" {
+			config.Model = DefaultConfigs["local"].Model
+		}
+		if config.Model != LocalModel {
+			return fmt.Errorf("unknown local model %q (available: %s)", config.Model, LocalModel)
+		}
 	default:
 		return fmt.Errorf("unknown provider: %s", config.Provider)
 	}
@@ -0,0 +1,100 @@
+package embedding
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultMaxRetries is how often a 429/5xx or network failure is retried
+	defaultMaxRetries = 4
+	// defaultRetryBackoff is the first retry delay; it doubles per attempt
+	defaultRetryBackoff = 500 * time.Millisecond
+	// maxRetryBackoff caps a single retry delay, including Retry-After
+	maxRetryBackoff = 30 * time.Second
+)
+
+// retryableStatus reports whether an HTTP status is worth retrying
+func retryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryDelay returns the wait before retry attempt (0-based): the server's
+// Retry-After when given, else exponential backoff with up to 25% jitter
+func retryDelay(attempt int, base time.Duration, header http.Header) time.Duration {
+	if header != nil {
+		if v := header.Get("Retry-After"); v != "" {
+			if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+				return min(time.Duration(secs)*time.Second, maxRetryBackoff)
+			}
+			if t, err := http.ParseTime(v); err == nil {
+				return min(max(time.Until(t), 0), maxRetryBackoff)
+			}
+		}
+	}
+	if base <= 0 {
+		base = defaultRetryBackoff
+	}
+	d := base << min(attempt, 16)
+	if d <= 0 || d > maxRetryBackoff {
+		d = maxRetryBackoff
+	}
+	return d + time.Duration(rand.Int64N(int64(d)/4+1))
+}
+
+// sleepContext waits for d or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// rateLimiter spaces requests evenly to stay under a requests-per-minute
+// budget shared by all goroutines using the provider
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// newRateLimiter returns a limiter for rpm requests per minute, or nil
+// (no limit) when rpm is not positive
+func newRateLimiter(rpm int) *rateLimiter {
+	if rpm <= 0 {
+		return nil
+	}
+	return &rateLimiter{interval: time.Minute / time.Duration(rpm)}
+}
+
+// Wait blocks until the caller may send a request. A nil limiter never waits.
+func (l *rateLimiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return ctx.Err()
+	}
+	l.mu.Lock()
+	now := time.Now()
+	slot := l.next
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next = slot.Add(l.interval)
+	l.mu.Unlock()
+	return sleepContext(ctx, time.Until(slot))
+}
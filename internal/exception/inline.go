@@ -0,0 +1,266 @@
+package exception
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/diffsec/quokka/internal/treesitter"
+)
+
+// inlineMarker starts an inline suppression comment:
+//
+//	// quokka:ignore CWE-89 reason="parameterised upstream" expires=2027-01-01 approved-by=alice
+//
+// A comment on its own line applies to the next line of code; a trailing
+// comment applies to its own line. Without fingerprint= the exception is
+// scoped to that location and the CWE is required, mirroring path-glob
+// exceptions.
+const inlineMarker = "quokka:ignore"
+
+// inlineSkipDirs are never walked by ScanInline. Hidden directories are
+// skipped as well.
+var inlineSkipDirs = map[string]bool{"node_modules": true, "vendor": true}
+
+// Annotation is one quokka:ignore comment found in source.
+type Annotation struct {
+	File string `json:"file"`
+	// Line is the comment's line; Target the line of code it suppresses.
+	Line   int    `json:"line"`
+	Target int    `json:"target"`
+	Text   string `json:"text"`
+	// Exception is the suppression the comment declares. Nil when the
+	// comment is malformed, in which case Error says why.
+	Exception *Exception `json:"exception,omitempty"`
+	Error     string     `json:"error,omitempty"`
+}
+
+// Problem returns why the annotation suppresses nothing — malformed,
+// expired, or approved by an agent when allowAgent (the project's
+// allow_agent_writes.exceptions) is off — or "" when it is in effect.
+func (a Annotation) Problem(now time.Time, allowAgent bool) string {
+	if a.Exception == nil {
+		return "malformed: " + a.Error
+	}
+	if a.Exception.IsExpired(now) {
+		return "expired " + a.Exception.Expires.Format("2006-01-02")
+	}
+	if !allowAgent && a.Exception.ApprovedByAgent() {
+		return "approved by " + a.Exception.ApprovedBy + " but allow_agent_writes.exceptions is off"
+	}
+	return ""
+}
+
+// ParseExpires accepts YYYY-MM-DD or full RFC3339. Anchors the date at
+// 23:59:59 UTC so "expires: 2026-09-01" means "good through 2026-09-01."
+func ParseExpires(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t.Add(24*time.Hour - time.Second), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("expected YYYY-MM-DD or RFC3339, got %q", s)
+}
+
+// ParseInline parses the text of a quokka:ignore comment into the exception
+// it declares, scoped to file:line unless it names a fingerprint. The
+// result is checked with Validate. ok is false when the comment isn't a
+// quokka:ignore comment at all.
+func ParseInline(comment, file string, line int) (e Exception, ok bool, err error) {
+	body, ok := inlineBody(comment)
+	if !ok {
+		return Exception{}, false, nil
+	}
+	e = Exception{ID: fmt.Sprintf("inline:%s:%d", file, line)}
+	tokens, err := splitInline(body)
+	if err != nil {
+		return Exception{}, true, err
+	}
+	for _, tok := range tokens {
+		key, value, hasValue := strings.Cut(tok, "=")
+		if !hasValue {
+			if cwe := strings.ToUpper(tok); strings.HasPrefix(cwe, "CWE-") && e.CWE == "" {
+				e.CWE = cwe
+				continue
+			}
+			return Exception{}, true, fmt.Errorf("unexpected %q (want a CWE id or key=value)", tok)
+		}
+		switch strings.ToLower(key) {
+		case "reason":
+			e.Reason = value
+		case "expires":
+			if e.Expires, err = ParseExpires(value); err != nil {
+				return Exception{}, true, fmt.Errorf("expires: %w", err)
+			}
+		case "approved-by", "approved_by":
+			e.ApprovedBy = value
+		case "approved-for", "approved_for":
+			e.ApprovedFor = value
+		case "fingerprint":
+			e.Fingerprint = value
+		case "cwe":
+			e.CWE = strings.ToUpper(value)
+		default:
+			return Exception{}, true, fmt.Errorf("unknown key %q", key)
+		}
+	}
+	if !e.IsFingerprint() {
+		e.File = filepath.ToSlash(file)
+		e.Line = line
+	}
+	if err := e.Validate(); err != nil {
+		return Exception{}, true, err
+	}
+	return e, true, nil
+}
+
+// inlineBody strips the comment delimiters and returns what follows the
+// marker. The marker must open the comment, so prose that merely mentions
+// it is not an annotation.
+func inlineBody(comment string) (string, bool) {
+	s := strings.TrimSpace(comment)
+	for _, leader := range []string{"//", "/*", "#", "--", "<!--"} {
+		if strings.HasPrefix(s, leader) {
+			s = strings.TrimSpace(strings.TrimLeft(strings.TrimPrefix(s, leader), "/*!"))
+			break
+		}
+	}
+	s = strings.TrimSpace(strings.TrimSuffix(strings.TrimSuffix(s, "*/"), "-->"))
+	rest, ok := strings.CutPrefix(s, inlineMarker)
+	if !ok || (rest != "" && rest[0] != ' ' && rest[0] != '\t') {
+		return "", false
+	}
+	return strings.TrimSpace(rest), true
+}
+
+// splitInline splits on whitespace, keeping double-quoted values (which may
+// contain \" escapes) together and unquoted.
+func splitInline(s string) ([]string, error) {
+	var out []string
+	var cur strings.Builder
+	inQuote, started := false, false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case inQuote && c == '\\' && i+1 < len(s):
+			i++
+			cur.WriteByte(s[i])
+		case c == '"':
+			inQuote = !inQuote
+			started = true
+		case !inQuote && (c == ' ' || c == '\t'):
+			if started {
+				out = append(out, cur.String())
+				cur.Reset()
+				started = false
+			}
+		default:
+			cur.WriteByte(c)
+			started = true
+		}
+	}
+	if inQuote {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if started {
+		out = append(out, cur.String())
+	}
+	return out, nil
+}
+
+// ParseInlineFile returns the quokka:ignore annotations in one file. file
+// is the path recorded on the annotations (relative to the project root);
+// files without the marker are not parsed at all.
+func ParseInlineFile(parser *treesitter.Parser, source []byte, file string) ([]Annotation, error) {
+	if !bytes.Contains(source, []byte(inlineMarker)) {
+		return nil, nil
+	}
+	comments, err := parser.ExtractComments(source, file)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lines that hold only a comment are skipped when looking for the code
+	// an own-line annotation applies to.
+	commentOnly := map[int]bool{}
+	for _, c := range comments {
+		if !c.Trailing {
+			for l := c.Line; l <= c.EndLine; l++ {
+				commentOnly[l] = true
+			}
+		}
+	}
+	lines := strings.Split(string(source), "\n")
+
+	var out []Annotation
+	for _, c := range comments {
+		target := c.Line
+		if !c.Trailing {
+			target = c.EndLine + 1
+			for target <= len(lines) && (commentOnly[target] || strings.TrimSpace(lines[target-1]) == "") {
+				target++
+			}
+		}
+		e, ok, err := ParseInline(c.Text, file, target)
+		if !ok {
+			continue
+		}
+		a := Annotation{File: filepath.ToSlash(file), Line: c.Line, Target: target, Text: strings.TrimSpace(c.Text)}
+		if err != nil {
+			a.Error = err.Error()
+		} else {
+			a.Exception = &e
+		}
+		out = append(out, a)
+	}
+	return out, nil
+}
+
+// ScanInline walks the project for quokka:ignore annotations in every
+// language tree-sitter supports. Files that fail to parse are skipped.
+func ScanInline(root string) ([]Annotation, error) {
+	parser := treesitter.NewParser()
+	var out []Annotation
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if path != root && (strings.HasPrefix(d.Name(), ".") || inlineSkipDirs[d.Name()]) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if treesitter.DetectLanguageName(path) == "" {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil || info.Size() > treesitter.DefaultMaxFileSize {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil
+		}
+		source, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		found, err := ParseInlineFile(parser, source, filepath.ToSlash(rel))
+		if err != nil {
+			return nil
+		}
+		out = append(out, found...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scan inline exceptions: %w", err)
+	}
+	return out, nil
+}
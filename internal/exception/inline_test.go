@@ -0,0 +1,180 @@
+package exception
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/diffsec/quokka/internal/finding"
+	"github.com/diffsec/quokka/internal/treesitter"
+)
+
+func writeSource(t *testing.T, root, rel, content string) {
+	t.Helper()
+	path := filepath.Join(root, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseInline(t *testing.T) {
+	e, ok, err := ParseInline(`// quokka:ignore CWE-89 reason="parameterised upstream" expires=2027-01-01 approved-by=alice`, "db/q.go", 12)
+	if !ok || err != nil {
+		t.Fatalf("ok=%v err=%v", ok, err)
+	}
+	if e.CWE != "CWE-89" || e.Reason != "parameterised upstream" || e.ApprovedBy != "alice" {
+		t.Errorf("parsed = %+v", e)
+	}
+	if !e.IsLocation() || e.File != "db/q.go" || e.Line != 12 || e.ID != "inline:db/q.go:12" {
+		t.Errorf("scope = %+v", e)
+	}
+	if e.Expires.Format("2006-01-02 15:04:05") != "2027-01-01 23:59:59" {
+		t.Errorf("expires = %v", e.Expires)
+	}
+
+	e, _, err = ParseInline(`# quokka:ignore fingerprint=abc123 reason=fixture expires=2027-01-01 approved-by=bob`, "a.py", 3)
+	if err != nil || !e.IsFingerprint() || e.IsLocation() {
+		t.Errorf("fingerprint scope: %+v, err %v", e, err)
+	}
+
+	for _, notOurs := range []string{"// TODO: quokka:ignore someday", "// quokka:ignored", "/* plain */"} {
+		if _, ok, _ := ParseInline(notOurs, "a.go", 1); ok {
+			t.Errorf("%q parsed as an annotation", notOurs)
+		}
+	}
+
+	malformed := map[string]string{
+		`// quokka:ignore reason=x expires=2027-01-01 approved-by=a`:                 "cwe is required",
+		`// quokka:ignore CWE-89 expires=2027-01-01 approved-by=a`:                   "reason is required",
+		`// quokka:ignore CWE-89 reason=x approved-by=a`:                             "expires is required",
+		`// quokka:ignore CWE-89 reason=x expires=2027-01-01`:                        "approved_by is required",
+		`// quokka:ignore CWE-89 reason=x expires=soon approved-by=a`:                "expires:",
+		`// quokka:ignore CWE-89 reason=x expires=2027-01-01 approved-by=a owner=me`: "unknown key",
+		`// quokka:ignore CWE-89 reason="x expires=2027-01-01 approved-by=a`:         "unterminated quote",
+	}
+	for comment, want := range malformed {
+		if _, ok, err := ParseInline(comment, "a.go", 1); !ok || err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: ok=%v err=%v, want %q", comment, ok, err, want)
+		}
+	}
+}
+
+func TestParseInlineFile_TargetLines(t *testing.T) {
+	source := `package db
+
+func q(id string) {
+	// quokka:ignore CWE-89 reason="own line" expires=2027-01-01 approved-by=a
+	// (explanatory note)
+
+	db.Exec("SELECT " + id)
+	db.Exec("DELETE " + id) // quokka:ignore CWE-89 reason=trailing expires=2027-01-01 approved-by=a
+	s := "// quokka:ignore CWE-89 reason=string expires=2027-01-01 approved-by=a"
+}
+`
+	got, err := ParseInlineFile(treesitter.NewParser(), []byte(source), "db/q.go")
+	if err != nil {
+		t.Fatalf("ParseInlineFile: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d annotations, want 2: %+v", len(got), got)
+	}
+	if got[0].Line != 4 || got[0].Target != 7 || got[0].Exception.Line != 7 {
+		t.Errorf("own-line annotation = %+v", got[0])
+	}
+	if got[1].Line != 8 || got[1].Target != 8 {
+		t.Errorf("trailing annotation = %+v", got[1])
+	}
+}
+
+func TestStore_MatchInline(t *testing.T) {
+	s, cleanup := newTestStore(t)
+	defer cleanup()
+	writeSource(t, s.root, "app/views.py", `def view(request):
+    # quokka:ignore CWE-89 reason="ids are ints" expires=2099-01-01 approved-by=alice
+    cursor.execute("SELECT %s" % request.id)
+    cursor.execute("DELETE %s" % request.id)  # quokka:ignore CWE-89 reason=old expires=2020-01-01 approved-by=alice
+`)
+
+	at := func(line int, cwe string) finding.Finding {
+		return finding.Finding{CWE: cwe, Location: finding.Location{File: "app/views.py", LineStart: line, LineEnd: line}}
+	}
+	got, err := s.Match(at(3, "cwe-89"))
+	if err != nil || got == nil || got.ID != "inline:app/views.py:3" {
+		t.Fatalf("Match line 3 = %+v, %v", got, err)
+	}
+	for _, f := range []finding.Finding{at(3, "CWE-78"), at(4, "CWE-89"), at(5, "CWE-89")} {
+		if got, _ := s.Match(f); got != nil {
+			t.Errorf("unexpected match for %s line %d: %s", f.CWE, f.Location.LineStart, got.ID)
+		}
+	}
+
+	findings := []finding.Finding{at(3, "CWE-89"), at(4, "CWE-89")}
+	n, err := s.Annotate(findings)
+	if err != nil || n != 1 || findings[0].Suppression == nil || findings[0].Suppression.Reason != "ids are ints" || findings[1].Suppression != nil {
+		t.Errorf("Annotate: n=%d err=%v findings=%+v", n, err, findings)
+	}
+}
+
+func TestStore_InlineAgentApprovalsAndEdits(t *testing.T) {
+	s, cleanup := newTestStore(t)
+	defer cleanup()
+	f := finding.Finding{CWE: "CWE-78", Location: finding.Location{File: "app/run.py", LineStart: 2, LineEnd: 2}}
+	writeSource(t, s.root, "app/run.py", "# quokka:ignore CWE-78 reason=ok expires=2099-01-01 approved-by=agent:triage\nos.system(cmd)\n")
+	if got, _ := s.Match(f); got != nil {
+		t.Errorf("agent approval honoured without allow_agent_writes.exceptions: %s", got.ID)
+	}
+
+	writeSource(t, s.root, "app/run.py", "# quokka:ignore CWE-78 reason=ok expires=2099-01-01 approved-by=alice\nos.system(cmd)\n")
+	if got, _ := s.Match(f); got == nil {
+		t.Error("same store missed an annotation added after its first read")
+	}
+
+	s.allowAgent = true
+	s.inline = nil
+	writeSource(t, s.root, "app/run.py", "# quokka:ignore CWE-78 reason=ok expires=2099-01-01 approved-by=agent:triage\nos.system(cmd)\n")
+	if got, _ := s.Match(f); got == nil {
+		t.Error("agent approval ignored with allow_agent_writes.exceptions on")
+	}
+}
+
+func TestScanInline_ReportsProblems(t *testing.T) {
+	root := t.TempDir()
+	writeSource(t, root, "a.js", "// quokka:ignore CWE-79 reason=ok expires=2099-01-01 approved-by=a\nel.innerHTML = x\n")
+	writeSource(t, root, "b.rb", "system(cmd) # quokka:ignore CWE-78 reason=old expires=2020-01-01 approved-by=a\n")
+	writeSource(t, root, "c.rs", "/* quokka:ignore CWE-22 expires=2099-01-01 approved-by=a */\nopen(p);\n")
+	writeSource(t, root, "e.py", "# quokka:ignore CWE-78 reason=ok expires=2099-01-01 approved-by=agent:triage\nos.system(cmd)\n")
+	writeSource(t, root, "node_modules/x/d.js", "// quokka:ignore garbage\n")
+	writeSource(t, root, "notes.txt", "// quokka:ignore garbage\n")
+
+	got, err := ScanInline(root)
+	if err != nil {
+		t.Fatalf("ScanInline: %v", err)
+	}
+	if len(got) != 4 {
+		t.Fatalf("got %d annotations, want 4: %+v", len(got), got)
+	}
+	problems := map[string]string{}
+	for _, a := range got {
+		problems[a.File] = a.Problem(time.Now(), false)
+		if a.File == "e.py" && a.Problem(time.Now(), true) != "" {
+			t.Errorf("agent approval rejected with agent writes allowed: %q", a.Problem(time.Now(), true))
+		}
+	}
+	if !strings.Contains(problems["e.py"], "allow_agent_writes.exceptions is off") {
+		t.Errorf("e.py problem = %q", problems["e.py"])
+	}
+	if problems["a.js"] != "" {
+		t.Errorf("a.js problem = %q", problems["a.js"])
+	}
+	if problems["b.rb"] != "expired 2020-01-01" {
+		t.Errorf("b.rb problem = %q", problems["b.rb"])
+	}
+	if !strings.Contains(problems["c.rs"], "malformed: reason is required") {
+		t.Errorf("c.rs problem = %q", problems["c.rs"])
+	}
+}
@@ -7,10 +7,12 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/diffsec/quokka/internal/finding"
 	"github.com/diffsec/quokka/internal/project"
+	"github.com/diffsec/quokka/internal/treesitter"
 	"gopkg.in/yaml.v3"
 )
 
@@ -22,13 +24,28 @@ const fileName = "exceptions.yaml"
 // reads, mutates in memory, and writes the full file back. For quokka's
 // expected scale (dozens, maybe hundreds of exceptions) this is fine and
 // keeps the API simple.
+//
+// Match and Annotate also honour quokka:ignore annotations in the source
+// files of the findings they check (see inline.go). Each file is parsed
+// once per Store and again only when it changes on disk. Annotations
+// approved by an agent count only when the project allows agent-written
+// exceptions (allow_agent_writes.exceptions), as for quokka exception add.
 type Store struct {
 	path string
+	root string
+
+	allowAgent bool
+	mu         sync.Mutex
+	inline     *inlineCache
 }
 
 // NewStore creates a new exception store rooted at the given project.
 func NewStore(p *project.Project) *Store {
-	return &Store{path: filepath.Join(p.GetQuokkaPath(), fileName)}
+	s := &Store{path: filepath.Join(p.GetQuokkaPath(), fileName), root: p.RootPath}
+	if p.Config != nil {
+		s.allowAgent = p.Config.AllowAgentWrites.Exceptions
+	}
+	return s
 }
 
 // fileShape is the on-disk YAML layout. Wrapping the list in a struct keeps
@@ -162,6 +179,7 @@ func (s *Store) Match(f finding.Finding) (*Exception, error) {
 	if err != nil {
 		return nil, err
 	}
+	all = append(all, s.inlineFor(f.Location.File)...)
 	for i := range all {
 		if matches(all[i], f) {
 			return &all[i], nil
@@ -170,6 +188,34 @@ func (s *Store) Match(f finding.Finding) (*Exception, error) {
 	return nil, nil
 }
 
+// Annotate sets Suppression on every finding matched by a non-expired
+// exception and clears it on the rest. The exceptions file is read once,
+// so prefer this over calling Match in a loop. Returns how many findings
+// are suppressed.
+func (s *Store) Annotate(findings []finding.Finding) (int, error) {
+	var all []Exception
+	if s != nil {
+		var err error
+		if all, err = s.List(false); err != nil {
+			return 0, err
+		}
+	}
+	suppressed := 0
+	for i := range findings {
+		findings[i].Suppression = nil
+		candidates := append(all[:len(all):len(all)], s.inlineFor(findings[i].Location.File)...)
+		for _, e := range candidates {
+			if matches(e, findings[i]) {
+				sup := e.Suppression()
+				findings[i].Suppression = &sup
+				suppressed++
+				break
+			}
+		}
+	}
+	return suppressed, nil
+}
+
 func matches(e Exception, f finding.Finding) bool {
 	if e.IsFingerprint() {
 		return e.Fingerprint != "" && e.Fingerprint == f.Fingerprint
@@ -189,9 +235,74 @@ func matches(e Exception, f finding.Finding) bool {
 			return true
 		}
 	}
+	if e.IsLocation() {
+		if !strings.EqualFold(e.CWE, f.CWE) || filepath.ToSlash(filepath.Clean(e.File)) != filepath.ToSlash(filepath.Clean(f.Location.File)) {
+			return false
+		}
+		end := max(f.Location.LineEnd, f.Location.LineStart)
+		return f.Location.LineStart <= e.Line && e.Line <= end
+	}
 	return false
 }
 
+// inlineCache holds the inline exceptions of each source file a Store has
+// checked, with the file's size and modification time when it was read.
+type inlineCache struct {
+	parser *treesitter.Parser
+	files  map[string]inlineFile
+}
+
+type inlineFile struct {
+	size       int64
+	modTime    time.Time
+	exceptions []Exception
+}
+
+// inlineFor returns the inline exceptions in effect in file (relative to
+// the project root): valid, unexpired and, unless the project allows agent
+// writes, approved by a human. Unreadable or unparseable files declare
+// none; quokka exception scan is where broken annotations surface. Only
+// successful parses are cached, so a failed read is retried next time.
+func (s *Store) inlineFor(file string) []Exception {
+	if s == nil || s.root == "" || file == "" || treesitter.DetectLanguageName(file) == "" {
+		return nil
+	}
+	path := file
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(s.root, file)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.inline == nil {
+		s.inline = &inlineCache{parser: treesitter.NewParser(), files: map[string]inlineFile{}}
+	}
+	if cached, ok := s.inline.files[file]; ok && cached.size == info.Size() && cached.modTime.Equal(info.ModTime()) {
+		return cached.exceptions
+	}
+	entry := inlineFile{size: info.Size(), modTime: info.ModTime()}
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	annotations, err := ParseInlineFile(s.inline.parser, source, file)
+	if err != nil {
+		return nil
+	}
+	now := time.Now()
+	for _, a := range annotations {
+		if a.Problem(now, s.allowAgent) == "" {
+			entry.exceptions = append(entry.exceptions, *a.Exception)
+		}
+	}
+	s.inline.files[file] = entry
+	return entry.exceptions
+}
+
 // save writes the full list back to disk via tempfile + rename (atomic).
 func (s *Store) save(all []Exception) error {
 	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
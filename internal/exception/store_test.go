@@ -256,6 +256,46 @@ func TestStore_NilStoreMatchesNothing(t *testing.T) {
 	}
 }
 
+func TestStore_Annotate(t *testing.T) {
+	s, cleanup := newTestStore(t)
+	defer cleanup()
+	exp := futureDate()
+	saved, _ := s.Add(Exception{Fingerprint: "fp-1", Reason: "fixture", Expires: exp, ApprovedBy: "human:lead"})
+	_, _ = s.Add(Exception{Fingerprint: "fp-2", Reason: "old", Expires: pastDate(), ApprovedBy: "h"})
+
+	findings := []finding.Finding{
+		{ID: "FIND-001", Fingerprint: "fp-1"},
+		{ID: "FIND-002", Fingerprint: "fp-2"},
+		// A stale annotation from an earlier pass must be cleared.
+		{ID: "FIND-003", Fingerprint: "fp-3", Suppression: &finding.Suppression{ExceptionID: "EXC-999"}},
+	}
+	n, err := s.Annotate(findings)
+	if err != nil {
+		t.Fatalf("Annotate: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("suppressed = %d, want 1", n)
+	}
+	sup := findings[0].Suppression
+	if sup == nil {
+		t.Fatal("FIND-001 should be annotated")
+	}
+	if sup.ExceptionID != saved.ID || sup.Reason != "fixture" || sup.ApprovedBy != "human:lead" || !sup.Expires.Equal(exp) {
+		t.Errorf("unexpected suppression: %+v", sup)
+	}
+	if findings[1].Suppression != nil {
+		t.Error("expired exception should not annotate FIND-002")
+	}
+	if findings[2].Suppression != nil {
+		t.Error("stale annotation on FIND-003 should be cleared")
+	}
+
+	var nilStore *Store
+	if n, err := nilStore.Annotate(findings); err != nil || n != 0 || findings[0].Suppression != nil {
+		t.Errorf("nil store: n=%d err=%v, want no suppressions", n, err)
+	}
+}
+
 func TestStore_AutoIncrementID(t *testing.T) {
 	s, cleanup := newTestStore(t)
 	defer cleanup()
@@ -1,6 +1,7 @@
 // Package exception manages persistent suppressions for findings. Exceptions
-// come in two shapes: per-finding (keyed by fingerprint) and pattern-based
-// (path glob + CWE). Both require a reason, an expires date, and an
+// come in three shapes: per-finding (keyed by fingerprint), pattern-based
+// (path glob + CWE) and location-based (file + line + CWE, declared inline
+// with a quokka:ignore comment). All require a reason, an expires date, and an
 // approver — the goal is to make suppressions auditable and time-bounded
 // rather than write-and-forget like older static-analysis ignore lists.
 package exception
@@ -9,15 +10,21 @@ import (
 	"fmt"
 	"strings"
 	"time"
+
+	"github.com/diffsec/quokka/internal/finding"
 )
 
 // Exception is one entry in .quokka/exceptions.yaml. It can be keyed by
-// Fingerprint XOR (PathGlob + CWE). The store enforces this exclusivity at
-// write time so callers don't need to revalidate it.
+// exactly one of Fingerprint, (PathGlob + CWE) or (File + Line + CWE). The
+// store enforces this exclusivity at write time so callers don't need to
+// revalidate it. Location-scoped exceptions come from inline annotations
+// (see ParseInline) rather than the file.
 type Exception struct {
 	ID          string    `yaml:"id" json:"id"`
 	Fingerprint string    `yaml:"fingerprint,omitempty" json:"fingerprint,omitempty"`
 	PathGlob    string    `yaml:"path_glob,omitempty" json:"path_glob,omitempty"`
+	File        string    `yaml:"file,omitempty" json:"file,omitempty"`
+	Line        int       `yaml:"line,omitempty" json:"line,omitempty"`
 	CWE         string    `yaml:"cwe,omitempty" json:"cwe,omitempty"`
 	Reason      string    `yaml:"reason" json:"reason"`
 	Expires     time.Time `yaml:"expires" json:"expires"`
@@ -37,6 +44,11 @@ func (e Exception) IsPattern() bool {
 	return strings.TrimSpace(e.PathGlob) != ""
 }
 
+// IsLocation reports whether this exception targets a file + line + CWE.
+func (e Exception) IsLocation() bool {
+	return strings.TrimSpace(e.File) != "" || e.Line > 0
+}
+
 // IsExpired reports whether the exception is past its expires date as of
 // the given moment (typically time.Now). Exceptions with a zero expires
 // time are treated as expired — the field is mandatory.
@@ -47,8 +59,26 @@ func (e Exception) IsExpired(now time.Time) bool {
 	return now.After(e.Expires)
 }
 
+// ApprovedByAgent reports whether an agent ("agent:<name>") approved the
+// exception rather than a human.
+func (e Exception) ApprovedByAgent() bool {
+	return strings.HasPrefix(strings.ToLower(strings.TrimSpace(e.ApprovedBy)), "agent:")
+}
+
+// Suppression returns the finding-side view of this exception, as attached
+// to suppressed findings for listing and export.
+func (e Exception) Suppression() finding.Suppression {
+	return finding.Suppression{
+		ExceptionID: e.ID,
+		Reason:      e.Reason,
+		ApprovedBy:  e.ApprovedBy,
+		Expires:     e.Expires,
+	}
+}
+
 // Validate checks that the exception has the required fields and the
-// fingerprint/pattern XOR constraint. Returns the first error encountered.
+// fingerprint/pattern/location exclusivity. Returns the first error
+// encountered.
 func (e Exception) Validate() error {
 	if strings.TrimSpace(e.Reason) == "" {
 		return fmt.Errorf("reason is required")
@@ -61,14 +91,26 @@ func (e Exception) Validate() error {
 	}
 	hasFP := e.IsFingerprint()
 	hasPat := e.IsPattern()
+	hasLoc := e.IsLocation()
 	if hasFP && hasPat {
 		return fmt.Errorf("exception cannot set both fingerprint and path_glob")
 	}
-	if !hasFP && !hasPat {
+	if hasLoc && (hasFP || hasPat) {
+		return fmt.Errorf("exception cannot combine file/line with fingerprint or path_glob")
+	}
+	if !hasFP && !hasPat && !hasLoc {
 		return fmt.Errorf("exception must set either fingerprint or path_glob")
 	}
 	if hasPat && strings.TrimSpace(e.CWE) == "" {
 		return fmt.Errorf("cwe is required for path-glob exceptions (suppressions are scoped to a vulnerability class)")
 	}
+	if hasLoc {
+		if strings.TrimSpace(e.File) == "" || e.Line < 1 {
+			return fmt.Errorf("location exceptions need both file and line")
+		}
+		if strings.TrimSpace(e.CWE) == "" {
+			return fmt.Errorf("cwe is required for location exceptions (suppressions are scoped to a vulnerability class)")
+		}
+	}
 	return nil
 }
@@ -0,0 +1,191 @@
+package finding
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/diffsec/quokka/internal/project"
+)
+
+// DefaultBackend is the backend used when project.yaml does not name one:
+// one YAML file per finding under findings/raw.
+const DefaultBackend = "yaml"
+
+// ErrNotFound is wrapped by backend errors for a missing finding ID.
+var ErrNotFound = errors.New("not found")
+
+// ErrConflict is wrapped by UpdateBy errors when the stored finding changed
+// after the caller read the copy it is writing back.
+var ErrConflict = errors.New("was changed by another writer")
+
+// Backend persists findings. Store layers validation, fingerprinting and
+// timestamps on top, so a backend only has to store what it is given.
+type Backend interface {
+	// Name returns the backend name as used in project.yaml
+	Name() string
+	// Get returns the finding with the given ID; the error wraps ErrNotFound
+	// when there is none.
+	Get(id string) (*Finding, error)
+	// Insert stores a new finding, allocating the next FIND-NNN ID when
+	// f.ID is empty. When f.CreatedBy is set and a finding with the same
+	// fingerprint and creator is already stored, nothing is written and
+	// that finding is returned instead. The dedup check, ID allocation and
+	// write must be atomic with respect to other writers.
+	Insert(f *Finding) (existing *Finding, err error)
+	// Put writes f as-is, replacing any stored finding with the same ID
+	Put(f *Finding) error
+	// Update reads the stored finding with the given ID, passes it to apply
+	// and stores the finding apply returns. The read, apply and write are
+	// atomic with respect to other writers, so concurrent updates of one
+	// finding are serialized instead of the last one overwriting the
+	// rest. Nothing is written when apply fails. The error wraps
+	// ErrNotFound when there is no such finding.
+	Update(id string, apply func(stored *Finding) (*Finding, error)) error
+	// Delete removes a finding; the error wraps ErrNotFound when there is none
+	Delete(id string) error
+	// Query returns the stored findings matching opts, in no particular
+	// order. Limit and Offset are applied by the Store.
+	Query(opts *FilterOptions) ([]Finding, error)
+	// Close releases any resources held by the backend
+	Close() error
+}
+
+// BackendOpener opens a backend rooted at a project's findings directory.
+type BackendOpener func(findingsPath string) (Backend, error)
+
+var (
+	backendsMu sync.RWMutex
+	backends   = map[string]BackendOpener{
+		DefaultBackend: func(findingsPath string) (Backend, error) {
+			return newYAMLBackend(findingsPath), nil
+		},
+	}
+)
+
+// RegisterBackend makes a backend available by name. Backends with heavy
+// dependencies (the SQLite driver) live in their own package and register
+// themselves from init, so importing finding does not pull them in.
+func RegisterBackend(name string, open BackendOpener) {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+	backends[name] = open
+}
+
+// BackendNames returns the registered backend names, sorted.
+func BackendNames() []string {
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// OpenBackend opens the named backend for the project.
+func OpenBackend(p *project.Project, name string) (Backend, error) {
+	return OpenBackendAt(name, p.GetFindingsPath())
+}
+
+// OpenBackendAt opens the named backend on a findings directory that need
+// not belong to the active project, such as a copy extracted from git.
+func OpenBackendAt(name, findingsPath string) (Backend, error) {
+	backendsMu.RLock()
+	open, ok := backends[name]
+	backendsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown finding backend %q (available: %v)", name, BackendNames())
+	}
+	return open(findingsPath)
+}
+
+// ConfiguredBackend returns the backend name selected in project.yaml.
+func ConfiguredBackend(p *project.Project) string {
+	if p.Config != nil && p.Config.Findings.Backend != "" {
+		return p.Config.Findings.Backend
+	}
+	return DefaultBackend
+}
+
+// Migrate copies every finding from src into dst, preserving IDs,
+// timestamps and fingerprints. dst must be empty so a migration never
+// silently merges two stores. Returns the IDs copied.
+func Migrate(src, dst Backend) ([]string, error) {
+	existing, err := dst.Query(nil)
+	if err != nil {
+		return nil, fmt.Errorf("read %s backend: %w", dst.Name(), err)
+	}
+	if len(existing) > 0 {
+		return nil, fmt.Errorf("the %s backend already holds %d findings; refusing to merge", dst.Name(), len(existing))
+	}
+
+	all, err := src.Query(nil)
+	if err != nil {
+		return nil, fmt.Errorf("read %s backend: %w", src.Name(), err)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].ID < all[j].ID })
+
+	ids := make([]string, 0, len(all))
+	for i := range all {
+		if err := dst.Put(&all[i]); err != nil {
+			return ids, fmt.Errorf("copy %s: %w", all[i].ID, err)
+		}
+		ids = append(ids, all[i].ID)
+	}
+	return ids, nil
+}
+
+// Match reports whether f passes every filter set in opts. A nil opts
+// matches everything; Limit and Offset are ignored.
+func (opts *FilterOptions) Match(f *Finding) bool {
+	if opts == nil {
+		return true
+	}
+	if opts.Severity != "" && f.Severity != opts.Severity {
+		return false
+	}
+	if opts.Status != "" && f.Status != opts.Status {
+		return false
+	}
+	if opts.Confidence != "" && f.Confidence != opts.Confidence {
+		return false
+	}
+	if opts.Exploitability != "" && f.Exploitability != opts.Exploitability {
+		return false
+	}
+	if opts.FixPriority != "" && f.FixPriority != opts.FixPriority {
+		return false
+	}
+	if opts.CWE != "" && f.CWE != opts.CWE {
+		return false
+	}
+	if opts.File != "" && f.Location.File != opts.File {
+		return false
+	}
+	if opts.Tag != "" && !containsTag(f.Tags, opts.Tag) {
+		return false
+	}
+	if opts.CreatedBy != "" && f.CreatedBy != opts.CreatedBy {
+		return false
+	}
+	return true
+}
+
+// failedBackend stands in for a backend that could not be opened so that
+// NewStore can keep its signature; every call reports the open error.
+type failedBackend struct {
+	name string
+	err  error
+}
+
+func (b failedBackend) Name() string                                          { return b.name }
+func (b failedBackend) Get(string) (*Finding, error)                          { return nil, b.err }
+func (b failedBackend) Insert(*Finding) (*Finding, error)                     { return nil, b.err }
+func (b failedBackend) Put(*Finding) error                                    { return b.err }
+func (b failedBackend) Update(string, func(*Finding) (*Finding, error)) error { return b.err }
+func (b failedBackend) Delete(string) error                                   { return b.err }
+func (b failedBackend) Query(*FilterOptions) ([]Finding, error)               { return nil, b.err }
+func (b failedBackend) Close() error                                          { return nil }
@@ -0,0 +1,198 @@
+package finding
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestStoreListLimitOffset(t *testing.T) {
+	p, cleanup := setupTestProject(t)
+	defer cleanup()
+
+	store := NewStore(p)
+	for i, sev := range []Severity{SeverityLow, SeverityCritical, SeverityHigh, SeverityMedium} {
+		f := &Finding{Title: fmt.Sprintf("f%d", i), Severity: sev, Location: Location{File: "a.go", LineStart: 1}}
+		if _, err := store.Create(f); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+
+	result, err := store.List(&FilterOptions{Offset: 1, Limit: 2})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if result.Total != 4 || len(result.Findings) != 2 {
+		t.Fatalf("got Total=%d len=%d, want 4 and 2", result.Total, len(result.Findings))
+	}
+	if result.Findings[0].Severity != SeverityHigh || result.Findings[1].Severity != SeverityMedium {
+		t.Errorf("page = %s, %s; want high, medium", result.Findings[0].Severity, result.Findings[1].Severity)
+	}
+
+	result, _ = store.List(&FilterOptions{Offset: 10})
+	if len(result.Findings) != 0 {
+		t.Errorf("offset past the end returned %d findings", len(result.Findings))
+	}
+}
+
+// TestStoreConcurrentCreate mirrors parallel agents filing findings at the
+// same time: every Create must get its own ID and none may be overwritten.
+func TestStoreConcurrentCreate(t *testing.T) {
+	p, cleanup := setupTestProject(t)
+	defer cleanup()
+
+	const workers, perWorker = 8, 5
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*perWorker)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			store := NewStore(p)
+			for i := 0; i < perWorker; i++ {
+				f := &Finding{
+					Title:     fmt.Sprintf("finding %d-%d", w, i),
+					Severity:  SeverityLow,
+					CWE:       "CWE-79",
+					Location:  Location{File: fmt.Sprintf("f%d_%d.go", w, i), LineStart: 1},
+					CreatedBy: "agent",
+				}
+				if _, err := store.Create(f); err != nil {
+					errs <- err
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("Create failed: %v", err)
+	}
+
+	result, err := NewStore(p).List(nil)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if result.Total != workers*perWorker {
+		t.Errorf("expected %d findings, got %d", workers*perWorker, result.Total)
+	}
+}
+
+// TestStoreConcurrentCreateDedup files the same finding from parallel
+// agents: the dedup check and the write must not interleave, so each round
+// creates exactly one finding and every other Create reports it as existing.
+func TestStoreConcurrentCreateDedup(t *testing.T) {
+	p, cleanup := setupTestProject(t)
+	defer cleanup()
+
+	const rounds, workers = 10, 8
+	for round := 0; round < rounds; round++ {
+		var wg sync.WaitGroup
+		start := make(chan struct{})
+		created := make(chan bool, workers)
+		errs := make(chan error, workers)
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				f := &Finding{
+					Title:     "same finding",
+					Severity:  SeverityHigh,
+					CWE:       "CWE-89",
+					Location:  Location{File: fmt.Sprintf("db%d.go", round), LineStart: 12},
+					CreatedBy: "agent",
+				}
+				store := NewStore(p)
+				<-start
+				existed, err := store.Create(f)
+				if err != nil {
+					errs <- err
+					return
+				}
+				created <- !existed
+			}()
+		}
+		close(start)
+		wg.Wait()
+		close(created)
+		close(errs)
+		for err := range errs {
+			t.Errorf("round %d: Create failed: %v", round, err)
+		}
+		fresh := 0
+		for c := range created {
+			if c {
+				fresh++
+			}
+		}
+		if fresh != 1 {
+			t.Errorf("round %d: %d Creates reported a new finding, want 1", round, fresh)
+		}
+	}
+
+	result, err := NewStore(p).List(nil)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if result.Total != rounds {
+		t.Errorf("expected %d findings, got %d", rounds, result.Total)
+	}
+}
+
+func TestStoreDeleteNotFound(t *testing.T) {
+	p, cleanup := setupTestProject(t)
+	defer cleanup()
+
+	if err := NewStore(p).Delete("FIND-999"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestUnknownBackend(t *testing.T) {
+	p, cleanup := setupTestProject(t)
+	defer cleanup()
+
+	p.Config.Findings.Backend = "postgres"
+	store := NewStore(p)
+	if _, err := store.List(nil); err == nil {
+		t.Error("expected List on an unknown backend to fail")
+	}
+	if _, err := store.Create(&Finding{Title: "x", Location: Location{File: "a.go", LineStart: 1}}); err == nil {
+		t.Error("expected Create on an unknown backend to fail")
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	p, cleanup := setupTestProject(t)
+	defer cleanup()
+
+	store := NewStore(p)
+	for _, title := range []string{"one", "two"} {
+		if _, err := store.Create(&Finding{Title: title, Severity: SeverityHigh, Location: Location{File: title + ".go", LineStart: 1}}); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+
+	dst := newYAMLBackend(t.TempDir())
+	ids, err := Migrate(store.backend, dst)
+	if err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	if len(ids) != 2 || ids[0] != "FIND-001" || ids[1] != "FIND-002" {
+		t.Errorf("migrated %v", ids)
+	}
+
+	orig, _ := store.Read("FIND-002")
+	copied, err := dst.Get("FIND-002")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if copied.Title != orig.Title || !copied.CreatedAt.Equal(orig.CreatedAt) || copied.Fingerprint != orig.Fingerprint {
+		t.Errorf("copy differs: %+v vs %+v", copied, orig)
+	}
+
+	if _, err := Migrate(store.backend, dst); err == nil {
+		t.Error("expected migration into a non-empty backend to fail")
+	}
+}
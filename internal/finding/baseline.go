@@ -0,0 +1,202 @@
+package finding
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// BaselineState classifies a finding relative to a previous run.
+type BaselineState string
+
+const (
+	BaselineNew       BaselineState = "new"       // present now, absent from the baseline
+	BaselineUnchanged BaselineState = "unchanged" // present in both runs
+	BaselineResolved  BaselineState = "resolved"  // present in the baseline only
+)
+
+// BaselineDiff is the result of comparing the current findings against a
+// baseline snapshot. Every finding carries its BaselineState.
+type BaselineDiff struct {
+	New       []Finding `json:"new"`
+	Unchanged []Finding `json:"unchanged"`
+	Resolved  []Finding `json:"resolved"`
+}
+
+// All returns new, unchanged and resolved findings in that order, which is
+// the order exporters should present them in.
+func (d *BaselineDiff) All() []Finding {
+	all := make([]Finding, 0, len(d.New)+len(d.Unchanged)+len(d.Resolved))
+	all = append(all, d.New...)
+	all = append(all, d.Unchanged...)
+	all = append(all, d.Resolved...)
+	return all
+}
+
+// Counts returns the number of findings per state.
+func (d *BaselineDiff) Counts() map[BaselineState]int {
+	return map[BaselineState]int{
+		BaselineNew:       len(d.New),
+		BaselineUnchanged: len(d.Unchanged),
+		BaselineResolved:  len(d.Resolved),
+	}
+}
+
+// DiffBaseline classifies findings by fingerprint. A current finding whose
+// fingerprint appears in the baseline is unchanged, otherwise new; baseline
+// fingerprints with no current counterpart are resolved. Findings already
+// marked fixed in the current set count as absent, so a manual fix shows up
+// as resolved rather than unchanged. Findings already fixed in the baseline
+// count as absent too: they aren't resolved again, and a current finding
+// with the same fingerprint is new (it came back).
+//
+// Several findings may share a fingerprint (different creators, same vuln).
+// All current copies get the same state; a resolved fingerprint is reported
+// once, using the first baseline finding that carried it.
+func DiffBaseline(current, baseline []Finding) *BaselineDiff {
+	baseFPs := make(map[string]bool, len(baseline))
+	for _, f := range baseline {
+		if f.Status != StatusFixed {
+			baseFPs[fingerprintOf(f)] = true
+		}
+	}
+
+	diff := &BaselineDiff{New: []Finding{}, Unchanged: []Finding{}, Resolved: []Finding{}}
+	currentFPs := make(map[string]bool, len(current))
+	for _, f := range current {
+		if f.Status == StatusFixed {
+			continue
+		}
+		fp := fingerprintOf(f)
+		f.Fingerprint = fp
+		currentFPs[fp] = true
+		if baseFPs[fp] {
+			f.BaselineState = BaselineUnchanged
+			diff.Unchanged = append(diff.Unchanged, f)
+		} else {
+			f.BaselineState = BaselineNew
+			diff.New = append(diff.New, f)
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, f := range baseline {
+		if f.Status == StatusFixed {
+			continue
+		}
+		fp := fingerprintOf(f)
+		if currentFPs[fp] || seen[fp] {
+			continue
+		}
+		seen[fp] = true
+		f.Fingerprint = fp
+		f.BaselineState = BaselineResolved
+		diff.Resolved = append(diff.Resolved, f)
+	}
+
+	for _, list := range [][]Finding{diff.New, diff.Unchanged, diff.Resolved} {
+		sortBySeverity(list)
+	}
+	return diff
+}
+
+// MarkResolvedFixed sets every open or confirmed finding in the store whose
+// fingerprint matches a resolved baseline entry to fixed, appending a note
+// so the status change is attributable. It returns the IDs it updated.
+func (s *Store) MarkResolvedFixed(resolved []Finding, author string) ([]string, error) {
+	if len(resolved) == 0 {
+		return nil, nil
+	}
+	fps := make(map[string]bool, len(resolved))
+	for _, f := range resolved {
+		fps[fingerprintOf(f)] = true
+	}
+
+	all, err := s.List(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var updated []string
+	for _, f := range all.Findings {
+		if f.Status != StatusOpen && f.Status != StatusConfirmed {
+			continue
+		}
+		if !fps[fingerprintOf(f)] {
+			continue
+		}
+		marked := false
+		_, err := s.Modify(f.ID, Actor{Name: author, Source: "finding diff"}, func(f *Finding) error {
+			// Re-checked on the stored copy: it may have been triaged
+			// since the list was read.
+			if f.Status != StatusOpen && f.Status != StatusConfirmed {
+				return nil
+			}
+			f.Status = StatusFixed
+			f.Notes = append(f.Notes, FindingNote{
+				Timestamp: time.Now(),
+				Author:    author,
+				Text:      "Marked fixed: absent from the current run (baseline diff).",
+			})
+			marked = true
+			return nil
+		})
+		if err != nil {
+			return updated, fmt.Errorf("failed to update %s: %w", f.ID, err)
+		}
+		if marked {
+			updated = append(updated, f.ID)
+		}
+	}
+	return updated, nil
+}
+
+// ParseBaseline decodes a baseline snapshot. Both the JSON export report
+// (`finding export -f json`) and a bare JSON array of findings are accepted.
+func ParseBaseline(data []byte) ([]Finding, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, fmt.Errorf("baseline is empty")
+	}
+
+	if data[0] == '[' {
+		var findings []Finding
+		if err := json.Unmarshal(data, &findings); err != nil {
+			return nil, fmt.Errorf("failed to parse baseline: %w", err)
+		}
+		return findings, nil
+	}
+
+	var report struct {
+		Findings *[]Finding `json:"findings"`
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline: %w", err)
+	}
+	if report.Findings == nil {
+		return nil, fmt.Errorf("baseline has no \"findings\" array (expected a quokka JSON export)")
+	}
+	return *report.Findings, nil
+}
+
+// fingerprintOf returns the stored fingerprint, recomputing it for findings
+// that pre-date the field.
+func fingerprintOf(f Finding) string {
+	if f.Fingerprint != "" {
+		return f.Fingerprint
+	}
+	return Fingerprint(f)
+}
+
+func sortBySeverity(findings []Finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		wi := SeverityWeight(findings[i].Severity)
+		wj := SeverityWeight(findings[j].Severity)
+		if wi != wj {
+			return wi > wj
+		}
+		return findings[i].ID < findings[j].ID
+	})
+}
@@ -0,0 +1,164 @@
+package finding
+
+import (
+	"testing"
+)
+
+func baselineFinding(id, title, file string, status Status) Finding {
+	return Finding{
+		ID:       id,
+		Title:    title,
+		Severity: SeverityHigh,
+		Status:   status,
+		CWE:      "CWE-89",
+		Location: Location{File: file, LineStart: 10},
+	}
+}
+
+func TestDiffBaseline(t *testing.T) {
+	baseline := []Finding{
+		baselineFinding("FIND-001", "SQL injection in getUser", "db.go", StatusOpen),
+		baselineFinding("FIND-002", "SQL injection in listUsers", "db.go", StatusOpen),
+		baselineFinding("FIND-003", "SQL injection in deleteUser", "db.go", StatusOpen),
+	}
+	current := []Finding{
+		// Same issue, reworded title and shifted line: still unchanged.
+		baselineFinding("FIND-010", "Possible SQL Injection in getUser", "db.go", StatusOpen),
+		baselineFinding("FIND-011", "SQL injection in search", "search.go", StatusOpen),
+		// Marked fixed in the store: counts as gone.
+		baselineFinding("FIND-012", "SQL injection in deleteUser", "db.go", StatusFixed),
+	}
+	current[0].Location.LineStart = 99
+
+	diff := DiffBaseline(current, baseline)
+
+	if len(diff.New) != 1 || diff.New[0].ID != "FIND-011" {
+		t.Errorf("New = %+v, want [FIND-011]", diff.New)
+	}
+	if len(diff.Unchanged) != 1 || diff.Unchanged[0].ID != "FIND-010" {
+		t.Errorf("Unchanged = %+v, want [FIND-010]", diff.Unchanged)
+	}
+	if len(diff.Resolved) != 2 {
+		t.Fatalf("Resolved = %+v, want FIND-002 and FIND-003", diff.Resolved)
+	}
+	for _, f := range diff.Resolved {
+		if f.BaselineState != BaselineResolved {
+			t.Errorf("%s state = %q, want resolved", f.ID, f.BaselineState)
+		}
+		if f.Fingerprint == "" {
+			t.Errorf("%s has no fingerprint", f.ID)
+		}
+	}
+	if diff.New[0].BaselineState != BaselineNew || diff.Unchanged[0].BaselineState != BaselineUnchanged {
+		t.Error("current findings not annotated with their baseline state")
+	}
+
+	counts := diff.Counts()
+	if counts[BaselineNew] != 1 || counts[BaselineUnchanged] != 1 || counts[BaselineResolved] != 2 {
+		t.Errorf("Counts = %v", counts)
+	}
+	if all := diff.All(); len(all) != 4 || all[0].BaselineState != BaselineNew || all[3].BaselineState != BaselineResolved {
+		t.Errorf("All() order wrong: %+v", all)
+	}
+}
+
+func TestDiffBaseline_FixedBaselineEntries(t *testing.T) {
+	baseline := []Finding{
+		baselineFinding("FIND-001", "SQL injection in getUser", "db.go", StatusFixed),
+		baselineFinding("FIND-002", "SQL injection in listUsers", "db.go", StatusFixed),
+	}
+	current := []Finding{
+		// Fixed in the baseline, open again now: a regression, so new.
+		baselineFinding("FIND-010", "SQL injection in getUser", "db.go", StatusOpen),
+	}
+
+	diff := DiffBaseline(current, baseline)
+
+	if len(diff.New) != 1 || diff.New[0].ID != "FIND-010" {
+		t.Errorf("New = %+v, want [FIND-010]", diff.New)
+	}
+	if len(diff.Unchanged) != 0 {
+		t.Errorf("Unchanged = %+v, want none", diff.Unchanged)
+	}
+	// FIND-002 was already fixed: not resolved a second time.
+	if len(diff.Resolved) != 0 {
+		t.Errorf("Resolved = %+v, want none", diff.Resolved)
+	}
+}
+
+func TestParseBaseline(t *testing.T) {
+	report := []byte(`{"metadata":{"tool":"quokka"},"findings":[{"id":"FIND-001","title":"x","fingerprint":"abc"}]}`)
+	got, err := ParseBaseline(report)
+	if err != nil {
+		t.Fatalf("ParseBaseline(report): %v", err)
+	}
+	if len(got) != 1 || got[0].Fingerprint != "abc" {
+		t.Errorf("ParseBaseline(report) = %+v", got)
+	}
+
+	got, err = ParseBaseline([]byte(`[{"id":"FIND-002","title":"y"}]`))
+	if err != nil {
+		t.Fatalf("ParseBaseline(array): %v", err)
+	}
+	if len(got) != 1 || got[0].ID != "FIND-002" {
+		t.Errorf("ParseBaseline(array) = %+v", got)
+	}
+
+	empty, err := ParseBaseline([]byte(`{"findings":[]}`))
+	if err != nil || len(empty) != 0 {
+		t.Errorf("empty findings array should parse, got %v, %v", empty, err)
+	}
+
+	for _, bad := range []string{"", `{"runs":[]}`, "not json"} {
+		if _, err := ParseBaseline([]byte(bad)); err == nil {
+			t.Errorf("ParseBaseline(%q) should fail", bad)
+		}
+	}
+}
+
+func TestStoreMarkResolvedFixed(t *testing.T) {
+	p, cleanup := setupTestProject(t)
+	defer cleanup()
+	store := NewStore(p)
+
+	gone := baselineFinding("", "SQL injection in getUser", "db.go", "")
+	gone.Confidence = ConfidenceHigh
+	stays := baselineFinding("", "SQL injection in search", "search.go", "")
+	fp := baselineFinding("", "SQL injection in report", "report.go", StatusFalsePositive)
+	for _, f := range []*Finding{&gone, &stays, &fp} {
+		if _, err := store.Create(f); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	resolved := []Finding{
+		{Fingerprint: gone.Fingerprint},
+		{Fingerprint: fp.Fingerprint},
+	}
+	updated, err := store.MarkResolvedFixed(resolved, "quokka")
+	if err != nil {
+		t.Fatalf("MarkResolvedFixed: %v", err)
+	}
+	if len(updated) != 1 || updated[0] != gone.ID {
+		t.Fatalf("updated = %v, want [%s]", updated, gone.ID)
+	}
+
+	got, err := store.Read(gone.ID)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if got.Status != StatusFixed {
+		t.Errorf("status = %s, want fixed", got.Status)
+	}
+	if len(got.Notes) != 1 || got.Notes[0].Author != "quokka" {
+		t.Errorf("expected one attributed note, got %+v", got.Notes)
+	}
+
+	// False positives keep their triage status.
+	if got, _ := store.Read(fp.ID); got.Status != StatusFalsePositive {
+		t.Errorf("false positive status changed to %s", got.Status)
+	}
+	if got, _ := store.Read(stays.ID); got.Status != StatusOpen {
+		t.Errorf("unrelated finding status changed to %s", got.Status)
+	}
+}
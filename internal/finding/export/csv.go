@@ -55,6 +55,10 @@ func (e *CSVExporter) Export(findings []finding.Finding) ([]byte, error) {
 		"References",
 		"Created At",
 		"Created By",
+		"Suppressed By",
+		"Suppression Reason",
+		"Suppression Approved By",
+		"Suppression Expires",
 	}
 	if err := w.Write(headers); err != nil {
 		return nil, fmt.Errorf("failed to write header: %w", err)
@@ -78,6 +82,14 @@ func (e *CSVExporter) Export(findings []finding.Finding) ([]byte, error) {
 			lineEnd = fmt.Sprintf("%d", f.Location.LineEnd)
 		}
 
+		var supID, supReason, supApprover, supExpires string
+		if sup := f.Suppression; sup != nil {
+			supID = sup.ExceptionID
+			supReason = sup.Reason
+			supApprover = sup.ApprovedBy
+			supExpires = sup.Expires.Format("2006-01-02")
+		}
+
 		row := []string{
 			f.ID,
 			f.Title,
@@ -98,6 +110,10 @@ func (e *CSVExporter) Export(findings []finding.Finding) ([]byte, error) {
 			strings.Join(f.References, "; "),
 			f.CreatedAt.Format("2006-01-02 15:04:05"),
 			f.CreatedBy,
+			supID,
+			supReason,
+			supApprover,
+			supExpires,
 		}
 
 		if err := w.Write(row); err != nil {
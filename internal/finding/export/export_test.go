@@ -458,3 +458,146 @@ func TestSARIFSeverityMapping(t *testing.T) {
 		})
 	}
 }
+
+func TestExport_BaselineState(t *testing.T) {
+	findings := createTestFindings()
+	findings[0].BaselineState = finding.BaselineNew
+	findings[1].BaselineState = finding.BaselineResolved
+
+	data, err := NewSARIFExporter().Export(findings)
+	if err != nil {
+		t.Fatalf("SARIF export: %v", err)
+	}
+	var sarif SarifLog
+	if err := json.Unmarshal(data, &sarif); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	got := map[string]string{}
+	for _, r := range sarif.Runs[0].Results {
+		got[r.Properties["id"].(string)] = r.BaselineState
+	}
+	if got["FIND-001"] != "new" || got["FIND-002"] != "absent" {
+		t.Errorf("SARIF baselineState = %v, want FIND-001=new FIND-002=absent", got)
+	}
+
+	data, err = NewJSONExporter().Export(findings)
+	if err != nil {
+		t.Fatalf("JSON export: %v", err)
+	}
+	var report JSONReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if report.Summary.ByBaselineState["new"] != 1 || report.Summary.ByBaselineState["resolved"] != 1 {
+		t.Errorf("by_baseline_state = %v", report.Summary.ByBaselineState)
+	}
+	if report.Findings[0].BaselineState != finding.BaselineNew {
+		t.Errorf("finding baseline_state = %q, want new", report.Findings[0].BaselineState)
+	}
+
+	data, err = NewMarkdownExporter().Export(findings)
+	if err != nil {
+		t.Fatalf("Markdown export: %v", err)
+	}
+	md := string(data)
+	for _, want := range []string{"### Compared to Baseline", "| new | 1 |", "| resolved | 1 |", "| **Baseline** | new |"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q", want)
+		}
+	}
+}
+
+func TestExport_NoBaselineStateByDefault(t *testing.T) {
+	findings := createTestFindings()
+
+	data, err := NewSARIFExporter().Export(findings)
+	if err != nil {
+		t.Fatalf("SARIF export: %v", err)
+	}
+	if strings.Contains(string(data), "baselineState") {
+		t.Error("SARIF should omit baselineState when no baseline was applied")
+	}
+
+	data, err = NewMarkdownExporter().Export(findings)
+	if err != nil {
+		t.Fatalf("Markdown export: %v", err)
+	}
+	if strings.Contains(string(data), "Compared to Baseline") {
+		t.Error("markdown should omit the baseline section when no baseline was applied")
+	}
+}
+
+func TestExport_SuppressionAnnotation(t *testing.T) {
+	findings := createTestFindings()
+	findings[1].Suppression = &finding.Suppression{
+		ExceptionID: "EXC-004",
+		Reason:      "search is admin-only",
+		ApprovedBy:  "human:secteam",
+		Expires:     time.Date(2030, 1, 31, 0, 0, 0, 0, time.UTC),
+	}
+
+	for _, format := range []string{"json", "md", "html", "csv", "sarif"} {
+		data, err := ExportFindings(findings, format, "p")
+		if err != nil {
+			t.Fatalf("%s export: %v", format, err)
+		}
+		out := string(data)
+		for _, want := range []string{"EXC-004", "search is admin-only", "human:secteam", "2030-01-31"} {
+			if !strings.Contains(out, want) {
+				t.Errorf("%s export missing %q", format, want)
+			}
+		}
+	}
+
+	data, err := NewSARIFExporter().Export(findings)
+	if err != nil {
+		t.Fatalf("SARIF export: %v", err)
+	}
+	var sarif SarifLog
+	if err := json.Unmarshal(data, &sarif); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	for _, r := range sarif.Runs[0].Results {
+		id := r.Properties["id"]
+		if id == "FIND-002" && (len(r.Suppressions) != 1 || r.Suppressions[0].Justification != "search is admin-only") {
+			t.Errorf("FIND-002 suppressions = %+v", r.Suppressions)
+		}
+		if id == "FIND-001" && len(r.Suppressions) != 0 {
+			t.Errorf("FIND-001 should not be suppressed: %+v", r.Suppressions)
+		}
+	}
+
+	data, err = NewJSONExporter().Export(findings)
+	if err != nil {
+		t.Fatalf("JSON export: %v", err)
+	}
+	var report JSONReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if report.Summary.Suppressed != 1 {
+		t.Errorf("summary.suppressed = %d, want 1", report.Summary.Suppressed)
+	}
+	if report.Findings[1].Suppression == nil || report.Findings[1].Suppression.ExceptionID != "EXC-004" {
+		t.Errorf("JSON finding lost suppressed_by: %+v", report.Findings[1].Suppression)
+	}
+}
+
+func TestMarkdownExport_SuppressionCellEscaped(t *testing.T) {
+	findings := createTestFindings()
+	findings[0].Suppression = &finding.Suppression{
+		ExceptionID: "EXC-005",
+		Reason:      "admin-only | behind VPN\nreviewed twice",
+		ApprovedBy:  "human:a|b",
+		Expires:     time.Date(2030, 1, 31, 0, 0, 0, 0, time.UTC),
+	}
+
+	data, err := NewMarkdownExporter().Export(findings)
+	if err != nil {
+		t.Fatalf("Markdown export: %v", err)
+	}
+	want := "| **Suppressed** | `EXC-005`: admin-only \\| behind VPN<br>reviewed twice (approved by human:a\\|b, expires 2030-01-31) |\n"
+	if !strings.Contains(string(data), want) {
+		t.Errorf("suppression row not escaped, want %q in:\n%s", want, data)
+	}
+}
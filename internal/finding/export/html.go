@@ -43,9 +43,13 @@ func (e *HTMLExporter) Export(findings []finding.Finding) ([]byte, error) {
 	// Summary stats
 	severityCounts := make(map[string]int)
 	statusCounts := make(map[string]int)
+	suppressed := 0
 	for _, f := range findings {
 		severityCounts[string(f.Severity)]++
 		statusCounts[string(f.Status)]++
+		if f.Suppression != nil {
+			suppressed++
+		}
 	}
 
 	// HTML document
@@ -168,6 +172,15 @@ func (e *HTMLExporter) Export(findings []finding.Finding) ([]byte, error) {
         }
         a { color: var(--info); }
         .evidence-list { list-style-position: inside; }
+        .finding.suppressed { opacity: 0.7; }
+        .suppressed-badge {
+            padding: 0.25rem 0.75rem;
+            border-radius: 9999px;
+            font-size: 0.75rem;
+            font-weight: 600;
+            background: var(--text-muted);
+            color: white;
+        }
     </style>
 </head>
 <body>
@@ -193,6 +206,14 @@ func (e *HTMLExporter) Export(findings []finding.Finding) ([]byte, error) {
 		}
 	}
 
+	if suppressed > 0 {
+		fmt.Fprintf(&b, `            <div class="stat-card">
+                <div class="stat-value" style="color: var(--text-muted)">%d</div>
+                <div class="stat-label">Suppressed</div>
+            </div>
+`, suppressed)
+	}
+
 	b.WriteString(`        </div>
 
         <h2>Findings</h2>
@@ -213,16 +234,26 @@ func (e *HTMLExporter) Export(findings []finding.Finding) ([]byte, error) {
 func (e *HTMLExporter) renderFinding(f finding.Finding) string {
 	var b strings.Builder
 
-	fmt.Fprintf(&b, `        <div class="finding">
+	cardClass := "finding"
+	suppressedBadge := ""
+	if f.Suppression != nil {
+		cardClass = "finding suppressed"
+		suppressedBadge = `
+                <span class="suppressed-badge">SUPPRESSED</span>`
+	}
+
+	fmt.Fprintf(&b, `        <div class="%s">
             <div class="finding-header">
-                <span class="severity-badge severity-%s">%s</span>
+                <span class="severity-badge severity-%s">%s</span>%s
                 <span class="finding-title">%s</span>
                 <span class="finding-id">%s</span>
             </div>
             <div class="finding-body">
 `,
+		cardClass,
 		string(f.Severity),
 		strings.ToUpper(string(f.Severity)),
+		suppressedBadge,
 		html.EscapeString(f.Title),
 		html.EscapeString(f.ID),
 	)
@@ -244,6 +275,11 @@ func (e *HTMLExporter) renderFinding(f finding.Finding) string {
 	if f.CVSS != nil {
 		fmt.Fprintf(&b, `                        <div class="meta-item"><span class="meta-label">CVSS:</span> %.1f (%s)</div>
 `, f.CVSS.Score, html.EscapeString(f.CVSS.Vector))
+	}
+	if sup := f.Suppression; sup != nil {
+		fmt.Fprintf(&b, `                        <div class="meta-item"><span class="meta-label">Suppressed by:</span> %s (approved by %s, expires %s)</div>
+                        <div class="meta-item"><span class="meta-label">Reason:</span> %s</div>
+`, html.EscapeString(sup.ExceptionID), html.EscapeString(sup.ApprovedBy), sup.Expires.Format("2006-01-02"), html.EscapeString(sup.Reason))
 	}
 	b.WriteString(`                    </div>
                 </div>
@@ -27,6 +27,11 @@ type JSONSummary struct {
 	Total      int            `json:"total"`
 	BySeverity map[string]int `json:"by_severity"`
 	ByStatus   map[string]int `json:"by_status"`
+	// ByBaselineState is only populated when the findings come from a
+	// baseline diff (`finding diff`).
+	ByBaselineState map[string]int `json:"by_baseline_state,omitempty"`
+	// Suppressed counts findings carrying an exception match.
+	Suppressed int `json:"suppressed,omitempty"`
 }
 
 // JSONExporter exports findings to JSON format
@@ -61,6 +66,15 @@ func (e *JSONExporter) Export(findings []finding.Finding) ([]byte, error) {
 	for _, f := range findings {
 		summary.BySeverity[string(f.Severity)]++
 		summary.ByStatus[string(f.Status)]++
+		if f.BaselineState != "" {
+			if summary.ByBaselineState == nil {
+				summary.ByBaselineState = make(map[string]int)
+			}
+			summary.ByBaselineState[string(f.BaselineState)]++
+		}
+		if f.Suppression != nil {
+			summary.Suppressed++
+		}
 	}
 
 	report := JSONReport{
@@ -47,9 +47,20 @@ func (e *MarkdownExporter) Export(findings []finding.Finding) ([]byte, error) {
 	// Summary by severity
 	severityCounts := make(map[string]int)
 	statusCounts := make(map[string]int)
+	baselineCounts := make(map[finding.BaselineState]int)
+	suppressed := 0
 	for _, f := range findings {
 		severityCounts[string(f.Severity)]++
 		statusCounts[string(f.Status)]++
+		if f.BaselineState != "" {
+			baselineCounts[f.BaselineState]++
+		}
+		if f.Suppression != nil {
+			suppressed++
+		}
+	}
+	if suppressed > 0 {
+		fmt.Fprintf(&b, "**Suppressed by exception:** %d\n\n", suppressed)
 	}
 
 	b.WriteString("### By Severity\n\n")
@@ -72,6 +83,16 @@ func (e *MarkdownExporter) Export(findings []finding.Finding) ([]byte, error) {
 	}
 	b.WriteString("\n")
 
+	if len(baselineCounts) > 0 {
+		b.WriteString("### Compared to Baseline\n\n")
+		b.WriteString("| State | Count |\n")
+		b.WriteString("|-------|-------|\n")
+		for _, state := range []finding.BaselineState{finding.BaselineNew, finding.BaselineUnchanged, finding.BaselineResolved} {
+			fmt.Fprintf(&b, "| %s | %d |\n", state, baselineCounts[state])
+		}
+		b.WriteString("\n")
+	}
+
 	// Findings
 	b.WriteString("## Findings\n\n")
 
@@ -87,7 +108,11 @@ func (e *MarkdownExporter) renderFinding(f finding.Finding) string {
 	var b strings.Builder
 
 	// Title with severity badge
-	fmt.Fprintf(&b, "### %s %s\n\n", e.formatSeverity(f.Severity), f.Title)
+	suppressedTag := ""
+	if f.Suppression != nil {
+		suppressedTag = " _(suppressed)_"
+	}
+	fmt.Fprintf(&b, "### %s %s%s\n\n", e.formatSeverity(f.Severity), f.Title, suppressedTag)
 
 	// Metadata table
 	b.WriteString("| Property | Value |\n")
@@ -96,6 +121,13 @@ func (e *MarkdownExporter) renderFinding(f finding.Finding) string {
 	fmt.Fprintf(&b, "| **Severity** | %s |\n", f.Severity)
 	fmt.Fprintf(&b, "| **Confidence** | %s |\n", f.Confidence)
 	fmt.Fprintf(&b, "| **Status** | %s |\n", f.Status)
+	if f.BaselineState != "" {
+		fmt.Fprintf(&b, "| **Baseline** | %s |\n", f.BaselineState)
+	}
+	if sup := f.Suppression; sup != nil {
+		fmt.Fprintf(&b, "| **Suppressed** | `%s`: %s (approved by %s, expires %s) |\n",
+			tableCell(sup.ExceptionID), tableCell(sup.Reason), tableCell(sup.ApprovedBy), sup.Expires.Format("2006-01-02"))
+	}
 	if f.CWE != "" {
 		fmt.Fprintf(&b, "| **CWE** | [%s](https://cwe.mitre.org/data/definitions/%s.html) |\n", f.CWE, strings.TrimPrefix(f.CWE, "CWE-"))
 	}
@@ -214,6 +246,14 @@ func (e *MarkdownExporter) renderFinding(f finding.Finding) string {
 	return b.String()
 }
 
+// tableCellReplacer escapes free text for a single table cell: a pipe
+// would end the cell and a newline would end the row.
+var tableCellReplacer = strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>", "\r", "<br>")
+
+func tableCell(s string) string {
+	return tableCellReplacer.Replace(s)
+}
+
 func (e *MarkdownExporter) formatSeverity(s finding.Severity) string {
 	switch s {
 	case finding.SeverityCritical:
@@ -56,6 +56,7 @@ type SarifResult struct {
 	Locations           []SarifLocation        `json:"locations,omitempty"`
 	CodeFlows           []SarifCodeFlow        `json:"codeFlows,omitempty"`
 	PartialFingerprints map[string]string      `json:"partialFingerprints,omitempty"`
+	BaselineState       string                 `json:"baselineState,omitempty"`
 	Suppressions        []SarifSuppression     `json:"suppressions,omitempty"`
 	Properties          map[string]interface{} `json:"properties,omitempty"`
 }
@@ -353,10 +354,25 @@ func (e *SARIFExporter) buildResult(f finding.Finding, ruleMap map[string]int) S
 		}
 	}
 
+	result.BaselineState = sarifBaselineState(f.BaselineState)
+
 	// Mark suppressed findings as dismissed at the SARIF layer rather than
 	// dropping them; code-scanning shows them in a "dismissed" state with
 	// the justification visible, preserving the audit trail.
-	if reason, ok := e.suppressions[f.ID]; ok {
+	// An exception attached to the finding itself takes precedence over the
+	// WithSuppressions map.
+	if f.Suppression != nil {
+		result.Suppressions = []SarifSuppression{{
+			Kind:          "external",
+			Status:        "accepted",
+			Justification: f.Suppression.Reason,
+		}}
+		result.Properties["suppression"] = map[string]interface{}{
+			"exceptionId": f.Suppression.ExceptionID,
+			"approvedBy":  f.Suppression.ApprovedBy,
+			"expires":     f.Suppression.Expires.Format("2006-01-02"),
+		}
+	} else if reason, ok := e.suppressions[f.ID]; ok {
 		result.Suppressions = []SarifSuppression{{
 			Kind:          "external",
 			Status:        "accepted",
@@ -367,6 +383,22 @@ func (e *SARIFExporter) buildResult(f finding.Finding, ruleMap map[string]int) S
 	return result
 }
 
+// sarifBaselineState maps a quokka baseline classification onto the SARIF
+// 2.1.0 baselineState vocabulary. "resolved" is "absent" in SARIF terms: the
+// result was in the baseline run but not in this one.
+func sarifBaselineState(s finding.BaselineState) string {
+	switch s {
+	case finding.BaselineNew:
+		return "new"
+	case finding.BaselineUnchanged:
+		return "unchanged"
+	case finding.BaselineResolved:
+		return "absent"
+	default:
+		return ""
+	}
+}
+
 func (e *SARIFExporter) severityToLevel(s finding.Severity) string {
 	switch s {
 	case finding.SeverityCritical, finding.SeverityHigh:
@@ -0,0 +1,188 @@
+package finding
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// HistoryCreated is the Field of the event recorded when a finding is created.
+const HistoryCreated = "created"
+
+// HistoryNote is the Field of the event recorded when a note is appended.
+const HistoryNote = "note"
+
+// HistoryChanged is the New value of an event for a prose field whose old
+// or new text is longer than proseLimit; the text itself is not copied
+// into the log.
+const HistoryChanged = "(changed)"
+
+// proseLimit is the longest description, impact or remediation text kept
+// verbatim in a history event.
+const proseLimit = 80
+
+// HistoryEvent records one change to a finding: who made it, when, through
+// which command or agent, and the old and new value of the field.
+type HistoryEvent struct {
+	Timestamp time.Time `yaml:"timestamp" json:"timestamp"`
+	Actor     string    `yaml:"actor,omitempty" json:"actor,omitempty"`
+	Source    string    `yaml:"source,omitempty" json:"source,omitempty"`
+	Field     string    `yaml:"field" json:"field"`
+	Old       string    `yaml:"old,omitempty" json:"old,omitempty"`
+	New       string    `yaml:"new,omitempty" json:"new,omitempty"`
+}
+
+// Actor identifies who is changing a finding and through what, for the
+// history log.
+type Actor struct {
+	// Name is the person or agent, e.g. "human:alice" or "validation-agent"
+	Name string
+	// Source is the command or component, e.g. "finding triage" or "dashboard"
+	Source string
+}
+
+// StatusHook is called after UpdateBy persists a status change, with the
+// finding as stored before and after the update. Hooks cannot fail the
+// update; they are for side effects such as rule accounting.
+type StatusHook func(before, after *Finding, by Actor)
+
+// OnStatusChange registers a hook run after every persisted status change.
+func (s *Store) OnStatusChange(h StatusHook) {
+	s.statusHooks = append(s.statusHooks, h)
+}
+
+// UpdateBy writes back a finding the caller read and changed, appending a
+// history event for every tracked field that changed. History is owned by
+// the store: whatever History the caller passes in is replaced by the
+// stored log plus the new events, so a full-document PUT cannot rewrite it.
+//
+// If the stored finding was updated after f was read (its UpdatedAt
+// differs), nothing is written and the error wraps ErrConflict: writing f
+// would silently undo the other change. Callers that change a few fields
+// should use Modify, which cannot conflict.
+func (s *Store) UpdateBy(f *Finding, by Actor) error {
+	if err := s.validate(f); err != nil {
+		return err
+	}
+	read := f.UpdatedAt
+	_, err := s.update(f.ID, by, func(stored *Finding) (*Finding, error) {
+		if !read.IsZero() && !stored.UpdatedAt.Equal(read) {
+			return nil, fmt.Errorf("finding '%s' %w since it was read; re-read it and retry", f.ID, ErrConflict)
+		}
+		return f, nil
+	})
+	return err
+}
+
+// Modify applies change to the stored finding and saves the result,
+// recording history like UpdateBy. The read, change and write are one
+// atomic step, so concurrent triage of the same finding is serialized and
+// every change and history event is kept. Returns the finding as saved.
+func (s *Store) Modify(id string, by Actor, change func(f *Finding) error) (*Finding, error) {
+	return s.update(id, by, func(stored *Finding) (*Finding, error) {
+		f := *stored
+		f.Tags = append([]string(nil), stored.Tags...)
+		f.References = append([]string(nil), stored.References...)
+		f.Notes = append([]FindingNote(nil), stored.Notes...)
+		if err := change(&f); err != nil {
+			return nil, err
+		}
+		if err := s.validate(&f); err != nil {
+			return nil, err
+		}
+		return &f, nil
+	})
+}
+
+// update runs one backend update: next returns the new version of the
+// stored finding, to which update adds the history events and timestamps.
+// The events are diffed against, and appended to, the history read inside
+// the backend's atomic update, so an event can never be dropped by a
+// concurrent writer saving an older log. Status hooks run once the write
+// is committed.
+func (s *Store) update(id string, by Actor, next func(stored *Finding) (*Finding, error)) (*Finding, error) {
+	var before, after *Finding
+	err := s.backend.Update(id, func(stored *Finding) (*Finding, error) {
+		f, err := next(stored)
+		if err != nil {
+			return nil, err
+		}
+
+		now := time.Now()
+		history := append([]HistoryEvent(nil), stored.History...)
+		for _, c := range changedFields(stored, f) {
+			history = append(history, HistoryEvent{
+				Timestamp: now,
+				Actor:     by.Name,
+				Source:    by.Source,
+				Field:     c.field,
+				Old:       c.old,
+				New:       c.new,
+			})
+		}
+
+		f.ID = stored.ID
+		f.History = history
+		f.CreatedAt = stored.CreatedAt
+		f.UpdatedAt = now
+		f.Fingerprint = Fingerprint(*f)
+		before, after = stored, f
+		return f, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if before.Status != after.Status {
+		for _, h := range s.statusHooks {
+			h(before, after, by)
+		}
+	}
+	return after, nil
+}
+
+type fieldChange struct {
+	field, old, new string
+}
+
+// changedFields lists the tracked fields that differ between two versions
+// of a finding, plus one entry per appended note. Long prose is recorded
+// as HistoryChanged rather than copied.
+func changedFields(before, after *Finding) []fieldChange {
+	var changes []fieldChange
+	add := func(field, old, new string) {
+		if old != new {
+			changes = append(changes, fieldChange{field, old, new})
+		}
+	}
+	addProse := func(field, old, new string) {
+		if old != new && (len(old) > proseLimit || len(new) > proseLimit) {
+			old, new = "", HistoryChanged
+		}
+		add(field, old, new)
+	}
+	add("title", before.Title, after.Title)
+	add("status", string(before.Status), string(after.Status))
+	add("severity", string(before.Severity), string(after.Severity))
+	add("confidence", string(before.Confidence), string(after.Confidence))
+	add("exploitability", string(before.Exploitability), string(after.Exploitability))
+	add("fix_priority", string(before.FixPriority), string(after.FixPriority))
+	add("duplicate_of", before.DuplicateOf, after.DuplicateOf)
+	add("cwe", before.CWE, after.CWE)
+	add("location", locationString(before.Location), locationString(after.Location))
+	add("tags", strings.Join(before.Tags, ", "), strings.Join(after.Tags, ", "))
+	add("references", strings.Join(before.References, ", "), strings.Join(after.References, ", "))
+	addProse("description", before.Description, after.Description)
+	addProse("impact", before.Impact, after.Impact)
+	addProse("remediation", before.Remediation, after.Remediation)
+
+	if len(after.Notes) > len(before.Notes) {
+		for _, n := range after.Notes[len(before.Notes):] {
+			changes = append(changes, fieldChange{HistoryNote, "", n.Text})
+		}
+	}
+	return changes
+}
+
+func locationString(l Location) string {
+	return fmt.Sprintf("%s:%d", l.File, l.LineStart)
+}
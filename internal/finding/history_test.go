@@ -0,0 +1,207 @@
+package finding
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStoreHistory(t *testing.T) {
+	p, cleanup := setupTestProject(t)
+	defer cleanup()
+
+	store := NewStore(p)
+	f := &Finding{
+		Title:     "SQL injection",
+		Severity:  SeverityHigh,
+		CWE:       "CWE-89",
+		Location:  Location{File: "db.go", LineStart: 10},
+		CreatedBy: "injection-agent",
+	}
+	if _, err := store.Create(f); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if len(f.History) != 1 || f.History[0].Field != HistoryCreated || f.History[0].Actor != "injection-agent" || f.History[0].New != "open" {
+		t.Fatalf("unexpected creation history: %+v", f.History)
+	}
+
+	f.Status = StatusFalsePositive
+	f.Severity = SeverityLow
+	f.Notes = append(f.Notes, FindingNote{Timestamp: time.Now(), Author: "validation-agent", Text: "parameterised by the ORM"})
+	if err := store.UpdateBy(f, Actor{Name: "validation-agent", Source: "finding triage"}); err != nil {
+		t.Fatalf("UpdateBy failed: %v", err)
+	}
+
+	got, _ := store.Read(f.ID)
+	if len(got.History) != 4 {
+		t.Fatalf("expected 4 history events, got %d: %+v", len(got.History), got.History)
+	}
+	want := []HistoryEvent{
+		{Field: "status", Old: "open", New: "false_positive"},
+		{Field: "severity", Old: "high", New: "low"},
+		{Field: HistoryNote, New: "parameterised by the ORM"},
+	}
+	for i, w := range want {
+		e := got.History[i+1]
+		if e.Field != w.Field || e.Old != w.Old || e.New != w.New {
+			t.Errorf("event %d = %s %q -> %q, want %s %q -> %q", i+1, e.Field, e.Old, e.New, w.Field, w.Old, w.New)
+		}
+		if e.Actor != "validation-agent" || e.Source != "finding triage" || e.Timestamp.IsZero() {
+			t.Errorf("event %d attribution = %q via %q at %v", i+1, e.Actor, e.Source, e.Timestamp)
+		}
+	}
+
+	// An update with no tracked change records nothing.
+	if err := store.UpdateBy(got, Actor{Name: "someone"}); err != nil {
+		t.Fatalf("UpdateBy failed: %v", err)
+	}
+	again, _ := store.Read(f.ID)
+	if len(again.History) != 4 {
+		t.Errorf("no-op update added history: %+v", again.History[4:])
+	}
+
+	// Tags and short prose are recorded verbatim, long prose as changed.
+	again.Tags = []string{"sqli", "orm"}
+	again.Impact = "none"
+	again.Description = strings.Repeat("The query is built from request input. ", 4)
+	if err := store.UpdateBy(again, Actor{Name: "human:alice"}); err != nil {
+		t.Fatalf("UpdateBy failed: %v", err)
+	}
+	edited, _ := store.Read(f.ID)
+	want = []HistoryEvent{
+		{Field: "tags", New: "sqli, orm"},
+		{Field: "description", New: HistoryChanged},
+		{Field: "impact", New: "none"},
+	}
+	if len(edited.History) != 4+len(want) {
+		t.Fatalf("expected %d history events, got %d: %+v", 4+len(want), len(edited.History), edited.History)
+	}
+	for i, w := range want {
+		e := edited.History[4+i]
+		if e.Field != w.Field || e.Old != w.Old || e.New != w.New {
+			t.Errorf("event %d = %s %q -> %q, want %s %q -> %q", 4+i, e.Field, e.Old, e.New, w.Field, w.Old, w.New)
+		}
+	}
+}
+
+// TestStoreHistoryNotOverwritable covers full-document updates (the
+// dashboard PUT): the caller's History is ignored, the stored log kept.
+func TestStoreHistoryNotOverwritable(t *testing.T) {
+	p, cleanup := setupTestProject(t)
+	defer cleanup()
+
+	store := NewStore(p)
+	f := &Finding{Title: "XSS", Severity: SeverityMedium, Location: Location{File: "a.go", LineStart: 1}}
+	if _, err := store.Create(f); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	replacement := *f
+	replacement.Status = StatusConfirmed
+	replacement.History = []HistoryEvent{{Field: "status", Old: "nothing", New: "to see here"}}
+	if err := store.Update(&replacement); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	got, _ := store.Read(f.ID)
+	if len(got.History) != 2 || got.History[0].Field != HistoryCreated {
+		t.Fatalf("history rewritten: %+v", got.History)
+	}
+	if e := got.History[1]; e.Field != "status" || e.Old != "open" || e.New != "confirmed" || e.Actor != "" {
+		t.Errorf("unexpected event %+v", e)
+	}
+}
+
+func TestMarkResolvedFixedRecordsHistory(t *testing.T) {
+	p, cleanup := setupTestProject(t)
+	defer cleanup()
+
+	store := NewStore(p)
+	f := &Finding{Title: "Open redirect", Severity: SeverityLow, CWE: "CWE-601", Location: Location{File: "r.go", LineStart: 3}}
+	if _, err := store.Create(f); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if _, err := store.MarkResolvedFixed([]Finding{*f}, "ci"); err != nil {
+		t.Fatalf("MarkResolvedFixed failed: %v", err)
+	}
+
+	got, _ := store.Read(f.ID)
+	last := got.History[len(got.History)-1]
+	if got.History[1].Field != "status" || got.History[1].New != "fixed" || last.Actor != "ci" || last.Source != "finding diff" {
+		t.Errorf("unexpected history: %+v", got.History)
+	}
+}
+
+func TestOnStatusChange(t *testing.T) {
+	p, cleanup := setupTestProject(t)
+	defer cleanup()
+
+	store := NewStore(p)
+	var calls []string
+	store.OnStatusChange(func(before, after *Finding, by Actor) {
+		calls = append(calls, string(before.Status)+"->"+string(after.Status)+" by "+by.Name)
+	})
+
+	f := &Finding{Title: "SSRF", Severity: SeverityHigh, Location: Location{File: "s.go", LineStart: 2}}
+	if _, err := store.Create(f); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	f.Severity = SeverityLow
+	if err := store.UpdateBy(f, Actor{Name: "alice"}); err != nil {
+		t.Fatalf("UpdateBy failed: %v", err)
+	}
+	f.Status = StatusFalsePositive
+	if err := store.UpdateBy(f, Actor{Name: "bob"}); err != nil {
+		t.Fatalf("UpdateBy failed: %v", err)
+	}
+
+	if len(calls) != 1 || calls[0] != "open->false_positive by bob" {
+		t.Errorf("hook calls = %v, want one status change", calls)
+	}
+}
+
+// TestStoreHistoryConcurrent: every writer's history event survives when
+// several agents triage the same finding at once.
+func TestStoreHistoryConcurrent(t *testing.T) {
+	p, cleanup := setupTestProject(t)
+	defer cleanup()
+
+	f := &Finding{Title: "SQL injection", Severity: SeverityHigh, CWE: "CWE-89", Location: Location{File: "db.go", LineStart: 10}}
+	if _, err := NewStore(p).Create(f); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	const workers = 8
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			actor := fmt.Sprintf("agent-%d", w)
+			_, err := NewStore(p).Modify(f.ID, Actor{Name: actor, Source: "test"}, func(f *Finding) error {
+				f.Notes = append(f.Notes, FindingNote{Author: actor, Text: "seen by " + actor})
+				return nil
+			})
+			if err != nil {
+				t.Errorf("Modify: %v", err)
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	got, _ := NewStore(p).Read(f.ID)
+	byActor := map[string]string{}
+	for _, e := range got.History {
+		if e.Field == HistoryNote {
+			byActor[e.Actor] = e.New
+		}
+	}
+	for w := 0; w < workers; w++ {
+		actor := fmt.Sprintf("agent-%d", w)
+		if byActor[actor] != "seen by "+actor {
+			t.Errorf("missing note event from %s; history = %+v", actor, got.History)
+		}
+	}
+}
@@ -0,0 +1,345 @@
+// Package sqlitestore is the SQLite backend for the finding store. Findings
+// live in findings/findings.db with indexed columns for the fields List
+// filters on and the full finding kept as YAML, so the row and the
+// findings/raw file for a finding carry the same data.
+//
+// The package registers itself as the "sqlite" backend on import; the
+// finding package does not depend on it so that the many packages that
+// only read findings do not link the SQLite driver.
+package sqlitestore
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/diffsec/quokka/internal/finding"
+	"gopkg.in/yaml.v3"
+	_ "modernc.org/sqlite"
+)
+
+const (
+	// Name is the backend name used in project.yaml
+	Name = "sqlite"
+	// FileName is the database file inside the findings directory
+	FileName = "findings.db"
+)
+
+func init() {
+	finding.RegisterBackend(Name, func(findingsPath string) (finding.Backend, error) {
+		b, err := New(filepath.Join(findingsPath, FileName))
+		if err != nil {
+			return nil, err
+		}
+		return b, nil
+	})
+}
+
+// Backend stores findings in a SQLite database
+type Backend struct {
+	db   *sql.DB
+	path string
+}
+
+// New opens (creating if needed) the finding database at path
+func New(path string) (*Backend, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	// Parallel agents file findings from separate processes. busy_timeout
+	// makes writers queue instead of failing with SQLITE_BUSY, and
+	// _txlock=immediate takes the write lock at BEGIN so the dedup check
+	// and ID allocation in Insert see every committed finding.
+	dsn := path + "?_pragma=busy_timeout(10000)&_pragma=journal_mode(WAL)&_pragma=synchronous(NORMAL)&_txlock=immediate"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	b := &Backend{db: db, path: path}
+	if err := b.init(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return b, nil
+}
+
+// init creates the database schema
+func (b *Backend) init() error {
+	schema := `
+		CREATE TABLE IF NOT EXISTS findings (
+			id TEXT PRIMARY KEY,
+			seq INTEGER,
+			fingerprint TEXT NOT NULL,
+			created_by TEXT NOT NULL,
+			severity TEXT NOT NULL,
+			status TEXT NOT NULL,
+			confidence TEXT NOT NULL,
+			exploitability TEXT NOT NULL,
+			fix_priority TEXT NOT NULL,
+			cwe TEXT NOT NULL,
+			file TEXT NOT NULL,
+			created_at INTEGER NOT NULL,
+			updated_at INTEGER NOT NULL,
+			data TEXT NOT NULL
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_findings_fingerprint ON findings(fingerprint, created_by);
+		CREATE INDEX IF NOT EXISTS idx_findings_seq ON findings(seq);
+		CREATE INDEX IF NOT EXISTS idx_findings_severity ON findings(severity);
+		CREATE INDEX IF NOT EXISTS idx_findings_status ON findings(status);
+		CREATE INDEX IF NOT EXISTS idx_findings_cwe ON findings(cwe);
+		CREATE INDEX IF NOT EXISTS idx_findings_file ON findings(file);
+	`
+
+	if _, err := b.db.Exec(schema); err != nil {
+		return fmt.Errorf("failed to create schema: %w", err)
+	}
+	return nil
+}
+
+// Path returns the database file path
+func (b *Backend) Path() string {
+	return b.path
+}
+
+// Name returns the backend name
+func (b *Backend) Name() string {
+	return Name
+}
+
+// Get reads a finding by ID
+func (b *Backend) Get(id string) (*finding.Finding, error) {
+	var data string
+	err := b.db.QueryRow("SELECT data FROM findings WHERE id = ?", id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("finding '%s' %w", id, finding.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read finding: %w", err)
+	}
+	return decode(data)
+}
+
+// Insert stores a new finding. The dedup lookup, ID allocation and insert
+// run in one immediate transaction, so concurrent writers are serialized.
+func (b *Backend) Insert(f *finding.Finding) (*finding.Finding, error) {
+	tx, err := b.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if f.CreatedBy != "" {
+		var data string
+		err := tx.QueryRow(
+			"SELECT data FROM findings WHERE fingerprint = ? AND created_by = ? ORDER BY created_at LIMIT 1",
+			f.Fingerprint, f.CreatedBy,
+		).Scan(&data)
+		if err == nil {
+			return decode(data)
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("dedup lookup: %w", err)
+		}
+	}
+
+	allocated := false
+	if f.ID == "" {
+		var next int
+		if err := tx.QueryRow("SELECT COALESCE(MAX(seq), 0) + 1 FROM findings").Scan(&next); err != nil {
+			return nil, fmt.Errorf("failed to allocate finding ID: %w", err)
+		}
+		f.ID = finding.FormatID(next)
+		allocated = true
+	} else {
+		var one int
+		err := tx.QueryRow("SELECT 1 FROM findings WHERE id = ?", f.ID).Scan(&one)
+		if err == nil {
+			return nil, fmt.Errorf("finding '%s' already exists", f.ID)
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("failed to read finding: %w", err)
+		}
+	}
+
+	if err := write(tx, "INSERT", f); err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		if allocated {
+			f.ID = ""
+		}
+		return nil, err
+	}
+	return nil, nil
+}
+
+// Put writes a finding, replacing any row with the same ID
+func (b *Backend) Put(f *finding.Finding) error {
+	return write(b.db, "INSERT OR REPLACE", f)
+}
+
+// Update reads, changes and rewrites a finding in one immediate
+// transaction, so concurrent updates from other processes are serialized.
+func (b *Backend) Update(id string, apply func(stored *finding.Finding) (*finding.Finding, error)) error {
+	tx, err := b.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var data string
+	err = tx.QueryRow("SELECT data FROM findings WHERE id = ?", id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("finding '%s' %w", id, finding.ErrNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read finding: %w", err)
+	}
+	stored, err := decode(data)
+	if err != nil {
+		return err
+	}
+
+	f, err := apply(stored)
+	if err != nil {
+		return err
+	}
+	if f.ID != id {
+		return fmt.Errorf("update of '%s' cannot change its ID to '%s'", id, f.ID)
+	}
+	if err := write(tx, "INSERT OR REPLACE", f); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit update: %w", err)
+	}
+	return nil
+}
+
+// Delete deletes a finding
+func (b *Backend) Delete(id string) error {
+	res, err := b.db.Exec("DELETE FROM findings WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete finding: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("finding '%s' %w", id, finding.ErrNotFound)
+	}
+	return nil
+}
+
+// Query returns the findings matching opts. Every filter except Tag maps to
+// an indexed column; tags are checked after decoding.
+func (b *Backend) Query(opts *finding.FilterOptions) ([]finding.Finding, error) {
+	var where []string
+	var args []any
+	add := func(column, value string) {
+		if value != "" {
+			where = append(where, column+" = ?")
+			args = append(args, value)
+		}
+	}
+	if opts != nil {
+		add("severity", string(opts.Severity))
+		add("status", string(opts.Status))
+		add("confidence", string(opts.Confidence))
+		add("exploitability", string(opts.Exploitability))
+		add("fix_priority", string(opts.FixPriority))
+		add("cwe", opts.CWE)
+		add("file", opts.File)
+		add("created_by", opts.CreatedBy)
+	}
+
+	query := "SELECT data FROM findings"
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+
+	rows, err := b.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query findings: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var findings []finding.Finding
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to scan finding: %w", err)
+		}
+		f, err := decode(data)
+		if err != nil {
+			continue
+		}
+		if opts.Match(f) {
+			findings = append(findings, *f)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query findings: %w", err)
+	}
+	return findings, nil
+}
+
+// Close closes the database
+func (b *Backend) Close() error {
+	return b.db.Close()
+}
+
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// write inserts a finding row with the given verb ("INSERT" or
+// "INSERT OR REPLACE").
+func write(db execer, verb string, f *finding.Finding) error {
+	data, err := yaml.Marshal(f)
+	if err != nil {
+		return fmt.Errorf("failed to marshal finding: %w", err)
+	}
+
+	var seq any
+	if num, ok := finding.ParseIDNumber(f.ID); ok {
+		seq = num
+	}
+
+	_, err = db.Exec(verb+` INTO findings
+		(id, seq, fingerprint, created_by, severity, status, confidence, exploitability, fix_priority, cwe, file, created_at, updated_at, data)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		f.ID,
+		seq,
+		f.Fingerprint,
+		f.CreatedBy,
+		string(f.Severity),
+		string(f.Status),
+		string(f.Confidence),
+		string(f.Exploitability),
+		string(f.FixPriority),
+		f.CWE,
+		f.Location.File,
+		f.CreatedAt.UnixNano(),
+		f.UpdatedAt.UnixNano(),
+		string(data),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to write finding: %w", err)
+	}
+	return nil
+}
+
+func decode(data string) (*finding.Finding, error) {
+	var f finding.Finding
+	if err := yaml.Unmarshal([]byte(data), &f); err != nil {
+		return nil, fmt.Errorf("failed to parse finding: %w", err)
+	}
+	if f.Fingerprint == "" {
+		f.Fingerprint = finding.Fingerprint(f)
+	}
+	return &f, nil
+}
@@ -0,0 +1,300 @@
+package sqlitestore
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/diffsec/quokka/internal/finding"
+	"github.com/diffsec/quokka/internal/project"
+)
+
+func setupSQLiteStore(t *testing.T) (*project.Project, *finding.Store) {
+	t.Helper()
+	p, err := project.Initialize(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to initialize project: %v", err)
+	}
+	p.Config.Findings.Backend = Name
+	store := finding.NewStore(p)
+	t.Cleanup(func() { _ = store.Close() })
+	return p, store
+}
+
+func newFinding(title, file string, sev finding.Severity, createdBy string) *finding.Finding {
+	return &finding.Finding{
+		Title:     title,
+		Severity:  sev,
+		CWE:       "CWE-89",
+		Location:  finding.Location{File: file, LineStart: 10},
+		CreatedBy: createdBy,
+		Tags:      []string{"sqli"},
+	}
+}
+
+func TestStoreCRUD(t *testing.T) {
+	p, store := setupSQLiteStore(t)
+	if store.Backend() != Name {
+		t.Fatalf("Backend() = %q, want %q", store.Backend(), Name)
+	}
+
+	f := newFinding("SQL injection", "db.go", finding.SeverityHigh, "agent")
+	if _, err := store.Create(f); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if f.ID != "FIND-001" || f.Status != finding.StatusOpen {
+		t.Errorf("created %s with status %s", f.ID, f.Status)
+	}
+	if _, err := os.Stat(filepath.Join(p.GetFindingsPath(), FileName)); err != nil {
+		t.Errorf("database not created: %v", err)
+	}
+
+	got, err := store.Read("FIND-001")
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if got.Title != f.Title || got.Fingerprint != f.Fingerprint || !got.CreatedAt.Equal(f.CreatedAt) {
+		t.Errorf("Read = %+v", got)
+	}
+
+	got.Status = finding.StatusConfirmed
+	if err := store.Update(got); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	confirmed, _ := store.List(&finding.FilterOptions{Status: finding.StatusConfirmed})
+	if confirmed.Total != 1 {
+		t.Errorf("confirmed findings = %d, want 1", confirmed.Total)
+	}
+
+	if err := store.Delete("FIND-001"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := store.Read("FIND-001"); !errors.Is(err, finding.ErrNotFound) {
+		t.Errorf("Read after delete = %v, want ErrNotFound", err)
+	}
+	if err := store.Delete("FIND-001"); !errors.Is(err, finding.ErrNotFound) {
+		t.Errorf("second Delete = %v, want ErrNotFound", err)
+	}
+}
+
+func TestStoreDedupAndExplicitID(t *testing.T) {
+	_, store := setupSQLiteStore(t)
+
+	first := newFinding("SQL injection", "db.go", finding.SeverityHigh, "agent")
+	if _, err := store.Create(first); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	dup := newFinding("SQL Injection!", "db.go", finding.SeverityHigh, "agent")
+	if _, err := store.Create(dup); err != nil {
+		t.Fatalf("Create duplicate: %v", err)
+	}
+	if dup.ID != first.ID {
+		t.Errorf("duplicate got ID %s, want existing %s", dup.ID, first.ID)
+	}
+	other := newFinding("SQL injection", "db.go", finding.SeverityHigh, "other-agent")
+	if _, err := store.Create(other); err != nil {
+		t.Fatalf("Create other creator: %v", err)
+	}
+	if other.ID != "FIND-002" {
+		t.Errorf("other creator got ID %s, want FIND-002", other.ID)
+	}
+
+	custom := newFinding("Custom", "x.go", finding.SeverityLow, "")
+	custom.ID = "FIND-010"
+	if _, err := store.Create(custom); err != nil {
+		t.Fatalf("Create with ID: %v", err)
+	}
+	again := newFinding("Custom again", "y.go", finding.SeverityLow, "")
+	again.ID = "FIND-010"
+	if _, err := store.Create(again); err == nil {
+		t.Error("expected error for existing ID")
+	}
+	next := newFinding("Next", "z.go", finding.SeverityLow, "")
+	if _, err := store.Create(next); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if next.ID != "FIND-011" {
+		t.Errorf("next ID = %s, want FIND-011", next.ID)
+	}
+}
+
+func TestStoreFilters(t *testing.T) {
+	_, store := setupSQLiteStore(t)
+
+	for _, f := range []*finding.Finding{
+		newFinding("a", "db.go", finding.SeverityCritical, "x"),
+		newFinding("b", "web.go", finding.SeverityLow, "x"),
+		newFinding("c", "web.go", finding.SeverityCritical, "y"),
+	} {
+		if _, err := store.Create(f); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	tests := []struct {
+		opts finding.FilterOptions
+		want int
+	}{
+		{finding.FilterOptions{Severity: finding.SeverityCritical}, 2},
+		{finding.FilterOptions{File: "web.go"}, 2},
+		{finding.FilterOptions{File: "web.go", CreatedBy: "y"}, 1},
+		{finding.FilterOptions{CWE: "CWE-89", Tag: "SQLI"}, 3},
+		{finding.FilterOptions{Tag: "xss"}, 0},
+	}
+	for _, tt := range tests {
+		list, err := store.List(&tt.opts)
+		if err != nil {
+			t.Fatalf("List(%+v): %v", tt.opts, err)
+		}
+		if list.Total != tt.want {
+			t.Errorf("List(%+v) = %d findings, want %d", tt.opts, list.Total, tt.want)
+		}
+	}
+
+	all, _ := store.List(nil)
+	if all.Findings[0].Severity != finding.SeverityCritical || all.Findings[2].Severity != finding.SeverityLow {
+		t.Errorf("List not sorted by severity: %v, %v", all.Findings[0].Severity, all.Findings[2].Severity)
+	}
+}
+
+// TestStoreConcurrentCreate files findings from many goroutines, each with
+// its own connection pool as separate agent processes would have, and
+// checks every finding got a distinct ID.
+func TestStoreConcurrentCreate(t *testing.T) {
+	p, _ := setupSQLiteStore(t)
+
+	const workers, perWorker = 6, 10
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*perWorker)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			store := finding.NewStore(p)
+			defer func() { _ = store.Close() }()
+			for i := 0; i < perWorker; i++ {
+				f := newFinding(fmt.Sprintf("finding %d-%d", w, i), fmt.Sprintf("f%d_%d.go", w, i), finding.SeverityMedium, "agent")
+				if _, err := store.Create(f); err != nil {
+					errs <- err
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("Create: %v", err)
+	}
+
+	store := finding.NewStore(p)
+	defer func() { _ = store.Close() }()
+	list, err := store.List(nil)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	seen := map[string]bool{}
+	for _, f := range list.Findings {
+		if seen[f.ID] {
+			t.Errorf("duplicate ID %s", f.ID)
+		}
+		seen[f.ID] = true
+	}
+	if len(seen) != workers*perWorker || !seen[finding.FormatID(workers*perWorker)] {
+		t.Errorf("got %d distinct IDs, want FIND-001..%s", len(seen), finding.FormatID(workers*perWorker))
+	}
+}
+
+// TestStoreConcurrentModify updates one finding from many goroutines, each
+// with its own connection pool, and checks no update was lost.
+func TestStoreConcurrentModify(t *testing.T) {
+	p, store := setupSQLiteStore(t)
+	f := newFinding("SQL injection", "db.go", finding.SeverityHigh, "agent")
+	if _, err := store.Create(f); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	const workers = 6
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			store := finding.NewStore(p)
+			defer func() { _ = store.Close() }()
+			_, err := store.Modify(f.ID, finding.Actor{Name: fmt.Sprintf("agent-%d", w)}, func(f *finding.Finding) error {
+				f.Notes = append(f.Notes, finding.FindingNote{Author: fmt.Sprintf("agent-%d", w), Text: "checked"})
+				return nil
+			})
+			if err != nil {
+				t.Errorf("Modify: %v", err)
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	got, err := store.Read(f.ID)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if len(got.Notes) != workers {
+		t.Errorf("got %d notes, want %d", len(got.Notes), workers)
+	}
+	notes := 0
+	for _, e := range got.History {
+		if e.Field == finding.HistoryNote {
+			notes++
+		}
+	}
+	if notes != workers {
+		t.Errorf("got %d note history events, want %d", notes, workers)
+	}
+}
+
+func TestMigrateFromYAML(t *testing.T) {
+	p, err := project.Initialize(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to initialize project: %v", err)
+	}
+	yamlStore := finding.NewStore(p)
+	for _, f := range []*finding.Finding{
+		newFinding("a", "db.go", finding.SeverityHigh, "x"),
+		newFinding("b", "web.go", finding.SeverityLow, "x"),
+	} {
+		if _, err := yamlStore.Create(f); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+	before, _ := yamlStore.List(nil)
+
+	src, _ := finding.OpenBackend(p, finding.DefaultBackend)
+	dst, err := finding.OpenBackend(p, Name)
+	if err != nil {
+		t.Fatalf("OpenBackend: %v", err)
+	}
+	defer func() { _ = dst.Close() }()
+
+	ids, err := finding.Migrate(src, dst)
+	if err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	if len(ids) != 2 || ids[0] != "FIND-001" {
+		t.Errorf("migrated %v", ids)
+	}
+	if _, err := finding.Migrate(src, dst); err == nil {
+		t.Error("expected second migration into a non-empty store to fail")
+	}
+
+	after, err := finding.NewStoreWithBackend(p, dst).List(nil)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	for i := range before.Findings {
+		b, a := before.Findings[i], after.Findings[i]
+		if a.ID != b.ID || a.Fingerprint != b.Fingerprint || !a.CreatedAt.Equal(b.CreatedAt) || !a.UpdatedAt.Equal(b.UpdatedAt) {
+			t.Errorf("finding %d changed in migration: %+v -> %+v", i, b, a)
+		}
+	}
+}
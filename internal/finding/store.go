@@ -5,7 +5,6 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
-	"strconv"
 	"strings"
 	"time"
 
@@ -13,71 +12,70 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
-// Store handles finding CRUD operations
+// Store handles finding CRUD operations. Persistence is delegated to the
+// Backend selected in project.yaml; the Store owns validation,
+// fingerprinting, timestamps and ordering so every backend behaves alike.
 type Store struct {
-	basePath    string
-	rawPath     string
+	backend     Backend
 	exportsPath string
+	statusHooks []StatusHook
 }
 
-// NewStore creates a new finding store for the given project
+// NewStore creates a new finding store for the given project. If the
+// configured backend cannot be opened, every operation on the store
+// returns the open error.
 func NewStore(p *project.Project) *Store {
-	basePath := p.GetFindingsPath()
+	name := ConfiguredBackend(p)
+	backend, err := OpenBackend(p, name)
+	if err != nil {
+		backend = failedBackend{name: name, err: fmt.Errorf("open %s finding store: %w", name, err)}
+	}
+	return NewStoreWithBackend(p, backend)
+}
+
+// NewStoreWithBackend creates a finding store on an already opened backend
+func NewStoreWithBackend(p *project.Project, backend Backend) *Store {
 	return &Store{
-		basePath:    basePath,
-		rawPath:     filepath.Join(basePath, project.RawDir),
-		exportsPath: filepath.Join(basePath, project.ExportsDir),
+		backend:     backend,
+		exportsPath: filepath.Join(p.GetFindingsPath(), project.ExportsDir),
 	}
 }
 
+// Backend returns the name of the backend holding the findings
+func (s *Store) Backend() string {
+	return s.backend.Name()
+}
+
+// Close releases the backend
+func (s *Store) Close() error {
+	return s.backend.Close()
+}
+
 // Create creates a new finding.
 //
 // Dedup: if a finding with the same fingerprint AND the same created_by
-// already exists in the store, Create silently no-ops and the caller's
-// passed-in struct is mutated to reflect the existing finding (ID,
-// timestamps, fingerprint). This matches what callers reasonably expect
+// already exists in the store, Create writes nothing, returns existed=true
+// and the caller's passed-in struct is mutated to reflect the existing
+// finding (ID, timestamps, fingerprint). This matches what callers reasonably expect
 // when an LLM agent files the same vuln twice in a single run — the
 // store should hold one row, not N. Dedup is scoped to "same creator"
 // deliberately: when two different agents independently flag the same
 // vuln, that's a confidence signal worth preserving until the validation
 // phase consolidates them.
-func (s *Store) Create(f *Finding) error {
+//
+// CreatedBy="" findings (legacy / unattributed) skip dedup since the
+// match would be too coarse — any two unattributed findings on the
+// same CWE+file would collapse, which discards signal.
+func (s *Store) Create(f *Finding) (existed bool, err error) {
 	// Validate first so a malformed input can't even attempt dedup.
 	if err := s.validate(f); err != nil {
-		return err
+		return false, err
 	}
 
 	// Compute fingerprint up-front so dedup check sees the same hash
 	// that gets persisted (no double computation).
 	f.Fingerprint = Fingerprint(*f)
 
-	// Dedup: same fingerprint + same created_by → return existing.
-	// CreatedBy="" findings (legacy / unattributed) skip dedup since the
-	// match would be too coarse — any two unattributed findings on the
-	// same CWE+file would collapse, which discards signal.
-	if f.CreatedBy != "" {
-		if existing, found, err := s.findByFingerprintAndCreator(f.Fingerprint, f.CreatedBy); err != nil {
-			return err
-		} else if found {
-			*f = *existing
-			return nil
-		}
-	}
-
-	// Generate ID if not provided
-	if f.ID == "" {
-		id, err := s.generateID()
-		if err != nil {
-			return err
-		}
-		f.ID = id
-	}
-
-	// Check if already exists
-	if _, err := s.Read(f.ID); err == nil {
-		return fmt.Errorf("finding '%s' already exists", f.ID)
-	}
-
 	// Set defaults and timestamps
 	if f.Status == "" {
 		f.Status = StatusOpen
@@ -85,150 +83,54 @@ func (s *Store) Create(f *Finding) error {
 	now := time.Now()
 	f.CreatedAt = now
 	f.UpdatedAt = now
-
-	return s.save(f)
-}
-
-// findByFingerprintAndCreator returns the first stored finding with the
-// given fingerprint+creator pair. Used by Create() to no-op a duplicate
-// file from the same agent. Returns (nil, false, nil) when no match
-// exists — that's the common case.
-func (s *Store) findByFingerprintAndCreator(fingerprint, createdBy string) (*Finding, bool, error) {
-	if fingerprint == "" || createdBy == "" {
-		return nil, false, nil
-	}
-	result, err := s.List(&FilterOptions{CreatedBy: createdBy})
+	f.History = []HistoryEvent{{
+		Timestamp: now,
+		Actor:     f.CreatedBy,
+		Field:     HistoryCreated,
+		New:       string(f.Status),
+	}}
+
+	// The backend performs dedup, ID allocation and the write as one step
+	// so parallel agents filing at the same time cannot collide.
+	existing, err := s.backend.Insert(f)
 	if err != nil {
-		return nil, false, fmt.Errorf("dedup lookup: %w", err)
+		return false, err
 	}
-	for i := range result.Findings {
-		existing := &result.Findings[i]
-		// Some stored findings may pre-date the fingerprint field; recompute
-		// rather than trust .Fingerprint blindly so dedup catches them too.
-		fp := existing.Fingerprint
-		if fp == "" {
-			fp = Fingerprint(*existing)
-		}
-		if fp == fingerprint {
-			return existing, true, nil
-		}
+	if existing != nil {
+		*f = *existing
+		return true, nil
 	}
-	return nil, false, nil
+	return false, nil
 }
 
 // Read reads a finding by ID
 func (s *Store) Read(id string) (*Finding, error) {
-	path := s.getPath(id)
-	data, err := os.ReadFile(path)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, fmt.Errorf("finding '%s' not found", id)
-		}
-		return nil, fmt.Errorf("failed to read finding: %w", err)
-	}
-
-	var f Finding
-	if err := yaml.Unmarshal(data, &f); err != nil {
-		return nil, fmt.Errorf("failed to parse finding: %w", err)
-	}
-
-	if f.Fingerprint == "" {
-		f.Fingerprint = Fingerprint(f)
-	}
-
-	return &f, nil
+	return s.backend.Get(id)
 }
 
-// Update updates an existing finding
+// Update updates an existing finding. Changes are recorded in the history
+// without an actor; callers that know who is acting should use UpdateBy.
 func (s *Store) Update(f *Finding) error {
-	// Check if exists
-	existing, err := s.Read(f.ID)
-	if err != nil {
-		return err
-	}
-
-	// Validate
-	if err := s.validate(f); err != nil {
-		return err
-	}
-
-	// Preserve created_at
-	f.CreatedAt = existing.CreatedAt
-	f.UpdatedAt = time.Now()
-	f.Fingerprint = Fingerprint(*f)
-
-	return s.save(f)
+	return s.UpdateBy(f, Actor{})
 }
 
 // Delete deletes a finding
 func (s *Store) Delete(id string) error {
-	path := s.getPath(id)
-	if err := os.Remove(path); err != nil {
-		if os.IsNotExist(err) {
-			return fmt.Errorf("finding '%s' not found", id)
-		}
-		return fmt.Errorf("failed to delete finding: %w", err)
-	}
-	return nil
+	return s.backend.Delete(id)
 }
 
-// List lists all findings, optionally filtered
+// List lists all findings, optionally filtered. Findings are sorted by
+// severity (critical first), then by created_at (newest first); Total is
+// the number of matches before Offset and Limit are applied.
 func (s *Store) List(opts *FilterOptions) (*FindingList, error) {
-	entries, err := os.ReadDir(s.rawPath)
+	findings, err := s.backend.Query(opts)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return &FindingList{Findings: []Finding{}, Total: 0}, nil
-		}
-		return nil, fmt.Errorf("failed to read directory: %w", err)
+		return nil, err
 	}
-
-	var findings []Finding
-	for _, entry := range entries {
-		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".yaml") {
-			continue
-		}
-
-		id := strings.TrimSuffix(entry.Name(), ".yaml")
-		f, err := s.Read(id)
-		if err != nil {
-			continue
-		}
-
-		// Apply filters
-		if opts != nil {
-			if opts.Severity != "" && f.Severity != opts.Severity {
-				continue
-			}
-			if opts.Status != "" && f.Status != opts.Status {
-				continue
-			}
-			if opts.Confidence != "" && f.Confidence != opts.Confidence {
-				continue
-			}
-			if opts.Exploitability != "" && f.Exploitability != opts.Exploitability {
-				continue
-			}
-			if opts.FixPriority != "" && f.FixPriority != opts.FixPriority {
-				continue
-			}
-			if opts.CWE != "" && f.CWE != opts.CWE {
-				continue
-			}
-			if opts.File != "" && f.Location.File != opts.File {
-				continue
-			}
-			if opts.Tag != "" && !containsTag(f.Tags, opts.Tag) {
-				continue
-			}
-			if opts.CreatedBy != "" && f.CreatedBy != opts.CreatedBy {
-				continue
-			}
-		}
-
-		findings = append(findings, *f)
+	if findings == nil {
+		findings = []Finding{}
 	}
 
-	// Sort by severity (critical first), then by created_at (newest first)
 	sort.Slice(findings, func(i, j int) bool {
 		wi := SeverityWeight(findings[i].Severity)
 		wj := SeverityWeight(findings[j].Severity)
@@ -238,9 +140,19 @@ func (s *Store) List(opts *FilterOptions) (*FindingList, error) {
 		return findings[i].CreatedAt.After(findings[j].CreatedAt)
 	})
 
+	total := len(findings)
+	if opts != nil {
+		if opts.Offset > 0 {
+			findings = findings[min(opts.Offset, len(findings)):]
+		}
+		if opts.Limit > 0 && opts.Limit < len(findings) {
+			findings = findings[:opts.Limit]
+		}
+	}
+
 	return &FindingList{
 		Findings: findings,
-		Total:    len(findings),
+		Total:    total,
 	}, nil
 }
 
@@ -315,7 +227,7 @@ func (s *Store) Import(path string) (*Finding, error) {
 
 	// Generate new ID if importing
 	f.ID = ""
-	if err := s.Create(&f); err != nil {
+	if _, err := s.Create(&f); err != nil {
 		return nil, err
 	}
 
@@ -327,59 +239,6 @@ func (s *Store) GetExportsPath() string {
 	return s.exportsPath
 }
 
-// save writes a finding to disk
-func (s *Store) save(f *Finding) error {
-	path := s.getPath(f.ID)
-
-	// Ensure directory exists
-	if err := os.MkdirAll(s.rawPath, 0755); err != nil {
-		return fmt.Errorf("failed to create directory: %w", err)
-	}
-
-	data, err := yaml.Marshal(f)
-	if err != nil {
-		return fmt.Errorf("failed to marshal finding: %w", err)
-	}
-
-	if err := os.WriteFile(path, data, 0644); err != nil {
-		return fmt.Errorf("failed to write finding: %w", err)
-	}
-
-	return nil
-}
-
-// getPath returns the file path for a finding
-func (s *Store) getPath(id string) string {
-	return filepath.Join(s.rawPath, id+".yaml")
-}
-
-// generateID generates a new finding ID
-func (s *Store) generateID() (string, error) {
-	entries, err := os.ReadDir(s.rawPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return "FIND-001", nil
-		}
-		return "", fmt.Errorf("failed to read directory: %w", err)
-	}
-
-	maxNum := 0
-	for _, entry := range entries {
-		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".yaml") {
-			continue
-		}
-		name := strings.TrimSuffix(entry.Name(), ".yaml")
-		if strings.HasPrefix(name, "FIND-") {
-			numStr := strings.TrimPrefix(name, "FIND-")
-			if num, err := strconv.Atoi(numStr); err == nil && num > maxNum {
-				maxNum = num
-			}
-		}
-	}
-
-	return fmt.Sprintf("FIND-%03d", maxNum+1), nil
-}
-
 // validate validates a finding. It normalizes case for severity/confidence
 // to lowercase, defaults empty confidence to "medium", and rejects invalid
 // values. It also logs a warning to stderr when CWE is empty.
@@ -1,8 +1,11 @@
 package finding
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/diffsec/quokka/internal/project"
@@ -46,7 +49,7 @@ func TestStoreCreate(t *testing.T) {
 		Description: "User input in SQL query",
 	}
 
-	err := store.Create(f)
+	_, err := store.Create(f)
 	if err != nil {
 		t.Fatalf("Create failed: %v", err)
 	}
@@ -90,8 +93,8 @@ func TestStoreCreateDedupSameCreator(t *testing.T) {
 		CreatedBy:  "security-agent",
 		Location:   Location{File: "src/api/users.py", LineStart: 42},
 	}
-	if err := store.Create(first); err != nil {
-		t.Fatalf("first Create: %v", err)
+	if existed, err := store.Create(first); err != nil || existed {
+		t.Fatalf("first Create: existed=%v err=%v", existed, err)
 	}
 	if first.ID != "FIND-001" {
 		t.Fatalf("first ID: got %q, want FIND-001", first.ID)
@@ -108,9 +111,13 @@ func TestStoreCreateDedupSameCreator(t *testing.T) {
 		CreatedBy:  "security-agent",
 		Location:   Location{File: "src/api/users.py", LineStart: 45},
 	}
-	if err := store.Create(dup); err != nil {
+	existed, err := store.Create(dup)
+	if err != nil {
 		t.Fatalf("dup Create: %v", err)
 	}
+	if !existed {
+		t.Error("dup Create did not report the existing finding")
+	}
 	if dup.ID != first.ID {
 		t.Errorf("dedup did not return existing ID: got %q, want %q", dup.ID, first.ID)
 	}
@@ -147,10 +154,10 @@ func TestStoreCreateDedupDifferentCreatorsCoexist(t *testing.T) {
 
 	a := mk("security-agent")
 	b := mk("injection-agent")
-	if err := store.Create(a); err != nil {
+	if _, err := store.Create(a); err != nil {
 		t.Fatalf("first Create: %v", err)
 	}
-	if err := store.Create(b); err != nil {
+	if _, err := store.Create(b); err != nil {
 		t.Fatalf("second Create: %v", err)
 	}
 	if a.ID == b.ID {
@@ -188,10 +195,10 @@ func TestStoreCreateDedupSkipsEmptyCreator(t *testing.T) {
 
 	a := mk()
 	b := mk()
-	if err := store.Create(a); err != nil {
+	if _, err := store.Create(a); err != nil {
 		t.Fatalf("first Create: %v", err)
 	}
-	if err := store.Create(b); err != nil {
+	if _, err := store.Create(b); err != nil {
 		t.Fatalf("second Create: %v", err)
 	}
 	if a.ID == b.ID {
@@ -212,7 +219,7 @@ func TestStoreCreateWithID(t *testing.T) {
 		Location: Location{File: "test.go", LineStart: 1},
 	}
 
-	err := store.Create(f)
+	_, err := store.Create(f)
 	if err != nil {
 		t.Fatalf("Create failed: %v", err)
 	}
@@ -229,7 +236,7 @@ func TestStoreCreateValidation(t *testing.T) {
 	store := NewStore(p)
 
 	// Missing title
-	err := store.Create(&Finding{
+	_, err := store.Create(&Finding{
 		Severity: SeverityHigh,
 		Location: Location{File: "test.go", LineStart: 1},
 	})
@@ -238,7 +245,7 @@ func TestStoreCreateValidation(t *testing.T) {
 	}
 
 	// Missing file
-	err = store.Create(&Finding{
+	_, err = store.Create(&Finding{
 		Title:    "Test",
 		Severity: SeverityHigh,
 		Location: Location{LineStart: 1},
@@ -248,7 +255,7 @@ func TestStoreCreateValidation(t *testing.T) {
 	}
 
 	// Invalid severity
-	err = store.Create(&Finding{
+	_, err = store.Create(&Finding{
 		Title:    "Test",
 		Severity: "invalid",
 		Location: Location{File: "test.go", LineStart: 1},
@@ -282,7 +289,7 @@ func TestStoreRead(t *testing.T) {
 		Tags:        []string{"xss", "owasp-top-10"},
 	}
 
-	err := store.Create(original)
+	_, err := store.Create(original)
 	if err != nil {
 		t.Fatalf("Create failed: %v", err)
 	}
@@ -335,7 +342,7 @@ func TestStoreUpdate(t *testing.T) {
 		Location: Location{File: "test.go", LineStart: 1},
 	}
 
-	err := store.Create(f)
+	_, err := store.Create(f)
 	if err != nil {
 		t.Fatalf("Create failed: %v", err)
 	}
@@ -370,6 +377,79 @@ func TestStoreUpdate(t *testing.T) {
 	}
 }
 
+// TestStoreUpdateConflict: writing back a copy read before another update
+// fails instead of undoing that update.
+func TestStoreUpdateConflict(t *testing.T) {
+	p, cleanup := setupTestProject(t)
+	defer cleanup()
+
+	store := NewStore(p)
+	f := &Finding{Title: "Test Finding", Severity: SeverityMedium, Location: Location{File: "test.go", LineStart: 1}}
+	if _, err := store.Create(f); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	stale, _ := store.Read(f.ID)
+	if _, err := store.Modify(f.ID, Actor{Name: "a"}, func(f *Finding) error {
+		f.Status = StatusConfirmed
+		return nil
+	}); err != nil {
+		t.Fatalf("Modify failed: %v", err)
+	}
+
+	stale.Severity = SeverityLow
+	if err := store.Update(stale); !errors.Is(err, ErrConflict) {
+		t.Fatalf("Update of stale copy: err = %v, want ErrConflict", err)
+	}
+	got, _ := store.Read(f.ID)
+	if got.Status != StatusConfirmed || got.Severity != SeverityMedium {
+		t.Errorf("stored = %s/%s, want confirmed/medium", got.Status, got.Severity)
+	}
+
+	if _, err := store.Modify("FIND-999", Actor{}, func(*Finding) error { return nil }); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Modify of missing finding: err = %v, want ErrNotFound", err)
+	}
+}
+
+// TestStoreConcurrentModify: parallel updates of one finding are applied one
+// after another, so none of them is lost.
+func TestStoreConcurrentModify(t *testing.T) {
+	p, cleanup := setupTestProject(t)
+	defer cleanup()
+
+	f := &Finding{Title: "Test Finding", Severity: SeverityMedium, Location: Location{File: "test.go", LineStart: 1}}
+	if _, err := NewStore(p).Create(f); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	const workers = 8
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			store := NewStore(p)
+			defer func() { _ = store.Close() }()
+			_, err := store.Modify(f.ID, Actor{Name: fmt.Sprintf("agent-%d", w)}, func(f *Finding) error {
+				f.Notes = append(f.Notes, FindingNote{Author: fmt.Sprintf("agent-%d", w), Text: "checked"})
+				return nil
+			})
+			if err != nil {
+				t.Errorf("Modify: %v", err)
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	got, err := NewStore(p).Read(f.ID)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(got.Notes) != workers {
+		t.Errorf("got %d notes, want %d", len(got.Notes), workers)
+	}
+}
+
 func TestStoreDelete(t *testing.T) {
 	p, cleanup := setupTestProject(t)
 	defer cleanup()
@@ -382,7 +462,7 @@ func TestStoreDelete(t *testing.T) {
 		Location: Location{File: "test.go", LineStart: 1},
 	}
 
-	err := store.Create(f)
+	_, err := store.Create(f)
 	if err != nil {
 		t.Fatalf("Create failed: %v", err)
 	}
@@ -414,7 +494,7 @@ func TestStoreList(t *testing.T) {
 	}
 
 	for _, f := range findings {
-		if err := store.Create(f); err != nil {
+		if _, err := store.Create(f); err != nil {
 			t.Fatalf("Create failed: %v", err)
 		}
 	}
@@ -470,7 +550,7 @@ func TestStoreStats(t *testing.T) {
 	}
 
 	for _, f := range findings {
-		if err := store.Create(f); err != nil {
+		if _, err := store.Create(f); err != nil {
 			t.Fatalf("Create failed: %v", err)
 		}
 	}
@@ -519,7 +599,7 @@ func TestStoreIDGeneration(t *testing.T) {
 			Severity: SeverityInfo,
 			Location: Location{File: "test.go", LineStart: 1},
 		}
-		if err := store.Create(f); err != nil {
+		if _, err := store.Create(f); err != nil {
 			t.Fatalf("Create failed: %v", err)
 		}
 
@@ -560,7 +640,7 @@ func TestStoreFlowTrace(t *testing.T) {
 		ReviewedBy: []string{"validation-agent", "review-agent"},
 	}
 
-	err := store.Create(f)
+	_, err := store.Create(f)
 	if err != nil {
 		t.Fatalf("Create failed: %v", err)
 	}
@@ -609,7 +689,7 @@ func TestStoreStatsWithCreatedBy(t *testing.T) {
 	}
 
 	for _, f := range findings {
-		if err := store.Create(f); err != nil {
+		if _, err := store.Create(f); err != nil {
 			t.Fatalf("Create failed: %v", err)
 		}
 	}
@@ -666,7 +746,7 @@ func TestStoreValidateLineStart(t *testing.T) {
 	store := NewStore(p)
 
 	// line_start = 0 should fail
-	err := store.Create(&Finding{
+	_, err := store.Create(&Finding{
 		Title:    "Bad line_start zero",
 		Severity: SeverityHigh,
 		Location: Location{File: "test.go", LineStart: 0},
@@ -678,7 +758,7 @@ func TestStoreValidateLineStart(t *testing.T) {
 	}
 
 	// negative line_start should fail
-	err = store.Create(&Finding{
+	_, err = store.Create(&Finding{
 		Title:    "Bad line_start negative",
 		Severity: SeverityHigh,
 		Location: Location{File: "test.go", LineStart: -5},
@@ -688,7 +768,7 @@ func TestStoreValidateLineStart(t *testing.T) {
 	}
 
 	// positive line_start should succeed
-	err = store.Create(&Finding{
+	_, err = store.Create(&Finding{
 		Title:    "Good line_start",
 		Severity: SeverityHigh,
 		Location: Location{File: "test.go", LineStart: 1},
@@ -710,7 +790,7 @@ func TestStoreValidateSeverityCaseInsensitive(t *testing.T) {
 		Severity: "HIGH",
 		Location: Location{File: "test.go", LineStart: 1},
 	}
-	if err := store.Create(f); err != nil {
+	if _, err := store.Create(f); err != nil {
 		t.Fatalf("expected success for HIGH severity, got %v", err)
 	}
 	if f.Severity != SeverityHigh {
@@ -727,7 +807,7 @@ func TestStoreValidateSeverityCaseInsensitive(t *testing.T) {
 	}
 
 	// Unknown severity rejected
-	err = store.Create(&Finding{
+	_, err = store.Create(&Finding{
 		Title:    "Unknown severity",
 		Severity: "scary",
 		Location: Location{File: "test.go", LineStart: 1},
@@ -749,7 +829,7 @@ func TestStoreValidateConfidence(t *testing.T) {
 		Severity: SeverityHigh,
 		Location: Location{File: "test.go", LineStart: 1},
 	}
-	if err := store.Create(f); err != nil {
+	if _, err := store.Create(f); err != nil {
 		t.Fatalf("Create failed: %v", err)
 	}
 	if f.Confidence != ConfidenceMedium {
@@ -757,7 +837,7 @@ func TestStoreValidateConfidence(t *testing.T) {
 	}
 
 	// Invalid confidence rejected
-	err := store.Create(&Finding{
+	_, err := store.Create(&Finding{
 		Title:      "Bad confidence",
 		Severity:   SeverityHigh,
 		Confidence: "verymuch",
@@ -774,7 +854,7 @@ func TestStoreValidateConfidence(t *testing.T) {
 		Confidence: "HIGH",
 		Location:   Location{File: "test.go", LineStart: 1},
 	}
-	if err := store.Create(f2); err != nil {
+	if _, err := store.Create(f2); err != nil {
 		t.Fatalf("Create failed: %v", err)
 	}
 	if f2.Confidence != ConfidenceHigh {
@@ -789,7 +869,7 @@ func TestStoreValidateEmptyCWEAccepted(t *testing.T) {
 	store := NewStore(p)
 
 	// Empty CWE should be accepted (just warn)
-	err := store.Create(&Finding{
+	_, err := store.Create(&Finding{
 		Title:    "No CWE",
 		Severity: SeverityHigh,
 		Location: Location{File: "test.go", LineStart: 1},
@@ -812,7 +892,7 @@ func TestStoreDuplicateStatusRoundTrip(t *testing.T) {
 		CWE:      "CWE-89",
 		Location: Location{File: "db.go", LineStart: 10},
 	}
-	if err := store.Create(canonical); err != nil {
+	if _, err := store.Create(canonical); err != nil {
 		t.Fatalf("Create canonical failed: %v", err)
 	}
 
@@ -823,7 +903,7 @@ func TestStoreDuplicateStatusRoundTrip(t *testing.T) {
 		CWE:      "CWE-89",
 		Location: Location{File: "db.go", LineStart: 10},
 	}
-	if err := store.Create(dup); err != nil {
+	if _, err := store.Create(dup); err != nil {
 		t.Fatalf("Create duplicate failed: %v", err)
 	}
 
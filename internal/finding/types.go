@@ -174,6 +174,22 @@ type Finding struct {
 	UpdatedAt      time.Time      `yaml:"updated_at" json:"updated_at"`
 	CreatedBy      string         `yaml:"created_by,omitempty" json:"created_by,omitempty"`
 	DuplicateOf    string         `yaml:"duplicate_of,omitempty" json:"duplicate_of,omitempty"`
+	History        []HistoryEvent `yaml:"history,omitempty" json:"history,omitempty"`
+
+	// BaselineState is set by DiffBaseline when comparing runs. It is
+	// never persisted; exporters surface it when present.
+	BaselineState BaselineState `yaml:"-" json:"baseline_state,omitempty"`
+	// Suppression is set when an exception in .quokka/exceptions.yaml
+	// matches this finding (see exception.Store.Annotate). Never persisted.
+	Suppression *Suppression `yaml:"-" json:"suppressed_by,omitempty"`
+}
+
+// Suppression describes the exception that hides a finding.
+type Suppression struct {
+	ExceptionID string    `json:"exception_id"`
+	Reason      string    `json:"reason"`
+	ApprovedBy  string    `json:"approved_by"`
+	Expires     time.Time `json:"expires"`
 }
 
 // FindingList represents a list of findings with metadata
@@ -0,0 +1,356 @@
+package finding
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/diffsec/quokka/internal/project"
+	"gopkg.in/yaml.v3"
+)
+
+// maxIDAttempts bounds how often Insert retries when a concurrent writer
+// claims the ID it just allocated.
+const maxIDAttempts = 50
+
+// lockTimeout bounds how long Insert and Update wait for another writer's
+// lock. A lock older than lockStale was left by a process that died holding
+// it and is broken.
+const (
+	lockTimeout = 10 * time.Second
+	lockStale   = 30 * time.Second
+)
+
+// yamlBackend keeps one YAML file per finding under findings/raw. It is the
+// original store layout and remains the default; it is also what
+// `quokka finding migrate --to yaml` writes, so the layout doubles as an
+// export format for the other backends.
+type yamlBackend struct {
+	rawPath string
+}
+
+func newYAMLBackend(findingsPath string) *yamlBackend {
+	return &yamlBackend{rawPath: filepath.Join(findingsPath, project.RawDir)}
+}
+
+// Name returns the backend name
+func (b *yamlBackend) Name() string {
+	return DefaultBackend
+}
+
+// Get reads a finding by ID
+func (b *yamlBackend) Get(id string) (*Finding, error) {
+	data, err := os.ReadFile(b.getPath(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("finding '%s' %w", id, ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to read finding: %w", err)
+	}
+
+	var f Finding
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse finding: %w", err)
+	}
+
+	// Some stored findings may pre-date the fingerprint field.
+	if f.Fingerprint == "" {
+		f.Fingerprint = Fingerprint(f)
+	}
+
+	return &f, nil
+}
+
+// Insert stores a new finding under a store-wide lock file, so the dedup
+// check, ID allocation and write of two writers cannot interleave. Files are
+// also created with a link from a temporary file, which fails if the name is
+// taken, so a writer that allocates an ID without the lock (Put) cannot be
+// overwritten either: Insert allocates again.
+func (b *yamlBackend) Insert(f *Finding) (*Finding, error) {
+	unlock, err := b.acquire(insertLockName, "the findings store")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	if f.CreatedBy != "" {
+		existing, err := b.findByFingerprintAndCreator(f.Fingerprint, f.CreatedBy)
+		if err != nil || existing != nil {
+			return existing, err
+		}
+	}
+
+	if f.ID != "" {
+		err := b.create(f)
+		if errors.Is(err, fs.ErrExist) {
+			return nil, fmt.Errorf("finding '%s' already exists", f.ID)
+		}
+		return nil, err
+	}
+
+	for attempt := 0; attempt < maxIDAttempts; attempt++ {
+		id, err := b.generateID()
+		if err != nil {
+			return nil, err
+		}
+		f.ID = id
+		err = b.create(f)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			f.ID = ""
+			return nil, err
+		}
+		return nil, nil
+	}
+	f.ID = ""
+	return nil, fmt.Errorf("failed to allocate a finding ID after %d attempts", maxIDAttempts)
+}
+
+// Put writes a finding, replacing any existing file
+func (b *yamlBackend) Put(f *Finding) error {
+	if err := os.MkdirAll(b.rawPath, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	data, err := yaml.Marshal(f)
+	if err != nil {
+		return fmt.Errorf("failed to marshal finding: %w", err)
+	}
+
+	tmp, err := b.writeTemp(data)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, b.getPath(f.ID)); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to write finding: %w", err)
+	}
+	return nil
+}
+
+// Update rewrites a finding under a per-finding lock file, so writers in
+// other processes queue rather than interleave their read and write. The
+// file is replaced by rename, so readers never see a partial finding.
+func (b *yamlBackend) Update(id string, apply func(stored *Finding) (*Finding, error)) error {
+	unlock, err := b.lock(id)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	stored, err := b.Get(id)
+	if err != nil {
+		return err
+	}
+	f, err := apply(stored)
+	if err != nil {
+		return err
+	}
+	if f.ID != id {
+		return fmt.Errorf("update of '%s' cannot change its ID to '%s'", id, f.ID)
+	}
+	return b.Put(f)
+}
+
+// insertLockName is the lock file Insert holds. Finding IDs never start
+// with a dot, so it cannot clash with a per-finding lock.
+const insertLockName = ".insert.lock"
+
+// lock takes the lock file for one finding, waiting up to lockTimeout for
+// another holder to release it.
+func (b *yamlBackend) lock(id string) (unlock func(), err error) {
+	return b.acquire("."+id+".lock", fmt.Sprintf("finding '%s'", id))
+}
+
+// acquire creates the named lock file in the raw directory, waiting up to
+// lockTimeout for another holder to release it. what names the locked
+// resource in errors.
+func (b *yamlBackend) acquire(name, what string) (unlock func(), err error) {
+	if err := os.MkdirAll(b.rawPath, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+	path := filepath.Join(b.rawPath, name)
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		lf, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			_ = lf.Close()
+			return func() { _ = os.Remove(path) }, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, fmt.Errorf("failed to lock %s: %w", what, err)
+		}
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > lockStale {
+			_ = os.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%s is locked by another writer (%s)", what, path)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// Delete deletes a finding
+func (b *yamlBackend) Delete(id string) error {
+	if err := os.Remove(b.getPath(id)); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("finding '%s' %w", id, ErrNotFound)
+		}
+		return fmt.Errorf("failed to delete finding: %w", err)
+	}
+	return nil
+}
+
+// Query reads every finding file and keeps the ones matching opts
+func (b *yamlBackend) Query(opts *FilterOptions) ([]Finding, error) {
+	entries, err := os.ReadDir(b.rawPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	var findings []Finding
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".yaml") {
+			continue
+		}
+
+		f, err := b.Get(strings.TrimSuffix(entry.Name(), ".yaml"))
+		if err != nil {
+			continue
+		}
+		if opts.Match(f) {
+			findings = append(findings, *f)
+		}
+	}
+	return findings, nil
+}
+
+// Close is a no-op; the backend holds no open files
+func (b *yamlBackend) Close() error {
+	return nil
+}
+
+// findByFingerprintAndCreator returns the first stored finding with the
+// given fingerprint+creator pair, or nil when no match exists — that's the
+// common case.
+func (b *yamlBackend) findByFingerprintAndCreator(fingerprint, createdBy string) (*Finding, error) {
+	if fingerprint == "" || createdBy == "" {
+		return nil, nil
+	}
+	matches, err := b.Query(&FilterOptions{CreatedBy: createdBy})
+	if err != nil {
+		return nil, fmt.Errorf("dedup lookup: %w", err)
+	}
+	for i := range matches {
+		if matches[i].Fingerprint == fingerprint {
+			return &matches[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// create writes a finding under a name that must not exist yet. The
+// content goes to a temporary file first and is then hard-linked into
+// place, so readers never see a partially written finding and the link
+// fails with fs.ErrExist when another writer got there first.
+func (b *yamlBackend) create(f *Finding) error {
+	if err := os.MkdirAll(b.rawPath, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	data, err := yaml.Marshal(f)
+	if err != nil {
+		return fmt.Errorf("failed to marshal finding: %w", err)
+	}
+
+	tmp, err := b.writeTemp(data)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp) }()
+
+	if err := os.Link(tmp, b.getPath(f.ID)); err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			return err
+		}
+		return fmt.Errorf("failed to write finding: %w", err)
+	}
+	return nil
+}
+
+// writeTemp writes data to a new temporary file in the raw directory and
+// returns its path.
+func (b *yamlBackend) writeTemp(data []byte) (string, error) {
+	tmp, err := os.CreateTemp(b.rawPath, ".finding-*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("failed to write finding: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return "", fmt.Errorf("failed to write finding: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return "", fmt.Errorf("failed to write finding: %w", err)
+	}
+	return tmp.Name(), nil
+}
+
+// getPath returns the file path for a finding
+func (b *yamlBackend) getPath(id string) string {
+	return filepath.Join(b.rawPath, id+".yaml")
+}
+
+// generateID returns the ID after the highest FIND-NNN on disk
+func (b *yamlBackend) generateID() (string, error) {
+	entries, err := os.ReadDir(b.rawPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "FIND-001", nil
+		}
+		return "", fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	maxNum := 0
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".yaml") {
+			continue
+		}
+		if num, ok := ParseIDNumber(strings.TrimSuffix(entry.Name(), ".yaml")); ok && num > maxNum {
+			maxNum = num
+		}
+	}
+
+	return FormatID(maxNum + 1), nil
+}
+
+// ParseIDNumber extracts N from a "FIND-N" ID. Backends use it to find the
+// highest allocated number.
+func ParseIDNumber(id string) (int, bool) {
+	numStr, ok := strings.CutPrefix(id, "FIND-")
+	if !ok {
+		return 0, false
+	}
+	num, err := strconv.Atoi(numStr)
+	if err != nil {
+		return 0, false
+	}
+	return num, true
+}
+
+// FormatID formats the Nth finding ID
+func FormatID(num int) string {
+	return fmt.Sprintf("FIND-%03d", num)
+}
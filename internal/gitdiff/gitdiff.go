@@ -0,0 +1,314 @@
+// Package gitdiff parses unified diffs into per-file hunks and classifies
+// finding locations against them, so PR reviews can be scoped to the lines
+// a change actually touched rather than to whole files.
+package gitdiff
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Hunk is one "@@ -a,b +c,d @@" block. Diffs are produced with -U0, so the
+// new-side range is exactly the added or modified lines.
+type Hunk struct {
+	OldStart int `json:"old_start"`
+	OldLines int `json:"old_lines"`
+	NewStart int `json:"new_start"`
+	NewLines int `json:"new_lines"`
+
+	// Section is the function context git prints after the second "@@",
+	// e.g. "func (s *Store) Create(f *Finding) error {". Informational only.
+	Section string `json:"section,omitempty"`
+}
+
+// Lines returns the new-side line range the hunk touches. A pure deletion
+// ("+9,0") has no new lines; it is anchored to the lines on either side of
+// the removed block (9-10), since that's where the removed code used to be.
+func (h Hunk) Lines() (start, end int) {
+	if h.NewLines == 0 {
+		start = max(h.NewStart, 1)
+		return start, h.NewStart + 1
+	}
+	return h.NewStart, h.NewStart + h.NewLines - 1
+}
+
+// File is the diff of a single file.
+type File struct {
+	Path    string `json:"path"`
+	OldPath string `json:"old_path,omitempty"` // set for renames
+	Hunks   []Hunk `json:"hunks,omitempty"`
+	Deleted bool   `json:"deleted,omitempty"`
+	Binary  bool   `json:"binary,omitempty"`
+}
+
+// Touches reports whether any hunk overlaps lines start..end (inclusive).
+func (f *File) Touches(start, end int) bool {
+	if end < start {
+		end = start
+	}
+	for _, h := range f.Hunks {
+		hs, he := h.Lines()
+		if hs <= end && start <= he {
+			return true
+		}
+	}
+	return false
+}
+
+// Diff is a parsed multi-file diff.
+type Diff struct {
+	Files []File `json:"files"`
+}
+
+// File returns the diff for path (as it exists after the change), or nil
+// when the file isn't part of the diff.
+func (d *Diff) File(path string) *File {
+	if d == nil {
+		return nil
+	}
+	for i := range d.Files {
+		if d.Files[i].Path == path {
+			return &d.Files[i]
+		}
+	}
+	return nil
+}
+
+// Paths returns every path in the diff, including deleted and binary files,
+// in diff order — the same set `git diff --name-only` prints.
+func (d *Diff) Paths() []string {
+	var out []string
+	for _, f := range d.Files {
+		out = append(out, f.Path)
+	}
+	return out
+}
+
+// HunkMap returns the hunks keyed by path, omitting files without any
+// (deleted and binary files). This is the shape setup.json stores.
+func (d *Diff) HunkMap() map[string][]Hunk {
+	out := map[string][]Hunk{}
+	for _, f := range d.Files {
+		if !f.Deleted && len(f.Hunks) > 0 {
+			out[f.Path] = f.Hunks
+		}
+	}
+	return out
+}
+
+// FromHunks rebuilds a Diff from a HunkMap, e.g. one read back from
+// setup.json.
+func FromHunks(hunks map[string][]Hunk) *Diff {
+	d := &Diff{}
+	for path, hs := range hunks {
+		d.Files = append(d.Files, File{Path: path, Hunks: hs})
+	}
+	sort.Slice(d.Files, func(i, j int) bool { return d.Files[i].Path < d.Files[j].Path })
+	return d
+}
+
+// FormatRanges renders hunks as a compact line list for prompts and
+// summaries: "12-18, 40, 55-60".
+func FormatRanges(hunks []Hunk) string {
+	parts := make([]string, 0, len(hunks))
+	for _, h := range hunks {
+		start, end := h.Lines()
+		if end > start {
+			parts = append(parts, fmt.Sprintf("%d-%d", start, end))
+		} else {
+			parts = append(parts, strconv.Itoa(start))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Git runs `git diff -U0 <baseRef>...HEAD` in dir (empty means the current
+// directory) and parses the result. Paths are relative to the repository
+// root, like `git diff --name-only`.
+func Git(dir, baseRef string) (*Diff, error) {
+	cmd := exec.Command("git", "diff", "-U0", "--no-color", "--no-ext-diff", "-M", baseRef+"...HEAD")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git diff failed: %w", err)
+	}
+	return Parse(out)
+}
+
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@ ?(.*)$`)
+
+// Parse parses unified diff output in git's format.
+func Parse(data []byte) (*Diff, error) {
+	d := &Diff{}
+	var cur *File
+	// Remaining old/new lines in the current hunk. Body lines are skipped
+	// by count rather than by prefix, so a removed line that reads
+	// "-- comment" can't be mistaken for a "--- a/file" header.
+	remOld, remNew := 0, 0
+
+	flush := func() {
+		if cur != nil && cur.Path != "" {
+			d.Files = append(d.Files, *cur)
+		}
+		cur = nil
+	}
+
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	lineNo := 0
+	for sc.Scan() {
+		line := sc.Text()
+		lineNo++
+
+		if remOld > 0 || remNew > 0 {
+			switch {
+			case strings.HasPrefix(line, "-"):
+				remOld--
+				continue
+			case strings.HasPrefix(line, "+"):
+				remNew--
+				continue
+			case strings.HasPrefix(line, " "):
+				remOld--
+				remNew--
+				continue
+			case strings.HasPrefix(line, `\`): // "\ No newline at end of file"
+				continue
+			}
+			// Truncated hunk; fall through and treat the line as a header.
+			remOld, remNew = 0, 0
+		}
+
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			flush()
+			cur = &File{}
+			if a, b, ok := splitGitHeader(strings.TrimPrefix(line, "diff --git ")); ok {
+				cur.OldPath, cur.Path = a, b
+			}
+		case cur == nil:
+			// Preamble before the first file (e.g. commit message); ignore.
+		case strings.HasPrefix(line, "--- "):
+			if p := diffPath(line[4:], "a/"); p != "" {
+				cur.OldPath = p
+			}
+		case strings.HasPrefix(line, "+++ "):
+			if p := diffPath(line[4:], "b/"); p != "" {
+				cur.Path = p
+			} else {
+				cur.Deleted = true
+			}
+		case strings.HasPrefix(line, "rename to "):
+			cur.Path = unquote(strings.TrimPrefix(line, "rename to "))
+		case strings.HasPrefix(line, "rename from "):
+			cur.OldPath = unquote(strings.TrimPrefix(line, "rename from "))
+		case strings.HasPrefix(line, "deleted file mode"):
+			cur.Deleted = true
+		case strings.HasPrefix(line, "Binary files "), strings.HasPrefix(line, "GIT binary patch"):
+			cur.Binary = true
+		case strings.HasPrefix(line, "@@ "):
+			m := hunkHeader.FindStringSubmatch(line)
+			if m == nil {
+				return nil, fmt.Errorf("gitdiff: line %d: malformed hunk header %q", lineNo, line)
+			}
+			h := Hunk{
+				OldStart: atoi(m[1]),
+				OldLines: countOrOne(m[2]),
+				NewStart: atoi(m[3]),
+				NewLines: countOrOne(m[4]),
+				Section:  strings.TrimSpace(m[5]),
+			}
+			cur.Hunks = append(cur.Hunks, h)
+			remOld, remNew = h.OldLines, h.NewLines
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("gitdiff: %w", err)
+	}
+	flush()
+
+	for i := range d.Files {
+		f := &d.Files[i]
+		if f.Deleted && f.OldPath != "" {
+			f.Path = f.OldPath
+		}
+		if f.OldPath == f.Path {
+			f.OldPath = ""
+		}
+	}
+	return d, nil
+}
+
+// splitGitHeader splits the "a/x b/x" part of a "diff --git" line. It is
+// only a fallback for entries without ---/+++ lines (mode changes, binary
+// files, pure renames), so ambiguous unquoted paths containing " b/" are
+// resolved by assuming both sides are the same length.
+func splitGitHeader(s string) (string, string, bool) {
+	if strings.HasPrefix(s, `"`) {
+		if a, rest, ok := cutQuoted(s); ok {
+			return strings.TrimPrefix(a, "a/"), diffPath(strings.TrimSpace(rest), "b/"), true
+		}
+		return "", "", false
+	}
+	if n := len(s); n >= 7 && n%2 == 1 && s[n/2] == ' ' && strings.HasPrefix(s, "a/") && s[n/2+1:n/2+3] == "b/" {
+		return s[2 : n/2], s[n/2+3:], true
+	}
+	a, b, ok := strings.Cut(s, " b/")
+	if !ok {
+		return "", "", false
+	}
+	return strings.TrimPrefix(a, "a/"), b, true
+}
+
+// diffPath strips the a/ or b/ prefix from a ---/+++ path. /dev/null yields
+// "".
+func diffPath(s, prefix string) string {
+	s = strings.TrimRight(s, "\t")
+	s = unquote(s)
+	if s == "/dev/null" {
+		return ""
+	}
+	return strings.TrimPrefix(s, prefix)
+}
+
+// unquote decodes git's C-style quoting of paths with special characters.
+func unquote(s string) string {
+	if strings.HasPrefix(s, `"`) {
+		if u, err := strconv.Unquote(s); err == nil {
+			return u
+		}
+	}
+	return s
+}
+
+func cutQuoted(s string) (string, string, bool) {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			u, err := strconv.Unquote(s[:i+1])
+			return u, s[i+1:], err == nil
+		}
+	}
+	return "", "", false
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// countOrOne parses a hunk length; git omits it when it is 1.
+func countOrOne(s string) int {
+	if s == "" {
+		return 1
+	}
+	return atoi(s)
+}
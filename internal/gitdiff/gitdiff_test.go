@@ -0,0 +1,198 @@
+package gitdiff
+
+import (
+	"testing"
+
+	"github.com/diffsec/quokka/internal/finding"
+)
+
+const sampleDiff = `diff --git a/app/db.go b/app/db.go
+index 1111111..2222222 100644
+--- a/app/db.go
++++ b/app/db.go
+@@ -12,0 +13,2 @@ func Query(db *sql.DB, id string) error {
++	q := "SELECT * FROM users WHERE id = '" + id + "'"
++	return db.Exec(q)
+@@ -40,3 +42 @@ func Close() {
+--- removed comment that looks like a header
+-	x := 1
+-	y := 2
++	z := 3
+@@ -80 +80,0 @@ func Tail() {
+-	defer cleanup()
+diff --git a/new.py b/new.py
+new file mode 100644
+index 0000000..3333333
+--- /dev/null
++++ b/new.py
+@@ -0,0 +1,3 @@
++import os
++
++os.system(input())
+diff --git a/old.js b/old.js
+deleted file mode 100644
+index 4444444..0000000
+--- a/old.js
++++ /dev/null
+@@ -1 +0,0 @@
+-eval(x)
+diff --git a/lib/a.rb b/lib/b.rb
+similarity index 100%
+rename from lib/a.rb
+rename to lib/b.rb
+diff --git a/logo.png b/logo.png
+index 5555555..6666666 100644
+Binary files a/logo.png and b/logo.png differ
+`
+
+func TestParse(t *testing.T) {
+	d, err := Parse([]byte(sampleDiff))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	want := []string{"app/db.go", "new.py", "old.js", "lib/b.rb", "logo.png"}
+	got := d.Paths()
+	if len(got) != len(want) {
+		t.Fatalf("paths = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("paths[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	db := d.File("app/db.go")
+	if len(db.Hunks) != 3 {
+		t.Fatalf("app/db.go hunks = %+v", db.Hunks)
+	}
+	if h := db.Hunks[1]; h.OldStart != 40 || h.OldLines != 3 || h.NewStart != 42 || h.NewLines != 1 || h.Section != "func Close() {" {
+		t.Errorf("hunk[1] = %+v", h)
+	}
+	if r := FormatRanges(db.Hunks); r != "13-14, 42, 80-81" {
+		t.Errorf("ranges = %q", r)
+	}
+
+	if f := d.File("new.py"); f.OldPath != "" || FormatRanges(f.Hunks) != "1-3" {
+		t.Errorf("new.py = %+v", f)
+	}
+	if f := d.File("old.js"); !f.Deleted {
+		t.Errorf("old.js = %+v", f)
+	}
+	if f := d.File("lib/b.rb"); f.OldPath != "lib/a.rb" || len(f.Hunks) != 0 {
+		t.Errorf("lib/b.rb = %+v", f)
+	}
+	if f := d.File("logo.png"); !f.Binary {
+		t.Errorf("logo.png = %+v", f)
+	}
+
+	hm := d.HunkMap()
+	if len(hm) != 2 || len(hm["app/db.go"]) != 3 {
+		t.Errorf("HunkMap = %v", hm)
+	}
+	if back := FromHunks(hm); !back.File("new.py").Touches(2, 2) {
+		t.Error("FromHunks lost new.py hunks")
+	}
+}
+
+func TestParseMalformedHunk(t *testing.T) {
+	if _, err := Parse([]byte("diff --git a/x b/x\n--- a/x\n+++ b/x\n@@ bogus @@\n")); err == nil {
+		t.Error("expected error for malformed hunk header")
+	}
+}
+
+func TestClassify(t *testing.T) {
+	d, err := Parse([]byte(sampleDiff))
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := 0
+	spans := func(path string) []Span {
+		calls++
+		if path != "app/db.go" {
+			return nil
+		}
+		return []Span{
+			{Name: "Query", Start: 10, End: 20},
+			{Name: "Query.func1", Start: 12, End: 18},
+			{Name: "Helper", Start: 25, End: 35},
+			{Name: "Close", Start: 40, End: 45},
+			{Name: "Tail", Start: 78, End: 0},
+		}
+	}
+	c := NewClassifier(d, spans)
+
+	cases := []struct {
+		loc      finding.Location
+		scope    Scope
+		function string
+	}{
+		{finding.Location{File: "app/db.go", LineStart: 14}, ScopeChangedLines, ""},
+		{finding.Location{File: "app/db.go", LineStart: 10, LineEnd: 13}, ScopeChangedLines, ""},
+		{finding.Location{File: "app/db.go", LineStart: 11}, ScopeChangedFunction, "Query"},
+		{finding.Location{File: "app/db.go", LineStart: 17}, ScopeChangedFunction, "Query.func1"},
+		{finding.Location{File: "app/db.go", LineStart: 44}, ScopeChangedFunction, "Close"},
+		{finding.Location{File: "app/db.go", LineStart: 30}, ScopeFile, ""},
+		{finding.Location{File: "app/db.go", LineStart: 90}, ScopeChangedFunction, "Tail"},
+		{finding.Location{File: "app/db.go"}, ScopeFile, ""},
+		{finding.Location{File: "new.py", LineStart: 3}, ScopeChangedLines, ""},
+		{finding.Location{File: "old.js", LineStart: 1}, ScopeOutside, ""},
+		{finding.Location{File: "untouched.go", LineStart: 1}, ScopeOutside, ""},
+	}
+	for _, tc := range cases {
+		got := c.Classify(tc.loc)
+		if got.Scope != tc.scope || got.Function != tc.function {
+			t.Errorf("Classify(%s:%d-%d) = %+v, want %s %q", tc.loc.File, tc.loc.LineStart, tc.loc.LineEnd, got, tc.scope, tc.function)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("span lookups = %d, want 1 (cached per file, skipped for changed lines)", calls)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	d, err := Parse([]byte(sampleDiff))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewClassifier(d, func(string) []Span { return []Span{{Name: "Query", Start: 10, End: 20}} })
+	findings := []finding.Finding{
+		{ID: "FIND-001", Location: finding.Location{File: "app/db.go", LineStart: 13}},
+		{ID: "FIND-002", Location: finding.Location{File: "app/db.go", LineStart: 11}},
+		{ID: "FIND-003", Location: finding.Location{File: "app/db.go", LineStart: 60}},
+		{ID: "FIND-004", Location: finding.Location{File: "other.go", LineStart: 1}},
+	}
+
+	for _, tc := range []struct {
+		limit Scope
+		want  int
+	}{{ScopeChangedLines, 1}, {ScopeChangedFunction, 2}, {ScopeFile, 3}} {
+		kept, scopes := c.Filter(findings, tc.limit)
+		if len(kept) != tc.want {
+			t.Errorf("Filter(%s) kept %d, want %d", tc.limit, len(kept), tc.want)
+		}
+		if len(scopes) != len(findings) {
+			t.Errorf("Filter(%s) classified %d findings, want all %d", tc.limit, len(scopes), len(findings))
+		}
+	}
+
+	_, scopes := c.Filter(findings, ScopeFile)
+	if l := scopes["FIND-002"].Label(); l != "in changed function `Query`" {
+		t.Errorf("label = %q", l)
+	}
+	if l := scopes["FIND-003"].Label(); l != "elsewhere in file" {
+		t.Errorf("label = %q", l)
+	}
+}
+
+func TestParseScope(t *testing.T) {
+	for in, want := range map[string]Scope{
+		"lines": ScopeChangedLines, "changed-function": ScopeChangedFunction, "": ScopeChangedFunction, "FILE": ScopeFile,
+	} {
+		if got, err := ParseScope(in); err != nil || got != want {
+			t.Errorf("ParseScope(%q) = %s, %v", in, got, err)
+		}
+	}
+	if _, err := ParseScope("outside"); err == nil {
+		t.Error("expected error for scope outside")
+	}
+}
@@ -0,0 +1,182 @@
+package gitdiff
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/diffsec/quokka/internal/finding"
+)
+
+// Scope says how close a finding sits to the change under review.
+type Scope string
+
+// Scopes, from narrowest to widest.
+const (
+	// ScopeChangedLines: the finding's lines overlap a hunk.
+	ScopeChangedLines Scope = "changed-lines"
+	// ScopeChangedFunction: the finding is inside a function or method
+	// that a hunk touches, but not on the changed lines themselves.
+	ScopeChangedFunction Scope = "changed-function"
+	// ScopeFile: the file changed, but the finding is elsewhere in it.
+	ScopeFile Scope = "file"
+	// ScopeOutside: the file is not part of the diff.
+	ScopeOutside Scope = "outside"
+)
+
+var scopeRank = map[Scope]int{
+	ScopeChangedLines:    0,
+	ScopeChangedFunction: 1,
+	ScopeFile:            2,
+	ScopeOutside:         3,
+}
+
+// ParseScope parses a --scope flag value. "lines" and "function" are
+// accepted as shorthands.
+func ParseScope(s string) (Scope, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "changed-lines", "lines":
+		return ScopeChangedLines, nil
+	case "changed-function", "function", "":
+		return ScopeChangedFunction, nil
+	case "file":
+		return ScopeFile, nil
+	}
+	return "", fmt.Errorf("invalid scope %q (valid: changed-lines, changed-function, file)", s)
+}
+
+// Within reports whether s is at least as narrow as limit, i.e. whether a
+// finding classified s is kept by a --scope limit filter.
+func (s Scope) Within(limit Scope) bool {
+	r, ok := scopeRank[s]
+	return ok && r <= scopeRank[limit]
+}
+
+// Label is the human-readable form used in PR comments.
+func (s Scope) Label() string {
+	switch s {
+	case ScopeChangedLines:
+		return "in changed lines"
+	case ScopeChangedFunction:
+		return "in changed function"
+	case ScopeFile:
+		return "elsewhere in file"
+	}
+	return "outside the diff"
+}
+
+// Span is a function or method's line range in the new version of a file.
+// End 0 means the span runs to the end of the file.
+type Span struct {
+	Name  string
+	Start int
+	End   int
+}
+
+func (s Span) contains(line int) bool {
+	return line >= s.Start && (s.End == 0 || line <= s.End)
+}
+
+// SpanFunc returns the function spans of a project-relative file. It is
+// typically backed by navigate.UnifiedExtractor; errors should yield nil,
+// which degrades ScopeChangedFunction to ScopeFile for that file.
+type SpanFunc func(path string) []Span
+
+// Classification is the scope of one finding, plus the changed function
+// it sits in when there is one.
+type Classification struct {
+	Scope    Scope  `json:"scope"`
+	Function string `json:"function,omitempty"`
+}
+
+// Label is Scope.Label with the function name appended when known.
+func (c Classification) Label() string {
+	if c.Scope == ScopeChangedFunction && c.Function != "" {
+		return fmt.Sprintf("in changed function `%s`", c.Function)
+	}
+	return c.Scope.Label()
+}
+
+// Classifier assigns findings a Scope against a diff.
+type Classifier struct {
+	Diff      *Diff
+	Functions SpanFunc // nil disables ScopeChangedFunction
+
+	changed map[string][]Span // path -> function spans a hunk touches
+}
+
+// NewClassifier returns a classifier for d using functions for symbol
+// ranges.
+func NewClassifier(d *Diff, functions SpanFunc) *Classifier {
+	return &Classifier{Diff: d, Functions: functions}
+}
+
+// Classify returns the scope of a location. Locations without a line
+// number can only be placed at file granularity.
+func (c *Classifier) Classify(loc finding.Location) Classification {
+	f := c.Diff.File(loc.File)
+	if f == nil || f.Deleted {
+		return Classification{Scope: ScopeOutside}
+	}
+	if loc.LineStart < 1 {
+		return Classification{Scope: ScopeFile}
+	}
+	end := max(loc.LineEnd, loc.LineStart)
+	if f.Touches(loc.LineStart, end) {
+		return Classification{Scope: ScopeChangedLines}
+	}
+	// The narrowest changed function containing the finding, so a closure
+	// inside a changed function reports the closure's own name.
+	var best *Span
+	for _, s := range c.changedFunctions(f) {
+		if !s.contains(loc.LineStart) {
+			continue
+		}
+		if best == nil || s.Start > best.Start {
+			best = &s
+		}
+	}
+	if best != nil {
+		return Classification{Scope: ScopeChangedFunction, Function: best.Name}
+	}
+	return Classification{Scope: ScopeFile}
+}
+
+// Filter keeps the findings whose scope is within limit and returns the
+// classification of every finding, keyed by ID.
+func (c *Classifier) Filter(findings []finding.Finding, limit Scope) ([]finding.Finding, map[string]Classification) {
+	var kept []finding.Finding
+	scopes := make(map[string]Classification, len(findings))
+	for _, f := range findings {
+		cl := c.Classify(f.Location)
+		scopes[f.ID] = cl
+		if cl.Scope.Within(limit) {
+			kept = append(kept, f)
+		}
+	}
+	return kept, scopes
+}
+
+func (c *Classifier) changedFunctions(f *File) []Span {
+	if c.Functions == nil {
+		return nil
+	}
+	if spans, ok := c.changed[f.Path]; ok {
+		return spans
+	}
+	var out []Span
+	for _, s := range c.Functions(f.Path) {
+		end := s.End
+		if end == 0 {
+			end = math.MaxInt
+		}
+		if f.Touches(s.Start, end) {
+			out = append(out, s)
+		}
+	}
+	if c.changed == nil {
+		c.changed = map[string][]Span{}
+	}
+	c.changed[f.Path] = out
+	return out
+}
@@ -0,0 +1,147 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// decodeArgs decodes a tools/call arguments object into dst (a pointer to
+// a struct), using the same property names as the generated schema.
+// Unknown or omitted arguments, missing required ones and values outside
+// an enum are errors, so a model that guesses an argument name is told so
+// instead of having it silently ignored.
+func decodeArgs(raw json.RawMessage, dst any, schema *Schema, omit []string) error {
+	v := reflect.ValueOf(dst).Elem()
+	return decodeStruct(raw, v, schema, omit, "")
+}
+
+func decodeStruct(raw json.RawMessage, v reflect.Value, schema *Schema, omit []string, path string) error {
+	args := map[string]json.RawMessage{}
+	if len(bytes.TrimSpace(raw)) > 0 && !bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+		if err := json.Unmarshal(raw, &args); err != nil {
+			return fmt.Errorf("%s: expected an object", argName(path, "arguments"))
+		}
+	}
+
+	byName := map[string]field{}
+	for _, f := range fields(v.Type()) {
+		if !slices.Contains(omit, f.name) {
+			byName[f.name] = f
+		}
+	}
+
+	var unknown []string
+	for name := range args {
+		if _, ok := byName[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		valid := make([]string, 0, len(byName))
+		for name := range byName {
+			valid = append(valid, name)
+		}
+		sort.Strings(valid)
+		return fmt.Errorf("unknown argument %s (valid: %s)", argName(path, strings.Join(unknown, ", ")), strings.Join(valid, ", "))
+	}
+	for _, name := range schema.Required {
+		if val, ok := args[name]; !ok || bytes.Equal(bytes.TrimSpace(val), []byte("null")) {
+			return fmt.Errorf("missing required argument %s", argName(path, name))
+		}
+	}
+
+	for name, val := range args {
+		f := byName[name]
+		var ps *Schema
+		if schema.Properties != nil {
+			ps = schema.Properties[name]
+		}
+		if ps == nil {
+			ps = &Schema{}
+		}
+		if err := decodeValue(val, fieldByIndex(v, f.index), ps, argName(path, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func decodeValue(raw json.RawMessage, v reflect.Value, schema *Schema, path string) error {
+	if bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+		return nil
+	}
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+
+	switch {
+	case v.Type() == durationType:
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return fmt.Errorf("%s: expected a duration string like \"30s\"", path)
+		}
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		v.SetInt(int64(d))
+		return nil
+	case v.Kind() == reflect.Struct && v.Type() != timeType:
+		return decodeStruct(raw, v, schema, nil, path)
+	}
+
+	if err := json.Unmarshal(raw, v.Addr().Interface()); err != nil {
+		return fmt.Errorf("%s: expected %s", path, describeType(schema))
+	}
+	if len(schema.Enum) > 0 && v.Kind() == reflect.String && v.String() != "" && !slices.Contains(schema.Enum, v.String()) {
+		return fmt.Errorf("%s: invalid value %q (valid: %s)", path, v.String(), strings.Join(schema.Enum, ", "))
+	}
+	return nil
+}
+
+// fieldByIndex is reflect.Value.FieldByIndex, allocating nil embedded
+// pointers on the way down.
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+func describeType(s *Schema) string {
+	switch s.Type {
+	case "":
+		return "a JSON value"
+	case "array":
+		if s.Items != nil && s.Items.Type != "" {
+			return "an array of " + s.Items.Type + "s"
+		}
+		return "an array"
+	case "object", "integer":
+		return "an " + s.Type
+	}
+	return "a " + s.Type
+}
+
+func argName(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
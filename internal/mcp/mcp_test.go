@@ -0,0 +1,226 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type level string
+
+type location struct {
+	File      string `json:"file"`
+	LineStart int    `json:"line_start"`
+}
+
+type baseOptions struct {
+	MaxDepth   int
+	IgnoreCase bool
+}
+
+type searchArgs struct {
+	Pattern string `desc:"Text to search for" mcp:"required"`
+	baseOptions
+	Level     level          `json:"level,omitempty"`
+	Where     *location      `json:"where,omitempty"`
+	Tags      []string       `json:"tags,omitempty"`
+	Timeout   time.Duration  `json:"timeout,omitempty"`
+	Extra     map[string]int `json:"extra,omitempty"`
+	FindingID string
+	Secret    string `json:"-"`
+	hidden    string
+}
+
+func TestSchema(t *testing.T) {
+	r := NewReflector()
+	RegisterEnum(r, level("low"), level("high"))
+	s := r.Schema(reflect.TypeFor[searchArgs]())
+
+	if s.Type != "object" {
+		t.Fatalf("type = %q", s.Type)
+	}
+	var names []string
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	want := []string{"pattern", "max_depth", "ignore_case", "level", "where", "tags", "timeout", "extra", "finding_id"}
+	if len(names) != len(want) {
+		t.Fatalf("properties = %v, want %v", names, want)
+	}
+	for _, name := range want {
+		if s.Properties[name] == nil {
+			t.Errorf("missing property %q", name)
+		}
+	}
+	if got := s.Properties["pattern"]; got.Type != "string" || got.Description != "Text to search for" {
+		t.Errorf("pattern = %+v", got)
+	}
+	if len(s.Required) != 1 || s.Required[0] != "pattern" {
+		t.Errorf("required = %v", s.Required)
+	}
+	if got := s.Properties["level"].Enum; len(got) != 2 || got[1] != "high" {
+		t.Errorf("level enum = %v", got)
+	}
+	if got := s.Properties["where"]; got.Type != "object" || got.Properties["line_start"].Type != "integer" {
+		t.Errorf("where = %+v", got)
+	}
+	if got := s.Properties["tags"]; got.Type != "array" || got.Items.Type != "string" {
+		t.Errorf("tags = %+v", got)
+	}
+	if got := s.Properties["timeout"]; got.Type != "string" {
+		t.Errorf("timeout = %+v", got)
+	}
+	if got := s.Properties["extra"]; got.AdditionalProperties == nil || got.AdditionalProperties.Type != "integer" {
+		t.Errorf("extra = %+v", got)
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	for in, want := range map[string]string{
+		"Pattern":    "pattern",
+		"MaxDepth":   "max_depth",
+		"FindingID":  "finding_id",
+		"HTTPServer": "http_server",
+		"CWE":        "cwe",
+	} {
+		if got := snakeCase(in); got != want {
+			t.Errorf("snakeCase(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func newTestServer() *Server {
+	s := NewServer("test", "0.0.0")
+	RegisterEnum(s.Reflector, level("low"), level("high"))
+	AddTool(s, ToolSpec{Name: "search", Description: "Search", ReadOnly: true},
+		func(ctx context.Context, in searchArgs) (any, error) {
+			return in, nil
+		})
+	AddTool(s, ToolSpec{Name: "count", Omit: []string{"finding_id"}},
+		func(ctx context.Context, in searchArgs) (any, error) {
+			return []int{1, 2}, nil
+		})
+	AddTool(s, ToolSpec{Name: "fail"}, func(ctx context.Context, in struct{}) (any, error) {
+		return nil, errors.New("no findings match")
+	})
+	return s
+}
+
+// roundTrip sends each request line and decodes one response per line.
+func roundTrip(t *testing.T, s *Server, lines ...string) []map[string]any {
+	t.Helper()
+	var out bytes.Buffer
+	if err := s.Serve(context.Background(), strings.NewReader(strings.Join(lines, "\n")+"\n"), &out); err != nil {
+		t.Fatalf("Serve: %v", err)
+	}
+	var resps []map[string]any
+	dec := json.NewDecoder(&out)
+	for dec.More() {
+		var m map[string]any
+		if err := dec.Decode(&m); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		resps = append(resps, m)
+	}
+	return resps
+}
+
+func TestServeHandshakeAndList(t *testing.T) {
+	resps := roundTrip(t, newTestServer(),
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"c","version":"1"}}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"resources/list"}`,
+		`{not json`,
+	)
+	if len(resps) != 4 {
+		t.Fatalf("expected 4 responses (notification gets none), got %d: %v", len(resps), resps)
+	}
+
+	init := resps[0]["result"].(map[string]any)
+	if init["protocolVersion"] != "2025-03-26" {
+		t.Errorf("negotiated version = %v", init["protocolVersion"])
+	}
+
+	tools := resps[1]["result"].(map[string]any)["tools"].([]any)
+	if len(tools) != 3 {
+		t.Fatalf("tools = %v", tools)
+	}
+	count := tools[0].(map[string]any)
+	if count["name"] != "count" {
+		t.Fatalf("tools not sorted: %v", count["name"])
+	}
+	props := count["inputSchema"].(map[string]any)["properties"].(map[string]any)
+	if _, ok := props["finding_id"]; ok {
+		t.Errorf("omitted property finding_id still in schema")
+	}
+	if tools[2].(map[string]any)["annotations"].(map[string]any)["readOnlyHint"] != true {
+		t.Errorf("search not annotated read-only: %v", tools[2])
+	}
+
+	if code := resps[2]["error"].(map[string]any)["code"]; code != float64(codeMethodNotFound) {
+		t.Errorf("unknown method code = %v", code)
+	}
+	if resps[3]["id"] != nil || resps[3]["error"].(map[string]any)["code"] != float64(codeParseError) {
+		t.Errorf("parse error response = %v", resps[3])
+	}
+}
+
+func TestServeToolsCall(t *testing.T) {
+	resps := roundTrip(t, newTestServer(),
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"search","arguments":{"pattern":"exec(","max_depth":2,"level":"high","where":{"file":"a.go","line_start":3},"timeout":"5s"}}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"search","arguments":{"max_depth":2}}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"search","arguments":{"pattern":"x","patern":"y"}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"search","arguments":{"pattern":"x","level":"medium"}}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"count","arguments":{"pattern":"x","finding_id":"abc"}}}`,
+		`{"jsonrpc":"2.0","id":6,"method":"tools/call","params":{"name":"fail"}}`,
+		`{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"nope"}}`,
+		`{"jsonrpc":"2.0","id":8,"method":"tools/call","params":{"name":"count","arguments":{"pattern":"x"}}}`,
+	)
+	if len(resps) != 8 {
+		t.Fatalf("expected 8 responses, got %d", len(resps))
+	}
+
+	ok := resps[0]["result"].(map[string]any)
+	if ok["isError"] == true {
+		t.Fatalf("search failed: %v", ok)
+	}
+	var got searchArgs
+	text := ok["content"].([]any)[0].(map[string]any)["text"].(string)
+	if err := json.Unmarshal([]byte(text), &got); err != nil {
+		t.Fatalf("result text: %v", err)
+	}
+	if got.Pattern != "exec(" || got.MaxDepth != 2 || got.Level != "high" || got.Where == nil || got.Where.LineStart != 3 || got.Timeout != 5*time.Second {
+		t.Errorf("decoded args = %+v", got)
+	}
+	if ok["structuredContent"] == nil {
+		t.Errorf("object result missing structuredContent")
+	}
+
+	for i, want := range map[int]string{
+		1: "missing required argument pattern",
+		2: "unknown argument patern",
+		3: `invalid value "medium"`,
+		4: "unknown argument finding_id",
+		5: "no findings match",
+	} {
+		res := resps[i]["result"].(map[string]any)
+		msg := res["content"].([]any)[0].(map[string]any)["text"].(string)
+		if res["isError"] != true || !strings.Contains(msg, want) {
+			t.Errorf("call %d: isError=%v text=%q, want %q", i+1, res["isError"], msg, want)
+		}
+	}
+
+	if code := resps[6]["error"].(map[string]any)["code"]; code != float64(codeInvalidParams) {
+		t.Errorf("unknown tool code = %v", code)
+	}
+	arr := resps[7]["result"].(map[string]any)
+	if arr["structuredContent"] != nil || arr["content"].([]any)[0].(map[string]any)["text"] != "[1,2]" {
+		t.Errorf("array result = %v", arr)
+	}
+}
@@ -0,0 +1,214 @@
+package mcp
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Schema is the subset of JSON Schema used for tool inputs.
+type Schema struct {
+	Type                 string             `json:"type,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// Reflector builds Schemas from Go types. Property names follow the json
+// tag when there is one and snake_case of the field name otherwise, since
+// several option structs (navigate.SearchOptions, think.*Options) were
+// written for flags rather than JSON. Two extra struct tags are honoured:
+//
+//	desc:"..."       the property description
+//	mcp:"required"   the property is required
+//
+// Fields tagged json:"-" and unexported fields are skipped; embedded
+// structs are flattened like encoding/json does.
+type Reflector struct {
+	enums map[reflect.Type][]string
+	descs map[reflect.Type]string
+}
+
+// NewReflector returns a Reflector with no registered enums.
+func NewReflector() *Reflector {
+	return &Reflector{enums: map[reflect.Type][]string{}, descs: map[reflect.Type]string{}}
+}
+
+// RegisterEnum records the allowed values of a string-kinded type, e.g.
+// finding.Severity, so every field of that type gets an enum constraint.
+func RegisterEnum[T ~string](r *Reflector, values ...T) {
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = string(v)
+	}
+	r.enums[reflect.TypeFor[T]()] = out
+}
+
+// Describe sets the description used for every field of type T that has
+// no desc tag of its own.
+func Describe[T any](r *Reflector, desc string) {
+	r.descs[reflect.TypeFor[T]()] = desc
+}
+
+var (
+	timeType     = reflect.TypeFor[time.Time]()
+	durationType = reflect.TypeFor[time.Duration]()
+	rawType      = reflect.TypeFor[json.RawMessage]()
+)
+
+// Schema returns the schema for t.
+func (r *Reflector) Schema(t reflect.Type) *Schema {
+	return r.schema(t, map[reflect.Type]bool{})
+}
+
+func (r *Reflector) schema(t reflect.Type, seen map[reflect.Type]bool) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	s := &Schema{Description: r.descs[t]}
+	if values, ok := r.enums[t]; ok {
+		s.Type = "string"
+		s.Enum = values
+		return s
+	}
+	switch t {
+	case timeType:
+		s.Type, s.Format = "string", "date-time"
+		return s
+	case durationType:
+		s.Type = "string"
+		if s.Description == "" {
+			s.Description = `Duration such as "30s" or "5m"`
+		}
+		return s
+	case rawType:
+		return s
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		s.Type = "string"
+	case reflect.Bool:
+		s.Type = "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s.Type = "integer"
+	case reflect.Float32, reflect.Float64:
+		s.Type = "number"
+	case reflect.Slice, reflect.Array:
+		s.Type = "array"
+		s.Items = r.schema(t.Elem(), seen)
+	case reflect.Map:
+		s.Type = "object"
+		s.AdditionalProperties = r.schema(t.Elem(), seen)
+	case reflect.Struct:
+		s.Type = "object"
+		if seen[t] {
+			return s // recursive type: leave the nested level open
+		}
+		seen[t] = true
+		s.Properties = map[string]*Schema{}
+		for _, f := range fields(t) {
+			ps := r.schema(f.typ, seen)
+			if f.desc != "" {
+				ps.Description = f.desc
+			}
+			s.Properties[f.name] = ps
+			if f.required {
+				s.Required = append(s.Required, f.name)
+			}
+		}
+		delete(seen, t)
+	default:
+		// interface{} and anything else: any JSON value.
+	}
+	return s
+}
+
+// field is an input property resolved from a struct field.
+type field struct {
+	name     string
+	index    []int
+	typ      reflect.Type
+	desc     string
+	required bool
+}
+
+// fields lists the properties of struct type t, flattening embedded
+// structs. Outer fields shadow embedded ones with the same name.
+func fields(t reflect.Type) []field {
+	var out []field
+	seen := map[string]bool{}
+	var walk func(t reflect.Type, index []int)
+	walk = func(t reflect.Type, index []int) {
+		var embedded []reflect.StructField
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			tag := sf.Tag.Get("json")
+			if tag == "-" {
+				continue
+			}
+			name, _, _ := strings.Cut(tag, ",")
+			if sf.Anonymous && name == "" {
+				ft := sf.Type
+				if ft.Kind() == reflect.Pointer {
+					ft = ft.Elem()
+				}
+				if ft.Kind() == reflect.Struct {
+					embedded = append(embedded, sf)
+					continue
+				}
+			}
+			if !sf.IsExported() {
+				continue
+			}
+			if name == "" {
+				name = snakeCase(sf.Name)
+			}
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			out = append(out, field{
+				name:     name,
+				index:    append(append([]int(nil), index...), i),
+				typ:      sf.Type,
+				desc:     sf.Tag.Get("desc"),
+				required: sf.Tag.Get("mcp") == "required",
+			})
+		}
+		for _, sf := range embedded {
+			ft := sf.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			walk(ft, append(append([]int(nil), index...), sf.Index...))
+		}
+	}
+	walk(t, nil)
+	return out
+}
+
+// snakeCase converts a Go field name to snake_case: "FindingID" becomes
+// "finding_id", "MaxDepth" becomes "max_depth".
+func snakeCase(s string) string {
+	runes := []rune(s)
+	var b strings.Builder
+	for i, c := range runes {
+		if unicode.IsUpper(c) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+				b.WriteByte('_')
+			}
+			c = unicode.ToLower(c)
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
@@ -0,0 +1,324 @@
+// Package mcp implements a Model Context Protocol server over stdio, so
+// agents can call quokka through typed tools instead of shelling out to the
+// CLI and parsing its output.
+//
+// Only the parts of the protocol quokka needs are implemented: the
+// initialize handshake, ping, tools/list and tools/call. Messages are
+// newline-delimited JSON-RPC 2.0, per the MCP stdio transport. Tool input
+// schemas are generated from Go types (see Reflector), and arguments are
+// decoded with the same naming rules, so a tool's schema can't drift from
+// the struct it binds to.
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"sort"
+	"sync"
+)
+
+// ProtocolVersion is the newest MCP revision the server speaks.
+const ProtocolVersion = "2025-06-18"
+
+// supportedVersions are the revisions a client may negotiate. The tools
+// surface is the same in all of them.
+var supportedVersions = []string{ProtocolVersion, "2025-03-26", "2024-11-05"}
+
+// JSON-RPC error codes.
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeInternalError  = -32603
+)
+
+// ToolSpec describes a tool for AddTool.
+type ToolSpec struct {
+	Name        string
+	Description string
+
+	// Required lists argument names the client must supply, in addition
+	// to fields tagged `mcp:"required"` on the input type.
+	Required []string
+	// Omit hides input fields from the schema and rejects them as
+	// arguments, e.g. server-assigned fields like id and created_at when
+	// the input type is a stored record.
+	Omit []string
+
+	// ReadOnly marks tools that don't modify project state; Destructive
+	// marks tools that delete it. Both are hints to the client.
+	ReadOnly    bool
+	Destructive bool
+}
+
+// Tool is a registered tool as advertised by tools/list.
+type Tool struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	InputSchema *Schema          `json:"inputSchema"`
+	Annotations *ToolAnnotations `json:"annotations,omitempty"`
+
+	call func(ctx context.Context, args json.RawMessage) (any, error)
+}
+
+// ToolAnnotations are the behaviour hints from the MCP spec.
+type ToolAnnotations struct {
+	ReadOnlyHint    bool `json:"readOnlyHint"`
+	DestructiveHint bool `json:"destructiveHint"`
+}
+
+// Server is an MCP server exposing a fixed set of tools.
+type Server struct {
+	Name         string
+	Version      string
+	Instructions string
+
+	// Reflector generates input schemas. Register enums on it before
+	// adding tools that use them.
+	Reflector *Reflector
+
+	tools map[string]*Tool
+}
+
+// NewServer creates a server that reports name and version in the
+// initialize handshake.
+func NewServer(name, version string) *Server {
+	return &Server{
+		Name:      name,
+		Version:   version,
+		Reflector: NewReflector(),
+		tools:     map[string]*Tool{},
+	}
+}
+
+// AddTool registers a tool whose arguments decode into In. The input
+// schema is generated from In, which must be a struct. fn's result is
+// returned to the client as JSON; an error becomes a tool error the model
+// can read and correct, not a protocol failure.
+func AddTool[In any](s *Server, spec ToolSpec, fn func(ctx context.Context, in In) (any, error)) {
+	t := reflect.TypeFor[In]()
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("mcp: tool %s: input type %s is not a struct", spec.Name, t))
+	}
+	if _, dup := s.tools[spec.Name]; dup {
+		panic(fmt.Sprintf("mcp: tool %s registered twice", spec.Name))
+	}
+
+	schema := s.Reflector.Schema(t)
+	for _, name := range spec.Omit {
+		delete(schema.Properties, name)
+		schema.Required = slices.DeleteFunc(schema.Required, func(r string) bool { return r == name })
+	}
+	for _, name := range spec.Required {
+		if _, ok := schema.Properties[name]; !ok {
+			panic(fmt.Sprintf("mcp: tool %s: required argument %q is not a field of %s", spec.Name, name, t))
+		}
+		if !slices.Contains(schema.Required, name) {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	sort.Strings(schema.Required)
+	if schema.Properties == nil {
+		schema.Properties = map[string]*Schema{}
+	}
+
+	tool := &Tool{
+		Name:        spec.Name,
+		Description: spec.Description,
+		InputSchema: schema,
+		call: func(ctx context.Context, args json.RawMessage) (any, error) {
+			var in In
+			if err := decodeArgs(args, &in, schema, spec.Omit); err != nil {
+				return nil, err
+			}
+			return fn(ctx, in)
+		},
+	}
+	if spec.ReadOnly || spec.Destructive {
+		tool.Annotations = &ToolAnnotations{ReadOnlyHint: spec.ReadOnly, DestructiveHint: spec.Destructive}
+	}
+	s.tools[spec.Name] = tool
+}
+
+// Tools returns the registered tools sorted by name.
+func (s *Server) Tools() []*Tool {
+	out := make([]*Tool, 0, len(s.tools))
+	for _, t := range s.tools {
+		out = append(out, t)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Serve reads requests from r and writes responses to w until r is
+// exhausted or ctx is cancelled. Requests are handled one at a time, in
+// order: the backing stores and language servers aren't built for
+// concurrent callers, and clients pipeline calls anyway.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	var mu sync.Mutex
+	enc := json.NewEncoder(w)
+	send := func(msg any) error {
+		mu.Lock()
+		defer mu.Unlock()
+		return enc.Encode(msg)
+	}
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 32*1024*1024)
+	for sc.Scan() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if resp := s.handle(ctx, line); resp != nil {
+			if err := send(resp); err != nil {
+				return fmt.Errorf("mcp: write response: %w", err)
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("mcp: read request: %w", err)
+	}
+	return nil
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string { return e.Message }
+
+var nullID = json.RawMessage("null")
+
+// handle processes one message and returns the response, or nil for
+// notifications.
+func (s *Server) handle(ctx context.Context, line []byte) *response {
+	var req request
+	if err := json.Unmarshal(line, &req); err != nil {
+		return &response{JSONRPC: "2.0", ID: nullID, Error: &rpcError{Code: codeParseError, Message: "parse error: " + err.Error()}}
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		id := req.ID
+		if id == nil {
+			id = nullID
+		}
+		return &response{JSONRPC: "2.0", ID: id, Error: &rpcError{Code: codeInvalidRequest, Message: "invalid request"}}
+	}
+
+	result, err := s.dispatch(ctx, req)
+	if req.ID == nil {
+		return nil // notification: no response, even on error
+	}
+	resp := &response{JSONRPC: "2.0", ID: req.ID}
+	if err != nil {
+		var re *rpcError
+		if !errors.As(err, &re) {
+			re = &rpcError{Code: codeInternalError, Message: err.Error()}
+		}
+		resp.Error = re
+		return resp
+	}
+	resp.Result = result
+	return resp
+}
+
+func (s *Server) dispatch(ctx context.Context, req request) (any, error) {
+	switch req.Method {
+	case "initialize":
+		var params struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		_ = json.Unmarshal(req.Params, &params)
+		version := ProtocolVersion
+		if slices.Contains(supportedVersions, params.ProtocolVersion) {
+			version = params.ProtocolVersion
+		}
+		result := map[string]any{
+			"protocolVersion": version,
+			"capabilities":    map[string]any{"tools": map[string]any{"listChanged": false}},
+			"serverInfo":      map[string]any{"name": s.Name, "version": s.Version},
+		}
+		if s.Instructions != "" {
+			result["instructions"] = s.Instructions
+		}
+		return result, nil
+	case "ping":
+		return map[string]any{}, nil
+	case "tools/list":
+		return map[string]any{"tools": s.Tools()}, nil
+	case "tools/call":
+		return s.callTool(ctx, req.Params)
+	}
+	if len(req.Method) > 14 && req.Method[:14] == "notifications/" {
+		return nil, nil
+	}
+	return nil, &rpcError{Code: codeMethodNotFound, Message: "method not found: " + req.Method}
+}
+
+// callResult is the tools/call result shape.
+type callResult struct {
+	Content           []textContent `json:"content"`
+	StructuredContent any           `json:"structuredContent,omitempty"`
+	IsError           bool          `json:"isError,omitempty"`
+}
+
+type textContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+func (s *Server) callTool(ctx context.Context, raw json.RawMessage) (any, error) {
+	var params struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: "invalid tools/call params: " + err.Error()}
+	}
+	tool, ok := s.tools[params.Name]
+	if !ok {
+		return nil, &rpcError{Code: codeInvalidParams, Message: "unknown tool: " + params.Name}
+	}
+
+	out, err := tool.call(ctx, params.Arguments)
+	if err != nil {
+		return callResult{Content: []textContent{{Type: "text", Text: err.Error()}}, IsError: true}, nil
+	}
+	data, err := json.Marshal(out)
+	if err != nil {
+		return callResult{Content: []textContent{{Type: "text", Text: "encode result: " + err.Error()}}, IsError: true}, nil
+	}
+	res := callResult{Content: []textContent{{Type: "text", Text: string(data)}}}
+	// Structured content must be an object; arrays and scalars are only
+	// sent as text.
+	if len(data) > 0 && data[0] == '{' {
+		res.StructuredContent = json.RawMessage(data)
+	}
+	return res, nil
+}
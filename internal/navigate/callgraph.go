@@ -0,0 +1,494 @@
+package navigate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/diffsec/quokka/internal/navigate/lsp"
+	"github.com/diffsec/quokka/internal/project"
+	"github.com/diffsec/quokka/internal/treesitter"
+)
+
+// CallDirection selects which side of the call graph is expanded
+type CallDirection string
+
+const (
+	// DirectionCallers walks from a function to the functions that call it
+	DirectionCallers CallDirection = "callers"
+	// DirectionCallees walks from a function to the functions it calls
+	DirectionCallees CallDirection = "callees"
+)
+
+// DefaultCallDepth is the number of levels expanded below the root
+const DefaultCallDepth = 3
+
+// ModuleLevel names the pseudo-function holding a file's top-level code
+const ModuleLevel = "<module>"
+
+// CallOptions controls how a call tree is built
+type CallOptions struct {
+	// File restricts the root symbol to definitions in this file
+	File string
+	// Depth is the number of levels expanded below the root (default 3)
+	Depth int
+	// Method is auto, treesitter or lsp
+	Method ExtractionMethod
+	// External includes callees that are not defined in the project
+	External bool
+}
+
+// CallNode is one function in a call tree. CallLines are the lines of the
+// call sites and always refer to the caller's file: the parent's file in a
+// callees tree, the node's own file in a callers tree.
+type CallNode struct {
+	Name      string      `json:"name"`
+	Parent    string      `json:"parent,omitempty"`
+	File      string      `json:"file,omitempty"`
+	Line      int         `json:"line,omitempty"`
+	Method    string      `json:"method,omitempty"`
+	CallLines []int       `json:"call_lines,omitempty"`
+	External  bool        `json:"external,omitempty"`
+	Recursive bool        `json:"recursive,omitempty"`
+	Truncated bool        `json:"truncated,omitempty"`
+	Children  []*CallNode `json:"children,omitempty"`
+}
+
+// CallGraphResult holds one call tree per definition matching the symbol
+type CallGraphResult struct {
+	Symbol    string        `json:"symbol"`
+	Direction CallDirection `json:"direction"`
+	Depth     int           `json:"depth"`
+	Roots     []*CallNode   `json:"roots"`
+	Notes     []string      `json:"notes,omitempty"`
+}
+
+// CallGraph answers callers/callees queries for a project. The tree-sitter
+// graph is built from every supported file on first use; when a language
+// server is available its call hierarchy is preferred.
+type CallGraph struct {
+	project *project.Project
+	parser  *treesitter.Parser
+	manager *lsp.Manager
+	index   *callIndex
+}
+
+// NewCallGraph creates a call graph navigator for the project
+func NewCallGraph(p *project.Project) *CallGraph {
+	return &CallGraph{
+		project: p,
+		parser:  treesitter.NewParser(),
+		manager: lsp.NewManager(p.RootPath),
+	}
+}
+
+// Callers returns the tree of functions calling symbol
+func (g *CallGraph) Callers(ctx context.Context, symbol string, opts *CallOptions) (*CallGraphResult, error) {
+	return g.tree(ctx, symbol, DirectionCallers, opts)
+}
+
+// Callees returns the tree of functions called by symbol
+func (g *CallGraph) Callees(ctx context.Context, symbol string, opts *CallOptions) (*CallGraphResult, error) {
+	return g.tree(ctx, symbol, DirectionCallees, opts)
+}
+
+// Close shuts down any language servers that were started
+func (g *CallGraph) Close() error {
+	return g.manager.CloseAll(context.Background())
+}
+
+func (g *CallGraph) tree(ctx context.Context, symbol string, dir CallDirection, opts *CallOptions) (*CallGraphResult, error) {
+	if opts == nil {
+		opts = &CallOptions{}
+	}
+	depth := opts.Depth
+	if depth <= 0 {
+		depth = DefaultCallDepth
+	}
+	method := opts.Method
+	if method == "" {
+		method = MethodAuto
+	}
+	if method != MethodAuto && method != MethodTreeSitter && method != MethodLSP {
+		return nil, fmt.Errorf("unsupported call graph method %q (use auto, treesitter or lsp)", method)
+	}
+
+	if err := g.buildIndex(); err != nil {
+		return nil, err
+	}
+	roots := g.index.lookup(symbol, opts.File)
+	if len(roots) == 0 {
+		if opts.File != "" {
+			return nil, fmt.Errorf("no function named %q in %s", symbol, opts.File)
+		}
+		return nil, fmt.Errorf("no function named %q found", symbol)
+	}
+
+	result := &CallGraphResult{
+		Symbol:    symbol,
+		Direction: dir,
+		Depth:     depth,
+		Notes:     append([]string(nil), g.index.notes...),
+	}
+	for _, ref := range roots {
+		if method != MethodTreeSitter {
+			node, err := g.lspTree(ctx, ref, dir, depth, opts.External)
+			if err == nil {
+				result.Roots = append(result.Roots, node)
+				continue
+			}
+			if method == MethodLSP {
+				return nil, fmt.Errorf("%s: %w", ref.File, err)
+			}
+			if !errors.Is(err, ErrLSPNotAvailable) {
+				result.Notes = append(result.Notes, fmt.Sprintf("%s: LSP call hierarchy failed, using tree-sitter: %v", ref.File, err))
+			}
+		}
+
+		node := g.index.node(ref)
+		node.Method = string(MethodTreeSitter)
+		node.Children = g.index.expand(ref, dir, 1, depth, map[treesitter.FuncRef]bool{ref: true}, opts.External)
+		result.Roots = append(result.Roots, node)
+	}
+	return result, nil
+}
+
+// buildIndex parses every supported file once
+func (g *CallGraph) buildIndex() error {
+	if g.index != nil {
+		return nil
+	}
+	ix := newCallIndex()
+	notes, truncated, err := g.parser.WalkFlow(g.project.RootPath, func(ff *treesitter.FileFlow) error {
+		ix.Add(ff)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	ix.notes = notes
+	if truncated {
+		ix.notes = append(ix.notes, fmt.Sprintf("call graph stopped after %d files", treesitter.MaxFlowFiles))
+	}
+	ix.link()
+	g.index = ix
+	return nil
+}
+
+// lspTree builds a root's tree from the language server's call hierarchy.
+// The definition found by tree-sitter supplies the position to ask about.
+func (g *CallGraph) lspTree(ctx context.Context, ref treesitter.FuncRef, dir CallDirection, depth int, external bool) (*CallNode, error) {
+	if ref.Index < 0 {
+		return nil, ErrLSPNotAvailable
+	}
+	client, uri, done, err := openDocument(ctx, g.manager, filepath.Join(g.project.RootPath, ref.File))
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	items, err := client.PrepareCallHierarchy(ctx, uri, g.index.position(ref))
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("no call hierarchy item at %s:%d", ref.File, g.index.node(ref).Line)
+	}
+
+	node := g.index.node(ref)
+	node.Method = string(MethodLSP)
+	w := &lspCallWalker{ctx: ctx, client: client, root: g.project.RootPath, dir: dir, depth: depth, external: external}
+	node.Children, err = w.expand(items[0], 1, map[string]bool{itemKey(items[0]): true})
+	if err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// openDocument starts or reuses the language server for a file and opens
+// the file in it. The returned func closes the document again.
+func openDocument(ctx context.Context, manager *lsp.Manager, fullPath string) (*lsp.Client, string, func(), error) {
+	if !manager.CanHandle(fullPath) {
+		return nil, "", nil, ErrLSPNotAvailable
+	}
+
+	client, err := manager.GetClient(ctx, fullPath)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	content, err := os.ReadFile(fullPath)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	uri := "file://" + fullPath
+	if err := client.DidOpen(ctx, uri, lsp.GetLanguageID(filepath.Ext(fullPath)), string(content)); err != nil {
+		return nil, "", nil, err
+	}
+	return client, uri, func() { _ = client.DidClose(ctx, uri) }, nil
+}
+
+// lspCallWalker expands call hierarchy items level by level
+type lspCallWalker struct {
+	ctx      context.Context
+	client   *lsp.Client
+	root     string
+	dir      CallDirection
+	depth    int
+	external bool
+}
+
+func (w *lspCallWalker) expand(item lsp.CallHierarchyItem, level int, path map[string]bool) ([]*CallNode, error) {
+	type call struct {
+		item   lsp.CallHierarchyItem
+		ranges []lsp.Range
+	}
+	var calls []call
+	if w.dir == DirectionCallers {
+		incoming, err := w.client.IncomingCalls(w.ctx, item)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range incoming {
+			calls = append(calls, call{c.From, c.FromRanges})
+		}
+	} else {
+		outgoing, err := w.client.OutgoingCalls(w.ctx, item)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range outgoing {
+			calls = append(calls, call{c.To, c.FromRanges})
+		}
+	}
+
+	var nodes []*CallNode
+	for _, c := range calls {
+		node := w.node(c.item)
+		if node.External && !w.external {
+			continue
+		}
+		for _, r := range c.ranges {
+			node.CallLines = append(node.CallLines, r.Start.Line+1)
+		}
+
+		key := itemKey(c.item)
+		switch {
+		case path[key]:
+			node.Recursive = true
+		case node.External:
+		case level >= w.depth:
+			node.Truncated = true
+		default:
+			path[key] = true
+			children, err := w.expand(c.item, level+1, path)
+			delete(path, key)
+			if err != nil {
+				return nil, err
+			}
+			node.Children = children
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+func (w *lspCallWalker) node(item lsp.CallHierarchyItem) *CallNode {
+	node := &CallNode{
+		Name: item.Name,
+		Line: item.SelectionRange.Start.Line + 1, // LSP lines are 0-indexed
+	}
+	node.File, node.External = uriToProjectPath(w.root, item.URI)
+	return node
+}
+
+// uriToProjectPath turns a file URI into a project-relative path. URIs
+// outside the project (standard library, dependencies) keep their absolute
+// path and report external.
+func uriToProjectPath(root, uri string) (string, bool) {
+	path := lsp.URIToPath(uri)
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path, true
+	}
+	return filepath.ToSlash(rel), false
+}
+
+func itemKey(item lsp.CallHierarchyItem) string {
+	return fmt.Sprintf("%s:%d:%d", item.URI, item.SelectionRange.Start.Line, item.SelectionRange.Start.Character)
+}
+
+// callEdge is one call site. to.File is empty when the callee did not
+// resolve to a project function; callee keeps the text as written.
+type callEdge struct {
+	from, to treesitter.FuncRef
+	callee   string
+	line     int
+}
+
+// callIndex is the tree-sitter call graph of a project
+type callIndex struct {
+	*treesitter.FuncIndex
+	out   map[treesitter.FuncRef][]callEdge
+	in    map[treesitter.FuncRef][]callEdge
+	notes []string
+}
+
+func newCallIndex() *callIndex {
+	return &callIndex{
+		FuncIndex: treesitter.NewFuncIndex(),
+		out:       make(map[treesitter.FuncRef][]callEdge),
+		in:        make(map[treesitter.FuncRef][]callEdge),
+	}
+}
+
+// link resolves every call site once all definitions are known
+func (ix *callIndex) link() {
+	for _, rel := range ix.Files() {
+		ff := ix.File(rel)
+		for _, c := range ff.Calls {
+			from := treesitter.FuncRef{File: rel, Index: ff.EnclosingFunction(c.StartByte)}
+			targets := ix.Resolve(rel, c)
+			if len(targets) == 0 {
+				ix.out[from] = append(ix.out[from], callEdge{from: from, callee: c.Callee, line: c.Line})
+				continue
+			}
+			for _, to := range targets {
+				e := callEdge{from: from, to: to, callee: c.Callee, line: c.Line}
+				ix.out[from] = append(ix.out[from], e)
+				ix.in[to] = append(ix.in[to], e)
+			}
+		}
+	}
+}
+
+// lookup finds the definitions named by symbol, which is either a bare name
+// or Parent.name (also Parent::name and Parent#name).
+func (ix *callIndex) lookup(symbol, file string) []treesitter.FuncRef {
+	name, parent := symbol, ""
+	for _, sep := range []string{".", "::", "#"} {
+		if idx := strings.LastIndex(symbol, sep); idx >= 0 {
+			parent, name = symbol[:idx], symbol[idx+len(sep):]
+			break
+		}
+	}
+	file = filepath.ToSlash(filepath.Clean(file))
+
+	var refs []treesitter.FuncRef
+	for _, ref := range ix.Named(name) {
+		if parent != "" && ix.Function(ref).Parent != parent {
+			continue
+		}
+		if file != "." && ref.File != file {
+			continue
+		}
+		refs = append(refs, ref)
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].File != refs[j].File {
+			return refs[i].File < refs[j].File
+		}
+		return refs[i].Index < refs[j].Index
+	})
+	return refs
+}
+
+// expand returns the children of ref at the given level. Calls to the same
+// target are merged into one node listing every call line; a target already
+// on the current path is marked recursive instead of being expanded again.
+func (ix *callIndex) expand(ref treesitter.FuncRef, dir CallDirection, level, depth int, path map[treesitter.FuncRef]bool, external bool) []*CallNode {
+	edges := ix.out[ref]
+	if dir == DirectionCallers {
+		edges = ix.in[ref]
+	}
+
+	var nodes []*CallNode
+	byKey := make(map[string]*CallNode)
+	var targets []treesitter.FuncRef
+	for _, e := range edges {
+		target := e.to
+		if dir == DirectionCallers {
+			target = e.from
+		}
+		unresolved := target.File == ""
+		key := fmt.Sprintf("%s#%d", target.File, target.Index)
+		if unresolved {
+			if !external {
+				continue
+			}
+			key = "ext:" + e.callee
+		}
+		if node, ok := byKey[key]; ok {
+			if !slices.Contains(node.CallLines, e.line) {
+				node.CallLines = append(node.CallLines, e.line)
+			}
+			continue
+		}
+
+		var node *CallNode
+		if unresolved {
+			node = &CallNode{Name: e.callee, External: true}
+		} else {
+			node = ix.node(target)
+		}
+		node.CallLines = []int{e.line}
+		byKey[key] = node
+		nodes = append(nodes, node)
+		targets = append(targets, target)
+	}
+
+	for i, node := range nodes {
+		target := targets[i]
+		switch {
+		case node.External:
+		case path[target]:
+			node.Recursive = true
+		case level >= depth:
+			node.Truncated = ix.hasEdges(target, dir, external)
+		default:
+			path[target] = true
+			node.Children = ix.expand(target, dir, level+1, depth, path, external)
+			delete(path, target)
+		}
+	}
+	return nodes
+}
+
+func (ix *callIndex) hasEdges(ref treesitter.FuncRef, dir CallDirection, external bool) bool {
+	if dir == DirectionCallers {
+		return len(ix.in[ref]) > 0
+	}
+	for _, e := range ix.out[ref] {
+		if e.to.File != "" || external {
+			return true
+		}
+	}
+	return false
+}
+
+// node describes a project function without children
+func (ix *callIndex) node(ref treesitter.FuncRef) *CallNode {
+	if ref.Index < 0 {
+		return &CallNode{Name: ModuleLevel, File: ref.File}
+	}
+	fn := ix.Function(ref)
+	return &CallNode{Name: fn.Name, Parent: fn.Parent, File: ref.File, Line: fn.Line}
+}
+
+// position returns the 0-indexed LSP position of a function's name
+func (ix *callIndex) position(ref treesitter.FuncRef) lsp.Position {
+	ff := ix.File(ref.File)
+	fn := ff.Functions[ref.Index]
+	pos := lsp.Position{Line: fn.Line - 1}
+	if fn.Line >= 1 && fn.Line <= len(ff.Lines) {
+		if col := strings.Index(ff.Lines[fn.Line-1], fn.Name); col >= 0 {
+			pos.Character = col
+		}
+	}
+	return pos
+}
@@ -0,0 +1,468 @@
+package navigate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/diffsec/quokka/internal/navigate/lsp"
+	"github.com/diffsec/quokka/internal/project"
+)
+
+// CodeLocation is a position or range in a source file. Lines and columns
+// are 1-indexed; columns count bytes.
+type CodeLocation struct {
+	File      string `json:"file"`
+	Line      int    `json:"line"`
+	Column    int    `json:"column,omitempty"`
+	EndLine   int    `json:"end_line,omitempty"`
+	EndColumn int    `json:"end_column,omitempty"`
+	Text      string `json:"text,omitempty"`
+	External  bool   `json:"external,omitempty"`
+}
+
+// DefinitionResult describes where the symbol at a position is defined
+type DefinitionResult struct {
+	Position        CodeLocation   `json:"position"`
+	Symbol          string         `json:"symbol,omitempty"`
+	Method          string         `json:"method"`
+	Definitions     []CodeLocation `json:"definitions"`
+	TypeDefinitions []CodeLocation `json:"type_definitions,omitempty"`
+	Hover           string         `json:"hover,omitempty"`
+	Notes           []string       `json:"notes,omitempty"`
+}
+
+// ReferencesResult lists the references to the symbol at a position
+type ReferencesResult struct {
+	Position   CodeLocation   `json:"position"`
+	Symbol     string         `json:"symbol,omitempty"`
+	Method     string         `json:"method"`
+	References []CodeLocation `json:"references"`
+	Total      int            `json:"total"`
+	Notes      []string       `json:"notes,omitempty"`
+}
+
+// ParsePosition parses a <file>:<line>:<col> position
+func ParsePosition(spec string) (CodeLocation, error) {
+	usage := fmt.Errorf("invalid position %q: expected <file>:<line>:<col>", spec)
+
+	rest, colStr, ok := cutLast(spec, ":")
+	if !ok {
+		return CodeLocation{}, usage
+	}
+	file, lineStr, ok := cutLast(rest, ":")
+	if !ok || file == "" {
+		return CodeLocation{}, usage
+	}
+	line, err := strconv.Atoi(lineStr)
+	if err != nil || line < 1 {
+		return CodeLocation{}, usage
+	}
+	col, err := strconv.Atoi(colStr)
+	if err != nil || col < 1 {
+		return CodeLocation{}, usage
+	}
+	return CodeLocation{File: file, Line: line, Column: col}, nil
+}
+
+func cutLast(s, sep string) (string, string, bool) {
+	idx := strings.LastIndex(s, sep)
+	if idx < 0 {
+		return s, "", false
+	}
+	return s[:idx], s[idx+len(sep):], true
+}
+
+// Navigator answers go-to-definition and find-references queries. A
+// language server is used when one is installed for the file; otherwise
+// definitions come from tree-sitter symbols and references from the
+// tree-sitter call graph.
+type Navigator struct {
+	project *project.Project
+	graph   *CallGraph
+	symbols *TreeSitterExtractor
+	lines   map[string][]string
+}
+
+// NewNavigator creates a navigator for the project
+func NewNavigator(p *project.Project) *Navigator {
+	return &Navigator{
+		project: p,
+		graph:   NewCallGraph(p),
+		symbols: NewTreeSitterExtractor(p),
+		lines:   make(map[string][]string),
+	}
+}
+
+// Close shuts down any language servers that were started
+func (n *Navigator) Close() error {
+	return n.graph.Close()
+}
+
+// Definition finds the definition, type definition and hover text of the
+// symbol at pos
+func (n *Navigator) Definition(ctx context.Context, pos CodeLocation, method ExtractionMethod) (*DefinitionResult, error) {
+	method, err := navigationMethod(method)
+	if err != nil {
+		return nil, err
+	}
+	pos, symbol, qualifier, err := n.resolvePosition(pos)
+	if err != nil {
+		return nil, err
+	}
+	result := &DefinitionResult{Position: pos, Symbol: symbol, Definitions: []CodeLocation{}}
+
+	if method != MethodTreeSitter {
+		err := n.lspDefinition(ctx, result)
+		switch {
+		case err == nil && len(result.Definitions) > 0:
+			result.Method = string(MethodLSP)
+			return result, nil
+		case method == MethodLSP && err != nil:
+			return nil, err
+		case method == MethodLSP:
+			result.Method = string(MethodLSP)
+			return result, nil
+		case err == nil:
+			result.Notes = append(result.Notes, "language server found no definition, using tree-sitter")
+		case !errors.Is(err, ErrLSPNotAvailable):
+			result.Notes = append(result.Notes, fmt.Sprintf("LSP definition failed, using tree-sitter: %v", err))
+		}
+		result.TypeDefinitions = nil
+		result.Hover = ""
+	}
+
+	result.Method = string(MethodTreeSitter)
+	if symbol == "" {
+		return nil, fmt.Errorf("no identifier at %s:%d:%d", pos.File, pos.Line, pos.Column)
+	}
+	defs, err := n.findDefinitions(ctx, symbol, qualifier, pos.File)
+	if err != nil {
+		return nil, err
+	}
+	for _, sym := range defs {
+		result.Definitions = append(result.Definitions, n.symbolLocation(sym))
+	}
+	if len(defs) > 0 {
+		result.Hover = defs[0].Signature
+	}
+	return result, nil
+}
+
+// References finds the references to the symbol at pos
+func (n *Navigator) References(ctx context.Context, pos CodeLocation, method ExtractionMethod, includeDeclaration bool) (*ReferencesResult, error) {
+	method, err := navigationMethod(method)
+	if err != nil {
+		return nil, err
+	}
+	pos, symbol, qualifier, err := n.resolvePosition(pos)
+	if err != nil {
+		return nil, err
+	}
+	result := &ReferencesResult{Position: pos, Symbol: symbol, References: []CodeLocation{}}
+
+	if method != MethodTreeSitter {
+		err := n.lspReferences(ctx, result, includeDeclaration)
+		switch {
+		case err == nil && len(result.References) > 0:
+			result.Method = string(MethodLSP)
+			result.Total = len(result.References)
+			return result, nil
+		case method == MethodLSP && err != nil:
+			return nil, err
+		case method == MethodLSP:
+			result.Method = string(MethodLSP)
+			return result, nil
+		case err == nil:
+			result.Notes = append(result.Notes, "language server found no references, using tree-sitter")
+		case !errors.Is(err, ErrLSPNotAvailable):
+			result.Notes = append(result.Notes, fmt.Sprintf("LSP references failed, using tree-sitter: %v", err))
+		}
+	}
+
+	result.Method = string(MethodTreeSitter)
+	if symbol == "" {
+		return nil, fmt.Errorf("no identifier at %s:%d:%d", pos.File, pos.Line, pos.Column)
+	}
+	defs, err := n.findDefinitions(ctx, symbol, qualifier, pos.File)
+	if err != nil {
+		return nil, err
+	}
+	if err := n.graph.buildIndex(); err != nil {
+		return nil, err
+	}
+	ix := n.graph.index
+
+	seen := make(map[string]bool)
+	add := func(loc CodeLocation) {
+		key := fmt.Sprintf("%s:%d:%d", loc.File, loc.Line, loc.Column)
+		if !seen[key] {
+			seen[key] = true
+			result.References = append(result.References, loc)
+		}
+	}
+
+	functions := 0
+	for _, sym := range defs {
+		if includeDeclaration {
+			add(n.symbolLocation(sym))
+		}
+		for _, ref := range ix.Named(sym.Name) {
+			if ref.File != filepath.ToSlash(sym.File) || ix.Function(ref).Line != sym.Line {
+				continue
+			}
+			functions++
+			for _, e := range ix.in[ref] {
+				add(n.textLocation(e.from.File, e.line, sym.Name))
+			}
+		}
+	}
+	if functions == 0 {
+		result.Notes = append(result.Notes, fmt.Sprintf("tree-sitter only resolves call sites of functions; use 'quokka symbols refs %s' for a text search", symbol))
+	}
+
+	sort.SliceStable(result.References, func(i, j int) bool {
+		a, b := result.References[i], result.References[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	result.Total = len(result.References)
+	return result, nil
+}
+
+func navigationMethod(method ExtractionMethod) (ExtractionMethod, error) {
+	switch method {
+	case "":
+		return MethodAuto, nil
+	case MethodAuto, MethodTreeSitter, MethodLSP:
+		return method, nil
+	}
+	return "", fmt.Errorf("unsupported navigation method %q (use auto, treesitter or lsp)", method)
+}
+
+// resolvePosition makes pos project-relative, fills in the source line and
+// returns the identifier under the cursor together with the qualifier
+// written before it (e.g. "helpers" for helpers.run_query).
+func (n *Navigator) resolvePosition(pos CodeLocation) (CodeLocation, string, string, error) {
+	if filepath.IsAbs(pos.File) {
+		if rel, err := filepath.Rel(n.project.RootPath, pos.File); err == nil && !strings.HasPrefix(rel, "..") {
+			pos.File = rel
+		}
+	}
+	pos.File = filepath.ToSlash(filepath.Clean(pos.File))
+
+	lines, err := n.fileLines(pos.File)
+	if err != nil {
+		return pos, "", "", err
+	}
+	if pos.Line < 1 || pos.Line > len(lines) {
+		return pos, "", "", fmt.Errorf("%s has %d lines, no line %d", pos.File, len(lines), pos.Line)
+	}
+	line := lines[pos.Line-1]
+	pos.Text = strings.TrimSpace(line)
+
+	symbol, qualifier := identifierAt(line, pos.Column)
+	return pos, symbol, qualifier, nil
+}
+
+// identifierAt returns the identifier covering the 1-indexed byte column,
+// or ending just before it, plus the identifier before a preceding ".",
+// "::" or "->".
+func identifierAt(line string, col int) (string, string) {
+	i := col - 1
+	if i >= len(line) || (i >= 0 && !isIdentByte(line[i])) {
+		i--
+	}
+	if i < 0 || i >= len(line) || !isIdentByte(line[i]) {
+		return "", ""
+	}
+	start, end := i, i
+	for start > 0 && isIdentByte(line[start-1]) {
+		start--
+	}
+	for end < len(line) && isIdentByte(line[end]) {
+		end++
+	}
+
+	qualifier := ""
+	prefix := line[:start]
+	for _, sep := range []string{".", "::", "->"} {
+		if strings.HasSuffix(prefix, sep) {
+			q := strings.TrimSuffix(prefix, sep)
+			qs := len(q)
+			for qs > 0 && isIdentByte(q[qs-1]) {
+				qs--
+			}
+			qualifier = q[qs:]
+			break
+		}
+	}
+	return line[start:end], qualifier
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+// findDefinitions returns the tree-sitter symbols named name. Like call
+// resolution, a qualifier naming the symbol's class, module or package
+// directory wins; otherwise definitions in the referring file are preferred.
+func (n *Navigator) findDefinitions(ctx context.Context, name, qualifier, file string) ([]Symbol, error) {
+	found, err := n.symbols.Find(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var exact, matched, sameFile []Symbol
+	for _, sym := range found.Symbols {
+		if sym.Name != name || sym.Kind == SymbolImport {
+			continue
+		}
+		sym.File = filepath.ToSlash(sym.File)
+		exact = append(exact, sym)
+		if sym.File == file {
+			sameFile = append(sameFile, sym)
+		}
+		if qualifier == "" {
+			continue
+		}
+		base := strings.TrimSuffix(filepath.Base(sym.File), filepath.Ext(sym.File))
+		dir := filepath.Base(filepath.Dir(sym.File))
+		if qualifier == sym.Parent || qualifier == base || qualifier == dir {
+			matched = append(matched, sym)
+		}
+	}
+
+	defs := exact
+	switch {
+	case len(matched) > 0:
+		defs = matched
+	case len(sameFile) > 0:
+		defs = sameFile
+	}
+	sort.SliceStable(defs, func(i, j int) bool {
+		if defs[i].File != defs[j].File {
+			return defs[i].File < defs[j].File
+		}
+		return defs[i].Line < defs[j].Line
+	})
+	return defs, nil
+}
+
+func (n *Navigator) lspDefinition(ctx context.Context, result *DefinitionResult) error {
+	client, uri, done, err := openDocument(ctx, n.graph.manager, filepath.Join(n.project.RootPath, result.Position.File))
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	at := lspPosition(result.Position)
+	defs, err := client.Definition(ctx, uri, at)
+	if err != nil {
+		return err
+	}
+	for _, loc := range defs {
+		result.Definitions = append(result.Definitions, n.lspLocation(loc))
+	}
+
+	types, err := client.TypeDefinition(ctx, uri, at)
+	if err != nil {
+		result.Notes = append(result.Notes, fmt.Sprintf("type definition unavailable: %v", err))
+	}
+	for _, loc := range types {
+		result.TypeDefinitions = append(result.TypeDefinitions, n.lspLocation(loc))
+	}
+
+	hover, err := client.Hover(ctx, uri, at)
+	if err != nil {
+		result.Notes = append(result.Notes, fmt.Sprintf("hover unavailable: %v", err))
+	}
+	result.Hover = hover.Text()
+	return nil
+}
+
+func (n *Navigator) lspReferences(ctx context.Context, result *ReferencesResult, includeDeclaration bool) error {
+	client, uri, done, err := openDocument(ctx, n.graph.manager, filepath.Join(n.project.RootPath, result.Position.File))
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	refs, err := client.References(ctx, uri, lspPosition(result.Position), includeDeclaration)
+	if err != nil {
+		return err
+	}
+	for _, loc := range refs {
+		result.References = append(result.References, n.lspLocation(loc))
+	}
+	return nil
+}
+
+// lspPosition converts a 1-indexed position to LSP's 0-indexed one. LSP
+// counts UTF-16 code units; byte columns match for ASCII source.
+func lspPosition(pos CodeLocation) lsp.Position {
+	return lsp.Position{Line: pos.Line - 1, Character: pos.Column - 1}
+}
+
+func (n *Navigator) lspLocation(loc lsp.Location) CodeLocation {
+	file, external := uriToProjectPath(n.project.RootPath, loc.URI)
+	out := CodeLocation{
+		File:      file,
+		Line:      loc.Range.Start.Line + 1,
+		Column:    loc.Range.Start.Character + 1,
+		EndLine:   loc.Range.End.Line + 1,
+		EndColumn: loc.Range.End.Character + 1,
+		External:  external,
+	}
+	if lines, err := n.fileLines(file); err == nil && out.Line <= len(lines) {
+		out.Text = strings.TrimSpace(lines[out.Line-1])
+	}
+	return out
+}
+
+func (n *Navigator) symbolLocation(sym Symbol) CodeLocation {
+	return n.textLocation(sym.File, sym.Line, sym.Name)
+}
+
+// textLocation locates name on a line, for tree-sitter results that only
+// carry line numbers
+func (n *Navigator) textLocation(file string, line int, name string) CodeLocation {
+	loc := CodeLocation{File: file, Line: line}
+	lines, err := n.fileLines(file)
+	if err != nil || line < 1 || line > len(lines) {
+		return loc
+	}
+	loc.Text = strings.TrimSpace(lines[line-1])
+	if col := strings.Index(lines[line-1], name); col >= 0 {
+		loc.Column = col + 1
+	}
+	return loc
+}
+
+// fileLines reads a project-relative or absolute file, caching the lines
+func (n *Navigator) fileLines(file string) ([]string, error) {
+	if lines, ok := n.lines[file]; ok {
+		return lines, nil
+	}
+	path := file
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(n.project.RootPath, file)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	lines := strings.Split(string(data), "\n")
+	n.lines[file] = lines
+	return lines, nil
+}
@@ -111,6 +111,13 @@ func (c *Client) Initialize(ctx context.Context) error {
 				DocumentSymbol: DocumentSymbolClientCapabilities{
 					HierarchicalDocumentSymbolSupport: true,
 				},
+				CallHierarchy:  CallHierarchyClientCapabilities{},
+				Definition:     LinkClientCapabilities{LinkSupport: true},
+				TypeDefinition: LinkClientCapabilities{LinkSupport: true},
+				References:     ReferencesClientCapabilities{},
+				Hover: HoverClientCapabilities{
+					ContentFormat: []string{"markdown", "plaintext"},
+				},
 			},
 		},
 	}
@@ -206,6 +213,145 @@ func (c *Client) DocumentSymbols(ctx context.Context, uri string) ([]DocumentSym
 	return symbols, nil
 }
 
+// Definition requests the locations where the symbol at a position is defined
+func (c *Client) Definition(ctx context.Context, uri string, pos Position) ([]Location, error) {
+	return c.locations(ctx, "textDocument/definition", uri, pos)
+}
+
+// TypeDefinition requests the locations of the type of the symbol at a position
+func (c *Client) TypeDefinition(ctx context.Context, uri string, pos Position) ([]Location, error) {
+	return c.locations(ctx, "textDocument/typeDefinition", uri, pos)
+}
+
+// References requests every reference to the symbol at a position
+func (c *Client) References(ctx context.Context, uri string, pos Position, includeDeclaration bool) ([]Location, error) {
+	params := ReferenceParams{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+		Position:     pos,
+		Context:      ReferenceContext{IncludeDeclaration: includeDeclaration},
+	}
+	var locs []Location
+	if err := c.request(ctx, "textDocument/references", params, &locs); err != nil {
+		return nil, err
+	}
+	return locs, nil
+}
+
+// Hover requests hover information for the symbol at a position. A nil
+// Hover means the server has nothing to show.
+func (c *Client) Hover(ctx context.Context, uri string, pos Position) (*Hover, error) {
+	params := TextDocumentPositionParams{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+		Position:     pos,
+	}
+	var hover *Hover
+	if err := c.request(ctx, "textDocument/hover", params, &hover); err != nil {
+		return nil, err
+	}
+	return hover, nil
+}
+
+// locations sends a definition-style request. Servers may answer with a
+// single Location, a list of Locations or a list of LocationLinks; all are
+// returned as Locations pointing at the target's selection range.
+func (c *Client) locations(ctx context.Context, method, uri string, pos Position) ([]Location, error) {
+	params := TextDocumentPositionParams{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+		Position:     pos,
+	}
+	var raw json.RawMessage
+	if err := c.request(ctx, method, params, &raw); err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	var single Location
+	if err := json.Unmarshal(raw, &single); err == nil && single.URI != "" {
+		return []Location{single}, nil
+	}
+
+	var entries []json.RawMessage
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse %s response: %w", method, err)
+	}
+	locs := make([]Location, 0, len(entries))
+	for _, entry := range entries {
+		var link LocationLink
+		if err := json.Unmarshal(entry, &link); err == nil && link.TargetURI != "" {
+			locs = append(locs, Location{URI: link.TargetURI, Range: link.TargetSelectionRange})
+			continue
+		}
+		var loc Location
+		if err := json.Unmarshal(entry, &loc); err != nil {
+			return nil, fmt.Errorf("failed to parse %s response: %w", method, err)
+		}
+		locs = append(locs, loc)
+	}
+	return locs, nil
+}
+
+// PrepareCallHierarchy resolves the function-like symbol at a position to
+// the call hierarchy items used by IncomingCalls and OutgoingCalls
+func (c *Client) PrepareCallHierarchy(ctx context.Context, uri string, pos Position) ([]CallHierarchyItem, error) {
+	params := TextDocumentPositionParams{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+		Position:     pos,
+	}
+	var items []CallHierarchyItem
+	if err := c.request(ctx, "textDocument/prepareCallHierarchy", params, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// IncomingCalls requests the callers of a call hierarchy item
+func (c *Client) IncomingCalls(ctx context.Context, item CallHierarchyItem) ([]CallHierarchyIncomingCall, error) {
+	var calls []CallHierarchyIncomingCall
+	if err := c.request(ctx, "callHierarchy/incomingCalls", CallHierarchyCallsParams{Item: item}, &calls); err != nil {
+		return nil, err
+	}
+	return calls, nil
+}
+
+// OutgoingCalls requests the callees of a call hierarchy item
+func (c *Client) OutgoingCalls(ctx context.Context, item CallHierarchyItem) ([]CallHierarchyOutgoingCall, error) {
+	var calls []CallHierarchyOutgoingCall
+	if err := c.request(ctx, "callHierarchy/outgoingCalls", CallHierarchyCallsParams{Item: item}, &calls); err != nil {
+		return nil, err
+	}
+	return calls, nil
+}
+
+// request sends a request with the default timeout and decodes its result
+// into out. A null result leaves out untouched.
+func (c *Client) request(ctx context.Context, method string, params, out interface{}) error {
+	if !c.ready {
+		return fmt.Errorf("client not initialized")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultRequestTimeout)
+	defer cancel()
+
+	resp, err := c.call(ctx, method, params)
+	if err != nil {
+		return fmt.Errorf("%s failed: %w", method, err)
+	}
+
+	if resp.Error != nil {
+		return fmt.Errorf("%s error: %s", method, resp.Error.Message)
+	}
+
+	if len(resp.Result) == 0 || string(resp.Result) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(resp.Result, out); err != nil {
+		return fmt.Errorf("failed to parse %s response: %w", method, err)
+	}
+	return nil
+}
+
 // Shutdown sends the shutdown request to the server
 func (c *Client) Shutdown(ctx context.Context) error {
 	ctx, cancel := context.WithTimeout(ctx, DefaultRequestTimeout)
@@ -3,6 +3,7 @@ package lsp
 import (
 	"context"
 	"encoding/json"
+	"strings"
 	"testing"
 	"time"
 )
@@ -233,6 +234,32 @@ func TestJSONRPCMessageSerialization(t *testing.T) {
 			t.Errorf("Children length mismatch: got %d, want 1", len(decoded.Children))
 		}
 	})
+
+	t.Run("CallHierarchyIncomingCall", func(t *testing.T) {
+		// Servers stash state in item.data and expect it back verbatim in
+		// callHierarchy/incomingCalls and outgoingCalls.
+		raw := `[{"from":{"name":"handler","kind":12,"uri":"file:///app/api.go",
+			"range":{"start":{"line":4,"character":0},"end":{"line":9,"character":1}},
+			"selectionRange":{"start":{"line":4,"character":5},"end":{"line":4,"character":12}},
+			"data":{"id":7}},
+			"fromRanges":[{"start":{"line":6,"character":1},"end":{"line":6,"character":8}}]}]`
+
+		var calls []CallHierarchyIncomingCall
+		if err := json.Unmarshal([]byte(raw), &calls); err != nil {
+			t.Fatalf("Failed to unmarshal incoming calls: %v", err)
+		}
+		if len(calls) != 1 || calls[0].From.Name != "handler" || len(calls[0].FromRanges) != 1 {
+			t.Fatalf("unexpected calls: %+v", calls)
+		}
+
+		data, err := json.Marshal(CallHierarchyCallsParams{Item: calls[0].From})
+		if err != nil {
+			t.Fatalf("Failed to marshal params: %v", err)
+		}
+		if !strings.Contains(string(data), `"data":{"id":7}`) {
+			t.Errorf("item data not preserved: %s", data)
+		}
+	})
 }
 
 func TestManagerNewManager(t *testing.T) {
@@ -0,0 +1,337 @@
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// The fake language server is this test binary re-executed with
+// QUOKKA_FAKE_LSP set. It answers each request from a JSON script keyed by
+// method and logs every request it receives, so tests exercise the real
+// stdio framing and process handling without gopls or pyright installed.
+
+const (
+	fakeServerEnv = "QUOKKA_FAKE_LSP"
+	fakeScriptEnv = "QUOKKA_FAKE_LSP_SCRIPT"
+	fakeLogEnv    = "QUOKKA_FAKE_LSP_LOG"
+)
+
+// fakeReply is the scripted answer to one method
+type fakeReply struct {
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// fakeRequest is one logged request
+type fakeRequest struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+func TestFakeLanguageServer(t *testing.T) {
+	if os.Getenv(fakeServerEnv) == "" {
+		t.Skip("helper process for the fake language server")
+	}
+	os.Exit(runFakeServer(os.Stdin, os.Stdout))
+}
+
+func runFakeServer(in io.Reader, out io.Writer) int {
+	script := map[string]fakeReply{}
+	if data, err := os.ReadFile(os.Getenv(fakeScriptEnv)); err == nil {
+		if err := json.Unmarshal(data, &script); err != nil {
+			return 2
+		}
+	}
+	logFile, err := os.OpenFile(os.Getenv(fakeLogEnv), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return 2
+	}
+	defer func() { _ = logFile.Close() }()
+
+	write := func(msg interface{}) {
+		data, _ := json.Marshal(msg)
+		_, _ = fmt.Fprintf(out, "Content-Length: %d\r\n\r\n%s", len(data), data)
+	}
+
+	reader := bufio.NewReader(in)
+	for {
+		length := 0
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return 0
+			}
+			line = strings.TrimSpace(line)
+			if line == "" {
+				break
+			}
+			if v, ok := strings.CutPrefix(line, "Content-Length:"); ok {
+				length, _ = strconv.Atoi(strings.TrimSpace(v))
+			}
+		}
+		body := make([]byte, length)
+		if _, err := io.ReadFull(reader, body); err != nil {
+			return 0
+		}
+
+		var msg struct {
+			ID     *int64          `json:"id"`
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+		}
+		if err := json.Unmarshal(body, &msg); err != nil {
+			return 2
+		}
+		if msg.ID == nil {
+			if msg.Method == "exit" {
+				return 0
+			}
+			continue
+		}
+
+		entry, _ := json.Marshal(fakeRequest{Method: msg.Method, Params: msg.Params})
+		_, _ = logFile.Write(append(entry, '\n'))
+
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": *msg.ID}
+		switch reply, ok := script[msg.Method]; {
+		case msg.Method == "initialize":
+			// Real servers chatter before answering; the client must skip it.
+			write(map[string]interface{}{
+				"jsonrpc": "2.0",
+				"method":  "window/logMessage",
+				"params":  map[string]interface{}{"type": 3, "message": "fake server starting"},
+			})
+			resp["result"] = map[string]interface{}{"capabilities": map[string]interface{}{}}
+		case ok && reply.Error != "":
+			resp["error"] = map[string]interface{}{"code": -32603, "message": reply.Error}
+		case ok && len(reply.Result) > 0:
+			resp["result"] = reply.Result
+		default:
+			resp["result"] = nil
+		}
+		write(resp)
+	}
+}
+
+// startFakeServer launches the fake server with a script and returns an
+// initialized client plus the path of the request log.
+func startFakeServer(t *testing.T, script map[string]fakeReply) (*Client, string) {
+	t.Helper()
+	dir := t.TempDir()
+
+	data, err := json.Marshal(script)
+	if err != nil {
+		t.Fatalf("failed to marshal script: %v", err)
+	}
+	scriptPath := filepath.Join(dir, "script.json")
+	if err := os.WriteFile(scriptPath, data, 0644); err != nil {
+		t.Fatalf("failed to write script: %v", err)
+	}
+	logPath := filepath.Join(dir, "requests.jsonl")
+
+	t.Setenv(fakeServerEnv, "1")
+	t.Setenv(fakeScriptEnv, scriptPath)
+	t.Setenv(fakeLogEnv, logPath)
+
+	config := &ServerConfig{
+		Language: "fake",
+		Name:     "fake",
+		Command:  os.Args[0],
+		Args:     []string{"-test.run=^TestFakeLanguageServer$"},
+	}
+	ctx := context.Background()
+	client, err := NewClient(ctx, config, dir)
+	if err != nil {
+		t.Fatalf("failed to start fake server: %v", err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+
+	if err := client.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	return client, logPath
+}
+
+// loggedRequests returns the requests the fake server received for method
+func loggedRequests(t *testing.T, logPath, method string) []fakeRequest {
+	t.Helper()
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("failed to read request log: %v", err)
+	}
+	var reqs []fakeRequest
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var req fakeRequest
+		if err := json.Unmarshal([]byte(line), &req); err != nil {
+			t.Fatalf("bad log line %q: %v", line, err)
+		}
+		if req.Method == method {
+			reqs = append(reqs, req)
+		}
+	}
+	return reqs
+}
+
+func raw(s string) json.RawMessage {
+	return json.RawMessage(s)
+}
+
+func TestFakeServerDefinition(t *testing.T) {
+	client, logPath := startFakeServer(t, map[string]fakeReply{
+		// A single Location
+		"textDocument/definition": {Result: raw(`{"uri":"file:///proj/db.go",
+			"range":{"start":{"line":11,"character":5},"end":{"line":11,"character":13}}}`)},
+		// LocationLinks
+		"textDocument/typeDefinition": {Result: raw(`[{"targetUri":"file:///proj/model.go",
+			"targetRange":{"start":{"line":2,"character":0},"end":{"line":6,"character":1}},
+			"targetSelectionRange":{"start":{"line":2,"character":5},"end":{"line":2,"character":9}}}]`)},
+	})
+	ctx := context.Background()
+	pos := Position{Line: 20, Character: 7}
+
+	defs, err := client.Definition(ctx, "file:///proj/main.go", pos)
+	if err != nil {
+		t.Fatalf("Definition failed: %v", err)
+	}
+	if len(defs) != 1 || defs[0].URI != "file:///proj/db.go" || defs[0].Range.Start.Line != 11 {
+		t.Errorf("unexpected definitions: %+v", defs)
+	}
+
+	types, err := client.TypeDefinition(ctx, "file:///proj/main.go", pos)
+	if err != nil {
+		t.Fatalf("TypeDefinition failed: %v", err)
+	}
+	if len(types) != 1 || types[0].URI != "file:///proj/model.go" || types[0].Range.Start.Character != 5 {
+		t.Errorf("LocationLink should map to its selection range: %+v", types)
+	}
+
+	reqs := loggedRequests(t, logPath, "textDocument/definition")
+	if len(reqs) != 1 {
+		t.Fatalf("expected 1 definition request, got %d", len(reqs))
+	}
+	var params TextDocumentPositionParams
+	if err := json.Unmarshal(reqs[0].Params, &params); err != nil {
+		t.Fatalf("bad params: %v", err)
+	}
+	if params.TextDocument.URI != "file:///proj/main.go" || params.Position != pos {
+		t.Errorf("unexpected definition params: %+v", params)
+	}
+}
+
+func TestFakeServerReferences(t *testing.T) {
+	client, logPath := startFakeServer(t, map[string]fakeReply{
+		"textDocument/references": {Result: raw(`[
+			{"uri":"file:///proj/a.go","range":{"start":{"line":3,"character":1},"end":{"line":3,"character":4}}},
+			{"uri":"file:///proj/b.go","range":{"start":{"line":9,"character":8},"end":{"line":9,"character":11}}}]`)},
+	})
+
+	refs, err := client.References(context.Background(), "file:///proj/a.go", Position{Line: 3, Character: 1}, true)
+	if err != nil {
+		t.Fatalf("References failed: %v", err)
+	}
+	if len(refs) != 2 || refs[1].URI != "file:///proj/b.go" {
+		t.Errorf("unexpected references: %+v", refs)
+	}
+
+	reqs := loggedRequests(t, logPath, "textDocument/references")
+	var params ReferenceParams
+	if len(reqs) != 1 || json.Unmarshal(reqs[0].Params, &params) != nil {
+		t.Fatalf("expected one well-formed references request, got %+v", reqs)
+	}
+	if !params.Context.IncludeDeclaration {
+		t.Error("includeDeclaration was not sent")
+	}
+}
+
+func TestFakeServerHover(t *testing.T) {
+	client, _ := startFakeServer(t, map[string]fakeReply{
+		"textDocument/hover": {Result: raw(`{"contents":{"kind":"markdown","value":"func Find(id string) (*User, error)"}}`)},
+	})
+
+	hover, err := client.Hover(context.Background(), "file:///proj/a.go", Position{})
+	if err != nil {
+		t.Fatalf("Hover failed: %v", err)
+	}
+	if got := hover.Text(); got != "func Find(id string) (*User, error)" {
+		t.Errorf("unexpected hover text %q", got)
+	}
+}
+
+func TestFakeServerEmptyAndErrorResults(t *testing.T) {
+	client, _ := startFakeServer(t, map[string]fakeReply{
+		"textDocument/references": {Error: "no package for file"},
+	})
+	ctx := context.Background()
+
+	// Unscripted methods answer null.
+	defs, err := client.Definition(ctx, "file:///proj/a.go", Position{})
+	if err != nil || len(defs) != 0 {
+		t.Errorf("expected no definitions and no error, got %v, %v", defs, err)
+	}
+	hover, err := client.Hover(ctx, "file:///proj/a.go", Position{})
+	if err != nil || hover != nil || hover.Text() != "" {
+		t.Errorf("expected nil hover, got %+v, %v", hover, err)
+	}
+
+	if _, err := client.References(ctx, "file:///proj/a.go", Position{}, false); err == nil || !strings.Contains(err.Error(), "no package for file") {
+		t.Errorf("expected server error to surface, got %v", err)
+	}
+}
+
+func TestFakeServerCallHierarchy(t *testing.T) {
+	item := `{"name":"load_user","kind":12,"uri":"file:///proj/api.py",
+		"range":{"start":{"line":6,"character":0},"end":{"line":7,"character":30}},
+		"selectionRange":{"start":{"line":6,"character":4},"end":{"line":6,"character":13}},"data":"opaque"}`
+	client, logPath := startFakeServer(t, map[string]fakeReply{
+		"textDocument/prepareCallHierarchy": {Result: raw(`[` + item + `]`)},
+		"callHierarchy/incomingCalls": {Result: raw(`[{"from":{"name":"handler","kind":12,"uri":"file:///proj/api.py",
+			"range":{"start":{"line":2,"character":0},"end":{"line":4,"character":26}},
+			"selectionRange":{"start":{"line":2,"character":4},"end":{"line":2,"character":11}}},
+			"fromRanges":[{"start":{"line":4,"character":11},"end":{"line":4,"character":20}}]}]`)},
+	})
+	ctx := context.Background()
+
+	items, err := client.PrepareCallHierarchy(ctx, "file:///proj/api.py", Position{Line: 6, Character: 4})
+	if err != nil || len(items) != 1 {
+		t.Fatalf("PrepareCallHierarchy: %v, %+v", err, items)
+	}
+	calls, err := client.IncomingCalls(ctx, items[0])
+	if err != nil {
+		t.Fatalf("IncomingCalls failed: %v", err)
+	}
+	if len(calls) != 1 || calls[0].From.Name != "handler" || calls[0].FromRanges[0].Start.Line != 4 {
+		t.Errorf("unexpected incoming calls: %+v", calls)
+	}
+
+	reqs := loggedRequests(t, logPath, "callHierarchy/incomingCalls")
+	if len(reqs) != 1 || !strings.Contains(string(reqs[0].Params), `"data":"opaque"`) {
+		t.Errorf("item data was not sent back: %+v", reqs)
+	}
+}
+
+func TestHoverText(t *testing.T) {
+	tests := []struct {
+		contents string
+		want     string
+	}{
+		{`"plain string"`, "plain string"},
+		{`{"kind":"plaintext","value":"x int"}`, "x int"},
+		{`{"language":"go","value":"var x int"}`, "var x int"},
+		{`[{"language":"python","value":"def f()"}, "Docstring"]`, "def f()\n\nDocstring"},
+		{`[]`, ""},
+	}
+	for _, tt := range tests {
+		h := &Hover{Contents: json.RawMessage(tt.contents)}
+		if got := h.Text(); got != tt.want {
+			t.Errorf("Text(%s) = %q, want %q", tt.contents, got, tt.want)
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package lsp
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"strings"
+)
 
 // JSON-RPC 2.0 types
 
@@ -51,6 +54,11 @@ type ClientCapabilities struct {
 // TextDocumentClientCapabilities describes text document specific capabilities
 type TextDocumentClientCapabilities struct {
 	DocumentSymbol DocumentSymbolClientCapabilities `json:"documentSymbol,omitempty"`
+	CallHierarchy  CallHierarchyClientCapabilities  `json:"callHierarchy,omitempty"`
+	Definition     LinkClientCapabilities           `json:"definition,omitempty"`
+	TypeDefinition LinkClientCapabilities           `json:"typeDefinition,omitempty"`
+	References     ReferencesClientCapabilities     `json:"references,omitempty"`
+	Hover          HoverClientCapabilities          `json:"hover,omitempty"`
 }
 
 // DocumentSymbolClientCapabilities describes document symbol specific capabilities
@@ -58,6 +66,26 @@ type DocumentSymbolClientCapabilities struct {
 	HierarchicalDocumentSymbolSupport bool `json:"hierarchicalDocumentSymbolSupport,omitempty"`
 }
 
+// CallHierarchyClientCapabilities describes call hierarchy specific capabilities
+type CallHierarchyClientCapabilities struct {
+	DynamicRegistration bool `json:"dynamicRegistration,omitempty"`
+}
+
+// LinkClientCapabilities describes definition and typeDefinition capabilities
+type LinkClientCapabilities struct {
+	LinkSupport bool `json:"linkSupport,omitempty"`
+}
+
+// ReferencesClientCapabilities describes references specific capabilities
+type ReferencesClientCapabilities struct {
+	DynamicRegistration bool `json:"dynamicRegistration,omitempty"`
+}
+
+// HoverClientCapabilities describes hover specific capabilities
+type HoverClientCapabilities struct {
+	ContentFormat []string `json:"contentFormat,omitempty"`
+}
+
 // InitializeResult is the response from the initialize request
 type InitializeResult struct {
 	Capabilities ServerCapabilities `json:"capabilities"`
@@ -140,6 +168,114 @@ type Location struct {
 	Range Range  `json:"range"`
 }
 
+// Navigation types
+
+// ReferenceParams is sent to request the references to a symbol
+type ReferenceParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	Position     Position               `json:"position"`
+	Context      ReferenceContext       `json:"context"`
+}
+
+// ReferenceContext controls whether the declaration is included in references
+type ReferenceContext struct {
+	IncludeDeclaration bool `json:"includeDeclaration"`
+}
+
+// LocationLink is the richer definition result some servers return instead
+// of a Location
+type LocationLink struct {
+	OriginSelectionRange *Range `json:"originSelectionRange,omitempty"`
+	TargetURI            string `json:"targetUri"`
+	TargetRange          Range  `json:"targetRange"`
+	TargetSelectionRange Range  `json:"targetSelectionRange"`
+}
+
+// Hover is the result of textDocument/hover. Contents may be MarkupContent,
+// a MarkedString or a list of MarkedStrings; use Text to flatten it.
+type Hover struct {
+	Contents json.RawMessage `json:"contents"`
+	Range    *Range          `json:"range,omitempty"`
+}
+
+// MarkupContent is hover text in plaintext or markdown
+type MarkupContent struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+// Text returns the hover contents as plain text, joining multiple parts
+// with blank lines
+func (h *Hover) Text() string {
+	if h == nil || len(h.Contents) == 0 {
+		return ""
+	}
+
+	var parts []json.RawMessage
+	if err := json.Unmarshal(h.Contents, &parts); err != nil {
+		parts = []json.RawMessage{h.Contents}
+	}
+
+	var texts []string
+	for _, part := range parts {
+		var s string
+		if err := json.Unmarshal(part, &s); err == nil {
+			if s != "" {
+				texts = append(texts, s)
+			}
+			continue
+		}
+		// MarkupContent ({kind, value}) and MarkedString ({language, value})
+		// both carry the text in value.
+		var m MarkupContent
+		if err := json.Unmarshal(part, &m); err == nil && m.Value != "" {
+			texts = append(texts, m.Value)
+		}
+	}
+	return strings.Join(texts, "\n\n")
+}
+
+// Call Hierarchy types
+
+// TextDocumentPositionParams identifies a position in a text document
+type TextDocumentPositionParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	Position     Position               `json:"position"`
+}
+
+// CallHierarchyItem is a function-like symbol returned by
+// textDocument/prepareCallHierarchy. Data is opaque server state that must
+// be sent back unchanged in the follow-up incoming/outgoing requests.
+type CallHierarchyItem struct {
+	Name           string          `json:"name"`
+	Kind           SymbolKind      `json:"kind"`
+	Tags           []int           `json:"tags,omitempty"`
+	Detail         string          `json:"detail,omitempty"`
+	URI            string          `json:"uri"`
+	Range          Range           `json:"range"`
+	SelectionRange Range           `json:"selectionRange"`
+	Data           json.RawMessage `json:"data,omitempty"`
+}
+
+// CallHierarchyCallsParams is sent to request the callers or callees of an item
+type CallHierarchyCallsParams struct {
+	Item CallHierarchyItem `json:"item"`
+}
+
+// CallHierarchyIncomingCall is a caller of an item. FromRanges are the call
+// sites inside From.
+type CallHierarchyIncomingCall struct {
+	From       CallHierarchyItem `json:"from"`
+	FromRanges []Range           `json:"fromRanges"`
+}
+
+// CallHierarchyOutgoingCall is a callee of an item. FromRanges are the call
+// sites inside the item that was asked about, not inside To.
+type CallHierarchyOutgoingCall struct {
+	To         CallHierarchyItem `json:"to"`
+	FromRanges []Range           `json:"fromRanges"`
+}
+
 // SymbolKind represents the kind of symbol
 type SymbolKind int
 
@@ -1,6 +1,7 @@
 package navigate
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"strings"
@@ -512,6 +513,33 @@ CONSTANT = 42
 	}
 }
 
+func TestSymbolResultFunctions(t *testing.T) {
+	result := &SymbolResult{Symbols: []Symbol{
+		{Name: "User", Kind: SymbolStruct, Line: 3},
+		{Name: "main", Kind: SymbolFunction, Line: 12},
+		{Name: "String", Kind: SymbolMethod, Line: 16, EndLine: 18},
+		{Name: "globalVar", Kind: SymbolVariable, Line: 20},
+		{Name: "last", Kind: SymbolFunction, Line: 24},
+	}}
+
+	fns := result.Functions()
+	if len(fns) != 3 {
+		t.Fatalf("expected 3 functions, got %d: %+v", len(fns), fns)
+	}
+	// Regex symbols have no end line: estimated from the next symbol.
+	if fns[0].Name != "main" || fns[0].EndLine != 15 {
+		t.Errorf("main = %+v, want end line 15", fns[0])
+	}
+	// Extractor-provided end lines are kept.
+	if fns[1].EndLine != 18 {
+		t.Errorf("String = %+v, want end line 18", fns[1])
+	}
+	// The last symbol runs to end of file.
+	if fns[2].EndLine != 0 {
+		t.Errorf("last = %+v, want open end", fns[2])
+	}
+}
+
 func TestSymbolFind(t *testing.T) {
 	p, cleanup := setupTestProject(t)
 	defer cleanup()
@@ -534,3 +562,559 @@ func TestSymbolFind(t *testing.T) {
 		t.Errorf("expected 2 symbols with 'Handle', got %d", result.Total)
 	}
 }
+
+// Call graph tests
+
+func setupCallGraphProject(t *testing.T) (*project.Project, func()) {
+	t.Helper()
+	p, cleanup := setupTestProject(t)
+
+	files := map[string]string{
+		"api.py": `from db import helpers
+
+def handler(req):
+    user = req.args.get("id")
+    return load_user(user)
+
+def load_user(uid):
+    return helpers.run_query(uid)
+
+def admin(req):
+    return load_user(req.user)
+`,
+		"db/helpers.py": `def run_query(q):
+    cursor.execute(q)
+    return fetch(q)
+
+def fetch(q):
+    return run_query(q)
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(p.RootPath, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			cleanup()
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			cleanup()
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	return p, cleanup
+}
+
+func childNamed(nodes []*CallNode, name string) *CallNode {
+	for _, n := range nodes {
+		if n.Name == name {
+			return n
+		}
+	}
+	return nil
+}
+
+func TestCallGraphCallees(t *testing.T) {
+	p, cleanup := setupCallGraphProject(t)
+	defer cleanup()
+
+	graph := NewCallGraph(p)
+	defer func() { _ = graph.Close() }()
+
+	result, err := graph.Callees(context.Background(), "handler", &CallOptions{Method: MethodTreeSitter})
+	if err != nil {
+		t.Fatalf("Callees failed: %v", err)
+	}
+	if len(result.Roots) != 1 {
+		t.Fatalf("expected 1 root, got %d", len(result.Roots))
+	}
+	root := result.Roots[0]
+	if root.File != "api.py" || root.Line != 3 || root.Method != "treesitter" {
+		t.Errorf("unexpected root: %+v", root)
+	}
+
+	loadUser := childNamed(root.Children, "load_user")
+	if loadUser == nil || len(root.Children) != 1 {
+		t.Fatalf("expected only load_user under handler, got %+v", root.Children)
+	}
+	if len(loadUser.CallLines) != 1 || loadUser.CallLines[0] != 5 {
+		t.Errorf("expected call at line 5, got %v", loadUser.CallLines)
+	}
+	runQuery := childNamed(loadUser.Children, "run_query")
+	if runQuery == nil || runQuery.File != "db/helpers.py" {
+		t.Fatalf("expected qualified call to resolve to db/helpers.py, got %+v", loadUser.Children)
+	}
+	fetch := childNamed(runQuery.Children, "fetch")
+	if fetch == nil {
+		t.Fatalf("expected fetch under run_query, got %+v", runQuery.Children)
+	}
+	if !fetch.Truncated || len(fetch.Children) != 0 {
+		t.Errorf("expected fetch to be cut off at depth 3: %+v", fetch)
+	}
+}
+
+func TestCallGraphCalleesRecursionAndExternal(t *testing.T) {
+	p, cleanup := setupCallGraphProject(t)
+	defer cleanup()
+
+	graph := NewCallGraph(p)
+	defer func() { _ = graph.Close() }()
+
+	result, err := graph.Callees(context.Background(), "run_query", &CallOptions{Method: MethodTreeSitter, Depth: 5, External: true})
+	if err != nil {
+		t.Fatalf("Callees failed: %v", err)
+	}
+	root := result.Roots[0]
+
+	execute := childNamed(root.Children, "cursor.execute")
+	if execute == nil || !execute.External {
+		t.Errorf("expected external cursor.execute leaf, got %+v", root.Children)
+	}
+	fetch := childNamed(root.Children, "fetch")
+	if fetch == nil {
+		t.Fatalf("expected fetch under run_query, got %+v", root.Children)
+	}
+	back := childNamed(fetch.Children, "run_query")
+	if back == nil || !back.Recursive || len(back.Children) != 0 {
+		t.Errorf("expected recursive run_query leaf under fetch, got %+v", fetch.Children)
+	}
+}
+
+func TestCallGraphCallers(t *testing.T) {
+	p, cleanup := setupCallGraphProject(t)
+	defer cleanup()
+
+	graph := NewCallGraph(p)
+	defer func() { _ = graph.Close() }()
+
+	result, err := graph.Callers(context.Background(), "load_user", &CallOptions{Method: MethodTreeSitter, Depth: 1})
+	if err != nil {
+		t.Fatalf("Callers failed: %v", err)
+	}
+	root := result.Roots[0]
+	if len(root.Children) != 2 {
+		t.Fatalf("expected handler and admin as callers, got %+v", root.Children)
+	}
+	admin := childNamed(root.Children, "admin")
+	if admin == nil || admin.File != "api.py" || len(admin.CallLines) != 1 || admin.CallLines[0] != 11 {
+		t.Errorf("unexpected admin caller: %+v", admin)
+	}
+	for _, c := range root.Children {
+		if c.Truncated {
+			t.Errorf("%s has no callers and should not be truncated", c.Name)
+		}
+	}
+
+	if _, err := graph.Callers(context.Background(), "load_user", &CallOptions{Method: MethodTreeSitter, File: "db/helpers.py"}); err == nil {
+		t.Error("expected error when --file has no such definition")
+	}
+	if _, err := graph.Callers(context.Background(), "missing", nil); err == nil {
+		t.Error("expected error for unknown symbol")
+	}
+}
+
+// Definition and reference tests
+
+func TestParsePosition(t *testing.T) {
+	pos, err := ParsePosition("internal/api/handler.go:42:17")
+	if err != nil {
+		t.Fatalf("ParsePosition failed: %v", err)
+	}
+	if pos.File != "internal/api/handler.go" || pos.Line != 42 || pos.Column != 17 {
+		t.Errorf("unexpected position: %+v", pos)
+	}
+
+	for _, bad := range []string{"handler.go", "handler.go:42", ":4:2", "handler.go:x:1", "handler.go:3:0"} {
+		if _, err := ParsePosition(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestIdentifierAt(t *testing.T) {
+	line := "    return helpers.run_query(uid)"
+	tests := []struct {
+		col           int
+		name, qualify string
+	}{
+		{20, "run_query", "helpers"},
+		{29, "run_query", "helpers"}, // just past the name, on "("
+		{12, "helpers", ""},
+		{3, "", ""},
+	}
+	for _, tt := range tests {
+		name, qualifier := identifierAt(line, tt.col)
+		if name != tt.name || qualifier != tt.qualify {
+			t.Errorf("identifierAt(%d) = %q, %q; want %q, %q", tt.col, name, qualifier, tt.name, tt.qualify)
+		}
+	}
+}
+
+func TestNavigatorDefinitionTreeSitter(t *testing.T) {
+	p, cleanup := setupCallGraphProject(t)
+	defer cleanup()
+
+	nav := NewNavigator(p)
+	defer func() { _ = nav.Close() }()
+
+	// helpers.run_query( on line 8 of api.py
+	result, err := nav.Definition(context.Background(), CodeLocation{File: "api.py", Line: 8, Column: 22}, MethodTreeSitter)
+	if err != nil {
+		t.Fatalf("Definition failed: %v", err)
+	}
+	if result.Symbol != "run_query" || result.Method != "treesitter" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if len(result.Definitions) != 1 {
+		t.Fatalf("expected 1 definition, got %+v", result.Definitions)
+	}
+	def := result.Definitions[0]
+	if def.File != "db/helpers.py" || def.Line != 1 || def.Column != 5 {
+		t.Errorf("unexpected definition location: %+v", def)
+	}
+
+	if _, err := nav.Definition(context.Background(), CodeLocation{File: "api.py", Line: 2, Column: 1}, MethodTreeSitter); err == nil {
+		t.Error("expected error on a blank line")
+	}
+}
+
+func TestNavigatorReferencesTreeSitter(t *testing.T) {
+	p, cleanup := setupCallGraphProject(t)
+	defer cleanup()
+
+	nav := NewNavigator(p)
+	defer func() { _ = nav.Close() }()
+
+	// The definition of load_user on line 7 of api.py
+	result, err := nav.References(context.Background(), CodeLocation{File: "api.py", Line: 7, Column: 6}, MethodTreeSitter, true)
+	if err != nil {
+		t.Fatalf("References failed: %v", err)
+	}
+	var got []int
+	for _, ref := range result.References {
+		if ref.File != "api.py" {
+			t.Errorf("unexpected reference file: %+v", ref)
+		}
+		got = append(got, ref.Line)
+	}
+	want := []int{5, 7, 11}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Errorf("expected references on lines %v, got %v", want, got)
+	}
+
+	result, err = nav.References(context.Background(), CodeLocation{File: "api.py", Line: 7, Column: 6}, MethodTreeSitter, false)
+	if err != nil {
+		t.Fatalf("References failed: %v", err)
+	}
+	if result.Total != 2 {
+		t.Errorf("expected 2 call sites without the declaration, got %+v", result.References)
+	}
+}
+
+// Route extraction tests
+
+func writeRouteFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+}
+
+func findRoute(routes []Route, method, path string) *Route {
+	for i := range routes {
+		if routes[i].Method == method && routes[i].Path == path {
+			return &routes[i]
+		}
+	}
+	return nil
+}
+
+func TestExtractRoutesPython(t *testing.T) {
+	p, cleanup := setupTestProject(t)
+	defer cleanup()
+
+	writeRouteFiles(t, p.RootPath, map[string]string{
+		"app.py": `from flask import Blueprint
+from flask_login import login_required
+
+bp = Blueprint("admin", __name__, url_prefix="/admin")
+
+@bp.route("/users/<int:id>", methods=["GET", "POST"])
+@login_required
+def user(id):
+    return str(id)
+`,
+		"api/items.py": `from fastapi import APIRouter, Depends
+
+router = APIRouter(prefix="/items")
+
+@router.get("/{item_id}")
+def read_item(item_id: int, user=Depends(get_current_user)):
+    return item_id
+`,
+		"site/urls.py": `from django.urls import path, include
+
+urlpatterns = [
+    path("blog/", include("blog.urls")),
+]
+`,
+		"blog/urls.py": `from django.urls import path
+from . import views
+
+urlpatterns = [
+    path("posts/<int:pk>/", views.detail),
+]
+`,
+		"blog/views.py": `from django.contrib.auth.decorators import login_required
+
+@login_required
+def detail(request, pk):
+    return None
+`,
+	})
+
+	result, err := ExtractRoutes(p, nil)
+	if err != nil {
+		t.Fatalf("ExtractRoutes failed: %v", err)
+	}
+
+	for _, method := range []string{"GET", "POST"} {
+		r := findRoute(result.Routes, method, "/admin/users/<int:id>")
+		if r == nil {
+			t.Fatalf("expected flask %s route, got %+v", method, result.Routes)
+		}
+		if r.Framework != FrameworkFlask || r.Handler != "user" || r.File != "app.py" || r.Line != 6 {
+			t.Errorf("unexpected flask route: %+v", r)
+		}
+		if len(r.Auth) != 1 || r.Auth[0] != "login_required" {
+			t.Errorf("expected login_required auth, got %+v", r.Auth)
+		}
+	}
+
+	r := findRoute(result.Routes, "GET", "/items/{item_id}")
+	if r == nil || r.Framework != FrameworkFastAPI || r.Handler != "read_item" {
+		t.Fatalf("expected fastapi route, got %+v", result.Routes)
+	}
+	if len(r.Auth) != 1 || r.Auth[0] != "Depends(get_current_user)" {
+		t.Errorf("expected Depends(get_current_user) auth, got %+v", r.Middleware)
+	}
+
+	r = findRoute(result.Routes, MethodAny, "/blog/posts/<int:pk>/")
+	if r == nil || r.Framework != FrameworkDjango || r.Handler != "views.detail" || r.File != "blog/urls.py" {
+		t.Fatalf("expected django route under the include prefix, got %+v", result.Routes)
+	}
+	if len(r.Auth) != 1 || r.Auth[0] != "login_required" {
+		t.Errorf("expected view decorator as auth, got %+v", r.Middleware)
+	}
+}
+
+func TestExtractRoutesExpressAndGo(t *testing.T) {
+	p, cleanup := setupTestProject(t)
+	defer cleanup()
+
+	writeRouteFiles(t, p.RootPath, map[string]string{
+		"server.js": `const express = require('express');
+const router = express.Router();
+router.use(helmet());
+router.get('/users/:id', authenticate, (req, res) => res.send(req.params.id));
+router.post('/users', controller.create);
+`,
+		"main.go": `package main
+
+import "github.com/gin-gonic/gin"
+
+func main() {
+	r := gin.Default()
+	v1 := r.Group("/v1", AuthRequired())
+	v1.GET("/ping", ping)
+}
+`,
+		"mux.go": `package main
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func routes() {
+	r := chi.NewRouter()
+	r.Route("/api", func(r chi.Router) {
+		r.Use(jwtVerifier)
+		r.Get("/articles", listArticles)
+	})
+	http.HandleFunc("POST /login", login)
+}
+`,
+	})
+
+	result, err := ExtractRoutes(p, nil)
+	if err != nil {
+		t.Fatalf("ExtractRoutes failed: %v", err)
+	}
+
+	r := findRoute(result.Routes, "GET", "/users/:id")
+	if r == nil || r.Framework != FrameworkExpress || r.Handler != "<anonymous>" || r.Line != 4 {
+		t.Fatalf("expected express route, got %+v", result.Routes)
+	}
+	if strings.Join(r.Middleware, ",") != "helmet(),authenticate" || len(r.Auth) != 1 {
+		t.Errorf("unexpected express middleware: %+v", r)
+	}
+	if r := findRoute(result.Routes, "POST", "/users"); r == nil || r.Handler != "controller.create" {
+		t.Errorf("expected POST /users -> controller.create, got %+v", r)
+	}
+
+	r = findRoute(result.Routes, "GET", "/v1/ping")
+	if r == nil || r.Framework != FrameworkGin || r.Handler != "ping" {
+		t.Fatalf("expected gin group route, got %+v", result.Routes)
+	}
+	if len(r.Auth) != 1 || r.Auth[0] != "AuthRequired()" {
+		t.Errorf("expected group middleware as auth, got %+v", r.Middleware)
+	}
+
+	r = findRoute(result.Routes, "GET", "/api/articles")
+	if r == nil || r.Framework != FrameworkChi || r.Handler != "listArticles" {
+		t.Fatalf("expected chi route under /api, got %+v", result.Routes)
+	}
+	if len(r.Middleware) != 1 || r.Middleware[0] != "jwtVerifier" {
+		t.Errorf("expected scoped Use middleware, got %+v", r.Middleware)
+	}
+	if r := findRoute(result.Routes, "POST", "/login"); r == nil || r.Framework != FrameworkNetHTTP || r.Handler != "login" {
+		t.Errorf("expected net/http method pattern route, got %+v", r)
+	}
+
+	gin, err := ExtractRoutes(p, &RouteOptions{Frameworks: []string{FrameworkGin}})
+	if err != nil {
+		t.Fatalf("ExtractRoutes failed: %v", err)
+	}
+	if gin.Total != 1 || len(gin.Frameworks) != 1 || gin.Frameworks[0] != FrameworkGin {
+		t.Errorf("expected only the gin route, got %+v", gin)
+	}
+	if _, err := ExtractRoutes(p, &RouteOptions{Frameworks: []string{"struts"}}); err == nil {
+		t.Error("expected error for unknown framework")
+	}
+}
+
+func TestExtractRoutesSpringAndRails(t *testing.T) {
+	p, cleanup := setupTestProject(t)
+	defer cleanup()
+
+	writeRouteFiles(t, p.RootPath, map[string]string{
+		"src/UserController.java": `package app;
+
+import org.springframework.web.bind.annotation.*;
+
+@RestController
+@RequestMapping("/api/users")
+public class UserController {
+
+    @PreAuthorize("hasRole('ADMIN')")
+    @PostMapping("/{id}")
+    public User update(@PathVariable Long id) {
+        return null;
+    }
+}
+`,
+		"config/routes.rb": `Rails.application.routes.draw do
+  resources :photos, only: [:index, :show]
+  get 'profile', to: 'users#show'
+end
+`,
+		"app/controllers/application_controller.rb": `class ApplicationController < ActionController::Base
+  before_action :authenticate_user!
+end
+`,
+		"app/controllers/photos_controller.rb": `class PhotosController < ApplicationController
+  skip_before_action :authenticate_user!, only: [:index]
+end
+`,
+	})
+
+	result, err := ExtractRoutes(p, nil)
+	if err != nil {
+		t.Fatalf("ExtractRoutes failed: %v", err)
+	}
+
+	r := findRoute(result.Routes, "POST", "/api/users/{id}")
+	if r == nil || r.Framework != FrameworkSpring || r.Handler != "UserController.update" {
+		t.Fatalf("expected spring route under the class prefix, got %+v", result.Routes)
+	}
+	if len(r.Auth) != 1 || r.Auth[0] != `@PreAuthorize("hasRole('ADMIN')")` {
+		t.Errorf("expected @PreAuthorize auth, got %+v", r.Middleware)
+	}
+
+	index := findRoute(result.Routes, "GET", "/photos")
+	show := findRoute(result.Routes, "GET", "/photos/:id")
+	if index == nil || show == nil || index.Handler != "PhotosController#index" || show.Handler != "PhotosController#show" {
+		t.Fatalf("expected photos index and show, got %+v", result.Routes)
+	}
+	if len(index.Auth) != 0 || len(show.Auth) != 1 || show.Auth[0] != "authenticate_user!" {
+		t.Errorf("expected before_action on show only: index=%+v show=%+v", index.Middleware, show.Middleware)
+	}
+	if findRoute(result.Routes, "DELETE", "/photos/:id") != nil {
+		t.Error("only: should exclude destroy")
+	}
+	if r := findRoute(result.Routes, "GET", "/profile"); r == nil || r.Handler != "UsersController#show" || r.Line != 3 {
+		t.Errorf("expected GET /profile -> UsersController#show, got %+v", r)
+	}
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	cases := []struct {
+		framework, middleware string
+		auth                  bool
+	}{
+		{FrameworkExpress, "passport.authenticate('jwt', { session: false })", true},
+		{FrameworkExpress, "ensureLoggedIn()", true},
+		{FrameworkExpress, "requireAuth", true},
+		{FrameworkExpress, "verifyToken", true},
+		{FrameworkExpress, "csrfProtect", false},
+		{FrameworkExpress, "session({ secret: 'auth' })", false},
+		{FrameworkExpress, "secureHeaders()", false},
+		{FrameworkFlask, "csrf_token", false},
+		{FrameworkFlask, "roles_required('admin')", true},
+		{FrameworkFastAPI, "Depends(get_current_active_user)", true},
+		{FrameworkFastAPI, "Depends(get_db)", false},
+		{FrameworkChi, "jwtauth.Verifier(tokenAuth)", true},
+		{FrameworkGin, "JWTAuth()", true},
+		{FrameworkGin, "cors.Default()", false},
+		{FrameworkSpring, `@Secured("ROLE_ADMIN")`, true},
+		{FrameworkSpring, "@PermitAll", false},
+		{FrameworkRails, "require_login", true},
+		{FrameworkRails, "set_session_token", false},
+	}
+	for _, c := range cases {
+		got := len(authMiddleware(c.framework, []string{c.middleware})) == 1
+		if got != c.auth {
+			t.Errorf("%s %q: auth = %v, want %v", c.framework, c.middleware, got, c.auth)
+		}
+	}
+}
+
+func TestRenderRoutesMarkdown(t *testing.T) {
+	md := RenderRoutesMarkdown(&RoutesResult{
+		Routes: []Route{
+			{Method: "GET", Path: "/a", Handler: "a", File: "app.py", Line: 3, Framework: FrameworkFlask, Middleware: []string{"login_required"}, Auth: []string{"login_required"}},
+			{Method: "POST", Path: "/b", Handler: "b", File: "app.py", Line: 8, Framework: FrameworkFlask, Middleware: []string{"a|b"}},
+		},
+		Total:        2,
+		Frameworks:   []string{FrameworkFlask},
+		FilesScanned: 1,
+	})
+	for _, want := range []string{
+		"# API Endpoints",
+		"## flask",
+		"| GET | `/a` | `a` | app.py:3 | login_required |",
+		`| POST | ` + "`/b`" + ` | ` + "`b`" + ` | app.py:8 | a\|b |`,
+		"## Routes Without Detected Auth\n\n- POST `/b` (app.py:8)",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+}
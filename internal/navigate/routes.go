@@ -0,0 +1,492 @@
+package navigate
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/diffsec/quokka/internal/project"
+	"github.com/diffsec/quokka/internal/treesitter"
+)
+
+// Web frameworks recognised by ExtractRoutes
+const (
+	FrameworkFlask   = "flask"
+	FrameworkFastAPI = "fastapi"
+	FrameworkDjango  = "django"
+	FrameworkExpress = "express"
+	FrameworkGin     = "gin"
+	FrameworkChi     = "chi"
+	FrameworkNetHTTP = "net/http"
+	FrameworkSpring  = "spring"
+	FrameworkRails   = "rails"
+)
+
+// MethodAny marks a route that accepts every HTTP method
+const MethodAny = "ANY"
+
+// RouteFrameworks lists the frameworks ExtractRoutes understands
+func RouteFrameworks() []string {
+	return []string{
+		FrameworkChi, FrameworkDjango, FrameworkExpress, FrameworkFastAPI, FrameworkFlask,
+		FrameworkGin, FrameworkNetHTTP, FrameworkRails, FrameworkSpring,
+	}
+}
+
+// Route is one HTTP endpoint. Middleware lists the decorators, annotations,
+// filters and middleware functions applied to it, outermost first; Auth is
+// the subset that looks like authentication or authorization.
+type Route struct {
+	Method     string   `json:"method"`
+	Path       string   `json:"path"`
+	Handler    string   `json:"handler"`
+	File       string   `json:"file"`
+	Line       int      `json:"line"`
+	Framework  string   `json:"framework"`
+	Middleware []string `json:"middleware,omitempty"`
+	Auth       []string `json:"auth,omitempty"`
+}
+
+// RouteOptions filters the routes returned by ExtractRoutes
+type RouteOptions struct {
+	// Frameworks keeps only routes of these frameworks (default all)
+	Frameworks []string
+	// File keeps only routes declared in this file
+	File string
+}
+
+// RoutesResult holds the routes found in a project
+type RoutesResult struct {
+	Routes       []Route  `json:"routes"`
+	Total        int      `json:"total"`
+	Frameworks   []string `json:"frameworks,omitempty"`
+	FilesScanned int      `json:"files_scanned"`
+	Notes        []string `json:"notes,omitempty"`
+}
+
+// authWords are identifier words that name an authentication or
+// authorization check in any framework. Words that are just as often about
+// something else (token, session, secure, protect, role, admin) are left to
+// the per-framework lists, so csrfProtect, session() and secureHeaders are
+// not taken for auth.
+var authWords = map[string]bool{
+	"auth": true, "authn": true, "authz": true, "jwtauth": true, "basicauth": true,
+	"authenticate": true, "authenticated": true, "authentication": true, "authenticator": true,
+	"authorize": true, "authorized": true, "authorization": true, "authorizer": true,
+	"login": true, "logged": true, "jwt": true, "oauth": true, "oauth2": true,
+	"passport": true,
+}
+
+// authIdentifiers are the middleware, decorator, dependency and filter
+// names each framework uses for auth checks that authWords does not cover.
+// Identifiers are compared whole and case-insensitively.
+var authIdentifiers = map[string][]string{
+	FrameworkFlask: {"roles_required", "roles_accepted", "permission_required", "token_required",
+		"admin_required", "current_user", "require_user"},
+	FrameworkFastAPI: {"get_current_user", "get_current_active_user", "get_current_active_superuser",
+		"verify_token", "require_user", "security", "httpbearer", "httpbasic", "apikeyheader"},
+	FrameworkDjango: {"permission_required", "user_passes_test", "staff_member_required",
+		"superuser_required", "permissionrequiredmixin", "userpassestestmixin", "isadminuser",
+		"djangomodelpermissions"},
+	FrameworkExpress: {"requirerole", "checkrole", "checkpermissions", "requirepermission",
+		"verifytoken", "requireuser", "ensureuser"},
+	FrameworkGin:     {"requirerole", "requirepermission", "verifytoken", "casbin"},
+	FrameworkChi:     {"requirerole", "requirepermission", "verifytoken"},
+	FrameworkNetHTTP: {"requirerole", "requirepermission", "verifytoken"},
+	FrameworkSpring:  {"secured", "rolesallowed", "denyall"},
+	FrameworkRails: {"require_user", "require_admin", "require_admin!", "verify_authorized",
+		"check_authorization", "doorkeeper_authorize!"},
+}
+
+// identRE matches an identifier, keeping Ruby's ! and ? suffixes
+var identRE = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*[!?]?`)
+
+// quotedRE matches a string literal, whose contents are not identifiers
+var quotedRE = regexp.MustCompile(`"[^"]*"|'[^']*'|` + "`[^`]*`")
+
+// ExtractRoutes parses the project with tree-sitter and reports the HTTP
+// routes declared through Flask, FastAPI, Django, Express, Gin, chi,
+// net/http, Spring MVC and Rails. Extraction is syntactic: routers mounted
+// from another file (include_router, app.use("/api", router), r.Mount) keep
+// the path they were declared with.
+func ExtractRoutes(p *project.Project, opts *RouteOptions) (*RoutesResult, error) {
+	if opts == nil {
+		opts = &RouteOptions{}
+	}
+	for _, fw := range opts.Frameworks {
+		if !slices.Contains(RouteFrameworks(), fw) {
+			return nil, fmt.Errorf("unknown framework %q (valid: %s)", fw, strings.Join(RouteFrameworks(), ", "))
+		}
+	}
+
+	result := &RoutesResult{}
+	var files []*treesitter.FileFlow
+	notes, truncated, err := treesitter.NewParser().WalkFlow(p.RootPath, func(ff *treesitter.FileFlow) error {
+		files = append(files, ff)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Notes = notes
+	if truncated {
+		result.Notes = append(result.Notes, fmt.Sprintf("route extraction stopped after %d files", treesitter.MaxFlowFiles))
+	}
+	result.FilesScanned = len(files)
+
+	var routes []Route
+	var python []*treesitter.FileFlow
+	rails := newRailsFilters(p.RootPath)
+	for _, ff := range files {
+		switch ff.Language {
+		case "python":
+			python = append(python, ff)
+			routes = append(routes, pythonRoutes(ff)...)
+		case "javascript", "javascriptreact", "typescript", "typescriptreact":
+			routes = append(routes, expressRoutes(ff)...)
+		case "go":
+			routes = append(routes, goRoutes(ff)...)
+		case "java":
+			routes = append(routes, springRoutes(ff)...)
+		case "ruby":
+			if isRailsRoutesFile(ff.Path) {
+				routes = append(routes, railsRoutes(ff, rails)...)
+			}
+		}
+	}
+	routes = append(routes, djangoRoutes(python)...)
+
+	file := filepath.ToSlash(opts.File)
+	seen := make(map[string]bool)
+	for _, r := range routes {
+		if file != "" && r.File != file {
+			continue
+		}
+		if len(opts.Frameworks) > 0 && !slices.Contains(opts.Frameworks, r.Framework) {
+			continue
+		}
+		r.Auth = authMiddleware(r.Framework, r.Middleware)
+		result.Routes = append(result.Routes, r)
+		if !seen[r.Framework] {
+			seen[r.Framework] = true
+			result.Frameworks = append(result.Frameworks, r.Framework)
+		}
+	}
+	sort.Strings(result.Frameworks)
+	sort.SliceStable(result.Routes, func(i, j int) bool {
+		a, b := result.Routes[i], result.Routes[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Method < b.Method
+	})
+	if result.Routes == nil {
+		result.Routes = []Route{}
+	}
+	result.Total = len(result.Routes)
+	return result, nil
+}
+
+// authMiddleware returns the middleware entries that are auth checks: an
+// entry is one when any identifier in it, outside string literals, is
+// listed for the framework or contains an authWords word. Identifiers are
+// matched whole, so csrf_token and express-session do not count.
+func authMiddleware(framework string, mw []string) []string {
+	var out []string
+	for _, m := range mw {
+		for _, ident := range identRE.FindAllString(quotedRE.ReplaceAllString(m, ""), -1) {
+			if isAuthIdentifier(framework, ident) {
+				out = append(out, m)
+				break
+			}
+		}
+	}
+	return out
+}
+
+func isAuthIdentifier(framework, ident string) bool {
+	for _, known := range authIdentifiers[framework] {
+		if strings.EqualFold(ident, known) {
+			return true
+		}
+	}
+	for _, w := range identWords(ident) {
+		if authWords[w] {
+			return true
+		}
+	}
+	return false
+}
+
+// identWords splits an identifier into lowercase words at underscores and
+// camelCase boundaries: "ensureLoggedIn" -> ensure, logged, in;
+// "JWTAuth" -> jwt, auth.
+func identWords(ident string) []string {
+	var words []string
+	var cur []rune
+	runes := []rune(strings.TrimRight(ident, "!?"))
+	flush := func() {
+		if len(cur) > 0 {
+			words = append(words, strings.ToLower(string(cur)))
+			cur = cur[:0]
+		}
+	}
+	for i, r := range runes {
+		switch {
+		case r == '_':
+			flush()
+			continue
+		case unicode.IsUpper(r) && i > 0:
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				flush()
+			}
+		}
+		cur = append(cur, r)
+	}
+	flush()
+	return words
+}
+
+// fileText rejoins a file's lines; byte offsets in the flow facts index it
+func fileText(ff *treesitter.FileFlow) string {
+	return strings.Join(ff.Lines, "\n")
+}
+
+// receiverOf returns the callee text before its last ".", or ""
+func receiverOf(callee string) string {
+	if idx := strings.LastIndex(callee, "."); idx >= 0 {
+		return strings.TrimSpace(callee[:idx])
+	}
+	return ""
+}
+
+// stringLiteral returns the contents of a quoted string literal, allowing
+// Python prefixes such as r"..." and b"..."
+func stringLiteral(s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimLeft(s, "rRbBuUfF")
+	if len(s) < 2 {
+		return "", false
+	}
+	q := s[0]
+	if (q != '"' && q != '\'' && q != '`') || s[len(s)-1] != q {
+		return "", false
+	}
+	return s[1 : len(s)-1], true
+}
+
+// keywordRE matches a keyword argument: Python/Java "name=value", Ruby
+// "name: value" and ":name => value"
+var keywordRE = regexp.MustCompile(`^\s*:?([A-Za-z_]\w*)\s*(?:=>|=|:)\s*([\s\S]+)$`)
+
+// keywordArg returns the value of the first keyword argument named any of
+// names
+func keywordArg(args []string, names ...string) (string, bool) {
+	for _, a := range args {
+		if name, value, ok := splitKeyword(a); ok && slices.Contains(names, name) {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// positionalArgs drops keyword arguments
+func positionalArgs(args []string) []string {
+	var out []string
+	for _, a := range args {
+		if _, _, ok := splitKeyword(a); !ok {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// splitKeyword splits a keyword argument into name and value, rejecting
+// comparisons (a == b) and scope resolution (A::B)
+func splitKeyword(arg string) (string, string, bool) {
+	m := keywordRE.FindStringSubmatchIndex(arg)
+	if m == nil {
+		return "", "", false
+	}
+	value := arg[m[4]:m[5]]
+	if strings.HasPrefix(value, "=") || strings.HasPrefix(value, ":") && arg[m[4]-1] == ':' {
+		return "", "", false
+	}
+	return arg[m[2]:m[3]], strings.TrimSpace(value), true
+}
+
+// stringList parses a list of string literals or symbols written as
+// ["a", "b"], {"a", "b"}, ("a",), [:a, :b], %i[a b] or a single value
+func stringList(s string) []string {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "%i[") || strings.HasPrefix(s, "%w[") {
+		return strings.Fields(strings.TrimSuffix(s[3:], "]"))
+	}
+	if len(s) >= 2 && strings.ContainsRune("[{(", rune(s[0])) {
+		s = s[1 : len(s)-1]
+	}
+	var out []string
+	for _, item := range treesitter.SplitArgs(s) {
+		if v, ok := stringLiteral(item); ok {
+			out = append(out, v)
+		} else if v := strings.TrimPrefix(item, ":"); v != item {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// joinRoute joins path pieces with single slashes. The result starts with
+// "/" and keeps the last piece's trailing slash.
+func joinRoute(parts ...string) string {
+	var segs []string
+	trailing := false
+	for _, p := range parts {
+		if p == "" {
+			continue
+		}
+		trailing = strings.HasSuffix(p, "/")
+		for _, s := range strings.Split(p, "/") {
+			if s != "" {
+				segs = append(segs, s)
+			}
+		}
+	}
+	out := "/" + strings.Join(segs, "/")
+	if trailing && len(segs) > 0 {
+		out += "/"
+	}
+	return out
+}
+
+// isFunctionLiteral reports whether an argument is an inline function
+func isFunctionLiteral(arg string) bool {
+	arg = strings.TrimSpace(arg)
+	for _, prefix := range []string{"func(", "func (", "function", "async ", "lambda"} {
+		if strings.HasPrefix(arg, prefix) {
+			return true
+		}
+	}
+	return strings.HasPrefix(arg, "(") && strings.Contains(arg, "=>") || arrowParamRE.MatchString(arg)
+}
+
+// arrowParamRE matches a single-parameter arrow function such as "req => x"
+var arrowParamRE = regexp.MustCompile(`^[\w$]+\s*=>`)
+
+// exprName renders an argument for display: inline functions become
+// "<anonymous>" and whitespace is collapsed
+func exprName(arg string) string {
+	if isFunctionLiteral(arg) {
+		return "<anonymous>"
+	}
+	return strings.Join(strings.Fields(arg), " ")
+}
+
+// exprNames renders arguments with exprName, flattening array literals
+func exprNames(args []string) []string {
+	var out []string
+	for _, a := range args {
+		a = strings.TrimSpace(a)
+		if strings.HasPrefix(a, "[") && strings.HasSuffix(a, "]") {
+			out = append(out, exprNames(treesitter.SplitArgs(a[1:len(a)-1]))...)
+			continue
+		}
+		if a != "" {
+			out = append(out, exprName(a))
+		}
+	}
+	return out
+}
+
+// functionAfter returns the first function starting at or after offset
+func functionAfter(ff *treesitter.FileFlow, offset int) *treesitter.FlowFunction {
+	for i := range ff.Functions {
+		if ff.Functions[i].StartByte >= offset {
+			return &ff.Functions[i]
+		}
+	}
+	return nil
+}
+
+// qualifiedName renders a function as Parent.name
+func qualifiedName(fn *treesitter.FlowFunction) string {
+	if fn.Parent != "" {
+		return fn.Parent + "." + fn.Name
+	}
+	return fn.Name
+}
+
+// annotationsAbove collects the "@"-prefixed lines (decorators or
+// annotations, possibly spanning several lines) directly above a 1-indexed
+// line, top first
+func annotationsAbove(ff *treesitter.FileFlow, line int) []string {
+	var out, buf []string
+	depth := 0
+	for i := line - 2; i >= 0; i-- {
+		text := strings.TrimSpace(ff.Lines[i])
+		depth += strings.Count(text, ")") + strings.Count(text, "]") - strings.Count(text, "(") - strings.Count(text, "[")
+		buf = append([]string{text}, buf...)
+		if depth > 0 {
+			continue
+		}
+		if !strings.HasPrefix(text, "@") {
+			break
+		}
+		out = append([]string{strings.Join(strings.Fields(strings.Join(buf, " ")), " ")}, out...)
+		buf = nil
+	}
+	return out
+}
+
+// RenderRoutesMarkdown renders routes as the api_endpoints memory: one table
+// per framework plus a list of routes without auth middleware
+func RenderRoutesMarkdown(r *RoutesResult) string {
+	var b strings.Builder
+	b.WriteString("# API Endpoints\n\n")
+	fmt.Fprintf(&b, "Extracted by `quokka navigate routes`: %d route(s) in %d file(s) scanned.\n", r.Total, r.FilesScanned)
+
+	var unauth []Route
+	for _, fw := range r.Frameworks {
+		fmt.Fprintf(&b, "\n## %s\n\n", fw)
+		b.WriteString("| Method | Path | Handler | Location | Middleware |\n")
+		b.WriteString("|--------|------|---------|----------|------------|\n")
+		for _, rt := range r.Routes {
+			if rt.Framework != fw {
+				continue
+			}
+			fmt.Fprintf(&b, "| %s | `%s` | `%s` | %s:%d | %s |\n",
+				rt.Method, rt.Path, rt.Handler, rt.File, rt.Line, markdownCell(strings.Join(rt.Middleware, ", ")))
+			if len(rt.Auth) == 0 {
+				unauth = append(unauth, rt)
+			}
+		}
+	}
+
+	if len(unauth) > 0 {
+		b.WriteString("\n## Routes Without Detected Auth\n\n")
+		for _, rt := range unauth {
+			fmt.Fprintf(&b, "- %s `%s` (%s:%d)\n", rt.Method, rt.Path, rt.File, rt.Line)
+		}
+	}
+	for i, n := range r.Notes {
+		if i == 0 {
+			b.WriteString("\n## Notes\n\n")
+		}
+		fmt.Fprintf(&b, "- %s\n", n)
+	}
+	return b.String()
+}
+
+// markdownCell escapes pipes so a value fits in one table cell
+func markdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
@@ -0,0 +1,290 @@
+package navigate
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/diffsec/quokka/internal/treesitter"
+)
+
+// ginVerbs maps gin RouterGroup methods to the HTTP method they register
+var ginVerbs = map[string]string{
+	"GET":     "GET",
+	"POST":    "POST",
+	"PUT":     "PUT",
+	"PATCH":   "PATCH",
+	"DELETE":  "DELETE",
+	"HEAD":    "HEAD",
+	"OPTIONS": "OPTIONS",
+	"Any":     MethodAny,
+}
+
+// chiVerbs maps chi Router methods to the HTTP method they register
+var chiVerbs = map[string]string{
+	"Get":        "GET",
+	"Post":       "POST",
+	"Put":        "PUT",
+	"Patch":      "PATCH",
+	"Delete":     "DELETE",
+	"Head":       "HEAD",
+	"Options":    "OPTIONS",
+	"Connect":    "CONNECT",
+	"Trace":      "TRACE",
+	"Handle":     MethodAny,
+	"HandleFunc": MethodAny,
+}
+
+// httpAdapters wrap a handler without adding behaviour worth reporting; the
+// value is the index of the wrapped handler argument
+var httpAdapters = map[string]int{
+	"http.HandlerFunc":     0,
+	"http.StripPrefix":     1,
+	"http.TimeoutHandler":  0,
+	"http.MaxBytesHandler": 0,
+}
+
+// wrapperRE recognises function names that wrap a handler in middleware
+var wrapperRE = regexp.MustCompile(`(?i)auth|login|jwt|token|session|permission|role|secur|guard|protect|require|middleware|^mw|with|wrap|chain|log|cors|csrf|limit|recover|timeout|gzip|compress|trace|metric`)
+
+// chiWithRE matches the .With(mw...) segments of a chi receiver
+var chiWithRE = regexp.MustCompile(`\.With\(([^()]*(?:\([^()]*\)[^()]*)*)\)`)
+
+// goGroup is a gin route group assigned to a variable
+type goGroup struct {
+	parent     string
+	prefix     string
+	middleware []string
+}
+
+// goUse is a Use() call on a router variable, scoped to the chi Route/Group
+// closure it appears in (scope -1 for top level)
+type goUse struct {
+	recv       string
+	start      int
+	scope      int
+	middleware []string
+}
+
+// goScope is a chi Route/Group closure body
+type goScope struct {
+	start, end int
+	prefix     string
+}
+
+// goRoutes extracts gin, chi and net/http routes
+func goRoutes(ff *treesitter.FileFlow) []Route {
+	src := fileText(ff)
+	gin := strings.Contains(src, `"github.com/gin-gonic/gin"`)
+	chi := strings.Contains(src, `"github.com/go-chi/chi`)
+	nethttp := strings.Contains(src, `"net/http"`)
+	if !gin && !chi && !nethttp {
+		return nil
+	}
+
+	groups := make(map[string]goGroup)
+	if gin {
+		for _, a := range ff.Assigns {
+			if len(a.Targets) != 1 {
+				continue
+			}
+			idx := strings.Index(a.Value, ".Group(")
+			if idx < 0 {
+				continue
+			}
+			args := treesitter.SplitArgs(a.Value[idx+len(".Group"):])
+			if len(args) == 0 {
+				continue
+			}
+			if prefix, ok := stringLiteral(args[0]); ok {
+				groups[a.Targets[0]] = goGroup{parent: a.Value[:idx], prefix: prefix, middleware: exprNames(args[1:])}
+			}
+		}
+	}
+
+	var scopes []goScope
+	if chi {
+		for _, c := range ff.Calls {
+			name := c.Name()
+			if name != "Route" && name != "Group" || len(c.Args) == 0 || !isFunctionLiteral(c.Args[len(c.Args)-1]) {
+				continue
+			}
+			s := goScope{start: c.ArgStarts[len(c.Args)-1], end: c.EndByte}
+			if name == "Route" {
+				s.prefix, _ = stringLiteral(c.Args[0])
+			}
+			scopes = append(scopes, s)
+		}
+	}
+	innermost := func(offset int) int {
+		best := -1
+		for i, s := range scopes {
+			if offset >= s.start && offset < s.end && (best < 0 || s.start > scopes[best].start) {
+				best = i
+			}
+		}
+		return best
+	}
+
+	var uses []goUse
+	for _, c := range ff.Calls {
+		if c.Name() == "Use" {
+			uses = append(uses, goUse{
+				recv:       receiverOf(c.Callee),
+				start:      c.StartByte,
+				scope:      innermost(c.StartByte),
+				middleware: exprNames(c.Args),
+			})
+		}
+	}
+	usesOn := func(recv string, c treesitter.FlowCall) []string {
+		var mw []string
+		for _, u := range uses {
+			if u.recv != recv || u.start >= c.StartByte {
+				continue
+			}
+			if u.scope >= 0 && (c.StartByte < scopes[u.scope].start || c.StartByte >= scopes[u.scope].end) {
+				continue
+			}
+			mw = append(mw, u.middleware...)
+		}
+		return mw
+	}
+
+	var routes []Route
+	for _, c := range ff.Calls {
+		name, recv := c.Name(), receiverOf(c.Callee)
+		if recv == "" || len(c.Args) < 2 {
+			continue
+		}
+		r := Route{File: ff.Path, Line: c.Line}
+		var handlerArgs []string
+		switch {
+		case gin && recv != "http" && (ginVerbs[name] != "" || name == "Handle" && len(c.Args) >= 3):
+			args := c.Args
+			if name == "Handle" {
+				r.Method, _ = stringLiteral(args[0])
+				args = args[1:]
+			} else {
+				r.Method = ginVerbs[name]
+			}
+			route, ok := stringLiteral(args[0])
+			if !ok || r.Method == "" {
+				continue
+			}
+			prefix, mw := ginChain(recv, groups, c, usesOn)
+			r.Framework, r.Path, r.Middleware = FrameworkGin, joinRoute(prefix, route), mw
+			handlerArgs = args[1:]
+
+		case chi && recv != "http" && (chiVerbs[name] != "" || (name == "Method" || name == "MethodFunc") && len(c.Args) >= 3):
+			args := c.Args
+			if name == "Method" || name == "MethodFunc" {
+				r.Method, _ = stringLiteral(args[0])
+				args = args[1:]
+			} else {
+				r.Method = chiVerbs[name]
+			}
+			route, ok := stringLiteral(args[0])
+			if !ok || r.Method == "" {
+				continue
+			}
+			base := recv
+			var with []string
+			if idx := strings.Index(recv, ".With("); idx >= 0 {
+				base = recv[:idx]
+				for _, m := range chiWithRE.FindAllStringSubmatch(recv[idx:], -1) {
+					with = append(with, exprNames(treesitter.SplitArgs(m[1]))...)
+				}
+			}
+			var prefixes []string
+			for _, s := range scopes {
+				if c.StartByte >= s.start && c.StartByte < s.end {
+					prefixes = append(prefixes, s.prefix)
+				}
+			}
+			r.Framework, r.Path = FrameworkChi, joinRoute(append(prefixes, route)...)
+			r.Middleware = append(usesOn(base, c), with...)
+			handlerArgs = args[1:]
+
+		case nethttp && (name == "Handle" || name == "HandleFunc") && len(c.Args) == 2:
+			pattern, ok := stringLiteral(c.Args[0])
+			if !ok {
+				continue
+			}
+			r.Method, r.Path = MethodAny, pattern
+			if method, rest, found := strings.Cut(pattern, " "); found && method == strings.ToUpper(method) {
+				r.Method, r.Path = method, strings.TrimSpace(rest)
+			}
+			r.Framework = FrameworkNetHTTP
+			handlerArgs = c.Args[1:]
+
+		default:
+			continue
+		}
+		if len(handlerArgs) == 0 {
+			continue
+		}
+		r.Middleware = append(r.Middleware, exprNames(handlerArgs[:len(handlerArgs)-1])...)
+		handler, wrappers := unwrapGoHandler(handlerArgs[len(handlerArgs)-1])
+		r.Handler = handler
+		r.Middleware = append(r.Middleware, wrappers...)
+		routes = append(routes, r)
+	}
+	return routes
+}
+
+// ginChain resolves a gin receiver through its Group() assignments,
+// returning the joined prefix and the group and Use() middleware, outermost
+// first
+func ginChain(recv string, groups map[string]goGroup, c treesitter.FlowCall, usesOn func(string, treesitter.FlowCall) []string) (string, []string) {
+	var prefixes []string
+	var mw []string
+	cur := recv
+	for depth := 0; depth < 16; depth++ {
+		mw = append(usesOn(cur, c), mw...)
+		g, ok := groups[cur]
+		if !ok {
+			break
+		}
+		prefixes = append([]string{g.prefix}, prefixes...)
+		mw = append(append([]string(nil), g.middleware...), mw...)
+		cur = g.parent
+	}
+	return joinRoute(prefixes...), mw
+}
+
+// unwrapGoHandler peels middleware wrappers such as auth(http.HandlerFunc(h))
+// off a handler argument. Only single-argument calls whose name looks like
+// middleware are peeled, so handler factories (h.List(svc), NewAPI(db)) are
+// kept as the handler.
+func unwrapGoHandler(arg string) (string, []string) {
+	var mw []string
+	for depth := 0; depth < 8; depth++ {
+		arg = strings.TrimSpace(arg)
+		if isFunctionLiteral(arg) {
+			return "<anonymous>", mw
+		}
+		idx := strings.Index(arg, "(")
+		if idx <= 0 || !strings.HasSuffix(arg, ")") {
+			break
+		}
+		fn := arg[:idx]
+		args := treesitter.SplitArgs(arg[idx:])
+		if i, ok := httpAdapters[fn]; ok {
+			if i >= len(args) {
+				break
+			}
+			arg = args[i]
+			continue
+		}
+		last := fn
+		if i := strings.LastIndex(fn, "."); i >= 0 {
+			last = fn[i+1:]
+		}
+		if len(args) != 1 || strings.HasPrefix(last, "New") || strings.HasPrefix(last, "new") || !wrapperRE.MatchString(last) {
+			break
+		}
+		mw = append(mw, fn)
+		arg = args[0]
+	}
+	return exprName(arg), mw
+}
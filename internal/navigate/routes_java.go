@@ -0,0 +1,240 @@
+package navigate
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/diffsec/quokka/internal/treesitter"
+)
+
+// springMappings maps Spring MVC mapping annotations to their HTTP method;
+// "" means the method comes from RequestMapping(method = ...)
+var springMappings = map[string]string{
+	"GetMapping":     "GET",
+	"PostMapping":    "POST",
+	"PutMapping":     "PUT",
+	"DeleteMapping":  "DELETE",
+	"PatchMapping":   "PATCH",
+	"RequestMapping": "",
+}
+
+// springNoise are annotations that say nothing about who may call a route
+var springNoise = map[string]bool{
+	"Override": true, "Deprecated": true, "SuppressWarnings": true,
+	"ResponseBody": true, "ResponseStatus": true, "RestController": true,
+	"Controller": true, "Component": true, "Slf4j": true, "Transactional": true,
+	"Operation": true, "ApiOperation": true, "ApiResponse": true, "ApiResponses": true,
+	"Tag": true, "Api": true, "Validated": true,
+}
+
+// javaClassRE matches a class or interface declaration
+var javaClassRE = regexp.MustCompile(`\b(?:class|interface)\s+(\w+)`)
+
+// javaAnnotation is one parsed @Name(args) annotation
+type javaAnnotation struct {
+	name string
+	args string
+	text string
+}
+
+// springController holds the class-level mapping prefixes and annotations
+type springController struct {
+	prefixes   []string
+	middleware []string
+}
+
+// springRoutes extracts @GetMapping/@RequestMapping handler methods
+func springRoutes(ff *treesitter.FileFlow) []Route {
+	src := fileText(ff)
+	if !strings.Contains(src, "org.springframework") {
+		return nil
+	}
+
+	controllers := make(map[string]springController)
+	var routes []Route
+	for i := range ff.Functions {
+		fn := &ff.Functions[i]
+		annotations := methodAnnotations(ff, src, fn)
+		var mapping *javaAnnotation
+		var mw []string
+		for j, a := range annotations {
+			if _, ok := springMappings[a.name]; ok && mapping == nil {
+				mapping = &annotations[j]
+			} else if !springNoise[a.name] {
+				mw = append(mw, a.text)
+			}
+		}
+		if mapping == nil {
+			continue
+		}
+		ctrl, ok := controllers[fn.Parent]
+		if !ok {
+			ctrl = springControllerFor(ff, fn.Parent)
+			controllers[fn.Parent] = ctrl
+		}
+
+		args := treesitter.SplitArgs(mapping.args)
+		paths := mappingPaths(args)
+		methods := []string{springMappings[mapping.name]}
+		if methods[0] == "" {
+			methods = []string{MethodAny}
+			if v, ok := keywordArg(args, "method"); ok {
+				methods = nil
+				for _, m := range strings.Split(strings.Trim(v, "{}"), ",") {
+					m = strings.TrimSpace(m)
+					if idx := strings.LastIndex(m, "."); idx >= 0 {
+						m = m[idx+1:]
+					}
+					if m != "" {
+						methods = append(methods, m)
+					}
+				}
+			}
+		}
+
+		middleware := append(append([]string(nil), ctrl.middleware...), mw...)
+		for _, prefix := range ctrl.prefixes {
+			for _, p := range paths {
+				for _, m := range methods {
+					routes = append(routes, Route{
+						Method:     m,
+						Path:       joinRoute(prefix, p),
+						Handler:    qualifiedName(fn),
+						File:       ff.Path,
+						Line:       fn.Line,
+						Framework:  FrameworkSpring,
+						Middleware: middleware,
+					})
+				}
+			}
+		}
+	}
+	return routes
+}
+
+// methodAnnotations returns the annotations of a method: those inside its
+// declaration (modifiers) and any directly above it
+func methodAnnotations(ff *treesitter.FileFlow, src string, fn *treesitter.FlowFunction) []javaAnnotation {
+	header := src[fn.StartByte:min(fn.EndByte, len(src))]
+	if idx := strings.Index(header, fn.Name+"("); idx >= 0 {
+		header = header[:idx]
+	} else if idx := strings.Index(header, fn.Name+" ("); idx >= 0 {
+		header = header[:idx]
+	}
+	above := strings.Join(annotationsAbove(ff, fn.Line), "\n")
+	return parseAnnotations(above + "\n" + header)
+}
+
+// springControllerFor reads the class-level @RequestMapping prefix and
+// annotations of class name
+func springControllerFor(ff *treesitter.FileFlow, name string) springController {
+	ctrl := springController{prefixes: []string{""}}
+	for i, line := range ff.Lines {
+		m := javaClassRE.FindStringSubmatchIndex(line)
+		if m == nil || line[m[2]:m[3]] != name {
+			continue
+		}
+		text := strings.Join(annotationsAbove(ff, i+1), "\n") + "\n" + line[:m[0]]
+		for _, a := range parseAnnotations(text) {
+			switch {
+			case a.name == "RequestMapping":
+				if paths := mappingPaths(treesitter.SplitArgs(a.args)); len(paths) > 0 {
+					ctrl.prefixes = paths
+				}
+			case !springNoise[a.name]:
+				ctrl.middleware = append(ctrl.middleware, a.text)
+			}
+		}
+		break
+	}
+	return ctrl
+}
+
+// mappingPaths returns the paths of a mapping annotation's arguments
+// ("/x", value = "/x", path = {"/a", "/b"}); [""] when none are given
+func mappingPaths(args []string) []string {
+	var paths []string
+	for _, a := range positionalArgs(args) {
+		paths = append(paths, stringList(a)...)
+	}
+	for _, key := range []string{"value", "path"} {
+		if v, ok := keywordArg(args, key); ok {
+			paths = append(paths, stringList(v)...)
+		}
+	}
+	if len(paths) == 0 {
+		return []string{""}
+	}
+	return paths
+}
+
+// parseAnnotations scans text for @Name or @Name(...) annotations, skipping
+// string literals and balancing parentheses in the arguments
+func parseAnnotations(text string) []javaAnnotation {
+	var out []javaAnnotation
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '"':
+			for i++; i < len(text) && text[i] != '"'; i++ {
+				if text[i] == '\\' {
+					i++
+				}
+			}
+			continue
+		case '@':
+		default:
+			continue
+		}
+		start := i
+		j := i + 1
+		for j < len(text) && (isIdentByte(text[j]) || text[j] == '.') {
+			j++
+		}
+		name := text[i+1 : j]
+		if idx := strings.LastIndex(name, "."); idx >= 0 {
+			name = name[idx+1:]
+		}
+		if name == "" || name == "interface" {
+			continue
+		}
+		a := javaAnnotation{name: name}
+		k := j
+		for k < len(text) && (text[k] == ' ' || text[k] == '\t') {
+			k++
+		}
+		if k < len(text) && text[k] == '(' {
+			depth := 0
+			var quote byte
+			end := k
+			for ; end < len(text); end++ {
+				c := text[end]
+				if quote != 0 {
+					if c == '\\' {
+						end++
+					} else if c == quote {
+						quote = 0
+					}
+					continue
+				}
+				if c == '"' || c == '\'' {
+					quote = c
+				} else if c == '(' {
+					depth++
+				} else if c == ')' {
+					depth--
+					if depth == 0 {
+						break
+					}
+				}
+			}
+			if end < len(text) {
+				a.args = text[k+1 : end]
+				j = end + 1
+			}
+		}
+		a.text = strings.Join(strings.Fields(text[start:j]), " ")
+		out = append(out, a)
+		i = j - 1
+	}
+	return out
+}
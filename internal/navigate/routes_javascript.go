@@ -0,0 +1,113 @@
+package navigate
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/diffsec/quokka/internal/treesitter"
+)
+
+// expressVerbs maps Express router methods to the HTTP method they register
+var expressVerbs = map[string]string{
+	"get":     "GET",
+	"post":    "POST",
+	"put":     "PUT",
+	"patch":   "PATCH",
+	"delete":  "DELETE",
+	"del":     "DELETE",
+	"options": "OPTIONS",
+	"head":    "HEAD",
+	"all":     MethodAny,
+}
+
+var (
+	// expressImportRE matches require("express") and import ... from "express"
+	expressImportRE = regexp.MustCompile(`['"]express['"]`)
+	// expressChainRE matches router.route("/path") in a chained callee
+	expressChainRE = regexp.MustCompile("^([\\w$.]+)\\.route\\(\\s*(['\"`][^'\"`]*['\"`])\\s*\\)")
+	// expressRouterRE matches a plain router receiver such as app or api.v1
+	expressRouterRE = regexp.MustCompile(`^[\w$.]+$`)
+)
+
+// httpClients are receivers whose get/post calls are outgoing requests
+var httpClients = map[string]bool{
+	"axios": true, "got": true, "http": true, "https": true, "request": true, "superagent": true,
+}
+
+// expressUse is a router.use() call: middleware applied to later routes of
+// the same router whose path starts with prefix
+type expressUse struct {
+	start      int
+	prefix     string
+	middleware []string
+}
+
+// expressRoutes extracts app.get("/path", mw..., handler) style routes from
+// files that load Express
+func expressRoutes(ff *treesitter.FileFlow) []Route {
+	if !expressImportRE.MatchString(fileText(ff)) {
+		return nil
+	}
+
+	uses := make(map[string][]expressUse)
+	for _, c := range ff.Calls {
+		if c.Name() != "use" || len(c.Args) == 0 {
+			continue
+		}
+		args := c.Args
+		u := expressUse{start: c.StartByte}
+		if p, ok := stringLiteral(args[0]); ok {
+			u.prefix, args = p, args[1:]
+		}
+		u.middleware = exprNames(args)
+		recv := receiverOf(c.Callee)
+		uses[recv] = append(uses[recv], u)
+	}
+
+	var routes []Route
+	for _, c := range ff.Calls {
+		method, ok := expressVerbs[c.Name()]
+		recv := receiverOf(c.Callee)
+		if !ok || recv == "" || len(c.Args) == 0 {
+			continue
+		}
+		args := c.Args
+		var route string
+		if m := expressChainRE.FindStringSubmatch(recv); m != nil {
+			recv = m[1]
+			route, _ = stringLiteral(m[2])
+		} else {
+			if !expressRouterRE.MatchString(recv) || httpClients[recv] {
+				continue
+			}
+			p, ok := stringLiteral(args[0])
+			if !ok || len(args) < 2 || !strings.HasPrefix(p, "/") && p != "*" {
+				continue
+			}
+			route, args = p, args[1:]
+		}
+
+		var mw []string
+		for _, u := range uses[recv] {
+			if u.start < c.StartByte && strings.HasPrefix(route, u.prefix) {
+				mw = append(mw, u.middleware...)
+			}
+		}
+		names := exprNames(args)
+		if len(names) == 0 {
+			continue
+		}
+		mw = append(mw, names[:len(names)-1]...)
+
+		routes = append(routes, Route{
+			Method:     method,
+			Path:       route,
+			Handler:    names[len(names)-1],
+			File:       ff.Path,
+			Line:       c.Line,
+			Framework:  FrameworkExpress,
+			Middleware: mw,
+		})
+	}
+	return routes
+}
@@ -0,0 +1,380 @@
+package navigate
+
+import (
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/diffsec/quokka/internal/treesitter"
+)
+
+// pythonRouteVerbs maps Flask/FastAPI route decorator names to the method
+// they register; "" means the method comes from methods=[...]
+var pythonRouteVerbs = map[string]string{
+	"route":     "",
+	"api_route": "",
+	"get":       "GET",
+	"post":      "POST",
+	"put":       "PUT",
+	"patch":     "PATCH",
+	"delete":    "DELETE",
+	"head":      "HEAD",
+	"options":   "OPTIONS",
+	"websocket": "WEBSOCKET",
+}
+
+// dependsRE matches FastAPI dependency injection: Depends(get_user),
+// Security(oauth2_scheme, scopes=[...])
+var dependsRE = regexp.MustCompile(`\b(Depends|Security)\(\s*([\w.]*)`)
+
+// pyRouter is a Flask Blueprint or FastAPI APIRouter declared in a file
+type pyRouter struct {
+	prefix     string
+	middleware []string
+}
+
+// pythonRoutes extracts Flask and FastAPI decorator routes
+func pythonRoutes(ff *treesitter.FileFlow) []Route {
+	src := fileText(ff)
+	framework := ""
+	switch {
+	case strings.Contains(src, "fastapi"):
+		framework = FrameworkFastAPI
+	case strings.Contains(src, "flask"):
+		framework = FrameworkFlask
+	default:
+		return nil
+	}
+	routers := pythonRouters(ff)
+
+	var routes []Route
+	for _, c := range ff.Calls {
+		verb, ok := pythonRouteVerbs[c.Name()]
+		recv := receiverOf(c.Callee)
+		if !ok || recv == "" || !strings.HasPrefix(ff.LineText(c.Line), "@") {
+			continue
+		}
+		route, ok := "", false
+		if pos := positionalArgs(c.Args); len(pos) > 0 {
+			route, ok = stringLiteral(pos[0])
+		} else if v, found := keywordArg(c.Args, "rule", "path"); found {
+			route, ok = stringLiteral(v)
+		}
+		fn := functionAfter(ff, c.EndByte)
+		if !ok || fn == nil {
+			continue
+		}
+
+		methods := []string{verb}
+		if verb == "" {
+			methods = []string{"GET"}
+			if v, found := keywordArg(c.Args, "methods"); found {
+				if list := stringList(v); len(list) > 0 {
+					methods = list
+				}
+			}
+		}
+
+		router := routers[recv]
+		mw := append([]string(nil), router.middleware...)
+		if v, found := keywordArg(c.Args, "dependencies"); found {
+			mw = append(mw, dependencies(v)...)
+		}
+		for _, d := range annotationsAbove(ff, fn.Line) {
+			if !isPythonRouteDecorator(d) {
+				mw = append(mw, strings.TrimPrefix(d, "@"))
+			}
+		}
+		mw = append(mw, dependencies(pythonSignature(ff, fn))...)
+
+		for _, m := range methods {
+			routes = append(routes, Route{
+				Method:     strings.ToUpper(m),
+				Path:       joinRoute(router.prefix, route),
+				Handler:    qualifiedName(fn),
+				File:       ff.Path,
+				Line:       c.Line,
+				Framework:  framework,
+				Middleware: mw,
+			})
+		}
+	}
+	return routes
+}
+
+// pythonRouters indexes Blueprint/APIRouter/FastAPI assignments by variable
+// name, recording their URL prefix and router-wide dependencies
+func pythonRouters(ff *treesitter.FileFlow) map[string]pyRouter {
+	routers := make(map[string]pyRouter)
+	for _, a := range ff.Assigns {
+		if len(a.Targets) != 1 {
+			continue
+		}
+		idx := strings.Index(a.Value, "(")
+		if idx < 0 {
+			continue
+		}
+		ctor := a.Value[:idx]
+		if i := strings.LastIndex(ctor, "."); i >= 0 {
+			ctor = ctor[i+1:]
+		}
+		if ctor != "Blueprint" && ctor != "APIRouter" && ctor != "FastAPI" {
+			continue
+		}
+		args := treesitter.SplitArgs(a.Value[idx:])
+		var r pyRouter
+		if v, ok := keywordArg(args, "url_prefix", "prefix"); ok {
+			r.prefix, _ = stringLiteral(v)
+		}
+		if v, ok := keywordArg(args, "dependencies"); ok {
+			r.middleware = dependencies(v)
+		}
+		routers[a.Targets[0]] = r
+	}
+	return routers
+}
+
+// isPythonRouteDecorator reports whether a decorator registers a route
+func isPythonRouteDecorator(d string) bool {
+	name := strings.TrimPrefix(d, "@")
+	if idx := strings.Index(name, "("); idx >= 0 {
+		name = name[:idx]
+	}
+	idx := strings.LastIndex(name, ".")
+	if idx < 0 {
+		return false
+	}
+	_, ok := pythonRouteVerbs[name[idx+1:]]
+	return ok
+}
+
+// dependencies lists the Depends()/Security() calls in text
+func dependencies(text string) []string {
+	var out []string
+	for _, m := range dependsRE.FindAllStringSubmatch(text, -1) {
+		out = append(out, m[1]+"("+m[2]+")")
+	}
+	return out
+}
+
+// pythonSignature returns a function's def header, which may span lines
+func pythonSignature(ff *treesitter.FileFlow, fn *treesitter.FlowFunction) string {
+	var b strings.Builder
+	depth := 0
+	for i := fn.Line - 1; i < len(ff.Lines) && i < fn.EndLine; i++ {
+		line := ff.Lines[i]
+		b.WriteString(line)
+		b.WriteByte('\n')
+		depth += strings.Count(line, "(") - strings.Count(line, ")")
+		if depth <= 0 && strings.HasSuffix(strings.TrimSpace(line), ":") {
+			break
+		}
+	}
+	return b.String()
+}
+
+// djangoClassRE matches a class definition and its bases
+var djangoClassRE = regexp.MustCompile(`^\s*class\s+(\w+)\s*\(([^)]*)\)`)
+
+// djangoInclude is an include() entry: the urls module mounted at route
+type djangoInclude struct {
+	from   string
+	route  string
+	module string
+}
+
+// djangoEntry is a path()/re_path()/url() entry pointing at a view
+type djangoEntry struct {
+	ff    *treesitter.FileFlow
+	line  int
+	route string
+	view  string
+	mw    []string
+}
+
+// djangoRoutes extracts the urlpatterns of every urls module, resolving
+// include() prefixes across files
+func djangoRoutes(files []*treesitter.FileFlow) []Route {
+	var entries []djangoEntry
+	var includes []djangoInclude
+	modules := make(map[string]string) // file -> dotted module
+	for _, ff := range files {
+		if !strings.Contains(fileText(ff), "urlpatterns") {
+			continue
+		}
+		modules[ff.Path] = strings.ReplaceAll(strings.TrimSuffix(ff.Path, ".py"), "/", ".")
+		for _, c := range ff.Calls {
+			name, recv := c.Name(), receiverOf(c.Callee)
+			if name != "path" && name != "re_path" && name != "url" || recv != "" && recv != "urls" && recv != "django.urls" {
+				continue
+			}
+			if ff.EnclosingFunction(c.StartByte) >= 0 || len(c.Args) < 2 {
+				continue
+			}
+			route, ok := stringLiteral(c.Args[0])
+			if !ok {
+				continue
+			}
+			route = strings.TrimSuffix(strings.TrimPrefix(route, "^"), "$")
+			view := strings.TrimSpace(c.Args[1])
+			if strings.HasPrefix(view, "include(") {
+				args := treesitter.SplitArgs(view[len("include"):])
+				if len(args) == 0 {
+					continue
+				}
+				target := args[0]
+				if strings.HasPrefix(target, "(") {
+					if inner := treesitter.SplitArgs(target); len(inner) > 0 {
+						target = inner[0]
+					}
+				}
+				if module, ok := stringLiteral(target); ok {
+					includes = append(includes, djangoInclude{from: ff.Path, route: route, module: module})
+				}
+				continue
+			}
+			view, mw := unwrapDjangoView(view)
+			entries = append(entries, djangoEntry{ff: ff, line: c.Line, route: route, view: view, mw: mw})
+		}
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	mountedAt := make(map[string][]djangoInclude) // included file -> includes
+	for _, in := range includes {
+		for file, module := range modules {
+			if module == in.module || strings.HasSuffix(module, "."+in.module) {
+				mountedAt[file] = append(mountedAt[file], in)
+			}
+		}
+	}
+	var prefixes func(file string, seen map[string]bool) []string
+	prefixes = func(file string, seen map[string]bool) []string {
+		mounts := mountedAt[file]
+		if len(mounts) == 0 || seen[file] {
+			return []string{""}
+		}
+		seen[file] = true
+		defer delete(seen, file)
+		var out []string
+		for _, in := range mounts {
+			for _, pre := range prefixes(in.from, seen) {
+				if p := pre + in.route; !slices.Contains(out, p) {
+					out = append(out, p)
+				}
+			}
+		}
+		return out
+	}
+
+	views := &djangoViews{files: files}
+	var routes []Route
+	for _, e := range entries {
+		mw := append(views.middleware(e.view), e.mw...)
+		for _, pre := range prefixes(e.ff.Path, map[string]bool{}) {
+			routes = append(routes, Route{
+				Method:     MethodAny,
+				Path:       "/" + strings.TrimPrefix(pre+e.route, "/"),
+				Handler:    e.view,
+				File:       e.ff.Path,
+				Line:       e.line,
+				Framework:  FrameworkDjango,
+				Middleware: mw,
+			})
+		}
+	}
+	return routes
+}
+
+// unwrapDjangoView strips X.as_view(...) and peels decorator calls such as
+// login_required(views.index) into middleware
+func unwrapDjangoView(view string) (string, []string) {
+	var mw []string
+	for i := 0; i < 5; i++ {
+		view = strings.TrimSpace(view)
+		if idx := strings.Index(view, ".as_view("); idx >= 0 {
+			return view[:idx], mw
+		}
+		idx := strings.Index(view, "(")
+		if idx <= 0 || !strings.HasSuffix(view, ")") {
+			break
+		}
+		args := treesitter.SplitArgs(view[idx:])
+		if len(args) == 0 {
+			break
+		}
+		mw = append(mw, view[:idx])
+		view = args[0]
+	}
+	return view, mw
+}
+
+// djangoViews resolves view references to their decorators and, for class
+// based views, to mixins and method_decorator()s
+type djangoViews struct {
+	files []*treesitter.FileFlow
+}
+
+// middleware returns the decorators and auth mixins of the view named by ref
+// (e.g. "views.detail" or "ArticleView"), preferring a definition in the
+// module the reference is qualified with
+func (v *djangoViews) middleware(ref string) []string {
+	name, qualifier := ref, ""
+	if idx := strings.LastIndex(ref, "."); idx >= 0 {
+		name, qualifier = ref[idx+1:], ref[:idx]
+		if i := strings.LastIndex(qualifier, "."); i >= 0 {
+			qualifier = qualifier[i+1:]
+		}
+	}
+	var fallback []string
+	found := false
+	for _, ff := range v.files {
+		mw, ok := v.lookup(ff, name)
+		if !ok {
+			continue
+		}
+		if qualifier != "" && strings.TrimSuffix(path.Base(ff.Path), ".py") == qualifier {
+			return mw
+		}
+		if !found {
+			fallback, found = mw, true
+		}
+	}
+	return fallback
+}
+
+// lookup finds a module-level function or class called name in ff
+func (v *djangoViews) lookup(ff *treesitter.FileFlow, name string) ([]string, bool) {
+	for i := range ff.Functions {
+		fn := &ff.Functions[i]
+		if fn.Name == name && fn.Parent == "" {
+			return trimDecorators(annotationsAbove(ff, fn.Line)), true
+		}
+	}
+	for i, line := range ff.Lines {
+		m := djangoClassRE.FindStringSubmatch(line)
+		if m == nil || m[1] != name {
+			continue
+		}
+		mw := trimDecorators(annotationsAbove(ff, i+1))
+		for _, base := range strings.Split(m[2], ",") {
+			base = strings.TrimSpace(base)
+			if strings.HasSuffix(base, "Mixin") {
+				mw = append(mw, base)
+			}
+		}
+		return mw, true
+	}
+	return nil, false
+}
+
+// trimDecorators drops the leading "@" of each decorator
+func trimDecorators(decorators []string) []string {
+	var out []string
+	for _, d := range decorators {
+		out = append(out, strings.TrimPrefix(d, "@"))
+	}
+	return out
+}
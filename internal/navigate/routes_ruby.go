@@ -0,0 +1,396 @@
+package navigate
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/diffsec/quokka/internal/treesitter"
+)
+
+// railsVerbs maps Rails routing helpers to the HTTP method they register;
+// "" means the method comes from via:
+var railsVerbs = map[string]string{
+	"get":    "GET",
+	"post":   "POST",
+	"put":    "PUT",
+	"patch":  "PATCH",
+	"delete": "DELETE",
+	"match":  "",
+	"root":   "GET",
+}
+
+// railsAction is one of the seven REST actions generated by resources
+type railsAction struct {
+	action string
+	method string
+	suffix string
+	member bool
+}
+
+var railsResourceActions = []railsAction{
+	{"index", "GET", "", false},
+	{"create", "POST", "", false},
+	{"new", "GET", "/new", false},
+	{"edit", "GET", "/edit", true},
+	{"show", "GET", "", true},
+	{"update", "PATCH", "", true},
+	{"update", "PUT", "", true},
+	{"destroy", "DELETE", "", true},
+}
+
+var (
+	// railsMemberRE matches the opening line of a collection/member block
+	railsMemberRE = regexp.MustCompile(`^(\s*)(collection|member)\s+do\b`)
+	// railsFilterRE matches before_action filters in a controller
+	railsFilterRE = regexp.MustCompile(`^\s*(skip_)?before_(?:action|filter)\s+(.+)$`)
+)
+
+// railsBlock is a namespace/scope/resources/authenticate block in routes.rb
+type railsBlock struct {
+	kind       string
+	start, end int
+	path       string
+	module     string
+	resource   string // resources name, for nested routes
+	singular   bool
+	middleware []string
+}
+
+// isRailsRoutesFile reports whether path is config/routes.rb or a file
+// drawn from config/routes/
+func isRailsRoutesFile(p string) bool {
+	p = filepath.ToSlash(p)
+	return strings.HasSuffix(p, "config/routes.rb") || strings.Contains(p, "config/routes/")
+}
+
+// railsRoutes extracts the routes declared in a Rails routes file. Handlers
+// are reported as Controller#action, with the controller's before_action
+// filters as middleware.
+func railsRoutes(ff *treesitter.FileFlow, filters *railsFilters) []Route {
+	var blocks []railsBlock
+	for _, c := range ff.Calls {
+		if receiverOf(c.Callee) != "" || !strings.Contains(c.Text, " do") && !strings.Contains(c.Text, "{") {
+			continue
+		}
+		pos := positionalArgs(c.Args)
+		b := railsBlock{kind: c.Name(), start: c.StartByte, end: c.EndByte}
+		switch b.kind {
+		case "namespace":
+			if len(pos) == 0 {
+				continue
+			}
+			b.module = rubyName(pos[0])
+			b.path = b.module
+			if v, ok := keywordArg(c.Args, "path"); ok {
+				b.path = rubyName(v)
+			}
+		case "scope":
+			if len(pos) > 0 {
+				b.path = rubyName(pos[0])
+			} else if v, ok := keywordArg(c.Args, "path"); ok {
+				b.path = rubyName(v)
+			}
+			if v, ok := keywordArg(c.Args, "module"); ok {
+				b.module = rubyName(v)
+			}
+		case "resources", "resource":
+			if len(pos) == 0 {
+				continue
+			}
+			b.resource = rubyName(pos[0])
+			b.singular = b.kind == "resource"
+		case "authenticate", "authenticated":
+			b.middleware = []string{strings.Join(strings.Fields(ff.LineText(c.Line)), " ")}
+			b.middleware[0] = strings.TrimSuffix(strings.TrimSuffix(b.middleware[0], " do"), "{")
+		default:
+			continue
+		}
+		blocks = append(blocks, b)
+	}
+	members := railsMemberBlocks(ff)
+
+	var routes []Route
+	for _, c := range ff.Calls {
+		name := c.Name()
+		if receiverOf(c.Callee) != "" {
+			continue
+		}
+		var enclosing []railsBlock
+		for _, b := range blocks {
+			if b.start < c.StartByte && c.EndByte <= b.end {
+				enclosing = append(enclosing, b)
+			}
+		}
+		member := members[c.Line]
+		prefix, module, mw, resource := railsScope(enclosing, member)
+		if v, ok := keywordArg(c.Args, "on"); ok && resource != nil {
+			member = rubyName(v)
+			prefix, module, mw, resource = railsScope(enclosing, member)
+		}
+
+		add := func(method, route, controller, action string) {
+			if controller == "" {
+				return
+			}
+			if module != "" && !strings.Contains(controller, "/") {
+				controller = module + "/" + controller
+			}
+			routes = append(routes, Route{
+				Method:     method,
+				Path:       route,
+				Handler:    railsController(controller) + "#" + action,
+				File:       ff.Path,
+				Line:       c.Line,
+				Framework:  FrameworkRails,
+				Middleware: append(append([]string(nil), mw...), filters.For(controller, action)...),
+			})
+		}
+
+		switch {
+		case name == "resources" || name == "resource":
+			pos := positionalArgs(c.Args)
+			if len(pos) == 0 {
+				continue
+			}
+			res := rubyName(pos[0])
+			controller := res
+			if name == "resource" {
+				controller = res + "s"
+			}
+			if v, ok := keywordArg(c.Args, "controller"); ok {
+				controller = rubyName(v)
+			}
+			only, hasOnly := keywordArg(c.Args, "only")
+			except, _ := keywordArg(c.Args, "except")
+			for _, a := range railsResourceActions {
+				if name == "resource" && a.action == "index" {
+					continue
+				}
+				if hasOnly && !slices.Contains(stringList(only), a.action) || slices.Contains(stringList(except), a.action) {
+					continue
+				}
+				route := joinRoute(prefix, res)
+				if a.member && name == "resources" {
+					route = joinRoute(route, ":id")
+				}
+				add(a.method, joinRoute(route, a.suffix), controller, a.action)
+			}
+
+		case railsVerbs[name] != "" || name == "match":
+			pos := positionalArgs(c.Args)
+			var route, to string
+			switch {
+			case name == "root":
+				route = prefix
+				if len(pos) > 0 {
+					to = rubyName(pos[0])
+				}
+			case len(pos) > 0:
+				left, right, rocket := strings.Cut(pos[0], "=>")
+				route = rubyName(left)
+				if rocket {
+					to = rubyName(right)
+				}
+				route = joinRoute(prefix, route)
+			default:
+				continue
+			}
+			if v, ok := keywordArg(c.Args, "to"); ok {
+				to = rubyName(v)
+			}
+			controller, action, _ := strings.Cut(to, "#")
+			if v, ok := keywordArg(c.Args, "controller"); ok {
+				controller = rubyName(v)
+			}
+			if v, ok := keywordArg(c.Args, "action"); ok {
+				action = rubyName(v)
+			}
+			if controller == "" && resource != nil {
+				controller = resource.resource
+				if resource.singular {
+					controller += "s"
+				}
+			}
+			if action == "" && len(pos) > 0 {
+				action = path.Base(strings.Trim(rubyName(pos[0]), "/"))
+			}
+			methods := []string{railsVerbs[name]}
+			if name == "match" {
+				methods = []string{MethodAny}
+				if v, ok := keywordArg(c.Args, "via"); ok {
+					methods = nil
+					for _, m := range stringList(v) {
+						methods = append(methods, strings.ToUpper(m))
+					}
+					if m := rubyName(v); len(methods) == 0 && m != "" {
+						methods = []string{strings.ToUpper(m)}
+					}
+				}
+			}
+			for _, m := range methods {
+				add(m, route, controller, action)
+			}
+		}
+	}
+	return routes
+}
+
+// railsScope folds the enclosing blocks, outermost first, into a path prefix,
+// controller module and middleware. Resources blocks contribute
+// /photos/:photo_id to nested routes, or /photos (collection) and
+// /photos/:id (member) when the route sits in such a block.
+func railsScope(enclosing []railsBlock, member string) (string, string, []string, *railsBlock) {
+	var parts, modules, mw []string
+	var resource *railsBlock
+	for i, b := range enclosing {
+		mw = append(mw, b.middleware...)
+		if b.module != "" {
+			modules = append(modules, b.module)
+		}
+		switch {
+		case b.resource == "":
+			parts = append(parts, b.path)
+		case b.singular:
+			parts = append(parts, b.resource)
+		case i == len(enclosing)-1 && member == "collection":
+			parts = append(parts, b.resource)
+		case i == len(enclosing)-1 && member == "member":
+			parts = append(parts, b.resource, ":id")
+		default:
+			parts = append(parts, b.resource, ":"+singularize(b.resource)+"_id")
+		}
+		if b.resource != "" {
+			resource = &enclosing[i]
+		}
+	}
+	return joinRoute(parts...), strings.Join(modules, "/"), mw, resource
+}
+
+// railsMemberBlocks maps each line inside a collection/member block to the
+// block kind, using the block's indentation to find its closing end
+func railsMemberBlocks(ff *treesitter.FileFlow) map[int]string {
+	out := make(map[int]string)
+	for i, line := range ff.Lines {
+		m := railsMemberRE.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		for j := i + 1; j < len(ff.Lines); j++ {
+			if ff.Lines[j] == m[1]+"end" || strings.TrimRight(ff.Lines[j], " \t") == m[1]+"end" {
+				break
+			}
+			out[j+1] = m[2]
+		}
+	}
+	return out
+}
+
+// rubyName unquotes a string literal or symbol
+func rubyName(s string) string {
+	s = strings.TrimSpace(s)
+	if v, ok := stringLiteral(s); ok {
+		return v
+	}
+	return strings.TrimPrefix(s, ":")
+}
+
+// railsController renders admin/users as Admin::UsersController
+func railsController(controller string) string {
+	var parts []string
+	for _, seg := range strings.Split(controller, "/") {
+		var b strings.Builder
+		for _, word := range strings.Split(seg, "_") {
+			if word != "" {
+				b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+			}
+		}
+		parts = append(parts, b.String())
+	}
+	return strings.Join(parts, "::") + "Controller"
+}
+
+// singularize is the naive inflection Rails applies to nested resource ids
+func singularize(s string) string {
+	switch {
+	case strings.HasSuffix(s, "ies"):
+		return strings.TrimSuffix(s, "ies") + "y"
+	case strings.HasSuffix(s, "sses"), strings.HasSuffix(s, "xes"):
+		return s[:len(s)-2]
+	case strings.HasSuffix(s, "s"):
+		return s[:len(s)-1]
+	}
+	return s
+}
+
+// railsFilter is one before_action/skip_before_action line
+type railsFilter struct {
+	skip   bool
+	names  []string
+	only   []string
+	except []string
+}
+
+// railsFilters reads controller before_action filters on demand
+type railsFilters struct {
+	root  string
+	cache map[string][]railsFilter
+}
+
+func newRailsFilters(root string) *railsFilters {
+	return &railsFilters{root: root, cache: make(map[string][]railsFilter)}
+}
+
+// For returns the filters that run before controller#action: the
+// ApplicationController's, then the controller's own, minus skipped ones
+func (f *railsFilters) For(controller, action string) []string {
+	var active []string
+	for _, file := range []string{"application", controller} {
+		for _, flt := range f.load(file) {
+			if len(flt.only) > 0 && !slices.Contains(flt.only, action) || slices.Contains(flt.except, action) {
+				continue
+			}
+			for _, n := range flt.names {
+				if flt.skip {
+					active = slices.DeleteFunc(active, func(a string) bool { return a == n })
+				} else if !slices.Contains(active, n) {
+					active = append(active, n)
+				}
+			}
+		}
+	}
+	return active
+}
+
+func (f *railsFilters) load(controller string) []railsFilter {
+	if filters, ok := f.cache[controller]; ok {
+		return filters
+	}
+	var filters []railsFilter
+	data, err := os.ReadFile(filepath.Join(f.root, "app", "controllers", filepath.FromSlash(controller)+"_controller.rb"))
+	if err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			m := railsFilterRE.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			args := treesitter.SplitArgs(m[2])
+			flt := railsFilter{skip: m[1] != ""}
+			for _, a := range positionalArgs(args) {
+				flt.names = append(flt.names, rubyName(a))
+			}
+			if v, ok := keywordArg(args, "only"); ok {
+				flt.only = stringList(v)
+			}
+			if v, ok := keywordArg(args, "except"); ok {
+				flt.except = stringList(v)
+			}
+			filters = append(filters, flt)
+		}
+	}
+	f.cache[controller] = filters
+	return filters
+}
@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"slices"
+	"sort"
 	"strings"
 
 	"github.com/diffsec/quokka/internal/project"
@@ -55,6 +56,7 @@ type Symbol struct {
 	Kind      SymbolKind `json:"kind"`
 	File      string     `json:"file"`
 	Line      int        `json:"line"`
+	EndLine   int        `json:"end_line,omitempty"`
 	Signature string     `json:"signature,omitempty"`
 	Parent    string     `json:"parent,omitempty"`
 }
@@ -66,6 +68,32 @@ type SymbolResult struct {
 	Total   int      `json:"total"`
 }
 
+// Functions returns the function and method symbols of a single-file
+// result. The regex extractor only knows where a symbol starts, so a
+// missing EndLine is estimated as the line before the next symbol; the
+// last one is left open (0, to end of file).
+func (r *SymbolResult) Functions() []Symbol {
+	starts := make([]int, 0, len(r.Symbols))
+	for _, s := range r.Symbols {
+		starts = append(starts, s.Line)
+	}
+	sort.Ints(starts)
+
+	var out []Symbol
+	for _, s := range r.Symbols {
+		if s.Kind != SymbolFunction && s.Kind != SymbolMethod {
+			continue
+		}
+		if s.EndLine == 0 {
+			if i := sort.SearchInts(starts, s.Line+1); i < len(starts) {
+				s.EndLine = starts[i] - 1
+			}
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
 // SymbolExtractor extracts symbols from source files
 type SymbolExtractor struct {
 	project *project.Project
@@ -139,11 +139,12 @@ func (e *LSPExtractor) convertSymbols(lspSymbols []lsp.DocumentSymbol, file, par
 
 	for _, ls := range lspSymbols {
 		sym := Symbol{
-			Name:   ls.Name,
-			Kind:   mapLSPKind(ls.Kind),
-			File:   file,
-			Line:   ls.Range.Start.Line + 1, // LSP lines are 0-indexed
-			Parent: parent,
+			Name:    ls.Name,
+			Kind:    mapLSPKind(ls.Kind),
+			File:    file,
+			Line:    ls.Range.Start.Line + 1, // LSP lines are 0-indexed
+			EndLine: ls.Range.End.Line + 1,
+			Parent:  parent,
 		}
 
 		if ls.Detail != "" {
@@ -45,6 +45,7 @@ func (e *TreeSitterExtractor) Extract(_ context.Context, path string) (*SymbolRe
 			Kind:      mapTreeSitterKind(ts.Kind),
 			File:      path,
 			Line:      ts.Line,
+			EndLine:   ts.EndLine,
 			Signature: ts.Signature,
 			Parent:    ts.Parent,
 		}
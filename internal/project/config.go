@@ -111,7 +111,7 @@ func ApplicabilityMatches(rule ApplicabilityRule, classification ProjectClassifi
 
 // EmbeddingConfig contains configuration for the embedding provider
 type EmbeddingConfig struct {
-	// Provider is the embedding provider: "ollama", "openai", "huggingface"
+	// Provider is the embedding provider: "ollama", "openai", "huggingface", "local"
 	Provider string `yaml:"provider" json:"provider"`
 	// Model is the model name (provider-specific)
 	Model string `yaml:"model,omitempty" json:"model,omitempty"`
@@ -121,6 +121,20 @@ type EmbeddingConfig struct {
 	APIKeyEnv string `yaml:"api_key_env,omitempty" json:"api_key_env,omitempty"`
 	// Dimension is the embedding dimension
 	Dimension int `yaml:"dimension,omitempty" json:"dimension,omitempty"`
+	// BatchSize is the number of texts per embedding request
+	BatchSize int `yaml:"batch_size,omitempty" json:"batch_size,omitempty"`
+	// Headers are extra HTTP headers for OpenAI-compatible endpoints;
+	// values may reference the API key variable and ${ENV_VARS} listed in
+	// $QUOKKA_EMBEDDING_HEADER_ENV
+	Headers map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+	// AuthScheme is "bearer" (default), "header" or "none"
+	AuthScheme string `yaml:"auth_scheme,omitempty" json:"auth_scheme,omitempty"`
+	// AuthHeader is the header carrying the key for the "header" scheme
+	AuthHeader string `yaml:"auth_header,omitempty" json:"auth_header,omitempty"`
+	// MaxRetries bounds retries on 429/5xx (0 = default, -1 = off)
+	MaxRetries int `yaml:"max_retries,omitempty" json:"max_retries,omitempty"`
+	// RequestsPerMinute is a client-side rate limit (0 = unlimited)
+	RequestsPerMinute int `yaml:"requests_per_minute,omitempty" json:"requests_per_minute,omitempty"`
 }
 
 // IndexConfig contains configuration for semantic indexing
@@ -135,6 +149,12 @@ type IndexConfig struct {
 	Embedding EmbeddingConfig `yaml:"embedding" json:"embedding"`
 	// ExcludePatterns are file patterns to exclude from indexing
 	ExcludePatterns []string `yaml:"exclude_patterns,omitempty" json:"exclude_patterns,omitempty"`
+	// Quantization compresses vectors held in memory: "none" (default) or
+	// "int8", which re-ranks the top candidates at full precision
+	Quantization string `yaml:"quantization,omitempty" json:"quantization,omitempty"`
+	// RerankFactor is the number of quantized candidates re-ranked per
+	// result (default: 4)
+	RerankFactor int `yaml:"rerank_factor,omitempty" json:"rerank_factor,omitempty"`
 }
 
 // DefaultIndexConfig returns the default index configuration
@@ -158,6 +178,14 @@ func DefaultIndexConfig() IndexConfig {
 	}
 }
 
+// FindingsConfig selects where findings are stored
+type FindingsConfig struct {
+	// Backend is the finding store backend: "yaml" (default, one file per
+	// finding under findings/raw) or "sqlite" (findings/findings.db).
+	// Switch with `quokka finding migrate` rather than editing by hand.
+	Backend string `yaml:"backend,omitempty" json:"backend,omitempty"`
+}
+
 // ProjectConfig represents the .quokka/project.yaml configuration
 type ProjectConfig struct {
 	Name              string                 `yaml:"name" json:"name"`
@@ -168,6 +196,7 @@ type ProjectConfig struct {
 	Classification    ProjectClassification  `yaml:"classification,omitempty" json:"classification,omitempty"`
 	SecurityScope     SecurityScope          `yaml:"security_scope,omitempty" json:"security_scope,omitempty"`
 	Index             IndexConfig            `yaml:"index,omitempty" json:"index,omitempty"`
+	Findings          FindingsConfig         `yaml:"findings,omitempty" json:"findings,omitempty"`
 	AllowAgentWrites  AllowAgentWrites       `yaml:"allow_agent_writes,omitempty" json:"allow_agent_writes,omitempty"`
 }
 
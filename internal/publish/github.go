@@ -0,0 +1,163 @@
+package publish
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+const defaultGitHubAPI = "https://api.github.com"
+
+// githubPerPage is the page size for list calls (GitHub's maximum).
+const githubPerPage = 100
+
+// githubOutsideDiffRE matches the validation errors GitHub returns (with a
+// 422) for a review comment whose line isn't part of the diff. Other 422s,
+// such as a commit_id that isn't in the pull request, are real errors.
+var githubOutsideDiffRE = regexp.MustCompile(`pull_request_review_thread\.(line|start_line|position|diff_hunk)|"field"\s*:\s*"(line|start_line|position)"`)
+
+// GitHubPublisher publishes to a GitHub pull request: the summary is an issue
+// comment, inline comments are pull request review comments.
+type GitHubPublisher struct {
+	client *http.Client
+	base   string
+	owner  string
+	repo   string
+	number int
+	token  string
+}
+
+func newGitHub(cfg Config) (*GitHubPublisher, error) {
+	owner, repo, ok := strings.Cut(cfg.Repo, "/")
+	if !ok || owner == "" || repo == "" || strings.Contains(repo, "/") {
+		return nil, fmt.Errorf("github repository must be owner/name, got %q", cfg.Repo)
+	}
+	base := cfg.BaseURL
+	if base == "" {
+		base = defaultGitHubAPI
+	}
+	return &GitHubPublisher{
+		client: cfg.HTTPClient,
+		base:   strings.TrimRight(base, "/"),
+		owner:  owner,
+		repo:   repo,
+		number: cfg.Number,
+		token:  cfg.Token,
+	}, nil
+}
+
+// Name returns the provider name
+func (g *GitHubPublisher) Name() string {
+	return "github"
+}
+
+// Publish creates or updates the summary and inline comments. Without a
+// CommitSHA, inline comments are anchored to the pull request's head commit;
+// the local HEAD of a pull_request checkout is a merge commit GitHub rejects.
+func (g *GitHubPublisher) Publish(ctx context.Context, r Review) (*Result, error) {
+	if len(r.Inline) > 0 && r.CommitSHA == "" {
+		sha, err := g.headSHA(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("resolve pull request head commit: %w", err)
+		}
+		r.CommitSHA = sha
+	}
+	return publish(ctx, g.Name(), g, r)
+}
+
+// headSHA returns the pull request's head commit.
+func (g *GitHubPublisher) headSHA(ctx context.Context) (string, error) {
+	var pr struct {
+		Head struct {
+			SHA string `json:"sha"`
+		} `json:"head"`
+	}
+	if err := doJSON(ctx, g.client, g.setAuth, http.MethodGet, g.repoURL("/pulls/%d", g.number), nil, &pr); err != nil {
+		return "", err
+	}
+	if pr.Head.SHA == "" {
+		return "", fmt.Errorf("pull request %d has no head sha", g.number)
+	}
+	return pr.Head.SHA, nil
+}
+
+type githubComment struct {
+	ID      int64  `json:"id"`
+	Body    string `json:"body"`
+	HTMLURL string `json:"html_url"`
+}
+
+func (c githubComment) remote() remoteComment {
+	return remoteComment{ID: c.ID, Body: c.Body, URL: c.HTMLURL}
+}
+
+func (g *GitHubPublisher) setAuth(req *http.Request) {
+	req.Header.Set("Authorization", "Bearer "+g.token)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+}
+
+func (g *GitHubPublisher) repoURL(format string, args ...any) string {
+	return fmt.Sprintf("%s/repos/%s/%s", g.base, g.owner, g.repo) + fmt.Sprintf(format, args...)
+}
+
+// listAll pages through a GitHub list endpoint.
+func (g *GitHubPublisher) listAll(ctx context.Context, url string) ([]remoteComment, error) {
+	var all []remoteComment
+	for page := 1; ; page++ {
+		var batch []githubComment
+		pageURL := fmt.Sprintf("%s?per_page=%d&page=%d", url, githubPerPage, page)
+		if err := doJSON(ctx, g.client, g.setAuth, http.MethodGet, pageURL, nil, &batch); err != nil {
+			return nil, err
+		}
+		for _, c := range batch {
+			all = append(all, c.remote())
+		}
+		if len(batch) < githubPerPage {
+			return all, nil
+		}
+	}
+}
+
+func (g *GitHubPublisher) listSummaryComments(ctx context.Context) ([]remoteComment, error) {
+	return g.listAll(ctx, g.repoURL("/issues/%d/comments", g.number))
+}
+
+func (g *GitHubPublisher) createSummary(ctx context.Context, body string) (remoteComment, error) {
+	var out githubComment
+	err := doJSON(ctx, g.client, g.setAuth, http.MethodPost, g.repoURL("/issues/%d/comments", g.number),
+		map[string]string{"body": body}, &out)
+	return out.remote(), err
+}
+
+func (g *GitHubPublisher) updateSummary(ctx context.Context, c remoteComment, body string) error {
+	return doJSON(ctx, g.client, g.setAuth, http.MethodPatch, g.repoURL("/issues/comments/%d", c.ID),
+		map[string]string{"body": body}, nil)
+}
+
+func (g *GitHubPublisher) listInlineComments(ctx context.Context) ([]remoteComment, error) {
+	return g.listAll(ctx, g.repoURL("/pulls/%d/comments", g.number))
+}
+
+func (g *GitHubPublisher) createInline(ctx context.Context, c InlineComment, commitSHA string) error {
+	req := map[string]any{
+		"body":      c.Body,
+		"commit_id": commitSHA,
+		"path":      c.Path,
+		"line":      c.Line,
+		"side":      "RIGHT",
+	}
+	err := doJSON(ctx, g.client, g.setAuth, http.MethodPost, g.repoURL("/pulls/%d/comments", g.number), req, nil)
+	// GitHub answers 422 when the line is not part of the diff.
+	if rejectsPosition(err, http.StatusUnprocessableEntity, githubOutsideDiffRE) {
+		return errOutsideDiff
+	}
+	return err
+}
+
+func (g *GitHubPublisher) updateInline(ctx context.Context, c remoteComment, body string) error {
+	return doJSON(ctx, g.client, g.setAuth, http.MethodPatch, g.repoURL("/pulls/comments/%d", c.ID),
+		map[string]string{"body": body}, nil)
+}
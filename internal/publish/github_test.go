@@ -0,0 +1,265 @@
+package publish
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeGitHub is a minimal in-memory stand-in for the GitHub REST endpoints
+// the publisher uses. Lines listed in diffLines are the only ones that
+// accept review comments, mirroring GitHub's 422 for lines outside the diff.
+type fakeGitHub struct {
+	mu        sync.Mutex
+	nextID    int64
+	issue     []githubComment
+	review    []githubComment
+	diffLines map[string]bool // "path:line"
+	creates   int
+	updates   int
+	lastSHA   string
+	headSHA   string
+	prFetches int
+}
+
+func newFakeGitHub(t *testing.T, diffLines ...string) (*fakeGitHub, *httptest.Server) {
+	t.Helper()
+	f := &fakeGitHub{nextID: 100, diffLines: map[string]bool{}, headSHA: "abc123"}
+	for _, l := range diffLines {
+		f.diffLines[l] = true
+	}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return f, srv
+}
+
+func (f *fakeGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer tok" {
+		http.Error(w, `{"message":"Bad credentials"}`, http.StatusUnauthorized)
+		return
+	}
+
+	var in map[string]any
+	if r.Body != nil {
+		_ = json.NewDecoder(r.Body).Decode(&in)
+	}
+	body, _ := in["body"].(string)
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/repos/acme/shop/pulls/7":
+		f.prFetches++
+		_ = json.NewEncoder(w).Encode(map[string]any{"head": map[string]string{"sha": f.headSHA}})
+	case r.Method == http.MethodGet && r.URL.Path == "/repos/acme/shop/issues/7/comments":
+		writePage(w, r, f.issue)
+	case r.Method == http.MethodPost && r.URL.Path == "/repos/acme/shop/issues/7/comments":
+		f.nextID++
+		c := githubComment{ID: f.nextID, Body: body, HTMLURL: fmt.Sprintf("https://github.test/acme/shop/pull/7#issuecomment-%d", f.nextID)}
+		f.issue = append(f.issue, c)
+		f.creates++
+		_ = json.NewEncoder(w).Encode(c)
+	case r.Method == http.MethodPatch && strings.HasPrefix(r.URL.Path, "/repos/acme/shop/issues/comments/"):
+		f.patch(w, f.issue, strings.TrimPrefix(r.URL.Path, "/repos/acme/shop/issues/comments/"), body)
+	case r.Method == http.MethodGet && r.URL.Path == "/repos/acme/shop/pulls/7/comments":
+		writePage(w, r, f.review)
+	case r.Method == http.MethodPost && r.URL.Path == "/repos/acme/shop/pulls/7/comments":
+		key := fmt.Sprintf("%v:%v", in["path"], in["line"])
+		if !f.diffLines[key] || in["side"] != "RIGHT" {
+			http.Error(w, `{"message":"Validation Failed","errors":[{"resource":"PullRequestReviewComment","code":"custom","field":"pull_request_review_thread.line","message":"could not be resolved"}]}`, http.StatusUnprocessableEntity)
+			return
+		}
+		if in["commit_id"] != f.headSHA {
+			http.Error(w, `{"message":"Validation Failed","errors":[{"resource":"PullRequestReviewComment","code":"custom","field":"commit_id","message":"commit_id is not part of the pull request"}]}`, http.StatusUnprocessableEntity)
+			return
+		}
+		f.lastSHA, _ = in["commit_id"].(string)
+		f.nextID++
+		c := githubComment{ID: f.nextID, Body: body}
+		f.review = append(f.review, c)
+		f.creates++
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(c)
+	case r.Method == http.MethodPatch && strings.HasPrefix(r.URL.Path, "/repos/acme/shop/pulls/comments/"):
+		f.patch(w, f.review, strings.TrimPrefix(r.URL.Path, "/repos/acme/shop/pulls/comments/"), body)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeGitHub) patch(w http.ResponseWriter, list []githubComment, idStr, body string) {
+	id, _ := strconv.ParseInt(idStr, 10, 64)
+	for i := range list {
+		if list[i].ID == id {
+			list[i].Body = body
+			f.updates++
+			_ = json.NewEncoder(w).Encode(list[i])
+			return
+		}
+	}
+	http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+}
+
+// writePage serves list endpoints honouring per_page/page so pagination is
+// exercised the same way as against the real API.
+func writePage[T any](w http.ResponseWriter, r *http.Request, all []T) {
+	perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if perPage <= 0 {
+		perPage = 30
+	}
+	if page <= 0 {
+		page = 1
+	}
+	start := (page - 1) * perPage
+	if start > len(all) {
+		start = len(all)
+	}
+	end := start + perPage
+	if end > len(all) {
+		end = len(all)
+	}
+	_ = json.NewEncoder(w).Encode(all[start:end])
+}
+
+func sampleReview(summary string) Review {
+	return Review{
+		Summary:   summary,
+		CommitSHA: "abc123",
+		Inline: []InlineComment{
+			{Fingerprint: "fp1", Path: "app/db.py", Line: 12, Body: "SQL injection"},
+			{Fingerprint: "fp2", Path: "app/views.py", Line: 40, Body: "Reflected XSS"},
+			{Fingerprint: "fp3", Path: "app/util.py", Line: 3, Body: "Outside the diff"},
+		},
+	}
+}
+
+func TestGitHubPublishCreatesThenUpdates(t *testing.T) {
+	fake, srv := newFakeGitHub(t, "app/db.py:12", "app/views.py:40")
+	pub, err := NewPublisher(Config{Provider: "github", BaseURL: srv.URL, Repo: "acme/shop", Number: 7, Token: "tok"})
+	if err != nil {
+		t.Fatalf("NewPublisher: %v", err)
+	}
+
+	res, err := pub.Publish(context.Background(), sampleReview("## quokka security review\n\n2 findings"))
+	if err != nil {
+		t.Fatalf("first Publish: %v", err)
+	}
+	if !res.SummaryCreated || res.SummaryUpdated || res.InlineCreated != 2 || len(res.InlineSkipped) != 1 || res.InlineSkipped[0] != "app/util.py:3" {
+		t.Errorf("first publish result = %+v", res)
+	}
+	if !strings.Contains(res.SummaryURL, "issuecomment-") {
+		t.Errorf("SummaryURL = %q", res.SummaryURL)
+	}
+	if fake.lastSHA != "abc123" {
+		t.Errorf("commit_id = %q, want abc123", fake.lastSHA)
+	}
+	if !strings.Contains(fake.issue[0].Body, SummaryMarker) {
+		t.Error("summary comment lacks the hidden marker")
+	}
+	if !strings.Contains(fake.review[0].Body, FingerprintMarker("fp1")) {
+		t.Error("inline comment lacks the fingerprint marker")
+	}
+
+	// Second run: summary text changed, one finding reworded, one identical.
+	r := sampleReview("## quokka security review\n\n1 finding")
+	r.Inline[1].Body = "Reflected XSS (confirmed)"
+	res, err = pub.Publish(context.Background(), r)
+	if err != nil {
+		t.Fatalf("second Publish: %v", err)
+	}
+	if !res.SummaryUpdated || res.InlineCreated != 0 || res.InlineUpdated != 1 || res.InlineKept != 1 {
+		t.Errorf("second publish result = %+v", res)
+	}
+	if len(fake.issue) != 1 || len(fake.review) != 2 {
+		t.Errorf("duplicates created: %d summary, %d inline comments", len(fake.issue), len(fake.review))
+	}
+	if !strings.Contains(fake.issue[0].Body, "1 finding") {
+		t.Errorf("summary not updated: %q", fake.issue[0].Body)
+	}
+
+	// Third run: nothing changed, so nothing is sent.
+	updates := fake.updates
+	res, err = pub.Publish(context.Background(), r)
+	if err != nil {
+		t.Fatalf("third Publish: %v", err)
+	}
+	if res.SummaryCreated || res.SummaryUpdated || res.InlineKept != 2 || fake.updates != updates {
+		t.Errorf("third publish result = %+v, %d updates sent", res, fake.updates-updates)
+	}
+}
+
+func TestGitHubPublishUsesPullRequestHead(t *testing.T) {
+	fake, srv := newFakeGitHub(t, "app/db.py:12", "app/views.py:40")
+	fake.headSHA = "feedface"
+	pub, err := NewPublisher(Config{Provider: "github", BaseURL: srv.URL, Repo: "acme/shop", Number: 7, Token: "tok"})
+	if err != nil {
+		t.Fatalf("NewPublisher: %v", err)
+	}
+
+	// Without a commit the PR head is used, not whatever HEAD is locally.
+	r := sampleReview("x")
+	r.CommitSHA = ""
+	res, err := pub.Publish(context.Background(), r)
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if fake.prFetches != 1 || fake.lastSHA != "feedface" || res.InlineCreated != 2 {
+		t.Errorf("fetches=%d commit_id=%q result=%+v", fake.prFetches, fake.lastSHA, res)
+	}
+
+	// A commit outside the PR (e.g. a synthetic merge commit) is an error,
+	// not a silently skipped comment.
+	fake.review = nil
+	r = sampleReview("x")
+	r.CommitSHA = "merge0"
+	if _, err := pub.Publish(context.Background(), r); err == nil || !strings.Contains(err.Error(), "commit_id") {
+		t.Errorf("want commit_id error, got %v", err)
+	}
+}
+
+func TestGitHubPublishPaginatesExistingComments(t *testing.T) {
+	fake, srv := newFakeGitHub(t)
+	// Push the marker comment onto the second page.
+	for i := 0; i < githubPerPage; i++ {
+		fake.issue = append(fake.issue, githubComment{ID: int64(i + 1), Body: "lgtm"})
+	}
+	fake.issue = append(fake.issue, githubComment{ID: 9999, Body: "old\n\n" + SummaryMarker + "\n"})
+
+	pub, err := NewPublisher(Config{Provider: "github", BaseURL: srv.URL, Repo: "acme/shop", Number: 7, Token: "tok"})
+	if err != nil {
+		t.Fatalf("NewPublisher: %v", err)
+	}
+	res, err := pub.Publish(context.Background(), Review{Summary: "new"})
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if !res.SummaryUpdated || fake.creates != 0 || fake.updates != 1 {
+		t.Errorf("result = %+v, creates=%d updates=%d; want update of comment on page 2", res, fake.creates, fake.updates)
+	}
+}
+
+func TestGitHubPublishErrors(t *testing.T) {
+	_, srv := newFakeGitHub(t)
+	pub, err := NewPublisher(Config{Provider: "github", BaseURL: srv.URL, Repo: "acme/shop", Number: 7, Token: "wrong"})
+	if err != nil {
+		t.Fatalf("NewPublisher: %v", err)
+	}
+	if _, err := pub.Publish(context.Background(), Review{Summary: "x"}); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("want 401 error, got %v", err)
+	}
+
+	if _, err := NewPublisher(Config{Provider: "github", Repo: "noslash", Number: 1, Token: "t"}); err == nil {
+		t.Error("want error for repo without owner")
+	}
+	if _, err := NewPublisher(Config{Provider: "bitbucket", Repo: "a/b", Number: 1, Token: "t"}); err == nil {
+		t.Error("want error for unknown provider")
+	}
+}
@@ -0,0 +1,176 @@
+package publish
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const defaultGitLabAPI = "https://gitlab.com/api/v4"
+
+// gitlabPerPage is the page size for list calls (GitLab's maximum).
+const gitlabPerPage = 100
+
+// gitlabOutsideDiffRE matches the 400 GitLab returns for a diff note whose
+// position doesn't map onto the diff (an invalid or blank line_code).
+var gitlabOutsideDiffRE = regexp.MustCompile(`line_code|(?i)\bposition\b`)
+
+// GitLabPublisher publishes to a GitLab merge request: the summary is an MR
+// note, inline comments are diff discussions.
+type GitLabPublisher struct {
+	client  *http.Client
+	base    string
+	project string // URL-escaped path or numeric ID
+	iid     int
+	token   string
+
+	diffRefs *gitlabDiffRefs // fetched lazily for the first inline comment
+}
+
+type gitlabDiffRefs struct {
+	BaseSHA  string `json:"base_sha"`
+	HeadSHA  string `json:"head_sha"`
+	StartSHA string `json:"start_sha"`
+}
+
+type gitlabNote struct {
+	ID     int64  `json:"id"`
+	Body   string `json:"body"`
+	System bool   `json:"system"`
+}
+
+type gitlabDiscussion struct {
+	ID    string       `json:"id"`
+	Notes []gitlabNote `json:"notes"`
+}
+
+func newGitLab(cfg Config) *GitLabPublisher {
+	base := cfg.BaseURL
+	if base == "" {
+		base = defaultGitLabAPI
+	}
+	return &GitLabPublisher{
+		client:  cfg.HTTPClient,
+		base:    strings.TrimRight(base, "/"),
+		project: url.PathEscape(cfg.Repo),
+		iid:     cfg.Number,
+		token:   cfg.Token,
+	}
+}
+
+// Name returns the provider name
+func (g *GitLabPublisher) Name() string {
+	return "gitlab"
+}
+
+// Publish creates or updates the summary and inline comments
+func (g *GitLabPublisher) Publish(ctx context.Context, r Review) (*Result, error) {
+	return publish(ctx, g.Name(), g, r)
+}
+
+func (g *GitLabPublisher) setAuth(req *http.Request) {
+	req.Header.Set("PRIVATE-TOKEN", g.token)
+}
+
+func (g *GitLabPublisher) mrURL(format string, args ...any) string {
+	return fmt.Sprintf("%s/projects/%s/merge_requests/%d", g.base, g.project, g.iid) + fmt.Sprintf(format, args...)
+}
+
+// noteURL is the web link to a note; the API does not return one.
+func (g *GitLabPublisher) noteURL(id int64) string {
+	return fmt.Sprintf("%s#note_%d", g.mrURL(""), id)
+}
+
+func (g *GitLabPublisher) listSummaryComments(ctx context.Context) ([]remoteComment, error) {
+	var all []remoteComment
+	for page := 1; ; page++ {
+		var batch []gitlabNote
+		pageURL := g.mrURL("/notes?per_page=%d&page=%d", gitlabPerPage, page)
+		if err := doJSON(ctx, g.client, g.setAuth, http.MethodGet, pageURL, nil, &batch); err != nil {
+			return nil, err
+		}
+		for _, n := range batch {
+			if !n.System {
+				all = append(all, remoteComment{ID: n.ID, Body: n.Body, URL: g.noteURL(n.ID)})
+			}
+		}
+		if len(batch) < gitlabPerPage {
+			return all, nil
+		}
+	}
+}
+
+func (g *GitLabPublisher) createSummary(ctx context.Context, body string) (remoteComment, error) {
+	var out gitlabNote
+	err := doJSON(ctx, g.client, g.setAuth, http.MethodPost, g.mrURL("/notes"), map[string]string{"body": body}, &out)
+	return remoteComment{ID: out.ID, Body: out.Body, URL: g.noteURL(out.ID)}, err
+}
+
+func (g *GitLabPublisher) updateSummary(ctx context.Context, c remoteComment, body string) error {
+	return doJSON(ctx, g.client, g.setAuth, http.MethodPut, g.mrURL("/notes/%d", c.ID), map[string]string{"body": body}, nil)
+}
+
+// listInlineComments returns the first note of every discussion; that is
+// the note quokka writes when it opens a thread.
+func (g *GitLabPublisher) listInlineComments(ctx context.Context) ([]remoteComment, error) {
+	var all []remoteComment
+	for page := 1; ; page++ {
+		var batch []gitlabDiscussion
+		pageURL := g.mrURL("/discussions?per_page=%d&page=%d", gitlabPerPage, page)
+		if err := doJSON(ctx, g.client, g.setAuth, http.MethodGet, pageURL, nil, &batch); err != nil {
+			return nil, err
+		}
+		for _, d := range batch {
+			if len(d.Notes) == 0 {
+				continue
+			}
+			n := d.Notes[0]
+			all = append(all, remoteComment{ID: n.ID, DiscussionID: d.ID, Body: n.Body, URL: g.noteURL(n.ID)})
+		}
+		if len(batch) < gitlabPerPage {
+			return all, nil
+		}
+	}
+}
+
+func (g *GitLabPublisher) createInline(ctx context.Context, c InlineComment, _ string) error {
+	if g.diffRefs == nil {
+		var mr struct {
+			DiffRefs *gitlabDiffRefs `json:"diff_refs"`
+		}
+		if err := doJSON(ctx, g.client, g.setAuth, http.MethodGet, g.mrURL(""), nil, &mr); err != nil {
+			return fmt.Errorf("fetch merge request: %w", err)
+		}
+		if mr.DiffRefs == nil {
+			return fmt.Errorf("merge request has no diff_refs yet")
+		}
+		g.diffRefs = mr.DiffRefs
+	}
+
+	req := map[string]any{
+		"body": c.Body,
+		"position": map[string]any{
+			"position_type": "text",
+			"base_sha":      g.diffRefs.BaseSHA,
+			"start_sha":     g.diffRefs.StartSHA,
+			"head_sha":      g.diffRefs.HeadSHA,
+			"old_path":      c.Path,
+			"new_path":      c.Path,
+			"new_line":      c.Line,
+		},
+	}
+	err := doJSON(ctx, g.client, g.setAuth, http.MethodPost, g.mrURL("/discussions"), req, nil)
+	// GitLab answers 400 when the position does not map onto the diff.
+	if rejectsPosition(err, http.StatusBadRequest, gitlabOutsideDiffRE) {
+		return errOutsideDiff
+	}
+	return err
+}
+
+func (g *GitLabPublisher) updateInline(ctx context.Context, c remoteComment, body string) error {
+	return doJSON(ctx, g.client, g.setAuth, http.MethodPut,
+		g.mrURL("/discussions/%s/notes/%d", c.DiscussionID, c.ID), map[string]string{"body": body}, nil)
+}
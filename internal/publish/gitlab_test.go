@@ -0,0 +1,159 @@
+package publish
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeGitLab is a minimal in-memory stand-in for the GitLab v4 merge request
+// notes and discussions endpoints.
+type fakeGitLab struct {
+	mu          sync.Mutex
+	nextID      int64
+	notes       []gitlabNote
+	discussions []gitlabDiscussion
+	diffLines   map[string]bool
+	lastPos     map[string]any
+}
+
+const gitlabMRPath = "/api/v4/projects/acme%2Fshop/merge_requests/7"
+
+func newFakeGitLab(t *testing.T, diffLines ...string) (*fakeGitLab, *httptest.Server) {
+	t.Helper()
+	f := &fakeGitLab{nextID: 500, diffLines: map[string]bool{}}
+	for _, l := range diffLines {
+		f.diffLines[l] = true
+	}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return f, srv
+}
+
+func (f *fakeGitLab) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Header.Get("PRIVATE-TOKEN") != "tok" {
+		http.Error(w, `{"message":"401 Unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
+	path := r.URL.EscapedPath()
+	if !strings.HasPrefix(path, gitlabMRPath) {
+		http.NotFound(w, r)
+		return
+	}
+	path = strings.TrimPrefix(path, gitlabMRPath)
+
+	var in map[string]any
+	_ = json.NewDecoder(r.Body).Decode(&in)
+	body, _ := in["body"].(string)
+
+	switch {
+	case r.Method == http.MethodGet && path == "":
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"iid":       7,
+			"diff_refs": map[string]string{"base_sha": "b1", "head_sha": "h1", "start_sha": "s1"},
+		})
+	case r.Method == http.MethodGet && path == "/notes":
+		writePage(w, r, f.notes)
+	case r.Method == http.MethodPost && path == "/notes":
+		f.nextID++
+		n := gitlabNote{ID: f.nextID, Body: body}
+		f.notes = append(f.notes, n)
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(n)
+	case r.Method == http.MethodPut && strings.HasPrefix(path, "/notes/"):
+		id, _ := strconv.ParseInt(strings.TrimPrefix(path, "/notes/"), 10, 64)
+		for i := range f.notes {
+			if f.notes[i].ID == id {
+				f.notes[i].Body = body
+				_ = json.NewEncoder(w).Encode(f.notes[i])
+				return
+			}
+		}
+		http.NotFound(w, r)
+	case r.Method == http.MethodGet && path == "/discussions":
+		writePage(w, r, f.discussions)
+	case r.Method == http.MethodPost && path == "/discussions":
+		pos, _ := in["position"].(map[string]any)
+		key := fmt.Sprintf("%v:%v", pos["new_path"], pos["new_line"])
+		if !f.diffLines[key] {
+			http.Error(w, `{"message":"400 Bad request - Note {:line_code=>[\"can't be blank\"]}"}`, http.StatusBadRequest)
+			return
+		}
+		f.lastPos = pos
+		f.nextID++
+		d := gitlabDiscussion{ID: fmt.Sprintf("d%d", f.nextID), Notes: []gitlabNote{{ID: f.nextID, Body: body}}}
+		f.discussions = append(f.discussions, d)
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(d)
+	case r.Method == http.MethodPut && strings.HasPrefix(path, "/discussions/"):
+		parts := strings.Split(strings.TrimPrefix(path, "/discussions/"), "/")
+		if len(parts) != 3 || parts[1] != "notes" {
+			http.NotFound(w, r)
+			return
+		}
+		id, _ := strconv.ParseInt(parts[2], 10, 64)
+		for i := range f.discussions {
+			d := &f.discussions[i]
+			if d.ID == parts[0] && d.Notes[0].ID == id {
+				d.Notes[0].Body = body
+				_ = json.NewEncoder(w).Encode(d.Notes[0])
+				return
+			}
+		}
+		http.NotFound(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestGitLabPublishCreatesThenUpdates(t *testing.T) {
+	fake, srv := newFakeGitLab(t, "app/db.py:12", "app/views.py:40")
+	fake.notes = append(fake.notes, gitlabNote{ID: 1, Body: "approved this merge request", System: true})
+
+	pub, err := NewPublisher(Config{Provider: "gitlab", BaseURL: srv.URL + "/api/v4", Repo: "acme/shop", Number: 7, Token: "tok"})
+	if err != nil {
+		t.Fatalf("NewPublisher: %v", err)
+	}
+
+	res, err := pub.Publish(context.Background(), sampleReview("summary v1"))
+	if err != nil {
+		t.Fatalf("first Publish: %v", err)
+	}
+	if res.InlineCreated != 2 || len(res.InlineSkipped) != 1 {
+		t.Errorf("first publish result = %+v", res)
+	}
+	if fake.lastPos["head_sha"] != "h1" || fake.lastPos["base_sha"] != "b1" || fake.lastPos["position_type"] != "text" {
+		t.Errorf("position = %v, want diff refs from the merge request", fake.lastPos)
+	}
+	if !strings.Contains(res.SummaryURL, "#note_") {
+		t.Errorf("SummaryURL = %q", res.SummaryURL)
+	}
+
+	r := sampleReview("summary v2")
+	r.Inline[0].Body = "SQL injection (confirmed)"
+	res, err = pub.Publish(context.Background(), r)
+	if err != nil {
+		t.Fatalf("second Publish: %v", err)
+	}
+	if !res.SummaryUpdated || res.InlineCreated != 0 || res.InlineUpdated != 1 || res.InlineKept != 1 {
+		t.Errorf("second publish result = %+v", res)
+	}
+	if len(fake.notes) != 2 || len(fake.discussions) != 2 {
+		t.Errorf("duplicates created: %d notes, %d discussions", len(fake.notes), len(fake.discussions))
+	}
+	if !strings.Contains(fake.notes[1].Body, "summary v2") {
+		t.Errorf("summary not updated: %q", fake.notes[1].Body)
+	}
+	if !strings.Contains(fake.discussions[0].Notes[0].Body, "(confirmed)") {
+		t.Errorf("inline comment not updated: %q", fake.discussions[0].Notes[0].Body)
+	}
+}
@@ -0,0 +1,277 @@
+// Package publish posts review results to a pull/merge request: one summary
+// comment plus inline comments anchored to the finding locations. Comments
+// carry hidden HTML markers so a re-run edits its earlier comments instead
+// of piling up duplicates.
+package publish
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// SummaryMarker identifies the summary comment. It is an HTML comment, so it
+// renders invisibly on both GitHub and GitLab.
+const SummaryMarker = "<!-- quokka:summary -->"
+
+// fingerprintMarkerRE extracts the finding fingerprint from an inline comment.
+var fingerprintMarkerRE = regexp.MustCompile(`<!-- quokka:fingerprint:([0-9a-zA-Z]+) -->`)
+
+// FingerprintMarker returns the hidden marker embedded in an inline comment
+// for the finding with the given fingerprint.
+func FingerprintMarker(fingerprint string) string {
+	return "<!-- quokka:fingerprint:" + fingerprint + " -->"
+}
+
+// errOutsideDiff is returned by a backend when the host rejects an inline
+// comment because its line is not part of the diff.
+var errOutsideDiff = errors.New("line is not part of the diff")
+
+// Review is what gets published.
+type Review struct {
+	// Summary is the markdown body of the summary comment, without marker.
+	Summary string
+	// Inline comments, one per finding.
+	Inline []InlineComment
+	// CommitSHA is the head commit the inline comments refer to. GitHub uses
+	// the pull request's head commit when it is empty; GitLab always takes
+	// its diff refs from the merge request.
+	CommitSHA string
+}
+
+// InlineComment is a review comment anchored to a line in the diff.
+type InlineComment struct {
+	Fingerprint string
+	Path        string
+	Line        int
+	Body        string
+}
+
+// Result summarizes what a Publish call changed.
+type Result struct {
+	Provider       string   `json:"provider"`
+	SummaryURL     string   `json:"summary_url,omitempty"`
+	SummaryCreated bool     `json:"summary_created"`
+	SummaryUpdated bool     `json:"summary_updated"` // false when the existing summary was already current
+	InlineCreated  int      `json:"inline_created"`
+	InlineUpdated  int      `json:"inline_updated"`
+	InlineKept     int      `json:"inline_unchanged"`
+	InlineSkipped  []string `json:"inline_skipped,omitempty"` // path:line the host refused (outside the diff)
+}
+
+// Publisher posts a Review to a code host.
+type Publisher interface {
+	// Name returns the provider name
+	Name() string
+	// Publish creates or updates the summary and inline comments
+	Publish(ctx context.Context, r Review) (*Result, error)
+}
+
+// Config contains configuration for publishers
+type Config struct {
+	// Provider is "github" or "gitlab"
+	Provider string
+	// BaseURL is the REST API root, e.g. https://api.github.com or
+	// https://gitlab.example.com/api/v4. Empty uses the public default.
+	BaseURL string
+	// Repo is "owner/name" on GitHub, the project path or numeric ID on GitLab
+	Repo string
+	// Number is the pull request number (GitHub) or merge request IID (GitLab)
+	Number int
+	// Token is the API token
+	Token string
+	// HTTPClient overrides the default client (tests)
+	HTTPClient *http.Client
+}
+
+// NewPublisher creates a publisher for the configured provider
+func NewPublisher(cfg Config) (Publisher, error) {
+	if cfg.Repo == "" {
+		return nil, fmt.Errorf("repository is required")
+	}
+	if cfg.Number <= 0 {
+		return nil, fmt.Errorf("pull/merge request number is required")
+	}
+	if cfg.Token == "" {
+		return nil, fmt.Errorf("API token is required")
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	switch cfg.Provider {
+	case "github":
+		return newGitHub(cfg)
+	case "gitlab":
+		return newGitLab(cfg), nil
+	default:
+		return nil, fmt.Errorf("unknown publisher: %s (valid: github, gitlab)", cfg.Provider)
+	}
+}
+
+// remoteComment is a comment as stored on the host.
+type remoteComment struct {
+	ID           int64
+	DiscussionID string // GitLab only
+	Body         string
+	URL          string
+}
+
+// backend is the host-specific half of a publisher. publish drives it so
+// that marker matching and update semantics are identical across hosts.
+type backend interface {
+	listSummaryComments(ctx context.Context) ([]remoteComment, error)
+	createSummary(ctx context.Context, body string) (remoteComment, error)
+	updateSummary(ctx context.Context, c remoteComment, body string) error
+	listInlineComments(ctx context.Context) ([]remoteComment, error)
+	createInline(ctx context.Context, c InlineComment, commitSHA string) error
+	updateInline(ctx context.Context, c remoteComment, body string) error
+}
+
+func publish(ctx context.Context, name string, b backend, r Review) (*Result, error) {
+	res := &Result{Provider: name}
+
+	summaryBody := strings.TrimRight(r.Summary, "\n") + "\n\n" + SummaryMarker + "\n"
+	existing, err := b.listSummaryComments(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list comments: %w", err)
+	}
+	var summary *remoteComment
+	for i := range existing {
+		if strings.Contains(existing[i].Body, SummaryMarker) {
+			summary = &existing[i]
+			break
+		}
+	}
+	if summary != nil {
+		if summary.Body != summaryBody {
+			if err := b.updateSummary(ctx, *summary, summaryBody); err != nil {
+				return nil, fmt.Errorf("update summary: %w", err)
+			}
+			res.SummaryUpdated = true
+		}
+		res.SummaryURL = summary.URL
+	} else {
+		created, err := b.createSummary(ctx, summaryBody)
+		if err != nil {
+			return nil, fmt.Errorf("create summary: %w", err)
+		}
+		res.SummaryCreated = true
+		res.SummaryURL = created.URL
+	}
+
+	if len(r.Inline) == 0 {
+		return res, nil
+	}
+
+	threads, err := b.listInlineComments(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list review comments: %w", err)
+	}
+	byFingerprint := make(map[string]remoteComment, len(threads))
+	for _, c := range threads {
+		if m := fingerprintMarkerRE.FindStringSubmatch(c.Body); m != nil {
+			if _, dup := byFingerprint[m[1]]; !dup {
+				byFingerprint[m[1]] = c
+			}
+		}
+	}
+
+	for _, ic := range r.Inline {
+		body := strings.TrimRight(ic.Body, "\n") + "\n\n" + FingerprintMarker(ic.Fingerprint) + "\n"
+		if prev, ok := byFingerprint[ic.Fingerprint]; ok {
+			if prev.Body == body {
+				res.InlineKept++
+				continue
+			}
+			if err := b.updateInline(ctx, prev, body); err != nil {
+				return nil, fmt.Errorf("update comment on %s:%d: %w", ic.Path, ic.Line, err)
+			}
+			res.InlineUpdated++
+			continue
+		}
+		ic.Body = body
+		err := b.createInline(ctx, ic, r.CommitSHA)
+		if errors.Is(err, errOutsideDiff) {
+			res.InlineSkipped = append(res.InlineSkipped, fmt.Sprintf("%s:%d", ic.Path, ic.Line))
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("comment on %s:%d: %w", ic.Path, ic.Line, err)
+		}
+		res.InlineCreated++
+	}
+	return res, nil
+}
+
+// apiError is a non-2xx response from the host.
+type apiError struct {
+	Method string
+	URL    string
+	Status int
+	Body   string
+}
+
+func (e *apiError) Error() string {
+	body := strings.TrimSpace(e.Body)
+	if len(body) > 300 {
+		body = body[:300] + "..."
+	}
+	return fmt.Sprintf("%s %s: status %d: %s", e.Method, e.URL, e.Status, body)
+}
+
+// doJSON sends in (if non-nil) as a JSON body and decodes a 2xx response
+// into out (if non-nil). setAuth adds the host-specific credentials.
+func doJSON(ctx context.Context, client *http.Client, setAuth func(*http.Request), method, url string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	setAuth(req)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", method, url, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &apiError{Method: method, URL: url, Status: resp.StatusCode, Body: string(data)}
+	}
+	if out != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("failed to decode response from %s: %w", url, err)
+		}
+	}
+	return nil
+}
+
+// rejectsPosition reports whether err is a response with the given status
+// whose body matches re, i.e. the host refused the comment's line or diff
+// position rather than the request as a whole (bad commit, permissions).
+func rejectsPosition(err error, status int, re *regexp.Regexp) bool {
+	var ae *apiError
+	return errors.As(err, &ae) && ae.Status == status && re.MatchString(ae.Body)
+}
@@ -0,0 +1,271 @@
+package rule
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// activityFile is the append-only time series of rule firings and triage
+// outcomes, one JSON event per line, kept next to the rules it describes.
+// The .jsonl extension keeps it out of `opengrep --config .quokka/rules`.
+const activityFile = "activity.jsonl"
+
+// EventKind is what happened to a rule at a point in time.
+type EventKind string
+
+const (
+	// EventTrigger — the rule produced a result in a quokka sast run.
+	EventTrigger EventKind = "trigger"
+
+	// EventFalsePositive — a finding the rule produced was triaged as a
+	// false positive.
+	EventFalsePositive EventKind = "false_positive"
+
+	// EventConfirmed — a finding the rule produced was confirmed.
+	EventConfirmed EventKind = "confirmed"
+
+	// EventReopened — a finding previously triaged false_positive or
+	// confirmed went back to open, withdrawing that verdict.
+	EventReopened EventKind = "reopened"
+)
+
+// Event is one entry in the rule activity time series.
+type Event struct {
+	Time      time.Time `json:"time"`
+	Slug      string    `json:"slug"`
+	Kind      EventKind `json:"kind"`
+	RuleID    string    `json:"rule_id,omitempty"`
+	FindingID string    `json:"finding_id,omitempty"`
+}
+
+// Activity summarizes a rule's time series as of a point in time. Triage
+// counts reflect each finding's latest verdict, so a finding flipped from
+// false_positive back to open stops counting.
+type Activity struct {
+	Slug           string     `json:"slug"`
+	Triggers       int        `json:"triggers"`
+	FalsePositives int        `json:"false_positives"`
+	Confirmed      int        `json:"confirmed"`
+	FirstTriggerAt *time.Time `json:"first_trigger_at,omitempty"`
+	LastTriggerAt  *time.Time `json:"last_trigger_at,omitempty"`
+	// FPRatio is FalsePositives / (FalsePositives + Confirmed); 0 when
+	// nothing has been triaged.
+	FPRatio float64 `json:"fp_ratio"`
+}
+
+// Triaged is the number of findings with a false_positive or confirmed verdict.
+func (a Activity) Triaged() int { return a.FalsePositives + a.Confirmed }
+
+// RetirePolicy holds the deterministic thresholds behind retirement
+// signals. The judge still decides; the policy only reports which
+// thresholds a rule has crossed.
+type RetirePolicy struct {
+	// StaleDays flags rules with no trigger in this many days (measured
+	// from creation for rules that never fired). 0 disables the check.
+	StaleDays int
+	// MaxFPRatio flags rules whose FP ratio is above this value.
+	MaxFPRatio float64
+	// MinTriaged is how many triaged findings the FP check needs before
+	// it applies, so one early false positive doesn't retire a rule.
+	MinTriaged int
+}
+
+// DefaultRetirePolicy matches the rule-judge-agent's guidance: no fires in
+// 90 days, or more than 80% false positives over at least 5 triaged findings.
+func DefaultRetirePolicy() RetirePolicy {
+	return RetirePolicy{StaleDays: 90, MaxFPRatio: 0.8, MinTriaged: 5}
+}
+
+// Signals returns the retirement thresholds the rule has crossed, as short
+// human-readable reasons. Empty means none.
+func (p RetirePolicy) Signals(m Meta, a Activity, now time.Time) []string {
+	var out []string
+	if p.StaleDays > 0 {
+		since := m.CreatedAt
+		if a.LastTriggerAt != nil {
+			since = *a.LastTriggerAt
+		}
+		if !since.IsZero() {
+			if days := int(now.Sub(since).Hours() / 24); days >= p.StaleDays {
+				if a.LastTriggerAt == nil {
+					out = append(out, fmt.Sprintf("never fired in %d days since creation", days))
+				} else {
+					out = append(out, fmt.Sprintf("no fires in %d days", days))
+				}
+			}
+		}
+	}
+	if p.MaxFPRatio > 0 && a.Triaged() >= max(p.MinTriaged, 1) && a.FPRatio > p.MaxFPRatio {
+		out = append(out, fmt.Sprintf("%.0f%% false positives (%d of %d triaged)", a.FPRatio*100, a.FalsePositives, a.Triaged()))
+	}
+	return out
+}
+
+// DaysSinceTrigger returns whole days since the rule last fired, or -1 if
+// it never has.
+func (a Activity) DaysSinceTrigger(now time.Time) int {
+	if a.LastTriggerAt == nil {
+		return -1
+	}
+	return int(now.Sub(*a.LastTriggerAt).Hours() / 24)
+}
+
+// SlugForRuleID maps an opengrep rule id to the slug of the project-local
+// rule file defining it. Opengrep prefixes ids with the rule file's path
+// (e.g. "tmp.rdbg..quokka.quokka-admin-default-creds"), so the dotted
+// suffix is accepted too. Returns "" for rules that aren't project-local.
+func SlugForRuleID(idToSlug map[string]string, id string) string {
+	if slug, ok := idToSlug[id]; ok {
+		return slug
+	}
+	if i := strings.LastIndex(id, "."); i >= 0 {
+		return idToSlug[id[i+1:]]
+	}
+	return ""
+}
+
+// Record appends events to the activity time series and refreshes the
+// trigger and false-positive counters on each affected rule's metadata.
+// Events for rules without metadata are kept in the series but update no
+// counters.
+func (s *Store) Record(events ...Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return fmt.Errorf("mkdir rules dir: %w", err)
+	}
+	f, err := os.OpenFile(s.activityPath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("open rule activity: %w", err)
+	}
+	// One write per batch keeps concurrent appenders from interleaving
+	// inside a line.
+	var buf strings.Builder
+	slugs := map[string]bool{}
+	for _, e := range events {
+		if e.Time.IsZero() {
+			e.Time = time.Now().UTC()
+		}
+		line, err := json.Marshal(e)
+		if err != nil {
+			_ = f.Close()
+			return fmt.Errorf("marshal rule event: %w", err)
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+		slugs[e.Slug] = true
+	}
+	if _, err := f.WriteString(buf.String()); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("write rule activity: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	all, err := s.Events("")
+	if err != nil {
+		return err
+	}
+	for slug := range slugs {
+		m, err := s.ReadMeta(slug)
+		if err != nil {
+			continue
+		}
+		a := Summarize(slug, all)
+		m.TriggerCount = a.Triggers
+		m.FPCount = a.FalsePositives
+		m.ConfirmedCount = a.Confirmed
+		if a.LastTriggerAt != nil {
+			m.LastTriggerAt = *a.LastTriggerAt
+		}
+		if err := s.writeMeta(slug, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Events returns the activity time series in chronological order, limited
+// to one rule when slug is non-empty. A missing series is empty, not an
+// error; malformed lines are skipped.
+func (s *Store) Events(slug string) ([]Event, error) {
+	f, err := os.Open(s.activityPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("open rule activity: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var out []Event
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		if slug == "" || e.Slug == slug {
+			out = append(out, e)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read rule activity: %w", err)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Time.Before(out[j].Time) })
+	return out, nil
+}
+
+// Summarize folds the events of one rule into an Activity. events may hold
+// other rules' events; they are ignored.
+func Summarize(slug string, events []Event) Activity {
+	a := Activity{Slug: slug}
+	latest := map[string]EventKind{}
+	var order []string
+	for _, e := range events {
+		if e.Slug != slug {
+			continue
+		}
+		switch e.Kind {
+		case EventTrigger:
+			a.Triggers++
+			t := e.Time
+			if a.FirstTriggerAt == nil || t.Before(*a.FirstTriggerAt) {
+				a.FirstTriggerAt = &t
+			}
+			if a.LastTriggerAt == nil || t.After(*a.LastTriggerAt) {
+				a.LastTriggerAt = &t
+			}
+		case EventFalsePositive, EventConfirmed, EventReopened:
+			if e.FindingID == "" {
+				continue
+			}
+			if _, seen := latest[e.FindingID]; !seen {
+				order = append(order, e.FindingID)
+			}
+			latest[e.FindingID] = e.Kind
+		}
+	}
+	for _, id := range order {
+		switch latest[id] {
+		case EventFalsePositive:
+			a.FalsePositives++
+		case EventConfirmed:
+			a.Confirmed++
+		}
+	}
+	if n := a.Triaged(); n > 0 {
+		a.FPRatio = float64(a.FalsePositives) / float64(n)
+	}
+	return a
+}
+
+func (s *Store) activityPath() string { return filepath.Join(s.dir, activityFile) }
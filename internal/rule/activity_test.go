@@ -0,0 +1,117 @@
+package rule
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStore_RecordUpdatesMetaCounters(t *testing.T) {
+	s, cleanup := newTestStore(t)
+	defer cleanup()
+
+	if err := s.Add("hand-built-sql", []byte(validRule), validMeta()); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	day := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	err := s.Record(
+		Event{Time: day, Slug: "hand-built-sql", Kind: EventTrigger, FindingID: "F1"},
+		Event{Time: day.Add(24 * time.Hour), Slug: "hand-built-sql", Kind: EventTrigger, FindingID: "F2"},
+		Event{Time: day.Add(25 * time.Hour), Slug: "not-a-rule", Kind: EventTrigger},
+	)
+	if err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	if err := s.Record(Event{Time: day.Add(48 * time.Hour), Slug: "hand-built-sql", Kind: EventFalsePositive, FindingID: "F1"}); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	m, err := s.ReadMeta("hand-built-sql")
+	if err != nil {
+		t.Fatalf("ReadMeta: %v", err)
+	}
+	if m.TriggerCount != 2 || m.FPCount != 1 || !m.LastTriggerAt.Equal(day.Add(24*time.Hour)) {
+		t.Errorf("meta counters = triggers %d, fp %d, last %v", m.TriggerCount, m.FPCount, m.LastTriggerAt)
+	}
+
+	events, err := s.Events("hand-built-sql")
+	if err != nil || len(events) != 3 {
+		t.Fatalf("Events = %d, %v; want 3", len(events), err)
+	}
+	all, _ := s.Events("")
+	if len(all) != 4 {
+		t.Errorf("all events = %d, want 4 (unknown rules stay in the series)", len(all))
+	}
+}
+
+func TestSummarize_LatestVerdictPerFinding(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	events := []Event{
+		{Time: t0, Slug: "r", Kind: EventTrigger, FindingID: "F1"},
+		{Time: t0.Add(time.Hour), Slug: "r", Kind: EventTrigger, FindingID: "F2"},
+		{Time: t0.Add(2 * time.Hour), Slug: "r", Kind: EventFalsePositive, FindingID: "F1"},
+		{Time: t0.Add(3 * time.Hour), Slug: "r", Kind: EventFalsePositive, FindingID: "F2"},
+		{Time: t0.Add(4 * time.Hour), Slug: "r", Kind: EventReopened, FindingID: "F2"},
+		{Time: t0.Add(5 * time.Hour), Slug: "r", Kind: EventConfirmed, FindingID: "F3"},
+		{Time: t0.Add(6 * time.Hour), Slug: "other", Kind: EventTrigger},
+	}
+	a := Summarize("r", events)
+	if a.Triggers != 2 || a.FalsePositives != 1 || a.Confirmed != 1 || a.FPRatio != 0.5 {
+		t.Errorf("activity = %+v", a)
+	}
+	if !a.FirstTriggerAt.Equal(t0) || !a.LastTriggerAt.Equal(t0.Add(time.Hour)) {
+		t.Errorf("trigger window = %v .. %v", a.FirstTriggerAt, a.LastTriggerAt)
+	}
+	if got := a.DaysSinceTrigger(t0.Add(49 * time.Hour)); got != 2 {
+		t.Errorf("DaysSinceTrigger = %d, want 2", got)
+	}
+	if got := Summarize("none", events).DaysSinceTrigger(t0); got != -1 {
+		t.Errorf("DaysSinceTrigger for a silent rule = %d, want -1", got)
+	}
+}
+
+func TestRetirePolicy_Signals(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	policy := DefaultRetirePolicy()
+	lastFire := now.AddDate(0, 0, -120)
+
+	cases := []struct {
+		name string
+		meta Meta
+		act  Activity
+		want []string
+	}{
+		{"fresh and quiet", Meta{CreatedAt: now.AddDate(0, 0, -10)}, Activity{}, nil},
+		{"never fired", Meta{CreatedAt: now.AddDate(0, 0, -100)}, Activity{}, []string{"never fired"}},
+		{"stale", Meta{CreatedAt: now.AddDate(-1, 0, 0)}, Activity{Triggers: 3, LastTriggerAt: &lastFire}, []string{"no fires in 120 days"}},
+		{"noisy", Meta{CreatedAt: now}, Activity{FalsePositives: 9, Confirmed: 1, FPRatio: 0.9}, []string{"90% false positives (9 of 10 triaged)"}},
+		{"noisy but too few triaged", Meta{CreatedAt: now}, Activity{FalsePositives: 2, FPRatio: 1}, nil},
+	}
+	for _, tc := range cases {
+		got := policy.Signals(tc.meta, tc.act, now)
+		if len(got) != len(tc.want) {
+			t.Errorf("%s: signals = %v, want %v", tc.name, got, tc.want)
+			continue
+		}
+		for i := range got {
+			if !strings.HasPrefix(got[i], tc.want[i]) {
+				t.Errorf("%s: signal %q, want prefix %q", tc.name, got[i], tc.want[i])
+			}
+		}
+	}
+}
+
+func TestSlugForRuleID(t *testing.T) {
+	idToSlug := map[string]string{"quokka-hand-built-sql": "hand-built-sql"}
+	for id, want := range map[string]string{
+		"quokka-hand-built-sql":                  "hand-built-sql",
+		"tmp.rdbg..quokka.quokka-hand-built-sql": "hand-built-sql",
+		"python.lang.security.audit.md5":         "",
+		"":                                       "",
+	} {
+		if got := SlugForRuleID(idToSlug, id); got != want {
+			t.Errorf("SlugForRuleID(%q) = %q, want %q", id, got, want)
+		}
+	}
+}
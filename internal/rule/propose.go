@@ -0,0 +1,699 @@
+package rule
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/diffsec/quokka/internal/finding"
+	"github.com/diffsec/quokka/internal/treesitter"
+	"gopkg.in/yaml.v3"
+)
+
+// ProposeInput is everything Propose generalises from.
+type ProposeInput struct {
+	Finding *finding.Finding
+	// Slug names the rule; the opengrep id is "quokka-" + Slug.
+	Slug string
+	// Language is the tree-sitter language name of the finding's file.
+	Language string
+	// Source is the finding's file. Propose parses it with tree-sitter for
+	// the sink call, the enclosing function and the test fixture; without
+	// it the finding's snippet is parsed instead.
+	Source []byte
+}
+
+// Proposal is a draft opengrep rule generalised from a finding.
+type Proposal struct {
+	Slug   string `json:"slug"`
+	RuleID string `json:"rule_id"`
+	// Mode is "taint" when the finding's flow trace gave a usable source,
+	// otherwise "search".
+	Mode    string   `json:"mode"`
+	Pattern string   `json:"pattern"`
+	Inside  []string `json:"inside,omitempty"`
+	Sources []string `json:"sources,omitempty"`
+	Content []byte   `json:"-"`
+	// SinkLine is the line of the finding's file the rule must match.
+	SinkLine int `json:"sink_line"`
+	// Fixture is the enclosing function (or the sink line) with a ruleid:
+	// annotation above the sink, for .quokka/rules/tests/<slug>/.
+	FixtureName string `json:"fixture_name"`
+	Fixture     string `json:"-"`
+	Reasoning   string `json:"reasoning"`
+}
+
+// opengrepLanguages maps tree-sitter language names to opengrep's.
+var opengrepLanguages = map[string]string{
+	"go": "go", "python": "python", "javascript": "javascript",
+	"javascriptreact": "javascript", "typescript": "typescript",
+	"typescriptreact": "typescript", "java": "java", "rust": "rust",
+	"ruby": "ruby", "c": "c", "cpp": "cpp",
+}
+
+// traceLocRE splits a flow-trace step ("file:line: code") into its parts.
+var traceLocRE = regexp.MustCompile(`^(.*?):(\d+):\s?(.*)$`)
+
+// Propose generalises a finding into a draft rule. With a flow trace whose
+// source is a call or attribute access, it writes a taint-mode rule from
+// that source to the sink call; otherwise a search-mode rule matching the
+// sink call, constrained by the assignment that built its argument when
+// the enclosing function shows one. Locals become metavariables, string
+// literals become "...", and module or global names stay literal. Calls,
+// assignments and literals all come from the tree-sitter parse of the
+// file, never from matching its text.
+func Propose(in ProposeInput) (*Proposal, error) {
+	f := in.Finding
+	lang, ok := opengrepLanguages[in.Language]
+	if !ok {
+		return nil, fmt.Errorf("cannot propose a rule for %s: unsupported language", f.Location.File)
+	}
+
+	sinkLine, sourceLine := f.Location.LineStart, 0
+	var pathLines []int
+	if ft := f.FlowTrace; ft != nil {
+		if line, ok := traceLine(ft.Sink, f.Location.File); ok {
+			sinkLine = line
+		}
+		sourceLine, _ = traceLine(ft.Source, f.Location.File)
+		for _, step := range ft.Path {
+			if line, ok := traceLine(step, f.Location.File); ok {
+				pathLines = append(pathLines, line)
+			}
+		}
+	}
+
+	// at is the sink line in the parsed source: the first call of the
+	// snippet when the file itself is unavailable.
+	source, at := in.Source, sinkLine
+	if source == nil {
+		source, at, sourceLine, pathLines = []byte(f.Location.Snippet), 0, 0, nil
+	}
+	s, err := parseSyntax(source, f.Location.File)
+	if err != nil {
+		return nil, fmt.Errorf("cannot propose a rule for %s: %w", f.Location.File, err)
+	}
+	if at == 0 && len(s.ff.Calls) > 0 {
+		at = s.ff.Calls[0].Line
+	}
+	s.enclose(at)
+
+	g := newGeneraliser(s.locals())
+	sink, ok := s.sinkCall(at, s.taintedNames(append(pathLines, sourceLine)))
+	if !ok {
+		return nil, fmt.Errorf("no call expression found at the sink (%s:%d)", f.Location.File, sinkLine)
+	}
+
+	p := &Proposal{Slug: in.Slug, RuleID: "quokka-" + in.Slug, SinkLine: sinkLine}
+	if src, ok := g.source(s, sourceLine); ok && sourceLine != sinkLine {
+		p.Mode = "taint"
+		p.Sources = []string{src}
+		p.Pattern = g.callee(sink.Callee) + "(...)"
+	} else {
+		p.Mode = "search"
+		p.Pattern = g.call(s, sink)
+		p.Inside = g.buildingAssigns(s, sink)
+	}
+
+	content, err := p.ruleYAML(f, lang)
+	if err != nil {
+		return nil, err
+	}
+	p.Content = content
+	p.FixtureName, p.Fixture = fixtureFor(in, s, at)
+	p.Reasoning = p.reasoning(f, s.functionName(), sink.Callee)
+	return p, nil
+}
+
+// ProposalSlug derives a default slug from the finding's CWE and sink call,
+// e.g. "cwe-89-execute".
+func ProposalSlug(f *finding.Finding) string {
+	name := "pattern"
+	code := f.Location.Snippet
+	if f.FlowTrace != nil {
+		if _, _, c, ok := parseTraceStep(f.FlowTrace.Sink); ok {
+			code = c
+		}
+	}
+	if s, err := parseSyntax([]byte(code), f.Location.File); err == nil && len(s.ff.Calls) > 0 {
+		if c, ok := s.sinkCall(s.ff.Calls[0].Line, nil); ok {
+			name = c.Name()
+		}
+	}
+	prefix := "proposed"
+	if f.CWE != "" {
+		prefix = f.CWE
+	}
+	return slugify(prefix + "-" + name)
+}
+
+func (p *Proposal) ruleYAML(f *finding.Finding, lang string) ([]byte, error) {
+	entry := proposedRule{
+		ID:        p.RuleID,
+		Message:   strings.TrimSpace(f.Title),
+		Severity:  opengrepSeverity(f.Severity),
+		Languages: []string{lang},
+		Metadata:  map[string]string{"source_finding": f.ID},
+	}
+	if f.CWE != "" {
+		entry.Metadata["cwe"] = f.CWE
+	}
+	switch {
+	case p.Mode == "taint":
+		entry.Mode = "taint"
+		for _, s := range p.Sources {
+			entry.PatternSources = append(entry.PatternSources, map[string]string{"pattern": s})
+		}
+		entry.PatternSinks = []map[string]string{{"pattern": p.Pattern}}
+	case len(p.Inside) > 0:
+		for _, in := range p.Inside {
+			entry.Patterns = append(entry.Patterns, map[string]string{"pattern-inside": in + "\n...\n"})
+		}
+		entry.Patterns = append(entry.Patterns, map[string]string{"pattern": p.Pattern})
+	default:
+		entry.Pattern = p.Pattern
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(map[string][]proposedRule{"rules": {entry}}); err != nil {
+		return nil, fmt.Errorf("marshal rule: %w", err)
+	}
+	out := buf.Bytes()
+	if _, err := ParseRuleFile(out); err != nil {
+		return nil, fmt.Errorf("generated rule is invalid: %w", err)
+	}
+	return out, nil
+}
+
+func (p *Proposal) reasoning(f *finding.Finding, function, callee string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Generalised from %s", f.ID)
+	if f.CWE != "" {
+		fmt.Fprintf(&b, " (%s)", f.CWE)
+	}
+	fmt.Fprintf(&b, " at %s:%d", f.Location.File, p.SinkLine)
+	if function != "" {
+		fmt.Fprintf(&b, " in %s", function)
+	}
+	fmt.Fprintf(&b, ": sink %s", callee)
+	if p.Mode == "taint" {
+		fmt.Fprintf(&b, ", source %s (taint mode)", strings.Join(p.Sources, ", "))
+	} else if len(p.Inside) > 0 {
+		b.WriteString(", argument built by the preceding assignment")
+	}
+	b.WriteString(".")
+	return b.String()
+}
+
+type proposedRule struct {
+	ID             string              `yaml:"id"`
+	Message        string              `yaml:"message"`
+	Severity       string              `yaml:"severity"`
+	Languages      []string            `yaml:"languages"`
+	Mode           string              `yaml:"mode,omitempty"`
+	Pattern        string              `yaml:"pattern,omitempty"`
+	Patterns       []map[string]string `yaml:"patterns,omitempty"`
+	PatternSources []map[string]string `yaml:"pattern-sources,omitempty"`
+	PatternSinks   []map[string]string `yaml:"pattern-sinks,omitempty"`
+	Metadata       map[string]string   `yaml:"metadata,omitempty"`
+}
+
+func opengrepSeverity(s finding.Severity) string {
+	switch s {
+	case finding.SeverityCritical, finding.SeverityHigh:
+		return "ERROR"
+	case finding.SeverityMedium:
+		return "WARNING"
+	}
+	return "INFO"
+}
+
+// fixtureFor renders the enclosing function (or just the sink line),
+// dedented, with a ruleid: annotation above the sink line.
+func fixtureFor(in ProposeInput, s *syntax, sinkLine int) (string, string) {
+	name := filepath.Base(filepath.FromSlash(in.Finding.Location.File))
+
+	start, end := sinkLine, sinkLine
+	if fn := s.function(); fn != nil {
+		start, end = fn.Line, fn.EndLine
+	}
+	lines := s.ff.Lines[start-1 : end]
+
+	indent := leadingSpace(lines[0])
+	comment := "//"
+	if in.Language == "python" || in.Language == "ruby" {
+		comment = "#"
+	}
+	var b strings.Builder
+	for i, l := range lines {
+		l = strings.TrimPrefix(l, indent)
+		if start+i == sinkLine {
+			fmt.Fprintf(&b, "%s%s ruleid: quokka-%s\n", leadingSpace(l), comment, in.Slug)
+		}
+		b.WriteString(l)
+		b.WriteByte('\n')
+	}
+	return name, b.String()
+}
+
+// parseTraceStep splits "file:line: code" as written by think's FlowTrace.
+func parseTraceStep(s string) (file string, line int, code string, ok bool) {
+	m := traceLocRE.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return "", 0, "", false
+	}
+	line, err := strconv.Atoi(m[2])
+	if err != nil {
+		return "", 0, "", false
+	}
+	return m[1], line, strings.TrimSpace(m[3]), true
+}
+
+// traceLine returns the line of a flow-trace step in file; steps in other
+// files have no line in the parsed source.
+func traceLine(step, file string) (int, bool) {
+	stepFile, line, _, ok := parseTraceStep(step)
+	if !ok || filepath.ToSlash(filepath.Clean(stepFile)) != filepath.ToSlash(filepath.Clean(file)) {
+		return 0, false
+	}
+	return line, true
+}
+
+func leadingSpace(s string) string {
+	return s[:len(s)-len(strings.TrimLeft(s, " \t"))]
+}
+
+func slugify(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+// syntax is the tree-sitter view of the code Propose generalises from: the
+// flow extractor's call, assignment and function nodes, the leaf tokens,
+// and which function encloses the sink (-1 for module-level code).
+type syntax struct {
+	ff     *treesitter.FileFlow
+	tokens []treesitter.Token
+	fn     int
+}
+
+func parseSyntax(source []byte, relPath string) (*syntax, error) {
+	parser := treesitter.NewParser()
+	ff, err := parser.ExtractFlowFromSource(source, relPath)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := parser.ExtractTokens(source, relPath)
+	if err != nil {
+		return nil, err
+	}
+	return &syntax{ff: ff, tokens: tokens, fn: -1}, nil
+}
+
+// enclose records the innermost function spanning line.
+func (s *syntax) enclose(line int) {
+	for i, fn := range s.ff.Functions {
+		if fn.Line > line || fn.EndLine < line {
+			continue
+		}
+		if s.fn == -1 || fn.EndByte-fn.StartByte < s.ff.Functions[s.fn].EndByte-s.ff.Functions[s.fn].StartByte {
+			s.fn = i
+		}
+	}
+}
+
+func (s *syntax) function() *treesitter.FlowFunction {
+	if s.fn < 0 {
+		return nil
+	}
+	return &s.ff.Functions[s.fn]
+}
+
+func (s *syntax) functionName() string {
+	fn := s.function()
+	switch {
+	case fn == nil:
+		return ""
+	case fn.Parent != "":
+		return fn.Parent + "." + fn.Name
+	}
+	return fn.Name
+}
+
+// assigns returns the assignments directly inside the enclosing function.
+func (s *syntax) assigns() []treesitter.FlowAssign {
+	if s.fn < 0 {
+		return nil
+	}
+	var out []treesitter.FlowAssign
+	for _, a := range s.ff.Assigns {
+		if s.ff.EnclosingFunction(a.StartByte) == s.fn {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// locals returns the enclosing function's parameters and assigned names,
+// or nil when the sink is not inside a function.
+func (s *syntax) locals() map[string]bool {
+	fn := s.function()
+	if fn == nil {
+		return nil
+	}
+	out := map[string]bool{}
+	for _, p := range fn.Params {
+		out[p] = true
+	}
+	for _, a := range s.assigns() {
+		for _, t := range a.Targets {
+			out[chainRoot(t)] = true
+		}
+	}
+	return out
+}
+
+// tokensIn returns the tokens lying within the byte range [start, end).
+func (s *syntax) tokensIn(start, end int) []treesitter.Token {
+	i := sort.Search(len(s.tokens), func(i int) bool { return s.tokens[i].StartByte >= start })
+	j := i
+	for j < len(s.tokens) && s.tokens[j].EndByte <= end {
+		j++
+	}
+	return s.tokens[i:j]
+}
+
+// argTokens returns the tokens of each argument of c.
+func (s *syntax) argTokens(c treesitter.FlowCall) [][]treesitter.Token {
+	out := make([][]treesitter.Token, len(c.Args))
+	for i, a := range c.Args {
+		out[i] = s.tokensIn(c.ArgStarts[i], c.ArgStarts[i]+len(a))
+	}
+	return out
+}
+
+// taintedNames returns the names assigned on the flow trace's source and
+// path lines. They identify which call on the sink line the tainted value
+// reaches.
+func (s *syntax) taintedNames(lines []int) map[string]bool {
+	out := map[string]bool{}
+	for _, a := range s.ff.Assigns {
+		if !slices.Contains(lines, a.Line) {
+			continue
+		}
+		for _, t := range a.Targets {
+			out[t] = true
+		}
+	}
+	return out
+}
+
+// sinkCall chooses the call on the sink line that the tainted value
+// reaches directly (the innermost such call), or the outermost call when
+// nothing is known about taint.
+func (s *syntax) sinkCall(line int, tainted map[string]bool) (treesitter.FlowCall, bool) {
+	var calls []treesitter.FlowCall
+	for _, c := range s.ff.Calls {
+		if c.Line == line {
+			calls = append(calls, c)
+		}
+	}
+	if len(calls) == 0 {
+		return treesitter.FlowCall{}, false
+	}
+	span := func(c treesitter.FlowCall) int { return c.EndByte - c.StartByte }
+	best, outer := -1, 0
+	for i, c := range calls {
+		if c.StartByte < calls[outer].StartByte || c.StartByte == calls[outer].StartByte && span(c) > span(calls[outer]) {
+			outer = i
+		}
+		for _, arg := range s.argTokens(c) {
+			if mentionsAny(arg, tainted) && (best < 0 || span(c) < span(calls[best])) {
+				best = i
+			}
+		}
+	}
+	if best < 0 {
+		best = outer
+	}
+	return calls[best], true
+}
+
+// mentionsAny reports whether toks refer to one of names as a value (not
+// as a member of something else).
+func mentionsAny(toks []treesitter.Token, names map[string]bool) bool {
+	for i, t := range toks {
+		if t.Kind == treesitter.TokenIdent && !isMember(toks, i) && names[t.Text] {
+			return true
+		}
+	}
+	return false
+}
+
+// isMember reports whether the identifier at toks[i] is accessed on
+// something else (the "execute" of cur.execute).
+func isMember(toks []treesitter.Token, i int) bool {
+	if i == 0 {
+		return false
+	}
+	switch toks[i-1].Text {
+	case ".", "->", "::", "?.", "&.":
+		return true
+	}
+	return false
+}
+
+// chainAt returns the member chain (a.b.c) starting at the identifier
+// toks[i] and the index just past it.
+func chainAt(toks []treesitter.Token, i int) (string, int) {
+	chain := toks[i].Text
+	j := i + 1
+	for j+1 < len(toks) && toks[j].Text == "." && toks[j+1].Kind == treesitter.TokenIdent {
+		chain += "." + toks[j+1].Text
+		j += 2
+	}
+	return chain, j
+}
+
+func chainRoot(chain string) string {
+	if i := strings.IndexByte(chain, '.'); i >= 0 {
+		return chain[:i]
+	}
+	return chain
+}
+
+// keywords stay literal wherever they appear in a pattern.
+var keywords = map[string]bool{
+	"True": true, "False": true, "None": true, "nil": true, "null": true,
+	"true": true, "false": true, "undefined": true, "self": true, "this": true,
+	"and": true, "or": true, "not": true, "in": true, "is": true, "new": true,
+	"await": true, "typeof": true, "instanceof": true, "lambda": true,
+	"var": true, "let": true, "const": true, "return": true,
+}
+
+// generaliser rewrites source tokens into opengrep pattern syntax.
+type generaliser struct {
+	locals map[string]bool // nil when the sink is not inside a function
+	vars   map[string]string
+	used   map[string]bool
+}
+
+func newGeneraliser(locals map[string]bool) *generaliser {
+	return &generaliser{locals: locals, vars: map[string]string{}, used: map[string]bool{}}
+}
+
+// isLocal reports whether a chain's root is a parameter or assigned in the
+// enclosing function. Without a function every name counts as local, so
+// only calls to bare functions and dotted callees stay literal.
+func (g *generaliser) isLocal(chain string) bool {
+	root := chainRoot(chain)
+	if keywords[root] {
+		return false
+	}
+	return g.locals == nil || g.locals[root]
+}
+
+// metavar returns the metavariable standing for a name, derived from it
+// (user_id -> $USER_ID) so patterns stay readable.
+func (g *generaliser) metavar(name string) string {
+	if mv, ok := g.vars[name]; ok {
+		return mv
+	}
+	base := strings.ToUpper(slugify(name))
+	base = strings.ReplaceAll(base, "-", "_")
+	if base == "" || base[0] < 'A' || base[0] > 'Z' {
+		base = "X" + base
+	}
+	mv := "$" + base
+	for n := 2; g.used[mv]; n++ {
+		mv = fmt.Sprintf("$%s%d", base, n)
+	}
+	g.vars[name] = mv
+	g.used[mv] = true
+	return mv
+}
+
+// callee keeps module and global callees literal and abstracts a local
+// receiver: cursor.execute -> $CURSOR.execute when cursor is a local.
+func (g *generaliser) callee(chain string) string {
+	i := strings.IndexByte(chain, '.')
+	if i < 0 || g.locals == nil {
+		return chain
+	}
+	if root := chain[:i]; g.locals[root] && !keywords[root] {
+		return g.metavar(root) + chain[i:]
+	}
+	return chain
+}
+
+func (g *generaliser) call(s *syntax, c treesitter.FlowCall) string {
+	args := s.argTokens(c)
+	out := make([]string, len(args))
+	for i, a := range args {
+		out[i] = g.render(a)
+	}
+	return g.callee(c.Callee) + "(" + strings.Join(out, ", ") + ")"
+}
+
+// render generalises a run of tokens: string literals become "..." (f"..."
+// for Python f-strings), local names become metavariables, and everything
+// else stays as written. Comments are dropped and the whitespace between
+// tokens collapses to one space.
+func (g *generaliser) render(toks []treesitter.Token) string {
+	var b strings.Builder
+	prevEnd := -1
+	for i, t := range toks {
+		if t.Kind == treesitter.TokenComment {
+			continue
+		}
+		if prevEnd >= 0 && t.StartByte > prevEnd {
+			b.WriteByte(' ')
+		}
+		prevEnd = t.EndByte
+		switch {
+		case t.Kind == treesitter.TokenString:
+			b.WriteString(stringPattern(t.Text))
+		case t.Kind == treesitter.TokenIdent && !isMember(toks, i):
+			called := i+1 < len(toks) && toks[i+1].Text == "("
+			if called || keywords[t.Text] || !g.isLocal(t.Text) {
+				b.WriteString(t.Text)
+			} else {
+				b.WriteString(g.metavar(t.Text))
+			}
+		default:
+			b.WriteString(t.Text)
+		}
+	}
+	return b.String()
+}
+
+// stringPattern is the placeholder for a string literal token.
+func stringPattern(lit string) string {
+	if i := strings.IndexAny(lit, "\"'`"); i > 0 && strings.ContainsAny(lit[:i], "fF") {
+		return `f"..."`
+	}
+	return `"..."`
+}
+
+// source turns the flow trace's source line into a taint source pattern:
+// the first call or attribute access on a module or global name in the
+// line's assigned value, e.g. request.args.get(...) or request.form.
+func (g *generaliser) source(s *syntax, line int) (string, bool) {
+	if line == 0 || g.locals == nil {
+		return "", false
+	}
+	from := 0
+	for _, a := range s.ff.Assigns {
+		if a.Line == line {
+			from = a.StartByte
+			break
+		}
+	}
+	for _, c := range s.ff.Calls {
+		if c.Line == line && c.StartByte >= from && strings.Contains(c.Callee, ".") && !g.isLocal(c.Callee) {
+			return c.Callee + "(...)", true
+		}
+	}
+	var toks []treesitter.Token
+	for _, t := range s.tokens {
+		if t.Line == line && t.StartByte >= from {
+			toks = append(toks, t)
+		}
+	}
+	for i := 0; i < len(toks); i++ {
+		if toks[i].Kind != treesitter.TokenIdent || isMember(toks, i) {
+			continue
+		}
+		chain, next := chainAt(toks, i)
+		if strings.Contains(chain, ".") && !g.isLocal(chain) {
+			return chain, true
+		}
+		i = next - 1
+	}
+	return "", false
+}
+
+// buildingAssigns returns pattern-inside statements for sink arguments that
+// are locals built by an assignment earlier in the function, e.g.
+// `$QUERY = "..." + $UID` for cursor.execute(query).
+func (g *generaliser) buildingAssigns(s *syntax, sink treesitter.FlowCall) []string {
+	if g.locals == nil {
+		return nil
+	}
+	assigns := s.assigns()
+	var out []string
+	for _, arg := range s.argTokens(sink) {
+		if len(arg) != 1 || arg[0].Kind != treesitter.TokenIdent {
+			continue
+		}
+		name := arg[0].Text
+		if !g.locals[name] || keywords[name] {
+			continue
+		}
+		var last *treesitter.FlowAssign
+		for i := range assigns {
+			if a := &assigns[i]; a.Line < sink.Line && slices.Contains(a.Targets, name) {
+				last = a
+			}
+		}
+		if last == nil || plainCopy(s.tokensIn(last.StartByte, last.EndByte)) {
+			continue // parameters and plain copies carry no pattern
+		}
+		// The statement runs from the first token on the assignment's line
+		// to the end of its value, so declarations keep their keyword.
+		var stmt []treesitter.Token
+		for _, t := range s.tokens {
+			if t.Line >= last.Line && t.EndByte <= last.EndByte {
+				stmt = append(stmt, t)
+			}
+		}
+		out = append(out, g.render(stmt))
+	}
+	return out
+}
+
+// plainCopy reports whether a value is just a name or member chain.
+func plainCopy(toks []treesitter.Token) bool {
+	for _, t := range toks {
+		if t.Kind != treesitter.TokenIdent && t.Text != "." {
+			return false
+		}
+	}
+	return true
+}
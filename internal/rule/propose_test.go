@@ -0,0 +1,220 @@
+package rule
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/diffsec/quokka/internal/finding"
+	"github.com/diffsec/quokka/internal/treesitter"
+)
+
+const proposeSource = `import sqlite3
+from flask import request
+
+def get_user(conn):
+    user_id = request.args.get("id")
+    query = "SELECT * FROM users WHERE id = " + user_id
+    cur = conn.cursor()
+    cur.execute(query)
+    return cur.fetchone()
+`
+
+func proposeFinding() *finding.Finding {
+	return &finding.Finding{
+		ID:       "FIND-001",
+		Title:    "SQL injection via string concatenation",
+		Severity: finding.SeverityHigh,
+		CWE:      "CWE-89",
+		Location: finding.Location{File: "app/users.py", LineStart: 8, Snippet: "cur.execute(query)"},
+	}
+}
+
+func TestPropose_TaintModeFromFlowTrace(t *testing.T) {
+	f := proposeFinding()
+	f.FlowTrace = &finding.FlowTrace{
+		Source: `app/users.py:5: user_id = request.args.get("id")`,
+		Path:   []string{`app/users.py:6: query = "SELECT * FROM users WHERE id = " + user_id`},
+		Sink:   "app/users.py:8: cur.execute(query)",
+	}
+	p, err := Propose(ProposeInput{
+		Finding:  f,
+		Slug:     ProposalSlug(f),
+		Language: "python",
+		Source:   []byte(proposeSource),
+	})
+	if err != nil {
+		t.Fatalf("Propose: %v", err)
+	}
+	if p.Slug != "cwe-89-execute" || p.RuleID != "quokka-cwe-89-execute" {
+		t.Errorf("slug/id = %q/%q", p.Slug, p.RuleID)
+	}
+	if p.Mode != "taint" {
+		t.Fatalf("mode = %q, want taint", p.Mode)
+	}
+	if len(p.Sources) != 1 || p.Sources[0] != "request.args.get(...)" {
+		t.Errorf("sources = %v", p.Sources)
+	}
+	if p.Pattern != "$CUR.execute(...)" {
+		t.Errorf("sink pattern = %q", p.Pattern)
+	}
+
+	rf, err := ParseRuleFile(p.Content)
+	if err != nil {
+		t.Fatalf("generated rule does not parse: %v\n%s", err, p.Content)
+	}
+	r := rf.Rules[0]
+	if r.Mode != "taint" || r.Severity != "ERROR" || len(r.Languages) != 1 || r.Languages[0] != "python" {
+		t.Errorf("rule = %+v", r)
+	}
+	if !strings.Contains(string(p.Content), "source_finding: FIND-001") {
+		t.Errorf("metadata missing source finding:\n%s", p.Content)
+	}
+
+	exp := ParseAnnotations(p.FixtureName, []byte(p.Fixture))
+	if p.FixtureName != "users.py" || len(exp) != 1 || !exp[0].Match || exp[0].RuleID != p.RuleID {
+		t.Fatalf("fixture %s expectations = %+v\n%s", p.FixtureName, exp, p.Fixture)
+	}
+	if !strings.HasPrefix(p.Fixture, "def get_user(conn):\n") {
+		t.Errorf("fixture should be the dedented function:\n%s", p.Fixture)
+	}
+	if lines := strings.Split(p.Fixture, "\n"); lines[exp[0].Line-1] != "    cur.execute(query)" {
+		t.Errorf("annotation points at %q", lines[exp[0].Line-1])
+	}
+	if !strings.Contains(p.Reasoning, "FIND-001") || !strings.Contains(p.Reasoning, "get_user") {
+		t.Errorf("reasoning = %q", p.Reasoning)
+	}
+}
+
+func TestPropose_SearchModeWithBuildingAssignment(t *testing.T) {
+	p, err := Propose(ProposeInput{
+		Finding:  proposeFinding(),
+		Slug:     "concat-sql",
+		Language: "python",
+		Source:   []byte(proposeSource),
+	})
+	if err != nil {
+		t.Fatalf("Propose: %v", err)
+	}
+	if p.Mode != "search" || p.Pattern != "$CUR.execute($QUERY)" {
+		t.Errorf("mode/pattern = %q/%q", p.Mode, p.Pattern)
+	}
+	if len(p.Inside) != 1 || p.Inside[0] != `$QUERY = "..." + $USER_ID` {
+		t.Errorf("inside = %q", p.Inside)
+	}
+	if !strings.Contains(string(p.Content), "pattern-inside:") {
+		t.Errorf("rule missing pattern-inside:\n%s", p.Content)
+	}
+}
+
+func TestPropose_WithoutFunctionContext(t *testing.T) {
+	f := proposeFinding()
+	p, err := Propose(ProposeInput{Finding: f, Slug: "concat-sql", Language: "python"})
+	if err != nil {
+		t.Fatalf("Propose: %v", err)
+	}
+	if p.Pattern != "cur.execute($QUERY)" || len(p.Inside) != 0 {
+		t.Errorf("pattern = %q inside = %q", p.Pattern, p.Inside)
+	}
+	if p.Fixture != "# ruleid: quokka-concat-sql\ncur.execute(query)\n" {
+		t.Errorf("fixture = %q", p.Fixture)
+	}
+
+	if _, err := Propose(ProposeInput{Finding: f, Slug: "x", Language: "cobol"}); err == nil {
+		t.Error("expected error for unsupported language")
+	}
+	f.Location.Snippet = "return None"
+	if _, err := Propose(ProposeInput{Finding: f, Slug: "x", Language: "python"}); err == nil {
+		t.Error("expected error when the sink has no call")
+	}
+}
+
+func TestPropose_StringsAndCommentsAreNotCode(t *testing.T) {
+	// A call spelled out inside a string or comment on the sink line must
+	// not be taken for the sink.
+	src := `def lookup(conn, name):
+    q = "SELECT * FROM t WHERE n = '%s'" % name  # see db.execute(q) below
+    conn.execute(q, "x.run(y)")  # not os.system(q)
+`
+	f := proposeFinding()
+	f.Location = finding.Location{File: "app/lookup.py", LineStart: 3}
+	p, err := Propose(ProposeInput{Finding: f, Slug: "fmt-sql", Language: "python", Source: []byte(src)})
+	if err != nil {
+		t.Fatalf("Propose: %v", err)
+	}
+	if p.Pattern != `$CONN.execute($Q, "...")` {
+		t.Errorf("pattern = %q", p.Pattern)
+	}
+	if len(p.Inside) != 1 || p.Inside[0] != `$Q = "..." % $NAME` {
+		t.Errorf("inside = %q", p.Inside)
+	}
+	if !strings.Contains(p.Reasoning, "in lookup") {
+		t.Errorf("reasoning = %q", p.Reasoning)
+	}
+}
+
+func TestGeneraliser_Render(t *testing.T) {
+	g := newGeneraliser(map[string]bool{"db": true, "id": true})
+	cases := map[string]string{
+		`db.Query(fmt.Sprintf("SELECT %s", id))`: `$DB.Query(fmt.Sprintf("...", $ID))`,
+		`f"SELECT {id}" + ORDER`:                 `f"..." + ORDER`,
+		`id == None and id.strip()`:              `$ID == None and $ID.strip()`,
+	}
+	for in, want := range cases {
+		toks, err := treesitter.NewParser().ExtractTokens([]byte(in), "expr.py")
+		if err != nil {
+			t.Fatalf("ExtractTokens(%q): %v", in, err)
+		}
+		if got := g.render(toks); got != want {
+			t.Errorf("render(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestStore_DraftSkippedUntilPromoted(t *testing.T) {
+	s, cleanup := newTestStore(t)
+	defer cleanup()
+
+	m := validMeta()
+	m.Draft = true
+	if err := s.Add("hand-built-sql", []byte(validRule), m); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	paths, err := s.EnabledRulePaths()
+	if err != nil || len(paths) != 0 {
+		t.Fatalf("draft should not be scanned: %v %v", paths, err)
+	}
+	if err := s.Annotate("hand-built-sql", VerdictKeep, "matches are real"); err != nil {
+		t.Fatalf("Annotate: %v", err)
+	}
+	if paths, _ := s.EnabledRulePaths(); len(paths) != 0 {
+		t.Errorf("a keep verdict alone should not promote a draft, got %v", paths)
+	}
+	if err := s.Promote("hand-built-sql"); err != nil {
+		t.Fatalf("Promote: %v", err)
+	}
+	if paths, _ := s.EnabledRulePaths(); len(paths) != 1 {
+		t.Errorf("promoted draft should be scanned, got %v", paths)
+	}
+}
+
+func TestValidateStructure_TaintMode(t *testing.T) {
+	ok := `rules:
+  - id: t
+    message: m
+    mode: taint
+    pattern-sources: [{pattern: source()}]
+    pattern-sinks: [{pattern: sink(...)}]
+`
+	if _, err := ParseRuleFile([]byte(ok)); err != nil {
+		t.Errorf("taint rule rejected: %v", err)
+	}
+	missing := `rules:
+  - id: t
+    message: m
+    mode: taint
+    pattern-sources: [{pattern: source()}]
+`
+	if _, err := ParseRuleFile([]byte(missing)); err == nil {
+		t.Error("taint rule without sinks accepted")
+	}
+}
@@ -0,0 +1,266 @@
+package rule
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// testsDirName is the subdirectory of the rules dir holding fixture files,
+// one directory per slug: .quokka/rules/tests/<slug>/. List and
+// EnabledRulePaths only read the top level, so fixtures are never scanned
+// as rules.
+const testsDirName = "tests"
+
+// Annotation markers, following the opengrep/semgrep test convention: a
+// comment "ruleid: <id>" says the next code line must match rule <id>;
+// "ok: <id>" says it must not. Several ids may be comma-separated.
+const (
+	annotationRuleID = "ruleid:"
+	annotationOK     = "ok:"
+)
+
+// commentLeaders are the comment prefixes recognised before an annotation.
+var commentLeaders = []string{"//", "#", "--", "/*", "<!--", ";", "*"}
+
+// Expectation is one annotated line in a fixture file.
+type Expectation struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	RuleID string `json:"rule_id"`
+	// Match is true for "ruleid:" (must match) and false for "ok:" (must not).
+	Match bool `json:"match"`
+}
+
+// Match is one result produced by running a rule over its fixtures. File
+// is relative to the fixture directory.
+type Match struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	RuleID string `json:"rule_id"`
+}
+
+// TestResult is the outcome of running a rule against its fixtures.
+type TestResult struct {
+	Slug     string `json:"slug"`
+	Files    int    `json:"files"`
+	Expected int    `json:"expected"`
+	Matched  int    `json:"matched"`
+	// Missed are "ruleid:" lines the rule did not match.
+	Missed []Expectation `json:"missed,omitempty"`
+	// Unexpected are matches on lines not annotated "ruleid:", including
+	// lines explicitly annotated "ok:".
+	Unexpected []Match `json:"unexpected,omitempty"`
+}
+
+// Passed reports whether the rule matched every "ruleid:" line and nothing
+// else.
+func (r TestResult) Passed() bool {
+	return len(r.Missed) == 0 && len(r.Unexpected) == 0
+}
+
+// TestDir returns the fixture directory for a rule.
+func (s *Store) TestDir(slug string) string {
+	return filepath.Join(s.dir, testsDirName, slug)
+}
+
+// SetTests replaces a rule's fixtures with the files under srcDir. It is a
+// no-op when srcDir already is the rule's fixture directory.
+func (s *Store) SetTests(slug, srcDir string) error {
+	dst := s.TestDir(slug)
+	if a, err := filepath.Abs(srcDir); err == nil {
+		if b, err := filepath.Abs(dst); err == nil && a == b {
+			return nil
+		}
+	}
+	files, err := FixtureFiles(srcDir)
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(dst); err != nil {
+		return fmt.Errorf("clear rule fixtures: %w", err)
+	}
+	for _, f := range files {
+		data, err := os.ReadFile(filepath.Join(srcDir, filepath.FromSlash(f)))
+		if err != nil {
+			return fmt.Errorf("read fixture: %w", err)
+		}
+		path := filepath.Join(dst, filepath.FromSlash(f))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("mkdir rule fixtures: %w", err)
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return fmt.Errorf("write fixture: %w", err)
+		}
+	}
+	return nil
+}
+
+// RuleIDs parses rule YAML and returns every rule id it declares.
+func RuleIDs(content []byte) ([]string, error) {
+	var rf RuleFile
+	if err := yaml.Unmarshal(content, &rf); err != nil {
+		return nil, fmt.Errorf("parse rule YAML: %w", err)
+	}
+	out := make([]string, 0, len(rf.Rules))
+	for _, r := range rf.Rules {
+		if id := strings.TrimSpace(r.ID); id != "" {
+			out = append(out, id)
+		}
+	}
+	return out, nil
+}
+
+// FixtureFiles lists the files under dir (recursively), relative to dir and
+// sorted. A missing directory yields no files and no error.
+func FixtureFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir && os.IsNotExist(err) {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".") {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list rule fixtures: %w", err)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// ParseAnnotations extracts the expectations from one fixture file. An
+// annotation applies to the next line that is not itself an annotation,
+// so several annotations can stack above one line of code.
+func ParseAnnotations(file string, content []byte) []Expectation {
+	var out []Expectation
+	var pending []Expectation
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if ann, ok := parseAnnotation(scanner.Text()); ok {
+			for _, a := range ann {
+				a.File = file
+				pending = append(pending, a)
+			}
+			continue
+		}
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		for _, p := range pending {
+			p.Line = line
+			out = append(out, p)
+		}
+		pending = nil
+	}
+	return out
+}
+
+// parseAnnotation recognises a comment line holding "ruleid:" or "ok:".
+func parseAnnotation(text string) ([]Expectation, bool) {
+	s := strings.TrimSpace(text)
+	leader := ""
+	for _, l := range commentLeaders {
+		if strings.HasPrefix(s, l) {
+			leader = l
+			break
+		}
+	}
+	if leader == "" {
+		return nil, false
+	}
+	s = strings.TrimSpace(strings.TrimPrefix(s, leader))
+	s = strings.TrimSpace(strings.TrimSuffix(strings.TrimSuffix(s, "*/"), "-->"))
+
+	var match bool
+	switch {
+	case strings.HasPrefix(s, annotationRuleID):
+		match = true
+		s = strings.TrimPrefix(s, annotationRuleID)
+	case strings.HasPrefix(s, annotationOK):
+		s = strings.TrimPrefix(s, annotationOK)
+	default:
+		return nil, false
+	}
+
+	var out []Expectation
+	for _, id := range strings.Split(s, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			out = append(out, Expectation{RuleID: id, Match: match})
+		}
+	}
+	return out, len(out) > 0
+}
+
+// Evaluate compares expectations with the matches a rule produced. Rule ids
+// on matches are normalised to the declared ids, since opengrep prefixes
+// them with the rule file's path.
+func Evaluate(slug string, ids []string, expect []Expectation, matches []Match) TestResult {
+	res := TestResult{Slug: slug}
+
+	type key struct {
+		file, id string
+		line     int
+	}
+	want := map[key]bool{}
+	for _, e := range expect {
+		if e.Match {
+			want[key{e.File, e.RuleID, e.Line}] = true
+		}
+	}
+
+	got := map[key]bool{}
+	for _, m := range matches {
+		k := key{m.File, normaliseRuleID(ids, m.RuleID), m.Line}
+		if got[k] {
+			continue
+		}
+		got[k] = true
+		if want[k] {
+			res.Matched++
+		} else {
+			res.Unexpected = append(res.Unexpected, Match{File: k.file, Line: k.line, RuleID: k.id})
+		}
+	}
+
+	for _, e := range expect {
+		if !e.Match {
+			continue
+		}
+		res.Expected++
+		if !got[key{e.File, e.RuleID, e.Line}] {
+			res.Missed = append(res.Missed, e)
+		}
+	}
+	return res
+}
+
+// normaliseRuleID maps an emitted rule id to the declared id it ends with.
+func normaliseRuleID(ids []string, emitted string) string {
+	for _, id := range ids {
+		if emitted == id || strings.HasSuffix(emitted, "."+id) {
+			return id
+		}
+	}
+	return emitted
+}
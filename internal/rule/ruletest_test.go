@@ -0,0 +1,114 @@
+package rule
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseAnnotations(t *testing.T) {
+	src := `package main
+
+// ruleid: quokka-a, quokka-b
+// ok: quokka-c
+call()
+
+/* ok: quokka-a */
+
+safe()
+# ruleid: quokka-a
+<!-- ruleid: quokka-c -->
+// a regular comment
+`
+	got := ParseAnnotations("main.go", []byte(src))
+	want := []Expectation{
+		{File: "main.go", Line: 5, RuleID: "quokka-a", Match: true},
+		{File: "main.go", Line: 5, RuleID: "quokka-b", Match: true},
+		{File: "main.go", Line: 5, RuleID: "quokka-c", Match: false},
+		{File: "main.go", Line: 9, RuleID: "quokka-a", Match: false},
+		{File: "main.go", Line: 12, RuleID: "quokka-a", Match: true},
+		{File: "main.go", Line: 12, RuleID: "quokka-c", Match: true},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d expectations, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("expectation %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	ids := []string{"quokka-a"}
+	expect := []Expectation{
+		{File: "a.py", Line: 2, RuleID: "quokka-a", Match: true},
+		{File: "a.py", Line: 4, RuleID: "quokka-a", Match: true},
+		{File: "a.py", Line: 6, RuleID: "quokka-a", Match: false},
+	}
+	matches := []Match{
+		{File: "a.py", Line: 2, RuleID: "tmp.rules.quokka-a"},
+		{File: "a.py", Line: 2, RuleID: "quokka-a"}, // duplicate result on one line
+		{File: "a.py", Line: 6, RuleID: "quokka-a"},
+		{File: "b.py", Line: 1, RuleID: "quokka-a"},
+	}
+	res := Evaluate("a", ids, expect, matches)
+	if res.Passed() || res.Expected != 2 || res.Matched != 1 {
+		t.Errorf("result = %+v", res)
+	}
+	if len(res.Missed) != 1 || res.Missed[0].Line != 4 {
+		t.Errorf("missed = %+v, want line 4", res.Missed)
+	}
+	if len(res.Unexpected) != 2 || res.Unexpected[0].Line != 6 || res.Unexpected[1].File != "b.py" {
+		t.Errorf("unexpected = %+v, want a.py:6 and b.py:1", res.Unexpected)
+	}
+
+	if !Evaluate("a", ids, expect[:1], matches[:1]).Passed() {
+		t.Error("exact match should pass")
+	}
+}
+
+func TestStore_SetTestsAndRemove(t *testing.T) {
+	s, cleanup := newTestStore(t)
+	defer cleanup()
+
+	src := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(src, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, body := range map[string]string{"a.py": "# ruleid: quokka-hand-built-sql\nx\n", "sub/b.py": "y\n", ".hidden": "z"} {
+		if err := os.WriteFile(filepath.Join(src, name), []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Add("hand-built-sql", []byte(validRule), validMeta()); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if err := s.SetTests("hand-built-sql", src); err != nil {
+		t.Fatalf("SetTests: %v", err)
+	}
+	files, err := FixtureFiles(s.TestDir("hand-built-sql"))
+	if err != nil || len(files) != 2 || files[0] != "a.py" || files[1] != "sub/b.py" {
+		t.Errorf("fixtures = %v, %v", files, err)
+	}
+
+	// Setting a rule's fixtures from its own directory keeps them
+	if err := s.SetTests("hand-built-sql", s.TestDir("hand-built-sql")); err != nil {
+		t.Fatalf("SetTests onto itself: %v", err)
+	}
+	if files, _ := FixtureFiles(s.TestDir("hand-built-sql")); len(files) != 2 {
+		t.Errorf("fixtures after self-copy = %v", files)
+	}
+
+	// Fixtures live below the rules dir but are never listed as rules
+	if list, _ := s.List(); len(list) != 1 {
+		t.Errorf("List = %+v, want only the rule", list)
+	}
+
+	if err := s.Remove("hand-built-sql"); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if _, err := os.Stat(s.TestDir("hand-built-sql")); !os.IsNotExist(err) {
+		t.Errorf("fixtures kept after Remove: %v", err)
+	}
+}
@@ -43,7 +43,8 @@ func (s *Store) Dir() string { return s.dir }
 // Add writes a new rule + its metadata. The rule YAML is validated for
 // minimal opengrep structure; the metadata is validated for provenance.
 // Returns an error if a rule with the same slug already exists — Update
-// is the explicit overwrite path.
+// is the explicit overwrite path. Add does not run the rule's tests: rules
+// that are not drafts should come in through sast.AddRule.
 func (s *Store) Add(slug string, content []byte, meta Meta) error {
 	slug = strings.TrimSpace(slug)
 	if slug == "" {
@@ -53,11 +54,7 @@ func (s *Store) Add(slug string, content []byte, meta Meta) error {
 		return fmt.Errorf("slug %q must be lowercase letters, digits, and hyphens only", slug)
 	}
 
-	var rf RuleFile
-	if err := yaml.Unmarshal(content, &rf); err != nil {
-		return fmt.Errorf("parse rule YAML: %w", err)
-	}
-	if err := rf.ValidateStructure(); err != nil {
+	if _, err := ParseRuleFile(content); err != nil {
 		return err
 	}
 
@@ -93,11 +90,7 @@ func (s *Store) Update(slug string, content []byte, meta Meta) error {
 	if _, err := s.ReadMeta(slug); err != nil {
 		return fmt.Errorf("rule %q not found: %w", slug, err)
 	}
-	var rf RuleFile
-	if err := yaml.Unmarshal(content, &rf); err != nil {
-		return fmt.Errorf("parse rule YAML: %w", err)
-	}
-	if err := rf.ValidateStructure(); err != nil {
+	if _, err := ParseRuleFile(content); err != nil {
 		return err
 	}
 	meta.Slug = slug
@@ -110,7 +103,7 @@ func (s *Store) Update(slug string, content []byte, meta Meta) error {
 	return s.writeMeta(slug, meta)
 }
 
-// Remove deletes the rule and its metadata sidecar.
+// Remove deletes the rule, its metadata sidecar and its test fixtures.
 func (s *Store) Remove(slug string) error {
 	rPath := s.rulePath(slug)
 	if _, err := os.Stat(rPath); err != nil {
@@ -124,6 +117,7 @@ func (s *Store) Remove(slug string) error {
 	}
 	// Metadata absence is non-fatal — older imports may not have one.
 	_ = os.Remove(s.metaPath(slug))
+	_ = os.RemoveAll(s.TestDir(slug))
 	return nil
 }
 
@@ -181,7 +175,9 @@ func (s *Store) List() ([]Meta, error) {
 // The verdict can also flip Disabled on the rule itself (retire = disable);
 // because quokka doesn't directly edit the opengrep YAML to add a disabled
 // field, we instead store it in metadata and apply it at scan time when
-// merging rules into the opengrep --config path.
+// merging rules into the opengrep --config path. A keep verdict does not
+// promote a draft: that is Promote, once the draft's tests pass (see
+// sast.AnnotateRule).
 func (s *Store) Annotate(slug string, verdict Verdict, note string) error {
 	if verdict != VerdictUnknown && !IsValidVerdict(verdict) {
 		return fmt.Errorf("invalid verdict %q (valid: keep, refine, retire, escalate)", verdict)
@@ -197,19 +193,53 @@ func (s *Store) Annotate(slug string, verdict Verdict, note string) error {
 	return s.writeMeta(slug, m)
 }
 
+// Promote clears a draft's Draft flag so quokka sast scans it. Callers run
+// the rule's tests first.
+func (s *Store) Promote(slug string) error {
+	m, err := s.ReadMeta(slug)
+	if err != nil {
+		return fmt.Errorf("read meta: %w", err)
+	}
+	m.Draft = false
+	return s.writeMeta(slug, m)
+}
+
 // EnabledRulePaths returns the paths of all rule files that should be passed
-// to opengrep — i.e., rules NOT retired by judge verdict. Stable order.
+// to opengrep — i.e., rules NOT retired by judge verdict and not drafts
+// awaiting review. Tree-sitter query rules are left to
+// EnabledQueryRulePaths. Stable order.
 func (s *Store) EnabledRulePaths() ([]string, error) {
+	return s.enabledPaths(false)
+}
+
+// EnabledQueryRulePaths is EnabledRulePaths for the tree-sitter query rules
+// run by quokka sast --tool treesitter.
+func (s *Store) EnabledQueryRulePaths() ([]string, error) {
+	return s.enabledPaths(true)
+}
+
+// enabledPaths lists the enabled rule files of one engine. A file that no
+// longer parses counts as an opengrep rule, so opengrep reports the error
+// at scan time as it always has.
+func (s *Store) enabledPaths(query bool) ([]string, error) {
 	metas, err := s.List()
 	if err != nil {
 		return nil, err
 	}
 	var paths []string
 	for _, m := range metas {
-		if m.Disabled {
+		if m.Disabled || m.Draft {
 			continue
 		}
-		paths = append(paths, s.rulePath(m.Slug))
+		path := s.rulePath(m.Slug)
+		isQuery := false
+		if data, err := os.ReadFile(path); err == nil {
+			var rf RuleFile
+			isQuery = yaml.Unmarshal(data, &rf) == nil && rf.IsQuery()
+		}
+		if isQuery == query {
+			paths = append(paths, path)
+		}
 	}
 	return paths, nil
 }
@@ -223,17 +253,11 @@ func (s *Store) ParseRuleIDs(slug string) ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
-	var rf RuleFile
-	if err := yaml.Unmarshal(data, &rf); err != nil {
-		return nil, fmt.Errorf("parse rule %q: %w", slug, err)
-	}
-	out := make([]string, 0, len(rf.Rules))
-	for _, r := range rf.Rules {
-		if id := strings.TrimSpace(r.ID); id != "" {
-			out = append(out, id)
-		}
+	ids, err := RuleIDs(data)
+	if err != nil {
+		return nil, fmt.Errorf("rule %q: %w", slug, err)
 	}
-	return out, nil
+	return ids, nil
 }
 
 // RuleIDToSlug returns a map from every opengrep rule id (across all
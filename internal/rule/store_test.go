@@ -228,6 +228,67 @@ func TestStore_EnabledRulePathsSkipsDisabled(t *testing.T) {
 	}
 }
 
+const queryRule = `rules:
+  - id: quokka-shell-true
+    message: subprocess with shell=True
+    severity: ERROR
+    languages: [python]
+    query: |
+      (call arguments: (argument_list (keyword_argument name: (identifier) @kw value: (true)))) @match
+      (#eq? @kw "shell")
+`
+
+func TestStore_EnabledPathsSplitByEngine(t *testing.T) {
+	s, cleanup := newTestStore(t)
+	defer cleanup()
+	if err := s.Add("sql", []byte(validRule), validMeta()); err != nil {
+		t.Fatalf("Add opengrep rule: %v", err)
+	}
+	if err := s.Add("shell", []byte(queryRule), validMeta()); err != nil {
+		t.Fatalf("Add query rule: %v", err)
+	}
+
+	opengrep, err := s.EnabledRulePaths()
+	if err != nil || len(opengrep) != 1 || !strings.HasSuffix(opengrep[0], "sql.yaml") {
+		t.Errorf("EnabledRulePaths = %v, %v; want only sql.yaml", opengrep, err)
+	}
+	query, err := s.EnabledQueryRulePaths()
+	if err != nil || len(query) != 1 || !strings.HasSuffix(query[0], "shell.yaml") {
+		t.Errorf("EnabledQueryRulePaths = %v, %v; want only shell.yaml", query, err)
+	}
+}
+
+func TestValidateStructure_QueryRules(t *testing.T) {
+	cases := map[string]string{
+		"no languages": `rules:
+  - id: q
+    message: m
+    query: (call) @match
+`,
+		"query and pattern": `rules:
+  - id: q
+    message: m
+    languages: [python]
+    query: (call) @match
+    pattern: foo()
+`,
+		"mixed engines": `rules:
+  - id: q
+    message: m
+    languages: [python]
+    query: (call) @match
+  - id: p
+    message: m
+    pattern: foo()
+`,
+	}
+	for name, content := range cases {
+		if _, err := ParseRuleFile([]byte(content)); err == nil {
+			t.Errorf("%s: expected validation error", name)
+		}
+	}
+}
+
 const multiRuleYAML = `rules:
   - id: quokka-sql-concat
     message: hand-built SQL
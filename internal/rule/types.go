@@ -11,6 +11,8 @@ import (
 	"fmt"
 	"strings"
 	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
 // Verdict is the rule-judge-agent's assessment of an accumulated rule.
@@ -63,9 +65,15 @@ type Meta struct {
 	Verdict      Verdict   `yaml:"verdict,omitempty" json:"verdict,omitempty"`
 	VerdictNote  string    `yaml:"verdict_note,omitempty" json:"verdict_note,omitempty"`
 	LastAuditAt  time.Time `yaml:"last_audit_at,omitempty" json:"last_audit_at,omitempty"`
-	TriggerCount int       `yaml:"trigger_count,omitempty" json:"trigger_count,omitempty"`
+	TriggerCount int       `yaml:"trigger_count,omitempty" json:"trigger_count,omitempty"` // maintained from .quokka/rules/activity.jsonl
 	FPCount      int       `yaml:"fp_count,omitempty" json:"fp_count,omitempty"`
 	Disabled     bool      `yaml:"disabled,omitempty" json:"disabled,omitempty"`
+	// Maintained by Store.Record alongside TriggerCount and FPCount.
+	ConfirmedCount int       `yaml:"confirmed_count,omitempty" json:"confirmed_count,omitempty"`
+	LastTriggerAt  time.Time `yaml:"last_trigger_at,omitempty" json:"last_trigger_at,omitempty"`
+	// Draft marks a rule proposed by `quokka rule propose`. Drafts are not
+	// scanned until a keep verdict promotes them.
+	Draft bool `yaml:"draft,omitempty" json:"draft,omitempty"`
 }
 
 // Validate checks required fields on the metadata. Reasoning is encouraged
@@ -103,6 +111,33 @@ type RuleEntry struct {
 	// Patterns is intentionally interface — opengrep allows nested
 	// pattern-either/pattern-not/etc structures we don't fully model.
 	Patterns interface{} `yaml:"patterns,omitempty" json:"patterns,omitempty"`
+	// Mode is "taint" for taint-mode rules, which define sources and sinks
+	// instead of pattern/patterns. Empty means search mode.
+	Mode           string      `yaml:"mode,omitempty" json:"mode,omitempty"`
+	PatternSources interface{} `yaml:"pattern-sources,omitempty" json:"pattern-sources,omitempty"`
+	PatternSinks   interface{} `yaml:"pattern-sinks,omitempty" json:"pattern-sinks,omitempty"`
+	// Query is a tree-sitter S-expression query, run by quokka's built-in
+	// engine (quokka sast --tool treesitter) instead of opengrep. A rule
+	// with a query has no opengrep patterns.
+	Query string `yaml:"query,omitempty" json:"query,omitempty"`
+}
+
+// ParseRuleFile parses rule YAML and checks its structure.
+func ParseRuleFile(content []byte) (RuleFile, error) {
+	var rf RuleFile
+	if err := yaml.Unmarshal(content, &rf); err != nil {
+		return RuleFile{}, fmt.Errorf("parse rule YAML: %w", err)
+	}
+	if err := rf.ValidateStructure(); err != nil {
+		return RuleFile{}, err
+	}
+	return rf, nil
+}
+
+// IsQuery reports whether the file holds tree-sitter query rules rather
+// than opengrep rules, judged by its first rule.
+func (rf RuleFile) IsQuery() bool {
+	return len(rf.Rules) > 0 && strings.TrimSpace(rf.Rules[0].Query) != ""
 }
 
 // ValidateStructure checks that the file has the required opengrep shape.
@@ -114,6 +149,7 @@ func (rf RuleFile) ValidateStructure() error {
 		return fmt.Errorf("rule file must define at least one rule under \"rules:\"")
 	}
 	seen := map[string]bool{}
+	query := rf.IsQuery()
 	for i, r := range rf.Rules {
 		if strings.TrimSpace(r.ID) == "" {
 			return fmt.Errorf("rule[%d]: id is required", i)
@@ -125,6 +161,24 @@ func (rf RuleFile) ValidateStructure() error {
 		if strings.TrimSpace(r.Message) == "" {
 			return fmt.Errorf("rule %q: message is required (opengrep displays this when the rule fires)", r.ID)
 		}
+		if query != (r.Query != "") {
+			return fmt.Errorf("rule %q: a rule file must hold either tree-sitter query rules or opengrep rules, not both", r.ID)
+		}
+		if query {
+			if r.Pattern != "" || r.Patterns != nil || r.Mode != "" {
+				return fmt.Errorf("rule %q: query rules cannot also define opengrep patterns", r.ID)
+			}
+			if len(r.Languages) == 0 {
+				return fmt.Errorf("rule %q: query rules must list the languages their query is written for", r.ID)
+			}
+			continue
+		}
+		if r.Mode == "taint" {
+			if r.PatternSources == nil || r.PatternSinks == nil {
+				return fmt.Errorf("rule %q: taint mode requires pattern-sources and pattern-sinks", r.ID)
+			}
+			continue
+		}
 		if r.Pattern == "" && r.Patterns == nil {
 			return fmt.Errorf("rule %q: must define pattern or patterns", r.ID)
 		}
@@ -0,0 +1,287 @@
+package runner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// CheckpointFile is the checkpoint's file name under .quokka/review/.
+const CheckpointFile = "checkpoint.json"
+
+// ErrSetupChanged is returned by Dispatch when resuming from a checkpoint
+// that was written for a different setup.json. The plan, scope or agent
+// set may have changed, so reusing its results would be wrong.
+var ErrSetupChanged = errors.New("setup.json changed since the checkpoint was written")
+
+// Checkpoint records dispatch progress so an interrupted run can resume.
+// It is rewritten after every agent invocation and every phase.
+type Checkpoint struct {
+	SetupHash string            `json:"setup_hash"`
+	Profile   string            `json:"profile,omitempty"`
+	StartedAt time.Time         `json:"started_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+	Phases    []PhaseCheckpoint `json:"phases"`
+}
+
+// PhaseCheckpoint is the recorded state of one phase. Completed is set
+// once every agent has run and the post-phase triage plan was applied.
+type PhaseCheckpoint struct {
+	Name      string            `json:"name"`
+	Completed bool              `json:"completed"`
+	Skipped   bool              `json:"skipped,omitempty"`
+	Agents    []AgentCheckpoint `json:"agents,omitempty"`
+}
+
+// AgentCheckpoint is the persisted form of an AgentResult. Key is the
+// agent name, or agent@item for dynamic fan-out invocations.
+type AgentCheckpoint struct {
+	Key         string        `json:"key"`
+	Agent       string        `json:"agent"`
+	Item        string        `json:"item,omitempty"`
+	ExitCode    int           `json:"exit_code"`
+	Duration    time.Duration `json:"duration"`
+	LogPath     string        `json:"log_path,omitempty"`
+	Error       string        `json:"error,omitempty"`
+	Retries     int           `json:"retries,omitempty"`
+	RetryReason string        `json:"retry_reason,omitempty"`
+	FinishedAt  time.Time     `json:"finished_at"`
+}
+
+// Result converts the record back into an AgentResult marked as resumed.
+func (a AgentCheckpoint) Result() AgentResult {
+	res := AgentResult{
+		Agent:       a.Agent,
+		ExitCode:    a.ExitCode,
+		Duration:    a.Duration,
+		LogPath:     a.LogPath,
+		Retries:     a.Retries,
+		RetryReason: a.RetryReason,
+		Resumed:     true,
+	}
+	if a.Error != "" {
+		res.Err = errors.New(a.Error)
+	}
+	return res
+}
+
+// HashSetup returns the fingerprint of a setup.json used to tie a
+// checkpoint to the plan it was written for.
+func HashSetup(setupJSON []byte) string {
+	sum := sha256.Sum256(setupJSON)
+	return hex.EncodeToString(sum[:])
+}
+
+// LoadCheckpoint reads a checkpoint file.
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("parse checkpoint %s: %w", path, err)
+	}
+	return &cp, nil
+}
+
+// agentKey identifies one invocation within a phase.
+func agentKey(agent, item string) string {
+	if item == "" {
+		return agent
+	}
+	return agent + "@" + item
+}
+
+// checkpointState is the live checkpoint of a Dispatch call. All methods
+// are safe on a nil receiver, which means checkpointing is disabled, and
+// safe for concurrent use by parallel agents.
+type checkpointState struct {
+	mu     sync.Mutex
+	path   string
+	stdout io.Writer
+	cp     Checkpoint
+}
+
+// openCheckpoint starts a fresh checkpoint, or with cfg.Resume loads the
+// existing one. A missing checkpoint on resume starts fresh; one written
+// for a different setup.json is an error.
+func openCheckpoint(plan DispatchPlan, cfg DispatchConfig) (*checkpointState, error) {
+	if cfg.CheckpointPath == "" {
+		return nil, nil
+	}
+	state := &checkpointState{path: cfg.CheckpointPath, stdout: cfg.Stdout}
+
+	if cfg.Resume {
+		cp, err := LoadCheckpoint(cfg.CheckpointPath)
+		switch {
+		case err == nil:
+			if cp.SetupHash != cfg.SetupHash {
+				return nil, fmt.Errorf("%w (%s); re-run without --resume to start over", ErrSetupChanged, cfg.CheckpointPath)
+			}
+			state.cp = *cp
+			_, _ = fmt.Fprintf(cfg.Stdout, "Resuming from checkpoint %s (started %s)\n", cfg.CheckpointPath, cp.StartedAt.Format(time.RFC3339))
+			return state, nil
+		case errors.Is(err, os.ErrNotExist):
+			_, _ = fmt.Fprintf(cfg.Stdout, "No checkpoint at %s — starting from the first phase\n", cfg.CheckpointPath)
+		default:
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	state.cp = Checkpoint{SetupHash: cfg.SetupHash, Profile: plan.Profile, StartedAt: now, UpdatedAt: now}
+	if err := state.save(); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// completedPhase returns the recorded result of a phase that finished with
+// every agent succeeding. Phases with failed agents are not complete for
+// resume purposes: their failed agents run again.
+func (s *checkpointState) completedPhase(name string) (PhaseResult, bool) {
+	if s == nil {
+		return PhaseResult{}, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ph := s.phase(name, false)
+	if ph == nil || !ph.Completed {
+		return PhaseResult{}, false
+	}
+	result := PhaseResult{Name: name, Skipped: ph.Skipped}
+	for _, a := range ph.Agents {
+		if a.Error != "" {
+			return PhaseResult{}, false
+		}
+		result.Agents = append(result.Agents, a.Result())
+	}
+	return result, true
+}
+
+// succeeded returns the recorded result of an agent that already
+// completed successfully in this phase.
+func (s *checkpointState) succeeded(phase, key string) (AgentResult, bool) {
+	if s == nil {
+		return AgentResult{}, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ph := s.phase(phase, false)
+	if ph == nil {
+		return AgentResult{}, false
+	}
+	for _, a := range ph.Agents {
+		if a.Key == key && a.Error == "" {
+			return a.Result(), true
+		}
+	}
+	return AgentResult{}, false
+}
+
+// recordAgent stores an agent's result, replacing any earlier attempt.
+func (s *checkpointState) recordAgent(phase, item string, res AgentResult) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec := AgentCheckpoint{
+		Key:         agentKey(res.Agent, item),
+		Agent:       res.Agent,
+		Item:        item,
+		ExitCode:    res.ExitCode,
+		Duration:    res.Duration,
+		LogPath:     res.LogPath,
+		Retries:     res.Retries,
+		RetryReason: res.RetryReason,
+		FinishedAt:  time.Now(),
+	}
+	if res.Err != nil {
+		rec.Error = res.Err.Error()
+	}
+
+	ph := s.phase(phase, true)
+	ph.Completed = false
+	for i := range ph.Agents {
+		if ph.Agents[i].Key == rec.Key {
+			ph.Agents[i] = rec
+			s.saveLocked()
+			return
+		}
+	}
+	ph.Agents = append(ph.Agents, rec)
+	s.saveLocked()
+}
+
+// finishPhase marks a phase complete.
+func (s *checkpointState) finishPhase(name string, skipped bool) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ph := s.phase(name, true)
+	ph.Completed = true
+	ph.Skipped = skipped
+	if skipped {
+		ph.Agents = nil
+	}
+	s.saveLocked()
+}
+
+// phase finds a phase record, creating it when create is set. Callers
+// hold s.mu.
+func (s *checkpointState) phase(name string, create bool) *PhaseCheckpoint {
+	for i := range s.cp.Phases {
+		if s.cp.Phases[i].Name == name {
+			return &s.cp.Phases[i]
+		}
+	}
+	if !create {
+		return nil
+	}
+	s.cp.Phases = append(s.cp.Phases, PhaseCheckpoint{Name: name})
+	return &s.cp.Phases[len(s.cp.Phases)-1]
+}
+
+// saveLocked writes the checkpoint, warning instead of failing: losing a
+// checkpoint update only costs re-running work on resume.
+func (s *checkpointState) saveLocked() {
+	if err := s.save(); err != nil {
+		_, _ = fmt.Fprintf(s.stdout, "  warning: couldn't write checkpoint %s: %v\n", s.path, err)
+	}
+}
+
+// save writes the checkpoint atomically so an interrupt mid-write never
+// leaves a truncated file behind.
+func (s *checkpointState) save() error {
+	s.cp.UpdatedAt = time.Now()
+	data, err := json.MarshalIndent(s.cp, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal checkpoint: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("create checkpoint dir: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("write checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("write checkpoint: %w", err)
+	}
+	return nil
+}
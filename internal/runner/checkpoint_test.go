@@ -0,0 +1,232 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// scriptRunner stands in for opencode/claude: each agent runs a shell
+// snippet, defaulting to a clean exit. Invocations are appended to calls
+// so tests can assert which agents actually ran.
+type scriptRunner struct {
+	scripts map[string]string
+	calls   string // path of the invocation log
+}
+
+func (scriptRunner) Name() string { return "script" }
+
+func (r scriptRunner) AgentInvocation(ctx context.Context, workDir, agentName, model, userTurn string, logOut io.Writer) *exec.Cmd {
+	script := r.scripts[agentName]
+	if script == "" {
+		script = "exit 0"
+	}
+	cmd := exec.CommandContext(ctx, "sh", "-c", `echo "$0" >> "$1"; `+script, agentName, r.calls)
+	cmd.Dir = workDir
+	cmd.Stdout = logOut
+	cmd.Stderr = logOut
+	return cmd
+}
+
+func (r scriptRunner) invoked(t *testing.T) []string {
+	t.Helper()
+	data, err := os.ReadFile(r.calls)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Fields(string(data))
+}
+
+func checkpointTestConfig(t *testing.T, r scriptRunner) DispatchConfig {
+	t.Helper()
+	tmp := t.TempDir()
+	return DispatchConfig{
+		Runner:         r,
+		WorkDir:        tmp,
+		LogDir:         filepath.Join(tmp, "logs"),
+		CheckpointPath: filepath.Join(tmp, ".quokka", "review", CheckpointFile),
+		SetupHash:      HashSetup([]byte(`{"profile":"deep"}`)),
+		Stdout:         io.Discard,
+	}
+}
+
+func TestDispatchWritesCheckpoint(t *testing.T) {
+	tmp := t.TempDir()
+	r := scriptRunner{calls: filepath.Join(tmp, "calls")}
+	cfg := checkpointTestConfig(t, r)
+	plan := DispatchPlan{Profile: "deep", Phases: []DispatchPhase{
+		{Name: "recon", Mode: ModeSequential, Agents: []string{"recon-agent"}},
+		{Name: "analysis", Mode: ModeParallel, Agents: []string{"injection-agent", "authz-agent"}},
+	}}
+
+	if _, err := Dispatch(context.Background(), plan, cfg); err != nil {
+		t.Fatalf("Dispatch: %v", err)
+	}
+
+	cp, err := LoadCheckpoint(cfg.CheckpointPath)
+	if err != nil {
+		t.Fatalf("LoadCheckpoint: %v", err)
+	}
+	if cp.SetupHash != cfg.SetupHash || cp.Profile != "deep" {
+		t.Errorf("checkpoint header = %q/%q", cp.SetupHash, cp.Profile)
+	}
+	if len(cp.Phases) != 2 {
+		t.Fatalf("phases = %d, want 2", len(cp.Phases))
+	}
+	for _, ph := range cp.Phases {
+		if !ph.Completed {
+			t.Errorf("phase %s not marked completed", ph.Name)
+		}
+	}
+	if got := len(cp.Phases[1].Agents); got != 2 {
+		t.Errorf("analysis agents recorded = %d, want 2", got)
+	}
+}
+
+func TestDispatchResumeRerunsOnlyFailedAgents(t *testing.T) {
+	tmp := t.TempDir()
+	flaky := filepath.Join(tmp, "authz-ok")
+	r := scriptRunner{
+		calls: filepath.Join(tmp, "calls"),
+		// Fails until the marker file exists, standing in for a
+		// transient provider outage.
+		scripts: map[string]string{"authz-agent": `test -f "` + flaky + `"`},
+	}
+	cfg := checkpointTestConfig(t, r)
+	plan := DispatchPlan{Phases: []DispatchPhase{
+		{Name: "recon", Mode: ModeSequential, Agents: []string{"recon-agent"}},
+		{Name: "analysis", Mode: ModeSequential, Agents: []string{"injection-agent", "authz-agent"}},
+	}}
+
+	first, err := Dispatch(context.Background(), plan, cfg)
+	if err != nil {
+		t.Fatalf("first Dispatch: %v", err)
+	}
+	if first.Phases[1].Agents[1].Err == nil {
+		t.Fatal("expected authz-agent to fail on the first run")
+	}
+
+	if err := os.WriteFile(flaky, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(r.calls); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg.Resume = true
+	second, err := Dispatch(context.Background(), plan, cfg)
+	if err != nil {
+		t.Fatalf("resumed Dispatch: %v", err)
+	}
+	if got := r.invoked(t); len(got) != 1 || got[0] != "authz-agent" {
+		t.Errorf("resumed run invoked %v, want only [authz-agent]", got)
+	}
+
+	recon := second.Phases[0]
+	if len(recon.Agents) != 1 || !recon.Agents[0].Resumed {
+		t.Errorf("recon phase should come from the checkpoint: %+v", recon)
+	}
+	analysis := second.Phases[1].Agents
+	if !analysis[0].Resumed || analysis[0].Agent != "injection-agent" {
+		t.Errorf("injection-agent should be resumed: %+v", analysis[0])
+	}
+	if analysis[1].Resumed || analysis[1].Err != nil {
+		t.Errorf("authz-agent should have re-run and succeeded: %+v", analysis[1])
+	}
+
+	cp, err := LoadCheckpoint(cfg.CheckpointPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ph := range cp.Phases {
+		if !ph.Completed {
+			t.Errorf("phase %s not completed after resume", ph.Name)
+		}
+	}
+}
+
+func TestDispatchResumeRejectsChangedSetup(t *testing.T) {
+	tmp := t.TempDir()
+	r := scriptRunner{calls: filepath.Join(tmp, "calls")}
+	cfg := checkpointTestConfig(t, r)
+	plan := DispatchPlan{Phases: []DispatchPhase{
+		{Name: "recon", Mode: ModeSequential, Agents: []string{"recon-agent"}},
+	}}
+	if _, err := Dispatch(context.Background(), plan, cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg.Resume = true
+	cfg.SetupHash = HashSetup([]byte(`{"profile":"quick"}`))
+	_, err := Dispatch(context.Background(), plan, cfg)
+	if !errors.Is(err, ErrSetupChanged) {
+		t.Fatalf("err = %v, want ErrSetupChanged", err)
+	}
+}
+
+func TestDispatchResumeWithoutCheckpointStartsFresh(t *testing.T) {
+	tmp := t.TempDir()
+	r := scriptRunner{calls: filepath.Join(tmp, "calls")}
+	cfg := checkpointTestConfig(t, r)
+	cfg.Resume = true
+	plan := DispatchPlan{Phases: []DispatchPhase{
+		{Name: "recon", Mode: ModeSequential, Agents: []string{"recon-agent"}},
+	}}
+
+	if _, err := Dispatch(context.Background(), plan, cfg); err != nil {
+		t.Fatalf("Dispatch: %v", err)
+	}
+	if got := r.invoked(t); len(got) != 1 {
+		t.Errorf("invoked %v, want recon-agent once", got)
+	}
+}
+
+func TestDispatchStopsBetweenPhasesWhenCancelled(t *testing.T) {
+	tmp := t.TempDir()
+	r := scriptRunner{calls: filepath.Join(tmp, "calls")}
+	cfg := checkpointTestConfig(t, r)
+	plan := DispatchPlan{Phases: []DispatchPhase{
+		{Name: "recon", Mode: ModeSequential, Agents: []string{"recon-agent"}},
+	}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := Dispatch(ctx, plan, cfg)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if got := r.invoked(t); len(got) != 0 {
+		t.Errorf("no agent should run after cancellation, got %v", got)
+	}
+}
+
+func TestAgentCheckpointResult(t *testing.T) {
+	rec := AgentCheckpoint{
+		Key:      "poc-agent@f-1",
+		Agent:    "poc-agent",
+		Item:     "f-1",
+		ExitCode: 1,
+		Duration: 3 * time.Second,
+		Error:    "exit status 1",
+		Retries:  1,
+	}
+	res := rec.Result()
+	if !res.Resumed || res.Agent != "poc-agent" || res.Retries != 1 || res.Duration != 3*time.Second {
+		t.Errorf("Result() = %+v", res)
+	}
+	if res.Err == nil || res.Err.Error() != "exit status 1" {
+		t.Errorf("Result().Err = %v", res.Err)
+	}
+	if agentKey("poc-agent", "f-1") != rec.Key || agentKey("recon-agent", "") != "recon-agent" {
+		t.Error("agentKey mismatch")
+	}
+}
@@ -121,9 +121,33 @@ type DispatchConfig struct {
 	// own (degraded recall on weaker models).
 	ChangedFiles []string
 
+	// ChangedLines maps a changed file to its changed line ranges
+	// ("12-18, 40"), from the diff hunks. When set, the in-scope list
+	// tells agents which lines changed so they focus there rather than
+	// on pre-existing code the report step would filter out.
+	ChangedLines map[string]string
+
 	// Stdout is where progress lines ("=== phase N: analysis === ...")
 	// are written. Defaults to os.Stdout if nil.
 	Stdout io.Writer
+
+	// CheckpointPath is where progress is recorded after every agent and
+	// phase — typically .quokka/review/checkpoint.json. Empty disables
+	// checkpointing.
+	CheckpointPath string
+
+	// SetupHash identifies the setup.json the plan came from (see
+	// HashSetup). A resumed run refuses a checkpoint written for a
+	// different one.
+	SetupHash string
+
+	// Resume reuses the results the checkpoint records: phases whose
+	// agents all succeeded are skipped, and within other phases only
+	// failed or missing agents run again.
+	Resume bool
+
+	// checkpoint is the live checkpoint, set by Dispatch.
+	checkpoint *checkpointState
 }
 
 // AgentResult is the outcome of a single agent invocation.
@@ -146,6 +170,10 @@ type AgentResult struct {
 	// retry occurred. Useful for the run summary / manifest so we can
 	// see which agents needed scaffolding and which didn't.
 	RetryReason string
+
+	// Resumed is true when the result was read from the checkpoint
+	// instead of running the agent again.
+	Resumed bool
 }
 
 // PhaseResult is the outcome of one phase.
@@ -181,7 +209,12 @@ type DispatchResult struct {
 // individual AgentResult.Err is populated and the dispatcher continues.
 // The caller decides whether to abort the run based on the aggregate
 // result. Phase-level errors (e.g. gate command itself failed) are
-// returned from Dispatch immediately.
+// returned from Dispatch immediately, as is cancellation of ctx between
+// phases.
+//
+// With CheckpointPath set, every agent result and finished phase is
+// persisted so that a run cut short by a timeout, a hard failure or
+// Ctrl-C can be picked up again with Resume.
 func Dispatch(ctx context.Context, plan DispatchPlan, cfg DispatchConfig) (DispatchResult, error) {
 	if cfg.Stdout == nil {
 		cfg.Stdout = os.Stdout
@@ -191,11 +224,25 @@ func Dispatch(ctx context.Context, plan DispatchPlan, cfg DispatchConfig) (Dispa
 			return DispatchResult{}, fmt.Errorf("create log dir: %w", err)
 		}
 	}
+	checkpoint, err := openCheckpoint(plan, cfg)
+	if err != nil {
+		return DispatchResult{}, err
+	}
+	cfg.checkpoint = checkpoint
 
 	var out DispatchResult
 	for i, phase := range plan.Phases {
+		if err := ctx.Err(); err != nil {
+			return out, fmt.Errorf("interrupted before phase %q: %w", phase.Name, err)
+		}
 		_, _ = fmt.Fprintf(cfg.Stdout, "=== Phase %d/%d: %s (%s) ===\n", i+1, len(plan.Phases), phase.Name, phase.Mode)
 
+		if done, ok := checkpoint.completedPhase(phase.Name); ok {
+			_, _ = fmt.Fprintf(cfg.Stdout, "  completed in checkpoint — skipping phase\n")
+			out.Phases = append(out.Phases, done)
+			continue
+		}
+
 		switch phase.Mode {
 		case ModeGated:
 			ok, err := evaluateGate(ctx, phase.Gate, cfg.WorkDir)
@@ -205,17 +252,18 @@ func Dispatch(ctx context.Context, plan DispatchPlan, cfg DispatchConfig) (Dispa
 			if !ok {
 				_, _ = fmt.Fprintf(cfg.Stdout, "  gate produced no results — skipping phase\n")
 				out.Phases = append(out.Phases, PhaseResult{Name: phase.Name, Skipped: true})
+				checkpoint.finishPhase(phase.Name, true)
 				continue
 			}
-			results := runSequential(ctx, phase.Agents, cfg)
+			results := runSequential(ctx, phase.Name, phase.Agents, cfg)
 			out.Phases = append(out.Phases, PhaseResult{Name: phase.Name, Agents: results})
 
 		case ModeSequential:
-			results := runSequential(ctx, phase.Agents, cfg)
+			results := runSequential(ctx, phase.Name, phase.Agents, cfg)
 			out.Phases = append(out.Phases, PhaseResult{Name: phase.Name, Agents: results})
 
 		case ModeParallel:
-			results := runParallel(ctx, phase.Agents, cfg)
+			results := runParallel(ctx, phase.Name, phase.Agents, cfg)
 			out.Phases = append(out.Phases, PhaseResult{Name: phase.Name, Agents: results})
 
 		case ModeDynamicFanout:
@@ -226,9 +274,10 @@ func Dispatch(ctx context.Context, plan DispatchPlan, cfg DispatchConfig) (Dispa
 			if len(ids) == 0 {
 				_, _ = fmt.Fprintf(cfg.Stdout, "  dynamic-source produced no items — skipping phase\n")
 				out.Phases = append(out.Phases, PhaseResult{Name: phase.Name, Skipped: true})
+				checkpoint.finishPhase(phase.Name, true)
 				continue
 			}
-			results := runFanout(ctx, phase.DynamicAgent, ids, cfg)
+			results := runFanout(ctx, phase.Name, phase.DynamicAgent, ids, cfg)
 			out.Phases = append(out.Phases, PhaseResult{Name: phase.Name, Agents: results})
 
 		default:
@@ -244,6 +293,12 @@ func Dispatch(ctx context.Context, plan DispatchPlan, cfg DispatchConfig) (Dispa
 		if triageAuthor := triageAuthorForPhase(phase.Name); triageAuthor != "" {
 			applyTriagePlan(ctx, cfg, triageAuthor)
 		}
+
+		// An interrupt kills the in-flight agents, which then report
+		// failures; leave the phase open so resume re-runs them.
+		if ctx.Err() == nil {
+			checkpoint.finishPhase(phase.Name, false)
+		}
 	}
 	return out, nil
 }
@@ -278,7 +333,7 @@ func applyTriagePlan(ctx context.Context, cfg DispatchConfig, author string) {
 	}
 	_, _ = fmt.Fprintf(cfg.Stdout, "  applying triage plan from %s (author=%s)\n", planPath, author)
 
-	args := []string{"finding", "triage", "--plan", planPath, "--author", author}
+	args := []string{"finding", "triage", "--plan", planPath, "--author", author, "--source", "dispatcher"}
 	cmd := exec.CommandContext(ctx, "quokka", args...)
 	cmd.Dir = cfg.WorkDir
 	cmd.Env = os.Environ()
@@ -381,10 +436,10 @@ func jsonHasFindings(out []byte) bool {
 }
 
 // runSequential dispatches agents one at a time, in declared order.
-func runSequential(ctx context.Context, agents []string, cfg DispatchConfig) []AgentResult {
+func runSequential(ctx context.Context, phase string, agents []string, cfg DispatchConfig) []AgentResult {
 	results := make([]AgentResult, 0, len(agents))
 	for _, name := range agents {
-		results = append(results, runAgentWithRetry(ctx, name, defaultUserTurn(cfg.UserTurn, cfg.ChangedFiles), cfg))
+		results = append(results, runCheckpointed(ctx, phase, name, "", defaultUserTurn(cfg.UserTurn, cfg.ChangedFiles, cfg.ChangedLines), cfg))
 	}
 	return results
 }
@@ -392,7 +447,7 @@ func runSequential(ctx context.Context, agents []string, cfg DispatchConfig) []A
 // runParallel dispatches agents concurrently, optionally capped by
 // MaxParallel. Returns results in the same order as the input agent list
 // for log readability.
-func runParallel(ctx context.Context, agents []string, cfg DispatchConfig) []AgentResult {
+func runParallel(ctx context.Context, phase string, agents []string, cfg DispatchConfig) []AgentResult {
 	if len(agents) == 0 {
 		return nil
 	}
@@ -412,7 +467,7 @@ func runParallel(ctx context.Context, agents []string, cfg DispatchConfig) []Age
 				sem <- struct{}{}
 				defer func() { <-sem }()
 			}
-			results[i] = runAgentWithRetry(ctx, name, defaultUserTurn(cfg.UserTurn, cfg.ChangedFiles), cfg)
+			results[i] = runCheckpointed(ctx, phase, name, "", defaultUserTurn(cfg.UserTurn, cfg.ChangedFiles, cfg.ChangedLines), cfg)
 		}()
 	}
 	wg.Wait()
@@ -423,15 +478,33 @@ func runParallel(ctx context.Context, agents []string, cfg DispatchConfig) []Age
 // the user-turn prompt. Items are processed sequentially to keep cost
 // bounded — fan-out is typically small (handful of critical findings) but
 // per-item cost can be high (full LLM review).
-func runFanout(ctx context.Context, agentName string, ids []string, cfg DispatchConfig) []AgentResult {
+func runFanout(ctx context.Context, phase, agentName string, ids []string, cfg DispatchConfig) []AgentResult {
 	results := make([]AgentResult, 0, len(ids))
 	for _, id := range ids {
-		turn := fmt.Sprintf("%s Specifically: review finding %s.", defaultUserTurn(cfg.UserTurn, cfg.ChangedFiles), id)
-		results = append(results, runAgentWithRetry(ctx, agentName, turn, cfg))
+		turn := fmt.Sprintf("%s Specifically: review finding %s.", defaultUserTurn(cfg.UserTurn, cfg.ChangedFiles, cfg.ChangedLines), id)
+		results = append(results, runCheckpointed(ctx, phase, agentName, id, turn, cfg))
 	}
 	return results
 }
 
+// runCheckpointed runs one agent invocation unless the checkpoint already
+// holds a successful result for it, and records the outcome. item is the
+// fan-out item id, empty for plain phases.
+func runCheckpointed(ctx context.Context, phase, agentName, item, userTurn string, cfg DispatchConfig) AgentResult {
+	if res, ok := cfg.checkpoint.succeeded(phase, agentKey(agentName, item)); ok {
+		_, _ = fmt.Fprintf(cfg.Stdout, "  ✓ %s completed in checkpoint — skipping\n", agentKey(agentName, item))
+		return res
+	}
+	// Don't start new agents after an interrupt; the missing results make
+	// resume pick them up.
+	if err := ctx.Err(); err != nil {
+		return AgentResult{Agent: agentName, ExitCode: -1, Err: err}
+	}
+	res := runAgentWithRetry(ctx, agentName, userTurn, cfg)
+	cfg.checkpoint.recordAgent(phase, item, res)
+	return res
+}
+
 // runAgentWithRetry wraps runAgent with at most one corrective re-prompt
 // for failures classified as recoverable. Hard failures (quota / auth /
 // network) are surfaced as-is. Successes and "agent ran clean, just
@@ -562,7 +635,10 @@ This is your final retry. Original task: %s`, reason, creatorHint, originalTurn)
 // to work through. Without this, an OWASP-eval-scale fixture (70 files)
 // can produce widely different recall run-to-run depending on whether
 // the model decides to enumerate the tree itself.
-func defaultUserTurn(userTurn string, changedFiles []string) string {
+//
+// changedLines, when present, annotates each file with the lines the diff
+// touched.
+func defaultUserTurn(userTurn string, changedFiles []string, changedLines map[string]string) string {
 	if userTurn != "" {
 		return userTurn
 	}
@@ -575,11 +651,21 @@ func defaultUserTurn(userTurn string, changedFiles []string) string {
 		for _, f := range changedFiles {
 			b.WriteString("- ")
 			b.WriteString(f)
+			if lines := changedLines[f]; lines != "" {
+				b.WriteString(" (changed lines: ")
+				b.WriteString(lines)
+				b.WriteString(")")
+			}
 			b.WriteString("\n")
 		}
 		b.WriteString("\nDo NOT skip files because they look similar — each file is a distinct test case ")
 		b.WriteString("and may contain different vulnerability patterns. Out-of-scope findings are filtered out by ")
 		b.WriteString("the report step, so spending tokens on them is waste; in-scope findings missed cost recall directly.")
+		if len(changedLines) > 0 {
+			b.WriteString("\n\nWhere changed lines are listed, focus on them and the functions that contain them. ")
+			b.WriteString("Read the rest of the file for context (callers, sanitizers, data flow), but issues that are ")
+			b.WriteString("entirely in unchanged functions are pre-existing and are dropped from the PR report.")
+		}
 	}
 	return b.String()
 }
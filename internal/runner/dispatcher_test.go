@@ -365,3 +365,24 @@ func TestRunnerInvocationShape(t *testing.T) {
 		})
 	}
 }
+
+func TestDefaultUserTurnChangedLines(t *testing.T) {
+	turn := defaultUserTurn("", []string{"app/db.go", "README.md"}, map[string]string{"app/db.go": "13-14, 42"})
+	if !strings.Contains(turn, "- app/db.go (changed lines: 13-14, 42)\n") {
+		t.Errorf("expected changed lines on app/db.go, got:\n%s", turn)
+	}
+	if !strings.Contains(turn, "- README.md\n") {
+		t.Errorf("expected README.md without ranges, got:\n%s", turn)
+	}
+	if !strings.Contains(turn, "pre-existing") {
+		t.Errorf("expected focus guidance when changed lines are set, got:\n%s", turn)
+	}
+
+	plain := defaultUserTurn("", []string{"app/db.go"}, nil)
+	if strings.Contains(plain, "changed lines") {
+		t.Errorf("no changed lines expected without hunks, got:\n%s", plain)
+	}
+	if got := defaultUserTurn("custom", []string{"app/db.go"}, map[string]string{"app/db.go": "1"}); got != "custom" {
+		t.Errorf("explicit user turn should win, got %q", got)
+	}
+}
@@ -0,0 +1,84 @@
+package sast
+
+import (
+	"strings"
+
+	"github.com/diffsec/quokka/internal/finding"
+	"github.com/diffsec/quokka/internal/rule"
+)
+
+// RuleIDFromTags returns the opengrep or tree-sitter rule id recorded on a
+// finding's tags, or "" if the finding carries none.
+func RuleIDFromTags(tags []string) string {
+	for _, tag := range tags {
+		for _, prefix := range []string{OpengrepRuleTagPrefix, TreesitterRuleTagPrefix} {
+			if id, ok := strings.CutPrefix(tag, prefix); ok {
+				return id
+			}
+		}
+	}
+	return ""
+}
+
+// RecordFirings appends a trigger event for every finding produced by a
+// project-local rule. Findings from bundled rule packs are ignored. Call it
+// once per scan with the findings that were stored, so a rule that keeps
+// firing on the same code shows up in every run's history.
+func RecordFirings(rs *rule.Store, findings []finding.Finding) error {
+	idToSlug, err := rs.RuleIDToSlug()
+	if err != nil || len(idToSlug) == 0 {
+		return err
+	}
+	var events []rule.Event
+	for _, f := range findings {
+		id := RuleIDFromTags(f.Tags)
+		slug := rule.SlugForRuleID(idToSlug, id)
+		if slug == "" {
+			continue
+		}
+		events = append(events, rule.Event{Slug: slug, Kind: rule.EventTrigger, RuleID: id, FindingID: f.ID})
+	}
+	return rs.Record(events...)
+}
+
+// TriageRecorder returns a finding.StatusHook that records false_positive,
+// confirmed and reopened events against the project-local rule that
+// produced the finding. Failures are reported to onError (which may be
+// nil) rather than failing the triage that triggered them.
+func TriageRecorder(rs *rule.Store, onError func(error)) finding.StatusHook {
+	return func(before, after *finding.Finding, by finding.Actor) {
+		kind, ok := triageEvent(before.Status, after.Status)
+		if !ok {
+			return
+		}
+		id := RuleIDFromTags(after.Tags)
+		if id == "" {
+			return
+		}
+		idToSlug, err := rs.RuleIDToSlug()
+		if err == nil {
+			if slug := rule.SlugForRuleID(idToSlug, id); slug != "" {
+				err = rs.Record(rule.Event{Slug: slug, Kind: kind, RuleID: id, FindingID: after.ID})
+			}
+		}
+		if err != nil && onError != nil {
+			onError(err)
+		}
+	}
+}
+
+// triageEvent maps a status transition to the rule event it implies. Moving
+// into false_positive or confirmed records that verdict; moving from either
+// back to open withdraws it. Other moves (e.g. confirmed to fixed) keep the
+// verdict.
+func triageEvent(from, to finding.Status) (rule.EventKind, bool) {
+	switch {
+	case to == finding.StatusFalsePositive:
+		return rule.EventFalsePositive, true
+	case to == finding.StatusConfirmed:
+		return rule.EventConfirmed, true
+	case to == finding.StatusOpen && (from == finding.StatusFalsePositive || from == finding.StatusConfirmed):
+		return rule.EventReopened, true
+	}
+	return "", false
+}
@@ -0,0 +1,58 @@
+package sast
+
+import (
+	"testing"
+	"time"
+
+	"github.com/diffsec/quokka/internal/finding"
+	"github.com/diffsec/quokka/internal/project"
+	"github.com/diffsec/quokka/internal/rule"
+)
+
+func TestRuleActivityFromScanAndTriage(t *testing.T) {
+	p, err := project.Initialize(t.TempDir())
+	if err != nil {
+		t.Fatalf("project init: %v", err)
+	}
+	rs := rule.NewStore(p)
+	content := "rules:\n  - id: quokka-md5\n    message: md5\n    languages: [go]\n    pattern: md5.New()\n"
+	if err := rs.Add("md5", []byte(content), rule.Meta{CreatedBy: "human:test", CreatedAt: time.Now()}); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	local := finding.Finding{ID: "FIND-001", Tags: []string{"sast", OpengrepRuleTagPrefix + "tmp.quokka.quokka-md5"}}
+	bundled := finding.Finding{ID: "FIND-002", Tags: []string{OpengrepRuleTagPrefix + "go.lang.security.audit.md5"}}
+	if err := RecordFirings(rs, []finding.Finding{local, bundled}); err != nil {
+		t.Fatalf("RecordFirings: %v", err)
+	}
+
+	var hookErr error
+	hook := TriageRecorder(rs, func(err error) { hookErr = err })
+	open, fp := local, local
+	open.Status, fp.Status = finding.StatusOpen, finding.StatusFalsePositive
+	hook(&open, &fp, finding.Actor{})
+	fixed := fp
+	fixed.Status = finding.StatusFixed
+	hook(&fp, &fixed, finding.Actor{}) // not a verdict change
+	if hookErr != nil {
+		t.Fatalf("hook error: %v", hookErr)
+	}
+
+	m, err := rs.ReadMeta("md5")
+	if err != nil {
+		t.Fatalf("ReadMeta: %v", err)
+	}
+	if m.TriggerCount != 1 || m.FPCount != 1 || m.LastTriggerAt.IsZero() {
+		t.Errorf("meta = triggers %d, fp %d, last %v", m.TriggerCount, m.FPCount, m.LastTriggerAt)
+	}
+	events, _ := rs.Events("")
+	if len(events) != 2 {
+		t.Errorf("events = %+v, want a trigger and a false_positive", events)
+	}
+
+	// Reopening withdraws the verdict
+	hook(&fp, &open, finding.Actor{})
+	if m, _ := rs.ReadMeta("md5"); m.FPCount != 0 {
+		t.Errorf("fp count after reopen = %d, want 0", m.FPCount)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/diffsec/quokka/internal/finding"
@@ -147,6 +148,12 @@ func (s *Scanner) buildArgs(sarifOutPath string, targets []string) []string {
 // ParseSARIF converts an opengrep SARIF blob into quokka findings. Exposed so
 // callers can drive opengrep separately (or feed in canned SARIF for tests).
 func ParseSARIF(data []byte) ([]finding.Finding, error) {
+	return parseSARIF(data, "opengrep")
+}
+
+// parseSARIF converts SARIF from any tool. tool attributes the findings
+// (created_by and tags); empty means "use each run's driver name".
+func parseSARIF(data []byte, tool string) ([]finding.Finding, error) {
 	var log sarifLog
 	if err := json.Unmarshal(data, &log); err != nil {
 		return nil, fmt.Errorf("sast: parse SARIF: %w", err)
@@ -158,8 +165,12 @@ func ParseSARIF(data []byte) ([]finding.Finding, error) {
 		for _, r := range run.Tool.Driver.Rules {
 			rulesByID[r.ID] = r
 		}
+		runTool := tool
+		if runTool == "" {
+			runTool = driverTool(run.Tool.Driver.Name)
+		}
 		for _, result := range run.Results {
-			f := convertResult(result, rulesByID)
+			f := convertResult(result, rulesByID, runTool)
 			if f.Title == "" {
 				continue
 			}
@@ -169,7 +180,7 @@ func ParseSARIF(data []byte) ([]finding.Finding, error) {
 	return out, nil
 }
 
-func convertResult(r sarifResult, rules map[string]sarifRule) finding.Finding {
+func convertResult(r sarifResult, rules map[string]sarifRule, tool string) finding.Finding {
 	rule := rules[r.RuleID]
 
 	// Prefer the result message — it's the rule author's actual guidance.
@@ -190,6 +201,11 @@ func convertResult(r sarifResult, rules map[string]sarifRule) finding.Finding {
 			severity = mapSeverity(rule.DefaultConfiguration.Level)
 		}
 	}
+	// CodeQL and GitHub-targeted tools put a CVSS-like score in
+	// properties["security-severity"]; it's finer-grained than the level.
+	if sev, ok := securitySeverity(rule.Properties); ok {
+		severity = sev
+	}
 
 	var loc finding.Location
 	if len(r.Locations) > 0 {
@@ -218,12 +234,12 @@ func convertResult(r sarifResult, rules map[string]sarifRule) finding.Finding {
 		}
 	}
 
-	// Tag the finding with the opengrep rule id that produced it. This is
-	// what cmd/rule.go's audit path uses to attribute triggers and FPs
+	// Tag the finding with the rule id that produced it. For opengrep this
+	// is what cmd/rule.go's audit path uses to attribute triggers and FPs
 	// back to project-local rules by ID lookup.
-	tags := []string{"sast", "opengrep"}
+	tags := []string{"sast", tool}
 	if id := strings.TrimSpace(r.RuleID); id != "" {
-		tags = append(tags, "opengrep-rule:"+id)
+		tags = append(tags, tool+"-rule:"+id)
 	}
 
 	return finding.Finding{
@@ -236,7 +252,7 @@ func convertResult(r sarifResult, rules map[string]sarifRule) finding.Finding {
 		Description: description,
 		Remediation: strings.TrimSpace(rule.Help.Text),
 		Tags:        tags,
-		CreatedBy:   "opengrep",
+		CreatedBy:   tool,
 	}
 }
 
@@ -274,10 +290,15 @@ func firstSentence(s string) string {
 }
 
 // extractCWE accepts opengrep-shaped tags and returns the canonical
-// "CWE-<digits>" form. Returns "" when the tag isn't CWE-shaped.
+// "CWE-<digits>" form. CodeQL's "external/cwe/cwe-079" form is accepted
+// too, with the zero padding dropped. Returns "" when the tag isn't
+// CWE-shaped.
 func extractCWE(tag string) string {
 	tag = strings.TrimSpace(tag)
 	upper := strings.ToUpper(tag)
+	if rest, ok := strings.CutPrefix(upper, "EXTERNAL/CWE/CWE-"); ok {
+		upper = "CWE-" + strings.TrimLeft(rest, "0")
+	}
 	if !strings.HasPrefix(upper, "CWE-") {
 		return ""
 	}
@@ -362,6 +383,35 @@ func mapSeverity(level string) finding.Severity {
 	}
 }
 
+// securitySeverity maps a numeric properties["security-severity"] (a
+// string or number, 0.0-10.0) onto quokka severities using the CVSS bands.
+func securitySeverity(props map[string]any) (finding.Severity, bool) {
+	var score float64
+	switch v := props["security-severity"].(type) {
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return "", false
+		}
+		score = f
+	case float64:
+		score = v
+	default:
+		return "", false
+	}
+	switch {
+	case score >= 9:
+		return finding.SeverityCritical, true
+	case score >= 7:
+		return finding.SeverityHigh, true
+	case score >= 4:
+		return finding.SeverityMedium, true
+	case score > 0:
+		return finding.SeverityLow, true
+	}
+	return finding.SeverityInfo, true
+}
+
 func tagsOf(props map[string]any) []string {
 	raw, ok := props["tags"]
 	if !ok {
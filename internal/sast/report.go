@@ -0,0 +1,330 @@
+package sast
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/diffsec/quokka/internal/finding"
+)
+
+// Report formats accepted by ParseReport.
+const (
+	FormatSARIF       = "sarif"
+	FormatSemgrepJSON = "semgrep-json"
+	FormatBanditJSON  = "bandit-json"
+	FormatGosecJSON   = "gosec-json"
+)
+
+// ReportFormats lists the formats ParseReport understands.
+var ReportFormats = []string{FormatSARIF, FormatSemgrepJSON, FormatBanditJSON, FormatGosecJSON}
+
+// ReportOptions controls how a third-party report becomes findings.
+type ReportOptions struct {
+	// Tool attributes the findings: it becomes created_by and the tool tag.
+	// Empty means the format's own tool — the SARIF driver name (e.g.
+	// "codeql"), or semgrep / bandit / gosec.
+	Tool string
+
+	// ProjectRoot relativizes the file paths in the report, as
+	// Scanner.ProjectRoot does for opengrep.
+	ProjectRoot string
+}
+
+// ParseReport converts a SAST report produced outside quokka into
+// findings, so results from tools already in CI go through the same
+// triage, fingerprint dedup and export pipeline as `quokka sast`.
+// Findings are not persisted.
+func ParseReport(format string, data []byte, opts ReportOptions) ([]finding.Finding, error) {
+	tool := toolName(opts.Tool, "")
+	var (
+		findings []finding.Finding
+		err      error
+	)
+	switch strings.ToLower(format) {
+	case FormatSARIF:
+		findings, err = parseSARIF(data, tool)
+	case FormatSemgrepJSON:
+		findings, err = parseSemgrepJSON(data, orTool(tool, "semgrep"))
+	case FormatBanditJSON:
+		findings, err = parseBanditJSON(data, orTool(tool, "bandit"))
+	case FormatGosecJSON:
+		findings, err = parseGosecJSON(data, orTool(tool, "gosec"))
+	default:
+		return nil, fmt.Errorf("sast: unsupported report format %q (supported: %s)", format, strings.Join(ReportFormats, ", "))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if opts.ProjectRoot != "" {
+		for i := range findings {
+			findings[i].Location.File = relativizePath(findings[i].Location.File, opts.ProjectRoot)
+		}
+	}
+	return findings, nil
+}
+
+// toolName normalises a tool or SARIF driver name into a created_by value:
+// lowercase, spaces as dashes ("CodeQL" -> "codeql").
+func toolName(name, fallback string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = strings.Join(strings.Fields(name), "-")
+	if name == "" {
+		return fallback
+	}
+	return name
+}
+
+// knownDrivers are tools whose SARIF driver name carries an edition or
+// product suffix ("Opengrep OSS", "Semgrep Pro", "CodeQL command-line
+// toolchain"). Their findings are attributed to the bare tool name, so an
+// imported opengrep report is tagged opengrep-rule: like `quokka sast`
+// results and counts towards the same rules.
+var knownDrivers = []string{"bandit", "codeql", "gosec", "opengrep", "semgrep", "snyk"}
+
+// driverTool returns the created_by value for a SARIF driver name: the
+// known tool it names, otherwise the normalised name itself.
+func driverTool(name string) string {
+	if fields := strings.Fields(strings.ToLower(name)); len(fields) > 0 && slices.Contains(knownDrivers, fields[0]) {
+		return fields[0]
+	}
+	return toolName(name, "sarif")
+}
+
+func orTool(tool, fallback string) string {
+	if tool == "" {
+		return fallback
+	}
+	return tool
+}
+
+// newReportFinding fills in the fields shared by every importer.
+func newReportFinding(tool, ruleID, title string, sev finding.Severity, conf finding.Confidence, cwe string, loc finding.Location) finding.Finding {
+	tags := []string{"sast", tool}
+	if ruleID != "" {
+		tags = append(tags, tool+"-rule:"+ruleID)
+	}
+	if loc.File != "" {
+		loc.File = filepath.Clean(loc.File)
+	}
+	if loc.LineStart == 0 {
+		loc.LineStart = 1
+	}
+	return finding.Finding{
+		Title:      title,
+		Severity:   sev,
+		Confidence: conf,
+		Status:     finding.StatusOpen,
+		CWE:        cwe,
+		Location:   loc,
+		Tags:       tags,
+		CreatedBy:  tool,
+	}
+}
+
+// mapLabel maps the HIGH/MEDIUM/LOW style labels bandit, gosec and semgrep
+// use for severity.
+func mapLabel(label string) finding.Severity {
+	switch strings.ToUpper(strings.TrimSpace(label)) {
+	case "CRITICAL":
+		return finding.SeverityCritical
+	case "HIGH", "ERROR":
+		return finding.SeverityHigh
+	case "MEDIUM", "WARNING":
+		return finding.SeverityMedium
+	case "LOW", "INFO":
+		return finding.SeverityLow
+	}
+	return finding.SeverityMedium
+}
+
+func mapConfidence(label string) finding.Confidence {
+	switch strings.ToUpper(strings.TrimSpace(label)) {
+	case "HIGH":
+		return finding.ConfidenceHigh
+	case "LOW":
+		return finding.ConfidenceLow
+	}
+	return finding.ConfidenceMedium
+}
+
+// --- semgrep --json ---
+
+type semgrepReport struct {
+	Results []struct {
+		CheckID string `json:"check_id"`
+		Path    string `json:"path"`
+		Start   struct {
+			Line int `json:"line"`
+		} `json:"start"`
+		End struct {
+			Line int `json:"line"`
+		} `json:"end"`
+		Extra struct {
+			Message  string         `json:"message"`
+			Severity string         `json:"severity"`
+			Lines    string         `json:"lines"`
+			Fix      string         `json:"fix"`
+			Metadata map[string]any `json:"metadata"`
+		} `json:"extra"`
+	} `json:"results"`
+}
+
+func parseSemgrepJSON(data []byte, tool string) ([]finding.Finding, error) {
+	var report semgrepReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("sast: parse semgrep JSON: %w", err)
+	}
+	var out []finding.Finding
+	for _, r := range report.Results {
+		message := strings.TrimSpace(r.Extra.Message)
+		parts := strings.Split(r.CheckID, ".")
+		title := firstSentence(message)
+		if title == "" {
+			title = parts[len(parts)-1]
+		}
+
+		cwe := ""
+		for _, c := range stringsOf(r.Extra.Metadata["cwe"]) {
+			if cwe = extractCWE(c); cwe != "" {
+				break
+			}
+		}
+		conf, _ := r.Extra.Metadata["confidence"].(string)
+		lines := r.Extra.Lines
+		if lines == "requires login" {
+			lines = "" // semgrep redacts snippets for logged-out registry rules
+		}
+
+		f := newReportFinding(tool, r.CheckID, title, mapLabel(r.Extra.Severity), mapConfidence(conf), cwe, finding.Location{
+			File:      r.Path,
+			LineStart: r.Start.Line,
+			LineEnd:   r.End.Line,
+			Snippet:   lines,
+		})
+		f.Description = message
+		if r.Extra.Fix != "" {
+			f.Remediation = "Suggested fix: " + r.Extra.Fix
+		}
+		f.References = stringsOf(r.Extra.Metadata["references"])
+		out = append(out, f)
+	}
+	return out, nil
+}
+
+// stringsOf accepts a JSON string or array of strings.
+func stringsOf(v any) []string {
+	switch t := v.(type) {
+	case string:
+		return []string{t}
+	case []any:
+		var out []string
+		for _, e := range t {
+			if s, ok := e.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// --- bandit -f json ---
+
+type banditReport struct {
+	Results []struct {
+		Code            string `json:"code"`
+		Filename        string `json:"filename"`
+		IssueConfidence string `json:"issue_confidence"`
+		IssueSeverity   string `json:"issue_severity"`
+		IssueText       string `json:"issue_text"`
+		IssueCWE        struct {
+			ID int `json:"id"`
+		} `json:"issue_cwe"`
+		LineNumber int    `json:"line_number"`
+		LineRange  []int  `json:"line_range"`
+		MoreInfo   string `json:"more_info"`
+		TestID     string `json:"test_id"`
+		TestName   string `json:"test_name"`
+	} `json:"results"`
+}
+
+func parseBanditJSON(data []byte, tool string) ([]finding.Finding, error) {
+	var report banditReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("sast: parse bandit JSON: %w", err)
+	}
+	var out []finding.Finding
+	for _, r := range report.Results {
+		title := firstSentence(r.IssueText)
+		if title == "" {
+			title = r.TestName
+		}
+		cwe := ""
+		if r.IssueCWE.ID > 0 {
+			cwe = fmt.Sprintf("CWE-%d", r.IssueCWE.ID)
+		}
+		loc := finding.Location{File: r.Filename, LineStart: r.LineNumber, Snippet: strings.TrimRight(r.Code, "\n")}
+		if n := len(r.LineRange); n > 1 {
+			loc.LineEnd = r.LineRange[n-1]
+		}
+		f := newReportFinding(tool, r.TestID, title, mapLabel(r.IssueSeverity), mapConfidence(r.IssueConfidence), cwe, loc)
+		f.Description = strings.TrimSpace(r.IssueText)
+		if r.MoreInfo != "" {
+			f.References = []string{r.MoreInfo}
+		}
+		out = append(out, f)
+	}
+	return out, nil
+}
+
+// --- gosec -fmt=json ---
+
+type gosecReport struct {
+	Issues []struct {
+		Severity   string `json:"severity"`
+		Confidence string `json:"confidence"`
+		CWE        struct {
+			ID  string `json:"id"`
+			URL string `json:"url"`
+		} `json:"cwe"`
+		RuleID  string `json:"rule_id"`
+		Details string `json:"details"`
+		File    string `json:"file"`
+		Code    string `json:"code"`
+		Line    string `json:"line"`
+	} `json:"Issues"`
+}
+
+func parseGosecJSON(data []byte, tool string) ([]finding.Finding, error) {
+	var report gosecReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("sast: parse gosec JSON: %w", err)
+	}
+	var out []finding.Finding
+	for _, r := range report.Issues {
+		title := firstSentence(r.Details)
+		if title == "" {
+			title = r.RuleID
+		}
+		cwe := ""
+		if r.CWE.ID != "" {
+			cwe = extractCWE("CWE-" + strings.TrimPrefix(strings.ToUpper(r.CWE.ID), "CWE-"))
+		}
+		// gosec reports a single line as "42" and a span as "42-44".
+		loc := finding.Location{File: r.File, Snippet: strings.TrimRight(r.Code, "\n")}
+		start, end, _ := strings.Cut(r.Line, "-")
+		loc.LineStart, _ = strconv.Atoi(strings.TrimSpace(start))
+		loc.LineEnd, _ = strconv.Atoi(strings.TrimSpace(end))
+		f := newReportFinding(tool, r.RuleID, title, mapLabel(r.Severity), mapConfidence(r.Confidence), cwe, loc)
+		f.Description = strings.TrimSpace(r.Details)
+		if r.CWE.URL != "" {
+			f.References = []string{r.CWE.URL}
+		}
+		out = append(out, f)
+	}
+	return out, nil
+}
@@ -0,0 +1,178 @@
+package sast
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/diffsec/quokka/internal/finding"
+)
+
+const sampleCodeQLSARIF = `{
+  "version": "2.1.0",
+  "runs": [{
+    "tool": {"driver": {"name": "CodeQL", "rules": [{
+      "id": "go/sql-injection",
+      "shortDescription": {"text": "Database query built from user-controlled sources"},
+      "defaultConfiguration": {"level": "error"},
+      "properties": {"tags": ["security", "external/cwe/cwe-089"], "security-severity": "8.8"}
+    }]}},
+    "results": [{
+      "ruleId": "go/sql-injection",
+      "message": {"text": "This query depends on a user-provided value."},
+      "locations": [{"physicalLocation": {"artifactLocation": {"uri": "/src/app/db.go"}, "region": {"startLine": 17}}}]
+    }]
+  }]
+}`
+
+func TestParseReport_SARIFUsesDriverName(t *testing.T) {
+	got, err := ParseReport(FormatSARIF, []byte(sampleCodeQLSARIF), ReportOptions{ProjectRoot: "/src"})
+	if err != nil {
+		t.Fatalf("ParseReport: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("expected 1 finding, got %d", len(got))
+	}
+	f := got[0]
+	if f.CreatedBy != "codeql" {
+		t.Errorf("created_by = %q, want codeql", f.CreatedBy)
+	}
+	if f.CWE != "CWE-89" {
+		t.Errorf("cwe = %q, want CWE-89", f.CWE)
+	}
+	if f.Severity != finding.SeverityHigh {
+		t.Errorf("severity = %q, want high (security-severity 8.8)", f.Severity)
+	}
+	if f.Location.File != "app/db.go" {
+		t.Errorf("file = %q, want app/db.go", f.Location.File)
+	}
+	if !hasTag(f.Tags, "codeql-rule:go/sql-injection") {
+		t.Errorf("tags = %v", f.Tags)
+	}
+
+	// Known drivers drop their edition suffix.
+	opengrep := strings.Replace(sampleCodeQLSARIF, `"name": "CodeQL"`, `"name": "Opengrep OSS"`, 1)
+	got, _ = ParseReport(FormatSARIF, []byte(opengrep), ReportOptions{})
+	if got[0].CreatedBy != "opengrep" || !hasTag(got[0].Tags, OpengrepRuleTagPrefix+"go/sql-injection") {
+		t.Errorf("Opengrep OSS driver: created_by = %q, tags = %v", got[0].CreatedBy, got[0].Tags)
+	}
+
+	// An explicit tool overrides the driver name.
+	got, _ = ParseReport(FormatSARIF, []byte(sampleCodeQLSARIF), ReportOptions{Tool: "GitHub CodeQL"})
+	if got[0].CreatedBy != "github-codeql" {
+		t.Errorf("created_by = %q, want github-codeql", got[0].CreatedBy)
+	}
+}
+
+func TestParseReport_Semgrep(t *testing.T) {
+	data := `{"results": [{
+	  "check_id": "python.django.security.injection.sql.sql-injection-using-raw",
+	  "path": "app/views.py",
+	  "start": {"line": 12, "col": 5}, "end": {"line": 13, "col": 20},
+	  "extra": {
+	    "message": "Detected user input in a raw SQL query. Use the ORM instead.",
+	    "severity": "ERROR",
+	    "lines": "cursor.execute(q)",
+	    "metadata": {"cwe": ["CWE-89: Improper Neutralization of Special Elements used in an SQL Command"],
+	                 "confidence": "HIGH", "references": ["https://owasp.org/Top10/A03_2021-Injection"]}
+	  }
+	}], "errors": []}`
+	got, err := ParseReport(FormatSemgrepJSON, []byte(data), ReportOptions{})
+	if err != nil {
+		t.Fatalf("ParseReport: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("expected 1 finding, got %d", len(got))
+	}
+	f := got[0]
+	if f.Title != "Detected user input in a raw SQL query" {
+		t.Errorf("title = %q", f.Title)
+	}
+	if f.Severity != finding.SeverityHigh || f.Confidence != finding.ConfidenceHigh || f.CWE != "CWE-89" {
+		t.Errorf("severity/confidence/cwe = %s/%s/%s", f.Severity, f.Confidence, f.CWE)
+	}
+	if f.CreatedBy != "semgrep" || f.Location.LineStart != 12 || f.Location.LineEnd != 13 {
+		t.Errorf("created_by/location = %s/%+v", f.CreatedBy, f.Location)
+	}
+	if len(f.References) != 1 {
+		t.Errorf("references = %v", f.References)
+	}
+}
+
+func TestParseReport_Bandit(t *testing.T) {
+	data := `{"errors": [], "results": [{
+	  "code": "4 query = \"SELECT * FROM users WHERE id = '%s'\" % uid\n",
+	  "filename": "./app/db.py",
+	  "issue_confidence": "LOW",
+	  "issue_cwe": {"id": 89, "link": "https://cwe.mitre.org/data/definitions/89.html"},
+	  "issue_severity": "MEDIUM",
+	  "issue_text": "Possible SQL injection vector through string-based query construction.",
+	  "line_number": 4,
+	  "line_range": [4, 5],
+	  "more_info": "https://bandit.readthedocs.io/en/latest/plugins/b608_hardcoded_sql_expressions.html",
+	  "test_id": "B608",
+	  "test_name": "hardcoded_sql_expressions"
+	}]}`
+	got, err := ParseReport(FormatBanditJSON, []byte(data), ReportOptions{})
+	if err != nil {
+		t.Fatalf("ParseReport: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("expected 1 finding, got %d", len(got))
+	}
+	f := got[0]
+	if f.Location.File != "app/db.py" || f.Location.LineEnd != 5 {
+		t.Errorf("location = %+v", f.Location)
+	}
+	if f.Severity != finding.SeverityMedium || f.Confidence != finding.ConfidenceLow || f.CWE != "CWE-89" {
+		t.Errorf("severity/confidence/cwe = %s/%s/%s", f.Severity, f.Confidence, f.CWE)
+	}
+	if f.CreatedBy != "bandit" || !hasTag(f.Tags, "bandit-rule:B608") {
+		t.Errorf("created_by/tags = %s/%v", f.CreatedBy, f.Tags)
+	}
+}
+
+func TestParseReport_Gosec(t *testing.T) {
+	data := `{"Golang errors": {}, "Issues": [{
+	  "severity": "HIGH", "confidence": "HIGH",
+	  "cwe": {"id": "89", "url": "https://cwe.mitre.org/data/definitions/89.html"},
+	  "rule_id": "G201",
+	  "details": "SQL string formatting",
+	  "file": "/work/internal/db/query.go",
+	  "code": "41: q := fmt.Sprintf(\"SELECT %s\", col)\n",
+	  "line": "41-42",
+	  "column": "7"
+	}], "Stats": {}}`
+	got, err := ParseReport(FormatGosecJSON, []byte(data), ReportOptions{ProjectRoot: "/work"})
+	if err != nil {
+		t.Fatalf("ParseReport: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("expected 1 finding, got %d", len(got))
+	}
+	f := got[0]
+	if f.Location.File != "internal/db/query.go" || f.Location.LineStart != 41 || f.Location.LineEnd != 42 {
+		t.Errorf("location = %+v", f.Location)
+	}
+	if f.Title != "SQL string formatting" || f.CWE != "CWE-89" || f.Severity != finding.SeverityHigh {
+		t.Errorf("title/cwe/severity = %q/%s/%s", f.Title, f.CWE, f.Severity)
+	}
+	if f.CreatedBy != "gosec" {
+		t.Errorf("created_by = %q", f.CreatedBy)
+	}
+}
+
+func TestParseReport_UnknownFormat(t *testing.T) {
+	_, err := ParseReport("checkmarx-xml", []byte(`{}`), ReportOptions{})
+	if err == nil || !strings.Contains(err.Error(), "supported") {
+		t.Errorf("expected unsupported-format error, got %v", err)
+	}
+}
+
+func hasTag(tags []string, want string) bool {
+	for _, t := range tags {
+		if t == want {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,167 @@
+package sast
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/diffsec/quokka/internal/finding"
+	"github.com/diffsec/quokka/internal/rule"
+)
+
+// RunRuleTests runs rule YAML against the fixtures in fixtureDir and
+// compares the results with the files' ruleid:/ok: annotations. The rule
+// need not be stored yet, so `quokka rule add` can test before writing.
+// Only the Binary and ExtraArgs of base are used; the rule is the sole
+// config, and tree-sitter query rules run without opengrep. Fixture files
+// are passed to opengrep explicitly, so ignore files that would skip a
+// tests/ directory don't apply.
+func RunRuleTests(base Scanner, slug string, content []byte, fixtureDir string) (*rule.TestResult, error) {
+	ids, err := rule.RuleIDs(content)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := rule.FixtureFiles(fixtureDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("rule %q has no test fixtures: add annotated files under %s", slug, fixtureDir)
+	}
+
+	var expect []rule.Expectation
+	targets := make([]string, 0, len(files))
+	for _, f := range files {
+		path := filepath.Join(fixtureDir, filepath.FromSlash(f))
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read fixture: %w", err)
+		}
+		expect = append(expect, rule.ParseAnnotations(f, data)...)
+		targets = append(targets, path)
+	}
+	positives := 0
+	for _, e := range expect {
+		if e.Match {
+			positives++
+		}
+	}
+	if positives == 0 {
+		return nil, fmt.Errorf("rule %q fixtures have no \"ruleid:\" annotations: mark at least one line the rule must match", slug)
+	}
+
+	results, err := ScanRule(base, content, fixtureDir, targets)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]rule.Match, 0, len(results))
+	for _, f := range results {
+		matches = append(matches, rule.Match{
+			File:   filepath.ToSlash(f.Location.File),
+			Line:   f.Location.LineStart,
+			RuleID: RuleIDFromTags(f.Tags),
+		})
+	}
+
+	res := rule.Evaluate(slug, ids, expect, matches)
+	res.Files = len(files)
+	return &res, nil
+}
+
+// AddRule is how a rule that scans immediately gets into the store: it
+// runs the rule against the fixtures in fixtureDir, or those already under
+// .quokka/rules/tests/<slug>/ when fixtureDir is empty, and adds it only if
+// they pass, copying the fixtures into place. A failing run returns its
+// result with the error. Drafts, which quokka sast skips, are tested when
+// promoted instead.
+func AddRule(base Scanner, store *rule.Store, slug string, content []byte, meta rule.Meta, fixtureDir string) (*rule.TestResult, error) {
+	if _, err := rule.ParseRuleFile(content); err != nil {
+		return nil, err
+	}
+	if fixtureDir == "" {
+		fixtureDir = store.TestDir(slug)
+	}
+	res, err := RunRuleTests(base, slug, content, fixtureDir)
+	if err != nil {
+		return nil, err
+	}
+	if !res.Passed() {
+		return res, fmt.Errorf("rule %q failed its tests; not added", slug)
+	}
+	if err := store.Add(slug, content, meta); err != nil {
+		return nil, err
+	}
+	if err := store.SetTests(slug, fixtureDir); err != nil {
+		return res, fmt.Errorf("rule added but saving its fixtures failed: %w", err)
+	}
+	return res, nil
+}
+
+// AnnotateRule records a judge verdict on a stored rule. A keep verdict on
+// a draft from `quokka rule propose` also promotes it, but only once it
+// passes the fixtures under .quokka/rules/tests/<slug>/; otherwise nothing
+// is recorded and a failing run returns its result with the error.
+func AnnotateRule(base Scanner, store *rule.Store, slug string, verdict rule.Verdict, note string) (*rule.TestResult, error) {
+	m, err := store.ReadMeta(slug)
+	if err != nil {
+		return nil, fmt.Errorf("read meta: %w", err)
+	}
+	promote := verdict == rule.VerdictKeep && m.Draft
+	var res *rule.TestResult
+	if promote {
+		content, err := store.ReadRule(slug)
+		if err != nil {
+			return nil, err
+		}
+		res, err = RunRuleTests(base, slug, content, store.TestDir(slug))
+		if err != nil {
+			return nil, fmt.Errorf("draft %q not promoted: %w", slug, err)
+		}
+		if !res.Passed() {
+			return res, fmt.Errorf("draft %q failed its tests; not promoted", slug)
+		}
+	}
+	if err := store.Annotate(slug, verdict, note); err != nil {
+		return res, err
+	}
+	if promote {
+		if err := store.Promote(slug); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+// ScanRule scans targets with rule YAML that need not be stored, e.g. a
+// draft from `quokka rule propose` run across the repository. As with
+// RunRuleTests only the Binary and ExtraArgs of base are used, and query
+// rules go to a QueryScanner instead; paths in the results are relative
+// to root.
+func ScanRule(base Scanner, content []byte, root string, targets []string) ([]finding.Finding, error) {
+	tmp, err := os.CreateTemp("", "quokka-rule-*.yaml")
+	if err != nil {
+		return nil, fmt.Errorf("sast: tempfile: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	_, err = tmp.Write(content)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("sast: write rule: %w", err)
+	}
+
+	if rf, err := rule.ParseRuleFile(content); err == nil && rf.IsQuery() {
+		qs := QueryScanner{Configs: []string{tmp.Name()}, ProjectRoot: root}
+		return qs.Scan(targets)
+	}
+	scanner := Scanner{
+		Binary:      base.Binary,
+		Config:      tmp.Name(),
+		ExtraArgs:   base.ExtraArgs,
+		ProjectRoot: root,
+	}
+	return scanner.Scan(targets)
+}
@@ -0,0 +1,185 @@
+package sast
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/diffsec/quokka/internal/project"
+	"github.com/diffsec/quokka/internal/rule"
+)
+
+const md5Rule = `rules:
+  - id: quokka-md5
+    message: MD5 is not collision resistant
+    languages: [python]
+    pattern: hashlib.md5(...)
+`
+
+const md5Fixture = `import hashlib
+
+# ruleid: quokka-md5
+hashlib.md5(data)
+
+# ok: quokka-md5
+hashlib.sha256(data)
+`
+
+// stubOpengrep writes a fake opengrep that ignores its input and writes a
+// SARIF log with one result per line in lines, all in file.
+func stubOpengrep(t *testing.T, file string, lines ...int) string {
+	t.Helper()
+	var results []string
+	for _, l := range lines {
+		results = append(results, fmt.Sprintf(`{"ruleId": "rules.quokka-md5", "message": {"text": "MD5 is not collision resistant"},
+		  "locations": [{"physicalLocation": {"artifactLocation": {"uri": %q}, "region": {"startLine": %d}}}]}`, file, l))
+	}
+	sarif := `{"version": "2.1.0", "runs": [{"tool": {"driver": {"name": "opengrep"}}, "results": [` + strings.Join(results, ",") + `]}]}`
+	script := "#!/bin/sh\nfor a in \"$@\"; do case \"$a\" in --sarif-output=*) out=\"${a#--sarif-output=}\";; esac; done\n" +
+		"cat > \"$out\" <<'SARIF'\n" + sarif + "\nSARIF\n"
+	bin := filepath.Join(t.TempDir(), "opengrep")
+	if err := os.WriteFile(bin, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return bin
+}
+
+func writeRuleFixtures(t *testing.T) (fixtureDir string) {
+	t.Helper()
+	fixtureDir = filepath.Join(t.TempDir(), "tests", "md5")
+	if err := os.MkdirAll(fixtureDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(fixtureDir, "app.py"), []byte(md5Fixture), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return fixtureDir
+}
+
+func TestRunRuleTests_Passes(t *testing.T) {
+	fixtureDir := writeRuleFixtures(t)
+	bin := stubOpengrep(t, filepath.Join(fixtureDir, "app.py"), 4)
+
+	res, err := RunRuleTests(Scanner{Binary: bin}, "md5", []byte(md5Rule), fixtureDir)
+	if err != nil {
+		t.Fatalf("RunRuleTests: %v", err)
+	}
+	if !res.Passed() || res.Files != 1 || res.Expected != 1 || res.Matched != 1 {
+		t.Errorf("result = %+v, want a pass", res)
+	}
+}
+
+func TestRunRuleTests_ReportsMissedAndUnexpected(t *testing.T) {
+	fixtureDir := writeRuleFixtures(t)
+	bin := stubOpengrep(t, filepath.Join(fixtureDir, "app.py"), 7)
+
+	res, err := RunRuleTests(Scanner{Binary: bin}, "md5", []byte(md5Rule), fixtureDir)
+	if err != nil {
+		t.Fatalf("RunRuleTests: %v", err)
+	}
+	if res.Passed() {
+		t.Fatal("expected failure")
+	}
+	if len(res.Missed) != 1 || res.Missed[0].Line != 4 || res.Missed[0].RuleID != "quokka-md5" {
+		t.Errorf("missed = %+v, want line 4", res.Missed)
+	}
+	if len(res.Unexpected) != 1 || res.Unexpected[0].Line != 7 || res.Unexpected[0].File != "app.py" {
+		t.Errorf("unexpected = %+v, want app.py:7", res.Unexpected)
+	}
+}
+
+func TestRunRuleTests_RequiresFixtures(t *testing.T) {
+	fixtureDir := writeRuleFixtures(t)
+	bin := stubOpengrep(t, "")
+
+	if _, err := RunRuleTests(Scanner{Binary: bin}, "md5", []byte(md5Rule), filepath.Join(fixtureDir, "missing")); err == nil || !strings.Contains(err.Error(), "no test fixtures") {
+		t.Errorf("missing fixtures: err = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(fixtureDir, "app.py"), []byte("hashlib.md5(x)\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RunRuleTests(Scanner{Binary: bin}, "md5", []byte(md5Rule), fixtureDir); err == nil || !strings.Contains(err.Error(), "ruleid:") {
+		t.Errorf("unannotated fixtures: err = %v", err)
+	}
+}
+
+func TestAddRule_OnlyAddsPassingRules(t *testing.T) {
+	p, err := project.Initialize(t.TempDir())
+	if err != nil {
+		t.Fatalf("project init: %v", err)
+	}
+	store := rule.NewStore(p)
+	meta := rule.Meta{CreatedBy: "agent:test", CreatedAt: time.Now()}
+	fixtureDir := writeRuleFixtures(t)
+
+	failing := stubOpengrep(t, filepath.Join(fixtureDir, "app.py"), 7)
+	res, err := AddRule(Scanner{Binary: failing}, store, "md5", []byte(md5Rule), meta, fixtureDir)
+	if err == nil || res == nil || res.Passed() {
+		t.Fatalf("failing rule: res=%+v err=%v, want a failed result", res, err)
+	}
+	if _, err := store.ReadMeta("md5"); err == nil {
+		t.Error("failing rule was added")
+	}
+	if _, err := AddRule(Scanner{Binary: failing}, store, "md5", []byte(md5Rule), meta, ""); err == nil || !strings.Contains(err.Error(), "no test fixtures") {
+		t.Errorf("rule without fixtures: err = %v", err)
+	}
+
+	passing := stubOpengrep(t, filepath.Join(fixtureDir, "app.py"), 4)
+	if res, err := AddRule(Scanner{Binary: passing}, store, "md5", []byte(md5Rule), meta, fixtureDir); err != nil || !res.Passed() {
+		t.Fatalf("passing rule: res=%+v err=%v", res, err)
+	}
+	if _, err := os.Stat(filepath.Join(store.TestDir("md5"), "app.py")); err != nil {
+		t.Errorf("fixtures not copied into place: %v", err)
+	}
+}
+
+func TestAnnotateRule_PromotesOnlyPassingDrafts(t *testing.T) {
+	p, err := project.Initialize(t.TempDir())
+	if err != nil {
+		t.Fatalf("project init: %v", err)
+	}
+	store := rule.NewStore(p)
+	meta := rule.Meta{CreatedBy: "agent:rule-propose", CreatedAt: time.Now(), Draft: true}
+	if err := store.Add("md5", []byte(md5Rule), meta); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetTests("md5", writeRuleFixtures(t)); err != nil {
+		t.Fatal(err)
+	}
+	fixture := filepath.Join(store.TestDir("md5"), "app.py")
+
+	failing := stubOpengrep(t, fixture, 7)
+	if res, err := AnnotateRule(Scanner{Binary: failing}, store, "md5", rule.VerdictKeep, ""); err == nil || res == nil || res.Passed() {
+		t.Fatalf("failing draft: res=%+v err=%v, want a failed result", res, err)
+	}
+	if m, _ := store.ReadMeta("md5"); !m.Draft || m.Verdict != "" {
+		t.Errorf("failing draft was annotated: %+v", m)
+	}
+	if _, err := AnnotateRule(Scanner{Binary: failing}, store, "md5", rule.VerdictRefine, "tighten"); err != nil {
+		t.Errorf("refine verdict should not run tests: %v", err)
+	}
+
+	passing := stubOpengrep(t, fixture, 4)
+	if _, err := AnnotateRule(Scanner{Binary: passing}, store, "md5", rule.VerdictKeep, "real matches"); err != nil {
+		t.Fatalf("passing draft: %v", err)
+	}
+	if m, _ := store.ReadMeta("md5"); m.Draft || m.Verdict != rule.VerdictKeep {
+		t.Errorf("passing draft not promoted: %+v", m)
+	}
+}
+
+func TestScanRule_RelativisesToRoot(t *testing.T) {
+	root := t.TempDir()
+	bin := stubOpengrep(t, filepath.Join(root, "app.py"), 4)
+
+	results, err := ScanRule(Scanner{Binary: bin}, []byte(md5Rule), root, []string{root})
+	if err != nil {
+		t.Fatalf("ScanRule: %v", err)
+	}
+	if len(results) != 1 || results[0].Location.File != "app.py" || results[0].Location.LineStart != 4 {
+		t.Errorf("results = %+v, want app.py:4", results)
+	}
+}
@@ -0,0 +1,390 @@
+package sast
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/diffsec/quokka/internal/finding"
+	"github.com/diffsec/quokka/internal/rule"
+	"github.com/diffsec/quokka/internal/treesitter"
+	"gopkg.in/yaml.v3"
+)
+
+// TreesitterRuleTagPrefix is the tag prefix identifying the query rule that
+// produced a finding, the counterpart of OpengrepRuleTagPrefix.
+const TreesitterRuleTagPrefix = "treesitter-rule:"
+
+// matchCapture names the capture that sets a finding's location. Without
+// it the finding spans every capture of the match.
+const matchCapture = "match"
+
+// queryScanSkipDirs are directories never walked when scanning a directory.
+// Hidden directories (.git, .quokka, ...) are skipped as well.
+var queryScanSkipDirs = map[string]bool{"node_modules": true, "vendor": true}
+
+// ruleFixtureDir holds rule test fixtures (.quokka/rules/tests/<slug>/),
+// which are never rules themselves.
+const ruleFixtureDir = "tests"
+
+// QueryScanner runs project rules written as tree-sitter queries against
+// the grammars bundled in internal/treesitter, so quokka sast works where
+// the opengrep binary isn't installed. Rule files keep the opengrep layout
+// (rules: with id, message, severity, languages and metadata) but carry a
+// query: in place of pattern:
+//
+//	rules:
+//	  - id: quokka-shell-true
+//	    message: subprocess called with shell=True
+//	    severity: ERROR
+//	    languages: [python]
+//	    metadata: {cwe: "CWE-78"}
+//	    query: |
+//	      (call arguments: (argument_list
+//	        (keyword_argument name: (identifier) @kw value: (true)))) @match
+//	      (#eq? @kw "shell")
+//
+// Results have the same shape as opengrep's (see convertResult), so diff
+// scoping, fingerprints and triage treat both engines alike.
+type QueryScanner struct {
+	// Configs are query rule files or directories of them. Directories are
+	// searched recursively, skipping tests/ fixture directories; files in
+	// them holding opengrep rules are skipped.
+	Configs []string
+
+	// Store, when set, resolves a Configs entry naming its directory
+	// (.quokka/rules) to the store's enabled, non-draft query rules, so
+	// retired rules and drafts stay out of the scan.
+	Store *rule.Store
+
+	// ProjectRoot relativizes finding paths, as for Scanner.
+	ProjectRoot string
+}
+
+// queryRule is a loaded query rule with its compiled query.
+type queryRule struct {
+	ID        string         `yaml:"id"`
+	Message   string         `yaml:"message"`
+	Severity  string         `yaml:"severity"`
+	Languages []string       `yaml:"languages"`
+	Query     string         `yaml:"query"`
+	Metadata  map[string]any `yaml:"metadata"`
+
+	compiled *treesitter.Query
+}
+
+// Scan runs every query rule over the target files and directories and
+// returns the findings, unpersisted. Files the grammars can't parse are
+// reported on stderr and skipped, like opengrep's partial results.
+func (s *QueryScanner) Scan(targets []string) ([]finding.Finding, error) {
+	rules, err := loadQueryRules(s.Store, s.Configs...)
+	if err != nil {
+		return nil, err
+	}
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("sast: no tree-sitter query rules found in %s", strings.Join(s.Configs, ", "))
+	}
+	files, err := queryScanFiles(targets)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []finding.Finding
+	for _, path := range files {
+		lang := treesitter.DetectLanguageName(path)
+		var applicable []*queryRule
+		for _, r := range rules {
+			if r.appliesTo(lang) {
+				applicable = append(applicable, r)
+			}
+		}
+		if len(applicable) == 0 {
+			continue
+		}
+		if info, err := os.Stat(path); err != nil || info.Size() > treesitter.DefaultMaxFileSize {
+			continue
+		}
+		source, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("sast: read %s: %w", path, err)
+		}
+		file := path
+		if abs, err := filepath.Abs(path); err == nil && s.ProjectRoot != "" {
+			file = relativizePath(abs, s.ProjectRoot)
+		}
+		lines := strings.Split(string(source), "\n")
+		for _, r := range applicable {
+			matches, err := r.compiled.Match(source, path)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "warning: treesitter rule %s skipped %s: %v\n", r.ID, file, err)
+				continue
+			}
+			seen := map[int]bool{}
+			for _, m := range matches {
+				f := r.convertMatch(file, lines, m)
+				if seen[f.Location.LineStart] {
+					continue
+				}
+				seen[f.Location.LineStart] = true
+				out = append(out, f)
+			}
+		}
+	}
+	return out, nil
+}
+
+// loadQueryRules reads and compiles the query rules in the given files and
+// directories, each file once however many times it is named. A file named
+// explicitly must hold query rules; in directories, opengrep rule files,
+// quokka metadata sidecars and tests/ fixtures are skipped. The directory
+// of store, if given, yields only its enabled, non-draft query rules.
+func loadQueryRules(store *rule.Store, paths ...string) ([]*queryRule, error) {
+	var rules []*queryRule
+	seen := map[string]bool{}
+	load := func(path string, required bool) error {
+		key := path
+		if abs, err := filepath.Abs(path); err == nil {
+			key = abs
+		}
+		if seen[key] {
+			return nil
+		}
+		seen[key] = true
+		loaded, err := loadQueryRuleFile(path, required)
+		if err != nil {
+			return err
+		}
+		rules = append(rules, loaded...)
+		return nil
+	}
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, fmt.Errorf("sast: rules %s: %w", p, err)
+		}
+		if !info.IsDir() {
+			if err := load(p, true); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if store != nil && samePath(p, store.Dir()) {
+			enabled, err := store.EnabledQueryRulePaths()
+			if err != nil {
+				return nil, fmt.Errorf("sast: rules %s: %w", p, err)
+			}
+			for _, path := range enabled {
+				if err := load(path, false); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+		err = filepath.WalkDir(p, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			name := d.Name()
+			if d.IsDir() {
+				if path != p && (strings.HasPrefix(name, ".") || name == ruleFixtureDir) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if strings.HasSuffix(name, ".zmeta.yaml") || (!strings.HasSuffix(name, ".yaml") && !strings.HasSuffix(name, ".yml")) {
+				return nil
+			}
+			return load(path, false)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return rules, nil
+}
+
+// samePath reports whether a and b name the same file once made absolute.
+func samePath(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
+
+// loadQueryRuleFile loads one rule file. Unless required, a file that isn't
+// a query rule file yields no rules rather than an error.
+func loadQueryRuleFile(path string, required bool) ([]*queryRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("sast: read rules: %w", err)
+	}
+	rf, err := rule.ParseRuleFile(data)
+	if err != nil || !rf.IsQuery() {
+		if !required {
+			return nil, nil
+		}
+		if err == nil {
+			err = fmt.Errorf("no query: rules (opengrep rules need --tool opengrep)")
+		}
+		return nil, fmt.Errorf("sast: %s: %w", path, err)
+	}
+
+	var parsed struct {
+		Rules []*queryRule `yaml:"rules"`
+	}
+	if err := yaml.Unmarshal(data, &parsed); err != nil {
+		return nil, fmt.Errorf("sast: %s: %w", path, err)
+	}
+	for _, r := range parsed.Rules {
+		r.compiled, err = treesitter.CompileQuery(r.Languages[0], r.Query)
+		for _, lang := range r.Languages[1:] {
+			if err != nil {
+				break
+			}
+			_, err = treesitter.CompileQuery(lang, r.Query)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("sast: %s: rule %q: %w", path, r.ID, err)
+		}
+	}
+	return parsed.Rules, nil
+}
+
+// appliesTo reports whether the rule covers a DetectLanguageName language.
+// JSX and TSX files are covered by javascript and typescript rules.
+func (r *queryRule) appliesTo(lang string) bool {
+	if lang == "" {
+		return false
+	}
+	base := strings.TrimSuffix(lang, "react")
+	for _, l := range r.Languages {
+		l = strings.ToLower(strings.TrimSpace(l))
+		if l == lang || l == base {
+			return true
+		}
+	}
+	return false
+}
+
+// convertMatch builds the finding for one match, mirroring convertResult:
+// the message gives the title and description, the opengrep-style severity
+// maps through the SARIF levels, and metadata supplies the CWE.
+func (r *queryRule) convertMatch(file string, lines []string, m treesitter.QueryMatch) finding.Finding {
+	start, end := 0, 0
+	for _, c := range m.Captures {
+		if c.Name == matchCapture {
+			start, end = c.Line, c.EndLine
+			break
+		}
+		if start == 0 || c.Line < start {
+			start = c.Line
+		}
+		if c.EndLine > end {
+			end = c.EndLine
+		}
+	}
+	loc := finding.Location{File: file, LineStart: start, LineEnd: end}
+	if start >= 1 && end <= len(lines) {
+		loc.Snippet = strings.Join(lines[start-1:end], "\n")
+	}
+
+	description := strings.TrimSpace(r.Message)
+	severity := mapSeverity(sarifLevel(r.Severity))
+	if sev, ok := securitySeverity(r.Metadata); ok {
+		severity = sev
+	}
+
+	cwe := ""
+	for _, tag := range metadataStrings(r.Metadata["cwe"]) {
+		if cwe = extractCWE(tag); cwe != "" {
+			break
+		}
+	}
+
+	tags := []string{"sast", "treesitter"}
+	if id := strings.TrimSpace(r.ID); id != "" {
+		tags = append(tags, TreesitterRuleTagPrefix+id)
+	}
+
+	return finding.Finding{
+		Title:       titleFor(sarifRule{}, r.ID, description),
+		Severity:    severity,
+		Confidence:  finding.ConfidenceMedium,
+		Status:      finding.StatusOpen,
+		CWE:         cwe,
+		Location:    loc,
+		Description: description,
+		Tags:        tags,
+		CreatedBy:   "treesitter",
+	}
+}
+
+// sarifLevel maps an opengrep rule severity to the SARIF level opengrep
+// would report for it.
+func sarifLevel(severity string) string {
+	switch strings.ToUpper(strings.TrimSpace(severity)) {
+	case "ERROR", "CRITICAL", "HIGH":
+		return "error"
+	case "WARNING", "MEDIUM":
+		return "warning"
+	case "INFO", "LOW":
+		return "note"
+	}
+	return ""
+}
+
+// metadataStrings accepts a metadata value written as a string or a list.
+func metadataStrings(v any) []string {
+	switch t := v.(type) {
+	case string:
+		return []string{t}
+	case []any:
+		var out []string
+		for _, e := range t {
+			if s, ok := e.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// queryScanFiles expands targets into the files to scan, walking
+// directories while skipping hidden and vendored ones.
+func queryScanFiles(targets []string) ([]string, error) {
+	var files []string
+	for _, t := range targets {
+		info, err := os.Stat(t)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("sast: %w", err)
+		}
+		if !info.IsDir() {
+			files = append(files, t)
+			continue
+		}
+		err = filepath.WalkDir(t, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if d.IsDir() {
+				if path != t && (strings.HasPrefix(d.Name(), ".") || queryScanSkipDirs[d.Name()]) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if d.Type().IsRegular() {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("sast: walk %s: %w", t, err)
+		}
+	}
+	return files, nil
+}
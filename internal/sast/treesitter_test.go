@@ -0,0 +1,187 @@
+package sast
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/diffsec/quokka/internal/finding"
+	"github.com/diffsec/quokka/internal/project"
+	"github.com/diffsec/quokka/internal/rule"
+)
+
+const shellTrueRule = `rules:
+  - id: quokka-shell-true
+    message: subprocess called with shell=True. Pass an argument list instead.
+    severity: ERROR
+    languages: [python]
+    metadata:
+      cwe: ["CWE-78: OS Command Injection"]
+    query: |
+      (call
+        function: (attribute object: (identifier) @mod)
+        arguments: (argument_list
+          (keyword_argument name: (identifier) @kw value: (true)))) @match
+      (#eq? @mod "subprocess")
+      (#eq? @kw "shell")
+`
+
+const shellTrueSource = `import subprocess
+
+def run(cmd):
+    subprocess.run(cmd, shell=True)
+    subprocess.run(["ls"], shell=False)
+`
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestQueryScanner_FindingShape(t *testing.T) {
+	root := t.TempDir()
+	rules := filepath.Join(t.TempDir(), "rules")
+	writeFile(t, filepath.Join(rules, "shell.yaml"), shellTrueRule)
+	writeFile(t, filepath.Join(rules, "shell.zmeta.yaml"), "slug: shell\n")
+	writeFile(t, filepath.Join(rules, "md5.yaml"), md5Rule) // opengrep rule: skipped
+	writeFile(t, filepath.Join(root, "app", "run.py"), shellTrueSource)
+	writeFile(t, filepath.Join(root, "node_modules", "x", "run.py"), shellTrueSource)
+	writeFile(t, filepath.Join(root, ".venv", "run.py"), shellTrueSource)
+
+	s := &QueryScanner{Configs: []string{rules}, ProjectRoot: root}
+	results, err := s.Scan([]string{root})
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("got %d results, want 1: %+v", len(results), results)
+	}
+	f := results[0]
+	if f.Location.File != filepath.Join("app", "run.py") || f.Location.LineStart != 4 || f.Location.LineEnd != 4 {
+		t.Errorf("location = %+v", f.Location)
+	}
+	if f.Location.Snippet != "    subprocess.run(cmd, shell=True)" {
+		t.Errorf("snippet = %q", f.Location.Snippet)
+	}
+	if f.Title != "subprocess called with shell=True" || f.Severity != finding.SeverityHigh || f.CWE != "CWE-78" {
+		t.Errorf("title/severity/cwe = %q/%s/%s", f.Title, f.Severity, f.CWE)
+	}
+	if f.CreatedBy != "treesitter" || f.Status != finding.StatusOpen || RuleIDFromTags(f.Tags) != "quokka-shell-true" {
+		t.Errorf("created_by/status/rule = %s/%s/%q (tags %v)", f.CreatedBy, f.Status, RuleIDFromTags(f.Tags), f.Tags)
+	}
+}
+
+func TestQueryScanner_RuleErrors(t *testing.T) {
+	dir := t.TempDir()
+	opengrep := filepath.Join(dir, "md5.yaml")
+	writeFile(t, opengrep, md5Rule)
+	if _, err := (&QueryScanner{Configs: []string{opengrep}}).Scan([]string{dir}); err == nil || !strings.Contains(err.Error(), "--tool opengrep") {
+		t.Errorf("opengrep rule file: err = %v", err)
+	}
+
+	bad := filepath.Join(dir, "bad.yaml")
+	writeFile(t, bad, strings.Replace(shellTrueRule, "(#eq? @kw \"shell\")", "(#eq? @kw \"shell\"", 1))
+	if _, err := (&QueryScanner{Configs: []string{bad}}).Scan([]string{dir}); err == nil || !strings.Contains(err.Error(), "quokka-shell-true") {
+		t.Errorf("malformed query: err = %v", err)
+	}
+
+	empty := t.TempDir()
+	if _, err := (&QueryScanner{Configs: []string{empty}}).Scan([]string{dir}); err == nil {
+		t.Error("expected error when no query rules are found")
+	}
+}
+
+func TestLoadQueryRules_ResolvesStoreOnce(t *testing.T) {
+	p, err := project.Initialize(t.TempDir())
+	if err != nil {
+		t.Fatalf("project init: %v", err)
+	}
+	store := rule.NewStore(p)
+	add := func(slug string, draft bool) {
+		t.Helper()
+		content := strings.Replace(shellTrueRule, "quokka-shell-true", "quokka-"+slug, 1)
+		meta := rule.Meta{CreatedBy: "agent:test", CreatedAt: time.Now(), Draft: draft}
+		if err := store.Add(slug, []byte(content), meta); err != nil {
+			t.Fatal(err)
+		}
+	}
+	add("shell", false)
+	add("drafted", true)
+	add("retired", false)
+	if err := store.Annotate("retired", rule.VerdictRetire, ""); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(store.TestDir("shell"), "fixture.yaml"), strings.Replace(shellTrueRule, "quokka-shell-true", "quokka-fixture", 1))
+	enabled, err := store.EnabledQueryRulePaths()
+	if err != nil || len(enabled) != 1 {
+		t.Fatalf("enabled query rules = %v, %v", enabled, err)
+	}
+
+	rel, err := filepath.Rel(mustGetwd(t), store.Dir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, configs := range map[string][]string{
+		"store":         {store.Dir()},
+		"store twice":   {rel, store.Dir(), enabled[0]},
+		"file then dir": {enabled[0], store.Dir()},
+	} {
+		rules, err := loadQueryRules(store, configs...)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		var ids []string
+		for _, r := range rules {
+			ids = append(ids, r.ID)
+		}
+		if len(ids) != 1 || ids[0] != "quokka-shell" {
+			t.Errorf("%s: loaded %v, want only quokka-shell", name, ids)
+		}
+	}
+
+	rules, err := loadQueryRules(nil, store.Dir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range rules {
+		if r.ID == "quokka-fixture" {
+			t.Error("walk descended into tests/")
+		}
+	}
+}
+
+func mustGetwd(t *testing.T) string {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return wd
+}
+
+func TestRunRuleTests_QueryRuleWithoutOpengrep(t *testing.T) {
+	fixtureDir := t.TempDir()
+	writeFile(t, filepath.Join(fixtureDir, "run.py"), `import subprocess
+
+# ruleid: quokka-shell-true
+subprocess.run(cmd, shell=True)
+
+# ok: quokka-shell-true
+subprocess.run(["ls"], shell=False)
+`)
+
+	res, err := RunRuleTests(Scanner{Binary: "/nonexistent/opengrep"}, "shell-true", []byte(shellTrueRule), fixtureDir)
+	if err != nil {
+		t.Fatalf("RunRuleTests: %v", err)
+	}
+	if !res.Passed() || res.Matched != 1 {
+		t.Errorf("result = %+v, want a pass", res)
+	}
+}
@@ -29,6 +29,7 @@ type IndexStats struct {
 	LanguageCounts map[string]int              `json:"language_counts"`
 	IndexPath      string                      `json:"index_path"`
 	LastUpdated    time.Time                   `json:"last_updated,omitempty"`
+	Tombstones     int                         `json:"tombstones"`
 }
 
 // IndexProgress reports progress during indexing
@@ -48,6 +49,7 @@ type ProgressCallback func(progress *IndexProgress)
 type Indexer struct {
 	project    *project.Project
 	store      vectordb.Store
+	lexical    *LexicalIndex
 	provider   embedding.Provider
 	extractor  *chunk.Extractor
 	watcher    *fsnotify.Watcher
@@ -69,6 +71,10 @@ type IndexerConfig struct {
 	MaxChunkLines int
 	// ExcludePatterns are file patterns to exclude
 	ExcludePatterns []string
+	// Quantization is the in-memory vector compression ("none" or "int8")
+	Quantization string
+	// RerankFactor is the number of quantized candidates re-ranked per result
+	RerankFactor int
 }
 
 // NewIndexer creates a new semantic indexer
@@ -81,6 +87,15 @@ func NewIndexer(p *project.Project, config *IndexerConfig) (*Indexer, error) {
 
 	// Create vector store
 	storeConfig := vectordb.DefaultStoreConfig(config.StorePath, provider.Dimension())
+	quantization, err := vectordb.ParseQuantization(config.Quantization)
+	if err != nil {
+		_ = provider.Close()
+		return nil, err
+	}
+	storeConfig.Quantization = quantization
+	if config.RerankFactor > 0 {
+		storeConfig.RerankFactor = config.RerankFactor
+	}
 	store, err := vectordb.NewHNSWStore(storeConfig)
 	if err != nil {
 		_ = provider.Close()
@@ -104,9 +119,18 @@ func NewIndexer(p *project.Project, config *IndexerConfig) (*Indexer, error) {
 		extractor.SetMaxChunkLines(config.MaxChunkLines)
 	}
 
+	// The lexical index is best-effort: without it hybrid search falls
+	// back to vector-only rather than failing
+	lexical, err := NewLexicalIndex(config.StorePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: lexical index unavailable, keyword search disabled: %v\n", err)
+		lexical = nil
+	}
+
 	return &Indexer{
 		project:   p,
 		store:     store,
+		lexical:   lexical,
 		provider:  provider,
 		extractor: extractor,
 		excludes:  config.ExcludePatterns,
@@ -117,7 +141,7 @@ func NewIndexer(p *project.Project, config *IndexerConfig) (*Indexer, error) {
 func (idx *Indexer) Build(ctx context.Context, force bool, progress ProgressCallback) error {
 	// Clear existing index if force
 	if force {
-		if err := idx.store.Clear(); err != nil {
+		if err := idx.Clear(); err != nil {
 			return fmt.Errorf("failed to clear index: %w", err)
 		}
 	}
@@ -213,7 +237,7 @@ func (idx *Indexer) Build(ctx context.Context, force bool, progress ProgressCall
 					storeMu.Lock()
 					insertOK := true
 					for _, ce := range chunks {
-						if err := idx.store.InsertBatch(ce.chunks, ce.embeddings); err != nil {
+						if err := idx.insertChunks(ce.chunks, ce.embeddings); err != nil {
 							fmt.Printf("Warning: failed to store chunks for %s: %v\n", work.file, err)
 							insertOK = false
 						}
@@ -336,13 +360,19 @@ func (idx *Indexer) Update(ctx context.Context, progress ProgressCallback) (int,
 		currentSet[f] = true
 	}
 
+	// Indexes built before the lexical index existed are backfilled from
+	// the stored chunks, without re-embedding
+	if err := idx.backfillLexical(indexedFiles); err != nil {
+		fmt.Printf("Warning: failed to backfill lexical index: %v\n", err)
+	}
+
 	var updated int
 
 	// Remove deleted files (and their cached file-hash rows so the sidecar
 	// doesn't grow stale and a future re-add still looks "new").
 	for _, file := range indexedFiles {
 		if !currentSet[file] {
-			if err := idx.store.DeleteByFile(file); err != nil {
+			if err := idx.deleteFile(file); err != nil {
 				fmt.Printf("Warning: failed to remove %s: %v\n", file, err)
 			} else {
 				if err := idx.store.DeleteFileHash(file); err != nil {
@@ -390,7 +420,7 @@ func (idx *Indexer) Update(ctx context.Context, progress ProgressCallback) (int,
 		// Remove old chunks for this file (if any). DeleteByFile is a
 		// no-op when there are no rows, so it's safe regardless.
 		if indexedSet[file] {
-			if err := idx.store.DeleteByFile(file); err != nil {
+			if err := idx.deleteFile(file); err != nil {
 				fmt.Printf("Warning: failed to remove old chunks for %s: %v\n", file, err)
 			}
 		}
@@ -416,6 +446,8 @@ func (idx *Indexer) Update(ctx context.Context, progress ProgressCallback) (int,
 		idx.recordFileHash(fh)
 	}
 
+	idx.maybeCompact()
+
 	return updated, nil
 }
 
@@ -654,7 +686,7 @@ func (idx *Indexer) indexFile(ctx context.Context, file string) error {
 	// Insert results in order to maintain consistency
 	for i := 0; i < len(batches); i++ {
 		r := resultMap[i]
-		if err := idx.store.InsertBatch(r.chunks, r.embeddings); err != nil {
+		if err := idx.insertChunks(r.chunks, r.embeddings); err != nil {
 			return fmt.Errorf("failed to insert chunks: %w", err)
 		}
 	}
@@ -1092,7 +1124,7 @@ func (idx *Indexer) Watch(ctx context.Context) error {
 				if _, err := os.Stat(fullPath); os.IsNotExist(err) {
 					// File deleted: drop chunks AND the file-hash sidecar
 					// row so a future re-create looks "new" to Update().
-					if err := idx.store.DeleteByFile(f); err != nil {
+					if err := idx.deleteFile(f); err != nil {
 						fmt.Printf("Warning: failed to remove %s from index: %v\n", f, err)
 					}
 					if err := idx.store.DeleteFileHash(f); err != nil {
@@ -1100,7 +1132,7 @@ func (idx *Indexer) Watch(ctx context.Context) error {
 					}
 				} else {
 					// File created or modified
-					if err := idx.store.DeleteByFile(f); err != nil {
+					if err := idx.deleteFile(f); err != nil {
 						fmt.Printf("Warning: failed to remove old %s: %v\n", f, err)
 					}
 					if err := idx.indexFile(ctx, f); err != nil {
@@ -1116,6 +1148,7 @@ func (idx *Indexer) Watch(ctx context.Context) error {
 					}
 				}
 			}
+			idx.maybeCompact()
 		})
 	}
 
@@ -1199,12 +1232,96 @@ func (idx *Indexer) Stats() (*IndexStats, error) {
 		TotalFiles:     stats.TotalFiles,
 		TypeCounts:     stats.TypeCounts,
 		LanguageCounts: stats.LanguageCounts,
+		Tombstones:     idx.store.(*vectordb.HNSWStore).Tombstones(),
 	}, nil
 }
 
+// Compact rebuilds the vector index without deleted vectors
+func (idx *Indexer) Compact() (*vectordb.CompactStats, error) {
+	hnswStore, ok := idx.store.(*vectordb.HNSWStore)
+	if !ok {
+		return nil, fmt.Errorf("vector store does not support compaction")
+	}
+	return hnswStore.Compact()
+}
+
+// recallQueries is the number of sampled queries used to estimate the
+// recall of a quantized index
+const recallQueries = 20
+
+// QuantizationStats reports memory savings and estimated recall of a
+// quantized index, or nil when quantization is off
+func (idx *Indexer) QuantizationStats() (*vectordb.QuantizationStats, error) {
+	hnswStore, ok := idx.store.(*vectordb.HNSWStore)
+	if !ok {
+		return nil, nil
+	}
+	return hnswStore.QuantizationStats(recallQueries)
+}
+
+// maybeCompact compacts the vector index once enough vectors were deleted
+func (idx *Indexer) maybeCompact() {
+	hnswStore, ok := idx.store.(*vectordb.HNSWStore)
+	if !ok {
+		return
+	}
+	if _, err := hnswStore.MaybeCompact(); err != nil {
+		fmt.Printf("Warning: failed to compact index: %v\n", err)
+	}
+}
+
 // Clear clears the entire index
 func (idx *Indexer) Clear() error {
-	return idx.store.Clear()
+	if err := idx.store.Clear(); err != nil {
+		return err
+	}
+	if idx.lexical != nil {
+		return idx.lexical.Clear()
+	}
+	return nil
+}
+
+// insertChunks stores chunks in the vector store and the lexical index
+func (idx *Indexer) insertChunks(chunks []*chunk.Chunk, embeddings [][]float32) error {
+	if err := idx.store.InsertBatch(chunks, embeddings); err != nil {
+		return err
+	}
+	if idx.lexical != nil {
+		return idx.lexical.IndexChunks(chunks)
+	}
+	return nil
+}
+
+// deleteFile removes a file's chunks from the vector store and the lexical
+// index
+func (idx *Indexer) deleteFile(file string) error {
+	if err := idx.store.DeleteByFile(file); err != nil {
+		return err
+	}
+	if idx.lexical != nil {
+		return idx.lexical.DeleteByFile(file)
+	}
+	return nil
+}
+
+// backfillLexical fills an empty lexical index from the vector store
+func (idx *Indexer) backfillLexical(files []string) error {
+	if idx.lexical == nil || len(files) == 0 {
+		return nil
+	}
+	if n, err := idx.lexical.DocCount(); err != nil || n > 0 {
+		return err
+	}
+	for _, file := range files {
+		chunks, err := idx.store.GetByFile(file)
+		if err != nil {
+			return err
+		}
+		if err := idx.lexical.IndexChunks(chunks); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // Close closes the indexer and releases resources
@@ -1219,10 +1336,16 @@ func (idx *Indexer) Close() error {
 		fmt.Printf("Warning: failed to close provider: %v\n", err)
 	}
 
+	if idx.lexical != nil {
+		if err := idx.lexical.Close(); err != nil {
+			fmt.Printf("Warning: failed to close lexical index: %v\n", err)
+		}
+	}
+
 	return idx.store.Close()
 }
 
 // Searcher returns a searcher for this index
 func (idx *Indexer) Searcher() *Searcher {
-	return NewSearcher(idx.store, idx.provider)
+	return NewHybridSearcher(idx.store, idx.provider, idx.lexical)
 }
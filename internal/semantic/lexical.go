@@ -0,0 +1,283 @@
+package semantic
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/custom"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/keyword"
+	"github.com/blevesearch/bleve/v2/analysis/token/camelcase"
+	"github.com/blevesearch/bleve/v2/analysis/token/lowercase"
+	"github.com/blevesearch/bleve/v2/analysis/tokenizer/unicode"
+	"github.com/blevesearch/bleve/v2/mapping"
+	"github.com/blevesearch/bleve/v2/search/query"
+	"github.com/diffsec/quokka/internal/chunk"
+	"github.com/diffsec/quokka/internal/vectordb"
+)
+
+const (
+	// lexicalDir is the bleve index directory inside the vector store path
+	lexicalDir = "lexical"
+	// lexicalTimeout bounds a lexical query, like memory search
+	lexicalTimeout = 5 * time.Second
+	// lexicalOpenTimeout stops a second process (e.g. index watch) holding
+	// the index from blocking searches forever
+	lexicalOpenTimeout = "2s"
+	// codeAnalyzer keeps identifiers whole: verifyHMAC, X-Forwarded-For
+	codeAnalyzer = "code"
+	// codePartsAnalyzer also splits camelCase so "verify hmac" matches
+	codePartsAnalyzer = "code_parts"
+)
+
+// LexicalIndex is a BM25 full-text index over chunk names, signatures and
+// content. It sits next to the vector store so exact identifiers that
+// embeddings blur together can still be found.
+type LexicalIndex struct {
+	index bleve.Index
+	path  string
+	mu    sync.RWMutex
+}
+
+// lexicalDocument is the indexed form of a chunk
+type lexicalDocument struct {
+	File      string `json:"file"`
+	Language  string `json:"language"`
+	Type      string `json:"type"`
+	Name      string `json:"name"`
+	Signature string `json:"signature"`
+	Content   string `json:"content"`
+}
+
+// LexicalHit is one BM25 match
+type LexicalHit struct {
+	ID    string
+	Score float64
+}
+
+// NewLexicalIndex opens or creates the lexical index under storePath
+func NewLexicalIndex(storePath string) (*LexicalIndex, error) {
+	indexPath := filepath.Join(storePath, lexicalDir)
+	index, err := bleve.OpenUsing(indexPath, map[string]interface{}{"bolt_timeout": lexicalOpenTimeout})
+	if err == bleve.ErrorIndexPathDoesNotExist {
+		index, err = newLexicalBleve(indexPath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lexical index: %w", err)
+	}
+	return &LexicalIndex{index: index, path: indexPath}, nil
+}
+
+func newLexicalBleve(indexPath string) (bleve.Index, error) {
+	m, err := buildLexicalMapping()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(indexPath), 0755); err != nil {
+		return nil, err
+	}
+	return bleve.New(indexPath, m)
+}
+
+// buildLexicalMapping analyzes code fields with identifier-preserving
+// analyzers and keeps file/language/type as exact keywords for filtering
+func buildLexicalMapping() (mapping.IndexMapping, error) {
+	im := bleve.NewIndexMapping()
+	if err := im.AddCustomAnalyzer(codeAnalyzer, map[string]interface{}{
+		"type":          custom.Name,
+		"tokenizer":     unicode.Name,
+		"token_filters": []string{lowercase.Name},
+	}); err != nil {
+		return nil, err
+	}
+	if err := im.AddCustomAnalyzer(codePartsAnalyzer, map[string]interface{}{
+		"type":          custom.Name,
+		"tokenizer":     unicode.Name,
+		"token_filters": []string{camelcase.Name, lowercase.Name},
+	}); err != nil {
+		return nil, err
+	}
+
+	keywordField := bleve.NewTextFieldMapping()
+	keywordField.Analyzer = keyword.Name
+
+	codeField := bleve.NewTextFieldMapping()
+	codeField.Analyzer = codeAnalyzer
+	codeField.Store = false
+
+	partsField := bleve.NewTextFieldMapping()
+	partsField.Name = "parts"
+	partsField.Analyzer = codePartsAnalyzer
+	partsField.Store = false
+
+	doc := bleve.NewDocumentMapping()
+	doc.AddFieldMappingsAt("file", keywordField)
+	doc.AddFieldMappingsAt("language", keywordField)
+	doc.AddFieldMappingsAt("type", keywordField)
+	doc.AddFieldMappingsAt("name", codeField, partsField)
+	doc.AddFieldMappingsAt("signature", codeField)
+	doc.AddFieldMappingsAt("content", codeField, partsField)
+
+	im.DefaultMapping = doc
+	im.DefaultAnalyzer = codeAnalyzer
+	return im, nil
+}
+
+// IndexChunks adds or replaces chunks, keyed by chunk ID
+func (l *LexicalIndex) IndexChunks(chunks []*chunk.Chunk) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	batch := l.index.NewBatch()
+	for _, c := range chunks {
+		doc := lexicalDocument{
+			File:      c.File,
+			Language:  c.Language,
+			Type:      string(c.Type),
+			Name:      c.Name,
+			Signature: c.Signature,
+			Content:   c.Content,
+		}
+		if err := batch.Index(c.ID, doc); err != nil {
+			return fmt.Errorf("failed to index chunk %s: %w", c.ID, err)
+		}
+	}
+	return l.index.Batch(batch)
+}
+
+// DeleteByFile removes every chunk of a file
+func (l *LexicalIndex) DeleteByFile(file string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	q := bleve.NewTermQuery(file)
+	q.SetField("file")
+	for {
+		req := bleve.NewSearchRequest(q)
+		req.Size = 1000
+		res, err := l.index.Search(req)
+		if err != nil {
+			return err
+		}
+		if len(res.Hits) == 0 {
+			return nil
+		}
+		batch := l.index.NewBatch()
+		for _, hit := range res.Hits {
+			batch.Delete(hit.ID)
+		}
+		if err := l.index.Batch(batch); err != nil {
+			return err
+		}
+	}
+}
+
+// Search returns up to k chunks ranked by BM25. Filter file globs, types and
+// languages are applied; MinScore is a vector threshold and is ignored.
+func (l *LexicalIndex) Search(text string, k int, filter *vectordb.Filter) ([]LexicalHit, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	var fields []query.Query
+	for _, f := range []struct {
+		field string
+		boost float64
+	}{{"name", 3}, {"signature", 2}, {"content", 1}, {"parts", 0.5}} {
+		m := bleve.NewMatchQuery(text)
+		m.SetField(f.field)
+		m.SetBoost(f.boost)
+		fields = append(fields, m)
+	}
+	var q query.Query = bleve.NewDisjunctionQuery(fields...)
+	if filter != nil {
+		conj := []query.Query{q}
+		if fq := anyOf("file", filter.Files, true); fq != nil {
+			conj = append(conj, fq)
+		}
+		types := make([]string, len(filter.Types))
+		for i, t := range filter.Types {
+			types[i] = string(t)
+		}
+		if tq := anyOf("type", types, false); tq != nil {
+			conj = append(conj, tq)
+		}
+		if lq := anyOf("language", filter.Languages, false); lq != nil {
+			conj = append(conj, lq)
+		}
+		if len(conj) > 1 {
+			q = bleve.NewConjunctionQuery(conj...)
+		}
+	}
+
+	req := bleve.NewSearchRequest(q)
+	req.Size = k
+	ctx, cancel := context.WithTimeout(context.Background(), lexicalTimeout)
+	defer cancel()
+	res, err := l.index.SearchInContext(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("lexical search failed: %w", err)
+	}
+
+	hits := make([]LexicalHit, 0, len(res.Hits))
+	for _, h := range res.Hits {
+		hits = append(hits, LexicalHit{ID: h.ID, Score: h.Score})
+	}
+	return hits, nil
+}
+
+// anyOf matches any of values on a keyword field; glob enables * and ?
+func anyOf(field string, values []string, glob bool) query.Query {
+	if len(values) == 0 {
+		return nil
+	}
+	var qs []query.Query
+	for _, v := range values {
+		if glob {
+			w := bleve.NewWildcardQuery(v)
+			w.SetField(field)
+			qs = append(qs, w)
+		} else {
+			t := bleve.NewTermQuery(v)
+			t.SetField(field)
+			qs = append(qs, t)
+		}
+	}
+	return bleve.NewDisjunctionQuery(qs...)
+}
+
+// DocCount returns the number of indexed chunks
+func (l *LexicalIndex) DocCount() (uint64, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.index.DocCount()
+}
+
+// Clear drops and recreates the index
+func (l *LexicalIndex) Clear() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.index.Close(); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(l.path); err != nil {
+		return err
+	}
+	index, err := newLexicalBleve(l.path)
+	if err != nil {
+		return fmt.Errorf("failed to recreate lexical index: %w", err)
+	}
+	l.index = index
+	return nil
+}
+
+// Close closes the index
+func (l *LexicalIndex) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.index.Close()
+}
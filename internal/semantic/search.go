@@ -3,6 +3,8 @@ package semantic
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/diffsec/quokka/internal/chunk"
@@ -10,15 +12,56 @@ import (
 	"github.com/diffsec/quokka/internal/vectordb"
 )
 
+// SearchMode selects how candidates are retrieved
+type SearchMode string
+
+const (
+	// ModeHybrid fuses vector and lexical rankings with reciprocal-rank fusion
+	ModeHybrid SearchMode = "hybrid"
+	// ModeVector ranks by embedding cosine similarity only
+	ModeVector SearchMode = "vector"
+	// ModeLexical ranks by BM25 keyword relevance only
+	ModeLexical SearchMode = "lexical"
+)
+
+// SearchModes lists the supported search modes
+func SearchModes() []SearchMode {
+	return []SearchMode{ModeHybrid, ModeVector, ModeLexical}
+}
+
+// ParseSearchMode validates a --mode value; "" means hybrid
+func ParseSearchMode(s string) (SearchMode, error) {
+	if s == "" {
+		return ModeHybrid, nil
+	}
+	for _, m := range SearchModes() {
+		if string(m) == strings.ToLower(s) {
+			return m, nil
+		}
+	}
+	return "", fmt.Errorf("unknown search mode %q (expected hybrid, vector or lexical)", s)
+}
+
+// rrfK is the reciprocal-rank fusion constant from Cormack et al.; it damps
+// the weight of the very top ranks so neither retriever dominates
+const rrfK = 60
+
+// hybridCandidates is the minimum number of candidates fetched from each
+// retriever before fusion
+const hybridCandidates = 20
+
 // SearchOptions configures semantic search behavior
 type SearchOptions struct {
+	// Mode is hybrid (default), vector or lexical
+	Mode SearchMode
 	// Limit is the maximum number of results (default: 10)
 	Limit int
 	// MultiHop enables multi-hop exploration
 	MultiHop bool
 	// MaxHops is the maximum iterations for multi-hop (default: 3)
 	MaxHops int
-	// Threshold is the minimum similarity score (0-1)
+	// Threshold is the minimum vector similarity score (0-1); lexical
+	// matches are not subject to it
 	Threshold float32
 	// TimeLimit is the maximum search time (default: 5s)
 	TimeLimit time.Duration
@@ -29,6 +72,7 @@ type SearchOptions struct {
 // DefaultSearchOptions returns default search options
 func DefaultSearchOptions() *SearchOptions {
 	return &SearchOptions{
+		Mode:      ModeHybrid,
 		Limit:     10,
 		MultiHop:  false,
 		MaxHops:   3,
@@ -41,10 +85,23 @@ func DefaultSearchOptions() *SearchOptions {
 type SearchResult struct {
 	// Chunk is the matched code chunk
 	Chunk *chunk.Chunk `json:"chunk"`
-	// Score is the similarity score (0-1, higher is more similar)
+	// Score is the ranking score, higher is better: cosine similarity (0-1)
+	// in vector mode, BM25 in lexical mode, the fused RRF score in hybrid
 	Score float32 `json:"score"`
 	// Hop is the iteration this result was found in (for multi-hop)
 	Hop int `json:"hop,omitempty"`
+	// Scores breaks a hybrid score down by retriever
+	Scores *ScoreBreakdown `json:"scores,omitempty"`
+}
+
+// ScoreBreakdown shows how each retriever ranked a fused result. Ranks are
+// 1-based; 0 means the retriever did not return the chunk.
+type ScoreBreakdown struct {
+	Vector      float32 `json:"vector,omitempty"`
+	VectorRank  int     `json:"vector_rank,omitempty"`
+	Lexical     float64 `json:"lexical,omitempty"`
+	LexicalRank int     `json:"lexical_rank,omitempty"`
+	RRF         float64 `json:"rrf"`
 }
 
 // SearchResults contains search results with metadata
@@ -53,6 +110,10 @@ type SearchResults struct {
 	Results []*SearchResult `json:"results"`
 	// Query is the original query text
 	Query string `json:"query"`
+	// Mode is the retrieval mode actually used
+	Mode SearchMode `json:"mode,omitempty"`
+	// Notes explain degraded modes, e.g. a missing lexical index
+	Notes []string `json:"notes,omitempty"`
 	// TotalHops is the number of hops performed
 	TotalHops int `json:"total_hops,omitempty"`
 	// Duration is how long the search took
@@ -63,13 +124,21 @@ type SearchResults struct {
 type Searcher struct {
 	store    vectordb.Store
 	provider embedding.Provider
+	lexical  *LexicalIndex
 }
 
-// NewSearcher creates a new semantic searcher
+// NewSearcher creates a new vector-only semantic searcher
 func NewSearcher(store vectordb.Store, provider embedding.Provider) *Searcher {
+	return NewHybridSearcher(store, provider, nil)
+}
+
+// NewHybridSearcher creates a searcher that can also rank by keywords;
+// lexical may be nil, in which case hybrid search falls back to vector
+func NewHybridSearcher(store vectordb.Store, provider embedding.Provider, lexical *LexicalIndex) *Searcher {
 	return &Searcher{
 		store:    store,
 		provider: provider,
+		lexical:  lexical,
 	}
 }
 
@@ -88,28 +157,177 @@ func (s *Searcher) Search(ctx context.Context, query string, opts *SearchOptions
 		defer cancel()
 	}
 
-	// Generate query embedding
-	queryEmbedding, err := s.provider.Embed(ctx, query)
+	mode, err := ParseSearchMode(string(opts.Mode))
 	if err != nil {
-		return nil, fmt.Errorf("failed to embed query: %w", err)
+		return nil, err
+	}
+	var notes []string
+	if mode != ModeVector && !s.hasLexical() {
+		if mode == ModeLexical {
+			return nil, fmt.Errorf("lexical index is empty or unavailable; run 'quokka index update' to build it")
+		}
+		mode = ModeVector
+		notes = append(notes, "lexical index is empty or unavailable; using vector search (run 'quokka index update' to build it)")
+	}
+	if opts.MultiHop && mode != ModeVector {
+		mode = ModeVector
+		notes = append(notes, "multi-hop search is vector-only")
 	}
 
 	var results *SearchResults
-
-	if opts.MultiHop {
-		results, err = s.multiHopSearch(ctx, query, queryEmbedding, opts)
+	if mode == ModeLexical {
+		results, err = s.lexicalSearch(query, opts)
 	} else {
-		results, err = s.singleHopSearch(ctx, query, queryEmbedding, opts)
+		// Generate query embedding
+		queryEmbedding, embedErr := s.provider.Embed(ctx, query)
+		if embedErr != nil {
+			return nil, fmt.Errorf("failed to embed query: %w", embedErr)
+		}
+
+		switch {
+		case opts.MultiHop:
+			results, err = s.multiHopSearch(ctx, query, queryEmbedding, opts)
+		case mode == ModeHybrid:
+			results, err = s.hybridSearch(ctx, query, queryEmbedding, opts)
+		default:
+			results, err = s.singleHopSearch(ctx, query, queryEmbedding, opts)
+		}
 	}
 
 	if err != nil {
 		return nil, err
 	}
 
+	results.Mode = mode
+	results.Notes = append(notes, results.Notes...)
 	results.Duration = time.Since(startTime)
 	return results, nil
 }
 
+// hasLexical reports whether a non-empty lexical index is attached
+func (s *Searcher) hasLexical() bool {
+	if s.lexical == nil {
+		return false
+	}
+	n, err := s.lexical.DocCount()
+	return err == nil && n > 0
+}
+
+// lexicalSearch ranks chunks by BM25 only
+func (s *Searcher) lexicalSearch(query string, opts *SearchOptions) (*SearchResults, error) {
+	hits, err := s.lexical.Search(query, opts.Limit, opts.Filter)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*SearchResult, 0, len(hits))
+	for _, h := range hits {
+		c, err := s.store.Get(h.ID)
+		if err != nil || c == nil {
+			continue
+		}
+		results = append(results, &SearchResult{
+			Chunk: c,
+			Score: float32(h.Score),
+			Hop:   1,
+		})
+	}
+	return &SearchResults{
+		Results:   results,
+		Query:     query,
+		TotalHops: 1,
+	}, nil
+}
+
+// hybridSearch fetches candidates from both retrievers and fuses them
+func (s *Searcher) hybridSearch(ctx context.Context, query string, queryEmbedding []float32, opts *SearchOptions) (*SearchResults, error) {
+	k := max(opts.Limit*4, hybridCandidates)
+	candidates := *opts
+	candidates.Limit = k
+	vector, err := s.singleHopSearch(ctx, query, queryEmbedding, &candidates)
+	if err != nil {
+		return nil, err
+	}
+	hits, err := s.lexical.Search(query, k, opts.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	fused := fuseRRF(vector.Results, hits, opts.Limit)
+	results := make([]*SearchResult, 0, len(fused))
+	for _, r := range fused {
+		if r.Chunk == nil {
+			c, err := s.store.Get(r.Scores.lexicalID)
+			if err != nil || c == nil {
+				continue
+			}
+			r.Chunk = c
+		}
+		results = append(results, &SearchResult{
+			Chunk:  r.Chunk,
+			Score:  float32(r.Scores.RRF),
+			Hop:    1,
+			Scores: &r.Scores.ScoreBreakdown,
+		})
+	}
+
+	return &SearchResults{
+		Results:   results,
+		Query:     query,
+		TotalHops: 1,
+	}, nil
+}
+
+// fusedScore is a ScoreBreakdown plus the chunk ID of a lexical-only hit,
+// whose chunk still has to be loaded
+type fusedScore struct {
+	ScoreBreakdown
+	lexicalID string
+}
+
+// fusedResult is one entry of an RRF ranking
+type fusedResult struct {
+	Chunk  *chunk.Chunk
+	Scores *fusedScore
+}
+
+// fuseRRF merges a vector and a lexical ranking with reciprocal-rank fusion:
+// each chunk scores the sum of 1/(rrfK+rank) over the rankings it appears
+// in. Ties keep the vector order, then the lexical order.
+func fuseRRF(vector []*SearchResult, lexical []LexicalHit, limit int) []fusedResult {
+	byID := make(map[string]int)
+	var out []fusedResult
+	for i, r := range vector {
+		rank := i + 1
+		byID[r.Chunk.ID] = len(out)
+		out = append(out, fusedResult{Chunk: r.Chunk, Scores: &fusedScore{ScoreBreakdown: ScoreBreakdown{
+			Vector:     r.Score,
+			VectorRank: rank,
+			RRF:        1.0 / float64(rrfK+rank),
+		}}})
+	}
+	for i, h := range lexical {
+		rank := i + 1
+		idx, ok := byID[h.ID]
+		if !ok {
+			idx = len(out)
+			byID[h.ID] = idx
+			out = append(out, fusedResult{Scores: &fusedScore{lexicalID: h.ID}})
+		}
+		sc := out[idx].Scores
+		sc.Lexical = h.Score
+		sc.LexicalRank = rank
+		sc.RRF += 1.0 / float64(rrfK+rank)
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Scores.RRF > out[j].Scores.RRF
+	})
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
+
 // singleHopSearch performs a simple single-hop semantic search
 func (s *Searcher) singleHopSearch(ctx context.Context, query string, queryEmbedding []float32, opts *SearchOptions) (*SearchResults, error) {
 	// Search vector store
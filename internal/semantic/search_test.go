@@ -0,0 +1,108 @@
+package semantic
+
+import (
+	"context"
+	"testing"
+
+	"github.com/diffsec/quokka/internal/chunk"
+	"github.com/diffsec/quokka/internal/vectordb"
+)
+
+func TestParseSearchMode(t *testing.T) {
+	for in, want := range map[string]SearchMode{"": ModeHybrid, "hybrid": ModeHybrid, "Vector": ModeVector, "lexical": ModeLexical} {
+		got, err := ParseSearchMode(in)
+		if err != nil || got != want {
+			t.Errorf("ParseSearchMode(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseSearchMode("bm25"); err == nil {
+		t.Error("ParseSearchMode(bm25) should fail")
+	}
+}
+
+func TestFuseRRF(t *testing.T) {
+	a := &chunk.Chunk{ID: "a"}
+	b := &chunk.Chunk{ID: "b"}
+	vector := []*SearchResult{{Chunk: a, Score: 0.9}, {Chunk: b, Score: 0.8}}
+	lexical := []LexicalHit{{ID: "c", Score: 12}, {ID: "b", Score: 7}}
+
+	fused := fuseRRF(vector, lexical, 10)
+	if len(fused) != 3 {
+		t.Fatalf("got %d results, want 3", len(fused))
+	}
+	// b is ranked by both retrievers, so it must beat the single-list tops
+	if fused[0].Chunk != b {
+		t.Errorf("first result = %+v, want chunk b", fused[0])
+	}
+	s := fused[0].Scores
+	if s.VectorRank != 2 || s.LexicalRank != 2 || s.Vector != 0.8 || s.Lexical != 7 {
+		t.Errorf("breakdown for b = %+v", s.ScoreBreakdown)
+	}
+	if want := 2.0 / float64(rrfK+2); s.RRF != want {
+		t.Errorf("rrf for b = %v, want %v", s.RRF, want)
+	}
+	// a (vector #1) ties c (lexical #1); vector order wins the tie
+	if fused[1].Chunk != a || fused[2].Scores.lexicalID != "c" || fused[2].Chunk != nil {
+		t.Errorf("tie order = %+v, %+v", fused[1], fused[2])
+	}
+
+	if got := fuseRRF(vector, lexical, 1); len(got) != 1 {
+		t.Errorf("limit 1 returned %d results", len(got))
+	}
+}
+
+func TestLexicalSearchFindsIdentifiers(t *testing.T) {
+	dir := t.TempDir()
+	store, err := vectordb.NewHNSWStore(vectordb.DefaultStoreConfig(dir, 4))
+	if err != nil {
+		t.Fatalf("hnsw store: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+	lexical, err := NewLexicalIndex(dir)
+	if err != nil {
+		t.Fatalf("lexical index: %v", err)
+	}
+	defer func() { _ = lexical.Close() }()
+
+	idx := &Indexer{store: store, lexical: lexical}
+	chunks := []*chunk.Chunk{
+		{ID: "1", File: "auth/hmac.go", Language: "go", Type: chunk.ChunkFunction, Name: "verifyHMAC",
+			Content: "func verifyHMAC(sig, body []byte) bool { return hmac.Equal(sig, mac(body)) }"},
+		{ID: "2", File: "web/proxy.py", Language: "python", Type: chunk.ChunkFunction, Name: "client_ip",
+			Content: "def client_ip(request):\n    return request.headers.get('X-Forwarded-For')"},
+		{ID: "3", File: "web/views.py", Language: "python", Type: chunk.ChunkFunction, Name: "index",
+			Content: "def index(request):\n    return render(request, 'index.html')"},
+	}
+	embeddings := [][]float32{{1, 0, 0, 0}, {0, 1, 0, 0}, {0, 0, 1, 0}}
+	if err := idx.insertChunks(chunks, embeddings); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+
+	searcher := idx.Searcher()
+	opts := DefaultSearchOptions()
+	opts.Mode = ModeLexical
+	for query, want := range map[string]string{"verifyHMAC": "1", "X-Forwarded-For": "2", "verify hmac": "1"} {
+		res, err := searcher.Search(context.Background(), query, opts)
+		if err != nil {
+			t.Fatalf("search %q: %v", query, err)
+		}
+		if len(res.Results) == 0 || res.Results[0].Chunk.ID != want {
+			t.Errorf("search %q: want chunk %s first, got %+v", query, want, res.Results)
+		}
+	}
+
+	opts.Filter = &vectordb.Filter{Languages: []string{"go"}}
+	res, err := searcher.Search(context.Background(), "X-Forwarded-For", opts)
+	if err != nil || len(res.Results) != 0 {
+		t.Errorf("language filter: got %+v, %v", res, err)
+	}
+
+	if err := idx.deleteFile("auth/hmac.go"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	opts.Filter = nil
+	res, err = searcher.Search(context.Background(), "verifyHMAC", opts)
+	if err != nil || len(res.Results) != 0 {
+		t.Errorf("after delete: got %+v, %v", res, err)
+	}
+}
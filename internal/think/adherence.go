@@ -75,6 +75,7 @@ func AnalyzeAdherence(p *project.Project, opts AdherenceOptions) (*AdherenceRepo
 
 	// List findings.
 	store := finding.NewStore(p)
+	defer func() { _ = store.Close() }()
 	list, err := store.List(nil)
 	if err != nil {
 		return nil, fmt.Errorf("list findings: %w", err)
@@ -11,7 +11,7 @@ func TestAnalyzeAdherence_InAndOutOfScope(t *testing.T) {
 	store := finding.NewStore(p)
 
 	// in-scope: injection-agent owns CWE-89
-	if err := store.Create(&finding.Finding{
+	if _, err := store.Create(&finding.Finding{
 		Title:    "in-scope",
 		Severity: finding.SeverityHigh,
 		Status:   finding.StatusOpen,
@@ -22,7 +22,7 @@ func TestAnalyzeAdherence_InAndOutOfScope(t *testing.T) {
 		t.Fatalf("create in: %v", err)
 	}
 	// out-of-scope: injection-agent does not own CWE-79
-	if err := store.Create(&finding.Finding{
+	if _, err := store.Create(&finding.Finding{
 		Title:    "out-of-scope",
 		Severity: finding.SeverityMedium,
 		Status:   finding.StatusOpen,
@@ -32,13 +32,23 @@ type DataflowOptions struct {
 	FromFinding string
 	// MaxChains caps reported chains per file (default 8).
 	MaxChains int
+	// Engine selects the tracer: EngineRegex (default, line-by-line within
+	// one file) or EngineTaint (AST def-use graphs, follows calls across
+	// files).
+	Engine string
+	// MaxDepth bounds the call depth followed by the taint engine
+	// (default 6). Ignored by the regex engine.
+	MaxDepth int
 }
 
-// DataflowChain represents one source-to-sink trace within a single file.
+// DataflowChain represents one source-to-sink trace. File holds the source;
+// SinkFile is set only when the taint engine followed the flow into
+// another file.
 type DataflowChain struct {
 	File        string         `json:"file"`
 	SourceLine  int            `json:"source_line"`
 	SourceCode  string         `json:"source_code"`
+	SinkFile    string         `json:"sink_file,omitempty"`
 	SinkLine    int            `json:"sink_line"`
 	SinkCode    string         `json:"sink_code"`
 	Assignments []FlowStep     `json:"assignments,omitempty"`
@@ -48,18 +58,21 @@ type DataflowChain struct {
 	Reasoning   string         `json:"reasoning"`
 }
 
-// FlowStep is one intermediate line between source and sink.
+// FlowStep is one intermediate line between source and sink. File is empty
+// when the step is in the chain's source file.
 type FlowStep struct {
+	File     string `json:"file,omitempty"`
 	Line     int    `json:"line"`
 	Code     string `json:"code"`
 	Variable string `json:"variable,omitempty"`
-	Kind     string `json:"kind,omitempty"` // "assignment" | "guard"
+	Kind     string `json:"kind,omitempty"` // "assignment" | "guard" | "call" | "return"
 }
 
 // DataflowReport is the structured result of a dataflow analysis.
 type DataflowReport struct {
 	Source       string          `json:"source"`
 	Sink         string          `json:"sink"`
+	Engine       string          `json:"engine,omitempty"`
 	SinkClasses  []string        `json:"sink_classes,omitempty"`
 	File         string          `json:"file,omitempty"`
 	FromFinding  string          `json:"from_finding,omitempty"`
@@ -106,18 +119,35 @@ var guardPatterns = regexp.MustCompile(
 // assignmentRE matches a simple python/JS-ish variable assignment.
 var assignmentRE = regexp.MustCompile(`^\s*([A-Za-z_][A-Za-z_0-9]*)\s*=[^=]`)
 
-// AnalyzeDataflow performs an intra-file source-to-sink trace across the
-// project (or a single file). It is intentionally simple: regex-based,
-// linear within each file, and reports guards observed between the source
-// and sink lines.
+// AnalyzeDataflow performs a source-to-sink trace across the project (or a
+// single file). The default regex engine is intentionally simple: linear
+// within each file, reporting guards observed between the source and sink
+// lines. EngineTaint instead builds per-function def-use graphs from the
+// tree-sitter AST and follows calls across files.
 func AnalyzeDataflow(p *project.Project, opts DataflowOptions) (*DataflowReport, error) {
 	if opts.MaxChains <= 0 {
 		opts.MaxChains = 8
 	}
+	switch opts.Engine {
+	case "":
+		opts.Engine = EngineRegex
+	case EngineRegex, EngineTaint:
+	default:
+		return nil, fmt.Errorf("unknown dataflow engine %q (want %s or %s)", opts.Engine, EngineRegex, EngineTaint)
+	}
+
+	model, err := LoadTaintModel(p)
+	if err != nil {
+		return nil, err
+	}
+	// The taint model extends inferred and class-derived patterns; an
+	// explicit --source or --sink is used as given.
+	patterns := &patternSet{model: model, modelSources: opts.Source == ""}
 
 	// If a finding is named, hydrate source/sink/file from it.
 	if opts.FromFinding != "" {
 		store := finding.NewStore(p)
+		defer func() { _ = store.Close() }()
 		f, err := store.Read(opts.FromFinding)
 		if err != nil {
 			return nil, fmt.Errorf("from-finding: %w", err)
@@ -130,33 +160,58 @@ func AnalyzeDataflow(p *project.Project, opts DataflowOptions) (*DataflowReport,
 		}
 		if opts.Sink == "" {
 			opts.Sink = inferSinkFromFinding(f)
+			patterns.sinkClasses = sinkClassesForFinding(f)
+		}
+		if f.CWE != "" {
+			patterns.cwes = []string{strings.ToUpper(f.CWE)}
 		}
 	}
 
 	// Resolve the sink pattern when none was given explicitly.
 	// Precedence: explicit Sink > named SinkClasses > default class mix.
+	// Classes declared only by the taint model are known, not unknown.
 	var unknownClasses []string
 	resolvedClasses := opts.SinkClasses
 	if opts.Sink == "" {
 		var pat string
 		pat, unknownClasses = BuildSinkPatternFromClasses(opts.SinkClasses)
 		opts.Sink = pat
-		if len(opts.SinkClasses) == 0 {
-			resolvedClasses = DefaultSinkClasses()
+		resolvedClasses = splitClassNames(opts.SinkClasses)
+		if len(resolvedClasses) == 0 {
+			resolvedClasses = append(DefaultSinkClasses(), model.customSinkClasses()...)
+		}
+		kept := unknownClasses[:0]
+		for _, u := range unknownClasses {
+			if !model.hasSinkClass(u) {
+				kept = append(kept, u)
+			}
 		}
+		unknownClasses = kept
+		patterns.sinkClasses = resolvedClasses
+		patterns.cwes = cwesForClasses(resolvedClasses)
+	}
+	patterns.source, patterns.sink = opts.Source, opts.Sink
+
+	if _, err := regexp.Compile("(?i)" + opts.Source); err != nil {
+		return nil, fmt.Errorf("invalid source pattern: %w", err)
 	}
+	if _, err := regexp.Compile("(?i)" + opts.Sink); err != nil {
+		return nil, fmt.Errorf("invalid sink pattern: %w", err)
+	}
+	opts.Source, opts.Sink = patterns.union()
 
 	if opts.Source == "" || opts.Sink == "" {
 		if len(unknownClasses) > 0 {
 			return nil, fmt.Errorf("no valid sink classes resolved (unknown: %s); use --sink, --sink-class <name>, or --from-finding",
 				strings.Join(unknownClasses, ", "))
 		}
-		return nil, fmt.Errorf("source and sink patterns are required (use --source/--sink or --from-finding)")
+		return nil, fmt.Errorf("source and sink patterns are required (use --source/--sink, --from-finding, or declare them in %s)", taintModelFile)
 	}
 
 	report := &DataflowReport{
 		Source:      opts.Source,
 		Sink:        opts.Sink,
+		Engine:      opts.Engine,
 		SinkClasses: resolvedClasses,
 		File:        opts.File,
 		FromFinding: opts.FromFinding,
@@ -164,14 +219,16 @@ func AnalyzeDataflow(p *project.Project, opts DataflowOptions) (*DataflowReport,
 	for _, u := range unknownClasses {
 		report.Notes = append(report.Notes, fmt.Sprintf("unknown sink class: %q (see ListSinkClasses)", u))
 	}
-
-	srcRE, err := regexp.Compile("(?i)" + opts.Source)
-	if err != nil {
-		return nil, fmt.Errorf("invalid source pattern: %w", err)
+	if model != nil {
+		report.Notes = append(report.Notes, fmt.Sprintf("merged custom taint model %s", filepath.Join(".quokka", taintModelFile)))
 	}
-	sinkRE, err := regexp.Compile("(?i)" + opts.Sink)
-	if err != nil {
-		return nil, fmt.Errorf("invalid sink pattern: %w", err)
+
+	if opts.Engine == EngineTaint {
+		if err := analyzeDataflowTaint(p, opts, report, patterns); err != nil {
+			return nil, err
+		}
+		report.tally()
+		return report, nil
 	}
 
 	// Collect files to analyze.
@@ -210,7 +267,14 @@ func AnalyzeDataflow(p *project.Project, opts DataflowOptions) (*DataflowReport,
 		if !filepath.IsAbs(fullPath) {
 			fullPath = filepath.Join(p.RootPath, relPath)
 		}
-		chains, fileSrc, fileSink, err := traceFile(fullPath, relPath, srcRE, sinkRE, opts.MaxChains)
+		tp, err := patterns.forFile(relPath)
+		if err != nil {
+			return nil, err
+		}
+		if tp.src == nil || tp.sink == nil {
+			continue
+		}
+		chains, fileSrc, fileSink, err := traceFile(fullPath, relPath, tp, opts.MaxChains)
 		if err != nil {
 			report.Notes = append(report.Notes, fmt.Sprintf("%s: %v", relPath, err))
 			continue
@@ -220,26 +284,30 @@ func AnalyzeDataflow(p *project.Project, opts DataflowOptions) (*DataflowReport,
 		report.Summary.Sinks += fileSink
 	}
 
-	for _, c := range report.Chains {
+	report.tally()
+	return report, nil
+}
+
+// tally fills the verdict counts of the report summary from its chains.
+func (r *DataflowReport) tally() {
+	for _, c := range r.Chains {
 		switch c.Verdict {
 		case "guarded":
-			report.Summary.Guarded++
+			r.Summary.Guarded++
 		case "guard-uncertain":
-			report.Summary.Uncertain++
+			r.Summary.Uncertain++
 		default:
-			report.Summary.Unguarded++
+			r.Summary.Unguarded++
 		}
 	}
-	report.Summary.Chains = len(report.Chains)
-
-	return report, nil
+	r.Summary.Chains = len(r.Chains)
 }
 
 // traceFile walks one file and emits chains pairing each source occurrence
 // with the next sink occurrence after it. It also returns the total source
 // and sink line counts seen in the file (after filtering imports), so the
 // caller can populate report-level Summary tallies.
-func traceFile(fullPath, relPath string, srcRE, sinkRE *regexp.Regexp, maxChains int) ([]DataflowChain, int, int, error) {
+func traceFile(fullPath, relPath string, tp *taintPatterns, maxChains int) ([]DataflowChain, int, int, error) {
 	f, err := os.Open(fullPath)
 	if err != nil {
 		return nil, 0, 0, err
@@ -265,10 +333,10 @@ func traceFile(fullPath, relPath string, srcRE, sinkRE *regexp.Regexp, maxChains
 		if isImportLine(line) {
 			continue
 		}
-		if srcRE.MatchString(line) {
+		if tp.src.MatchString(line) {
 			srcLines = append(srcLines, i)
 		}
-		if sinkRE.MatchString(line) {
+		if tp.sink.MatchString(line) {
 			sinkLines = append(sinkLines, i)
 		}
 	}
@@ -311,7 +379,7 @@ func traceFile(fullPath, relPath string, srcRE, sinkRE *regexp.Regexp, maxChains
 					Kind:     "assignment",
 				})
 			}
-			if guardPatterns.MatchString(ln) {
+			if tp.guard.MatchString(ln) {
 				chain.Guards = append(chain.Guards, FlowStep{
 					Line: j + 1,
 					Code: trimmed,
@@ -322,7 +390,7 @@ func traceFile(fullPath, relPath string, srcRE, sinkRE *regexp.Regexp, maxChains
 
 		// Also check source and sink lines themselves for inline guards
 		// (e.g. shlex.quote(arg) right in the call site).
-		if guardPatterns.MatchString(lines[sinkIdx]) {
+		if tp.guard.MatchString(lines[sinkIdx]) {
 			chain.Guards = append(chain.Guards, FlowStep{
 				Line: sinkIdx + 1,
 				Code: strings.TrimSpace(lines[sinkIdx]),
@@ -330,44 +398,7 @@ func traceFile(fullPath, relPath string, srcRE, sinkRE *regexp.Regexp, maxChains
 			})
 		}
 
-		// C3: parameterized-call-at-sink detection. A sink call with >=2
-		// top-level positional args is treated as guarded ("parameterized
-		// call at sink"). A sink call with exactly 1 arg that contains an
-		// f-string or string concatenation is treated as unguarded
-		// regardless of other guard-shaped calls. Otherwise we fall back
-		// to the legacy guard-list verdict.
-		paramVerdict, paramReason := classifySinkArgs(lines[sinkIdx], sinkRE)
-		switch paramVerdict {
-		case "guarded":
-			chain.Verdict = "guarded"
-			chain.Confidence = "medium"
-			chain.Reasoning = paramReason
-			// Surface the sink line as a guard for transparency.
-			chain.Guards = append(chain.Guards, FlowStep{
-				Line: sinkIdx + 1,
-				Code: strings.TrimSpace(lines[sinkIdx]),
-				Kind: "guard",
-			})
-		case "unguarded":
-			chain.Verdict = "unguarded"
-			chain.Confidence = "high"
-			chain.Reasoning = paramReason
-		default:
-			switch {
-			case len(chain.Guards) == 0:
-				chain.Verdict = "unguarded"
-				chain.Confidence = "medium"
-				chain.Reasoning = "no guard-like call found between source and sink"
-			case len(chain.Guards) > 0 && containsEffectiveGuard(chain.Guards):
-				chain.Verdict = "guarded"
-				chain.Confidence = "low"
-				chain.Reasoning = fmt.Sprintf("%d guard-like call(s) found; verify each is effective for this CWE", len(chain.Guards))
-			default:
-				chain.Verdict = "guard-uncertain"
-				chain.Confidence = "low"
-				chain.Reasoning = "guard-shaped calls present but effectiveness unclear; manual review needed"
-			}
-		}
+		applyVerdict(&chain, lines[sinkIdx], tp.sink, tp.sanitizer)
 
 		chains = append(chains, chain)
 		if len(chains) >= maxChains {
@@ -378,6 +409,79 @@ func traceFile(fullPath, relPath string, srcRE, sinkRE *regexp.Regexp, maxChains
 	return chains, len(srcLines), len(sinkLines), nil
 }
 
+// applyVerdict sets a chain's verdict, confidence and reasoning from its
+// guards and the sink call's arguments. sinkCode is the sink line (or full
+// call text) matched by sinkRE.
+//
+// C3: parameterized-call-at-sink detection. A sink call with >=2 top-level
+// positional args is treated as guarded ("parameterized call at sink"). A
+// sink call with exactly 1 arg that contains an f-string or string
+// concatenation is treated as unguarded regardless of other guard-shaped
+// calls. Otherwise we fall back to the legacy guard-list verdict, in which
+// sanitizers declared by the project's taint model count as effective.
+func applyVerdict(chain *DataflowChain, sinkCode string, sinkRE, sanitizer *regexp.Regexp) {
+	paramVerdict, paramReason := classifySinkArgs(sinkCode, sinkRE)
+	switch paramVerdict {
+	case "guarded":
+		chain.Verdict = "guarded"
+		chain.Confidence = "medium"
+		chain.Reasoning = paramReason
+		// Surface the sink line as a guard for transparency.
+		chain.Guards = append(chain.Guards, FlowStep{
+			File: chain.SinkFile,
+			Line: chain.SinkLine,
+			Code: chain.SinkCode,
+			Kind: "guard",
+		})
+	case "unguarded":
+		chain.Verdict = "unguarded"
+		chain.Confidence = "high"
+		chain.Reasoning = paramReason
+	default:
+		switch {
+		case len(chain.Guards) == 0:
+			chain.Verdict = "unguarded"
+			chain.Confidence = "medium"
+			chain.Reasoning = "no guard-like call found between source and sink"
+		case len(chain.Guards) > 0 && containsEffectiveGuard(chain.Guards, sanitizer):
+			chain.Verdict = "guarded"
+			chain.Confidence = "low"
+			chain.Reasoning = fmt.Sprintf("%d guard-like call(s) found; verify each is effective for this CWE", len(chain.Guards))
+		default:
+			chain.Verdict = "guard-uncertain"
+			chain.Confidence = "low"
+			chain.Reasoning = "guard-shaped calls present but effectiveness unclear; manual review needed"
+		}
+	}
+}
+
+// FlowTrace converts the chain into the finding.FlowTrace shape stored on
+// findings, rendering each step as "file:line: code".
+func (c DataflowChain) FlowTrace() *finding.FlowTrace {
+	at := func(file string, line int, code string) string {
+		if file == "" {
+			file = c.File
+		}
+		return fmt.Sprintf("%s:%d: %s", file, line, code)
+	}
+	sinkFile := c.SinkFile
+	if sinkFile == "" {
+		sinkFile = c.File
+	}
+	ft := &finding.FlowTrace{
+		Source:    at(c.File, c.SourceLine, c.SourceCode),
+		Sink:      at(sinkFile, c.SinkLine, c.SinkCode),
+		Unguarded: c.Verdict == "unguarded",
+	}
+	for _, s := range c.Assignments {
+		ft.Path = append(ft.Path, at(s.File, s.Line, s.Code))
+	}
+	for _, g := range c.Guards {
+		ft.Guards = append(ft.Guards, at(g.File, g.Line, g.Code))
+	}
+	return ft
+}
+
 // isImportLine reports whether a line is a Python-style import statement
 // (`import X` or `from X import Y`). Whitespace at the start is ignored.
 func isImportLine(line string) bool {
@@ -596,11 +700,12 @@ func hasStringConcat(arg string) bool {
 
 // containsEffectiveGuard returns true if any guard looks substantive
 // (validation, sanitization, parameterization) rather than a mere
-// shape-check (startswith, endswith, isalnum).
-func containsEffectiveGuard(guards []FlowStep) bool {
+// shape-check (startswith, endswith, isalnum). A non-nil sanitizer adds the
+// taint model's declared sanitizers to the substantive set.
+func containsEffectiveGuard(guards []FlowStep, sanitizer *regexp.Regexp) bool {
 	strong := regexp.MustCompile(`(?i)(\b(validate|sanitize|escape|safe_load|allowlist|whitelist|bleach|html\.escape|shlex)\s*\(|\bparameteriz)`)
 	for _, g := range guards {
-		if strong.MatchString(g.Code) {
+		if strong.MatchString(g.Code) || (sanitizer != nil && sanitizer.MatchString(g.Code)) {
 			return true
 		}
 	}
@@ -615,17 +720,18 @@ func uniqueFiles(matches []navigate.SearchMatch) map[string]bool {
 	return out
 }
 
+// defaultRequestSource is the request-source alternation layered into every
+// source inferred from a finding. It always includes headers and the common
+// urllib.parse.unquote_plus wrapper since both appear verbatim in OWASP
+// benchmark fixtures and would otherwise miss.
+const defaultRequestSource = `request\.(form|args|cookies|headers|json|values)\.get|request\.data|urllib\.parse\.unquote_plus\s*\(\s*request\.`
+
 // inferSourceFromFinding tries to extract a source pattern from a finding's
 // description. We look for "SOURCE:" lines first; if that yields a literal
 // call expression we use it, then layer in common wrappers (request.headers,
 // urllib.parse.unquote_plus(request....), `wrapped.get_*_parameter`) so the
 // resulting pattern matches what the OWASP fixtures actually do.
 func inferSourceFromFinding(f *finding.Finding) string {
-	// Default request-source alternation. Always includes headers and the
-	// common urllib.parse.unquote_plus wrapper since both appear verbatim
-	// in OWASP benchmark fixtures and would otherwise miss.
-	defaultRequestSrc := `request\.(form|args|cookies|headers|json|values)\.get|request\.data|urllib\.parse\.unquote_plus\s*\(\s*request\.`
-
 	var parts []string
 	if pat := extractAfter(f.Description, "SOURCE:"); pat != "" {
 		parts = append(parts, regexCleanup(pat))
@@ -641,7 +747,7 @@ func inferSourceFromFinding(f *finding.Finding) string {
 		parts = append(parts, pat)
 	}
 
-	parts = append(parts, defaultRequestSrc)
+	parts = append(parts, defaultRequestSource)
 	return strings.Join(uniqueNonEmpty(parts), "|")
 }
 
@@ -689,19 +795,7 @@ func inferSinkFromFinding(f *finding.Finding) string {
 	}
 	// Fallback by CWE — reuse the named sink classes so additions there
 	// (e.g. new deserialization formats) automatically flow through.
-	cweToClass := map[string]string{
-		"CWE-89":  "sqli",
-		"CWE-78":  "cmdi",
-		"CWE-94":  "codeexec",
-		"CWE-502": "deserialization",
-		"CWE-611": "xxe",
-		"CWE-643": "xpath",
-		"CWE-90":  "ldap",
-		"CWE-79":  "xss",
-		"CWE-601": "redirect",
-		"CWE-22":  "pathtrav",
-	}
-	if cls, ok := cweToClass[strings.ToUpper(f.CWE)]; ok {
+	if cls, ok := cweSinkClasses[strings.ToUpper(f.CWE)]; ok {
 		if pat := SinkClassPattern(cls); pat != "" {
 			return pat
 		}
@@ -709,6 +803,20 @@ func inferSinkFromFinding(f *finding.Finding) string {
 	return DefaultSinkPattern()
 }
 
+// sinkClassesForFinding returns the sink classes behind inferSinkFromFinding:
+// none when the description names its sink, the CWE's class when known, and
+// the default mix otherwise. The taint model's sinks of these classes extend
+// the inferred pattern.
+func sinkClassesForFinding(f *finding.Finding) []string {
+	if extractAfter(f.Description, "SINK:") != "" {
+		return nil
+	}
+	if cls, ok := cweSinkClasses[strings.ToUpper(f.CWE)]; ok {
+		return []string{cls}
+	}
+	return DefaultSinkClasses()
+}
+
 // extractAfter returns the first call-shape token after the given label on
 // any line that begins (after trim) with that label.
 //
@@ -764,6 +872,9 @@ func RenderDataflowText(r *DataflowReport) string {
 		r.Summary.Unguarded, r.Summary.Guarded, r.Summary.Uncertain)
 	fmt.Fprintf(&b, "Source pattern: %s\n", r.Source)
 	fmt.Fprintf(&b, "Sink pattern:   %s\n", r.Sink)
+	if r.Engine != "" {
+		fmt.Fprintf(&b, "Engine:         %s\n", r.Engine)
+	}
 	if len(r.SinkClasses) > 0 {
 		fmt.Fprintf(&b, "Sink classes:   %s\n", strings.Join(r.SinkClasses, ", "))
 	}
@@ -788,12 +899,23 @@ func RenderDataflowText(r *DataflowReport) string {
 		fmt.Fprintf(&b, "### Chain %d: %s\n", i+1, c.File)
 		fmt.Fprintf(&b, "  SOURCE: line %d: %s\n", c.SourceLine, c.SourceCode)
 		for _, a := range c.Assignments {
-			fmt.Fprintf(&b, "    -> ASSIGN line %d (%s): %s\n", a.Line, a.Variable, a.Code)
+			switch a.Kind {
+			case "call":
+				fmt.Fprintf(&b, "    -> CALL   %s (%s): %s\n", stepLocation(a), a.Variable, a.Code)
+			case "return":
+				fmt.Fprintf(&b, "    -> RETURN %s: %s\n", stepLocation(a), a.Code)
+			default:
+				fmt.Fprintf(&b, "    -> ASSIGN %s (%s): %s\n", stepLocation(a), a.Variable, a.Code)
+			}
 		}
 		for _, g := range c.Guards {
-			fmt.Fprintf(&b, "    -> GUARD?  line %d: %s\n", g.Line, g.Code)
+			fmt.Fprintf(&b, "    -> GUARD?  %s: %s\n", stepLocation(g), g.Code)
+		}
+		if c.SinkFile != "" {
+			fmt.Fprintf(&b, "    -> SINK: %s line %d: %s\n", c.SinkFile, c.SinkLine, c.SinkCode)
+		} else {
+			fmt.Fprintf(&b, "    -> SINK: line %d: %s\n", c.SinkLine, c.SinkCode)
 		}
-		fmt.Fprintf(&b, "    -> SINK: line %d: %s\n", c.SinkLine, c.SinkCode)
 		fmt.Fprintf(&b, "  VERDICT: %s (confidence: %s)\n", c.Verdict, c.Confidence)
 		fmt.Fprintf(&b, "  REASONING: %s\n\n", c.Reasoning)
 	}
@@ -803,3 +925,12 @@ func RenderDataflowText(r *DataflowReport) string {
 	}
 	return b.String()
 }
+
+// stepLocation renders "line N" for steps in the chain's source file and
+// "file line N" for steps the taint engine followed into other files.
+func stepLocation(s FlowStep) string {
+	if s.File == "" {
+		return fmt.Sprintf("line %d", s.Line)
+	}
+	return fmt.Sprintf("%s line %d", s.File, s.Line)
+}
@@ -61,6 +61,7 @@ func AnalyzeDone(p *project.Project, opts DoneOptions) (*DoneReport, error) {
 
 	// Findings by this agent.
 	store := finding.NewStore(p)
+	defer func() { _ = store.Close() }()
 	list, err := store.List(nil)
 	if err != nil {
 		return nil, fmt.Errorf("list findings: %w", err)
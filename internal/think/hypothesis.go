@@ -2,6 +2,7 @@ package think
 
 import (
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 
@@ -134,6 +135,13 @@ func AnalyzeHypothesis(p *project.Project, opts HypothesisOptions) (*HypothesisR
 	}
 	sort.Strings(relevantCWEs)
 
+	// The taint model's sinks extend the CWE's sink regex, and the call
+	// names they target become evidence keywords.
+	model, err := LoadTaintModel(p)
+	if err != nil {
+		return nil, err
+	}
+
 	// Score each CWE by how many tech hints + memory keywords match.
 	techJoined := strings.ToLower(strings.Join(report.TechHints, " "))
 	for _, cweID := range relevantCWEs {
@@ -141,6 +149,9 @@ func AnalyzeHypothesis(p *project.Project, opts HypothesisOptions) (*HypothesisR
 		if !ok {
 			continue
 		}
+		if cls, ok := cweSinkClasses[cweID]; ok {
+			entry = withModelSinks(entry, model.sinks("", []string{cls}))
+		}
 
 		score := 0
 		var evidence []string
@@ -197,6 +208,24 @@ func AnalyzeHypothesis(p *project.Project, opts HypothesisOptions) (*HypothesisR
 	return report, nil
 }
 
+// withModelSinks returns e with custom sink patterns appended to its regex
+// and the dotted call names they target (e.g. "db.QueryRaw") added as
+// keywords.
+func withModelSinks(e cweEntry, sinks []string) cweEntry {
+	if len(sinks) == 0 {
+		return e
+	}
+	e.SinkRE = alternation(append([]string{e.SinkRE}, sinks...)...)
+	keywords := slices.Clone(e.Keywords)
+	for _, pat := range sinks {
+		if m := labelCallRE.FindString(strings.ReplaceAll(pat, `\.`, ".")); m != "" && !slices.Contains(keywords, m) {
+			keywords = append(keywords, m)
+		}
+	}
+	e.Keywords = keywords
+	return e
+}
+
 type memContent struct {
 	Name string
 	Body string
@@ -0,0 +1,484 @@
+package think
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/diffsec/quokka/internal/project"
+	"github.com/diffsec/quokka/internal/treesitter"
+	"gopkg.in/yaml.v3"
+)
+
+// taintModelFile is the per-project taint model under .quokka/.
+const taintModelFile = "taint.yaml"
+
+// AnyLanguage is the language key whose entries apply to every language.
+const AnyLanguage = "*"
+
+// anyCWE is the sanitizer key whose patterns apply to every CWE.
+const anyCWE = "*"
+
+// modelLanguages are the language keys a taint model may use, matching the
+// names reported by treesitter.DetectLanguageName (JSX/TSX fold into
+// javascript/typescript).
+var modelLanguages = []string{
+	AnyLanguage, "c", "cpp", "go", "java", "javascript", "python", "ruby", "rust", "typescript",
+}
+
+// cweKeyRE matches a sanitizer key such as "CWE-89".
+var cweKeyRE = regexp.MustCompile(`^CWE-[0-9]+$`)
+
+// classKeyRE matches a sink class name.
+var classKeyRE = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// TaintModel is the project's custom taint vocabulary, loaded from
+// .quokka/taint.yaml. It extends the built-in sources, sink classes and
+// guard patterns used by `think dataflow`, `think validate` and
+// `think hypothesis`; it never removes a built-in.
+//
+//	languages:
+//	  go:
+//	    sources: ['r\.URL\.Query\(\)\.Get']
+//	    sinks:
+//	      sqli: ['db\.QueryRaw\b']
+//	      cmdi: ['shellutil\.Run\b']
+//	    sanitizers:
+//	      CWE-89: ['policy\.Check\s*\(']
+//
+// Patterns are regex fragments in the same form as SinkClass.Patterns.
+// Sink keys are sink class names; a name that is not built in declares a new
+// class selectable with --sink-class. Sanitizer keys are CWE IDs, or "*" for
+// all CWEs. The "*" language applies to every file.
+type TaintModel struct {
+	SchemaVersion int                      `yaml:"schema_version,omitempty" json:"schema_version,omitempty"`
+	Languages     map[string]LanguageTaint `yaml:"languages" json:"languages"`
+}
+
+// LanguageTaint is the slice of a TaintModel for one language.
+type LanguageTaint struct {
+	Sources    []string            `yaml:"sources,omitempty" json:"sources,omitempty"`
+	Sinks      map[string][]string `yaml:"sinks,omitempty" json:"sinks,omitempty"`
+	Sanitizers map[string][]string `yaml:"sanitizers,omitempty" json:"sanitizers,omitempty"`
+}
+
+// TaintModelPath returns the location of the project's taint model.
+func TaintModelPath(p *project.Project) string {
+	return filepath.Join(p.GetQuokkaPath(), taintModelFile)
+}
+
+// ParseTaintModel decodes a taint model. Unknown keys are rejected so a
+// misspelt "sink:" does not silently disable a whole section. Patterns are
+// not checked here; use Lint.
+func ParseTaintModel(data []byte) (*TaintModel, error) {
+	m := &TaintModel{}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(m); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ReadTaintModel reads and parses the project's taint model without
+// validating its patterns. A missing file yields (nil, nil).
+func ReadTaintModel(p *project.Project) (*TaintModel, error) {
+	data, err := os.ReadFile(TaintModelPath(p))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read taint model: %w", err)
+	}
+	m, err := ParseTaintModel(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", taintModelFile, err)
+	}
+	return m, nil
+}
+
+// LoadTaintModel reads the project's taint model for the analyzers. A
+// missing file yields (nil, nil); a model with lint errors is rejected so a
+// broken pattern cannot quietly drop out of the analysis.
+func LoadTaintModel(p *project.Project) (*TaintModel, error) {
+	m, err := ReadTaintModel(p)
+	if err != nil || m == nil {
+		return nil, err
+	}
+	var errs []string
+	for _, issue := range m.Lint() {
+		if issue.Severity == "error" {
+			errs = append(errs, issue.Path+": "+issue.Message)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%s has %d error(s) (run `quokka think model lint`): %s",
+			taintModelFile, len(errs), strings.Join(errs, "; "))
+	}
+	return m, nil
+}
+
+// ModelIssue is one problem reported by TaintModel.Lint.
+type ModelIssue struct {
+	Severity string `json:"severity"` // "error" | "warning"
+	Path     string `json:"path"`     // e.g. languages.go.sinks.sqli[0]
+	Message  string `json:"message"`
+}
+
+// Lint checks the model: patterns must compile and must not match the empty
+// string (which would flag every line), sanitizer keys must be CWE IDs, and
+// language keys must be ones the analyzers can detect.
+func (m *TaintModel) Lint() []ModelIssue {
+	var issues []ModelIssue
+	add := func(sev, path, format string, args ...any) {
+		issues = append(issues, ModelIssue{Severity: sev, Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+	if m == nil {
+		return nil
+	}
+	if m.SchemaVersion > 1 {
+		add("error", "schema_version", "unsupported schema version %d (want 1)", m.SchemaVersion)
+	}
+	if len(m.Languages) == 0 {
+		add("warning", "languages", "model declares no languages")
+	}
+
+	checkPatterns := func(path string, patterns []string) {
+		if len(patterns) == 0 {
+			add("warning", path, "empty pattern list")
+		}
+		seen := make(map[string]bool, len(patterns))
+		for i, pat := range patterns {
+			at := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case strings.TrimSpace(pat) == "":
+				add("error", at, "empty pattern")
+				continue
+			case seen[pat]:
+				add("warning", at, "duplicate pattern %q", pat)
+				continue
+			}
+			seen[pat] = true
+			re, err := regexp.Compile("(?i)" + pat)
+			if err != nil {
+				add("error", at, "invalid regex: %v", err)
+				continue
+			}
+			if re.MatchString("") {
+				add("error", at, "pattern %q matches the empty string", pat)
+			}
+		}
+	}
+
+	for _, lang := range sortedKeys(m.Languages) {
+		lt := m.Languages[lang]
+		base := "languages." + lang
+		if !isModelLanguage(lang) {
+			add("warning", base, "unknown language %q (known: %s)", lang, strings.Join(modelLanguages, ", "))
+		}
+		if lt.Sources != nil {
+			checkPatterns(base+".sources", lt.Sources)
+		}
+		for _, class := range sortedKeys(lt.Sinks) {
+			path := base + ".sinks." + class
+			if !classKeyRE.MatchString(class) {
+				add("error", path, "invalid sink class name %q (lowercase letters, digits, '-' and '_')", class)
+			}
+			checkPatterns(path, lt.Sinks[class])
+		}
+		for _, key := range sortedKeys(lt.Sanitizers) {
+			path := base + ".sanitizers." + key
+			if key != anyCWE && !cweKeyRE.MatchString(key) {
+				add("error", path, "sanitizer key %q is not a CWE ID (e.g. CWE-89) or %q", key, anyCWE)
+			}
+			checkPatterns(path, lt.Sanitizers[key])
+		}
+	}
+	return issues
+}
+
+// ModelEntry is one pattern in the merged (built-in + custom) taint model.
+type ModelEntry struct {
+	Language string `json:"language"`      // "*" for every language
+	Kind     string `json:"kind"`          // "source" | "sink" | "sanitizer"
+	Key      string `json:"key,omitempty"` // sink class or CWE ("*" = any)
+	Pattern  string `json:"pattern"`
+	Origin   string `json:"origin"` // "builtin" | "custom"
+}
+
+// ListTaintModel flattens the built-in vocabulary and the custom model into
+// entries, sorted by kind, key and language. A non-empty lang keeps only
+// entries that apply to that language; builtin=false drops built-ins.
+func ListTaintModel(m *TaintModel, lang string, builtin bool) []ModelEntry {
+	var out []ModelEntry
+	if builtin {
+		out = append(out, ModelEntry{Language: AnyLanguage, Kind: "source", Pattern: defaultRequestSource, Origin: "builtin"})
+		for _, c := range ListSinkClasses() {
+			for _, pat := range c.Patterns {
+				out = append(out, ModelEntry{Language: AnyLanguage, Kind: "sink", Key: c.Name, Pattern: pat, Origin: "builtin"})
+			}
+		}
+		out = append(out, ModelEntry{Language: AnyLanguage, Kind: "sanitizer", Key: anyCWE, Pattern: guardPatterns.String(), Origin: "builtin"})
+	}
+	if m != nil {
+		for name, lt := range m.Languages {
+			for _, pat := range lt.Sources {
+				out = append(out, ModelEntry{Language: name, Kind: "source", Pattern: pat, Origin: "custom"})
+			}
+			for class, pats := range lt.Sinks {
+				for _, pat := range pats {
+					out = append(out, ModelEntry{Language: name, Kind: "sink", Key: class, Pattern: pat, Origin: "custom"})
+				}
+			}
+			for cwe, pats := range lt.Sanitizers {
+				for _, pat := range pats {
+					out = append(out, ModelEntry{Language: name, Kind: "sanitizer", Key: cwe, Pattern: pat, Origin: "custom"})
+				}
+			}
+		}
+	}
+	if lang != "" {
+		kept := out[:0]
+		for _, e := range out {
+			if e.Language == AnyLanguage || e.Language == lang {
+				kept = append(kept, e)
+			}
+		}
+		out = kept
+	}
+
+	kindOrder := map[string]int{"source": 0, "sink": 1, "sanitizer": 2}
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.Kind != b.Kind {
+			return kindOrder[a.Kind] < kindOrder[b.Kind]
+		}
+		if a.Key != b.Key {
+			return a.Key < b.Key
+		}
+		if a.Origin != b.Origin {
+			return a.Origin == "builtin"
+		}
+		return a.Language < b.Language
+	})
+	return out
+}
+
+// modelLanguage maps a file path to the taint-model language key. Files in
+// unrecognised languages map to AnyLanguage, so only "*" entries apply.
+func modelLanguage(path string) string {
+	switch lang := treesitter.DetectLanguageName(path); lang {
+	case "":
+		return AnyLanguage
+	case "javascriptreact":
+		return "javascript"
+	case "typescriptreact":
+		return "typescript"
+	default:
+		return lang
+	}
+}
+
+func isModelLanguage(lang string) bool {
+	for _, l := range modelLanguages {
+		if l == lang {
+			return true
+		}
+	}
+	return false
+}
+
+// forLanguage returns the model entries that apply to lang: the "*" entry
+// plus lang's own. An empty lang selects every language, for callers (the
+// file pre-filter, hypothesis) that are not looking at one file.
+func (m *TaintModel) forLanguage(lang string) []LanguageTaint {
+	if m == nil {
+		return nil
+	}
+	var out []LanguageTaint
+	for _, name := range sortedKeys(m.Languages) {
+		if lang == "" || name == AnyLanguage || name == lang {
+			out = append(out, m.Languages[name])
+		}
+	}
+	return out
+}
+
+// sources returns the custom source patterns for lang.
+func (m *TaintModel) sources(lang string) []string {
+	var out []string
+	for _, lt := range m.forLanguage(lang) {
+		out = append(out, lt.Sources...)
+	}
+	return uniqueNonEmpty(out)
+}
+
+// sinks returns the custom sink patterns of the given classes for lang.
+func (m *TaintModel) sinks(lang string, classes []string) []string {
+	var out []string
+	for _, lt := range m.forLanguage(lang) {
+		for _, class := range classes {
+			out = append(out, lt.Sinks[class]...)
+		}
+	}
+	return uniqueNonEmpty(out)
+}
+
+// sanitizers returns the custom sanitizer patterns for lang that apply to
+// any of cwes. A nil cwes selects every CWE; "*" entries always apply.
+func (m *TaintModel) sanitizers(lang string, cwes []string) []string {
+	var out []string
+	for _, lt := range m.forLanguage(lang) {
+		for _, key := range sortedKeys(lt.Sanitizers) {
+			if cwes == nil || key == anyCWE || containsFold(cwes, key) {
+				out = append(out, lt.Sanitizers[key]...)
+			}
+		}
+	}
+	return uniqueNonEmpty(out)
+}
+
+// customSinkClasses lists the sink classes the model declares that are not
+// built in. They join the default class mix.
+func (m *TaintModel) customSinkClasses() []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, lt := range m.forLanguage("") {
+		for class := range lt.Sinks {
+			if _, builtin := sinkClasses[class]; !builtin && !seen[class] {
+				seen[class] = true
+				out = append(out, class)
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// hasSinkClass reports whether any language of the model declares class.
+func (m *TaintModel) hasSinkClass(class string) bool {
+	for _, lt := range m.forLanguage("") {
+		if _, ok := lt.Sinks[class]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// alternation joins regex fragments into one alternation, or "" when there
+// are none.
+func alternation(parts ...string) string {
+	return strings.Join(uniqueNonEmpty(parts), "|")
+}
+
+// taintPatterns is the source, sink and guard regexes applied to one file.
+// A nil src or sink means nothing of that kind is declared for the file's
+// language. sanitizer holds only the model's sanitizers (nil when none
+// apply); guard is the built-in guardPatterns extended by them.
+type taintPatterns struct {
+	src, sink, guard *regexp.Regexp
+	sanitizer        *regexp.Regexp
+}
+
+// patternSet resolves taintPatterns per file language by layering the
+// project's taint model over the base source and sink patterns.
+type patternSet struct {
+	model  *TaintModel
+	source string
+	sink   string
+	// modelSources extends source with the model's sources; false when the
+	// caller gave an explicit source.
+	modelSources bool
+	// sinkClasses lists the classes whose model sinks extend sink; empty
+	// when the caller gave an explicit sink.
+	sinkClasses []string
+	// cwes selects the model sanitizers that apply; nil means all.
+	cwes []string
+
+	byLang map[string]*taintPatterns
+}
+
+// union returns the source and sink alternations across every language,
+// used to pre-select files before per-language patterns are known.
+func (s *patternSet) union() (string, string) {
+	return s.sourceFor(""), s.sinkFor("")
+}
+
+func (s *patternSet) sourceFor(lang string) string {
+	if !s.modelSources {
+		return s.source
+	}
+	return alternation(append([]string{s.source}, s.model.sources(lang)...)...)
+}
+
+func (s *patternSet) sinkFor(lang string) string {
+	return alternation(append([]string{s.sink}, s.model.sinks(lang, s.sinkClasses)...)...)
+}
+
+// forFile returns the compiled patterns for path's language, memoised.
+func (s *patternSet) forFile(path string) (*taintPatterns, error) {
+	lang := modelLanguage(path)
+	if tp, ok := s.byLang[lang]; ok {
+		return tp, nil
+	}
+	if s.byLang == nil {
+		s.byLang = make(map[string]*taintPatterns)
+	}
+
+	compile := func(pat string) (*regexp.Regexp, error) {
+		if pat == "" {
+			return nil, nil
+		}
+		return regexp.Compile("(?i)" + pat)
+	}
+	tp := &taintPatterns{guard: guardPatterns}
+	var err error
+	if tp.src, err = compile(s.sourceFor(lang)); err != nil {
+		return nil, fmt.Errorf("invalid source pattern: %w", err)
+	}
+	if tp.sink, err = compile(s.sinkFor(lang)); err != nil {
+		return nil, fmt.Errorf("invalid sink pattern: %w", err)
+	}
+	sanitizers := s.model.sanitizers(lang, s.cwes)
+	if tp.sanitizer, err = compile(alternation(sanitizers...)); err != nil {
+		return nil, fmt.Errorf("invalid sanitizer pattern: %w", err)
+	}
+	tp.guard = guardsWith(sanitizers)
+	s.byLang[lang] = tp
+	return tp, nil
+}
+
+// guardsWith returns guardPatterns extended by sanitizer fragments, or
+// guardPatterns itself when there are none. The fragments come from a
+// linted model, so they are known to compile.
+func guardsWith(sanitizers []string) *regexp.Regexp {
+	san := alternation(sanitizers...)
+	if san == "" {
+		return guardPatterns
+	}
+	return regexp.MustCompile(guardPatterns.String() + "|(?i:" + san + ")")
+}
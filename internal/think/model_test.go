@@ -0,0 +1,197 @@
+package think
+
+import (
+	"strings"
+	"testing"
+)
+
+const testTaintModel = `languages:
+  go:
+    sources: ['r\.URL\.Query\(\)\.Get']
+    sinks:
+      sqli: ['db\.QueryRaw\b']
+      ssrf: ['httpx\.Fetch\b']
+    sanitizers:
+      CWE-89: ['policy\.Check\s*\(']
+`
+
+const goHandler = `package api
+
+func handler(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	q := buildQuery(id)
+	rows, err := db.QueryRaw(q)
+	_ = rows
+	_ = err
+}
+`
+
+func TestParseTaintModel_RejectsUnknownKeys(t *testing.T) {
+	if _, err := ParseTaintModel([]byte("languages:\n  go:\n    sink:\n      sqli: ['x']\n")); err == nil {
+		t.Fatal("want error for misspelt sinks key")
+	}
+	m, err := ParseTaintModel(nil)
+	if err != nil {
+		t.Fatalf("empty model: %v", err)
+	}
+	if len(m.Languages) != 0 {
+		t.Errorf("want no languages, got %v", m.Languages)
+	}
+}
+
+func TestTaintModelLint(t *testing.T) {
+	m, err := ParseTaintModel([]byte(`languages:
+  go:
+    sources: ['(', 'a*']
+    sinks:
+      SQLi: ['db\.Exec', 'db\.Exec']
+    sanitizers:
+      sqli: ['policy\.Check']
+  cobol:
+    sources: ['ACCEPT']
+`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	var got []string
+	for _, i := range m.Lint() {
+		got = append(got, i.Severity+" "+i.Path)
+	}
+	want := []string{
+		"warning languages.cobol",
+		"error languages.go.sources[0]",
+		"error languages.go.sources[1]",
+		"error languages.go.sinks.SQLi",
+		"warning languages.go.sinks.SQLi[1]",
+		"error languages.go.sanitizers.sqli",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("lint issues:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	clean, _ := ParseTaintModel([]byte(testTaintModel))
+	if issues := clean.Lint(); len(issues) != 0 {
+		t.Errorf("want clean model, got %+v", issues)
+	}
+}
+
+func TestListTaintModel_FiltersByLanguage(t *testing.T) {
+	m, _ := ParseTaintModel([]byte(testTaintModel + `  python:
+    sources: ['flask\.request\.stream']
+`))
+	entries := ListTaintModel(m, "go", false)
+	if len(entries) != 4 {
+		t.Fatalf("want 4 go entries, got %d: %+v", len(entries), entries)
+	}
+	if e := entries[0]; e.Kind != "source" || e.Origin != "custom" || e.Language != "go" {
+		t.Errorf("unexpected first entry: %+v", e)
+	}
+	for _, e := range ListTaintModel(m, "python", true) {
+		if e.Language == "go" {
+			t.Errorf("go entry listed for python: %+v", e)
+		}
+	}
+}
+
+func TestAnalyzeDataflow_TaintModelSourcesAndSinks(t *testing.T) {
+	p, root := writeTempProject(t)
+	writeFile(t, root, ".quokka/taint.yaml", testTaintModel)
+	writeFile(t, root, "api/handler.go", goHandler)
+
+	r, err := AnalyzeDataflow(p, DataflowOptions{})
+	if err != nil {
+		t.Fatalf("analyze: %v", err)
+	}
+	if len(r.Chains) != 1 {
+		t.Fatalf("want 1 chain, got %d: %+v", len(r.Chains), r.Chains)
+	}
+	c := r.Chains[0]
+	if c.File != "api/handler.go" || c.SourceLine != 4 || c.SinkLine != 6 {
+		t.Errorf("chain = %s:%d->%d, want api/handler.go:4->6", c.File, c.SourceLine, c.SinkLine)
+	}
+	if c.Verdict != "unguarded" {
+		t.Errorf("verdict = %s, want unguarded", c.Verdict)
+	}
+}
+
+func TestAnalyzeDataflow_TaintModelSanitizerGuards(t *testing.T) {
+	p, root := writeTempProject(t)
+	writeFile(t, root, ".quokka/taint.yaml", testTaintModel)
+	writeFile(t, root, "api/handler.go", strings.Replace(goHandler,
+		"\tq := buildQuery(id)\n",
+		"\tif err := policy.Check(id); err != nil {\n\t\treturn\n\t}\n\tq := buildQuery(id)\n", 1))
+
+	r, err := AnalyzeDataflow(p, DataflowOptions{SinkClasses: []string{"sqli"}})
+	if err != nil {
+		t.Fatalf("analyze: %v", err)
+	}
+	if len(r.Chains) != 1 {
+		t.Fatalf("want 1 chain, got %d", len(r.Chains))
+	}
+	if c := r.Chains[0]; c.Verdict != "guarded" || len(c.Guards) != 1 {
+		t.Errorf("want guarded by policy.Check, got %s with guards %+v", c.Verdict, c.Guards)
+	}
+}
+
+func TestAnalyzeDataflow_TaintModelCustomClass(t *testing.T) {
+	p, root := writeTempProject(t)
+	writeFile(t, root, ".quokka/taint.yaml", testTaintModel)
+	writeFile(t, root, "api/fetch.go", `package api
+
+func proxy(w http.ResponseWriter, r *http.Request) {
+	target := r.URL.Query().Get("url")
+	httpx.Fetch(target)
+}
+`)
+	r, err := AnalyzeDataflow(p, DataflowOptions{SinkClasses: []string{"ssrf"}})
+	if err != nil {
+		t.Fatalf("analyze: %v", err)
+	}
+	for _, n := range r.Notes {
+		if strings.Contains(n, "unknown sink class") {
+			t.Errorf("model class reported unknown: %s", n)
+		}
+	}
+	if len(r.Chains) != 1 || r.Chains[0].SinkLine != 5 {
+		t.Fatalf("want one chain into httpx.Fetch, got %+v", r.Chains)
+	}
+}
+
+func TestAnalyzeDataflow_TaintModelLanguageScoped(t *testing.T) {
+	p, root := writeTempProject(t)
+	writeFile(t, root, ".quokka/taint.yaml", testTaintModel)
+	// Python code using the Go model's call shapes is not matched.
+	writeFile(t, root, "app.py", `def view(r):
+    id = r.URL.Query().Get("id")
+    db.QueryRaw(id)
+`)
+	r, err := AnalyzeDataflow(p, DataflowOptions{})
+	if err != nil {
+		t.Fatalf("analyze: %v", err)
+	}
+	if len(r.Chains) != 0 {
+		t.Errorf("want no chains outside go, got %+v", r.Chains)
+	}
+}
+
+func TestAnalyzeDataflow_InvalidTaintModel(t *testing.T) {
+	p, root := writeTempProject(t)
+	writeFile(t, root, ".quokka/taint.yaml", "languages:\n  go:\n    sources: ['(']\n")
+	_, err := AnalyzeDataflow(p, DataflowOptions{Source: "x", Sink: "y"})
+	if err == nil || !strings.Contains(err.Error(), "think model lint") {
+		t.Fatalf("want lint error, got %v", err)
+	}
+}
+
+func TestWithModelSinks(t *testing.T) {
+	e := withModelSinks(cweSinkMap["CWE-89"], []string{`db\.QueryRaw\b`})
+	if !strings.HasSuffix(e.SinkRE, `|db\.QueryRaw\b`) {
+		t.Errorf("sink regex = %s", e.SinkRE)
+	}
+	if e.Keywords[len(e.Keywords)-1] != "db.QueryRaw" {
+		t.Errorf("keywords = %v", e.Keywords)
+	}
+	if len(cweSinkMap["CWE-89"].Keywords) == len(e.Keywords) {
+		t.Error("built-in keywords were modified in place")
+	}
+}
@@ -47,6 +47,7 @@ func AnalyzeNext(p *project.Project, opts NextOptions) (*NextReport, error) {
 
 	// Load findings.
 	fStore := finding.NewStore(p)
+	defer func() { _ = fStore.Close() }()
 	fList, err := fStore.List(nil)
 	if err != nil {
 		return nil, fmt.Errorf("list findings: %w", err)
@@ -19,7 +19,7 @@ func TestAnalyzeNext_RanksOpenHighSeverityFirst(t *testing.T) {
 	}
 
 	store := finding.NewStore(p)
-	if err := store.Create(&finding.Finding{
+	if _, err := store.Create(&finding.Finding{
 		Title:    "x",
 		Severity: finding.SeverityHigh,
 		Status:   finding.StatusOpen,
@@ -192,6 +192,51 @@ var defaultSinkClasses = []string{
 	"xss",
 }
 
+// cweSinkClasses maps CWE IDs to the sink class covering them. It drives the
+// sink inferred for a finding and selects which CWE-keyed sanitizers of the
+// taint model apply to a class.
+var cweSinkClasses = map[string]string{
+	"CWE-89":  "sqli",
+	"CWE-78":  "cmdi",
+	"CWE-94":  "codeexec",
+	"CWE-502": "deserialization",
+	"CWE-611": "xxe",
+	"CWE-643": "xpath",
+	"CWE-90":  "ldap",
+	"CWE-79":  "xss",
+	"CWE-601": "redirect",
+	"CWE-22":  "pathtrav",
+}
+
+// cwesForClasses returns the CWEs covered by the named classes. The result
+// is non-nil even when no class maps to a CWE, so callers can tell "no
+// CWE-specific sanitizers" from "all sanitizers" (nil).
+func cwesForClasses(classes []string) []string {
+	out := []string{}
+	for _, cwe := range sortedKeys(cweSinkClasses) {
+		for _, c := range classes {
+			if cweSinkClasses[cwe] == c {
+				out = append(out, cwe)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// splitClassNames normalises repeated and comma-separated class names.
+func splitClassNames(classes []string) []string {
+	var out []string
+	for _, raw := range classes {
+		for _, name := range strings.Split(raw, ",") {
+			if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+				out = append(out, name)
+			}
+		}
+	}
+	return out
+}
+
 // ListSinkClasses returns all known sink classes sorted by name. Callers
 // (e.g. a future `--list-sink-classes` CLI flag) can use this to surface
 // the catalog without reaching into package internals.
@@ -229,25 +274,19 @@ func BuildSinkPatternFromClasses(classes []string) (string, []string) {
 	var parts []string
 	var unknown []string
 	seen := make(map[string]bool)
-	for _, raw := range classes {
-		// Accept comma-separated input as well as repeated flags.
-		for _, name := range strings.Split(raw, ",") {
-			name = strings.ToLower(strings.TrimSpace(name))
-			if name == "" {
-				continue
-			}
-			c, ok := sinkClasses[name]
-			if !ok {
-				unknown = append(unknown, name)
+	// Accept comma-separated input as well as repeated flags.
+	for _, name := range splitClassNames(classes) {
+		c, ok := sinkClasses[name]
+		if !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		for _, p := range c.Patterns {
+			if seen[p] {
 				continue
 			}
-			for _, p := range c.Patterns {
-				if seen[p] {
-					continue
-				}
-				seen[p] = true
-				parts = append(parts, p)
-			}
+			seen[p] = true
+			parts = append(parts, p)
 		}
 	}
 	return strings.Join(parts, "|"), unknown
@@ -0,0 +1,739 @@
+package think
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/diffsec/quokka/internal/project"
+	"github.com/diffsec/quokka/internal/treesitter"
+)
+
+// Dataflow engines selectable via DataflowOptions.Engine.
+const (
+	// EngineRegex is the legacy line-by-line, intra-file tracer.
+	EngineRegex = "regex"
+	// EngineTaint is the AST-based, inter-procedural taint engine.
+	EngineTaint = "taint"
+)
+
+// defaultTaintDepth bounds how many call levels the taint engine follows
+// when building function summaries.
+const defaultTaintDepth = 6
+
+// identChainRE matches an identifier or dotted identifier chain
+// (e.g. "param", "self.user_id", "req.query.id").
+var identChainRE = regexp.MustCompile(`[A-Za-z_$@][A-Za-z0-9_$]*(?:\.[A-Za-z_$][A-Za-z0-9_$]*)*`)
+
+// keywordArgRE matches a Python/Ruby keyword argument ("name=value" or
+// "name: value") and captures the parameter name.
+var keywordArgRE = regexp.MustCompile(`^\s*([A-Za-z_]\w*)\s*(?:=[^=]|:\s)`)
+
+// taintOrigin records where a taint fact started: a source expression
+// (param == -1) or a function parameter (param >= 0) while summarising.
+type taintOrigin struct {
+	param int
+	file  string
+	line  int
+	code  string
+}
+
+// taintFact is the provenance of a tainted value: its origin plus the
+// ordered steps (and guards) it passed through.
+type taintFact struct {
+	origin taintOrigin
+	steps  []FlowStep
+	guards []FlowStep
+}
+
+// with returns a copy of f extended by steps.
+func (f *taintFact) with(steps ...FlowStep) *taintFact {
+	out := &taintFact{origin: f.origin}
+	out.steps = append(slices.Clone(f.steps), steps...)
+	out.guards = slices.Clone(f.guards)
+	return out
+}
+
+// guarded returns a copy of f with an extra guard step.
+func (f *taintFact) guarded(g FlowStep) *taintFact {
+	out := f.with()
+	out.guards = append(out.guards, g)
+	return out
+}
+
+// then concatenates a callee-side fact (whose origin is a parameter) onto f.
+func (f *taintFact) then(callee *taintFact) *taintFact {
+	out := f.with(callee.steps...)
+	out.guards = append(out.guards, callee.guards...)
+	return out
+}
+
+// funcRef identifies a function in the index; Index -1 is module-level code.
+type funcRef = treesitter.FuncRef
+
+// sinkHit is a tainted value reaching a sink call.
+type sinkHit struct {
+	fact     *taintFact
+	file     string
+	line     int
+	code     string
+	callText string
+}
+
+// funcSummary captures how a function moves taint: which parameters reach
+// its return value or a sink. Key -1 in returns means the function returns
+// data read from a source.
+type funcSummary struct {
+	returns map[int]*taintFact
+	sinks   map[int][]sinkHit
+}
+
+// flowEvent is one def-use fact in a function body, in source order.
+type flowEvent struct {
+	kind  byte // 'a' assign, 'c' call, 'r' return
+	idx   int
+	start int
+}
+
+// taintEngine builds per-function def-use graphs from tree-sitter flow facts
+// and follows calls across files using memoised function summaries.
+type taintEngine struct {
+	*treesitter.FuncIndex
+	patterns map[string]*taintPatterns // per file, by its language
+	maxDepth int
+
+	events map[funcRef][]flowEvent
+	owner  map[string][]int // call index -> owning function index, per file
+
+	summaries  map[funcRef]*funcSummary
+	inProgress map[funcRef]bool
+	sourceRuns map[funcRef][]sinkHit
+	sourceRets map[funcRef][]*taintFact
+
+	// summaryFrom and sourceRunFrom hold the shallowest depth at which a
+	// memoised result may be reused: 0 when it is complete, its own depth
+	// when maxDepth cut it short (a shallower caller has more budget).
+	summaryFrom   map[funcRef]int
+	sourceRunFrom map[funcRef]int
+	frames        []*memoFrame
+}
+
+// memoFrame is a summary or source run being computed. Its result is
+// depth-limited when a callee below it was beyond maxDepth, and cut by a
+// cycle when a callee recursed into a summary still being computed above
+// it; the latter is never memoised.
+type memoFrame struct {
+	ref      funcRef
+	summary  bool
+	depthCut bool
+	cycleCut bool
+}
+
+func newTaintEngine(maxDepth int) *taintEngine {
+	if maxDepth <= 0 {
+		maxDepth = defaultTaintDepth
+	}
+	return &taintEngine{
+		FuncIndex:  treesitter.NewFuncIndex(),
+		patterns:   make(map[string]*taintPatterns),
+		maxDepth:   maxDepth,
+		events:     make(map[funcRef][]flowEvent),
+		owner:      make(map[string][]int),
+		summaries:  make(map[funcRef]*funcSummary),
+		inProgress: make(map[funcRef]bool),
+		sourceRuns: make(map[funcRef][]sinkHit),
+		sourceRets: make(map[funcRef][]*taintFact),
+
+		summaryFrom:   make(map[funcRef]int),
+		sourceRunFrom: make(map[funcRef]int),
+	}
+}
+
+// analyzeDataflowTaint runs the taint engine over the project and fills the
+// report. When opts.File is set, only that file's functions are analysed
+// for chains, but callees are still resolved project-wide.
+func analyzeDataflowTaint(p *project.Project, opts DataflowOptions, report *DataflowReport, patterns *patternSet) error {
+	engine := newTaintEngine(opts.MaxDepth)
+	parser := treesitter.NewParser()
+
+	notes, truncated, err := parser.WalkFlow(p.RootPath, func(ff *treesitter.FileFlow) error {
+		tp, err := patterns.forFile(ff.Path)
+		if err != nil {
+			return err
+		}
+		engine.add(ff, tp)
+		return nil
+	})
+	report.Notes = append(report.Notes, notes...)
+	if err != nil {
+		return err
+	}
+	if truncated {
+		report.Notes = append(report.Notes, fmt.Sprintf("taint engine stopped after %d files", treesitter.MaxFlowFiles))
+	}
+
+	scope := engine.Files()
+	if opts.File != "" {
+		rel := filepath.ToSlash(opts.File)
+		if engine.File(rel) == nil {
+			return fmt.Errorf("taint engine cannot analyze %s (unsupported language or unreadable); use --engine regex", opts.File)
+		}
+		scope = []string{rel}
+	}
+	report.FilesScanned = len(engine.Files())
+
+	for _, rel := range scope {
+		src, sinks := engine.countSites(rel)
+		report.Summary.Sources += src
+		report.Summary.Sinks += sinks
+	}
+
+	perFile := make(map[string]int)
+	seen := make(map[string]bool)
+	var chains []DataflowChain
+	for _, rel := range scope {
+		for _, ref := range engine.functionsOf(rel) {
+			hits, _ := engine.sourceRun(ref, 0)
+			for _, h := range hits {
+				if h.fact.origin.param != -1 {
+					continue
+				}
+				key := fmt.Sprintf("%s:%d>%s:%d", h.fact.origin.file, h.fact.origin.line, h.file, h.line)
+				if seen[key] || perFile[h.fact.origin.file] >= opts.MaxChains {
+					continue
+				}
+				seen[key] = true
+				perFile[h.fact.origin.file]++
+				chains = append(chains, engine.chainFor(h))
+			}
+		}
+	}
+
+	sort.SliceStable(chains, func(i, j int) bool {
+		if chains[i].File != chains[j].File {
+			return chains[i].File < chains[j].File
+		}
+		if chains[i].SourceLine != chains[j].SourceLine {
+			return chains[i].SourceLine < chains[j].SourceLine
+		}
+		return chains[i].SinkLine < chains[j].SinkLine
+	})
+	report.Chains = append(report.Chains, chains...)
+	return nil
+}
+
+// add indexes a parsed file: functions by name and each function's events.
+// tp holds the source, sink and guard patterns for the file's language.
+func (e *taintEngine) add(ff *treesitter.FileFlow, tp *taintPatterns) {
+	rel := filepath.ToSlash(ff.Path)
+	e.Add(ff)
+	e.patterns[rel] = tp
+
+	owners := make([]int, len(ff.Calls))
+	for i, c := range ff.Calls {
+		owner := ff.EnclosingFunction(c.StartByte)
+		owners[i] = owner
+		ref := funcRef{File: rel, Index: owner}
+		e.events[ref] = append(e.events[ref], flowEvent{kind: 'c', idx: i, start: c.StartByte})
+	}
+	e.owner[rel] = owners
+	for i, a := range ff.Assigns {
+		if e.valueDefinesFunction(ff, a.StartByte, a.EndByte) {
+			continue
+		}
+		ref := funcRef{File: rel, Index: ff.EnclosingFunction(a.StartByte)}
+		e.events[ref] = append(e.events[ref], flowEvent{kind: 'a', idx: i, start: a.StartByte})
+	}
+	for i, r := range ff.Returns {
+		ref := funcRef{File: rel, Index: ff.EnclosingFunction(r.StartByte)}
+		e.events[ref] = append(e.events[ref], flowEvent{kind: 'r', idx: i, start: r.StartByte})
+	}
+	for ref, evs := range e.events {
+		if ref.File != rel {
+			continue
+		}
+		sort.SliceStable(evs, func(i, j int) bool { return evs[i].start < evs[j].start })
+	}
+}
+
+// valueDefinesFunction reports whether an assignment's value is itself a
+// function definition (e.g. `const handler = (req) => {...}`). Such values
+// are modelled as functions, not as data flowing into the target.
+func (e *taintEngine) valueDefinesFunction(ff *treesitter.FileFlow, start, end int) bool {
+	for _, fn := range ff.Functions {
+		if fn.StartByte <= start && fn.EndByte >= end {
+			continue
+		}
+		if fn.StartByte >= start && fn.StartByte < end {
+			return true
+		}
+	}
+	return false
+}
+
+// functionsOf lists the module-level pseudo-function plus every function of
+// a file.
+func (e *taintEngine) functionsOf(rel string) []funcRef {
+	ff := e.File(rel)
+	refs := []funcRef{{File: rel, Index: -1}}
+	for i := range ff.Functions {
+		refs = append(refs, funcRef{File: rel, Index: i})
+	}
+	return refs
+}
+
+// countSites tallies distinct source and sink lines in a file for the
+// report summary.
+func (e *taintEngine) countSites(rel string) (int, int) {
+	ff := e.File(rel)
+	tp := e.patterns[rel]
+	srcLines := make(map[int]bool)
+	sinkLines := make(map[int]bool)
+	for _, c := range ff.Calls {
+		if e.isSink(rel, c) {
+			sinkLines[c.Line] = true
+		}
+		if matches(tp.src, c.Text) && !isImportLine(ff.LineText(c.Line)) {
+			srcLines[c.Line] = true
+		}
+	}
+	for _, a := range ff.Assigns {
+		if matches(tp.src, a.Value) {
+			srcLines[a.Line] = true
+		}
+	}
+	return len(srcLines), len(sinkLines)
+}
+
+// isSink reports whether a call in file matches the sink pattern within its
+// callee (so a sink nested in the arguments does not make the outer call a
+// sink).
+func (e *taintEngine) isSink(file string, c treesitter.FlowCall) bool {
+	sinkRE := e.patterns[file].sink
+	if sinkRE == nil {
+		return false
+	}
+	loc := sinkRE.FindStringIndex(c.Text)
+	return loc != nil && loc[0] <= len(c.Callee)
+}
+
+// matches reports whether re is set and matches s.
+func matches(re *regexp.Regexp, s string) bool {
+	return re != nil && re.MatchString(s)
+}
+
+// sourceRun analyses a function with no tainted parameters, memoised. The
+// returned hits and returns include flows that start at a source inside the
+// function or inside any callee.
+func (e *taintEngine) sourceRun(ref funcRef, depth int) ([]sinkHit, []*taintFact) {
+	if hits, ok := e.sourceRuns[ref]; ok && depth >= e.sourceRunFrom[ref] {
+		return hits, e.sourceRets[ref]
+	}
+	frame := e.push(ref, false)
+	rets, hits := e.analyze(ref, nil, depth)
+	e.pop()
+	if from, ok := frame.reusableFrom(depth); ok {
+		e.sourceRuns[ref] = hits
+		e.sourceRets[ref] = rets
+		e.sourceRunFrom[ref] = from
+	}
+	return hits, rets
+}
+
+// summary computes (or returns the memoised) taint summary of a function.
+// Recursive cycles and calls beyond maxDepth yield an empty summary, and
+// the summaries that depended on one are not reused where they would miss
+// flows (see memoFrame).
+func (e *taintEngine) summary(ref funcRef, depth int) *funcSummary {
+	if s, ok := e.summaries[ref]; ok && depth >= e.summaryFrom[ref] {
+		return s
+	}
+	empty := &funcSummary{returns: map[int]*taintFact{}, sinks: map[int][]sinkHit{}}
+	switch {
+	case ref.Index < 0:
+		return empty
+	case depth > e.maxDepth:
+		for _, f := range e.frames {
+			f.depthCut = true
+		}
+		return empty
+	case e.inProgress[ref]:
+		for i := len(e.frames) - 1; i >= 0 && !(e.frames[i].summary && e.frames[i].ref == ref); i-- {
+			e.frames[i].cycleCut = true
+		}
+		return empty
+	}
+	e.inProgress[ref] = true
+	defer delete(e.inProgress, ref)
+	frame := e.push(ref, true)
+	defer e.pop()
+
+	s := empty
+	ff := e.File(ref.File)
+	fn := ff.Functions[ref.Index]
+	for i, param := range fn.Params {
+		if param == "" {
+			continue
+		}
+		seed := &taintFact{origin: taintOrigin{param: i, file: ref.File, line: fn.Line, code: ff.LineText(fn.Line)}}
+		rets, hits := e.analyze(ref, map[string]*taintFact{param: seed}, depth)
+		for _, r := range rets {
+			if r.origin.param == i {
+				s.returns[i] = r
+				break
+			}
+		}
+		for _, h := range hits {
+			if h.fact.origin.param == i {
+				s.sinks[i] = append(s.sinks[i], h)
+			}
+		}
+	}
+	_, rets := e.sourceRun(ref, depth)
+	for _, r := range rets {
+		if r.origin.param == -1 {
+			s.returns[-1] = r
+			break
+		}
+	}
+	if from, ok := frame.reusableFrom(depth); ok {
+		e.summaries[ref] = s
+		e.summaryFrom[ref] = from
+	}
+	return s
+}
+
+func (e *taintEngine) push(ref funcRef, summary bool) *memoFrame {
+	f := &memoFrame{ref: ref, summary: summary}
+	e.frames = append(e.frames, f)
+	return f
+}
+
+func (e *taintEngine) pop() {
+	e.frames = e.frames[:len(e.frames)-1]
+}
+
+// reusableFrom returns the shallowest depth at which the frame's result,
+// computed at depth, may be reused, or false when it must not be memoised.
+func (f *memoFrame) reusableFrom(depth int) (int, bool) {
+	switch {
+	case f.cycleCut:
+		return 0, false
+	case f.depthCut:
+		return depth, true
+	}
+	return 0, true
+}
+
+// analyze walks one function's events in source order, propagating taint
+// from seeds (parameter name -> fact) and from source expressions. It
+// returns the tainted return values and every sink reached.
+func (e *taintEngine) analyze(ref funcRef, seeds map[string]*taintFact, depth int) ([]*taintFact, []sinkHit) {
+	ff := e.File(ref.File)
+	tainted := make(map[string]*taintFact, len(seeds))
+	for k, v := range seeds {
+		tainted[k] = v
+	}
+
+	var rets []*taintFact
+	var hits []sinkHit
+	for _, ev := range e.events[ref] {
+		switch ev.kind {
+		case 'a':
+			a := ff.Assigns[ev.idx]
+			fact := e.valueTaint(ref, tainted, a.Value, a.StartByte, a.EndByte, a.Line, depth)
+			if fact == nil {
+				continue
+			}
+			// A source read directly into a variable needs no extra step:
+			// the chain's SOURCE line already shows the assignment.
+			atSource := len(fact.steps) == 0 && fact.origin.param == -1 &&
+				fact.origin.file == ref.File && fact.origin.line == a.Line
+			for _, target := range a.Targets {
+				if atSource {
+					tainted[target] = fact
+					continue
+				}
+				tainted[target] = fact.with(FlowStep{
+					File:     ref.File,
+					Line:     a.Line,
+					Code:     ff.LineText(a.Line),
+					Variable: target,
+					Kind:     "assignment",
+				})
+			}
+		case 'r':
+			r := ff.Returns[ev.idx]
+			fact := e.valueTaint(ref, tainted, r.Value, r.StartByte, r.EndByte, r.Line, depth)
+			if fact == nil {
+				continue
+			}
+			rets = append(rets, fact.with(FlowStep{
+				File: ref.File,
+				Line: r.Line,
+				Code: ff.LineText(r.Line),
+				Kind: "return",
+			}))
+		case 'c':
+			hits = append(hits, e.callHits(ref, tainted, ev.idx, depth)...)
+		}
+	}
+	return rets, hits
+}
+
+// callHits reports sinks reached at one call site: directly when the call
+// is itself a sink, or through a resolved callee whose summary shows the
+// matching parameter reaching a sink.
+func (e *taintEngine) callHits(ref funcRef, tainted map[string]*taintFact, callIdx, depth int) []sinkHit {
+	ff := e.File(ref.File)
+	c := ff.Calls[callIdx]
+	code := ff.LineText(c.Line)
+
+	var hits []sinkHit
+	if e.isSink(ref.File, c) {
+		for i, arg := range c.Args {
+			fact := e.valueTaint(ref, tainted, arg, c.ArgStarts[i], c.ArgStarts[i]+len(arg), c.Line, depth)
+			if fact == nil {
+				continue
+			}
+			if e.patterns[ref.File].guard.MatchString(c.Text) {
+				fact = fact.guarded(FlowStep{File: ref.File, Line: c.Line, Code: code, Kind: "guard"})
+			}
+			hits = append(hits, sinkHit{fact: fact, file: ref.File, line: c.Line, code: code, callText: c.Text})
+			break
+		}
+	}
+
+	for _, callee := range e.Resolve(ref.File, c) {
+		sum := e.summary(callee, depth+1)
+		if len(sum.sinks) == 0 {
+			continue
+		}
+		calleeFn := e.Function(callee)
+		for i, arg := range c.Args {
+			pi, paramName := mapArgToParam(calleeFn, i, arg)
+			calleeHits := sum.sinks[pi]
+			if len(calleeHits) == 0 {
+				continue
+			}
+			fact := e.valueTaint(ref, tainted, arg, c.ArgStarts[i], c.ArgStarts[i]+len(arg), c.Line, depth)
+			if fact == nil {
+				continue
+			}
+			step := fact.with(FlowStep{
+				File:     ref.File,
+				Line:     c.Line,
+				Code:     code,
+				Variable: paramName,
+				Kind:     "call",
+			})
+			for _, h := range calleeHits {
+				hits = append(hits, sinkHit{
+					fact:     step.then(h.fact),
+					file:     h.file,
+					line:     h.line,
+					code:     h.code,
+					callText: h.callText,
+				})
+			}
+		}
+	}
+	return hits
+}
+
+// valueTaint decides whether the expression text (spanning [start, end) in
+// the function's file) carries taint. Calls to resolved project functions
+// are judged by their summaries; calls that do not propagate taint are
+// masked out before the source and variable checks, so a project-defined
+// sanitizer stops the flow.
+func (e *taintEngine) valueTaint(ref funcRef, tainted map[string]*taintFact, text string, start, end, line, depth int) *taintFact {
+	ff := e.File(ref.File)
+	masked := []byte(text)
+	owners := e.owner[ref.File]
+	coveredTo := -1
+	for ci, c := range ff.Calls {
+		if c.StartByte < start || c.EndByte > end || owners[ci] != ref.Index || c.StartByte < coveredTo {
+			continue
+		}
+		callees := e.Resolve(ref.File, c)
+		if len(callees) == 0 {
+			continue
+		}
+		coveredTo = c.EndByte
+		if fact := e.callReturnTaint(ref, tainted, c, callees, depth); fact != nil {
+			return fact
+		}
+		for i := c.StartByte - start; i < c.EndByte-start && i < len(masked); i++ {
+			masked[i] = ' '
+		}
+	}
+
+	expr := maskPlainStrings(string(masked))
+	code := ff.LineText(line)
+	var fact *taintFact
+	tp := e.patterns[ref.File]
+	if matches(tp.src, expr) && !isImportLine(code) {
+		fact = &taintFact{origin: taintOrigin{param: -1, file: ref.File, line: line, code: code}}
+	} else {
+		fact = taintedReference(expr, tainted)
+	}
+	if fact == nil {
+		return nil
+	}
+	if tp.guard.MatchString(expr) {
+		fact = fact.guarded(FlowStep{File: ref.File, Line: line, Code: code, Kind: "guard"})
+	}
+	return fact
+}
+
+// callReturnTaint returns the fact carried by a call's return value, using
+// the callees' summaries: either the callee returns source data, or a
+// tainted argument flows to its return.
+func (e *taintEngine) callReturnTaint(ref funcRef, tainted map[string]*taintFact, c treesitter.FlowCall, callees []funcRef, depth int) *taintFact {
+	ff := e.File(ref.File)
+	code := ff.LineText(c.Line)
+	for _, callee := range callees {
+		sum := e.summary(callee, depth+1)
+		if r := sum.returns[-1]; r != nil {
+			return r.with(FlowStep{File: ref.File, Line: c.Line, Code: code, Kind: "call"})
+		}
+		calleeFn := e.Function(callee)
+		for i, arg := range c.Args {
+			pi, paramName := mapArgToParam(calleeFn, i, arg)
+			r := sum.returns[pi]
+			if r == nil {
+				continue
+			}
+			fact := e.valueTaint(ref, tainted, arg, c.ArgStarts[i], c.ArgStarts[i]+len(arg), c.Line, depth)
+			if fact == nil {
+				continue
+			}
+			return fact.with(FlowStep{
+				File:     ref.File,
+				Line:     c.Line,
+				Code:     code,
+				Variable: paramName,
+				Kind:     "call",
+			}).then(r)
+		}
+	}
+	return nil
+}
+
+// mapArgToParam maps positional argument i (or a keyword argument) to the
+// callee's parameter index and name. Python/Rust methods declare the
+// receiver as their first parameter, which call sites do not pass.
+func mapArgToParam(fn treesitter.FlowFunction, i int, arg string) (int, string) {
+	if m := keywordArgRE.FindStringSubmatch(arg); m != nil {
+		for pi, name := range fn.Params {
+			if name == m[1] {
+				return pi, name
+			}
+		}
+	}
+	if len(fn.Params) > 0 && (fn.Params[0] == "self" || fn.Params[0] == "cls") {
+		i++
+	}
+	if i < len(fn.Params) {
+		return i, fn.Params[i]
+	}
+	return -2, ""
+}
+
+// taintedReference returns the fact of the first tainted variable referenced
+// by expr. A chain such as "user.name" refers to tainted "user"; the
+// attribute "x.user" does not.
+func taintedReference(expr string, tainted map[string]*taintFact) *taintFact {
+	if len(tainted) == 0 {
+		return nil
+	}
+	for _, loc := range identChainRE.FindAllStringIndex(expr, -1) {
+		if loc[0] > 0 && expr[loc[0]-1] == '.' {
+			continue
+		}
+		chain := expr[loc[0]:loc[1]]
+		for {
+			if f, ok := tainted[chain]; ok {
+				return f
+			}
+			idx := strings.LastIndex(chain, ".")
+			if idx < 0 {
+				break
+			}
+			chain = chain[:idx]
+		}
+	}
+	return nil
+}
+
+// maskPlainStrings blanks the contents of string literals that cannot
+// interpolate, so a tainted variable named "id" is not "referenced" by the
+// literal "SELECT id FROM t". f-strings, template literals and strings with
+// #{...} / ${...} interpolation are left intact.
+func maskPlainStrings(expr string) string {
+	out := []byte(expr)
+	for i := 0; i < len(out); i++ {
+		q := out[i]
+		if q != '"' && q != '\'' && q != '`' {
+			continue
+		}
+		end := i + 1
+		for end < len(out) && out[end] != q {
+			if out[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end > len(out) {
+			end = len(out)
+		}
+		body := string(out[i+1 : end])
+		prefix := i
+		for prefix > 0 && strings.ContainsRune("rRbBuUfF", rune(out[prefix-1])) && i-prefix < 3 {
+			prefix--
+		}
+		interpolates := q == '`' ||
+			strings.ContainsAny(string(out[prefix:i]), "fF") ||
+			strings.Contains(body, "#{") || strings.Contains(body, "${")
+		if !interpolates {
+			for j := i + 1; j < end; j++ {
+				out[j] = ' '
+			}
+		}
+		i = end
+	}
+	return string(out)
+}
+
+// chainFor converts a sink hit into the report's DataflowChain shape and
+// applies the same verdict rules as the regex engine.
+func (e *taintEngine) chainFor(h sinkHit) DataflowChain {
+	chain := DataflowChain{
+		File:       h.fact.origin.file,
+		SourceLine: h.fact.origin.line,
+		SourceCode: h.fact.origin.code,
+		SinkLine:   h.line,
+		SinkCode:   h.code,
+		Guards:     slices.Clone(h.fact.guards),
+	}
+	if h.file != chain.File {
+		chain.SinkFile = h.file
+	}
+	for _, s := range h.fact.steps {
+		if s.File == chain.File {
+			s.File = ""
+		}
+		chain.Assignments = append(chain.Assignments, s)
+	}
+	for i := range chain.Guards {
+		if chain.Guards[i].File == chain.File {
+			chain.Guards[i].File = ""
+		}
+	}
+	tp := e.patterns[h.file]
+	applyVerdict(&chain, h.callText, tp.sink, tp.sanitizer)
+	return chain
+}
@@ -62,6 +62,8 @@ func AnalyzeValidate(p *project.Project, opts ValidateOptions) (*ValidateReport,
 	}
 
 	store := finding.NewStore(p)
+	defer func() { _ = store.Close() }()
+
 	f, err := store.Read(opts.FindingID)
 	if err != nil {
 		return nil, err
@@ -92,9 +94,23 @@ func AnalyzeValidate(p *project.Project, opts ValidateOptions) (*ValidateReport,
 		}
 	}
 
-	// Search the file for source and sink patterns inferred from finding.
-	srcPat := inferSourceFromFinding(f)
+	// Search the file for source and sink patterns inferred from finding,
+	// extended by the project's taint model for the file's language.
+	model, err := LoadTaintModel(p)
+	if err != nil {
+		return nil, err
+	}
+	lang := modelLanguage(f.Location.File)
+	srcPat := alternation(append([]string{inferSourceFromFinding(f)}, model.sources(lang)...)...)
 	sinkPat := inferSinkFromFinding(f)
+	if classes := sinkClassesForFinding(f); len(classes) > 0 {
+		sinkPat = alternation(append([]string{sinkPat}, model.sinks(lang, classes)...)...)
+	}
+	var cwes []string
+	if f.CWE != "" {
+		cwes = []string{strings.ToUpper(f.CWE)}
+	}
+	guardRE := guardsWith(model.sanitizers(lang, cwes))
 
 	if srcPat != "" {
 		hits, lines := scanFileForPattern(reader, f.Location.File, srcPat)
@@ -108,7 +124,7 @@ func AnalyzeValidate(p *project.Project, opts ValidateOptions) (*ValidateReport,
 	}
 
 	// Detect guards anywhere in the file between any source and any sink.
-	guards := findGuardsBetween(reader, f.Location.File, r.SourceLines, r.SinkLines)
+	guards := findGuardsBetween(reader, f.Location.File, r.SourceLines, r.SinkLines, guardRE)
 	r.GuardsFound = guards
 
 	// Build rubric.
@@ -175,7 +191,7 @@ func scanFileForPattern(r *navigate.Reader, path, pattern string) (bool, []int)
 	return len(hits) > 0, hits
 }
 
-func findGuardsBetween(r *navigate.Reader, path string, sources, sinks []int) []FlowStep {
+func findGuardsBetween(r *navigate.Reader, path string, sources, sinks []int, guardRE *regexp.Regexp) []FlowStep {
 	if len(sources) == 0 || len(sinks) == 0 {
 		return nil
 	}
@@ -211,7 +227,7 @@ func findGuardsBetween(r *navigate.Reader, path string, sources, sinks []int) []
 				continue
 			}
 			code := rr.Lines[ln-1]
-			if guardPatterns.MatchString(code) {
+			if guardRE.MatchString(code) {
 				out = append(out, FlowStep{
 					Line: ln,
 					Code: strings.TrimSpace(code),
@@ -23,7 +23,7 @@ def view():
 		Location: finding.Location{File: "app.py", LineStart: 5},
 		Description: "SOURCE: request.form.get(\"x\") at app.py:3\nSINK: cur.execute(sql) at app.py:5",
 	}
-	if err := store.Create(f); err != nil {
+	if _, err := store.Create(f); err != nil {
 		t.Fatalf("create: %v", err)
 	}
 
@@ -62,7 +62,7 @@ def view():
 		Location: finding.Location{File: "app.py", LineStart: 5},
 		Description: "SOURCE: request.form.get\nSINK: cur.execute",
 	}
-	if err := store.Create(f); err != nil {
+	if _, err := store.Create(f); err != nil {
 		t.Fatalf("create: %v", err)
 	}
 
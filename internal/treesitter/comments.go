@@ -0,0 +1,77 @@
+package treesitter
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+
+	"github.com/odvcencio/gotreesitter"
+	"github.com/odvcencio/gotreesitter/grammars"
+)
+
+// Comment is one comment node in a source file.
+type Comment struct {
+	Text    string
+	Line    int
+	EndLine int
+	// Trailing is true when code precedes the comment on its first line
+	// (e.g. `x := f() // note`).
+	Trailing bool
+}
+
+// commentQueries holds the comment query for grammars whose comment node
+// isn't simply "comment".
+var commentQueries = map[string]string{
+	"java": "[(line_comment) (block_comment)] @comment",
+	"rust": "[(line_comment) (block_comment)] @comment",
+}
+
+// ExtractComments returns the comments in source, in document order. Only
+// real comment nodes are returned, so a marker inside a string literal is
+// never mistaken for a comment.
+func (p *Parser) ExtractComments(source []byte, relPath string) ([]Comment, error) {
+	langName := DetectLanguageName(relPath)
+	if langName == "" || grammars.DetectLanguage(filepath.Base(relPath)) == nil {
+		return nil, fmt.Errorf("tree-sitter does not support: %s", relPath)
+	}
+	query, ok := commentQueries[langName]
+	if !ok {
+		query = "(comment) @comment"
+	}
+
+	bt, err := grammars.ParseFilePooled(relPath, source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse: %w", err)
+	}
+	defer bt.Release()
+
+	root := bt.RootNode()
+	lang := bt.Language()
+	q, err := gotreesitter.NewQuery(query, lang)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile comment query: %w", err)
+	}
+
+	var comments []Comment
+	cursor := q.Exec(root, lang, source)
+	for {
+		match, ok := cursor.NextMatch()
+		if !ok {
+			break
+		}
+		for _, c := range match.Captures {
+			if c.Node == nil {
+				continue
+			}
+			start := int(c.Node.StartByte())
+			lineStart := bytes.LastIndexByte(source[:start], '\n') + 1
+			comments = append(comments, Comment{
+				Text:     c.Text(source),
+				Line:     int(c.Node.StartPoint().Row) + 1,
+				EndLine:  int(c.Node.EndPoint().Row) + 1,
+				Trailing: len(bytes.TrimSpace(source[lineStart:start])) > 0,
+			})
+		}
+	}
+	return comments, nil
+}
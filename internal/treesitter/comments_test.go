@@ -0,0 +1,41 @@
+package treesitter
+
+import "testing"
+
+func TestExtractComments_AllLanguages(t *testing.T) {
+	cases := map[string]string{
+		"a.go":   "package a\n\n// own line\nvar x = \"// not a comment\" // trailing\n",
+		"a.py":   "# own line\nx = \"# not a comment\"  # trailing\n",
+		"a.js":   "// own line\nconst x = \"// not a comment\"; // trailing\n",
+		"a.ts":   "// own line\nconst x: string = \"// not a comment\"; // trailing\n",
+		"a.jsx":  "// own line\nconst x = \"// not a comment\"; // trailing\n",
+		"a.tsx":  "// own line\nconst x = \"// not a comment\"; // trailing\n",
+		"A.java": "// own line\nclass A { String x = \"// not a comment\"; // trailing\n}\n",
+		"a.rs":   "// own line\nconst X: &str = \"// not a comment\"; // trailing\n",
+		"a.rb":   "# own line\nx = \"# not a comment\" # trailing\n",
+		"a.c":    "/* own line */\nchar *x = \"// not a comment\"; // trailing\n",
+		"a.cpp":  "// own line\nconst char *x = \"// not a comment\"; // trailing\n",
+	}
+	p := NewParser()
+	for file, src := range cases {
+		comments, err := p.ExtractComments([]byte(src), file)
+		if err != nil {
+			t.Errorf("%s: %v", file, err)
+			continue
+		}
+		if len(comments) != 2 {
+			t.Errorf("%s: got %d comments, want 2: %+v", file, len(comments), comments)
+			continue
+		}
+		if comments[0].Trailing || !comments[1].Trailing {
+			t.Errorf("%s: trailing flags = %v, %v", file, comments[0].Trailing, comments[1].Trailing)
+		}
+		if comments[1].Line != comments[0].Line+1 {
+			t.Errorf("%s: lines = %d, %d", file, comments[0].Line, comments[1].Line)
+		}
+	}
+
+	if _, err := p.ExtractComments([]byte("x"), "a.txt"); err == nil {
+		t.Error("expected error for unsupported file")
+	}
+}
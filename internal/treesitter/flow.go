@@ -0,0 +1,454 @@
+package treesitter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/odvcencio/gotreesitter"
+	"github.com/odvcencio/gotreesitter/grammars"
+)
+
+// FlowFunction is a function or method definition seen by ExtractFlow.
+// Byte offsets let callers attribute calls/assignments to the innermost
+// enclosing function.
+type FlowFunction struct {
+	Name      string
+	Parent    string
+	Params    []string
+	Line      int
+	EndLine   int
+	StartByte int
+	EndByte   int
+}
+
+// FlowCall is one call expression. Callee is the source text preceding the
+// argument list (e.g. "cursor.execute", "helpers.run_query"); ArgStarts
+// holds the absolute byte offset of each entry in Args.
+type FlowCall struct {
+	Callee    string
+	Args      []string
+	ArgStarts []int
+	Text      string
+	Line      int
+	StartByte int
+	EndByte   int
+}
+
+// Name returns the last segment of the callee (e.g. "execute" for
+// "cursor.execute").
+func (c FlowCall) Name() string {
+	name := c.Callee
+	for _, sep := range []string{".", "::", "->"} {
+		if idx := strings.LastIndex(name, sep); idx >= 0 {
+			name = name[idx+len(sep):]
+		}
+	}
+	return strings.TrimSpace(name)
+}
+
+// FlowAssign is one assignment or initialised declaration. Targets holds the
+// assigned names (dotted for attribute targets); Value is the right-hand side.
+type FlowAssign struct {
+	Targets   []string
+	Value     string
+	Line      int
+	StartByte int
+	EndByte   int
+}
+
+// FlowReturn is one return statement with a value.
+type FlowReturn struct {
+	Value     string
+	Line      int
+	StartByte int
+	EndByte   int
+}
+
+// FileFlow holds the def-use facts of a single file: function definitions,
+// call sites, assignments and returns, each ordered by position.
+type FileFlow struct {
+	Path      string
+	Language  string
+	Lines     []string
+	Functions []FlowFunction
+	Calls     []FlowCall
+	Assigns   []FlowAssign
+	Returns   []FlowReturn
+}
+
+// CanHandleFlow reports whether ExtractFlow has def-use queries for path.
+func (p *Parser) CanHandleFlow(path string) bool {
+	if grammars.DetectLanguage(filepath.Base(path)) == nil {
+		return false
+	}
+	return len(getFlowPatterns(DetectLanguageName(path))) > 0
+}
+
+// ExtractFlow extracts def-use facts from a file on disk. Oversized files
+// are skipped with a *SkippedFileError, mirroring ExtractSymbols.
+func (p *Parser) ExtractFlow(filePath, relPath string) (*FileFlow, error) {
+	if p.maxFileSize > 0 {
+		info, err := os.Stat(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat file: %w", err)
+		}
+		if info.Size() > p.maxFileSize {
+			return nil, &SkippedFileError{
+				Path:   relPath,
+				Size:   info.Size(),
+				Reason: fmt.Sprintf("exceeds max size %d", p.maxFileSize),
+			}
+		}
+	}
+
+	source, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return p.ExtractFlowFromSource(source, relPath)
+}
+
+// ExtractFlowFromSource extracts def-use facts from source bytes.
+//
+// Each flow pattern is compiled on its own so that a grammar lacking one
+// node type (e.g. an older TypeScript grammar without function_expression)
+// only loses that pattern rather than the whole file.
+func (p *Parser) ExtractFlowFromSource(source []byte, relPath string) (*FileFlow, error) {
+	langName := DetectLanguageName(relPath)
+	patterns := getFlowPatterns(langName)
+	if len(patterns) == 0 {
+		return nil, fmt.Errorf("no tree-sitter flow query for: %s", relPath)
+	}
+	if grammars.DetectLanguage(filepath.Base(relPath)) == nil {
+		return nil, fmt.Errorf("tree-sitter does not support: %s", relPath)
+	}
+
+	bt, err := grammars.ParseFilePooled(relPath, source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse: %w", err)
+	}
+	defer bt.Release()
+
+	root := bt.RootNode()
+	lang := bt.Language()
+	if bt.NodeType(root) == "" || root.ChildCount() == 0 {
+		return nil, fmt.Errorf("tree-sitter failed to parse %s", relPath)
+	}
+
+	ff := &FileFlow{
+		Path:     relPath,
+		Language: langName,
+		Lines:    strings.Split(string(source), "\n"),
+	}
+
+	compiled := 0
+	for _, pat := range patterns {
+		q, err := gotreesitter.NewQuery(pat, lang)
+		if err != nil {
+			continue
+		}
+		compiled++
+		cursor := q.Exec(root, lang, source)
+		for {
+			match, ok := cursor.NextMatch()
+			if !ok {
+				break
+			}
+			ff.addMatch(match, bt, source, langName)
+		}
+	}
+	if compiled == 0 {
+		return nil, fmt.Errorf("no flow query compiled for %s", relPath)
+	}
+
+	sort.SliceStable(ff.Functions, func(i, j int) bool { return ff.Functions[i].StartByte < ff.Functions[j].StartByte })
+	ff.Functions = dedupeFunctions(ff.Functions)
+	sort.SliceStable(ff.Calls, func(i, j int) bool { return ff.Calls[i].StartByte < ff.Calls[j].StartByte })
+	sort.SliceStable(ff.Assigns, func(i, j int) bool { return ff.Assigns[i].StartByte < ff.Assigns[j].StartByte })
+	sort.SliceStable(ff.Returns, func(i, j int) bool { return ff.Returns[i].StartByte < ff.Returns[j].StartByte })
+	return ff, nil
+}
+
+// addMatch records one query match under the fact kind named by its outer
+// capture (@function, @call, @assign or @return).
+func (ff *FileFlow) addMatch(match gotreesitter.QueryMatch, bt *gotreesitter.BoundTree, source []byte, langName string) {
+	texts := make(map[string]string, len(match.Captures))
+	starts := make(map[string]int, len(match.Captures))
+	ends := make(map[string]int, len(match.Captures))
+	lines := make(map[string]int, len(match.Captures))
+	endLines := make(map[string]int, len(match.Captures))
+	for _, cap := range match.Captures {
+		texts[cap.Name] = cap.Text(source)
+		starts[cap.Name] = int(cap.Node.StartByte())
+		ends[cap.Name] = int(cap.Node.EndByte())
+		lines[cap.Name] = int(cap.Node.StartPoint().Row) + 1
+		endLines[cap.Name] = int(cap.Node.EndPoint().Row) + 1
+	}
+
+	switch {
+	case hasCapture(texts, "function"):
+		name := texts["name"]
+		if name == "" {
+			return
+		}
+		ff.Functions = append(ff.Functions, FlowFunction{
+			Name:      name,
+			Parent:    findParentSymbol(match, bt),
+			Params:    ParamNames(langName, texts["params"]),
+			Line:      lines["function"],
+			EndLine:   endLines["function"],
+			StartByte: starts["function"],
+			EndByte:   ends["function"],
+		})
+	case hasCapture(texts, "call"):
+		argsStart, ok := starts["args"]
+		if !ok || argsStart < starts["call"] {
+			return
+		}
+		callee := strings.TrimSpace(string(source[starts["call"]:argsStart]))
+		callee = strings.TrimSpace(strings.TrimPrefix(callee, "new "))
+		if callee == "" {
+			return
+		}
+		args, offsets := splitArgSpans(texts["args"])
+		for i := range offsets {
+			offsets[i] += argsStart
+		}
+		ff.Calls = append(ff.Calls, FlowCall{
+			Callee:    callee,
+			Args:      args,
+			ArgStarts: offsets,
+			Text:      texts["call"],
+			Line:      lines["call"],
+			StartByte: starts["call"],
+			EndByte:   ends["call"],
+		})
+	case hasCapture(texts, "assign"):
+		targets := assignTargets(texts["left"])
+		if len(targets) == 0 {
+			return
+		}
+		ff.Assigns = append(ff.Assigns, FlowAssign{
+			Targets:   targets,
+			Value:     texts["right"],
+			Line:      lines["assign"],
+			StartByte: starts["right"],
+			EndByte:   ends["right"],
+		})
+	case hasCapture(texts, "return"):
+		ff.Returns = append(ff.Returns, FlowReturn{
+			Value:     texts["value"],
+			Line:      lines["return"],
+			StartByte: starts["value"],
+			EndByte:   ends["value"],
+		})
+	}
+}
+
+// dedupeFunctions drops functions matched by more than one pattern (e.g. a
+// Ruby method matched with and without a parameter list), keeping the match
+// that recorded the most parameters. Input must be sorted by StartByte.
+func dedupeFunctions(fns []FlowFunction) []FlowFunction {
+	out := fns[:0]
+	for _, fn := range fns {
+		if n := len(out); n > 0 && out[n-1].StartByte == fn.StartByte && out[n-1].Name == fn.Name {
+			if len(fn.Params) > len(out[n-1].Params) {
+				out[n-1] = fn
+			}
+			continue
+		}
+		out = append(out, fn)
+	}
+	return out
+}
+
+func hasCapture(texts map[string]string, name string) bool {
+	_, ok := texts[name]
+	return ok
+}
+
+// EnclosingFunction returns the index of the innermost function whose byte
+// range contains offset, or -1 for module-level code.
+func (ff *FileFlow) EnclosingFunction(offset int) int {
+	best := -1
+	for i, fn := range ff.Functions {
+		if offset < fn.StartByte || offset >= fn.EndByte {
+			continue
+		}
+		if best == -1 || fn.EndByte-fn.StartByte < ff.Functions[best].EndByte-ff.Functions[best].StartByte {
+			best = i
+		}
+	}
+	return best
+}
+
+// LineText returns the trimmed source of a 1-indexed line, or "".
+func (ff *FileFlow) LineText(line int) string {
+	if line < 1 || line > len(ff.Lines) {
+		return ""
+	}
+	return strings.TrimSpace(ff.Lines[line-1])
+}
+
+// SplitArgs splits an argument-list text (with or without the surrounding
+// parentheses) into its top-level arguments. Nested brackets and string
+// literals are treated as opaque.
+func SplitArgs(text string) []string {
+	args, _ := splitArgSpans(text)
+	return args
+}
+
+// splitArgSpans is SplitArgs that also reports each argument's byte offset
+// within text.
+func splitArgSpans(text string) ([]string, []int) {
+	lo, hi := 0, len(text)
+	for lo < hi && isSpace(text[lo]) {
+		lo++
+	}
+	for hi > lo && isSpace(text[hi-1]) {
+		hi--
+	}
+	if hi-lo >= 2 && text[lo] == '(' && text[hi-1] == ')' {
+		lo++
+		hi--
+	}
+
+	var args []string
+	var offsets []int
+	emit := func(from, to int) {
+		for from < to && isSpace(text[from]) {
+			from++
+		}
+		for to > from && isSpace(text[to-1]) {
+			to--
+		}
+		if from < to {
+			args = append(args, text[from:to])
+			offsets = append(offsets, from)
+		}
+	}
+
+	depth := 0
+	start := lo
+	var quote byte
+	for i := lo; i < hi; i++ {
+		c := text[i]
+		if quote != 0 {
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '"', '\'', '`':
+			quote = c
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			if depth > 0 {
+				depth--
+			}
+		case ',':
+			if depth == 0 {
+				emit(start, i)
+				start = i + 1
+			}
+		}
+	}
+	emit(start, hi)
+	return args, offsets
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// ParamNames extracts parameter names from a parameter-list text using the
+// naming conventions of the given language. Destructured or otherwise
+// unnamed parameters yield "" so positional indexes stay aligned.
+func ParamNames(language, text string) []string {
+	segments := SplitArgs(text)
+	if language == "go" {
+		return goParamNames(segments)
+	}
+	names := make([]string, 0, len(segments))
+	for _, seg := range segments {
+		if strings.HasPrefix(seg, "{") || strings.HasPrefix(seg, "[") {
+			names = append(names, "")
+			continue
+		}
+		if idx := strings.Index(seg, "="); idx >= 0 {
+			seg = seg[:idx]
+		}
+		switch language {
+		case "python", "javascript", "javascriptreact", "typescript", "typescriptreact", "rust":
+			if idx := strings.Index(seg, ":"); idx >= 0 {
+				seg = seg[:idx]
+			}
+		}
+		idents := identRE.FindAllString(seg, -1)
+		if len(idents) == 0 {
+			names = append(names, "")
+			continue
+		}
+		switch language {
+		case "java", "c", "cpp", "rust":
+			names = append(names, idents[len(idents)-1])
+		default:
+			names = append(names, idents[0])
+		}
+	}
+	return names
+}
+
+// goParamNames handles Go's grouped parameter declarations: in
+// "(a, b string, c int)" the bare "a" is a name sharing b's type, while in
+// "(string, int)" every bare word is an unnamed parameter type.
+func goParamNames(segments []string) []string {
+	named := false
+	for _, seg := range segments {
+		if len(strings.Fields(seg)) >= 2 {
+			named = true
+			break
+		}
+	}
+	names := make([]string, 0, len(segments))
+	for _, seg := range segments {
+		fields := strings.Fields(seg)
+		if !named || len(fields) == 0 {
+			names = append(names, "")
+			continue
+		}
+		names = append(names, fields[0])
+	}
+	return names
+}
+
+// assignTargets splits an assignment's left-hand side into target names.
+// Subscripts are reduced to their base ("x[0]" -> "x") and declaration
+// keywords/types are dropped ("const x" -> "x", "String s" -> "s").
+func assignTargets(left string) []string {
+	var out []string
+	for _, part := range SplitArgs(left) {
+		if idx := strings.IndexAny(part, "[("); idx >= 0 {
+			part = part[:idx]
+		}
+		part = strings.TrimLeft(strings.TrimSpace(part), "*&")
+		fields := strings.Fields(part)
+		if len(fields) == 0 {
+			continue
+		}
+		target := fields[len(fields)-1]
+		if !identRE.MatchString(target) {
+			continue
+		}
+		out = append(out, strings.TrimPrefix(target, "@"))
+	}
+	return out
+}
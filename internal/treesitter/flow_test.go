@@ -0,0 +1,156 @@
+package treesitter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractFlowPython(t *testing.T) {
+	source := []byte(`class Repo:
+    def find(self, user_id, limit=10):
+        sql = "SELECT * FROM t WHERE id = " + user_id
+        return self.db.execute(sql)
+
+def handler():
+    uid = request.args.get("id")
+    return Repo().find(uid)
+`)
+
+	p := NewParser()
+	ff, err := p.ExtractFlowFromSource(source, "repo.py")
+	if err != nil {
+		t.Fatalf("ExtractFlowFromSource: %v", err)
+	}
+
+	fns := make(map[string]FlowFunction)
+	for _, fn := range ff.Functions {
+		fns[fn.Name] = fn
+	}
+	find, ok := fns["find"]
+	if !ok {
+		t.Fatalf("expected function find, got %+v", ff.Functions)
+	}
+	if find.Parent != "Repo" {
+		t.Errorf("find.Parent = %q, want Repo", find.Parent)
+	}
+	if want := []string{"self", "user_id", "limit"}; !reflect.DeepEqual(find.Params, want) {
+		t.Errorf("find.Params = %v, want %v", find.Params, want)
+	}
+	if _, ok := fns["handler"]; !ok {
+		t.Errorf("expected function handler, got %+v", ff.Functions)
+	}
+
+	var execute *FlowCall
+	for i := range ff.Calls {
+		if ff.Calls[i].Callee == "self.db.execute" {
+			execute = &ff.Calls[i]
+		}
+	}
+	if execute == nil {
+		t.Fatalf("expected call self.db.execute, got %+v", ff.Calls)
+	}
+	if execute.Line != 4 || len(execute.Args) != 1 || execute.Args[0] != "sql" {
+		t.Errorf("unexpected execute call: %+v", *execute)
+	}
+	if got := string(source[execute.ArgStarts[0] : execute.ArgStarts[0]+3]); got != "sql" {
+		t.Errorf("ArgStarts[0] points at %q, want sql", got)
+	}
+	if idx := ff.EnclosingFunction(execute.StartByte); idx < 0 || ff.Functions[idx].Name != "find" {
+		t.Errorf("EnclosingFunction(execute) = %d, want find", idx)
+	}
+
+	targets := map[string]int{}
+	for _, a := range ff.Assigns {
+		for _, tgt := range a.Targets {
+			targets[tgt] = a.Line
+		}
+	}
+	if targets["sql"] != 3 || targets["uid"] != 7 {
+		t.Errorf("unexpected assignment targets: %v", targets)
+	}
+	if len(ff.Returns) != 2 {
+		t.Errorf("expected 2 returns, got %d", len(ff.Returns))
+	}
+}
+
+func TestExtractFlowGo(t *testing.T) {
+	source := []byte(`package store
+
+func (s *Store) Find(ctx context.Context, id string) error {
+	q := "SELECT * FROM t WHERE id = " + id
+	_, err := s.db.ExecContext(ctx, q)
+	return err
+}
+`)
+
+	p := NewParser()
+	ff, err := p.ExtractFlowFromSource(source, "store.go")
+	if err != nil {
+		t.Fatalf("ExtractFlowFromSource: %v", err)
+	}
+	if len(ff.Functions) != 1 || ff.Functions[0].Name != "Find" {
+		t.Fatalf("expected function Find, got %+v", ff.Functions)
+	}
+	if want := []string{"ctx", "id"}; !reflect.DeepEqual(ff.Functions[0].Params, want) {
+		t.Errorf("Params = %v, want %v", ff.Functions[0].Params, want)
+	}
+
+	found := false
+	for _, c := range ff.Calls {
+		if c.Callee == "s.db.ExecContext" {
+			found = true
+			if !reflect.DeepEqual(c.Args, []string{"ctx", "q"}) {
+				t.Errorf("ExecContext args = %v", c.Args)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("expected call s.db.ExecContext, got %+v", ff.Calls)
+	}
+}
+
+func TestExtractFlowUnsupported(t *testing.T) {
+	p := NewParser()
+	if p.CanHandleFlow("notes.txt") {
+		t.Error("CanHandleFlow(notes.txt) = true, want false")
+	}
+	if _, err := p.ExtractFlowFromSource([]byte("x"), "notes.txt"); err == nil {
+		t.Error("expected error for unsupported file")
+	}
+}
+
+func TestParamNames(t *testing.T) {
+	tests := []struct {
+		lang, text string
+		want       []string
+	}{
+		{"python", "(self, *args, key: str = 'x')", []string{"self", "args", "key"}},
+		{"go", "(a, b string, c int)", []string{"a", "b", "c"}},
+		{"go", "(ctx context.Context, ids ...string)", []string{"ctx", "ids"}},
+		{"go", "(string, int)", []string{"", ""}},
+		{"java", "(final String name, @PathVariable(\"id\") long id)", []string{"name", "id"}},
+		{"javascript", "({a, b}, c = 1)", []string{"", "c"}},
+		{"typescript", "(req: Request, res: Response)", []string{"req", "res"}},
+		{"rust", "(&self, mut name: String)", []string{"self", "name"}},
+	}
+	for _, tt := range tests {
+		if got := ParamNames(tt.lang, tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParamNames(%s, %q) = %v, want %v", tt.lang, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestFlowCallName(t *testing.T) {
+	tests := map[string]string{
+		"cursor.execute": "execute",
+		"run_query":      "run_query",
+		"Foo::bar":       "bar",
+		"self.repo.find": "find",
+		"obj->method":    "method",
+	}
+	for callee, want := range tests {
+		if got := (FlowCall{Callee: callee}).Name(); got != want {
+			t.Errorf("FlowCall{%q}.Name() = %q, want %q", callee, got, want)
+		}
+	}
+}
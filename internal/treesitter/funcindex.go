@@ -0,0 +1,178 @@
+package treesitter
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// MaxFlowFiles bounds how many files WalkFlow parses, so a run over a
+// monorepo cannot balloon memory.
+const MaxFlowFiles = 5000
+
+// FlowIgnoreDirs are never descended into by WalkFlow; hidden directories
+// are skipped as well.
+var FlowIgnoreDirs = []string{
+	"node_modules", "vendor", ".git", ".quokka",
+	"__pycache__", "target", "dist", "build",
+}
+
+// WalkFlow extracts def-use facts from every file under root that
+// ExtractFlow supports and passes each to fn, with Path set to the
+// slash-separated path relative to root. The walk stops after MaxFlowFiles
+// files and reports truncated. Files that fail to parse, other than
+// oversized ones, are listed in notes. An error from fn ends the walk and
+// is returned.
+func (p *Parser) WalkFlow(root string, fn func(ff *FileFlow) error) (notes []string, truncated bool, err error) {
+	parsed := 0
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			name := info.Name()
+			if path != root && (strings.HasPrefix(name, ".") || slices.Contains(FlowIgnoreDirs, name)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !p.CanHandleFlow(path) {
+			return nil
+		}
+		if parsed >= MaxFlowFiles {
+			truncated = true
+			return filepath.SkipAll
+		}
+		relPath, _ := filepath.Rel(root, path)
+		relPath = filepath.ToSlash(relPath)
+		ff, err := p.ExtractFlow(path, relPath)
+		if err != nil {
+			var skipped *SkippedFileError
+			if !errors.As(err, &skipped) {
+				notes = append(notes, fmt.Sprintf("%s: %v", relPath, err))
+			}
+			return nil
+		}
+		parsed++
+		return fn(ff)
+	})
+	if err != nil {
+		return notes, truncated, fmt.Errorf("walk project: %w", err)
+	}
+	return notes, truncated, nil
+}
+
+// FuncRef identifies a function by file and index into FileFlow.Functions;
+// Index -1 is the file's module-level code.
+type FuncRef struct {
+	File  string
+	Index int
+}
+
+// FuncIndex indexes the function definitions of parsed files by name, so a
+// call site can be resolved to the project functions it may invoke.
+type FuncIndex struct {
+	files  map[string]*FileFlow
+	order  []string
+	byName map[string][]FuncRef
+}
+
+// NewFuncIndex returns an empty index
+func NewFuncIndex() *FuncIndex {
+	return &FuncIndex{
+		files:  make(map[string]*FileFlow),
+		byName: make(map[string][]FuncRef),
+	}
+}
+
+// Add indexes a parsed file's function definitions under its
+// slash-separated path.
+func (ix *FuncIndex) Add(ff *FileFlow) {
+	rel := filepath.ToSlash(ff.Path)
+	ix.files[rel] = ff
+	ix.order = append(ix.order, rel)
+	for i, fn := range ff.Functions {
+		ix.byName[fn.Name] = append(ix.byName[fn.Name], FuncRef{File: rel, Index: i})
+	}
+}
+
+// File returns the indexed file with the given path, or nil
+func (ix *FuncIndex) File(rel string) *FileFlow {
+	return ix.files[rel]
+}
+
+// Files returns the indexed paths in the order they were added
+func (ix *FuncIndex) Files() []string {
+	return ix.order
+}
+
+// Named returns every function with the given name
+func (ix *FuncIndex) Named(name string) []FuncRef {
+	return ix.byName[name]
+}
+
+// Function returns the definition ref points to; ref must not be
+// module-level.
+func (ix *FuncIndex) Function(ref FuncRef) FlowFunction {
+	return ix.files[ref.File].Functions[ref.Index]
+}
+
+// Resolve maps a call site in file to candidate project functions.
+// Qualified calls ("helpers.run_query", "Repo.find") must name the callee's
+// module, package directory or class; bare and self/this calls prefer the
+// caller's file. Method calls on receivers whose type is unknown resolve
+// only when the method name is near-unique in the project.
+func (ix *FuncIndex) Resolve(file string, c FlowCall) []FuncRef {
+	name := c.Name()
+	cands := ix.byName[name]
+	if len(cands) == 0 {
+		return nil
+	}
+	qualifier := strings.TrimSuffix(c.Callee, name)
+	qualifier = strings.TrimRight(qualifier, ".:->")
+	qualLast := qualifier
+	if idx := strings.LastIndexAny(qualLast, ".:>"); idx >= 0 {
+		qualLast = qualLast[idx+1:]
+	}
+
+	var sameFile, matched []FuncRef
+	for _, ref := range cands {
+		fn := ix.Function(ref)
+		if ref.File == file {
+			sameFile = append(sameFile, ref)
+		}
+		if qualLast == "" {
+			continue
+		}
+		base := strings.TrimSuffix(filepath.Base(ref.File), filepath.Ext(ref.File))
+		dir := filepath.Base(filepath.Dir(ref.File))
+		if qualLast == base || qualLast == dir || qualLast == fn.Parent {
+			matched = append(matched, ref)
+		}
+	}
+
+	switch {
+	case len(matched) > 0:
+		return matched
+	case qualifier == "" || qualifier == "self" || qualifier == "this" || qualifier == "cls":
+		if len(sameFile) > 0 {
+			return sameFile
+		}
+		if len(cands) <= 3 {
+			return cands
+		}
+	case len(cands) <= 2:
+		// Method call on a receiver whose type we cannot see: accept only a
+		// near-unique method name.
+		for _, ref := range cands {
+			if ix.Function(ref).Parent == "" && ix.files[ref.File].Language != "go" {
+				return nil
+			}
+		}
+		return cands
+	}
+	return nil
+}
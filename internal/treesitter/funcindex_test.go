@@ -0,0 +1,64 @@
+package treesitter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWalkFlowAndResolve(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"app/views.py": `from app import helpers
+
+def view(request):
+    return helpers.run_query(request.args["id"])
+
+def local():
+    return view(None)
+`,
+		"app/helpers.py": `def run_query(q):
+    return db.execute(q)
+`,
+		"node_modules/lib/index.js": "function run_query(q) { return q }\n",
+		".venv/site.py":             "def run_query(q):\n    pass\n",
+	}
+	for rel, content := range files {
+		path := filepath.Join(root, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ix := NewFuncIndex()
+	notes, truncated, err := NewParser().WalkFlow(root, func(ff *FileFlow) error {
+		ix.Add(ff)
+		return nil
+	})
+	if err != nil || truncated || len(notes) > 0 {
+		t.Fatalf("WalkFlow: err=%v truncated=%v notes=%v", err, truncated, notes)
+	}
+	if got := ix.Files(); len(got) != 2 || ix.File("app/views.py") == nil || ix.File("app/helpers.py") == nil {
+		t.Fatalf("indexed files = %v, want app/helpers.py and app/views.py only", got)
+	}
+
+	calls := map[string]FlowCall{}
+	for _, c := range ix.File("app/views.py").Calls {
+		calls[c.Callee] = c
+	}
+	targets := ix.Resolve("app/views.py", calls["helpers.run_query"])
+	if len(targets) != 1 || targets[0].File != "app/helpers.py" || ix.Function(targets[0]).Name != "run_query" {
+		t.Errorf("helpers.run_query resolved to %+v", targets)
+	}
+	targets = ix.Resolve("app/views.py", calls["view"])
+	if len(targets) != 1 || targets[0].File != "app/views.py" {
+		t.Errorf("view resolved to %+v", targets)
+	}
+	execute := ix.File("app/helpers.py").Calls[0]
+	if targets := ix.Resolve("app/helpers.py", execute); execute.Callee != "db.execute" || len(targets) != 0 {
+		t.Errorf("%s resolved to %+v, want none", execute.Callee, targets)
+	}
+}
@@ -0,0 +1,101 @@
+package treesitter
+
+import "regexp"
+
+// identRE matches one identifier token (Ruby instance variables included).
+var identRE = regexp.MustCompile(`[A-Za-z_$@][A-Za-z0-9_$]*`)
+
+// Flow patterns feed ExtractFlow. Each entry is compiled as its own query and
+// must use the shared capture names:
+//
+//	@function (+ @name, @params)  function/method definitions
+//	@call (+ @args)               call expressions; callee = text before @args
+//	@assign (+ @left, @right)     assignments and initialised declarations
+//	@return (+ @value)            return statements with a value
+
+var pythonFlowPatterns = []string{
+	`(function_definition name: (identifier) @name parameters: (parameters) @params) @function`,
+	`(call function: (_) arguments: (argument_list) @args) @call`,
+	`(assignment left: (_) @left right: (_) @right) @assign`,
+	`(augmented_assignment left: (_) @left right: (_) @right) @assign`,
+	`(return_statement (_) @value) @return`,
+}
+
+var goFlowPatterns = []string{
+	`(function_declaration name: (identifier) @name parameters: (parameter_list) @params) @function`,
+	`(method_declaration name: (field_identifier) @name parameters: (parameter_list) @params) @function`,
+	`(call_expression function: (_) arguments: (argument_list) @args) @call`,
+	`(short_var_declaration left: (expression_list) @left right: (expression_list) @right) @assign`,
+	`(assignment_statement left: (expression_list) @left right: (expression_list) @right) @assign`,
+	`(var_spec name: (identifier) @left value: (expression_list) @right) @assign`,
+	`(return_statement (expression_list) @value) @return`,
+}
+
+var javascriptFlowPatterns = []string{
+	`(function_declaration name: (identifier) @name parameters: (formal_parameters) @params) @function`,
+	`(method_definition name: (property_identifier) @name parameters: (formal_parameters) @params) @function`,
+	`(variable_declarator name: (identifier) @name value: (arrow_function parameters: (formal_parameters) @params)) @function`,
+	`(variable_declarator name: (identifier) @name value: (function_expression parameters: (formal_parameters) @params)) @function`,
+	`(call_expression function: (_) arguments: (arguments) @args) @call`,
+	`(new_expression constructor: (_) arguments: (arguments) @args) @call`,
+	`(variable_declarator name: (identifier) @left value: (_) @right) @assign`,
+	`(assignment_expression left: (_) @left right: (_) @right) @assign`,
+	`(return_statement (_) @value) @return`,
+}
+
+var javaFlowPatterns = []string{
+	`(method_declaration name: (identifier) @name parameters: (formal_parameters) @params) @function`,
+	`(constructor_declaration name: (identifier) @name parameters: (formal_parameters) @params) @function`,
+	`(method_invocation arguments: (argument_list) @args) @call`,
+	`(object_creation_expression arguments: (argument_list) @args) @call`,
+	`(variable_declarator name: (identifier) @left value: (_) @right) @assign`,
+	`(assignment_expression left: (_) @left right: (_) @right) @assign`,
+	`(return_statement (_) @value) @return`,
+}
+
+var rubyFlowPatterns = []string{
+	`(method name: (identifier) @name parameters: (method_parameters) @params) @function`,
+	`(method name: (identifier) @name) @function`,
+	`(call arguments: (argument_list) @args) @call`,
+	`(assignment left: (_) @left right: (_) @right) @assign`,
+	`(return (argument_list) @value) @return`,
+}
+
+var rustFlowPatterns = []string{
+	`(function_item name: (identifier) @name parameters: (parameters) @params) @function`,
+	`(call_expression function: (_) arguments: (arguments) @args) @call`,
+	`(let_declaration pattern: (_) @left value: (_) @right) @assign`,
+	`(assignment_expression left: (_) @left right: (_) @right) @assign`,
+	`(return_expression (_) @value) @return`,
+}
+
+var cFlowPatterns = []string{
+	`(function_definition declarator: (function_declarator declarator: (identifier) @name parameters: (parameter_list) @params)) @function`,
+	`(call_expression function: (_) arguments: (argument_list) @args) @call`,
+	`(init_declarator declarator: (_) @left value: (_) @right) @assign`,
+	`(assignment_expression left: (_) @left right: (_) @right) @assign`,
+	`(return_statement (_) @value) @return`,
+}
+
+// getFlowPatterns returns the def-use query patterns for a language, or nil
+// when flow extraction is unsupported.
+func getFlowPatterns(language string) []string {
+	switch language {
+	case "python":
+		return pythonFlowPatterns
+	case "go":
+		return goFlowPatterns
+	case "javascript", "javascriptreact", "typescript", "typescriptreact":
+		return javascriptFlowPatterns
+	case "java":
+		return javaFlowPatterns
+	case "ruby":
+		return rubyFlowPatterns
+	case "rust":
+		return rustFlowPatterns
+	case "c", "cpp":
+		return cFlowPatterns
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,118 @@
+package treesitter
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"github.com/odvcencio/gotreesitter"
+	"github.com/odvcencio/gotreesitter/grammars"
+)
+
+// Query is a user-supplied tree-sitter S-expression query, such as the body
+// of a quokka query rule. It is compiled lazily per grammar, so a rule for
+// "typescript" also runs over .tsx files parsed with the TSX grammar.
+// Predicates (#eq?, #match?, #any-of?, #not-match? ...) are evaluated by the
+// query engine while matching.
+type Query struct {
+	source string
+
+	mu       sync.Mutex
+	compiled map[*gotreesitter.Language]*gotreesitter.Query
+}
+
+// QueryCapture is one captured node of a match.
+type QueryCapture struct {
+	Name      string
+	Text      string
+	Line      int
+	EndLine   int
+	StartByte int
+	EndByte   int
+}
+
+// QueryMatch is one match of a query: the index of the pattern that matched
+// and its captures, in query order.
+type QueryMatch struct {
+	Pattern  int
+	Captures []QueryCapture
+}
+
+// CompileQuery compiles a query against the grammar of the named language
+// (a DetectLanguageName value such as "python" or "go"), so syntax errors
+// and unknown node types are reported before any file is scanned.
+func CompileQuery(language, query string) (*Query, error) {
+	entry := grammars.DetectLanguageByName(language)
+	if entry == nil {
+		return nil, fmt.Errorf("tree-sitter does not support language %q", language)
+	}
+	q := &Query{source: query, compiled: map[*gotreesitter.Language]*gotreesitter.Query{}}
+	if _, err := q.forLanguage(entry.Language()); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// Match parses source with the grammar for relPath and returns every match
+// of the query, in document order.
+func (q *Query) Match(source []byte, relPath string) ([]QueryMatch, error) {
+	if grammars.DetectLanguage(filepath.Base(relPath)) == nil {
+		return nil, fmt.Errorf("tree-sitter does not support: %s", relPath)
+	}
+	bt, err := grammars.ParseFilePooled(relPath, source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse: %w", err)
+	}
+	defer bt.Release()
+
+	root := bt.RootNode()
+	lang := bt.Language()
+	if bt.NodeType(root) == "" {
+		return nil, fmt.Errorf("tree-sitter failed to parse %s", relPath)
+	}
+	cq, err := q.forLanguage(lang)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []QueryMatch
+	cursor := cq.Exec(root, lang, source)
+	for {
+		m, ok := cursor.NextMatch()
+		if !ok {
+			break
+		}
+		qm := QueryMatch{Pattern: m.PatternIndex}
+		for _, c := range m.Captures {
+			if c.Node == nil {
+				continue
+			}
+			qm.Captures = append(qm.Captures, QueryCapture{
+				Name:      c.Name,
+				Text:      c.Text(source),
+				Line:      int(c.Node.StartPoint().Row) + 1,
+				EndLine:   int(c.Node.EndPoint().Row) + 1,
+				StartByte: int(c.Node.StartByte()),
+				EndByte:   int(c.Node.EndByte()),
+			})
+		}
+		if len(qm.Captures) > 0 {
+			out = append(out, qm)
+		}
+	}
+	return out, nil
+}
+
+func (q *Query) forLanguage(lang *gotreesitter.Language) (*gotreesitter.Query, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if cq, ok := q.compiled[lang]; ok {
+		return cq, nil
+	}
+	cq, err := gotreesitter.NewQuery(q.source, lang)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile query: %w", err)
+	}
+	q.compiled[lang] = cq
+	return cq, nil
+}
@@ -0,0 +1,54 @@
+package treesitter
+
+import "testing"
+
+const shellTrueQuery = `(call
+  function: (attribute object: (identifier) @mod attribute: (identifier) @fn)
+  arguments: (argument_list (keyword_argument name: (identifier) @kw value: (true)))) @match
+(#eq? @mod "subprocess")
+(#eq? @kw "shell")`
+
+func TestCompileQuery_MatchWithPredicates(t *testing.T) {
+	q, err := CompileQuery("python", shellTrueQuery)
+	if err != nil {
+		t.Fatalf("CompileQuery: %v", err)
+	}
+	source := []byte(`import subprocess
+
+def run(cmd):
+    subprocess.run(cmd, shell=True)
+    subprocess.run(["ls"], shell=False)
+    other.run(cmd, shell=True)
+`)
+	matches, err := q.Match(source, "app/run.py")
+	if err != nil {
+		t.Fatalf("Match: %v", err)
+	}
+	if len(matches) != 1 {
+		t.Fatalf("got %d matches, want 1: %+v", len(matches), matches)
+	}
+	var match, fn *QueryCapture
+	for i, c := range matches[0].Captures {
+		switch c.Name {
+		case "match":
+			match = &matches[0].Captures[i]
+		case "fn":
+			fn = &matches[0].Captures[i]
+		}
+	}
+	if match == nil || match.Line != 4 || match.Text != "subprocess.run(cmd, shell=True)" {
+		t.Errorf("match capture = %+v", match)
+	}
+	if fn == nil || fn.Text != "run" {
+		t.Errorf("fn capture = %+v", fn)
+	}
+}
+
+func TestCompileQuery_Errors(t *testing.T) {
+	if _, err := CompileQuery("cobol", "(call) @c"); err == nil {
+		t.Error("expected error for unsupported language")
+	}
+	if _, err := CompileQuery("python", "(call"); err == nil {
+		t.Error("expected error for malformed query")
+	}
+}
@@ -0,0 +1,86 @@
+package treesitter
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/odvcencio/gotreesitter"
+	"github.com/odvcencio/gotreesitter/grammars"
+)
+
+// TokenKind classifies a Token.
+type TokenKind int
+
+const (
+	// TokenOther is punctuation, an operator, a keyword or a number.
+	TokenOther TokenKind = iota
+	TokenIdent
+	TokenString
+	TokenComment
+)
+
+// Token is one leaf of a file's syntax tree. String literals, including
+// interpolated ones, are a single token.
+type Token struct {
+	Kind      TokenKind
+	Text      string
+	Line      int
+	StartByte int
+	EndByte   int
+}
+
+// stringNodes are the literal node types kept whole by ExtractTokens, so
+// interpolations and escapes inside them are never taken for code.
+var stringNodes = map[string]bool{
+	"string": true, "string_literal": true, "raw_string_literal": true,
+	"interpreted_string_literal": true, "template_string": true,
+	"char_literal": true, "character_literal": true, "rune_literal": true,
+	"text_block": true, "encapsed_string": true,
+}
+
+// ExtractTokens returns the leaves of source's syntax tree in document
+// order. Zero-width nodes inserted by error recovery are dropped.
+func (p *Parser) ExtractTokens(source []byte, relPath string) ([]Token, error) {
+	if DetectLanguageName(relPath) == "" || grammars.DetectLanguage(filepath.Base(relPath)) == nil {
+		return nil, fmt.Errorf("tree-sitter does not support: %s", relPath)
+	}
+	bt, err := grammars.ParseFilePooled(relPath, source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse: %w", err)
+	}
+	defer bt.Release()
+
+	var out []Token
+	var walk func(n *gotreesitter.Node)
+	walk = func(n *gotreesitter.Node) {
+		if n == nil || n.IsMissing() || n.EndByte() <= n.StartByte() {
+			return
+		}
+		typ := bt.NodeType(n)
+		kind, leaf := TokenOther, n.ChildCount() == 0
+		switch {
+		case stringNodes[typ]:
+			kind, leaf = TokenString, true
+		case strings.Contains(typ, "comment"):
+			kind, leaf = TokenComment, true
+		case leaf && n.IsNamed() && (strings.HasSuffix(typ, "identifier") || typ == "constant"):
+			kind = TokenIdent
+		}
+		if !leaf {
+			for i := 0; i < n.ChildCount(); i++ {
+				walk(n.Child(i))
+			}
+			return
+		}
+		out = append(out, Token{
+			Kind:      kind,
+			Text:      n.Text(source),
+			Line:      int(n.StartPoint().Row) + 1,
+			StartByte: int(n.StartByte()),
+			EndByte:   int(n.EndByte()),
+		})
+	}
+	walk(bt.RootNode())
+	return out, nil
+}
@@ -0,0 +1,43 @@
+package treesitter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExtractTokens_KeepsLiteralsWhole(t *testing.T) {
+	cases := map[string]string{
+		"a.py":   "q = f\"a {x} b\" + y  # c(d)\n",
+		"a.go":   "package a\n\nvar q = `a ${x} b` + y // c(d)\n",
+		"a.js":   "const q = `a ${x} b` + y; // c(d)\n",
+		"A.java": "class A { String q = \"a (x) b\" + y; // c(d)\n}\n",
+		"a.rb":   "q = \"a #{x} b\" + y # c(d)\n",
+		"a.rs":   "const Q: &str = \"a {x} b\"; // c(d)\n",
+		"a.c":    "char *q = \"a (x) b\"; // c(d)\n",
+	}
+	p := NewParser()
+	for file, src := range cases {
+		toks, err := p.ExtractTokens([]byte(src), file)
+		if err != nil {
+			t.Errorf("%s: %v", file, err)
+			continue
+		}
+		kinds := map[TokenKind]int{}
+		for _, tok := range toks {
+			kinds[tok.Kind]++
+			if tok.Text != src[tok.StartByte:tok.EndByte] || tok.Line != 1+strings.Count(src[:tok.StartByte], "\n") {
+				t.Errorf("%s: token %+v does not match its span", file, tok)
+			}
+			if tok.Kind == TokenIdent && (tok.Text == "x" || tok.Text == "d") {
+				t.Errorf("%s: %q inside a literal or comment became an identifier", file, tok.Text)
+			}
+		}
+		if kinds[TokenString] != 1 || kinds[TokenComment] != 1 || kinds[TokenIdent] == 0 {
+			t.Errorf("%s: token kinds = %v", file, kinds)
+		}
+	}
+
+	if _, err := p.ExtractTokens([]byte("x"), "a.txt"); err == nil {
+		t.Error("expected error for unsupported file")
+	}
+}
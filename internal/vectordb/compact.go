@@ -0,0 +1,268 @@
+package vectordb
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// compactPendingKey marks a compaction whose metadata remap has committed
+// but whose rebuilt vectors file may not have replaced vectors.bin yet
+const compactPendingKey = "compaction_pending"
+
+// compactSuffix names the rebuilt index while it is being written
+const compactSuffix = ".compact"
+
+// CompactStats describes the outcome of an index compaction
+type CompactStats struct {
+	// Slots is the number of vector slots before compaction (live + tombstones)
+	Slots int `json:"slots"`
+	// Tombstones is the number of deleted slots awaiting reuse
+	Tombstones int `json:"tombstones"`
+	// Vectors is the number of vectors kept, renumbered 0..Vectors-1
+	Vectors int `json:"vectors"`
+	// Orphans are live vectors no chunk referenced; they were dropped
+	Orphans int `json:"orphans"`
+	// Missing are chunks whose vector was absent; their vector_idx is cleared
+	Missing int `json:"missing"`
+	// Repaired is the number of graph nodes whose neighbor lists were rebuilt
+	Repaired int `json:"repaired"`
+	// Duration is how long the compaction took
+	Duration time.Duration `json:"duration"`
+}
+
+// Tombstones returns the number of deleted vector slots awaiting reuse
+func (s *HNSWStore) Tombstones() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.freeList)
+}
+
+// needsCompaction reports whether tombstones passed the configured threshold.
+// Callers must hold s.mu.
+func (s *HNSWStore) needsCompaction() bool {
+	if s.config.CompactRatio <= 0 || s.nextIdx == 0 {
+		return false
+	}
+	tombstones := len(s.freeList)
+	if tombstones < s.config.CompactMinTombstones {
+		return false
+	}
+	return float64(tombstones)/float64(s.nextIdx) >= s.config.CompactRatio
+}
+
+// MaybeCompact compacts the index when the tombstone ratio passes
+// config.CompactRatio. It returns nil stats when nothing was done.
+func (s *HNSWStore) MaybeCompact() (*CompactStats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.index.diskMode || !s.needsCompaction() {
+		return nil, nil
+	}
+	return s.compact()
+}
+
+// Compact drops tombstoned and orphaned vectors, renumbers the survivors
+// densely, rebuilds the neighbor graph and rewrites vectors.bin. The chunk
+// vector_idx remap commits in a single SQLite transaction; a crash before
+// the new vectors file is in place is finished on the next open.
+func (s *HNSWStore) Compact() (*CompactStats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.index.diskMode {
+		return nil, fmt.Errorf("cannot compact while the index is in disk mode")
+	}
+	return s.compact()
+}
+
+// compact does the work of Compact. Callers must hold s.mu.
+func (s *HNSWStore) compact() (*CompactStats, error) {
+	start := time.Now()
+
+	live, err := s.meta.LiveVectorIdx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list live vectors: %w", err)
+	}
+
+	index, mapping, stats, err := s.index.compact(live, s.config.Path)
+	if err != nil {
+		return nil, err
+	}
+	stats.Slots = s.nextIdx
+	stats.Tombstones = len(s.freeList)
+
+	indexPath := filepath.Join(s.config.Path, "vectors.bin")
+	tmpPath := indexPath + compactSuffix
+	offsets, err := s.writeIndex(tmpPath, index, len(mapping), nil)
+	if err != nil {
+		index.close()
+		_ = os.Remove(tmpPath)
+		return nil, fmt.Errorf("failed to write compacted index: %w", err)
+	}
+
+	if err := s.meta.RemapVectorIdx(mapping, compactPendingKey, filepath.Base(tmpPath)); err != nil {
+		index.close()
+		_ = os.Remove(tmpPath)
+		return nil, fmt.Errorf("failed to remap chunk vectors: %w", err)
+	}
+
+	// From here the metadata refers to the new numbering, so the in-memory
+	// index is swapped even if finishing the file rename fails; the pending
+	// marker lets the next open complete it
+	s.index.close()
+	s.index = index
+	s.nextIdx = len(mapping)
+	s.freeList = []int{}
+
+	if err := os.Rename(tmpPath, indexPath); err != nil {
+		return nil, fmt.Errorf("failed to replace index file: %w", err)
+	}
+	if err := s.meta.DeleteMeta(compactPendingKey); err != nil {
+		return nil, err
+	}
+	if err := index.rebase(indexPath, offsets); err != nil {
+		return nil, err
+	}
+
+	stats.Duration = time.Since(start)
+	return stats, nil
+}
+
+// recoverCompaction finishes or discards a compaction interrupted by a
+// crash, before vectors.bin is loaded
+func (s *HNSWStore) recoverCompaction() error {
+	indexPath := filepath.Join(s.config.Path, "vectors.bin")
+	tmpPath := indexPath + compactSuffix
+
+	pending, err := s.meta.GetMeta(compactPendingKey)
+	if err != nil {
+		return err
+	}
+	_, statErr := os.Stat(tmpPath)
+
+	switch {
+	case pending != "" && statErr == nil:
+		// The remap committed: the rebuilt file matches the metadata
+		if err := os.Rename(tmpPath, indexPath); err != nil {
+			return err
+		}
+		return s.meta.DeleteMeta(compactPendingKey)
+	case pending != "":
+		// The rename happened; only the marker was left behind
+		return s.meta.DeleteMeta(compactPendingKey)
+	case statErr == nil:
+		// The remap never committed: the old file still matches
+		return os.Remove(tmpPath)
+	}
+	return nil
+}
+
+// compact returns a new index holding only the vectors in live, renumbered
+// 0..n-1 in ascending order of their old index, with the old-to-new
+// mapping. Neighbor lists are remapped; nodes that lost more than half of
+// their neighbors are reconnected to their nearest live vectors. A
+// quantized index gets a new scratch file in dir.
+func (h *hnswIndex) compact(live []int, dir string) (*hnswIndex, map[int]int, *CompactStats, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	stats := &CompactStats{}
+	out := newHNSWIndex(h.dimension, h.m, h.efConstruction, h.efSearch)
+	if h.quantized {
+		if err := out.enableQuantization(dir, h.rerank); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	mapping := make(map[int]int, len(live))
+
+	sorted := append([]int(nil), live...)
+	sort.Ints(sorted)
+	for _, oldIdx := range sorted {
+		vec, ok := h.vectorOf(oldIdx)
+		if !ok || h.deleted[oldIdx] {
+			stats.Missing++
+			continue
+		}
+		newIdx := len(mapping)
+		mapping[oldIdx] = newIdx
+		if out.quantized {
+			out.storeQuantized(newIdx, vec)
+		} else {
+			out.vectors[newIdx] = vec
+		}
+	}
+
+	countOrphan := func(idx int) {
+		if _, kept := mapping[idx]; !kept && !h.deleted[idx] {
+			stats.Orphans++
+		}
+	}
+	for idx := range h.vectors {
+		countOrphan(idx)
+	}
+	for idx := range h.codes {
+		countOrphan(idx)
+	}
+
+	want := min(h.m, len(mapping)-1)
+	for oldIdx, newIdx := range mapping {
+		old := h.neighbors[oldIdx]
+		neighbors := make([]int, 0, len(old))
+		for _, n := range old {
+			if mapped, ok := mapping[n]; ok && mapped != newIdx {
+				neighbors = append(neighbors, mapped)
+			}
+		}
+		if !out.quantized && len(neighbors) < len(old) && len(neighbors)*2 < want {
+			neighbors = out.nearest(newIdx, want)
+			stats.Repaired++
+		}
+		if len(neighbors) > 0 {
+			out.neighbors[newIdx] = neighbors
+		}
+	}
+
+	stats.Vectors = len(mapping)
+	return out, mapping, stats, nil
+}
+
+// vectorOf returns a vector from memory or, when quantized, from disk.
+// Callers must hold h.mu.
+func (h *hnswIndex) vectorOf(idx int) ([]float32, bool) {
+	if vec, ok := h.vectors[idx]; ok {
+		return vec, true
+	}
+	if !h.quantized {
+		return nil, false
+	}
+	vec, err := h.fullVector(idx)
+	return vec, err == nil
+}
+
+// nearest returns the m closest vectors to idx, excluding idx itself
+func (h *hnswIndex) nearest(idx, m int) []int {
+	vector := h.vectors[idx]
+	candidates := make([]searchCandidate, 0, len(h.vectors))
+	for other, vec := range h.vectors {
+		if other == idx || h.deleted[other] {
+			continue
+		}
+		candidates = append(candidates, searchCandidate{idx: other, distance: cosineDistance(vector, vec)})
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+		return candidates[i].idx < candidates[j].idx
+	})
+
+	neighbors := make([]int, 0, m)
+	for i := 0; i < len(candidates) && i < m; i++ {
+		neighbors = append(neighbors, candidates[i].idx)
+	}
+	return neighbors
+}
@@ -0,0 +1,199 @@
+package vectordb
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/diffsec/quokka/internal/chunk"
+)
+
+func newTestHNSWStore(t *testing.T, dir string) *HNSWStore {
+	t.Helper()
+	config := DefaultStoreConfig(dir, 3)
+	config.M = 2
+	config.CompactMinTombstones = 2
+	store, err := NewHNSWStore(config)
+	if err != nil {
+		t.Fatalf("NewHNSWStore: %v", err)
+	}
+	return store
+}
+
+func testChunk(file string, n int) *chunk.Chunk {
+	return &chunk.Chunk{
+		ID:          fmt.Sprintf("%s#%d", file, n),
+		File:        file,
+		Language:    "go",
+		Type:        chunk.ChunkFunction,
+		Name:        fmt.Sprintf("fn%d", n),
+		Content:     "func() {}",
+		StartLine:   n,
+		EndLine:     n,
+		ContentHash: fmt.Sprintf("%d", n),
+	}
+}
+
+// testVector gives each chunk a distinct direction so searches identify it
+func testVector(n int) []float32 {
+	return []float32{float32(n + 1), 1, float32(n % 3)}
+}
+
+// TestRemapVectorIdxOverlappingRanges: remapping 4->0 while 0 is still in
+// use must not collide, and unmapped indices are cleared
+func TestRemapVectorIdxOverlappingRanges(t *testing.T) {
+	store := newTestMetaStore(t)
+	for i, idx := range []int{0, 2, 4} {
+		if err := store.Insert(testChunk("a.go", i), idx); err != nil {
+			t.Fatalf("Insert: %v", err)
+		}
+	}
+
+	if err := store.RemapVectorIdx(map[int]int{2: 0, 4: 1}, "k", "v"); err != nil {
+		t.Fatalf("RemapVectorIdx: %v", err)
+	}
+
+	live, err := store.LiveVectorIdx()
+	if err != nil {
+		t.Fatalf("LiveVectorIdx: %v", err)
+	}
+	if fmt.Sprint(live) != "[0 1]" {
+		t.Errorf("live = %v, want [0 1]", live)
+	}
+	if c, err := store.GetByVectorIdx(1); err != nil || c.ID != "a.go#2" {
+		t.Errorf("vector 1 = %v, %v; want a.go#2", c, err)
+	}
+	if v, _ := store.GetMeta("k"); v != "v" {
+		t.Errorf("meta k = %q, want v (written in the remap transaction)", v)
+	}
+}
+
+func TestCompactReclaimsTombstones(t *testing.T) {
+	dir := t.TempDir()
+	store := newTestHNSWStore(t, dir)
+
+	var chunks []*chunk.Chunk
+	var vectors [][]float32
+	for i := 0; i < 6; i++ {
+		chunks = append(chunks, testChunk(fmt.Sprintf("f%d.go", i%3), i))
+		vectors = append(vectors, testVector(i))
+	}
+	if err := store.InsertBatch(chunks, vectors); err != nil {
+		t.Fatalf("InsertBatch: %v", err)
+	}
+	if err := store.DeleteByFile("f1.go"); err != nil {
+		t.Fatalf("DeleteByFile: %v", err)
+	}
+	if store.Tombstones() != 2 {
+		t.Fatalf("tombstones = %d, want 2", store.Tombstones())
+	}
+
+	stats, err := store.Compact()
+	if err != nil {
+		t.Fatalf("Compact: %v", err)
+	}
+	if stats.Slots != 6 || stats.Vectors != 4 || stats.Tombstones != 2 || stats.Missing != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+	if store.Tombstones() != 0 || store.nextIdx != 4 {
+		t.Errorf("after compact: tombstones %d, nextIdx %d", store.Tombstones(), store.nextIdx)
+	}
+	for idx, neighbors := range store.index.neighbors {
+		for _, n := range neighbors {
+			if n < 0 || n >= 4 || n == idx {
+				t.Errorf("node %d has dangling neighbor %d", idx, n)
+			}
+		}
+	}
+
+	// Every surviving chunk is still its own nearest neighbor
+	for i, c := range chunks {
+		if c.File == "f1.go" {
+			continue
+		}
+		res, err := store.Search(vectors[i], 1, nil)
+		if err != nil || len(res.Results) != 1 || res.Results[0].Chunk.ID != c.ID {
+			t.Errorf("search for %s: %+v, %v", c.ID, res, err)
+		}
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	// The compacted numbering survives a reopen
+	reopened := newTestHNSWStore(t, dir)
+	defer func() { _ = reopened.Close() }()
+	if reopened.nextIdx != 4 || reopened.Tombstones() != 0 {
+		t.Errorf("reopened: nextIdx %d, tombstones %d", reopened.nextIdx, reopened.Tombstones())
+	}
+	res, err := reopened.Search(vectors[5], 1, nil)
+	if err != nil || len(res.Results) != 1 || res.Results[0].Chunk.ID != chunks[5].ID {
+		t.Errorf("search after reopen: %+v, %v", res, err)
+	}
+}
+
+func TestMaybeCompactThreshold(t *testing.T) {
+	store := newTestHNSWStore(t, t.TempDir())
+	defer func() { _ = store.Close() }()
+
+	for i := 0; i < 10; i++ {
+		if err := store.Insert(testChunk(fmt.Sprintf("f%d.go", i), i), testVector(i)); err != nil {
+			t.Fatalf("Insert: %v", err)
+		}
+	}
+	_ = store.DeleteByFile("f0.go")
+	if stats, err := store.MaybeCompact(); err != nil || stats != nil {
+		t.Fatalf("1/10 tombstones compacted: %+v, %v", stats, err)
+	}
+	_ = store.DeleteByFile("f1.go")
+	stats, err := store.MaybeCompact()
+	if err != nil || stats == nil || stats.Vectors != 8 {
+		t.Fatalf("2/10 tombstones: %+v, %v", stats, err)
+	}
+}
+
+// TestRecoverCompaction: a crash after the remap committed but before the
+// rename leaves the marker and the .compact file; reopening finishes it
+func TestRecoverCompaction(t *testing.T) {
+	dir := t.TempDir()
+	indexPath := filepath.Join(dir, "vectors.bin")
+
+	store := newTestHNSWStore(t, dir)
+	if err := store.Insert(testChunk("a.go", 0), testVector(0)); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	if err := store.SaveIndex(); err != nil {
+		t.Fatalf("SaveIndex: %v", err)
+	}
+	if err := os.Rename(indexPath, indexPath+compactSuffix); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.meta.RemapVectorIdx(map[int]int{0: 0}, compactPendingKey, "vectors.bin.compact"); err != nil {
+		t.Fatal(err)
+	}
+	_ = store.meta.Close()
+
+	reopened := newTestHNSWStore(t, dir)
+	defer func() { _ = reopened.Close() }()
+	if _, err := os.Stat(indexPath + compactSuffix); !os.IsNotExist(err) {
+		t.Errorf(".compact file not renamed: %v", err)
+	}
+	if v, _ := reopened.meta.GetMeta(compactPendingKey); v != "" {
+		t.Errorf("pending marker not cleared: %q", v)
+	}
+	if reopened.index.numVectors() != 1 {
+		t.Errorf("vectors = %d, want 1", reopened.index.numVectors())
+	}
+
+	// A leftover .compact without a marker predates the remap and is dropped
+	if err := os.WriteFile(indexPath+compactSuffix, []byte("partial"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := reopened.recoverCompaction(); err != nil {
+		t.Fatalf("recoverCompaction: %v", err)
+	}
+	if _, err := os.Stat(indexPath + compactSuffix); !os.IsNotExist(err) {
+		t.Errorf("stale .compact file kept: %v", err)
+	}
+}
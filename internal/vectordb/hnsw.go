@@ -3,6 +3,7 @@ package vectordb
 import (
 	"encoding/binary"
 	"fmt"
+	"io"
 	"math"
 	"os"
 	"path/filepath"
@@ -50,17 +51,29 @@ func NewHNSWStore(config *StoreConfig) (*HNSWStore, error) {
 		return nil, err
 	}
 
-	// Create HNSW index
-	index := newHNSWIndex(config.Dimension, config.M, config.EfConstruction, config.EfSearch)
-
 	store := &HNSWStore{
 		config:   config,
 		meta:     meta,
-		index:    index,
 		nextIdx:  0,
 		freeList: []int{},
 	}
 
+	// Quantized scratch files are removed on close; clear any left by a crash
+	removeStaleVectorFiles(config.Path)
+
+	// Create HNSW index
+	index, err := store.newIndex()
+	if err != nil {
+		_ = meta.Close()
+		return nil, err
+	}
+	store.index = index
+
+	// Finish or discard a compaction interrupted by a crash
+	if err := store.recoverCompaction(); err != nil {
+		fmt.Printf("Warning: failed to recover interrupted compaction: %v\n", err)
+	}
+
 	// Try to load existing index
 	indexPath := filepath.Join(config.Path, "vectors.bin")
 	if _, err := os.Stat(indexPath); err == nil {
@@ -383,7 +396,12 @@ func (s *HNSWStore) Clear() error {
 	}
 
 	// Reset HNSW index
-	s.index = newHNSWIndex(s.config.Dimension, s.config.M, s.config.EfConstruction, s.config.EfSearch)
+	s.index.close()
+	index, err := s.newIndex()
+	if err != nil {
+		return err
+	}
+	s.index = index
 	s.nextIdx = 0
 	s.freeList = []int{}
 
@@ -405,40 +423,67 @@ func (s *HNSWStore) Close() error {
 		// Log warning but continue
 		fmt.Printf("Warning: failed to save index: %v\n", err)
 	}
+	s.index.close()
 
 	return s.meta.Close()
 }
 
-// saveIndex saves the HNSW index to disk
+// saveSuffix names vectors.bin while a save is being written
+const saveSuffix = ".save"
+
+// saveIndex saves the HNSW index to disk. The index is written next to
+// path and renamed over it, since a quantized index re-ranks from the file
+// it was loaded from until the new one is in place.
 func (s *HNSWStore) saveIndex(path string) error {
-	f, err := os.Create(path)
+	tmpPath := path + saveSuffix
+	offsets, err := s.writeIndex(tmpPath, s.index, s.nextIdx, s.freeList)
 	if err != nil {
+		_ = os.Remove(tmpPath)
 		return err
 	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	return s.index.rebase(path, offsets)
+}
+
+// writeIndex writes index with the given allocation state to path and
+// syncs it, so a rename over vectors.bin never exposes a partial file. It
+// returns the offsets index.save reports.
+func (s *HNSWStore) writeIndex(path string, index *hnswIndex, nextIdx int, freeList []int) (map[int]int64, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
 	defer func() { _ = f.Close() }()
 
 	// Write header
 	header := make([]byte, 24)
 	binary.LittleEndian.PutUint32(header[0:4], uint32(s.config.Dimension))
-	binary.LittleEndian.PutUint32(header[4:8], uint32(s.nextIdx))
-	binary.LittleEndian.PutUint32(header[8:12], uint32(len(s.freeList)))
-	binary.LittleEndian.PutUint32(header[12:16], uint32(s.index.numVectors()))
+	binary.LittleEndian.PutUint32(header[4:8], uint32(nextIdx))
+	binary.LittleEndian.PutUint32(header[8:12], uint32(len(freeList)))
+	binary.LittleEndian.PutUint32(header[12:16], uint32(index.numVectors()))
 	binary.LittleEndian.PutUint32(header[16:20], uint32(s.config.M))
 	binary.LittleEndian.PutUint32(header[20:24], uint32(s.config.EfConstruction))
 
 	if _, err := f.Write(header); err != nil {
-		return err
+		return nil, err
 	}
 
 	// Write free list
-	for _, idx := range s.freeList {
+	for _, idx := range freeList {
 		if err := binary.Write(f, binary.LittleEndian, int32(idx)); err != nil {
-			return err
+			return nil, err
 		}
 	}
 
 	// Write vectors
-	return s.index.save(f)
+	offsets, err := index.save(f)
+	if err != nil {
+		return nil, err
+	}
+	return offsets, f.Sync()
 }
 
 // loadIndex loads the HNSW index from disk
@@ -503,6 +548,18 @@ type hnswIndex struct {
 	vectorFile     *os.File
 	vectorOffsets  map[int]int64 // vector_idx -> file offset
 	graphBuilt     bool          // Whether neighbor graph has been built
+
+	// Quantized mode fields: int8 codes in memory, re-ranked at search time
+	// with full vectors read from vectors.bin as loaded or last saved
+	// (baseFile) or, for vectors inserted since, a scratch vectorFile
+	// created in vectorDir
+	quantized   bool
+	vectorDir   string
+	vectorPath  string
+	rerank      int
+	codes       map[int]int8Code
+	baseFile    *os.File
+	baseOffsets map[int]int64
 }
 
 func newHNSWIndex(dimension, m, efConstruction, efSearch int) *hnswIndex {
@@ -524,8 +581,8 @@ func (h *hnswIndex) EnableDiskMode(path string) error {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
-	if h.diskMode {
-		return nil // Already in disk mode
+	if h.diskMode || h.quantized {
+		return nil // Already in disk mode, or vectors already on disk
 	}
 
 	f, err := os.Create(path)
@@ -588,17 +645,19 @@ func (h *hnswIndex) readVectorAt(offset int64) ([]float32, error) {
 	if h.vectorFile == nil {
 		return nil, fmt.Errorf("vector file not open")
 	}
+	return readVector(h.vectorFile, offset, h.dimension)
+}
 
-	vec := make([]float32, h.dimension)
-	_, err := h.vectorFile.Seek(offset, 0)
-	if err != nil {
+// readVector reads a little-endian float32 vector at offset in f
+func readVector(f *os.File, offset int64, dimension int) ([]float32, error) {
+	buf := make([]byte, dimension*4)
+	if _, err := f.ReadAt(buf, offset); err != nil {
 		return nil, err
 	}
 
+	vec := make([]float32, dimension)
 	for i := range vec {
-		if err := binary.Read(h.vectorFile, binary.LittleEndian, &vec[i]); err != nil {
-			return nil, err
-		}
+		vec[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[i*4:]))
 	}
 
 	return vec, nil
@@ -617,10 +676,12 @@ func (h *hnswIndex) writeVectorToDisk(vector []float32) (int64, error) {
 	}
 
 	// Write vector
-	for _, v := range vector {
-		if err := binary.Write(h.vectorFile, binary.LittleEndian, v); err != nil {
-			return 0, err
-		}
+	buf := make([]byte, len(vector)*4)
+	for i, v := range vector {
+		binary.LittleEndian.PutUint32(buf[i*4:], math.Float32bits(v))
+	}
+	if _, err := h.vectorFile.Write(buf); err != nil {
+		return 0, err
 	}
 
 	return offset, nil
@@ -632,6 +693,12 @@ func (h *hnswIndex) insert(idx int, vector []float32) {
 
 	delete(h.deleted, idx)
 
+	if h.quantized {
+		// The graph is not used by search, so like disk mode it is skipped
+		h.storeQuantized(idx, vector)
+		return
+	}
+
 	if h.diskMode {
 		// Disk mode: write vector to disk, store only offset
 		offset, err := h.writeVectorToDisk(vector)
@@ -687,6 +754,10 @@ func (h *hnswIndex) search(query []float32, k int) []searchCandidate {
 	h.mu.Lock() // Use write lock in case we need to load vectors
 	defer h.mu.Unlock()
 
+	if h.quantized {
+		return h.searchQuantized(query, k, -1)
+	}
+
 	// If in disk mode and vectors not loaded, load them for search
 	if h.diskMode && len(h.vectors) == 0 && len(h.vectorOffsets) > 0 {
 		if err := h.loadVectorsFromDisk(); err != nil {
@@ -722,6 +793,32 @@ func (h *hnswIndex) search(query []float32, k int) []searchCandidate {
 	return candidates
 }
 
+// close releases the vector files; a quantized index's scratch file only
+// holds vectors not yet saved to vectors.bin and is removed
+func (h *hnswIndex) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closeFiles()
+}
+
+// closeFiles closes the vector files and removes a quantized index's
+// scratch file. Callers must hold h.mu.
+func (h *hnswIndex) closeFiles() {
+	if h.vectorFile != nil {
+		_ = h.vectorFile.Close()
+		h.vectorFile = nil
+	}
+	if h.quantized && h.vectorPath != "" {
+		_ = os.Remove(h.vectorPath)
+		h.vectorPath = ""
+	}
+	if h.baseFile != nil {
+		_ = h.baseFile.Close()
+		h.baseFile = nil
+	}
+}
+
 func (h *hnswIndex) delete(idx int) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
@@ -743,6 +840,22 @@ func (h *hnswIndex) numVectors() int {
 		return count
 	}
 
+	// Quantized vectors are on disk, except any that failed to write
+	if h.quantized {
+		count := 0
+		for idx := range h.codes {
+			if !h.deleted[idx] {
+				count++
+			}
+		}
+		for idx := range h.vectors {
+			if !h.deleted[idx] {
+				count++
+			}
+		}
+		return count
+	}
+
 	count := 0
 	for idx := range h.vectors {
 		if !h.deleted[idx] {
@@ -752,7 +865,9 @@ func (h *hnswIndex) numVectors() int {
 	return count
 }
 
-func (h *hnswIndex) save(f *os.File) error {
+// save writes the vectors to f. For a quantized index it returns the
+// offset in f of each disk-backed vector's components.
+func (h *hnswIndex) save(f *os.File) (map[int]int64, error) {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
@@ -761,28 +876,31 @@ func (h *hnswIndex) save(f *os.File) error {
 	if h.diskMode && len(h.vectorOffsets) > 0 {
 		totalVectors = len(h.vectorOffsets)
 	}
+	if h.quantized {
+		totalVectors = len(h.codes) + len(h.vectors)
+	}
 
 	// Write number of vectors
 	if err := binary.Write(f, binary.LittleEndian, int32(totalVectors)); err != nil {
-		return err
+		return nil, err
 	}
 
 	// Write neighbor graph header
 	if err := binary.Write(f, binary.LittleEndian, int32(len(h.neighbors))); err != nil {
-		return err
+		return nil, err
 	}
 
 	// Write neighbor graph
 	for idx, neighbors := range h.neighbors {
 		if err := binary.Write(f, binary.LittleEndian, int32(idx)); err != nil {
-			return err
+			return nil, err
 		}
 		if err := binary.Write(f, binary.LittleEndian, int32(len(neighbors))); err != nil {
-			return err
+			return nil, err
 		}
 		for _, neighborIdx := range neighbors {
 			if err := binary.Write(f, binary.LittleEndian, int32(neighborIdx)); err != nil {
-				return err
+				return nil, err
 			}
 		}
 	}
@@ -790,14 +908,14 @@ func (h *hnswIndex) save(f *os.File) error {
 	// Write vectors from memory
 	for idx, vec := range h.vectors {
 		if err := binary.Write(f, binary.LittleEndian, int32(idx)); err != nil {
-			return err
+			return nil, err
 		}
 		if err := binary.Write(f, binary.LittleEndian, h.deleted[idx]); err != nil {
-			return err
+			return nil, err
 		}
 		for _, v := range vec {
 			if err := binary.Write(f, binary.LittleEndian, v); err != nil {
-				return err
+				return nil, err
 			}
 		}
 	}
@@ -811,27 +929,55 @@ func (h *hnswIndex) save(f *os.File) error {
 			}
 
 			if err := binary.Write(f, binary.LittleEndian, int32(idx)); err != nil {
-				return err
+				return nil, err
 			}
 			if err := binary.Write(f, binary.LittleEndian, h.deleted[idx]); err != nil {
-				return err
+				return nil, err
 			}
 
 			// Read vector from disk file
 			vec, err := h.readVectorAt(offset)
 			if err != nil {
-				return fmt.Errorf("failed to read vector %d from disk: %w", idx, err)
+				return nil, fmt.Errorf("failed to read vector %d from disk: %w", idx, err)
 			}
 
 			for _, v := range vec {
 				if err := binary.Write(f, binary.LittleEndian, v); err != nil {
-					return err
+					return nil, err
 				}
 			}
 		}
 	}
 
-	return nil
+	// Write quantized vectors, recording where each one's components start
+	var offsets map[int]int64
+	if h.quantized {
+		offsets = make(map[int]int64, len(h.codes))
+		for idx := range h.codes {
+			vec, err := h.fullVector(idx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read vector %d from disk: %w", idx, err)
+			}
+			if err := binary.Write(f, binary.LittleEndian, int32(idx)); err != nil {
+				return nil, err
+			}
+			if err := binary.Write(f, binary.LittleEndian, h.deleted[idx]); err != nil {
+				return nil, err
+			}
+			offset, err := f.Seek(0, io.SeekCurrent)
+			if err != nil {
+				return nil, err
+			}
+			offsets[idx] = offset
+			for _, v := range vec {
+				if err := binary.Write(f, binary.LittleEndian, v); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	return offsets, nil
 }
 
 func (h *hnswIndex) load(f *os.File, dimension int) error {
@@ -877,6 +1023,17 @@ func (h *hnswIndex) load(f *os.File, dimension int) error {
 		}
 	}
 
+	// A quantized index re-ranks straight from this file through the
+	// offsets recorded below
+	if h.quantized {
+		base, err := os.Open(f.Name())
+		if err != nil {
+			return fmt.Errorf("failed to open vector file: %w", err)
+		}
+		h.baseFile = base
+		h.baseOffsets = make(map[int]int64, numVectors)
+	}
+
 	// Read each vector
 	for i := int32(0); i < numVectors; i++ {
 		var idx int32
@@ -889,6 +1046,14 @@ func (h *hnswIndex) load(f *os.File, dimension int) error {
 			return err
 		}
 
+		var offset int64
+		if h.quantized {
+			var err error
+			if offset, err = f.Seek(0, io.SeekCurrent); err != nil {
+				return err
+			}
+		}
+
 		vec := make([]float32, dimension)
 		for j := range vec {
 			if err := binary.Read(f, binary.LittleEndian, &vec[j]); err != nil {
@@ -896,7 +1061,12 @@ func (h *hnswIndex) load(f *os.File, dimension int) error {
 			}
 		}
 
-		h.vectors[int(idx)] = vec
+		if h.quantized {
+			h.baseOffsets[int(idx)] = offset
+			h.codes[int(idx)] = quantizeInt8(vec)
+		} else {
+			h.vectors[int(idx)] = vec
+		}
 		if deleted {
 			h.deleted[int(idx)] = true
 		}
@@ -0,0 +1,397 @@
+package vectordb
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/viterin/vek/vek32"
+)
+
+// Quantization selects how vectors are held in memory for search
+type Quantization string
+
+const (
+	// QuantizationNone keeps full float32 vectors in memory
+	QuantizationNone Quantization = "none"
+	// QuantizationInt8 keeps one int8 per dimension plus a per-vector scale
+	// in memory; full-precision vectors stay in vectors.bin and are only
+	// read to re-rank the best approximate candidates
+	QuantizationInt8 Quantization = "int8"
+)
+
+const (
+	// defaultRerankFactor is how many approximate candidates per requested
+	// result are re-ranked at full precision
+	defaultRerankFactor = 4
+
+	// quantFilePattern names the scratch file holding full-precision
+	// vectors inserted since a quantized store was opened or last saved.
+	// Each open gets its own file so concurrent writers never share it.
+	quantFilePattern = "vectors-*.f32"
+
+	// staleVectorAge is how long a scratch file must go unmodified before
+	// open treats it as left behind by a crash. Every insert appends to the
+	// file, so one that a compaction or quantization in another process is
+	// still writing is never this old.
+	staleVectorAge = 24 * time.Hour
+
+	// recallK is the result depth used for recall estimates
+	recallK = 10
+)
+
+// Quantizations returns the supported quantization modes
+func Quantizations() []string {
+	return []string{string(QuantizationNone), string(QuantizationInt8)}
+}
+
+// ParseQuantization validates a quantization name; empty means none
+func ParseQuantization(s string) (Quantization, error) {
+	switch q := Quantization(strings.ToLower(strings.TrimSpace(s))); q {
+	case "", QuantizationNone:
+		return QuantizationNone, nil
+	case QuantizationInt8:
+		return q, nil
+	}
+	return "", fmt.Errorf("unknown quantization %q (valid: %s)", s, strings.Join(Quantizations(), ", "))
+}
+
+// int8Code is a scalar-quantized vector: code[i]*scale approximates the
+// original component, and norm is the original vector's exact L2 norm
+type int8Code struct {
+	scale float32
+	norm  float32
+	code  []int8
+}
+
+// quantizeInt8 maps each component to [-127, 127] using the vector's
+// largest absolute component as the scale
+func quantizeInt8(v []float32) int8Code {
+	var maxAbs float32
+	for _, x := range v {
+		if a := float32(math.Abs(float64(x))); a > maxAbs {
+			maxAbs = a
+		}
+	}
+	c := int8Code{
+		norm: float32(math.Sqrt(float64(vek32.Dot(v, v)))),
+		code: make([]int8, len(v)),
+	}
+	if maxAbs == 0 {
+		return c
+	}
+	c.scale = maxAbs / 127
+	for i, x := range v {
+		c.code[i] = int8(math.Round(float64(x / c.scale)))
+	}
+	return c
+}
+
+// distance approximates the cosine distance between query and the
+// original vector. buf is scratch space of the vector's dimension.
+func (c int8Code) distance(query []float32, queryNorm float32, buf []float32) float32 {
+	if c.norm == 0 || queryNorm == 0 {
+		return 1.0
+	}
+	for i, x := range c.code {
+		buf[i] = float32(x)
+	}
+	similarity := vek32.Dot(query, buf) * c.scale / (c.norm * queryNorm)
+	return 1.0 - max(-1, min(1, similarity))
+}
+
+// QuantizationStats describes the memory and accuracy of a quantized index
+type QuantizationStats struct {
+	Mode         Quantization `json:"mode"`
+	Vectors      int          `json:"vectors"`
+	Dimension    int          `json:"dimension"`
+	RerankFactor int          `json:"rerank_factor,omitempty"`
+	// FullBytes is the memory full float32 vectors would take
+	FullBytes int64 `json:"full_bytes"`
+	// QuantizedBytes is the memory the in-memory codes take
+	QuantizedBytes int64 `json:"quantized_bytes"`
+	// Savings is the share of vector memory saved (0-1)
+	Savings float64 `json:"savings"`
+	// Recall is the mean recall@RecallK of quantized search against exact
+	// search, over Queries vectors sampled from the index
+	Recall  float64 `json:"recall"`
+	RecallK int     `json:"recall_k"`
+	Queries int     `json:"queries"`
+}
+
+// QuantizationStats reports memory savings and estimates recall by running
+// up to queries sampled index vectors through both quantized and exact
+// search. It returns nil when the store is not quantized.
+func (s *HNSWStore) QuantizationStats(queries int) (*QuantizationStats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if !s.index.quantized {
+		return nil, nil
+	}
+
+	n := s.index.numVectors()
+	stats := &QuantizationStats{
+		Mode:           QuantizationInt8,
+		Vectors:        n,
+		Dimension:      s.config.Dimension,
+		RerankFactor:   s.index.rerank,
+		FullBytes:      int64(n) * int64(s.config.Dimension) * 4,
+		QuantizedBytes: int64(n) * int64(s.config.Dimension+8),
+		RecallK:        recallK,
+	}
+	if stats.FullBytes > 0 {
+		stats.Savings = 1 - float64(stats.QuantizedBytes)/float64(stats.FullBytes)
+	}
+
+	recall, sampled, err := s.index.estimateRecall(queries, recallK)
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate recall: %w", err)
+	}
+	stats.Recall = recall
+	stats.Queries = sampled
+	return stats, nil
+}
+
+// newIndex returns an empty index set up for the configured quantization
+func (s *HNSWStore) newIndex() (*hnswIndex, error) {
+	index := newHNSWIndex(s.config.Dimension, s.config.M, s.config.EfConstruction, s.config.EfSearch)
+	if s.config.Quantization != QuantizationInt8 {
+		return index, nil
+	}
+	if err := index.enableQuantization(s.config.Path, s.config.RerankFactor); err != nil {
+		return nil, err
+	}
+	return index, nil
+}
+
+// enableQuantization switches an empty index to int8 codes in memory.
+// Full-precision vectors inserted later are appended to a scratch file in
+// dir, created on first use.
+func (h *hnswIndex) enableQuantization(dir string, rerank int) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if rerank <= 0 {
+		rerank = defaultRerankFactor
+	}
+	h.vectorDir = dir
+	h.quantized = true
+	h.rerank = rerank
+	h.codes = make(map[int]int8Code)
+	h.vectorOffsets = make(map[int]int64)
+	h.baseOffsets = make(map[int]int64)
+	return nil
+}
+
+// removeStaleVectorFiles deletes scratch vector files in dir that a
+// process left behind by not closing its store. Files modified within
+// staleVectorAge may belong to another process sharing the index and are
+// kept.
+func removeStaleVectorFiles(dir string) {
+	matches, _ := filepath.Glob(filepath.Join(dir, quantFilePattern))
+	for _, path := range matches {
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > staleVectorAge {
+			_ = os.Remove(path)
+		}
+	}
+}
+
+// storeQuantized writes the full vector to the scratch file and keeps only
+// its code in memory. Callers must hold h.mu.
+func (h *hnswIndex) storeQuantized(idx int, vector []float32) {
+	offset, err := h.appendScratch(vector)
+	if err != nil {
+		// Fall back to holding the full vector in memory
+		h.vectors[idx] = vector
+		delete(h.codes, idx)
+		delete(h.vectorOffsets, idx)
+		delete(h.baseOffsets, idx)
+		return
+	}
+	h.vectorOffsets[idx] = offset
+	delete(h.baseOffsets, idx)
+	h.codes[idx] = quantizeInt8(vector)
+	delete(h.vectors, idx)
+}
+
+// appendScratch appends a vector to the scratch file, creating it first if
+// needed. Callers must hold h.mu.
+func (h *hnswIndex) appendScratch(vector []float32) (int64, error) {
+	if h.vectorFile == nil {
+		f, err := os.CreateTemp(h.vectorDir, quantFilePattern)
+		if err != nil {
+			return 0, fmt.Errorf("failed to create vector file: %w", err)
+		}
+		h.vectorFile = f
+		h.vectorPath = f.Name()
+	}
+	return h.writeVectorToDisk(vector)
+}
+
+// fullVector reads the full-precision copy of a quantized vector from the
+// scratch file or vectors.bin. Callers must hold h.mu.
+func (h *hnswIndex) fullVector(idx int) ([]float32, error) {
+	if offset, ok := h.vectorOffsets[idx]; ok {
+		return h.readVectorAt(offset)
+	}
+	if offset, ok := h.baseOffsets[idx]; ok && h.baseFile != nil {
+		return readVector(h.baseFile, offset, h.dimension)
+	}
+	return nil, fmt.Errorf("vector %d is not on disk", idx)
+}
+
+// rebase points a quantized index at the vectors file just saved to path,
+// where index.save put each vector's components at offsets, and drops the
+// scratch file. Other indexes are left alone.
+func (h *hnswIndex) rebase(path string, offsets map[int]int64) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !h.quantized {
+		return nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open vector file: %w", err)
+	}
+	h.closeFiles()
+	h.baseFile = f
+	h.baseOffsets = offsets
+	h.vectorOffsets = make(map[int]int64)
+	return nil
+}
+
+// searchQuantized ranks every vector by its int8 code, then re-ranks the
+// best k*rerank candidates with exact cosine distance from disk. Callers
+// must hold h.mu.
+func (h *hnswIndex) searchQuantized(query []float32, k int, exclude int) []searchCandidate {
+	queryNorm := float32(math.Sqrt(float64(vek32.Dot(query, query))))
+	buf := make([]float32, h.dimension)
+
+	approx := make([]searchCandidate, 0, len(h.codes)+len(h.vectors))
+	for idx, c := range h.codes {
+		if h.deleted[idx] || idx == exclude {
+			continue
+		}
+		approx = append(approx, searchCandidate{idx: idx, distance: c.distance(query, queryNorm, buf)})
+	}
+	sortCandidates(approx)
+	if limit := k * h.rerank; len(approx) > limit {
+		approx = approx[:limit]
+	}
+
+	candidates := make([]searchCandidate, 0, len(approx)+len(h.vectors))
+	for _, c := range approx {
+		vec, err := h.fullVector(c.idx)
+		if err != nil {
+			continue
+		}
+		dist := cosineDistance(query, vec)
+		candidates = append(candidates, searchCandidate{idx: c.idx, distance: dist, score: 1.0 - dist})
+	}
+	// Vectors that could not be written to the vector file are exact already
+	for idx, vec := range h.vectors {
+		if h.deleted[idx] || idx == exclude {
+			continue
+		}
+		dist := cosineDistance(query, vec)
+		candidates = append(candidates, searchCandidate{idx: idx, distance: dist, score: 1.0 - dist})
+	}
+
+	sortCandidates(candidates)
+	if len(candidates) > k {
+		candidates = candidates[:k]
+	}
+	return candidates
+}
+
+// estimateRecall samples up to queries live vectors, evenly spread over
+// the index, and compares quantized search against an exact scan of the
+// vectors on disk. Each query excludes itself from both result lists.
+func (h *hnswIndex) estimateRecall(queries, k int) (float64, int, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	ids := make([]int, 0, len(h.codes))
+	for idx := range h.codes {
+		if !h.deleted[idx] {
+			ids = append(ids, idx)
+		}
+	}
+	sort.Ints(ids)
+	if len(ids) < 2 || queries <= 0 {
+		return 0, 0, nil
+	}
+
+	step := max(1, len(ids)/queries)
+	var sample []int
+	var vecs [][]float32
+	for i := 0; i < len(ids) && len(sample) < queries; i += step {
+		vec, err := h.fullVector(ids[i])
+		if err != nil {
+			return 0, 0, err
+		}
+		sample = append(sample, ids[i])
+		vecs = append(vecs, vec)
+	}
+
+	// One pass over the vectors serves every query
+	exact := make([][]searchCandidate, len(sample))
+	for _, idx := range ids {
+		vec, err := h.fullVector(idx)
+		if err != nil {
+			return 0, 0, err
+		}
+		for q, query := range vecs {
+			if idx == sample[q] {
+				continue
+			}
+			exact[q] = insertTopK(exact[q], searchCandidate{idx: idx, distance: cosineDistance(query, vec)}, k)
+		}
+	}
+
+	var total float64
+	for q, query := range vecs {
+		want := make(map[int]bool, len(exact[q]))
+		for _, c := range exact[q] {
+			want[c.idx] = true
+		}
+		hits := 0
+		for _, c := range h.searchQuantized(query, k, sample[q]) {
+			if want[c.idx] {
+				hits++
+			}
+		}
+		total += float64(hits) / float64(len(exact[q]))
+	}
+	return total / float64(len(sample)), len(sample), nil
+}
+
+// insertTopK adds c to top, kept sorted by distance and at most k long
+func insertTopK(top []searchCandidate, c searchCandidate, k int) []searchCandidate {
+	if len(top) == k && c.distance >= top[k-1].distance {
+		return top
+	}
+	i := sort.Search(len(top), func(i int) bool { return top[i].distance > c.distance })
+	if len(top) < k {
+		top = append(top, searchCandidate{})
+	}
+	copy(top[i+1:], top[i:])
+	top[i] = c
+	return top
+}
+
+// sortCandidates orders candidates by ascending distance, ties by index
+func sortCandidates(candidates []searchCandidate) {
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+		return candidates[i].idx < candidates[j].idx
+	})
+}
@@ -0,0 +1,186 @@
+package vectordb
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseQuantization(t *testing.T) {
+	for in, want := range map[string]Quantization{"": QuantizationNone, "none": QuantizationNone, " INT8 ": QuantizationInt8} {
+		if got, err := ParseQuantization(in); err != nil || got != want {
+			t.Errorf("ParseQuantization(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseQuantization("pq"); err == nil {
+		t.Error("ParseQuantization(pq) should fail")
+	}
+}
+
+func TestInt8CodeApproximatesCosine(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	buf := make([]float32, 64)
+	for i := 0; i < 50; i++ {
+		a, b := randomVector(rng, 64), randomVector(rng, 64)
+		norm := float32(math.Sqrt(float64(dot(a, a))))
+		got := quantizeInt8(b).distance(a, norm, buf)
+		if want := cosineDistance(a, b); math.Abs(float64(got-want)) > 0.02 {
+			t.Fatalf("approximate distance %v, exact %v", got, want)
+		}
+	}
+	if d := quantizeInt8(make([]float32, 4)).distance([]float32{1, 0, 0, 0}, 1, buf); d != 1 {
+		t.Errorf("zero vector distance = %v, want 1", d)
+	}
+}
+
+func newQuantizedStore(t *testing.T, dir string, dim int) *HNSWStore {
+	t.Helper()
+	config := DefaultStoreConfig(dir, dim)
+	config.Quantization = QuantizationInt8
+	config.CompactMinTombstones = 1
+	store, err := NewHNSWStore(config)
+	if err != nil {
+		t.Fatalf("NewHNSWStore: %v", err)
+	}
+	return store
+}
+
+func TestQuantizedStoreSearchRecallAndReopen(t *testing.T) {
+	const dim, n = 32, 300
+	dir := t.TempDir()
+	rng := rand.New(rand.NewPCG(3, 4))
+	store := newQuantizedStore(t, dir, dim)
+
+	vectors := make([][]float32, n)
+	for i := range vectors {
+		vectors[i] = randomVector(rng, dim)
+		if err := store.Insert(testChunk(fmt.Sprintf("f%d.go", i%10), i), vectors[i]); err != nil {
+			t.Fatalf("Insert: %v", err)
+		}
+	}
+	if len(store.index.vectors) != 0 || len(store.index.codes) != n {
+		t.Fatalf("quantized index holds %d full vectors and %d codes", len(store.index.vectors), len(store.index.codes))
+	}
+
+	// Re-ranking at full precision returns exact scores
+	res, err := store.Search(vectors[7], 3, nil)
+	if err != nil || len(res.Results) != 3 || res.Results[0].Chunk.ID != "f7.go#7" {
+		t.Fatalf("search: %+v, %v", res, err)
+	}
+	if res.Results[0].Score < 0.9999 {
+		t.Errorf("self score = %v, want exact 1", res.Results[0].Score)
+	}
+
+	stats, err := store.QuantizationStats(20)
+	if err != nil {
+		t.Fatalf("QuantizationStats: %v", err)
+	}
+	if stats.Vectors != n || stats.Queries != 20 || stats.Recall < 0.9 {
+		t.Errorf("stats = %+v, want %d vectors and recall >= 0.9", stats, n)
+	}
+	if stats.Savings < 0.6 || stats.QuantizedBytes >= stats.FullBytes {
+		t.Errorf("savings = %v (%d of %d bytes)", stats.Savings, stats.QuantizedBytes, stats.FullBytes)
+	}
+
+	// Compaction moves the full-precision vectors to a new file
+	if err := store.DeleteByFile("f3.go"); err != nil {
+		t.Fatalf("DeleteByFile: %v", err)
+	}
+	oldPath := store.index.vectorPath
+	if _, err := store.Compact(); err != nil {
+		t.Fatalf("Compact: %v", err)
+	}
+	if _, err := os.Stat(oldPath); !os.IsNotExist(err) {
+		t.Errorf("old vector file kept after compaction: %v", err)
+	}
+	res, err = store.Search(vectors[8], 1, nil)
+	if err != nil || len(res.Results) != 1 || res.Results[0].Chunk.ID != "f8.go#8" {
+		t.Errorf("search after compaction: %+v, %v", res, err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if leftovers, _ := filepath.Glob(filepath.Join(dir, "vectors-*.f32")); len(leftovers) != 0 {
+		t.Errorf("vector files left after Close: %v", leftovers)
+	}
+
+	// Reopening re-ranks from vectors.bin without copying it, and clears
+	// scratch files a crashed process left behind but not one another
+	// process is still writing
+	stale := filepath.Join(dir, "vectors-stale.f32")
+	live := filepath.Join(dir, "vectors-live.f32")
+	for _, path := range []string{stale, live} {
+		if err := os.WriteFile(path, make([]byte, 64), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := time.Now().Add(-2 * staleVectorAge)
+	if err := os.Chtimes(stale, old, old); err != nil {
+		t.Fatal(err)
+	}
+	store = newQuantizedStore(t, dir, dim)
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("stale vector file kept on open: %v", err)
+	}
+	if _, err := os.Stat(live); err != nil {
+		t.Errorf("vector file in use removed on open: %v", err)
+	}
+	_ = os.Remove(live)
+	if leftovers, _ := filepath.Glob(filepath.Join(dir, "vectors-*.f32")); len(leftovers) != 0 {
+		t.Errorf("open created vector files: %v", leftovers)
+	}
+	res, err = store.Search(vectors[9], 1, nil)
+	if err != nil || len(res.Results) != 1 || res.Results[0].Chunk.ID != "f9.go#9" || res.Results[0].Score < 0.9999 {
+		t.Errorf("search after reopen: %+v, %v", res, err)
+	}
+	if err := store.Insert(testChunk("new.go", n), vectors[0]); err != nil {
+		t.Fatalf("Insert after reopen: %v", err)
+	}
+	if err := store.SaveIndex(); err != nil {
+		t.Fatalf("SaveIndex: %v", err)
+	}
+	if store.index.vectorFile != nil || len(store.index.baseOffsets) != n-n/10+1 {
+		t.Errorf("save left %d vectors outside vectors.bin", len(store.index.vectorOffsets))
+	}
+	res, err = store.Search(vectors[0], 2, nil)
+	if err != nil || len(res.Results) != 2 || res.Results[1].Score < 0.9999 {
+		t.Errorf("search after save: %+v, %v", res, err)
+	}
+	if err := store.DeleteByFile("new.go"); err != nil {
+		t.Fatalf("DeleteByFile: %v", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	// vectors.bin keeps full precision, so a quantized store reopens as a
+	// plain one and vice versa
+	plain, err := NewHNSWStore(DefaultStoreConfig(dir, dim))
+	if err != nil {
+		t.Fatalf("NewHNSWStore: %v", err)
+	}
+	defer func() { _ = plain.Close() }()
+	if plain.index.numVectors() != n-n/10 {
+		t.Errorf("reopened vectors = %d, want %d", plain.index.numVectors(), n-n/10)
+	}
+}
+
+func randomVector(rng *rand.Rand, dim int) []float32 {
+	v := make([]float32, dim)
+	for i := range v {
+		v[i] = float32(rng.NormFloat64())
+	}
+	return v
+}
+
+func dot(a, b []float32) float32 {
+	var s float32
+	for i := range a {
+		s += a[i] * b[i]
+	}
+	return s
+}
@@ -93,6 +93,11 @@ func (s *SQLiteMetaStore) init() error {
 			sha256 TEXT NOT NULL,
 			indexed_at INTEGER NOT NULL
 		);
+
+		CREATE TABLE IF NOT EXISTS store_meta (
+			key TEXT PRIMARY KEY,
+			value TEXT NOT NULL
+		);
 	`
 
 	_, err := s.db.Exec(schema)
@@ -268,6 +273,78 @@ func (s *SQLiteMetaStore) DeleteByFile(file string) ([]int, error) {
 	return indices, nil
 }
 
+// LiveVectorIdx returns the vector indices referenced by chunks, ascending
+func (s *SQLiteMetaStore) LiveVectorIdx() ([]int, error) {
+	rows, err := s.db.Query(`SELECT DISTINCT vector_idx FROM chunks WHERE vector_idx IS NOT NULL ORDER BY vector_idx`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var indices []int
+	for rows.Next() {
+		var idx int
+		if err := rows.Scan(&idx); err != nil {
+			return nil, err
+		}
+		indices = append(indices, idx)
+	}
+	return indices, rows.Err()
+}
+
+// RemapVectorIdx rewrites chunk vector indices from old to new in one
+// transaction; live indices missing from mapping are cleared. The
+// metaKey/metaValue pair is written in the same transaction so a caller can
+// record the matching on-disk state atomically with the remap.
+func (s *SQLiteMetaStore) RemapVectorIdx(mapping map[int]int, metaKey, metaValue string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Old and new ranges overlap, so park every row at -(new+1) first and
+	// flip the sign afterwards; no row ever collides with an unmapped one
+	stmt, err := tx.Prepare("UPDATE chunks SET vector_idx = ? WHERE vector_idx = ?")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = stmt.Close() }()
+	for oldIdx, newIdx := range mapping {
+		if _, err := stmt.Exec(-(newIdx + 1), oldIdx); err != nil {
+			return fmt.Errorf("failed to remap vector %d: %w", oldIdx, err)
+		}
+	}
+	if _, err := tx.Exec("UPDATE chunks SET vector_idx = NULL WHERE vector_idx >= 0"); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("UPDATE chunks SET vector_idx = -vector_idx - 1 WHERE vector_idx < 0"); err != nil {
+		return err
+	}
+	if metaKey != "" {
+		if _, err := tx.Exec("INSERT OR REPLACE INTO store_meta (key, value) VALUES (?, ?)", metaKey, metaValue); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetMeta returns a store_meta value, or "" when the key is not set
+func (s *SQLiteMetaStore) GetMeta(key string) (string, error) {
+	var value string
+	err := s.db.QueryRow("SELECT value FROM store_meta WHERE key = ?", key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return value, err
+}
+
+// DeleteMeta removes a store_meta key
+func (s *SQLiteMetaStore) DeleteMeta(key string) error {
+	_, err := s.db.Exec("DELETE FROM store_meta WHERE key = ?", key)
+	return err
+}
+
 // Count returns the total number of chunks
 func (s *SQLiteMetaStore) Count() (int, error) {
 	var count int
@@ -99,16 +99,30 @@ type StoreConfig struct {
 	EfConstruction int
 	// EfSearch is the HNSW search parameter
 	EfSearch int
+	// CompactRatio is the tombstone share of vector slots above which
+	// MaybeCompact rebuilds the index (0 disables automatic compaction)
+	CompactRatio float64
+	// CompactMinTombstones keeps small indexes from compacting constantly
+	CompactMinTombstones int
+	// Quantization selects in-memory vector compression (default none)
+	Quantization Quantization
+	// RerankFactor is how many quantized candidates per result are
+	// re-ranked at full precision (default 4)
+	RerankFactor int
 }
 
 // DefaultStoreConfig returns default configuration
 func DefaultStoreConfig(path string, dimension int) *StoreConfig {
 	return &StoreConfig{
-		Path:           path,
-		Dimension:      dimension,
-		MaxElements:    100000,
-		M:              16,
-		EfConstruction: 200,
-		EfSearch:       50,
+		Path:                 path,
+		Dimension:            dimension,
+		MaxElements:          100000,
+		M:                    16,
+		EfConstruction:       200,
+		EfSearch:             50,
+		CompactRatio:         0.2,
+		CompactMinTombstones: 256,
+		Quantization:         QuantizationNone,
+		RerankFactor:         defaultRerankFactor,
 	}
 }